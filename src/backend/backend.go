@@ -0,0 +1,79 @@
+// Package backend abstracts the zk-SNARK proving system the compliance predicates are proven with,
+// so that the Generator, Prover and Verifier do not depend on a specific one.
+package backend
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// ID names a Backend. It is stored with keys and proofs.
+type ID string
+
+const (
+	Groth16 ID = "groth16"
+	PLONK   ID = "plonk"
+)
+
+// Keys and proofs of a backend, in their gnark representation.
+// They are serialized with WriteTo and deserialized with ReadFrom.
+type (
+	ProvingKey interface {
+		io.WriterTo
+		io.ReaderFrom
+	}
+	VerifyingKey interface {
+		io.WriterTo
+		io.ReaderFrom
+	}
+	Proof interface {
+		io.WriterTo
+		io.ReaderFrom
+	}
+)
+
+// A Backend compiles compliance predicates, generates their keys, and creates and verifies their
+// proofs, over the BN254 curve.
+type Backend interface {
+	ID() ID
+
+	// Compile the circuit into the constraint system this backend proves.
+	Compile(circuit frontend.Circuit) (constraint.ConstraintSystem, error)
+
+	// Setup generates the proving and verifying keys of a compiled circuit.
+	Setup(ccs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error)
+
+	// Prove creates a proof of the full witness, using all CPUs unless opts say otherwise.
+	Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...ProveOption) (Proof, error)
+
+	Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error
+
+	// Empty keys, to be deserialized with ReadFrom.
+	NewProvingKey() ProvingKey
+	NewVerifyingKey() VerifyingKey
+
+	// ReadProof decodes a proof received from an untrusted source.
+	ReadProof(data []byte) (Proof, error)
+}
+
+// Backends by ID.
+var backends = map[ID]Backend{
+	Groth16: groth16Backend{},
+	PLONK:   plonkBackend{},
+}
+
+// Default is the Backend used when none is chosen.
+var Default = backends[Groth16]
+
+// Get returns the Backend with the given ID.
+func Get(id ID) (Backend, error) {
+	b, ok := backends[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q", id)
+	}
+	return b, nil
+}
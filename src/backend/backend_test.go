@@ -0,0 +1,361 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+
+	"src/hashsuite"
+)
+
+// Proves knowledge of the square root X of the public Y.
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestBackends(t *testing.T) {
+	for id, b := range backends {
+		t.Run(string(id), func(t *testing.T) {
+			ccs, err := b.Compile(&squareCircuit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pk, vk, err := b.Setup(ccs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fullWitness, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+			if err != nil {
+				t.Fatal(err)
+			}
+			publicWitness, err := fullWitness.Public()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			proof, err := b.Prove(ccs, pk, fullWitness)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The solver can be capped at a single worker
+			if _, err := b.Prove(ccs, pk, fullWitness, WithWorkers(1)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := b.Prove(ccs, pk, fullWitness, WithWorkers(0)); err == nil {
+				t.Fatal("proved with 0 workers")
+			}
+
+			// The full witness of a proof can be kept, and solved again once read back
+			var written bytes.Buffer
+			if _, err := b.Prove(ccs, pk, fullWitness, WithWitness(&written)); err != nil {
+				t.Fatal(err)
+			}
+			reloaded, err := ReadWitness(written.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := b.Prove(ccs, pk, reloaded); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ReadWitness(written.Bytes()[:written.Len()-1]); err == nil {
+				t.Fatal("decoded a truncated witness")
+			}
+
+			// Round trip the keys and the proof through their encodings
+			var encoded bytes.Buffer
+			if _, err := vk.WriteTo(&encoded); err != nil {
+				t.Fatal(err)
+			}
+			vk = b.NewVerifyingKey()
+			if _, err := vk.ReadFrom(&encoded); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := proof.WriteTo(&encoded); err != nil {
+				t.Fatal(err)
+			}
+			proof, err = b.ReadProof(encoded.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := b.Verify(proof, vk, publicWitness); err != nil {
+				t.Fatal(err)
+			}
+
+			wrongWitness, err := frontend.NewWitness(&squareCircuit{Y: 10}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Verify(proof, vk, wrongWitness); err == nil {
+				t.Fatal("proof verified against the wrong public witness")
+			}
+
+			// A truncated proof must be rejected rather than decoded
+			if _, err := b.ReadProof(encoded.Bytes()[:encoded.Len()/2]); err == nil {
+				t.Fatal("decoded a truncated proof")
+			}
+		})
+	}
+}
+
+func TestProverOptions(t *testing.T) {
+	options, err := NewProverOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Curve != ecc.BN254 || options.Output == nil || options.Backend != "" || options.Workers != 0 {
+		t.Errorf("unexpected default options %+v", options)
+	}
+
+	// Settings the compliance predicates cannot have are rejected, rather than ignored
+	for name, opt := range map[string]ProveOption{
+		"curve":   WithCurve(ecc.BLS12_381),
+		"backend": WithBackend("stark"),
+		"hash":    WithHash("sha256"),
+		"output":  WithOutput(nil),
+		"context": WithContext(nil),
+	} {
+		if _, err := NewProverOptions(opt); err == nil {
+			t.Errorf("accepted another %s", name)
+		}
+	}
+	if _, err := NewProverOptions(WithCurve(ecc.BN254), WithBackend(PLONK), WithHash(hashsuite.Default.Name())); err != nil {
+		t.Error(err)
+	}
+
+	// Keys of another backend than the one asserted do not prove
+	b := backends[Groth16]
+	ccs, err := b.Compile(&squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, _, err := b.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fullWitness, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Prove(ccs, pk, fullWitness, WithBackend(PLONK)); err == nil {
+		t.Error("proved with groth16 keys asserted to be plonk keys")
+	}
+	if _, err := b.Prove(ccs, pk, fullWitness, WithBackend(Groth16)); err != nil {
+		t.Error(err)
+	}
+
+	// A cancelled proof is not started
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Prove(ccs, pk, fullWitness, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error %v for a cancelled proof", err)
+	}
+}
+
+// A backend reports the proving phase of a proof, and that it is done, to its progress.
+func TestProgress(t *testing.T) {
+	b := backends[Groth16]
+	ccs, err := b.Compile(&squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, _, err := b.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var phases []Progress
+	progress := WithProgress(func(p Progress) { phases = append(phases, p) })
+	Report([]ProveOption{progress}, PhaseSolving)
+	fullWitness, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Prove(ccs, pk, fullWitness, progress); err != nil {
+		t.Fatal(err)
+	}
+	if len(phases) != 3 || phases[0].Phase != PhaseSolving || phases[1].Phase != PhaseProving || phases[2].Phase != PhaseDone {
+		t.Fatalf("unexpected phases %v", phases)
+	}
+	if phases[2].Time.Before(phases[1].Time) || phases[1].Time.Before(phases[0].Time) {
+		t.Errorf("phases out of order in time %v", phases)
+	}
+
+	// A proof that fails is never done, and invalid options report nothing
+	phases = nil
+	fullWitness, err = frontend.NewWitness(&squareCircuit{X: 3, Y: 8}, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Prove(ccs, pk, fullWitness, progress); err == nil {
+		t.Fatal("proved a wrong square root")
+	}
+	Report([]ProveOption{progress, WithWorkers(0)}, PhaseCompiling)
+	if len(phases) != 1 || phases[0].Phase != PhaseProving {
+		t.Errorf("unexpected phases %v", phases)
+	}
+}
+
+// Proves knowledge of the cube root X of the public Y.
+type cubeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// The PLONK keys of different circuits are set up from one universal SRS, which must be large enough.
+func TestUniversalSRS(t *testing.T) {
+	square, err := backends[PLONK].Compile(&squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cube, err := backends[PLONK].Compile(&cubeCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs, err := NewSRS(max(SRSSize(square), SRSSize(cube)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The SRS survives its encoding
+	var encoded bytes.Buffer
+	if _, err := srs.WriteTo(&encoded); err != nil {
+		t.Fatal(err)
+	}
+	if srs, err = ReadSRS(&encoded); err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewPLONK(srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID() != PLONK {
+		t.Errorf("unexpected backend %s", b.ID())
+	}
+
+	for ccs, assignment := range map[constraint.ConstraintSystem]frontend.Circuit{square: &squareCircuit{X: 3, Y: 9}, cube: &cubeCircuit{X: 3, Y: 27}} {
+		pk, vk, err := b.Setup(ccs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fullWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The keys are proven and verified with the default PLONK backend
+		proof, err := backends[PLONK].Prove(ccs, pk, fullWitness)
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicWitness, err := fullWitness.Public()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := backends[PLONK].Verify(proof, vk, publicWitness); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// An SRS too small for a circuit is rejected
+	small, err := NewSRS(SRSSize(cube) - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err = NewPLONK(small); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := b.Setup(cube); err == nil {
+		t.Error("set up keys from an SRS too small for the circuit")
+	}
+	if _, err := NewPLONK(nil); err == nil {
+		t.Error("created a PLONK backend without an SRS")
+	}
+}
+
+// Proofs are created on the GPU only by builds with the icicle build tag, and only with groth16.
+func TestGPU(t *testing.T) {
+	if _, err := NewProverOptions(WithGPU()); (err == nil) != GPU {
+		t.Fatalf("GPU is %v, but asking for it returned %v", GPU, err)
+	}
+	if !GPU {
+		t.Skip("built without the icicle build tag")
+	}
+
+	b := backends[PLONK]
+	ccs, err := b.Compile(&squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, _, err := b.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fullWitness, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Prove(ccs, pk, fullWitness, WithGPU()); err == nil {
+		t.Error("created a plonk proof on the GPU")
+	}
+}
+
+// An SRS of exactly SRSSize points sets up the PLONK keys of a circuit, and one of a point less does not: the
+// size matches what gnark's PLONK Setup requires, see BlindingPoints.
+func TestSRSSize(t *testing.T) {
+	ccs, err := backends[PLONK].Compile(&squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := int(ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints() + ccs.GetNbPublicVariables())))
+	if size := SRSSize(ccs); size != domain+BlindingPoints {
+		t.Errorf("SRS of %d points for a domain of %d", size, domain)
+	}
+
+	srs, err := NewSRS(SRSSize(ccs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, lagrange, err := universalSRS(srs, ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(canonical.Pk.G1) != SRSSize(ccs) || len(lagrange.Pk.G1) != domain {
+		t.Errorf("cut a canonical SRS of %d and a Lagrange SRS of %d points from %d", len(canonical.Pk.G1), len(lagrange.Pk.G1), SRSSize(ccs))
+	}
+	b, err := NewPLONK(srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := b.Setup(ccs); err != nil {
+		t.Errorf("could not set up keys from an SRS of SRSSize points: %v", err)
+	}
+
+	short, err := NewSRS(SRSSize(ccs) - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err = NewPLONK(short); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := b.Setup(ccs); err == nil {
+		t.Error("set up keys from an SRS one point short of SRSSize")
+	}
+}
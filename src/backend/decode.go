@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// Compressed and uncompressed sizes of encoded BN254 points.
+var (
+	g1 = [2]int{bn254.SizeOfG1AffineCompressed, bn254.SizeOfG1AffineUncompressed}
+	g2 = [2]int{bn254.SizeOfG2AffineCompressed, bn254.SizeOfG2AffineUncompressed}
+)
+
+// A proofReader walks over an encoded proof to check it before gnark decodes it.
+//
+// gnark allocates whatever length a length prefix declares, so the prefixes are checked
+// against the size of the input first. The first error is kept in err.
+type proofReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+// Skip over points. Points are either compressed or uncompressed, as told by the two most
+// significant bits of their first byte.
+func (r *proofReader) points(sizes ...[2]int) {
+	for _, size := range sizes {
+		if r.err != nil {
+			return
+		}
+		if r.offset >= len(r.data) {
+			r.err = fmt.Errorf("invalid PCD proof: truncated")
+			return
+		}
+		if r.data[r.offset]&0b11000000 == 0 {
+			r.skip(size[1])
+		} else {
+			r.skip(size[0])
+		}
+	}
+}
+
+func (r *proofReader) skip(n int) {
+	if r.err != nil {
+		return
+	}
+	if r.offset+n > len(r.data) {
+		r.err = fmt.Errorf("invalid PCD proof: truncated")
+		return
+	}
+	r.offset += n
+}
+
+// Check and skip over a slice prefixed with its length, whose elements take at least elementSize bytes.
+func (r *proofReader) slice(elementSize int) {
+	if r.err != nil {
+		return
+	}
+	if r.offset+4 > len(r.data) {
+		r.err = fmt.Errorf("invalid PCD proof: truncated")
+		return
+	}
+	n := uint64(binary.BigEndian.Uint32(r.data[r.offset : r.offset+4]))
+	r.offset += 4
+	if n*uint64(elementSize) > uint64(len(r.data)-r.offset) {
+		r.err = fmt.Errorf("invalid PCD proof: %d elements declared in %d bytes", n, len(r.data))
+		return
+	}
+	r.offset += int(n) * elementSize
+}
+
+// ReadWitness decodes a public or full witness over BN254, as written by witness.MarshalBinary or WriteTo,
+// received from an untrusted source or read back from storage. Both are the same encoding: the number of
+// public and secret variables, followed by a length prefixed vector of field elements.
+func ReadWitness(data []byte) (witness.Witness, error) {
+	// gnark allocates whatever length the length prefix declares, so check it against the input size first
+	if len(data) < 12 {
+		return nil, fmt.Errorf("invalid witness: truncated")
+	}
+	nbElements := uint64(binary.BigEndian.Uint32(data[8:12]))
+	if nbElements*fr.Bytes != uint64(len(data)-12) {
+		return nil, fmt.Errorf("invalid witness: %d field elements declared in %d bytes", nbElements, len(data))
+	}
+
+	w, err := witness.New(ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	if err := w.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("invalid witness: %w", err)
+	}
+	return w, nil
+}
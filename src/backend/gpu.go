@@ -0,0 +1,27 @@
+package backend
+
+import (
+	"fmt"
+
+	icicle_bn254 "github.com/consensys/gnark/backend/groth16/bn254/icicle"
+)
+
+// GPU tells whether this build proves on the GPU when asked to with WithGPU. It is true for builds with the
+// icicle build tag, which links gnark's ICICLE prover, and needs CUDA and the ICICLE libraries:
+//
+//	go build -tags icicle ./...
+//
+// Only groth16 proofs are accelerated, with their multi-scalar multiplications and FFTs run on the GPU.
+const GPU = icicle_bn254.HasIcicle
+
+// WithGPU proves on the GPU, which the pixel matrices of the compliance predicates need at realistic
+// resolutions. It fails for builds without GPU support, rather than silently proving on the CPU.
+func WithGPU() ProveOption {
+	return func(options *ProverOptions) error {
+		if !GPU {
+			return fmt.Errorf("this build does not prove on the GPU: build it with -tags icicle")
+		}
+		options.GPU = true
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	gnarkbackend "github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// Groth16 over an R1CS, with a per-circuit trusted setup.
+type groth16Backend struct{}
+
+func (groth16Backend) ID() ID {
+	return Groth16
+}
+
+func (groth16Backend) Compile(circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+}
+
+func (groth16Backend) Setup(ccs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error) {
+	return groth16.Setup(ccs)
+}
+
+func (groth16Backend) Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...ProveOption) (Proof, error) {
+	provingKey, ok := pk.(groth16.ProvingKey)
+	if !ok {
+		return nil, fmt.Errorf("not a groth16 proving key")
+	}
+	return prove(Groth16, opts, fullWitness, func(options ...gnarkbackend.ProverOption) (Proof, error) {
+		return groth16.Prove(ccs, provingKey, fullWitness, options...)
+	})
+}
+
+func (groth16Backend) Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
+	groth16Proof, ok := proof.(groth16.Proof)
+	if !ok {
+		return fmt.Errorf("not a groth16 proof")
+	}
+	verifyingKey, ok := vk.(groth16.VerifyingKey)
+	if !ok {
+		return fmt.Errorf("not a groth16 verifying key")
+	}
+	return groth16.Verify(groth16Proof, verifyingKey, publicWitness)
+}
+
+func (groth16Backend) NewProvingKey() ProvingKey {
+	return groth16.NewProvingKey(ecc.BN254)
+}
+
+func (groth16Backend) NewVerifyingKey() VerifyingKey {
+	return groth16.NewVerifyingKey(ecc.BN254)
+}
+
+// A groth16 proof over BN254 is encoded as Ar (G1), Bs (G2), Krs (G1), a length prefixed
+// slice of commitments (G1) and a commitment proof of knowledge (G1).
+func (groth16Backend) ReadProof(data []byte) (Proof, error) {
+	r := proofReader{data: data}
+	r.points(g1, g2, g1)
+	r.slice(bn254.SizeOfG1AffineCompressed)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	proof := groth16.NewProof(ecc.BN254)
+	if _, err := proof.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("invalid PCD proof: %w", err)
+	}
+	return proof, nil
+}
@@ -0,0 +1,206 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	gnarkbackend "github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint/solver"
+
+	"src/hashsuite"
+)
+
+// A ProveOption configures a single proof: the Prove call of a Backend, and the Prover calling it.
+type ProveOption func(*ProverOptions) error
+
+// ProverOptions are the settings of a proof, as set by its ProveOption values, see NewProverOptions.
+//
+// The curve, backend and hash of a proof are those of the keys it is created with, which were compiled into
+// its compliance predicate by the Generator. Setting them does not change them: it asserts what the caller
+// expects, so that keys of another backend, or a build with another hashsuite.Default, fail before proving
+// rather than produce proofs the caller's verifiers reject.
+type ProverOptions struct {
+	Curve   ecc.ID    // Curve of the proof; BN254, the only curve of the compliance predicates
+	Backend ID        // Proving system the keys must be for; any if empty
+	Hash    string    // Name of the HashSuite the predicates must use, see hashsuite.Default; any if empty
+	Workers int       // Goroutines solving the constraint system; one per CPU if 0
+	GPU     bool      // Whether the proof is created on the GPU, see WithGPU
+	Output  io.Writer // Where the Prover reports its progress and errors; os.Stdout unless set
+
+	// Context cancels the proof between its phases; context.Background() unless set, see WithContext
+	Context context.Context
+
+	// Progress is told the phases of the proof, see WithProgress; nil unless set
+	Progress func(Progress)
+
+	// EditorKey signs the image of an edit, see WithEditorKey; nil unless set
+	EditorKey signature.Signer
+
+	witness io.Writer
+}
+
+// NewProverOptions returns the ProverOptions that opts set.
+func NewProverOptions(opts ...ProveOption) (ProverOptions, error) {
+	options := ProverOptions{Curve: ecc.BN254, Output: os.Stdout, Context: context.Background()}
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return ProverOptions{}, err
+		}
+	}
+	return options, nil
+}
+
+// WithCurve asserts that the proof is over curve. The compliance predicates verify the EdDSA signature of the
+// camera over the twisted Edwards curve whose base field is the scalar field of BN254, so BN254 is the only
+// curve they are compiled for, and any other fails.
+func WithCurve(curve ecc.ID) ProveOption {
+	return func(options *ProverOptions) error {
+		if curve != ecc.BN254 {
+			return fmt.Errorf("the compliance predicates are over %s, not %s", ecc.BN254, curve)
+		}
+		options.Curve = curve
+		return nil
+	}
+}
+
+// WithBackend asserts that the proof is created with the backend id, i.e. that the keys were generated for it.
+func WithBackend(id ID) ProveOption {
+	return func(options *ProverOptions) error {
+		if _, err := Get(id); err != nil {
+			return err
+		}
+		options.Backend = id
+		return nil
+	}
+}
+
+// WithHash asserts that the compliance predicates hash with the HashSuite of the given name. They are
+// compiled with hashsuite.Default, so any other fails.
+func WithHash(name string) ProveOption {
+	return func(options *ProverOptions) error {
+		if name != hashsuite.Default.Name() {
+			return fmt.Errorf("the compliance predicates of this build hash with %s, not %s", hashsuite.Default.Name(), name)
+		}
+		options.Hash = name
+		return nil
+	}
+}
+
+// WithWorkers caps the goroutines solving the constraint system of a proof at workers, instead of one
+// per CPU, so that the concurrent proofs of a multi-tenant server do not each grab every core.
+//
+// The multi-scalar multiplications of the gnark provers size themselves by runtime.NumCPU and cannot be
+// capped per proof. They only run on as many CPUs as runtime.GOMAXPROCS allows the whole process.
+func WithWorkers(workers int) ProveOption {
+	return func(options *ProverOptions) error {
+		if workers < 1 {
+			return fmt.Errorf("invalid number of workers: %d", workers)
+		}
+		options.Workers = workers
+		return nil
+	}
+}
+
+// WithOutput reports the progress and errors of the Prover to w instead of os.Stdout, e.g. io.Discard for a
+// quiet server that only looks at the proofs it gets back. gnark logs compiling and proving with its own
+// logger, which is set for the whole process with logger.Set.
+func WithOutput(w io.Writer) ProveOption {
+	return func(options *ProverOptions) error {
+		if w == nil {
+			return fmt.Errorf("no writer for the output")
+		}
+		options.Output = w
+		return nil
+	}
+}
+
+// WithContext cancels the proof with ctx, e.g. when the client of a proving server goes away. The Prover
+// checks ctx before it compiles the compliance predicate and before it proves, and gives up with the error of
+// ctx, so that a cancelled request does not start minutes of proving nobody waits for.
+//
+// A phase that has started runs to its end: gnark compiles and proves without a context, so that a cancelled
+// proof still holds its CPUs until the phase it is in is over.
+func WithContext(ctx context.Context) ProveOption {
+	return func(options *ProverOptions) error {
+		if ctx == nil {
+			return fmt.Errorf("no context for the proof")
+		}
+		options.Context = ctx
+		return nil
+	}
+}
+
+// WithEditorKey signs the image of an edit with editorKey, the EditorKey of the SK_PP generated with the keys of
+// the edit. The key is secret: it is kept with the secret key of the camera rather than with the proving key, so
+// that only the editors it is handed to can sign edits the verifier attributes to the keys. Original images are
+// signed by the camera, and proven without it.
+func WithEditorKey(editorKey signature.Signer) ProveOption {
+	return func(options *ProverOptions) error {
+		if editorKey == nil {
+			return fmt.Errorf("no editor key to sign edits with")
+		}
+		options.EditorKey = editorKey
+		return nil
+	}
+}
+
+// WithWitness writes the full witness of a proof to w before proving it, in the encoding of witness.WriteTo,
+// which ReadWitness reads back. A proof that fails to solve can then be solved again, e.g. with gnark's test
+// engine, without the images and keys it was created from.
+//
+// The full witness holds the secret inputs of the compliance predicate, e.g. the pixels of the previous image:
+// it is for debugging, and must not be published with the proof.
+func WithWitness(w io.Writer) ProveOption {
+	return func(options *ProverOptions) error {
+		if w == nil {
+			return fmt.Errorf("no writer for the witness")
+		}
+		options.witness = w
+		return nil
+	}
+}
+
+// prove creates the proof of fullWitness with the backend b, by calling the gnark prover of b with the gnark
+// prover options that implement opts, and reports its progress. It writes fullWitness out if opts ask for it.
+func prove(b ID, opts []ProveOption, fullWitness witness.Witness, gnarkProve func(...gnarkbackend.ProverOption) (Proof, error)) (Proof, error) {
+	options, err := NewProverOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if options.Backend != "" && options.Backend != b {
+		return nil, fmt.Errorf("the keys are for %s, not %s", b, options.Backend)
+	}
+	if err := options.Context.Err(); err != nil {
+		return nil, fmt.Errorf("proving cancelled: %w", err)
+	}
+
+	var proverOptions []gnarkbackend.ProverOption
+	if options.Workers > 0 {
+		proverOptions = append(proverOptions, gnarkbackend.WithSolverOptions(solver.WithNbTasks(options.Workers)))
+	}
+	if options.GPU {
+		if b != Groth16 {
+			return nil, fmt.Errorf("%s proofs are not created on the GPU, only %s proofs", b, Groth16)
+		}
+		proverOptions = append(proverOptions, gnarkbackend.WithIcicleAcceleration())
+	}
+
+	if options.witness != nil {
+		if _, err := fullWitness.WriteTo(options.witness); err != nil {
+			return nil, fmt.Errorf("writing the witness: %w", err)
+		}
+	}
+
+	options.report(PhaseProving)
+	proof, err := gnarkProve(proverOptions...)
+	if err != nil {
+		return nil, err
+	}
+	options.report(PhaseDone)
+	return proof, nil
+}
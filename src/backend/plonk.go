@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	gnarkbackend "github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test/unsafekzg"
+)
+
+// PLONK over a sparse constraint system, with a KZG structured reference string.
+//
+// Unless the backend was created with NewPLONK, the SRS is generated for each circuit by unsafekzg, whose
+// toxic waste is known to the process that ran the setup: PLONK keys are then only as trustworthy as the
+// machine that generated them.
+type plonkBackend struct {
+	srs *kzg.SRS // universal SRS the keys are set up from; nil for an SRS per circuit
+}
+
+func (plonkBackend) ID() ID {
+	return PLONK
+}
+
+func (plonkBackend) Compile(circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	return frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+}
+
+func (b plonkBackend) Setup(ccs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error) {
+	if b.srs != nil {
+		srs, srsLagrange, err := universalSRS(b.srs, ccs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return plonk.Setup(ccs, srs, srsLagrange)
+	}
+
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plonk.Setup(ccs, srs, srsLagrange)
+}
+
+func (plonkBackend) Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...ProveOption) (Proof, error) {
+	provingKey, ok := pk.(plonk.ProvingKey)
+	if !ok {
+		return nil, fmt.Errorf("not a plonk proving key")
+	}
+	return prove(PLONK, opts, fullWitness, func(options ...gnarkbackend.ProverOption) (Proof, error) {
+		return plonk.Prove(ccs, provingKey, fullWitness, options...)
+	})
+}
+
+func (plonkBackend) Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
+	plonkProof, ok := proof.(plonk.Proof)
+	if !ok {
+		return fmt.Errorf("not a plonk proof")
+	}
+	verifyingKey, ok := vk.(plonk.VerifyingKey)
+	if !ok {
+		return fmt.Errorf("not a plonk verifying key")
+	}
+	return plonk.Verify(plonkProof, verifyingKey, publicWitness)
+}
+
+func (plonkBackend) NewProvingKey() ProvingKey {
+	return plonk.NewProvingKey(ecc.BN254)
+}
+
+func (plonkBackend) NewVerifyingKey() VerifyingKey {
+	return plonk.NewVerifyingKey(ecc.BN254)
+}
+
+// A plonk proof over BN254 is encoded as LRO (3 G1), Z (G1), H (3 G1), the batched opening proof
+// (G1 and a length prefixed slice of field elements), the shifted opening proof of Z (G1 and a field
+// element) and a length prefixed slice of commitments (G1).
+func (plonkBackend) ReadProof(data []byte) (Proof, error) {
+	r := proofReader{data: data}
+	r.points(g1, g1, g1, g1, g1, g1, g1, g1)
+	r.slice(fr.Bytes)
+	r.points(g1)
+	r.skip(fr.Bytes)
+	r.slice(bn254.SizeOfG1AffineCompressed)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	proof := plonk.NewProof(ecc.BN254)
+	if _, err := proof.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("invalid PCD proof: %w", err)
+	}
+	return proof, nil
+}
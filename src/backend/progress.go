@@ -0,0 +1,60 @@
+package backend
+
+import "time"
+
+// A Phase of a proof. A proof goes through the phases in the order they are declared in, unless it fails; the
+// proofs of the frames of a clip share the constraint system the clip compiled once.
+type Phase string
+
+const (
+	// The witness of the compliance predicate is assigned from the images, signatures and parameters of the
+	// proof. It is assigned before compiling, which takes the circuit the witness is read from over.
+	PhaseSolving Phase = "solving"
+
+	// The compliance predicate is compiled, or its constraint system is taken from the cache of the process,
+	// see generator.Compile.
+	PhaseCompiling Phase = "compiling"
+
+	// The backend solves the constraint system for the witness and creates the proof, which takes minutes for
+	// the larger predicates. gnark solves and proves in one call, so solving the internal wires of the
+	// constraint system is part of this phase.
+	PhaseProving Phase = "proving"
+
+	// The proof was created.
+	PhaseDone Phase = "done"
+)
+
+// Progress reports that a proof entered a Phase, and when. The time a phase took is the time between its
+// Progress and the next one, e.g. the time of PhaseDone minus the time of PhaseProving.
+type Progress struct {
+	Phase Phase
+	Time  time.Time
+}
+
+// WithProgress calls progress as the proof enters each Phase, so that CLIs and servers can tell their users
+// what a proof that takes minutes is doing. The Prover of every compliance predicate reports PhaseSolving and
+// PhaseCompiling, and the backend PhaseProving and PhaseDone.
+//
+// progress is called from the goroutine creating the proof, and must return quickly.
+func WithProgress(progress func(Progress)) ProveOption {
+	return func(options *ProverOptions) error {
+		options.Progress = progress
+		return nil
+	}
+}
+
+// Report tells the progress of the proof opts configure, if any, that it entered phase. Invalid options
+// report nothing: they fail the proof when it is created.
+func Report(opts []ProveOption, phase Phase) {
+	options, err := NewProverOptions(opts...)
+	if err != nil {
+		return
+	}
+	options.report(phase)
+}
+
+func (options ProverOptions) report(phase Phase) {
+	if options.Progress != nil {
+		options.Progress(Progress{Phase: phase, Time: time.Now()})
+	}
+}
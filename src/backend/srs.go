@@ -0,0 +1,74 @@
+package backend
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark/constraint"
+)
+
+// NewPLONK returns a PLONK backend whose Setup takes the keys of every compliance predicate from the universal
+// SRS srs, e.g. the SRS of a powers of tau ceremony, rather than from an SRS generated for the predicate. Keys
+// of new transformations are then set up without another trusted setup, and are as trustworthy as srs.
+//
+// srs must be in canonical form, and hold at least SRSSize points for the largest predicate. The backend has
+// the PLONK ID: its keys and proofs are those of the default PLONK backend, which proves and verifies them.
+func NewPLONK(srs *kzg.SRS) (Backend, error) {
+	if srs == nil || len(srs.Pk.G1) < 2 {
+		return nil, fmt.Errorf("the SRS holds no powers of tau")
+	}
+	return plonkBackend{srs: srs}, nil
+}
+
+// BlindingPoints is the number of points the canonical SRS of a compliance predicate holds beyond its FFT domain.
+// gnark's PLONK Setup (backend/plonk/bn254.Setup) takes a Lagrange SRS of exactly as many points as the domain
+// has elements, and a canonical SRS of at least 3 more: the polynomials of a proof are blinded, which raises
+// their degree, and opening them with KZG needs those points.
+const BlindingPoints = 3
+
+// SRSSize returns the number of G1 points of the canonical SRS the PLONK keys of ccs are set up from: the size
+// of the FFT domain of ccs, the smallest power of two holding its constraints and public inputs, plus
+// BlindingPoints.
+func SRSSize(ccs constraint.ConstraintSystem) int {
+	return int(ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()+ccs.GetNbPublicVariables()))) + BlindingPoints
+}
+
+// NewSRS generates a universal SRS of size points, for development: its toxic waste is drawn and dropped by
+// this process, so keys set up from it are only as trustworthy as the machine that generated it. Production
+// keys are set up from the SRS of a ceremony, read with ReadSRS.
+func NewSRS(size int) (*kzg.SRS, error) {
+	tau, err := rand.Int(rand.Reader, fr.Modulus())
+	if err != nil {
+		return nil, err
+	}
+	return kzg.NewSRS(uint64(size), tau)
+}
+
+// ReadSRS reads a universal SRS in the encoding of its WriteTo method.
+func ReadSRS(r io.Reader) (*kzg.SRS, error) {
+	srs := new(kzg.SRS)
+	if _, err := srs.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("invalid SRS: %w", err)
+	}
+	return srs, nil
+}
+
+// universalSRS returns the canonical and Lagrange SRS the PLONK keys of ccs are set up from, cut from srs.
+func universalSRS(srs *kzg.SRS, ccs constraint.ConstraintSystem) (*kzg.SRS, *kzg.SRS, error) {
+	size := SRSSize(ccs)
+	if len(srs.Pk.G1) < size {
+		return nil, nil, fmt.Errorf("the SRS holds %d points, the compliance predicate needs %d", len(srs.Pk.G1), size)
+	}
+
+	// The Lagrange SRS spans the FFT domain only
+	lagrange, err := kzg.ToLagrangeG1(srs.Pk.G1[:size-BlindingPoints])
+	if err != nil {
+		return nil, nil, err
+	}
+	canonical := &kzg.SRS{Pk: kzg.ProvingKey{G1: srs.Pk.G1[:size]}, Vk: srs.Vk}
+	return canonical, &kzg.SRS{Pk: kzg.ProvingKey{G1: lagrange}, Vk: srs.Vk}, nil
+}
@@ -1,11 +1,16 @@
+// Package camera simulates a secure camera, which runs the Generator and signs the pictures it takes.
 package camera
 
 import (
 	"fmt"
+	"io"
+	"math/big"
 	gen "src/generator"
 	myImage "src/image"
 	"src/prover"
 	myTransformations "src/transformations"
+
+	"github.com/consensys/gnark-crypto/signature"
 )
 
 // A Secure Camera is not defined in the PhotoProof paper.
@@ -17,11 +22,33 @@ type SecureCamera struct {
 	provingKey   gen.PK_PP
 	verifyingKey gen.VK_PP
 	picture      myImage.I
+	counter      uint64 // number of pictures taken, the nonce the current picture is signed for
 }
 
-// Simulate a secure camera taking a picture
+// Simulate a secure camera taking a picture. Its perceptual hash is stored in its metadata, which the camera
+// signs, so that verifiers can tell which pictures a published image may come from.
 func (cam *SecureCamera) TakePicture() {
 	cam.picture = myImage.AllWhiteImage()
+	cam.picture.SetDHash()
+	cam.counter++
+}
+
+// Take a picture from the sensor output of the camera, a DNG file, rather than the all white test image.
+// The capture metadata read from the file is part of the picture, which the camera signs.
+func (cam *SecureCamera) TakeDNGPicture(dng io.Reader) error {
+	picture, err := myImage.DecodeDNG(dng)
+	if err != nil {
+		return err
+	}
+	picture.SetDHash()
+	cam.picture = picture
+	cam.counter++
+	return nil
+}
+
+// Counter returns the capture counter of the current picture, the nonce of its proofs.
+func (cam *SecureCamera) Counter() *big.Int {
+	return new(big.Int).SetUint64(cam.counter)
 }
 
 // Simulate a secure camera running the generator function
@@ -55,20 +82,54 @@ func (cam *SecureCamera) CameraGenerator() (gen.PK_PP, gen.VK_PP) {
 	return pk_PP, vk_PP
 }
 
+// CameraEditorKey returns the key the camera signs the edits of its pictures with, which it hands to the
+// editors it trusts with backend.WithEditorKey. Unlike the proving key, it is kept secret.
+func (cam *SecureCamera) CameraEditorKey() signature.Signer {
+	return cam.secretKey.EditorKey
+}
+
 // Simulate a secure camera running the editor function with the Identity transformation
 func (cam *SecureCamera) CameraProver() prover.Proof {
 
 	// Sign this camera's picture
-	signedImage := cam.picture.Sign(cam.secretKey.SecretKey)
+	signedImage := cam.picture.Sign(cam.secretKey.SecretKey, cam.Counter(), big.NewInt(0))
 
 	// Create a Z struct {Image, PublicKey}
 	z := myImage.Z{Image: cam.picture, PublicKey: cam.provingKey.PublicKey}
 
 	// Create proof using signedImage as the digital signature
-	proof := prover.Proof{ImageSignature: signedImage, Z: z}
+	proof := prover.NewSignedProof(z, signedImage, cam.Counter())
 
 	return prover.Prover(cam.provingKey, cam.verifyingKey.VerifyingKey, proof, myTransformations.Transformation{
 		T:      myTransformations.Identity,
 		Params: nil,
 	})
 }
+
+// Simulate a secure camera reading out the RAW capture of its picture, and signing it like CameraProver signs
+// the picture. The sensor samples every pixel in the color of its Bayer filter, scaled to the RAW bit depth.
+func (cam *SecureCamera) CameraRAW() (myImage.RAW, []byte) {
+	raw := myImage.RAW{M: myImage.Metadata{Width: myImage.Width, Height: myImage.Height}}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			pixel := cam.picture.Pixels[y][x]
+			channel := [2][2]uint8{{pixel.R, pixel.G}, {pixel.G, pixel.B}}[y%2][x%2]
+			raw.Samples[y][x] = uint16(channel)<<(myImage.RAWBits-8) | uint16(channel)>>(16-myImage.RAWBits)
+		}
+	}
+	return raw, raw.Sign(cam.secretKey.SecretKey, cam.Counter(), big.NewInt(0))
+}
+
+// Simulate a secure camera with a monochrome mode, e.g. for document scans, reading out the grayscale capture of
+// its picture and signing it like CameraProver signs the picture.
+func (cam *SecureCamera) CameraGray() (myImage.Gray, []byte) {
+	gray := cam.picture.ToGray()
+	return gray, gray.Sign(cam.secretKey.SecretKey, cam.Counter(), big.NewInt(0))
+}
+
+// Simulate a secure camera with a deep color mode reading out the capture of its picture at image.DeepBits per
+// channel, and signing it like CameraProver signs the picture.
+func (cam *SecureCamera) CameraDeep() (myImage.Deep, []byte) {
+	deep := cam.picture.ToDeep()
+	return deep, deep.Sign(cam.secretKey.SecretKey, cam.Counter(), big.NewInt(0))
+}
@@ -0,0 +1,660 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"src/backend"
+	"src/editor"
+	gen "src/generator"
+	myImage "src/image"
+	"src/profiling"
+	"src/prover"
+	myTransformations "src/transformations"
+	"src/verifier"
+)
+
+// Names of the key files inside the -keys directory.
+const (
+	provingKeyFile   = "pk_pp.json"
+	verifyingKeyFile = "vk_pp.json"
+	secretKeyFile    = "sk_pp.json"
+	counterFile      = "counter.json" // capture counter of the camera, next to its secret key
+
+	disclosureProvingKeyFile   = "pk_disclosure.json"
+	disclosureVerifyingKeyFile = "vk_disclosure.json"
+)
+
+// Run the Generator and write the proving, verifying and secret keys into the -keys directory.
+func keygen(args []string, stderr io.Writer) (interface{}, error) {
+	flags := flag.NewFlagSet("keygen", flag.ExitOnError)
+	keys := flags.String("keys", ".", "directory to write the keys into")
+	imagePath := flags.String("image", "", "JSON encoded image, or JPEG, PNG, TIFF, WebP or DNG file (default: all white image)")
+	backendID := flags.String("backend", string(backend.Default.ID()), "proving system: groth16 or plonk")
+	profile := flags.String("profile", "", "write a pprof constraint profile of the compliance predicate to this file, and report its constraints per gadget")
+	disclosure := flags.Bool("disclosure", false, "also write the keys of selective disclosures")
+	public := flags.String("public", "", "parameters of the crops to disclose, comma separated, e.g. x1,y1 for a public size (default: none)")
+	srsPath := flags.String("srs", "", "universal SRS, written by srs or by a ceremony, to set the plonk keys up from (default: an SRS per circuit)")
+	flags.Parse(args)
+
+	image, err := readImage(*imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := backend.Get(backend.ID(*backendID))
+	if err != nil {
+		return nil, err
+	}
+	if *srsPath != "" {
+		if b.ID() != backend.PLONK {
+			return nil, fmt.Errorf("-srs sets up plonk keys, not %s keys", b.ID())
+		}
+		file, err := os.Open(*srsPath)
+		if err != nil {
+			return nil, err
+		}
+		srs, err := backend.ReadSRS(bufio.NewReader(file))
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		if b, err = backend.NewPLONK(srs); err != nil {
+			return nil, err
+		}
+	}
+
+	// The Generator compiles the compliance predicate, which is what the profiler records
+	var session *profiling.Session
+	if *profile != "" {
+		session = profiling.Start(*profile)
+	}
+
+	t := myTransformations.Transformation{T: myTransformations.Identity, Params: map[string]int{}}
+	if *public != "" {
+		t.Public = strings.Split(*public, ",")
+	}
+	pk_pp, vk_pp, sk_pp, err := gen.GeneratorWithBackend(b, image, t)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(*keys, 0o755); err != nil {
+		return nil, err
+	}
+	provingKeyPath := filepath.Join(*keys, provingKeyFile)
+	verifyingKeyPath := filepath.Join(*keys, verifyingKeyFile)
+	secretKeyPath := filepath.Join(*keys, secretKeyFile)
+	if err := writeJSON(provingKeyPath, pk_pp, 0o644); err != nil {
+		return nil, err
+	}
+	if err := writeJSON(verifyingKeyPath, vk_pp, 0o644); err != nil {
+		return nil, err
+	}
+	if err := writeJSON(secretKeyPath, sk_pp, 0o600); err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"provingKey":   provingKeyPath,
+		"verifyingKey": verifyingKeyPath,
+		"secretKey":    secretKeyPath,
+	}
+	if session != nil {
+		report, err := session.Stop()
+		if err != nil {
+			return nil, err
+		}
+		result["profile"] = report
+	}
+
+	if *disclosure {
+		pk_disclosure, vk_disclosure, err := gen.DisclosureGenerator(b, pk_pp.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		disclosureProvingKeyPath := filepath.Join(*keys, disclosureProvingKeyFile)
+		disclosureVerifyingKeyPath := filepath.Join(*keys, disclosureVerifyingKeyFile)
+		if err := writeJSON(disclosureProvingKeyPath, pk_disclosure, 0o644); err != nil {
+			return nil, err
+		}
+		if err := writeJSON(disclosureVerifyingKeyPath, vk_disclosure, 0o644); err != nil {
+			return nil, err
+		}
+		result["disclosureProvingKey"] = disclosureProvingKeyPath
+		result["disclosureVerifyingKey"] = disclosureVerifyingKeyPath
+	}
+	return result, nil
+}
+
+// Sign an image with the camera's secret key and create its initial PCD proof.
+func prove(args []string, stderr io.Writer) (interface{}, error) {
+	flags := flag.NewFlagSet("prove", flag.ExitOnError)
+	keys := flags.String("keys", ".", "directory holding the keys written by keygen")
+	imagePath := flags.String("image", "", "JSON encoded image, or JPEG, PNG, TIFF, WebP or DNG file (default: all white image)")
+	out := flags.String("out", "proof.json", "file to write the proof into")
+	compress := flags.String("compress", string(prover.Uncompressed), "compression of the proof file: none, gzip or zstd")
+	workers := flags.Int("workers", 0, "number of CPUs to prove with (default: all)")
+	gpu := flags.Bool("gpu", false, "prove groth16 proofs on the GPU, with a build made with -tags icicle")
+	witness := flags.String("witness", "", "also write the full witness of the proof into this file, to debug a proof that fails; it holds the secret inputs (default: none)")
+	flags.Parse(args)
+
+	opts, err := proveOptions(stderr, *workers, *gpu)
+	if err != nil {
+		return nil, err
+	}
+	witnessOpts, closeWitness, err := witnessOption(*witness)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWitness()
+	opts = append(opts, witnessOpts...)
+
+	compression, err := prover.ParseCompression(*compress)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := readImage(*imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pk_pp gen.PK_PP
+	var vk_pp gen.VK_PP
+	var sk_pp gen.SK_PP
+	if err := readJSON(filepath.Join(*keys, provingKeyFile), &pk_pp); err != nil {
+		return nil, err
+	}
+	if err := readJSON(filepath.Join(*keys, verifyingKeyFile), &vk_pp); err != nil {
+		return nil, err
+	}
+	if err := readJSON(filepath.Join(*keys, secretKeyFile), &sk_pp); err != nil {
+		return nil, err
+	}
+
+	counter, err := nextCounter(*keys)
+	if err != nil {
+		return nil, err
+	}
+
+	signedImage := image.Sign(sk_pp.SecretKey, counter, big.NewInt(0))
+	z := myImage.Z{Image: image, PublicKey: pk_pp.PublicKey}
+
+	proof := prover.Prover(pk_pp, vk_pp.VerifyingKey, prover.NewSignedProof(z, signedImage, counter), myTransformations.Transformation{
+		T:      myTransformations.Identity,
+		Params: nil,
+	}, opts...)
+	if proof.PCDProof() == nil {
+		return nil, fmt.Errorf("could not create a PCD proof")
+	}
+
+	if err := writeProof(*out, proof, compression); err != nil {
+		return nil, err
+	}
+	return map[string]string{"proof": *out, "nonce": proof.Nonce().String(), "nullifier": proof.Nullifier().String()}, nil
+}
+
+// Crop the image carried by a proof and create the PCD proof of the edited image.
+func edit(args []string, stderr io.Writer) (interface{}, error) {
+	flags := flag.NewFlagSet("edit", flag.ExitOnError)
+	keys := flags.String("keys", ".", "directory holding the keys written by keygen")
+	in := flags.String("proof", "proof.json", "proof of the image to edit")
+	crop := flags.String("crop", "", "area to crop, as x0,y0,x1,y1")
+	out := flags.String("out", "edited.json", "file to write the new proof into")
+	compress := flags.String("compress", string(prover.Uncompressed), "compression of the proof file: none, gzip or zstd")
+	workers := flags.Int("workers", 0, "number of CPUs to prove with (default: all)")
+	gpu := flags.Bool("gpu", false, "prove groth16 proofs on the GPU, with a build made with -tags icicle")
+	preview := flags.Int("preview", 0, "preview the image before and after the edit on stderr, downscaled this many times (default: no preview)")
+	witness := flags.String("witness", "", "also write the full witness of the new proof into this file, to debug a proof that fails; it holds the secret inputs (default: none)")
+	flags.Parse(args)
+
+	opts, err := proveOptions(stderr, *workers, *gpu)
+	if err != nil {
+		return nil, err
+	}
+	witnessOpts, closeWitness, err := witnessOption(*witness)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWitness()
+	opts = append(opts, witnessOpts...)
+
+	compression, err := prover.ParseCompression(*compress)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := parseCrop(*crop)
+	if err != nil {
+		return nil, err
+	}
+
+	var pk_pp gen.PK_PP
+	var vk_pp gen.VK_PP
+	var sk_pp gen.SK_PP
+	if err := readJSON(filepath.Join(*keys, provingKeyFile), &pk_pp); err != nil {
+		return nil, err
+	}
+	if err := readJSON(filepath.Join(*keys, verifyingKeyFile), &vk_pp); err != nil {
+		return nil, err
+	}
+	// The edit is signed with the editor key, which is kept secret with the secret key
+	if err := readJSON(filepath.Join(*keys, secretKeyFile), &sk_pp); err != nil {
+		return nil, err
+	}
+	if sk_pp.EditorKey == nil {
+		return nil, fmt.Errorf("%s holds no editor key: create the keys with keygen again", secretKeyFile)
+	}
+	opts = append(opts, backend.WithEditorKey(sk_pp.EditorKey))
+	proof, err := readProof(*in)
+	if err != nil {
+		return nil, err
+	}
+
+	if *preview > 0 {
+		fmt.Fprintln(stderr, "Before:")
+		proof.Z().Image.Preview(stderr, *preview)
+	}
+	edited := editor.EditorCrop(pk_pp, vk_pp.VerifyingKey, proof, params, opts...)
+	if edited.PCDProof() == nil {
+		return nil, fmt.Errorf("could not create a PCD proof")
+	}
+	if *preview > 0 {
+		fmt.Fprintln(stderr, "After:")
+		edited.Z().Image.Preview(stderr, *preview)
+	}
+
+	if err := writeProof(*out, edited, compression); err != nil {
+		return nil, err
+	}
+	return map[string]string{"proof": *out, "nonce": edited.Nonce().String(), "prevProofHash": edited.PrevProofHash().String(), "nullifier": edited.Nullifier().String(), "hops": strconv.Itoa(edited.Z().Hops)}, nil
+}
+
+// Disclose a region of the original image of a proof, without the rest of the image.
+func disclose(args []string, stderr io.Writer) (interface{}, error) {
+	flags := flag.NewFlagSet("disclose", flag.ExitOnError)
+	keys := flags.String("keys", ".", "directory holding the disclosure keys written by keygen -disclosure")
+	in := flags.String("proof", "proof.json", "proof of the original image, as written by prove")
+	crop := flags.String("crop", "", "area to disclose, as x0,y0,x1,y1")
+	out := flags.String("out", "disclosure.json", "file to write the disclosure into")
+	workers := flags.Int("workers", 0, "number of CPUs to prove with (default: all)")
+	gpu := flags.Bool("gpu", false, "prove groth16 proofs on the GPU, with a build made with -tags icicle")
+	witness := flags.String("witness", "", "also write the full witness of the disclosure into this file, to debug a proof that fails; it holds the secret inputs (default: none)")
+	flags.Parse(args)
+
+	opts, err := proveOptions(stderr, *workers, *gpu)
+	if err != nil {
+		return nil, err
+	}
+	witnessOpts, closeWitness, err := witnessOption(*witness)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWitness()
+	opts = append(opts, witnessOpts...)
+
+	params, err := parseCrop(*crop)
+	if err != nil {
+		return nil, err
+	}
+
+	var pk_disclosure gen.PK_PP
+	if err := readJSON(filepath.Join(*keys, disclosureProvingKeyFile), &pk_disclosure); err != nil {
+		return nil, err
+	}
+	proof, err := readProof(*in)
+	if err != nil {
+		return nil, err
+	}
+
+	disclosure, err := prover.Disclose(pk_disclosure, proof, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeJSON(*out, disclosure, 0o644); err != nil {
+		return nil, err
+	}
+	return map[string]string{"disclosure": *out, "nonce": disclosure.Nonce().String()}, nil
+}
+
+// Report the constraints, wires, public inputs and estimated proving memory of the compliance predicate keygen
+// generates keys for, compiled for the images of this build, or estimated for images of -width x -height pixels.
+func stats(args []string, stderr io.Writer) (interface{}, error) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	backendID := flags.String("backend", string(backend.Default.ID()), "proving system: groth16 or plonk")
+	public := flags.String("public", "", "parameters of the crops to disclose, comma separated, as for keygen (default: none)")
+	width := flags.Int("width", myImage.Width, "width of the images to estimate the predicate for")
+	height := flags.Int("height", myImage.Height, "height of the images to estimate the predicate for")
+	flags.Parse(args)
+
+	b, err := backend.Get(backend.ID(*backendID))
+	if err != nil {
+		return nil, err
+	}
+	if *width < 1 || *height < 1 {
+		return nil, fmt.Errorf("invalid image size %dx%d", *width, *height)
+	}
+	t := myTransformations.Transformation{T: myTransformations.Identity, Params: map[string]int{}}
+	if *public != "" {
+		t.Public = strings.Split(*public, ",")
+	}
+	statistics, err := gen.Statistics(b, t)
+	if err != nil {
+		return nil, err
+	}
+	return statistics.Scale(*width, *height), nil
+}
+
+// Write a universal SRS the plonk keys of every compliance predicate can be set up from, for development: the
+// process that generates it could have kept its toxic waste. Production keys use the SRS of a ceremony.
+func srs(args []string, stderr io.Writer) (interface{}, error) {
+	flags := flag.NewFlagSet("srs", flag.ExitOnError)
+	out := flags.String("out", "srs.bin", "file to write the SRS into")
+	size := flags.Int("size", 1<<20+backend.BlindingPoints, fmt.Sprintf("number of powers of tau, at least the number of constraints and public inputs of the largest predicate, rounded up to a power of two, plus %d", backend.BlindingPoints))
+	flags.Parse(args)
+
+	universal, err := backend.NewSRS(*size)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	if _, err := universal.WriteTo(writer); err != nil {
+		return nil, err
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"srs": *out, "size": *size}, nil
+}
+
+// Write the image carried by a proof as a JPEG file of the given quality.
+func export(args []string, stderr io.Writer) (interface{}, error) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	in := flags.String("proof", "proof.json", "proof of the image to export")
+	out := flags.String("out", "image.jpg", "JPEG file to write the image into")
+	quality := flags.Int("quality", myImage.DefaultJPEGQuality, "JPEG quality, from 1 (smallest) to 100 (best)")
+	flags.Parse(args)
+
+	proof, err := readProof(*in)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded bytes.Buffer
+	if err := proof.Z().Image.EncodeJPEG(&encoded, *quality); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(*out, encoded.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"image": *out, "quality": *quality}, nil
+}
+
+// Verify a proof against the verifying key.
+func verify(args []string, stderr io.Writer) (interface{}, error) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	keys := flags.String("keys", ".", "directory holding the keys written by keygen")
+	in := flags.String("proof", "proof.json", "proof to verify")
+	nonce := flags.String("nonce", "", "nonce the proof must hold for, as printed by prove or edit (default: any)")
+	chain := flags.String("chain", "", "verify the complete edit history instead of -proof: its proofs in order, original first, separated by commas")
+	disclosure := flags.String("disclosure", "", "verify a disclosure written by disclose instead of -proof")
+	enqueue := flags.String("enqueue", "", "check the statement of -proof and write its verification job into this file instead of verifying it")
+	job := flags.String("job", "", "verify a job written by -enqueue instead of -proof")
+	flags.Parse(args)
+
+	if *disclosure != "" {
+		return verifyDisclosure(*keys, *disclosure, *nonce)
+	}
+
+	var vk_pp gen.VK_PP
+	if err := readJSON(filepath.Join(*keys, verifyingKeyFile), &vk_pp); err != nil {
+		return nil, err
+	}
+
+	// A job holds the public witness of its statement, and no image or nonce to check
+	if *job != "" {
+		var queued verifier.Job
+		if err := readJSON(*job, &queued); err != nil {
+			return nil, err
+		}
+		if !verifier.VerifyJob(vk_pp, queued) {
+			return nil, fmt.Errorf("%s did not pass verification", *job)
+		}
+		return map[string]bool{"verified": true}, nil
+	}
+
+	paths := []string{*in}
+	if *chain != "" {
+		paths = strings.Split(*chain, ",")
+	}
+	proofs := make([]prover.Proof, len(paths))
+	for i, path := range paths {
+		proof, err := readProof(strings.TrimSpace(path))
+		if err != nil {
+			return nil, err
+		}
+		proofs[i] = proof
+	}
+
+	// Every proof of an edit history holds for the nonce of its original image
+	if *nonce != "" {
+		expected, ok := new(big.Int).SetString(*nonce, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid -nonce %q", *nonce)
+		}
+		if !verifier.VerifierWithNonce(vk_pp, proofs[0], expected) {
+			return nil, fmt.Errorf("%s did not pass verification", paths[0])
+		}
+	}
+
+	if *enqueue != "" {
+		queued, err := verifier.NewJob(vk_pp, proofs[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", *in, err)
+		}
+		if err := writeJSON(*enqueue, queued, 0o644); err != nil {
+			return nil, err
+		}
+		return map[string]string{"job": *enqueue}, nil
+	}
+
+	if *chain != "" {
+		if !verifier.VerifyChain(vk_pp, proofs) {
+			return nil, fmt.Errorf("the edit history %s did not pass verification", *chain)
+		}
+	} else if !verifier.Verifier(vk_pp, proofs[0]) {
+		return nil, fmt.Errorf("%s did not pass verification", *in)
+	}
+	return map[string]bool{"verified": true}, nil
+}
+
+// Verify a disclosure against the disclosure verifying key, and optionally its capture counter.
+func verifyDisclosure(keys string, path string, nonce string) (interface{}, error) {
+	var vk_disclosure gen.VK_PP
+	if err := readJSON(filepath.Join(keys, disclosureVerifyingKeyFile), &vk_disclosure); err != nil {
+		return nil, err
+	}
+	var disclosure prover.Disclosure
+	if err := readJSON(path, &disclosure); err != nil {
+		return nil, err
+	}
+
+	if nonce != "" && disclosure.Nonce().String() != nonce {
+		return nil, fmt.Errorf("%s discloses capture %s, not %s", path, disclosure.Nonce(), nonce)
+	}
+	if !verifier.VerifyDisclosure(vk_disclosure, disclosure) {
+		return nil, fmt.Errorf("%s did not pass verification", path)
+	}
+	return map[string]bool{"verified": true}, nil
+}
+
+// Read a JSON encoded image, or an image file of any format image.Decode detects, or return the all white
+// image if no path is given.
+func readImage(path string) (myImage.I, error) {
+	if path == "" {
+		return myImage.AllWhiteImage(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return myImage.I{}, err
+	}
+
+	var image myImage.I
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = json.Unmarshal(data, &image)
+	} else {
+		image, _, err = myImage.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return myImage.I{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return image, nil
+}
+
+// Parse crop parameters given as "x0,y0,x1,y1".
+func parseCrop(value string) (map[string]int, error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid -crop %q: expected x0,y0,x1,y1", value)
+	}
+
+	params := make(map[string]int)
+	for i, name := range []string{"x0", "y0", "x1", "y1"} {
+		v, err := strconv.Atoi(strings.TrimSpace(fields[i]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid -crop %q: %w", value, err)
+		}
+		params[name] = v
+	}
+
+	return params, nil
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Increment the capture counter kept in the keys directory and return it. The counter is written before
+// the picture is proven, so that a failed proof does not leave its nonce to be used again.
+func nextCounter(keys string) (*big.Int, error) {
+	path := filepath.Join(keys, counterFile)
+
+	var counter uint64
+	if err := readJSON(path, &counter); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	counter++
+
+	if err := writeJSON(path, counter, 0o600); err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(counter), nil
+}
+
+// Report the phases and errors of the proof on stderr, prove on the GPU if gpu is set, and cap the CPUs used
+// for proving at workers, unless workers is 0. A CLI process only creates one proof, so the whole process is
+// capped, including the parts of the prover that backend.WithWorkers cannot cap.
+func proveOptions(stderr io.Writer, workers int, gpu bool) ([]backend.ProveOption, error) {
+	opts := []backend.ProveOption{backend.WithOutput(stderr), progressOption(stderr)}
+	if gpu {
+		// A build without GPU support fails here, rather than in the middle of the Prover
+		opts = append(opts, backend.WithGPU())
+		if _, err := backend.NewProverOptions(opts...); err != nil {
+			return nil, err
+		}
+	}
+	if workers == 0 {
+		return opts, nil
+	}
+	if workers < 0 {
+		return nil, fmt.Errorf("invalid number of workers: %d", workers)
+	}
+	runtime.GOMAXPROCS(workers)
+	return append(opts, backend.WithWorkers(workers)), nil
+}
+
+// Print each phase of the proof on stderr as it starts, and the time it took once the next one starts, so
+// that a proof that takes minutes tells what it is doing.
+func progressOption(stderr io.Writer) backend.ProveOption {
+	var last backend.Progress
+	return backend.WithProgress(func(progress backend.Progress) {
+		if last.Phase != "" {
+			fmt.Fprintf(stderr, "%s took %v\n", last.Phase, progress.Time.Sub(last.Time).Round(time.Millisecond))
+		}
+		if progress.Phase != backend.PhaseDone {
+			fmt.Fprintf(stderr, "%s...\n", progress.Phase)
+		}
+		last = progress
+	})
+}
+
+// Write the full witness of the proof into path, for debugging, unless path is empty. The returned function
+// closes the file once the proof is created.
+func witnessOption(path string) ([]backend.ProveOption, func(), error) {
+	if path == "" {
+		return nil, func() {}, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []backend.ProveOption{backend.WithWitness(file)}, func() { file.Close() }, nil
+}
+
+// Read a proof container, compressed or not.
+func readProof(path string) (prover.Proof, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return prover.Proof{}, err
+	}
+	defer file.Close()
+
+	proof, err := prover.ReadContainer(file)
+	if err != nil {
+		return prover.Proof{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return proof, nil
+}
+
+// Write a proof container with the given compression.
+func writeProof(path string, proof prover.Proof, compression prover.Compression) error {
+	var container bytes.Buffer
+	if err := prover.WriteContainer(&container, proof, compression); err != nil {
+		return err
+	}
+	return os.WriteFile(path, container.Bytes(), 0o644)
+}
+
+func writeJSON(path string, v interface{}, perm os.FileMode) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
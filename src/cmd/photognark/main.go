@@ -0,0 +1,74 @@
+// Command photognark wraps the Generator, Prover, Editor and Verifier functions.
+//
+// Usage:
+//
+//	photognark keygen   -keys DIR [-image FILE] [-backend groth16|plonk] [-srs SRS] [-profile PPROF] [-disclosure]
+//	photognark srs      -out SRS [-size N]
+//	photognark stats    [-backend groth16|plonk] [-public PARAMS] [-width W -height H]
+//	photognark prove    -keys DIR [-image FILE] -out PROOF [-compress none|gzip|zstd] [-workers N] [-gpu] [-witness FILE]
+//	photognark edit     -keys DIR -proof PROOF -crop x0,y0,x1,y1 -out PROOF [-compress none|gzip|zstd] [-workers N] [-gpu] [-preview N] [-witness FILE]
+//	photognark disclose -keys DIR -proof PROOF -crop x0,y0,x1,y1 -out DISCLOSURE [-workers N] [-gpu] [-witness FILE]
+//	photognark export   -proof PROOF -out FILE.jpg [-quality Q]
+//	photognark verify   -keys DIR (-proof PROOF | -chain PROOF,PROOF,... | -disclosure DISCLOSURE) [-nonce NONCE]
+//	photognark verify   -keys DIR (-proof PROOF -enqueue JOB | -job JOB)
+//
+// Every subcommand prints its result as a JSON object on stdout. Images are JSON encoded
+// image.I values (as returned by ToByte), or JPEG, PNG, TIFF, WebP and DNG files, whose format
+// is detected from their content; without -image the all white test image is used.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/consensys/gnark/logger"
+	"github.com/rs/zerolog"
+)
+
+// Subcommands, by name. Each reports its progress to stderr and returns the result run prints on stdout.
+var commands = map[string]func(args []string, stderr io.Writer) (interface{}, error){
+	"keygen":   keygen,
+	"prove":    prove,
+	"edit":     edit,
+	"disclose": disclose,
+	"srs":      srs,
+	"stats":    stats,
+	"export":   export,
+	"verify":   verify,
+}
+
+func main() {
+	// The Prover reports to the stderr it is given, but the Generator and Verifier print their errors with
+	// fmt.Println, and gnark's logger holds on to the stdout it was created with: send both to stderr so that
+	// stdout only carries the JSON result.
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	logger.Set(logger.Logger().Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "15:04:05"}))
+
+	os.Exit(run(os.Args[1:], stdout, os.Stderr))
+}
+
+// Run the subcommand named by args[0] with the flags that follow it, print its result as JSON on stdout and
+// return the exit code of the command.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 1 || commands[args[0]] == nil {
+		fmt.Fprintln(stderr, "usage: photognark keygen|srs|stats|prove|edit|disclose|export|verify [flags]")
+		return 2
+	}
+
+	result, err := commands[args[0]](args[1:], stderr)
+	if err != nil {
+		result = map[string]string{"error": err.Error()}
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+
+	if err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"src/backend"
+)
+
+// Run photognark with args, fail the test unless it exits with code, and return its JSON result.
+func runCommand(t *testing.T, code int, args ...string) map[string]interface{} {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if got := run(args, &stdout, &stderr); got != code {
+		t.Fatalf("photognark %s exited with %d, not %d: %s%s", strings.Join(args, " "), got, code, stdout.String(), stderr.String())
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("photognark %s printed %q: %v", strings.Join(args, " "), stdout.String(), err)
+	}
+	return result
+}
+
+func TestUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"unknown"}, &stdout, &stderr); code != 2 {
+		t.Errorf("an unknown subcommand exited with %d, not 2", code)
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), "usage") {
+		t.Errorf("an unknown subcommand printed %q on stdout and %q on stderr", stdout.String(), stderr.String())
+	}
+}
+
+func TestSRS(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "srs.bin")
+	result := runCommand(t, 0, "srs", "-out", out, "-size", fmt.Sprint(1<<4+backend.BlindingPoints))
+	if result["srs"] != out {
+		t.Errorf("srs returned %v", result)
+	}
+	file, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := backend.ReadSRS(file); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	result := runCommand(t, 0, "stats", "-width", "32", "-height", "24")
+	if constraints, ok := result["constraints"].(float64); !ok || constraints <= 0 {
+		t.Errorf("stats returned %v", result)
+	}
+}
+
+// The subcommands of a camera, an editor and a verifier, each run on the files the previous ones wrote.
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	keys := filepath.Join(dir, "keys")
+	proof := filepath.Join(dir, "proof.json")
+	cropped := filepath.Join(dir, "cropped.json")
+	disclosure := filepath.Join(dir, "disclosure.json")
+
+	t.Run("keygen", func(t *testing.T) {
+		result := runCommand(t, 0, "keygen", "-keys", keys, "-disclosure")
+		for _, file := range []string{provingKeyFile, verifyingKeyFile, secretKeyFile, disclosureProvingKeyFile, disclosureVerifyingKeyFile} {
+			if _, err := os.Stat(filepath.Join(keys, file)); err != nil {
+				t.Errorf("keygen returned %v: %v", result, err)
+			}
+		}
+	})
+
+	var nonce string
+	t.Run("prove", func(t *testing.T) {
+		result := runCommand(t, 0, "prove", "-keys", keys, "-out", proof, "-compress", "gzip")
+		nonce, _ = result["nonce"].(string)
+		if result["proof"] != proof || nonce == "" {
+			t.Errorf("prove returned %v", result)
+		}
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		result := runCommand(t, 0, "edit", "-keys", keys, "-proof", proof, "-crop", "3,3,6,6", "-out", cropped)
+		if result["proof"] != cropped || result["nonce"] != nonce || result["hops"] != "1" {
+			t.Errorf("edit returned %v", result)
+		}
+	})
+
+	t.Run("disclose", func(t *testing.T) {
+		result := runCommand(t, 0, "disclose", "-keys", keys, "-proof", proof, "-crop", "3,3,6,6", "-out", disclosure)
+		if result["disclosure"] != disclosure || result["nonce"] != nonce {
+			t.Errorf("disclose returned %v", result)
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		out := filepath.Join(dir, "cropped.jpg")
+		runCommand(t, 0, "export", "-proof", cropped, "-out", out)
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(data, []byte{0xff, 0xd8}) {
+			t.Errorf("export wrote no JPEG file")
+		}
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		runCommand(t, 0, "verify", "-keys", keys, "-proof", proof, "-nonce", nonce)
+		runCommand(t, 0, "verify", "-keys", keys, "-chain", proof+","+cropped)
+		runCommand(t, 0, "verify", "-keys", keys, "-disclosure", disclosure, "-nonce", nonce)
+
+		// The JSON result carries the error of a proof that does not pass
+		result := runCommand(t, 1, "verify", "-keys", keys, "-proof", cropped, "-nonce", "1"+nonce)
+		if _, ok := result["error"]; !ok {
+			t.Errorf("verify returned %v for another nonce", result)
+		}
+	})
+}
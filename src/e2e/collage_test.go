@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"encoding/json"
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	"src/editor"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	"src/verifier"
+)
+
+// A diptych of a picture and an edit of it verifies with the proofs of both, and only as composed.
+func TestCollage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("proves several images, each with keys of its own")
+	}
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	pk_pp, vk_pp := secureCamera.CameraGenerator()
+	original := secureCamera.CameraProver()
+	edited := editor.EditorCrop(pk_pp, vk_pp.VerifyingKey, original, map[string]int{"x0": 4, "y0": 4, "x1": 11, "y1": 11}, backend.WithEditorKey(secureCamera.CameraEditorKey()))
+
+	pk_collage, vk_collage, err := gen.CollageGenerator(backend.Default, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prover.ProveCollage(pk_collage, 1, 2, []prover.Proof{original}); err == nil {
+		t.Fatal("a 1 x 2 collage of one input was proven")
+	}
+	collage, err := prover.ProveCollage(pk_collage, 1, 2, []prover.Proof{original, edited})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var published prover.Collage
+	remarshal(t, collage, &published)
+	vk_inputs := []gen.VK_PP{vk_pp, vk_pp}
+	if !verifier.VerifyCollage(vk_collage, vk_inputs, published) {
+		t.Fatal("the collage did not pass verification")
+	}
+	if verifier.VerifyCollage(vk_collage, vk_inputs[:1], published) {
+		t.Error("the collage passed verification without the keys of all inputs")
+	}
+
+	// Tampering with the collage, its grid or its inputs fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var image myImage.I
+			remarshal(t, encoded["image"], &image)
+			pixel := image.GetPixel(0, 0)
+			pixel.R ^= 0xff
+			image.SetPixel(0, 0, pixel)
+			encoded["image"] = image
+		},
+		"grid": func(encoded map[string]interface{}) {
+			encoded["rows"], encoded["cols"] = 2, 1
+		},
+		"order": func(encoded map[string]interface{}) {
+			inputs := encoded["inputs"].([]interface{})
+			inputs[0], inputs[1] = inputs[1], inputs[0]
+		},
+		"input": func(encoded map[string]interface{}) {
+			encoded["inputs"].([]interface{})[1] = encoded["inputs"].([]interface{})[0]
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, published, &fields)
+		change(fields)
+		data, err := json.Marshal(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tampered prover.Collage
+		if err := json.Unmarshal(data, &tampered); err != nil {
+			continue
+		}
+		if verifier.VerifyCollage(vk_collage, vk_inputs, tampered) {
+			t.Errorf("a collage with a tampered %s passed verification", name)
+		}
+	}
+}
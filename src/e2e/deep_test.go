@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	"src/verifier"
+)
+
+// An area of the camera's deep color capture verifies without the capture, and only with its area.
+func TestDeep(t *testing.T) {
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	capture, captureSignature := secureCamera.CameraDeep()
+
+	pk_deep, vk_deep, err := gen.DeepGenerator(backend.Default, vk_pp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	area := map[string]int{"x0": 2, "y0": 2, "x1": 12, "y1": 10}
+	other := capture
+	other.Pixels[0][0].R--
+	if _, err := prover.ProveDeep(pk_deep, other, captureSignature, secureCamera.Counter(), area); err == nil {
+		t.Fatal("a deep color capture the camera did not sign was proven")
+	}
+	deep, err := prover.ProveDeep(pk_deep, capture, captureSignature, secureCamera.Counter(), area)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var published prover.DeepImage
+	remarshal(t, deep, &published)
+	if !verifier.VerifyDeep(vk_deep, published) {
+		t.Fatal("the deep color image did not pass verification")
+	}
+
+	// Tampering with the image, its area or its capture fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var image myImage.Deep
+			remarshal(t, encoded["image"], &image)
+			image.Pixels[3][4].B ^= 1
+			encoded["image"] = image
+		},
+		"area": func(encoded map[string]interface{}) {
+			encoded["area"] = map[string]int{"x0": 3, "y0": 2, "x1": 13, "y1": 10}
+		},
+		"nonce": func(encoded map[string]interface{}) {
+			encoded["nonce"] = "2"
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, published, &fields)
+		change(fields)
+		var tampered prover.DeepImage
+		remarshal(t, fields, &tampered)
+		if verifier.VerifyDeep(vk_deep, tampered) {
+			t.Errorf("a deep color image with a tampered %s passed verification", name)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package e2e
+
+import (
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+	"src/verifier"
+)
+
+// A photo developed from the camera's RAW capture verifies without the capture, and only with its gains.
+func TestDevelop(t *testing.T) {
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	raw, rawSignature := secureCamera.CameraRAW()
+
+	pk_develop, vk_develop, err := gen.DevelopGenerator(backend.Default, vk_pp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gains := [3]int{myTransformations.GainOne * 3 / 2, myTransformations.GainOne, myTransformations.GainOne * 5 / 4}
+	other := raw
+	other.Samples[0][0]--
+	if _, err := prover.ProveDevelopment(pk_develop, other, rawSignature, secureCamera.Counter(), gains); err == nil {
+		t.Fatal("a RAW capture the camera did not sign was developed")
+	}
+	development, err := prover.ProveDevelopment(pk_develop, raw, rawSignature, secureCamera.Counter(), gains)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var published prover.Development
+	remarshal(t, development, &published)
+	if !verifier.VerifyDevelopment(vk_develop, published) {
+		t.Fatal("the development did not pass verification")
+	}
+
+	// Tampering with the developed image, its gains or its capture fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var image myImage.I
+			remarshal(t, encoded["image"], &image)
+			pixel := image.GetPixel(2, 9)
+			pixel.R ^= 1
+			image.SetPixel(2, 9, pixel)
+			encoded["image"] = image
+		},
+		"gains": func(encoded map[string]interface{}) {
+			encoded["gains"] = []int{myTransformations.GainOne, myTransformations.GainOne, myTransformations.GainOne}
+		},
+		"nonce": func(encoded map[string]interface{}) {
+			encoded["nonce"] = "2"
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, published, &fields)
+		change(fields)
+		var tampered prover.Development
+		remarshal(t, fields, &tampered)
+		if verifier.VerifyDevelopment(vk_develop, tampered) {
+			t.Errorf("a development with a tampered %s passed verification", name)
+		}
+	}
+}
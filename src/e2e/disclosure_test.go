@@ -0,0 +1,92 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	"src/verifier"
+)
+
+// A region disclosed from a picture verifies without the picture, and only as published.
+func TestDisclosure(t *testing.T) {
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	original := secureCamera.CameraProver()
+
+	pk_disclosure, vk_disclosure, err := gen.DisclosureGenerator(backend.Default, vk_pp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disclosure, err := prover.Disclose(pk_disclosure, original, map[string]int{"x0": 3, "y0": 4, "x1": 6, "y1": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The published artifact reveals neither the original's metadata nor its signature
+	encoded, err := json.Marshal(disclosure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encoded, []byte(myImage.AllWhiteImage().M.Author)) {
+		t.Error("the disclosure reveals the metadata of the original")
+	}
+	signature, _ := json.Marshal(original.ImageSignature())
+	if bytes.Contains(encoded, signature) {
+		t.Error("the disclosure reveals the signature of the original")
+	}
+
+	var published prover.Disclosure
+	if err := json.Unmarshal(encoded, &published); err != nil {
+		t.Fatal(err)
+	}
+	if !verifier.VerifyDisclosure(vk_disclosure, published) {
+		t.Fatal("the disclosure did not pass verification")
+	}
+	if published.Nonce().Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("the disclosure holds for capture %v, expected 1", published.Nonce())
+	}
+
+	// Tampering with the published region, its size or its capture fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var region myImage.I
+			remarshal(t, encoded["region"], &region)
+			region.SetPixel(0, 0, myImage.RGBPixel{R: 254, G: 255, B: 255})
+			encoded["region"] = region
+		},
+		"size": func(encoded map[string]interface{}) {
+			var region myImage.I
+			remarshal(t, encoded["region"], &region)
+			region.M.Width = 3
+			encoded["region"] = region
+		},
+		"nonce": func(encoded map[string]interface{}) {
+			encoded["nonce"] = "2"
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, published, &fields)
+		change(fields)
+		var tampered prover.Disclosure
+		remarshal(t, fields, &tampered)
+		if verifier.VerifyDisclosure(vk_disclosure, tampered) {
+			t.Errorf("a disclosure with a tampered %s passed verification", name)
+		}
+	}
+
+	// Another camera did not take the picture
+	_, otherKey, _, _ := gen.Sign(myImage.AllWhiteImage(), big.NewInt(1), big.NewInt(0))
+	vk_other := vk_disclosure
+	vk_other.PublicKey = otherKey
+	if verifier.VerifyDisclosure(vk_other, published) {
+		t.Error("the disclosure passed verification for another camera")
+	}
+}
@@ -0,0 +1,3 @@
+// Package e2e holds end-to-end scenario tests, which script a secure camera, editors and a
+// verifier together and check the verifier's verdict.
+package e2e
@@ -0,0 +1,5 @@
+// Package edits holds the end-to-end tests of the edits of a single image, e.g. rotations, flips and
+// photometric adjustments: keys generated for the edit, an original image proven with them, its edits proven
+// by an editor, and the edit history verified. Each compliance predicate needs its own setup, so these are
+// kept apart from the scenarios of package e2e, and the tests of each package run within their own timeout.
+package edits
@@ -0,0 +1,693 @@
+package edits
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"src/backend"
+	"src/editor"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+	"src/verifier"
+)
+
+// An edit of the proof of an image, made by an editor function, e.g. editor.EditorGain with the gains to apply.
+type edit func(pk_pp gen.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof
+
+// An edit case: keys generated for a transformation of a picture, the edits proven one after the other from the
+// proof of the original picture, and what the edit history they make must be.
+type editCase struct {
+	name    string
+	picture myImage.I
+	keys    myTransformations.Transformation // transformation the keys are generated for
+	circuit myTransformations.CircuitID      // compliance predicate of the keys, if set
+	edits   []edit
+
+	want    func(picture myImage.I) (myImage.I, error) // image of the last edit, if set
+	changes bool                                       // whether the last edit changes the pixels of the picture
+	params  []map[string]int                           // some public parameters of each proof, the original's first
+	tamper  [2]string                                  // a parameter of the last proof, and another value it does not hold for
+	reject  edit                                       // an edit of the last proof the keys do not prove
+	why     string                                     // what reject would have proven
+	check   func(t *testing.T, vk_pp gen.VK_PP, picture myImage.I, history []prover.Proof)
+}
+
+// TestEdits proves the edit history of every edit case with keys generated for it, and verifies it.
+func TestEdits(t *testing.T) {
+	if testing.Short() {
+		t.Skip("proves several images, each with keys of its own")
+	}
+	for _, c := range editCases(t) {
+		t.Run(c.name, func(t *testing.T) {
+			pk_pp, vk_pp, sk_pp, err := gen.Generator(c.picture, c.keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.circuit != (myTransformations.CircuitID{}) && vk_pp.Circuit != c.circuit {
+				t.Fatalf("the keys are for circuit %s, expected %s", vk_pp.Circuit, c.circuit)
+			}
+
+			signed := prover.NewSignedProof(myImage.Z{Image: c.picture, PublicKey: pk_pp.PublicKey}, c.picture.Sign(sk_pp.SecretKey, big.NewInt(1), big.NewInt(0)), big.NewInt(1))
+			history := []prover.Proof{prover.Prover(pk_pp, vk_pp.VerifyingKey, signed, myTransformations.Transformation{T: myTransformations.Identity})}
+			for _, edit := range c.edits {
+				history = append(history, edit(pk_pp, vk_pp.VerifyingKey, history[len(history)-1], backend.WithEditorKey(sk_pp.EditorKey)))
+			}
+			last := history[len(history)-1]
+
+			if c.want != nil {
+				expected, err := c.want(c.picture)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if image := last.Z().Image; image.Pixels != expected.Pixels || image.M.Width != expected.M.Width || image.M.Height != expected.M.Height {
+					t.Fatal("the edited image is not the picture edited outside of the circuit")
+				}
+			}
+			if c.changes && last.Z().Image.Pixels == c.picture.Pixels {
+				t.Fatal("the edit did not change the picture")
+			}
+			for i, params := range c.params {
+				for name, value := range params {
+					if got := history[i].Params()[name]; got != value {
+						t.Errorf("proof %d holds for %s %d, expected %d", i, name, got, value)
+					}
+				}
+			}
+			if !verifier.VerifyChain(vk_pp, history) {
+				t.Fatal("the edit history did not pass verification")
+			}
+
+			// The parameters are stored with the proof, and a proof does not hold for others
+			if c.tamper != [2]string{} {
+				encoded, err := json.Marshal(last)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var decoded prover.Proof
+				if err := json.Unmarshal(encoded, &decoded); err != nil || !verifier.Verifier(vk_pp, decoded) {
+					t.Fatalf("the decoded proof did not pass verification: %v", err)
+				}
+				if !bytes.Contains(encoded, []byte(c.tamper[0])) {
+					t.Fatalf("the encoded proof does not hold %s", c.tamper[0])
+				}
+				var tampered prover.Proof
+				if err := json.Unmarshal(bytes.Replace(encoded, []byte(c.tamper[0]), []byte(c.tamper[1]), 1), &tampered); err != nil {
+					t.Fatal(err)
+				}
+				if verifier.Verifier(vk_pp, tampered) {
+					t.Errorf("a proof passed verification for %s instead of %s", c.tamper[1], c.tamper[0])
+				}
+			}
+
+			if c.reject != nil {
+				if rejected := c.reject(pk_pp, vk_pp.VerifyingKey, last, backend.WithEditorKey(sk_pp.EditorKey)); rejected.PCDProof() != nil {
+					t.Error(c.why + " was proven")
+				}
+			}
+			if c.check != nil {
+				c.check(t, vk_pp, c.picture, history)
+			}
+		})
+	}
+}
+
+// The edit cases, one for the keys of every edit of a single image.
+func editCases(t *testing.T) []editCase {
+	sub := func(img myImage.I, x0, y0, x1, y1 int) myImage.I {
+		t.Helper()
+		area, err := img.SubImage(x0, y0, x1, y1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return area
+	}
+	portrait := sub(myImage.CoordinateImage(), 2, 0, 11, myImage.Height-1)
+
+	credited := myImage.GradientImage()
+	credited.M.Author = "Jane Doe"
+	credited.M.Timestamp = time.Unix(1700000000, 0)
+	credited.M.DeviceID = "PhotoGnark test camera"
+
+	chain := []myTransformations.Transformation{
+		{T: myTransformations.Crop, Params: map[string]int{"x0": 2, "y0": 1, "x1": 13, "y1": 10}},
+		{T: myTransformations.Brightness, Params: map[string]int{"delta": 30}},
+		{T: myTransformations.Downscale},
+	}
+	chainT, err := myTransformations.ChainOf(chain...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	background := myImage.Rect{X0: 1, Y0: 1, X1: 12, Y1: 4}
+	frame := myImage.RGBPixel{R: 240, G: 230, B: 200}
+	face := myImage.Rect{X0: 3, Y0: 1, X1: 6, Y1: 4}
+	plate := myImage.Rect{X0: 8, Y0: 8, X1: 11, Y1: 9}
+	pixelated := myImage.Rect{X0: 2, Y0: 2, X1: 9, Y1: 7}
+	subject := myImage.Rect{X0: 3, Y0: 2, X1: 10, Y1: 8}
+	convolved := myImage.Rect{X0: 1, Y0: 1, X1: 12, Y1: 9}
+
+	// "PG", three pixels wide, over the first two rows of the strip
+	var credit myImage.Caption
+	for _, x := range []int{1, 2, 3, 5, 6, 7} {
+		credit[0][x] = true
+	}
+	for _, x := range []int{1, 3, 5} {
+		credit[1][x] = true
+	}
+	captioned := sub(myImage.GradientImage(), 0, 0, 13, 9)
+	bottom := captioned.M.Height - myImage.CaptionHeight
+
+	var logo myImage.Watermark
+	for j := range logo {
+		for i := range logo[j] {
+			logo[j][i] = myImage.RGBPixel{R: 200, G: uint8(40 * i), B: uint8(40 * j)}
+		}
+	}
+
+	// A picture mirrored, and back, is the picture again, and was mirrored in between
+	flipped := func(t *testing.T, vk_pp gen.VK_PP, picture myImage.I, history []prover.Proof) {
+		if history[1].Z().Image.Pixels == picture.Pixels {
+			t.Error("the picture was not mirrored")
+		}
+	}
+
+	return []editCase{
+		{
+			// A portrait picture rotated by quarter turns, and the keys of a Rotate prove nothing but rotations
+			name:    "rotate",
+			picture: portrait,
+			keys:    myTransformations.Transformation{T: myTransformations.Rotate},
+			circuit: myTransformations.RotateCircuitID,
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorRotate(pk, vk, proof, 1, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorRotate(pk, vk, proof, 1, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) { return picture.Rotate(2) },
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorCrop(pk, vk, proof, map[string]int{"x0": 0, "y0": 0, "x1": 5, "y1": 5}, opts...)
+			},
+			why: "a crop with the keys of a Rotate",
+		},
+		{
+			// A picture rotated and cropped in a single proof, twice
+			name:    "rotatecrop",
+			picture: portrait,
+			keys:    myTransformations.Transformation{T: myTransformations.RotateCrop},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorRotateCrop(pk, vk, proof, 1, map[string]int{"x0": 1, "y0": 2, "x1": 11, "y1": 8}, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorRotateCrop(pk, vk, proof, 2, map[string]int{"x0": 0, "y0": 0, "x1": 4, "y1": 3}, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) {
+				rotated, err := picture.Rotate(1)
+				if err != nil {
+					return myImage.I{}, err
+				}
+				if err := rotated.Crop(1, 2, 11, 8); err != nil {
+					return myImage.I{}, err
+				}
+				if rotated, err = rotated.Rotate(2); err != nil {
+					return myImage.I{}, err
+				}
+				return rotated, rotated.Crop(0, 0, 4, 3)
+			},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorRotate(pk, vk, proof, 2, opts...)
+			},
+			why: "a rotation with the keys of a RotateCrop",
+		},
+		{
+			// A picture mirrored left to right, and back
+			name:    "fliph",
+			picture: sub(myImage.CoordinateImage(), 1, 1, 12, 9),
+			keys:    myTransformations.Transformation{T: myTransformations.FlipH},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorFlipH(pk, vk, proof, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorFlipH(pk, vk, proof, opts...)
+				},
+			},
+			want:  func(picture myImage.I) (myImage.I, error) { return picture, nil },
+			check: flipped,
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorRotate(pk, vk, proof, 2, opts...)
+			},
+			why: "a rotation with the keys of a FlipH",
+		},
+		{
+			// A picture mirrored top to bottom, and back
+			name:    "flipv",
+			picture: sub(myImage.CoordinateImage(), 1, 1, 12, 9),
+			keys:    myTransformations.Transformation{T: myTransformations.FlipV},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorFlipV(pk, vk, proof, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorFlipV(pk, vk, proof, opts...)
+				},
+			},
+			want:  func(picture myImage.I) (myImage.I, error) { return picture, nil },
+			check: flipped,
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorFlipH(pk, vk, proof, opts...)
+			},
+			why: "a horizontal flip with the keys of a FlipV",
+		},
+		{
+			// A picture halved twice, the second time of an odd size
+			name:    "downscale",
+			picture: sub(myImage.CoordinateImage(), 0, 0, 9, 10),
+			keys:    myTransformations.Transformation{T: myTransformations.Downscale},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorDownscale(pk, vk, proof, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorDownscale(pk, vk, proof, opts...)
+				},
+			},
+			check: func(t *testing.T, vk_pp gen.VK_PP, picture myImage.I, history []prover.Proof) {
+				if m := history[2].Z().Image.M; history[1].Z().Image.M.Width != 5 || m.Width != 3 || m.Height != 3 {
+					t.Errorf("the picture was downscaled to %d x %d pixels", m.Width, m.Height)
+				}
+			},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorFlipH(pk, vk, proof, opts...)
+			},
+			why: "a flip with the keys of a Downscale",
+		},
+		{
+			// A picture brightened and then darkened, whose proofs hold for their public delta only
+			name:    "brightness",
+			picture: sub(myImage.CoordinateImage(), 1, 1, 12, 9),
+			keys:    myTransformations.Transformation{T: myTransformations.Brightness},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorBrightness(pk, vk, proof, 30, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorBrightness(pk, vk, proof, -60, opts...)
+				},
+			},
+			want:   func(picture myImage.I) (myImage.I, error) { return picture.Brighten(-30) },
+			params: []map[string]int{{"delta": 0}, {"delta": 30}, {"delta": -60}},
+			tamper: [2]string{`"delta":-60`, `"delta":-50`},
+		},
+		{
+			// A picture gamma corrected for display, and a gamma outside image.Gammas
+			name:    "gamma",
+			picture: sub(myImage.GradientImage(), 0, 0, 13, 10),
+			keys:    myTransformations.Transformation{T: myTransformations.Gamma},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorGamma(pk, vk, proof, 220, opts...)
+				},
+			},
+			want:   func(picture myImage.I) (myImage.I, error) { return picture.GammaCorrect(220) },
+			params: []map[string]int{{"gamma": 100}, {"gamma": 220}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorGamma(pk, vk, proof, 221, opts...)
+			},
+			why: "a gamma outside image.Gammas",
+		},
+		{
+			// A picture toned in sepia, and the keys of a Sepia prove no other edit
+			name:    "sepia",
+			picture: sub(myImage.NoiseImage(5), 0, 0, 11, 8),
+			keys:    myTransformations.Transformation{T: myTransformations.Sepia},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorSepia(pk, vk, proof, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) { return picture.Sepia(), nil },
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorBrightness(pk, vk, proof, 10, opts...)
+			},
+			why: "a brightness adjustment with the keys of a Sepia",
+		},
+		{
+			// A picture rotated in hue and desaturated, within the bounds of hue and saturation
+			name:    "huesaturation",
+			picture: sub(myImage.NoiseImage(6), 0, 0, 11, 8),
+			keys:    myTransformations.Transformation{T: myTransformations.HueSaturation},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorHueSaturation(pk, vk, proof, 210, 128, opts...)
+				},
+			},
+			want:   func(picture myImage.I) (myImage.I, error) { return picture.AdjustHueSaturation(210, 128) },
+			params: []map[string]int{{"hue": 0, "saturation": 256}, {"hue": 210, "saturation": 128}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorHueSaturation(pk, vk, proof, 0, myImage.MaxSaturation+1, opts...)
+			},
+			why: "a saturation past image.MaxSaturation",
+		},
+		{
+			// A picture white balanced and then exposed
+			name:    "gain",
+			picture: sub(myImage.GradientImage(), 2, 1, 12, 10),
+			keys:    myTransformations.Transformation{T: myTransformations.Gain},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorGain(pk, vk, proof, [3]int{300, 256, 200}, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorGain(pk, vk, proof, [3]int{320, 320, 320}, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) {
+				balanced, err := picture.ApplyGains([3]int{300, 256, 200})
+				if err != nil {
+					return myImage.I{}, err
+				}
+				return balanced.ApplyGains([3]int{320, 320, 320})
+			},
+			params: []map[string]int{nil, {"gain_r": 300, "gain_b": 200}},
+		},
+		{
+			// A picture of a document binarized for reading, and a threshold outside [0, image.MaxThreshold]
+			name:    "threshold",
+			picture: sub(myImage.GradientImage(), 1, 0, 14, 11),
+			keys:    myTransformations.Transformation{T: myTransformations.Threshold},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorThreshold(pk, vk, proof, 120, opts...)
+				},
+			},
+			want:   func(picture myImage.I) (myImage.I, error) { return picture.Binarize(120) },
+			params: []map[string]int{{"threshold": myImage.MaxThreshold + 1}, {"threshold": 120}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorThreshold(pk, vk, proof, myImage.MaxThreshold+1, opts...)
+			},
+			why: "a threshold outside [0, image.MaxThreshold]",
+		},
+		{
+			// A picture with its red and blue swapped, and then its blue dropped
+			name:    "channelswap",
+			picture: sub(myImage.GradientImage(), 0, 2, 11, 11),
+			keys:    myTransformations.Transformation{T: myTransformations.ChannelSwap},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorChannelSwap(pk, vk, proof, [3]int{2, 1, 0}, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorChannelSwap(pk, vk, proof, [3]int{0, 1, myImage.DroppedChannel}, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) {
+				swapped, err := picture.SwapChannels([3]int{2, 1, 0})
+				if err != nil {
+					return myImage.I{}, err
+				}
+				return swapped.SwapChannels([3]int{0, 1, myImage.DroppedChannel})
+			},
+			params: []map[string]int{nil, {"source_r": 2}, {"source_b": myImage.DroppedChannel}},
+		},
+		{
+			// A picture with a face and then a license plate redacted, and a region outside of the picture
+			name:    "redact",
+			picture: sub(myImage.GradientImage(), 0, 0, 12, 9),
+			keys:    myTransformations.Transformation{T: myTransformations.Redact},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorRedact(pk, vk, proof, face, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorRedact(pk, vk, proof, plate, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) {
+				faceless, err := picture.Redact(face)
+				if err != nil {
+					return myImage.I{}, err
+				}
+				return faceless.Redact(plate)
+			},
+			params: []map[string]int{{"x1": -1}, {"x0": face.X0}, {"y1": plate.Y1}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorRedact(pk, vk, proof, myImage.Rect{X0: 10, Y0: 0, X1: 14, Y1: 2}, opts...)
+			},
+			why: "a region outside of the picture",
+		},
+		{
+			// A picture with a face pixelated, and blocks outside image.MosaicBlocks
+			name:    "mosaic",
+			picture: sub(myImage.GradientImage(), 1, 1, 14, 10),
+			keys:    myTransformations.Transformation{T: myTransformations.Mosaic},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorMosaic(pk, vk, proof, pixelated, 2, opts...)
+				},
+			},
+			want:    func(picture myImage.I) (myImage.I, error) { return picture.Pixelate(pixelated, 2) },
+			changes: true,
+			params:  []map[string]int{nil, {"block": 2, "y1": pixelated.Y1}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorMosaic(pk, vk, proof, pixelated, 3, opts...)
+			},
+			why: "blocks outside image.MosaicBlocks",
+		},
+		{
+			// A picture with its background blurred, and a region on the edge of the picture
+			name:    "blur",
+			picture: sub(myImage.NoiseImage(3), 1, 1, 14, 10),
+			keys:    myTransformations.Transformation{T: myTransformations.Blur},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorBlur(pk, vk, proof, background, opts...)
+				},
+			},
+			want:    func(picture myImage.I) (myImage.I, error) { return picture.BoxBlur(background) },
+			changes: true,
+			params:  []map[string]int{nil, {"x1": background.X1}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorBlur(pk, vk, proof, myImage.Rect{X0: 0, Y0: 1, X1: 12, Y1: 4}, opts...)
+			},
+			why: "a region on the edge of the picture",
+		},
+		{
+			// A picture with its subject sharpened, and a region on the edge of the picture
+			name:    "sharpen",
+			picture: sub(myImage.NoiseImage(3), 1, 1, 14, 10),
+			keys:    myTransformations.Transformation{T: myTransformations.Sharpen},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorSharpen(pk, vk, proof, subject, opts...)
+				},
+			},
+			want:    func(picture myImage.I) (myImage.I, error) { return picture.Sharpen(subject) },
+			changes: true,
+			params:  []map[string]int{nil, {"x1": subject.X1}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorSharpen(pk, vk, proof, myImage.Rect{X0: 3, Y0: 2, X1: 10, Y1: 9}, opts...)
+			},
+			why: "a region on the edge of the picture",
+		},
+		{
+			// A picture stamped with the logo of an agency, and a logo off the picture
+			name:    "watermark",
+			picture: sub(myImage.GradientImage(), 0, 0, 13, 9),
+			keys:    myTransformations.Transformation{T: myTransformations.Watermark},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorWatermark(pk, vk, proof, &logo, 10, 6, opts...)
+				},
+			},
+			want:    func(picture myImage.I) (myImage.I, error) { return picture.Stamp(&logo, 10, 6) },
+			changes: true,
+			params:  []map[string]int{nil, {"x": 10, "watermark_1": 200<<16 | 40<<8}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorWatermark(pk, vk, proof, &logo, 13, 6, opts...)
+			},
+			why: "a logo off the picture",
+		},
+		{
+			// A picture with a credit line along its bottom edge, and a caption off the edges
+			name:    "caption",
+			picture: captioned,
+			keys:    myTransformations.Transformation{T: myTransformations.Caption},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorCaption(pk, vk, proof, &credit, bottom, opts...)
+				},
+			},
+			want:    func(picture myImage.I) (myImage.I, error) { return picture.AddCaption(&credit, bottom) },
+			changes: true,
+			params:  []map[string]int{nil, {"y": bottom, "caption_1": 0b101010}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorCaption(pk, vk, proof, &credit, 3, opts...)
+			},
+			why: "a caption off the edges of the picture",
+		},
+		{
+			// A framed picture, smaller than the pixels of an image, and a frame wider than image.MaxBorder
+			name:    "border",
+			picture: sub(myImage.GradientImage(), 2, 1, 13, 9),
+			keys:    myTransformations.Transformation{T: myTransformations.Border},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorBorder(pk, vk, proof, 1, frame, opts...)
+				},
+			},
+			want:    func(picture myImage.I) (myImage.I, error) { return picture.AddBorder(1, frame) },
+			changes: true,
+			params:  []map[string]int{nil, {"width": 1, "g": 230}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorBorder(pk, vk, proof, myImage.MaxBorder+1, frame, opts...)
+			},
+			why: "a frame wider than image.MaxBorder",
+		},
+		{
+			// The keys of a Convolution prove every whitelisted kernel: a picture blurred and then sharpened, and a
+			// kernel outside of the whitelist
+			name:    "convolution",
+			picture: sub(myImage.NoiseImage(5), 0, 0, 13, 10),
+			keys:    myTransformations.Transformation{T: myTransformations.Convolution},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorConvolve(pk, vk, proof, convolved, 1, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorConvolve(pk, vk, proof, convolved, 2, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) {
+				blurred, err := picture.BoxBlur(convolved)
+				if err != nil {
+					return myImage.I{}, err
+				}
+				return blurred.Sharpen(convolved)
+			},
+			params: []map[string]int{nil, {"kernel": 1}, {"kernel": 2}},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorConvolve(pk, vk, proof, convolved, len(myImage.Kernels), opts...)
+			},
+			why: "a kernel outside of the whitelist",
+		},
+		{
+			// A crop with keys that disclose its size, but not its offset, tells the verifier the size of the crop
+			// only, and does not hold for another size
+			name:    "disclosedcrop",
+			picture: myImage.CoordinateImage(),
+			keys:    myTransformations.Transformation{T: myTransformations.Identity, Public: []string{"y1", "x1"}},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorCrop(pk, vk, proof, map[string]int{"x0": 3, "y0": 2, "x1": 9, "y1": 6}, opts...)
+				},
+			},
+			want:   func(picture myImage.I) (myImage.I, error) { return picture.SubImage(3, 2, 9, 6) },
+			params: []map[string]int{nil, {"x1": 9, "y1": 6}},
+			tamper: [2]string{`"x1":9`, `"x1":10`},
+			check: func(t *testing.T, vk_pp gen.VK_PP, picture myImage.I, history []prover.Proof) {
+				if vk_pp.Circuit.Disclosed != "x1,y1" {
+					t.Errorf("the keys are for circuit %s, expected one disclosing x1,y1", vk_pp.Circuit)
+				}
+				if params := history[1].Params(); len(params) != 2 {
+					t.Errorf("the crop discloses %v, expected its size only", params)
+				}
+			},
+		},
+		{
+			// A picture cropped, then rotated, with a single pair of keys of a Policy, whose proofs tell which
+			// transformation of the policy they hold for, and the keys of a Policy prove nothing else
+			name:    "policy",
+			picture: portrait,
+			keys:    myTransformations.Transformation{T: myTransformations.Policy},
+			circuit: myTransformations.PolicyCircuitID,
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorCrop(pk, vk, proof, map[string]int{"x0": 1, "y0": 2, "x1": 8, "y1": 9}, opts...)
+				},
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorRotate(pk, vk, proof, 1, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) {
+				cropped, err := picture.SubImage(1, 2, 8, 9)
+				if err != nil {
+					return myImage.I{}, err
+				}
+				return cropped.Rotate(1)
+			},
+			params: []map[string]int{
+				{"selector": myTransformations.PolicyTypes[0]},
+				{"selector": myTransformations.PolicyTypes[1]},
+				{"selector": myTransformations.PolicyTypes[2]},
+			},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorBrightness(pk, vk, proof, 40, opts...)
+			},
+			why: "a brightness with the keys of a Policy",
+		},
+		{
+			// A picture cropped, brightened and downscaled in a single proof, which holds for the public delta of the
+			// brightness, and the keys of a Chain prove no other composition
+			name:    "chain",
+			picture: myImage.CoordinateImage(),
+			keys:    chainT,
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorChain(pk, vk, proof, chain, opts...)
+				},
+			},
+			want: func(picture myImage.I) (myImage.I, error) {
+				var err error
+				for _, step := range chain {
+					if picture, err = step.Apply(picture); err != nil {
+						return myImage.I{}, err
+					}
+				}
+				return picture, nil
+			},
+			params: []map[string]int{{"delta_1": 0}, {"delta_1": 30}},
+			check: func(t *testing.T, vk_pp gen.VK_PP, picture myImage.I, history []prover.Proof) {
+				if vk_pp.Circuit.Name != myTransformations.ChainCircuitID.Name || vk_pp.Circuit.Steps != "crop,brightness,downscale" {
+					t.Errorf("the keys are for circuit %s, expected the chain of a crop, a brightness and a downscale", vk_pp.Circuit)
+				}
+			},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorChain(pk, vk, proof, []myTransformations.Transformation{chain[1], chain[0], chain[2]}, opts...)
+			},
+			why: "a brightness, then a crop, with the keys of a crop, then a brightness",
+		},
+		{
+			// A picture credited to an agency keeps the pixels, the time and the camera of the capture, and an
+			// author longer than image.MaxAuthor
+			name:    "credit",
+			picture: credited,
+			keys:    myTransformations.Transformation{T: myTransformations.Credit},
+			edits: []edit{
+				func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+					return editor.EditorCredit(pk, vk, proof, "Associated Press", opts...)
+				},
+			},
+			check: func(t *testing.T, vk_pp gen.VK_PP, picture myImage.I, history []prover.Proof) {
+				image := history[1].Z().Image
+				if image.Pixels != picture.Pixels || image.M.Author != "Associated Press" || !image.M.Timestamp.Equal(picture.M.Timestamp) || image.M.DeviceID != picture.M.DeviceID {
+					t.Errorf("the credited image is not the picture credited to the agency: %+v", image.M)
+				}
+			},
+			reject: func(pk gen.PK_PP, vk backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+				return editor.EditorCredit(pk, vk, proof, strings.Repeat("a", myImage.MaxAuthor+1), opts...)
+			},
+			why: "an author longer than MaxAuthor",
+		},
+	}
+}
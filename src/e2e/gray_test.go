@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	"src/verifier"
+)
+
+// An area of the camera's grayscale capture verifies without the capture, and only with its area.
+func TestGray(t *testing.T) {
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	capture, captureSignature := secureCamera.CameraGray()
+
+	pk_gray, vk_gray, err := gen.GrayGenerator(backend.Default, vk_pp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	area := map[string]int{"x0": 2, "y0": 2, "x1": 12, "y1": 10}
+	other := capture
+	other.Pixels[0][0]--
+	if _, err := prover.ProveGray(pk_gray, other, captureSignature, secureCamera.Counter(), area); err == nil {
+		t.Fatal("a grayscale capture the camera did not sign was proven")
+	}
+	gray, err := prover.ProveGray(pk_gray, capture, captureSignature, secureCamera.Counter(), area)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var published prover.GrayImage
+	remarshal(t, gray, &published)
+	if !verifier.VerifyGray(vk_gray, published) {
+		t.Fatal("the grayscale image did not pass verification")
+	}
+
+	// Tampering with the image, its area or its capture fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var image myImage.Gray
+			remarshal(t, encoded["image"], &image)
+			image.Pixels[3][4] ^= 1
+			encoded["image"] = image
+		},
+		"area": func(encoded map[string]interface{}) {
+			encoded["area"] = map[string]int{"x0": 3, "y0": 2, "x1": 13, "y1": 10}
+		},
+		"nonce": func(encoded map[string]interface{}) {
+			encoded["nonce"] = "2"
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, published, &fields)
+		change(fields)
+		var tampered prover.GrayImage
+		remarshal(t, fields, &tampered)
+		if verifier.VerifyGray(vk_gray, tampered) {
+			t.Errorf("a grayscale image with a tampered %s passed verification", name)
+		}
+	}
+}
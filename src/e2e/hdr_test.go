@@ -0,0 +1,75 @@
+package e2e
+
+import (
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+	"src/verifier"
+)
+
+// An HDR merge of three captures verifies without the captures, and only with its weights.
+func TestHDR(t *testing.T) {
+	if testing.Short() {
+		t.Skip("proves several images, each with keys of its own")
+	}
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	var exposures [myTransformations.HDRExposures]prover.Proof
+	for i := range exposures {
+		if i > 0 {
+			secureCamera.TakePicture()
+		}
+		exposures[i] = secureCamera.CameraProver()
+	}
+
+	pk_hdr, vk_hdr, err := gen.HDRGenerator(backend.Default, vk_pp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prover.ProveHDR(pk_hdr, [myTransformations.HDRExposures]prover.Proof{exposures[0], exposures[1], exposures[0]}, [myTransformations.HDRExposures]int{1, 2, 1}); err == nil {
+		t.Fatal("an HDR merge of a capture twice was proven")
+	}
+	hdr, err := prover.ProveHDR(pk_hdr, exposures, [myTransformations.HDRExposures]int{1, 2, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var published prover.HDR
+	remarshal(t, hdr, &published)
+	if !verifier.VerifyHDR(vk_hdr, published) {
+		t.Fatal("the HDR merge did not pass verification")
+	}
+
+	// Tampering with the merged image, its weights or its captures fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var image myImage.I
+			remarshal(t, encoded["image"], &image)
+			pixel := image.GetPixel(7, 7)
+			pixel.B ^= 1
+			image.SetPixel(7, 7, pixel)
+			encoded["image"] = image
+		},
+		"weights": func(encoded map[string]interface{}) {
+			encoded["weights"] = []int{1, 1, 1}
+		},
+		"nonces": func(encoded map[string]interface{}) {
+			encoded["nonces"] = []string{"1", "2", "4"}
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, published, &fields)
+		change(fields)
+		var tampered prover.HDR
+		remarshal(t, fields, &tampered)
+		if verifier.VerifyHDR(vk_hdr, tampered) {
+			t.Errorf("an HDR merge with a tampered %s passed verification", name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package e2e
+
+import (
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	"src/verifier"
+)
+
+// A panorama of two captures verifies without the captures, and only as stitched.
+func TestPanorama(t *testing.T) {
+	if testing.Short() {
+		t.Skip("proves several images, each with keys of its own")
+	}
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	left := secureCamera.CameraProver()
+	secureCamera.TakePicture()
+	right := secureCamera.CameraProver()
+
+	pk_panorama, vk_panorama, err := gen.PanoramaGenerator(backend.Default, vk_pp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prover.ProvePanorama(pk_panorama, left, left, 10, 3); err == nil {
+		t.Fatal("a panorama of one capture was proven")
+	}
+	panorama, err := prover.ProvePanorama(pk_panorama, left, right, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var published prover.Panorama
+	remarshal(t, panorama, &published)
+	if !verifier.VerifyPanorama(vk_panorama, published) {
+		t.Fatal("the panorama did not pass verification")
+	}
+	if leftNonce, rightNonce := published.Nonces(); leftNonce.Int64() != 1 || rightNonce.Int64() != 2 {
+		t.Errorf("the panorama holds for captures %v and %v, expected 1 and 2", leftNonce, rightNonce)
+	}
+
+	// Tampering with the panorama, its seam or its captures fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var image myImage.I
+			remarshal(t, encoded["image"], &image)
+			pixel := image.GetPixel(12, 3)
+			pixel.G ^= 1
+			image.SetPixel(12, 3, pixel)
+			encoded["image"] = image
+		},
+		"seam": func(encoded map[string]interface{}) {
+			encoded["seam"] = 11
+		},
+		"overlap": func(encoded map[string]interface{}) {
+			encoded["overlap"] = 2
+		},
+		"nonces": func(encoded map[string]interface{}) {
+			encoded["leftNonce"], encoded["rightNonce"] = encoded["rightNonce"], encoded["leftNonce"]
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, published, &fields)
+		change(fields)
+		var tampered prover.Panorama
+		remarshal(t, fields, &tampered)
+		if verifier.VerifyPanorama(vk_panorama, tampered) {
+			t.Errorf("a panorama with a tampered %s passed verification", name)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package e2e
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/signature"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+
+	"src/backend"
+	"src/camera"
+	"src/editor"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+	"src/verifier"
+)
+
+// Keys and proof threaded through the steps of a scenario.
+type state struct {
+	pk_pp gen.PK_PP
+	vk_pp gen.VK_PP
+	proof prover.Proof
+
+	editorKey signature.Signer // editor key of the keys, which the camera hands to its editors
+
+	other gen.VK_PP // verifying key of another camera
+}
+
+// A step of a scenario, e.g. an edit or an attack on the proof.
+type step struct {
+	name string
+	run  func(t *testing.T, s *state)
+}
+
+// A scenario starts from a picture taken and proven by the secure camera, runs its steps in
+// order, and expects the final proof to be accepted or rejected by the verifier.
+// skip, when set, is the reason the scenario cannot be exercised in the current tree.
+type scenario struct {
+	name     string
+	steps    []step
+	verified bool
+	skip     string
+}
+
+// Crop the proven image to {(x0,y0), (x1,y1)}.
+func crop(x0, y0, x1, y1 int) step {
+	return step{"crop", func(t *testing.T, s *state) {
+		s.proof = editor.EditorCrop(s.pk_pp, s.vk_pp.VerifyingKey, s.proof, map[string]int{"x0": x0, "y0": y0, "x1": x1, "y1": y1}, backend.WithEditorKey(s.editorKey))
+	}}
+}
+
+// Crop the proven image, signing the crop with another editor key than the one of the keys.
+func otherEditor(x0, y0, x1, y1 int) step {
+	return step{"other editor", func(t *testing.T, s *state) {
+		editorKey, err := ceddsa.New(1, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.proof = editor.EditorCrop(s.pk_pp, s.vk_pp.VerifyingKey, s.proof, map[string]int{"x0": x0, "y0": y0, "x1": x1, "y1": y1}, backend.WithEditorKey(editorKey))
+	}}
+}
+
+// Run a transformation the compliance predicate does not permit.
+func disallowed() step {
+	return step{"disallowed transformation", func(t *testing.T, s *state) {
+		s.proof = prover.Prover(s.pk_pp, s.vk_pp.VerifyingKey, s.proof, myTransformations.Transformation{T: -1})
+	}}
+}
+
+// Change a pixel of the proven image, the way an attacker editing the proof file would.
+func tamperPixel(x, y int) step {
+	return step{"tamper pixel", func(t *testing.T, s *state) {
+		s.proof = editJSON(t, s.proof, func(encoded map[string]interface{}) {
+			var image myImage.I
+			remarshal(t, encoded["image"], &image)
+			pixel := image.GetPixel(x, y)
+			pixel.R ^= 0xff
+			image.SetPixel(x, y, pixel)
+			encoded["image"] = image
+		})
+	}}
+}
+
+// Claim the proof for another nonce, the way an attacker re-presenting it in another context would.
+func replay(nonce int64) step {
+	return step{"replay", func(t *testing.T, s *state) {
+		s.proof = editJSON(t, s.proof, func(encoded map[string]interface{}) {
+			encoded["nonce"] = big.NewInt(nonce).String()
+		})
+	}}
+}
+
+// Claim an edit was made from another proof, the way an attacker splicing edit histories would.
+func relink(prevProofHash int64) step {
+	return step{"relink", func(t *testing.T, s *state) {
+		s.proof = editJSON(t, s.proof, func(encoded map[string]interface{}) {
+			encoded["prevProofHash"] = big.NewInt(prevProofHash).String()
+		})
+	}}
+}
+
+// Claim the proof is of another capture than its own, the way an attacker submitting a picture twice would.
+func renullify(nullifier int64) step {
+	return step{"renullify", func(t *testing.T, s *state) {
+		s.proof = editJSON(t, s.proof, func(encoded map[string]interface{}) {
+			encoded["nullifier"] = big.NewInt(nullifier).String()
+		})
+	}}
+}
+
+// Claim the proof is more or fewer edits past its original than it is, the way an attacker hiding edits would.
+func rehop(hops int) step {
+	return step{"rehop", func(t *testing.T, s *state) {
+		s.proof = editJSON(t, s.proof, func(encoded map[string]interface{}) {
+			encoded["hops"] = hops
+		})
+	}}
+}
+
+// Claim an edit was made from another image, the way an attacker splicing edit histories would.
+func reimage(x, y int) step {
+	return step{"reimage", func(t *testing.T, s *state) {
+		prev := s.proof.Z().Image.Clone()
+		pixel := prev.GetPixel(x, y)
+		pixel.R ^= 0xff
+		prev.SetPixel(x, y, pixel)
+		s.proof = editJSON(t, s.proof, func(encoded map[string]interface{}) {
+			encoded["prevImageBytes"] = prev.Digest()
+		})
+	}}
+}
+
+// Verify with the verifying key of another camera.
+func wrongKey() step {
+	return step{"wrong key", func(t *testing.T, s *state) {
+		s.vk_pp = s.other
+	}}
+}
+
+var scenarios = []scenario{
+	{name: "original", verified: true},
+	{name: "crop", steps: []step{crop(3, 3, 6, 6)}, verified: true},
+	{name: "crop twice", steps: []step{crop(2, 2, 12, 10), crop(1, 1, 5, 5)}, verified: true},
+	{name: "tampered original", steps: []step{tamperPixel(0, 0)}, verified: false},
+	{name: "tampered crop", steps: []step{crop(3, 3, 6, 6), tamperPixel(1, 1)}, verified: false},
+	{name: "replayed original", steps: []step{replay(2)}, verified: false},
+	{name: "replayed crop", steps: []step{crop(3, 3, 6, 6), replay(2)}, verified: false},
+	{name: "relinked crop", steps: []step{crop(3, 3, 6, 6), relink(2)}, verified: false},
+	{name: "renullified crop", steps: []step{crop(3, 3, 6, 6), renullify(2)}, verified: false},
+	{name: "reimaged original", steps: []step{reimage(0, 0)}, verified: false},
+	{name: "reimaged crop", steps: []step{crop(3, 3, 6, 6), reimage(1, 1)}, verified: false},
+	{name: "rehopped original", steps: []step{rehop(1)}, verified: false},
+	{name: "rehopped crop", steps: []step{crop(3, 3, 6, 6), rehop(0)}, verified: false},
+	{name: "other editor", steps: []step{otherEditor(3, 3, 6, 6)}, verified: false},
+	{name: "other editor after crop", steps: []step{crop(2, 2, 12, 10), otherEditor(1, 1, 5, 5)}, verified: false},
+	{name: "wrong key", steps: []step{wrongKey()}, verified: false},
+	{name: "wrong key after crop", steps: []step{crop(3, 3, 6, 6), wrongKey()}, verified: false},
+	{name: "disallowed transformation", steps: []step{disallowed()}, verified: false},
+}
+
+func TestScenarios(t *testing.T) {
+	if testing.Short() {
+		t.Skip("proves several images, each with keys of its own")
+	}
+	// Keys are generated once, by two cameras, and shared by every scenario
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	pk_pp, vk_pp := secureCamera.CameraGenerator()
+
+	otherCamera := camera.SecureCamera{}
+	otherCamera.TakePicture()
+	_, other := otherCamera.CameraGenerator()
+
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			if sc.skip != "" {
+				t.Skip(sc.skip)
+			}
+
+			s := state{pk_pp: pk_pp, vk_pp: vk_pp, proof: secureCamera.CameraProver(), editorKey: secureCamera.CameraEditorKey(), other: other}
+
+			for _, st := range sc.steps {
+				st.run(t, &s)
+			}
+
+			if got := verifier.Verifier(s.vk_pp, s.proof); got != sc.verified {
+				t.Errorf("verifier returned %t, expected %t", got, sc.verified)
+			}
+		})
+	}
+}
+
+// Apply change to the JSON encoding of a proof and decode the result.
+func editJSON(t *testing.T, proof prover.Proof, change func(encoded map[string]interface{})) prover.Proof {
+	t.Helper()
+
+	var encoded map[string]interface{}
+	remarshal(t, proof, &encoded)
+	change(encoded)
+
+	var edited prover.Proof
+	remarshal(t, encoded, &edited)
+	return edited
+}
+
+// Encode v as JSON and decode it into out.
+func remarshal(t *testing.T, v interface{}, out interface{}) {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Every proof of an edit history holds for the capture counter of the original image, and the chain
+// verifier only accepts the history in order and complete.
+func TestEditHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("proves several images, each with keys of its own")
+	}
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	pk_pp, vk_pp := secureCamera.CameraGenerator()
+
+	cropTo := func(proof prover.Proof, x0, y0, x1, y1 int) prover.Proof {
+		return editor.EditorCrop(pk_pp, vk_pp.VerifyingKey, proof, map[string]int{"x0": x0, "y0": y0, "x1": x1, "y1": y1}, backend.WithEditorKey(secureCamera.CameraEditorKey()))
+	}
+	original := secureCamera.CameraProver()
+	first := cropTo(original, 2, 2, 12, 10)
+	second := cropTo(first, 1, 1, 5, 5)
+	fork := cropTo(original, 1, 1, 13, 11)
+
+	// The original is signed by the camera, and every edit by the editor key of the keys
+	if !original.Z().PublicKey.Equal(vk_pp.PublicKey) {
+		t.Error("the original image is not signed by the camera")
+	}
+	for _, proof := range []prover.Proof{first, second, fork} {
+		if !proof.Z().PublicKey.Equal(vk_pp.EditorKey) {
+			t.Error("an edit is not signed by the editor key of the keys")
+		}
+	}
+
+	for _, proof := range []prover.Proof{original, first, second} {
+		if !verifier.VerifierWithNonce(vk_pp, proof, big.NewInt(1)) {
+			t.Error("a proof of the first picture does not hold for capture counter 1")
+		}
+		if verifier.VerifierWithNonce(vk_pp, proof, big.NewInt(2)) {
+			t.Error("a proof of the first picture holds for capture counter 2")
+		}
+	}
+
+	histories := []struct {
+		name     string
+		chain    []prover.Proof
+		verified bool
+	}{
+		{"original", []prover.Proof{original}, true},
+		{"complete", []prover.Proof{original, first, second}, true},
+		{"dropped edit", []prover.Proof{original, second}, false},
+		{"reordered", []prover.Proof{original, second, first}, false},
+		{"no original", []prover.Proof{first, second}, false},
+		{"forked", []prover.Proof{original, fork, second}, false},
+	}
+	for _, history := range histories {
+		if got := verifier.VerifyChain(vk_pp, history.chain); got != history.verified {
+			t.Errorf("%s: chain verifier returned %t, expected %t", history.name, got, history.verified)
+		}
+	}
+
+	// Every edit is one hop past the proof it was made from
+	for hops, proof := range []prover.Proof{original, first, second} {
+		if proof.Z().Hops != hops {
+			t.Errorf("proof %d of the edit history holds for %d hops", hops, proof.Z().Hops)
+		}
+	}
+
+	// Every edit carries the nullifier of the capture, so a capture is only accepted once, whichever edits
+	// it is submitted under
+	for _, proof := range []prover.Proof{first, second, fork} {
+		if proof.Nullifier() == nil || proof.Nullifier().Cmp(original.Nullifier()) != 0 {
+			t.Error("an edit carries another nullifier than its original image")
+		}
+	}
+	secureCamera.TakePicture()
+	next := secureCamera.CameraProver()
+	if next.Nullifier().Cmp(original.Nullifier()) == 0 {
+		t.Error("two captures have the same nullifier")
+	}
+
+	store := verifier.NewMemoryNullifierStore()
+	submissions := []struct {
+		name     string
+		chain    []prover.Proof
+		accepted bool
+	}{
+		{"first submission", []prover.Proof{original, first}, true},
+		{"same capture, other edit", []prover.Proof{original, fork}, false},
+		{"same capture, unedited", []prover.Proof{original}, false},
+		{"next capture", []prover.Proof{next}, true},
+		{"invalid history", []prover.Proof{first}, false},
+	}
+	for _, submission := range submissions {
+		if got := verifier.VerifySubmission(vk_pp, submission.chain, store); got != submission.accepted {
+			t.Errorf("%s: submission verifier returned %t, expected %t", submission.name, got, submission.accepted)
+		}
+	}
+}
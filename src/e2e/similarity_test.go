@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+	"src/verifier"
+)
+
+// A slightly altered picture verifies under a distance bound, and only under the published bound.
+func TestSimilarity(t *testing.T) {
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	original := secureCamera.CameraProver()
+
+	pk_similarity, vk_similarity, err := gen.SimilarityGenerator(backend.Default, vk_pp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Recompression moved one channel of one pixel
+	published := original.Z().Image
+	pixel := published.Pixels[0][0]
+	pixel.R ^= 1
+	published.SetPixel(0, 0, pixel)
+
+	// Beyond the bound, no proof can be created
+	if _, err := prover.ProveSimilarity(pk_similarity, original, published, myTransformations.L1, big.NewInt(0)); err == nil {
+		t.Fatal("a similarity beyond its bound was proven")
+	}
+
+	similarity, err := prover.ProveSimilarity(pk_similarity, original, published, myTransformations.L1, big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(similarity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded prover.Similarity
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !verifier.VerifySimilarity(vk_similarity, decoded) {
+		t.Fatal("the similarity did not pass verification")
+	}
+
+	// Tampering with the published image, the norm, the bound or the capture fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var img myImage.I
+			remarshal(t, encoded["image"], &img)
+			pixel := img.Pixels[1][1]
+			pixel.G ^= 1
+			img.SetPixel(1, 1, pixel)
+			encoded["image"] = img
+		},
+		"norm": func(encoded map[string]interface{}) {
+			encoded["norm"] = "L2"
+		},
+		"bound": func(encoded map[string]interface{}) {
+			encoded["bound"] = "1"
+		},
+		"nonce": func(encoded map[string]interface{}) {
+			encoded["nonce"] = "2"
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, decoded, &fields)
+		change(fields)
+		var tampered prover.Similarity
+		remarshal(t, fields, &tampered)
+		if verifier.VerifySimilarity(vk_similarity, tampered) {
+			t.Errorf("a similarity with a tampered %s passed verification", name)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package e2e
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	"src/video"
+)
+
+// A clip verifies frame by frame, chained by its frame counters; trimming it keeps it verifiable, but dropping,
+// reordering or recropping frames does not.
+func TestVideo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("proves several images, each with keys of its own")
+	}
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	captures := video.Record(&secureCamera, 3)
+
+	pk_frame, vk_frame, err := gen.FrameGenerator(backend.Default, vk_pp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	area := map[string]int{"x0": 2, "y0": 2, "x1": 12, "y1": 9}
+	if _, err := video.Prove(pk_frame, []prover.Proof{captures[0], captures[2]}, area); err == nil {
+		t.Fatal("a clip with a dropped frame was proven")
+	}
+	clip, err := video.Prove(pk_frame, captures, area)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clip.First().Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("the clip starts at frame counter %v, expected 2", clip.First())
+	}
+
+	var published video.Clip
+	remarshal(t, clip, &published)
+	if !video.Verify(vk_frame, published) {
+		t.Fatal("the clip did not pass verification")
+	}
+	trimmed, err := published.Trim(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !video.Verify(vk_frame, trimmed) {
+		t.Fatal("the trimmed clip did not pass verification")
+	}
+
+	// Tampering with the frames, their order, their counters or the area fails
+	for name, change := range map[string]func(encoded map[string]interface{}){
+		"pixel": func(encoded map[string]interface{}) {
+			var frames []myImage.I
+			remarshal(t, encoded["frames"], &frames)
+			pixel := frames[1].GetPixel(3, 3)
+			pixel.G ^= 1
+			frames[1].SetPixel(3, 3, pixel)
+			encoded["frames"] = frames
+		},
+		"dropped frame": func(encoded map[string]interface{}) {
+			encoded["frames"] = []interface{}{encoded["frames"].([]interface{})[0], encoded["frames"].([]interface{})[2]}
+			encoded["pcdProofs"] = []interface{}{encoded["pcdProofs"].([]interface{})[0], encoded["pcdProofs"].([]interface{})[2]}
+		},
+		"reordered": func(encoded map[string]interface{}) {
+			frames, pcdProofs := encoded["frames"].([]interface{}), encoded["pcdProofs"].([]interface{})
+			frames[0], frames[1] = frames[1], frames[0]
+			pcdProofs[0], pcdProofs[1] = pcdProofs[1], pcdProofs[0]
+		},
+		"first": func(encoded map[string]interface{}) {
+			encoded["first"] = "3"
+		},
+		"area": func(encoded map[string]interface{}) {
+			encoded["area"] = map[string]int{"x0": 3, "y0": 2, "x1": 13, "y1": 9}
+		},
+	} {
+		var fields map[string]interface{}
+		remarshal(t, published, &fields)
+		change(fields)
+		var tampered video.Clip
+		remarshal(t, fields, &tampered)
+		if video.Verify(vk_frame, tampered) {
+			t.Errorf("a clip with a tampered %s passed verification", name)
+		}
+	}
+}
+
+// A sequence verifies against the camera's signatures and its hash chain; tampering with a frame, its counter,
+// the order of the frames or the chain does not.
+func TestSequence(t *testing.T) {
+	secureCamera := camera.SecureCamera{}
+	secureCamera.TakePicture()
+	_, vk_pp := secureCamera.CameraGenerator()
+	captures := video.Record(&secureCamera, 3)
+
+	if _, err := video.NewSequence([]prover.Proof{captures[0], captures[2]}); err == nil {
+		t.Fatal("a sequence with a dropped frame was created")
+	}
+	sequence, err := video.NewSequence(captures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var published video.Sequence
+	remarshal(t, sequence, &published)
+	if !video.VerifySequence(vk_pp.PublicKey, published) {
+		t.Fatal("the sequence did not pass verification")
+	}
+	if !bytes.Equal(published.Head(), sequence.Head()) {
+		t.Fatal("the head of the sequence changed through JSON")
+	}
+
+	for name, change := range map[string]func(sequence *video.Sequence){
+		"pixel": func(sequence *video.Sequence) {
+			pixel := sequence.Frames[1].Z.Image.GetPixel(3, 3)
+			pixel.G ^= 1
+			sequence.Frames[1].Z.Image.SetPixel(3, 3, pixel)
+		},
+		"counter": func(sequence *video.Sequence) {
+			sequence.Frames[2].Nonce = big.NewInt(9)
+		},
+		"reordered": func(sequence *video.Sequence) {
+			sequence.Frames[0], sequence.Frames[1] = sequence.Frames[1], sequence.Frames[0]
+			sequence.Chain[0], sequence.Chain[1] = sequence.Chain[1], sequence.Chain[0]
+		},
+		"chain": func(sequence *video.Sequence) {
+			sequence.Chain = sequence.Chain[:2]
+		},
+	} {
+		var tampered video.Sequence
+		remarshal(t, published, &tampered)
+		change(&tampered)
+		if video.VerifySequence(vk_pp.PublicKey, tampered) {
+			t.Errorf("a sequence with a tampered %s passed verification", name)
+		}
+	}
+}
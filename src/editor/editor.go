@@ -1,13 +1,175 @@
+// Package editor applies permissible transformations to proven images. Every edit is signed with the editor key
+// of the keys, the EditorKey of their SK_PP, which the caller passes with backend.WithEditorKey.
 package editor
 
 import (
+	"fmt"
+
+	"src/backend"
 	generator "src/generator"
+	myImage "src/image"
 	prover "src/prover"
 	myTransformations "src/transformations"
-
-	"github.com/consensys/gnark/backend/groth16"
 )
 
-func EditorCrop(pk_pcd generator.PK_PP, verifyingKey groth16.VerifyingKey, proof prover.Proof, params map[string]int) prover.Proof {
-	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Crop, Params: params})
+// EditorCrop crops the image of a proof to params {x0, y0, x1, y1} and returns the PCD proof of the result.
+// pk_pcd are keys the Generator created for a Crop, or for a Policy.
+func EditorCrop(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, params map[string]int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Crop, Params: params}, opts...)
+}
+
+// EditorRotate rotates the image of a proof clockwise by quarters quarter turns and returns the PCD proof of the
+// result. pk_pcd are keys the Generator created for a Rotate, or for a Policy.
+func EditorRotate(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, quarters int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Rotate, Params: map[string]int{"quarters": quarters}}, opts...)
+}
+
+// EditorFlipH mirrors the image of a proof left to right and returns the PCD proof of the result. pk_pcd are keys
+// the Generator created for a FlipH.
+func EditorFlipH(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.FlipH}, opts...)
+}
+
+// EditorFlipV mirrors the image of a proof top to bottom and returns the PCD proof of the result. pk_pcd are keys
+// the Generator created for a FlipV.
+func EditorFlipV(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.FlipV}, opts...)
+}
+
+// EditorDownscale halves the image of a proof, averaging blocks of 2 x 2 pixels, and returns the PCD proof of
+// the result. pk_pcd are keys the Generator created for a Downscale.
+func EditorDownscale(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Downscale}, opts...)
+}
+
+// EditorRotateCrop rotates the image of a proof clockwise by quarters quarter turns, crops the rotated image to
+// params {x0, y0, x1, y1} and returns the PCD proof of the result, a single proof of both edits. pk_pcd are keys
+// the Generator created for a RotateCrop.
+func EditorRotateCrop(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, quarters int, params map[string]int, opts ...backend.ProveOption) prover.Proof {
+	t := myTransformations.Transformation{T: myTransformations.RotateCrop, Params: map[string]int{"quarters": quarters}}
+	for key, value := range params {
+		t.Params[key] = value
+	}
+	return prover.Prover(pk_pcd, verifyingKey, proof, t, opts...)
+}
+
+// EditorBrightness adds delta to every channel of the image of a proof, clamped to [0, 255], and returns the
+// PCD proof of the result, which holds for delta. pk_pcd are keys the Generator created for a Brightness.
+func EditorBrightness(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, delta int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Brightness, Params: map[string]int{"delta": delta}}, opts...)
+}
+
+// EditorGamma gamma corrects the image of a proof with gamma, in hundredths, one of image.Gammas, and returns the
+// PCD proof of the result, which holds for gamma. pk_pcd are keys the Generator created for a Gamma.
+func EditorGamma(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, gamma int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Gamma, Params: map[string]int{"gamma": gamma}}, opts...)
+}
+
+// EditorSepia tones the image of a proof in sepia and returns the PCD proof of the result. pk_pcd are keys the
+// Generator created for a Sepia.
+func EditorSepia(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Sepia}, opts...)
+}
+
+// EditorHueSaturation rotates the hue of the image of a proof by hue degrees, one of image.Hues, then scales its
+// saturation by saturation out of 256, and returns the PCD proof of the result, which holds for hue and
+// saturation. pk_pcd are keys the Generator created for a HueSaturation.
+func EditorHueSaturation(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, hue, saturation int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.HueSaturation, Params: map[string]int{"hue": hue, "saturation": saturation}}, opts...)
+}
+
+// EditorGain multiplies the R, G and B of the image of a proof by gains, out of 256, and returns the PCD
+// proof of the result, which holds for the gains. Equal gains correct the exposure. pk_pcd are keys the
+// Generator created for a Gain.
+func EditorGain(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, gains [3]int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Gain, Params: map[string]int{"gain_r": gains[0], "gain_g": gains[1], "gain_b": gains[2]}}, opts...)
+}
+
+// EditorThreshold binarizes the image of a proof at a threshold, in [0, image.MaxThreshold], and returns the PCD
+// proof of the result, which holds for the threshold: pixels whose luma is above it become white, the others
+// black. pk_pcd are keys the Generator created for a Threshold.
+func EditorThreshold(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, threshold int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Threshold, Params: map[string]int{"threshold": threshold}}, opts...)
+}
+
+// EditorChannelSwap reorders or drops the channels of the image of a proof and returns the PCD proof of the
+// result, which holds for the sources: channel c becomes channel sources[c], or 0 for image.DroppedChannel.
+// pk_pcd are keys the Generator created for a ChannelSwap.
+func EditorChannelSwap(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, sources [3]int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.ChannelSwap, Params: map[string]int{"source_r": sources[0], "source_g": sources[1], "source_b": sources[2]}}, opts...)
+}
+
+// EditorRedact blackens a rectangle of the image of a proof, e.g. a face or a license plate, and returns the PCD
+// proof of the result, which holds for the rectangle and shows every other pixel is unchanged. pk_pcd are keys
+// the Generator created for a Redact.
+func EditorRedact(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, rect myImage.Rect, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Redact, Params: map[string]int{"x0": rect.X0, "y0": rect.Y0, "x1": rect.X1, "y1": rect.Y1}}, opts...)
+}
+
+// EditorMosaic pixelates a rectangle of the image of a proof with blocks of block x block pixels, one of
+// image.MosaicBlocks, e.g. to anonymize a face, and returns the PCD proof of the result, which holds for the
+// rectangle and the block. pk_pcd are keys the Generator created for a Mosaic.
+func EditorMosaic(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, rect myImage.Rect, block int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Mosaic, Params: map[string]int{"x0": rect.X0, "y0": rect.Y0, "x1": rect.X1, "y1": rect.Y1, "block": block}}, opts...)
+}
+
+// EditorBlur box blurs a rectangle of the image of a proof, which lies a pixel within its edges, e.g. to soften
+// a background, and returns the PCD proof of the result, which holds for the rectangle. pk_pcd are keys the
+// Generator created for a Blur.
+func EditorBlur(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, rect myImage.Rect, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Blur, Params: map[string]int{"x0": rect.X0, "y0": rect.Y0, "x1": rect.X1, "y1": rect.Y1}}, opts...)
+}
+
+// EditorSharpen sharpens a rectangle of the image of a proof, which lies a pixel within its edges, with
+// image.SharpenKernel, and returns the PCD proof of the result, which holds for the rectangle. pk_pcd are keys
+// the Generator created for a Sharpen.
+func EditorSharpen(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, rect myImage.Rect, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Sharpen, Params: map[string]int{"x0": rect.X0, "y0": rect.Y0, "x1": rect.X1, "y1": rect.Y1}}, opts...)
+}
+
+// EditorWatermark stamps a watermark, e.g. the logo of an agency, on the image of a proof with its top left
+// corner at (x, y), and returns the PCD proof of the result, which holds for the position and the pixels of the
+// watermark. pk_pcd are keys the Generator created for a Watermark.
+func EditorWatermark(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, watermark *myImage.Watermark, x, y int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Watermark, Params: myTransformations.WatermarkParams(watermark, x, y)}, opts...)
+}
+
+// EditorCaption draws a caption, e.g. a credit line or a timestamp, along the top or the bottom edge of the image
+// of a proof, from row y, and returns the PCD proof of the result, which holds for the row and the caption.
+// pk_pcd are keys the Generator created for a Caption.
+func EditorCaption(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, caption *myImage.Caption, y int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Caption, Params: myTransformations.CaptionParams(caption, y)}, opts...)
+}
+
+// EditorBorder draws a border of width pixels, in [0, image.MaxBorder], in color along the edges of the image of
+// a proof, and returns the PCD proof of the result, which holds for the width and the color. pk_pcd are keys the
+// Generator created for a Border.
+func EditorBorder(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, width int, color myImage.RGBPixel, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Border, Params: map[string]int{"width": width, "r": int(color.R), "g": int(color.G), "b": int(color.B)}}, opts...)
+}
+
+// EditorConvolve convolves a rectangle of the image of a proof, which lies a pixel within its edges, by
+// image.Kernels[kernel], and returns the PCD proof of the result, which holds for the rectangle and the index of
+// the kernel. pk_pcd are keys the Generator created for a Convolution.
+func EditorConvolve(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, rect myImage.Rect, kernel int, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Convolution, Params: map[string]int{"x0": rect.X0, "y0": rect.Y0, "x1": rect.X1, "y1": rect.Y1, "kernel": kernel}}, opts...)
+}
+
+// EditorChain transforms the image of a proof by every one of steps in turn, e.g. a crop, a brightness and a
+// downscale, and returns a single PCD proof of the result, which holds for the public parameters of every step.
+// pk_pcd are keys the Generator created for the Chain of the same types of steps, see transformations.ChainOf.
+func EditorChain(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, steps []myTransformations.Transformation, opts ...backend.ProveOption) prover.Proof {
+	t, err := myTransformations.ChainOf(steps...)
+	if err != nil {
+		fmt.Println("Error while creating Proof: \n" + err.Error() + "\n-----------------")
+		return prover.Proof{}
+	}
+	return prover.Prover(pk_pcd, verifyingKey, proof, t, opts...)
+}
+
+// EditorCredit credits the image of a proof to author, of at most image.MaxAuthor bytes, and returns the PCD proof
+// of the result, whose pixels and other metadata are those of the image. The author is not public. pk_pcd are
+// keys the Generator created for a Credit.
+func EditorCredit(pk_pcd generator.PK_PP, verifyingKey backend.VerifyingKey, proof prover.Proof, author string, opts ...backend.ProveOption) prover.Proof {
+	return prover.Prover(pk_pcd, verifyingKey, proof, myTransformations.Transformation{T: myTransformations.Credit, Params: myTransformations.CreditParams(author)}, opts...)
 }
@@ -0,0 +1,44 @@
+// Package embedded tunes proving for camera-class hardware: ARM64 systems on a chip with a few slow cores
+// and little memory, proving pictures on the device that took them.
+//
+// At the version this module depends on, gnark-crypto only has assembly for amd64. On arm64 the field and
+// curve arithmetic runs its pure Go implementation, which the Go compiler does not vectorize with NEON, so
+// there is no NEON setting to choose. A Profile instead bounds what the prover may use: CPUs, so the camera
+// stays responsive while proving, and memory, so that proving fits next to the camera firmware. Offload
+// moves the setup artifacts out of memory between proofs.
+package embedded
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"src/backend"
+)
+
+// A Profile of runtime settings for proving on constrained hardware.
+type Profile struct {
+	Workers     int   // CPUs to prove with; 0 for all of them
+	MemoryLimit int64 // Soft memory limit of the Go runtime, in bytes; 0 leaves it unchanged
+	GCPercent   int   // Garbage collection target, see debug.SetGCPercent; 0 leaves it unchanged
+}
+
+// Camera is the Profile of a quad-core camera SoC with 1 GiB of memory: proving uses half of the cores and
+// collects garbage early, to stay within half of the memory.
+var Camera = Profile{Workers: 2, MemoryLimit: 512 << 20, GCPercent: 50}
+
+// Apply configures the Go runtime of the whole process for the profile, and returns the options to pass
+// to the Prover.
+func (profile Profile) Apply() []backend.ProveOption {
+	if profile.MemoryLimit > 0 {
+		debug.SetMemoryLimit(profile.MemoryLimit)
+	}
+	if profile.GCPercent > 0 {
+		debug.SetGCPercent(profile.GCPercent)
+	}
+	if profile.Workers > 0 {
+		// The multi-scalar multiplications of the prover can only be capped for the whole process
+		runtime.GOMAXPROCS(profile.Workers)
+		return []backend.ProveOption{backend.WithWorkers(profile.Workers)}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package embedded
+
+import (
+	"bytes"
+	"math/big"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"testing"
+
+	"src/backend"
+	"src/editor"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+	"src/verifier"
+)
+
+// Run on camera hardware with:
+//
+//	GOARCH=arm64 go test -c ./embedded
+//	./embedded.test -test.bench . -test.run XXX
+
+var (
+	keysOnce sync.Once
+	pk_pp    gen.PK_PP
+	vk_pp    gen.VK_PP
+	sk_pp    gen.SK_PP
+	keysErr  error
+)
+
+// Keys of the crop circuit, generated once for all tests and benchmarks.
+func keys(tb testing.TB) (gen.PK_PP, gen.VK_PP, gen.SK_PP) {
+	keysOnce.Do(func() {
+		pk_pp, vk_pp, sk_pp, keysErr = gen.Generator(myImage.AllWhiteImage(), myTransformations.Transformation{T: myTransformations.Identity})
+	})
+	if keysErr != nil {
+		tb.Fatal(keysErr)
+	}
+	return pk_pp, vk_pp, sk_pp
+}
+
+// The proof of the signed all white image.
+func originalProof(pk_pp gen.PK_PP, sk_pp gen.SK_PP) prover.Proof {
+	img := myImage.AllWhiteImage()
+	counter := big.NewInt(1)
+	return prover.NewSignedProof(myImage.Z{Image: img, PublicKey: pk_pp.PublicKey}, img.Sign(sk_pp.SecretKey, counter, big.NewInt(0)), counter)
+}
+
+// Apply a profile for the duration of the test.
+func apply(tb testing.TB, profile Profile) []backend.ProveOption {
+	procs := runtime.GOMAXPROCS(0)
+	limit := debug.SetMemoryLimit(-1)
+	gcPercent := debug.SetGCPercent(-1)
+	debug.SetGCPercent(gcPercent)
+	tb.Cleanup(func() {
+		runtime.GOMAXPROCS(procs)
+		debug.SetMemoryLimit(limit)
+		debug.SetGCPercent(gcPercent)
+	})
+	return profile.Apply()
+}
+
+func TestOffloadedKeyProves(t *testing.T) {
+	pk_pp, vk_pp, sk_pp := keys(t)
+
+	dir := t.TempDir()
+	if err := Offload(dir, pk_pp); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Circuit != pk_pp.Circuit || loaded.Backend != pk_pp.Backend || !bytes.Equal(loaded.PublicKey.Bytes(), pk_pp.PublicKey.Bytes()) {
+		t.Fatalf("loaded %+v, offloaded %+v", loaded, pk_pp)
+	}
+
+	opts := apply(t, Camera)
+	proof := prover.Prover(loaded, vk_pp.VerifyingKey, originalProof(loaded, sk_pp), myTransformations.Transformation{T: myTransformations.Identity}, opts...)
+	proof = editor.EditorCrop(loaded, vk_pp.VerifyingKey, proof, map[string]int{"x0": 3, "y0": 3, "x1": 6, "y1": 6}, append(opts, backend.WithEditorKey(sk_pp.EditorKey))...)
+	if !verifier.Verifier(vk_pp, proof) {
+		t.Fatal("proof created with the offloaded key did not verify")
+	}
+}
+
+// Loading the proving key from flash, as written by Offload, against the compressed encoding of the key files.
+func BenchmarkLoadProvingKey(b *testing.B) {
+	pk_pp, _, _ := keys(b)
+
+	b.Run("compressed", func(b *testing.B) {
+		var encoded bytes.Buffer
+		if _, err := pk_pp.ProvingKey.WriteTo(&encoded); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			provingKey := backend.Default.NewProvingKey()
+			if _, err := provingKey.ReadFrom(bytes.NewReader(encoded.Bytes())); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("offloaded", func(b *testing.B) {
+		dir := b.TempDir()
+		if err := Offload(dir, pk_pp); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := Load(dir); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// Proving a crop with all CPUs, and with the Camera profile.
+func BenchmarkProve(b *testing.B) {
+	pk_pp, vk_pp, sk_pp := keys(b)
+	proof := prover.Prover(pk_pp, vk_pp.VerifyingKey, originalProof(pk_pp, sk_pp), myTransformations.Transformation{T: myTransformations.Identity})
+
+	for _, profile := range []struct {
+		name    string
+		profile Profile
+	}{{"default", Profile{}}, {"camera", Camera}} {
+		b.Run(profile.name, func(b *testing.B) {
+			opts := append(apply(b, profile.profile), backend.WithEditorKey(sk_pp.EditorKey))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				editor.EditorCrop(pk_pp, vk_pp.VerifyingKey, proof, map[string]int{"x0": 3, "y0": 3, "x1": 6, "y1": 6}, opts...)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package embedded
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"src/backend"
+	gen "src/generator"
+	myTransformations "src/transformations"
+)
+
+// Between pictures, the proving key is most of the memory a camera holds: tens of megabytes for the crop
+// circuit. Offload writes it to flash so that it can be dropped from memory, and Load reads it back for
+// the next proof.
+//
+// The key is written without point compression. That doubles its size on flash, but reading it back
+// skips decompressing every point, which dominates loading a key on slow cores. Load also skips the
+// subgroup checks of the points, so the directory must only be writable by the camera itself.
+
+const (
+	provingKeyFile = "proving_key.bin"
+	metadataFile   = "pk_pp.json"
+)
+
+// Everything of a PK_PP besides its proving key.
+type metadata struct {
+	PublicKey []byte                      `json:"publicKey"`
+	Circuit   myTransformations.CircuitID `json:"circuit"`
+	Backend   backend.ID                  `json:"backend"`
+}
+
+// Uncompressed encodings of the gnark keys.
+type rawWriter interface {
+	WriteRawTo(w io.Writer) (int64, error)
+}
+type unsafeReader interface {
+	UnsafeReadFrom(r io.Reader) (int64, error)
+}
+
+// Offload writes pk_pp into the directory dir.
+func Offload(dir string, pk_pp gen.PK_PP) error {
+	raw, ok := pk_pp.ProvingKey.(rawWriter)
+	if !ok {
+		return fmt.Errorf("the %s proving key has no uncompressed encoding", pk_pp.Backend)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	decoded := metadata{PublicKey: pk_pp.PublicKey.Bytes(), Circuit: pk_pp.Circuit, Backend: pk_pp.Backend}
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, metadataFile), encoded, 0o600); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, provingKeyFile), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	if _, err := raw.WriteRawTo(buffered); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads the PK_PP written by Offload from the directory dir.
+func Load(dir string) (gen.PK_PP, error) {
+	encoded, err := os.ReadFile(filepath.Join(dir, metadataFile))
+	if err != nil {
+		return gen.PK_PP{}, err
+	}
+	var decoded metadata
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return gen.PK_PP{}, fmt.Errorf("%s: %w", metadataFile, err)
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return gen.PK_PP{}, err
+	}
+	publicKey, err := gen.PublicKeyFromBytes(decoded.PublicKey)
+	if err != nil {
+		return gen.PK_PP{}, err
+	}
+	provingKey := b.NewProvingKey()
+	unsafe, ok := provingKey.(unsafeReader)
+	if !ok {
+		return gen.PK_PP{}, fmt.Errorf("the %s proving key has no uncompressed encoding", decoded.Backend)
+	}
+
+	file, err := os.Open(filepath.Join(dir, provingKeyFile))
+	if err != nil {
+		return gen.PK_PP{}, err
+	}
+	defer file.Close()
+	if _, err := unsafe.UnsafeReadFrom(bufio.NewReader(file)); err != nil {
+		return gen.PK_PP{}, fmt.Errorf("%s: %w", provingKeyFile, err)
+	}
+
+	return gen.PK_PP{ProvingKey: provingKey, PublicKey: publicKey, Circuit: decoded.Circuit, Backend: decoded.Backend}, nil
+}
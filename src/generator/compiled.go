@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"sync"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+
+	"src/backend"
+	myTransformations "src/transformations"
+)
+
+// A compiled compliance predicate is keyed by the backend it was compiled for and its CircuitID. The ID names
+// the transformation, its version and the shape of its circuit (the steps of a chain, the parameters a crop
+// discloses), and all circuits are over BN254 with images of image.Width x image.Height pixels, so two
+// circuits with the same key compile to the same constraint system, whatever their assignment.
+type compiledKey struct {
+	backend backend.ID
+	circuit myTransformations.CircuitID
+}
+
+type compiledPredicate struct {
+	sync.Mutex
+	ccs constraint.ConstraintSystem
+}
+
+var (
+	compiledMu sync.Mutex
+	compiled   = map[compiledKey]*compiledPredicate{}
+)
+
+// Compile returns the compliance predicate id, compiled from circuit by b. It is compiled once per process:
+// the Generator compiles it when creating the keys, and the Prover reuses it for every proof, only solving
+// the witness of the new assignment. Compiling is not retried concurrently, but circuits with different
+// keys compile in parallel. Errors are not cached.
+//
+// circuit must be the circuit of id; circuits whose shape is not recorded in their CircuitID, like a
+// collage of a grid, are compiled with b.Compile instead.
+func Compile(b backend.Backend, id myTransformations.CircuitID, circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	key := compiledKey{backend: b.ID(), circuit: id}
+	compiledMu.Lock()
+	predicate, ok := compiled[key]
+	if !ok {
+		predicate = &compiledPredicate{}
+		compiled[key] = predicate
+	}
+	compiledMu.Unlock()
+
+	predicate.Lock()
+	defer predicate.Unlock()
+	if predicate.ccs == nil {
+		ccs, err := b.Compile(circuit)
+		if err != nil {
+			return nil, err
+		}
+		predicate.ccs = ccs
+	}
+	return predicate.ccs, nil
+}
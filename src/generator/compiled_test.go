@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+
+	"src/backend"
+	myTransformations "src/transformations"
+)
+
+// Proves knowledge of the square root X of the public Y.
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// A predicate is compiled once per backend and CircuitID, whatever the assignment it is compiled from.
+func TestCompile(t *testing.T) {
+	id := myTransformations.CircuitID{Name: "square", Version: 1}
+	groth16, err := backend.Get(backend.Groth16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plonk, err := backend.Get(backend.PLONK)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ccs, err := Compile(groth16, id, &squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Compile(groth16, id, &squareCircuit{X: 3, Y: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != ccs {
+		t.Error("the predicate was compiled again")
+	}
+
+	// Another backend, or another version, is another predicate
+	other, err := Compile(plonk, id, &squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == ccs {
+		t.Error("the predicate of another backend is the same constraint system")
+	}
+	id.Version = 2
+	if other, err = Compile(groth16, id, &squareCircuit{}); err != nil {
+		t.Fatal(err)
+	}
+	if other == ccs {
+		t.Error("the predicate of another version is the same constraint system")
+	}
+}
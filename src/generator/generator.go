@@ -1,39 +1,51 @@
+// Package generator implements the Generator of PhotoProof, which creates the proving, verifying and
+// signing keys of a secure camera.
 package generator
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
+	"math/big"
 
+	"src/backend"
 	myImage "src/image"
 	myTransformations "src/transformations"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark-crypto/signature"
 	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/std/signature/eddsa"
 )
 
 // As defined in the paper, VK_PP is an output of the Generator function; and inputs for the Prover and Verifier functions.
 type VK_PP struct {
-	VerifyingKey groth16.VerifyingKey // public PCD verifying key
-	PublicKey    signature.PublicKey  // public digital signature key
+	VerifyingKey backend.VerifyingKey        // public PCD verifying key
+	PublicKey    signature.PublicKey         // public digital signature key
+	EditorKey    signature.PublicKey         // public key of the edits proven with the matching PK_PP, nil for keys without edits
+	Circuit      myTransformations.CircuitID // compliance predicate the keys were generated for
+	Backend      backend.ID                  // proving system the keys were generated for
 }
 
+// As defined in the paper, PK_PP is an output of the Generator function; and an input for the Prover function.
 type PK_PP struct {
-	ProvingKey groth16.ProvingKey  // public PCD proving key (pk_PCD)
-	PublicKey  signature.PublicKey // public digital signature key (p_s)
+	ProvingKey backend.ProvingKey          // public PCD proving key (pk_PCD)
+	PublicKey  signature.PublicKey         // public digital signature key (p_s)
+	Circuit    myTransformations.CircuitID // compliance predicate the keys were generated for
+	Backend    backend.ID                  // proving system the keys were generated for
 }
 
+// As defined in the paper, SK_PP is an output of the Generator function, kept secret by the camera.
 type SK_PP struct {
 	SecretKey signature.Signer // Secret key stored by secure camera
+	EditorKey signature.Signer // key the Prover signs the images of edits with, see backend.WithEditorKey; nil for keys without edits
 }
 
-func Sign(image myImage.I) ([]byte, signature.PublicKey, signature.Signer, []byte) {
+// Sign generates a fresh pair of signature keys and signs the image, nonce and hash of the previous proof
+// with it, see image.Statement. The Generator signs with it once, when it creates the keys of a camera.
+// It returns the signature, the keys, and the digest of the image, see image.I.Digest.
+func Sign(image myImage.I, nonce *big.Int, prevProofHash *big.Int) ([]byte, signature.PublicKey, signature.Signer, []byte) {
 	// 1. Generate a normal signature keys.
 	secretKey, err := ceddsa.New(1, rand.Reader) // Generate a secret key for signing
 	if err != nil {
@@ -42,24 +54,89 @@ func Sign(image myImage.I) ([]byte, signature.PublicKey, signature.Signer, []byt
 
 	publicKey := secretKey.Public() // Generate a public key for verifying
 
-	// Instantiate hash function to be used when signing the image
-	hFunc := hash.MIMC_BN254.New()
+	normalSignature, digest := SignWith(secretKey, image, nonce, prevProofHash)
+	return normalSignature, publicKey, secretKey, digest
+}
+
+// SignWith signs the image, nonce and hash of the previous proof with secretKey, e.g. the EditorKey of an
+// SK_PP. It returns the signature and the digest of the image, which is returned so that the caller does not
+// encode the image again.
+func SignWith(secretKey signature.Signer, image myImage.I, nonce *big.Int, prevProofHash *big.Int) ([]byte, []byte) {
+	digest := image.Digest()
+	return myImage.SignDigest(secretKey, digest, nonce, prevProofHash), digest
+}
+
+// Input: an image and one permissible transformation t, or a Policy for the set of permissible transformations
+// T of transformations.PolicyTypes
+// Output: A proving key, a verification key and a signing key, for the default backend, for the compliance
+// predicate of t, see Transformation.Circuit.
+func Generator(image myImage.I, t myTransformations.Transformation) (PK_PP, VK_PP, SK_PP, error) {
+	return GeneratorWithBackend(backend.Default, image, t)
+}
+
+// GeneratorWithBackend runs the Generator for the given proving system.
+func GeneratorWithBackend(b backend.Backend, image myImage.I, t myTransformations.Transformation) (PK_PP, VK_PP, SK_PP, error) {
+	return GeneratorWithContext(context.Background(), b, image, t)
+}
+
+// GeneratorWithContext runs the Generator for the given proving system, and gives up with the error of ctx if
+// it is cancelled before the circuit is compiled or before the keys are set up. Compiling and setting up are
+// not interrupted once started.
+func GeneratorWithContext(ctx context.Context, b backend.Backend, image myImage.I, t myTransformations.Transformation) (PK_PP, VK_PP, SK_PP, error) {
+	if err := ctx.Err(); err != nil {
+		return PK_PP{}, VK_PP{}, SK_PP{}, err
+	}
+
+	// An invalid image is rejected before the circuit is compiled
+	if err := image.Validate(); err != nil {
+		return PK_PP{}, VK_PP{}, SK_PP{}, err
+	}
 
-	// Sign the image as a big endian slice
-	big_endian_bytes_Image := image.ToBigEndian() // encode image as big endian slice
-	normalSignature, err := secretKey.Sign(big_endian_bytes_Image, hFunc)
+	// The circuit is compiled from an assignment of an original image, for which any capture counter will do
+	id, frontendCircuit, publicKey, secretKey, err := compliancePredicate(image, t)
 	if err != nil {
-		fmt.Println(err.Error())
+		return PK_PP{}, VK_PP{}, SK_PP{}, err
 	}
 
-	return normalSignature, publicKey, secretKey, big_endian_bytes_Image
-}
+	if err := ctx.Err(); err != nil {
+		return PK_PP{}, VK_PP{}, SK_PP{}, err
+	}
 
-// Input: an image and one permissible transformation t (TODO: set/combination of permissible transformations T)
-// Output: A proving key, a verification key and a signing key.
-func Generator(image myImage.I, t myTransformations.Transformation) (PK_PP, VK_PP, SK_PP, error) {
+	// When compiling a compliance_predicate (aka constraint system) in Gnark, we require:
+	//        - a specific circuit,
+	//        - elliptic curve (the security parameter of the bn254 curve has 254-bit prime number, 128-bit security)
+	// 		  - a builder for the backend's constraint system (aka a frontend.builder interface)
+	compliance_predicate, err := Compile(b, id, frontendCircuit)
+	if err != nil {
+		return PK_PP{}, VK_PP{}, SK_PP{}, err
+	}
 
-	normalSignature, publicKey, secretKey, big_endian_bytes_Image := Sign(image)
+	if err := ctx.Err(); err != nil {
+		return PK_PP{}, VK_PP{}, SK_PP{}, err
+	}
+
+	// 3. Generate PCD keys from the compliance_predicate (A. one-time setup https://docs.gnark.consensys.io/HowTo/prove)
+	provingKey, verifyingKey, err := b.Setup(compliance_predicate)
+	if err != nil {
+		return PK_PP{}, VK_PP{}, SK_PP{}, err
+	}
+	// 4. Generate the key the edits are signed with, so that every edit proven with these keys is signed by
+	// one key the verifier knows, rather than by a key of its own
+	editorKey, err := ceddsa.New(1, rand.Reader)
+	if err != nil {
+		return PK_PP{}, VK_PP{}, SK_PP{}, err
+	}
+
+	vk_PCD := VK_PP{VerifyingKey: verifyingKey, PublicKey: publicKey, EditorKey: editorKey.Public(), Circuit: id, Backend: b.ID()}
+	pk_PCD := PK_PP{ProvingKey: provingKey, PublicKey: publicKey, Circuit: id, Backend: b.ID()}
+
+	return pk_PCD, vk_PCD, SK_PP{SecretKey: secretKey, EditorKey: editorKey}, err
+}
+
+// compliancePredicate returns the compliance predicate of t and its assignment for image, signed with a fresh
+// camera key for any capture counter, which it returns with its public key.
+func compliancePredicate(image myImage.I, t myTransformations.Transformation) (myTransformations.CircuitID, frontend.Circuit, signature.PublicKey, signature.Signer, error) {
+	normalSignature, publicKey, secretKey, big_endian_bytes_Image := Sign(image, big.NewInt(0), big.NewInt(0))
 
 	// Assign the eddsa_signature into an eddsa.Signature
 	var eddsa_signature eddsa.Signature
@@ -69,29 +146,30 @@ func Generator(image myImage.I, t myTransformations.Transformation) (PK_PP, VK_P
 	var eddsa_publicKey eddsa.PublicKey
 	eddsa_publicKey.Assign(1, publicKey.Bytes())
 
-	// 2. Compile a compliance predicate, depending on the permissible Transformation(s)
-	var compliance_predicate constraint.ConstraintSystem // Generating a non-compile compliance predicate
-	var err error
-
+	// 2. Assign a compliance predicate, depending on the permissible Transformation(s)
+	// An Identity is converted into a crop of the whole image
 	frT := t.ToFr()
 
-	// If the transformation is identity, then set the params accordingly
-	// NOTE: This if statement is not necessary & should be moved to camera. Generator is predefined with allowed transformations
-	//			due to the choice in type of circuit.
-	if t.T == myTransformations.Identity {
-		frT.Params.X0 = 0
-		frT.Params.Y0 = 0
-		frT.Params.X1 = myImage.N - 1
-		frT.Params.Y1 = myImage.N - 1
-	}
-
 	// Specifying which circuit we are using
 	var circuit myTransformations.CropCircuit
 
 	// Set circuit's public and secret fields
 	circuit.PublicKey = eddsa_publicKey
 	circuit.ImageSignature = eddsa_signature
+	circuit.Nonce = 0
+	circuit.PrevProofHash = 0
+	circuit.Nullifier = 0
+	circuit.Hops = 0
 	circuit.ImageBytes = big_endian_bytes_Image
+	circuit.Metadata = image.MetadataDigest()
+	circuit.PrevImageBytes = big_endian_bytes_Image
+	circuit.PrevMetadata = image.MetadataDigest()
+	circuit.Prev = myTransformations.NewPrevSignature(publicKey.Bytes(), normalSignature, big.NewInt(0)) // The original image is its own previous image
+	statementDigest, err := myTransformations.StatementDigest(publicKey.Bytes(), normalSignature, big.NewInt(0), big.NewInt(0), big.NewInt(0), 0, big_endian_bytes_Image, big_endian_bytes_Image, publicKey.Bytes())
+	if err != nil {
+		return myTransformations.CircuitID{}, nil, nil, nil, err
+	}
+	circuit.Statement = statementDigest
 	circuit.FrImage = image.ToFrontendImage()
 	circuit.CroppedImage_in = image.ToFrontendImage()
 	circuit.Params = frT.Params
@@ -99,22 +177,119 @@ func Generator(image myImage.I, t myTransformations.Transformation) (PK_PP, VK_P
 	// Dereferencing the
 	var frontendCircuit frontend.Circuit = &circuit
 
-	// When compiling a compliance_predicate (aka constraint system) in Gnark, we require:
-	//        - a specific circuit,
-	//        - elliptic curve (the security parameter of the bn254 curve has 254-bit prime number, 128-bit security)
-	// 		  - R1CS builder (aka a frontend.builder interface)
-	compliance_predicate, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, frontendCircuit)
+	// Edits other than a crop have compliance predicates of their own, e.g. the RotateCircuit, compiled from
+	// the edit that keeps the original image
+	id, err := t.Circuit()
 	if err != nil {
-		fmt.Println(err.Error())
+		return myTransformations.CircuitID{}, nil, nil, nil, err
 	}
+	if id.Name == myTransformations.CropCircuitID.Name {
+		// The crop of t discloses the parameters of t that are Public
+		if err := circuit.SetDisclosed(id); err != nil {
+			return myTransformations.CircuitID{}, nil, nil, nil, err
+		}
+	} else {
+		statement := myTransformations.EditStatement{
+			Statement:      statementDigest,
+			PublicKey:      eddsa_publicKey,
+			ImageSignature: eddsa_signature,
+			Nonce:          0,
+			PrevProofHash:  0,
+			Nullifier:      0,
+			Hops:           0,
+			ImageBytes:     big_endian_bytes_Image,
+			Metadata:       image.MetadataDigest(),
+			PrevImageBytes: big_endian_bytes_Image,
+			PrevMetadata:   image.MetadataDigest(),
+			Prev:           circuit.Prev,
+		}
+		frontendCircuit, err = myTransformations.EditAssignment(id, myTransformations.Transformation{T: myTransformations.Identity}, image, image, statement)
+		if err != nil {
+			return myTransformations.CircuitID{}, nil, nil, nil, err
+		}
+	}
+	return id, frontendCircuit, publicKey, secretKey, nil
+}
 
-	// 3. Generate PCD keys from the compliance_predicate (A. one-time setup https://docs.gnark.consensys.io/HowTo/prove)
-	provingKey, verifyingKey, err := groth16.Setup(compliance_predicate)
+// DisclosureGenerator creates the keys of selective disclosures of the pictures taken by the camera with
+// publicKey, for the given proving system. The camera's signing keys are not needed, the keys only record
+// publicKey as the camera whose pictures can be disclosed.
+func DisclosureGenerator(b backend.Backend, publicKey signature.PublicKey) (PK_PP, VK_PP, error) {
+	return circuitGenerator(b, publicKey, &myTransformations.DisclosureCircuit{}, myTransformations.DisclosureCircuitID)
+}
+
+// SimilarityGenerator creates the keys of proofs that a published image is within a distance of a picture
+// taken by the camera with publicKey, for the given proving system. Like the DisclosureGenerator, it does not
+// need the camera's signing keys.
+func SimilarityGenerator(b backend.Backend, publicKey signature.PublicKey) (PK_PP, VK_PP, error) {
+	return circuitGenerator(b, publicKey, &myTransformations.SimilarityCircuit{}, myTransformations.SimilarityCircuitID)
+}
+
+// PanoramaGenerator creates the keys of panoramas of two pictures taken by the camera with publicKey, for the
+// given proving system. Like the DisclosureGenerator, it does not need the camera's signing keys.
+func PanoramaGenerator(b backend.Backend, publicKey signature.PublicKey) (PK_PP, VK_PP, error) {
+	return circuitGenerator(b, publicKey, &myTransformations.PanoramaCircuit{}, myTransformations.PanoramaCircuitID)
+}
+
+// HDRGenerator creates the keys of HDR merges of pictures taken by the camera with publicKey, for the given
+// proving system. Like the DisclosureGenerator, it does not need the camera's signing keys.
+func HDRGenerator(b backend.Backend, publicKey signature.PublicKey) (PK_PP, VK_PP, error) {
+	return circuitGenerator(b, publicKey, &myTransformations.HDRCircuit{}, myTransformations.HDRCircuitID)
+}
+
+// FrameGenerator creates the keys of the frames of video clips recorded by the camera with publicKey, for the
+// given proving system. Like the DisclosureGenerator, it does not need the camera's signing keys.
+func FrameGenerator(b backend.Backend, publicKey signature.PublicKey) (PK_PP, VK_PP, error) {
+	return circuitGenerator(b, publicKey, &myTransformations.FrameCircuit{}, myTransformations.FrameCircuitID)
+}
+
+// DevelopGenerator creates the keys of developments of RAW captures taken by the camera with publicKey, for
+// the given proving system. Like the DisclosureGenerator, it does not need the camera's signing keys.
+func DevelopGenerator(b backend.Backend, publicKey signature.PublicKey) (PK_PP, VK_PP, error) {
+	return circuitGenerator(b, publicKey, &myTransformations.DevelopCircuit{}, myTransformations.DevelopCircuitID)
+}
+
+// GrayGenerator creates the keys of grayscale captures taken by the camera with publicKey, for the given
+// proving system. Like the DisclosureGenerator, it does not need the camera's signing keys.
+func GrayGenerator(b backend.Backend, publicKey signature.PublicKey) (PK_PP, VK_PP, error) {
+	return circuitGenerator(b, publicKey, &myTransformations.GrayCircuit{}, myTransformations.GrayCircuitID)
+}
+
+// DeepGenerator creates the keys of deep color captures taken by the camera with publicKey, for the given
+// proving system. Like the DisclosureGenerator, it does not need the camera's signing keys.
+func DeepGenerator(b backend.Backend, publicKey signature.PublicKey) (PK_PP, VK_PP, error) {
+	return circuitGenerator(b, publicKey, &myTransformations.DeepCircuit{}, myTransformations.DeepCircuitID)
+}
+
+// CollageGenerator creates the keys of collages of rows x cols images, for the given proving system. The keys
+// are not tied to a camera: every input of a collage carries the key of its own proof.
+func CollageGenerator(b backend.Backend, rows, cols int) (PK_PP, VK_PP, error) {
+	if err := myTransformations.CheckGrid(rows, cols); err != nil {
+		return PK_PP{}, VK_PP{}, err
+	}
+	return circuitGenerator(b, nil, myTransformations.NewCollageCircuit(rows, cols), myTransformations.CollageCircuitID)
+}
+
+// circuitGenerator creates the keys of the compliance predicate circuit, recorded as id, over pictures
+// taken by the camera with publicKey, or by any camera if publicKey is nil.
+func circuitGenerator(b backend.Backend, publicKey signature.PublicKey, circuit frontend.Circuit, id myTransformations.CircuitID) (PK_PP, VK_PP, error) {
+	var compliance_predicate constraint.ConstraintSystem
+	var err error
+	if id == myTransformations.CollageCircuitID {
+		compliance_predicate, err = b.Compile(circuit) // The grid of a collage is not recorded in its CircuitID
+	} else {
+		compliance_predicate, err = Compile(b, id, circuit)
+	}
 	if err != nil {
-		fmt.Println(err.Error())
+		return PK_PP{}, VK_PP{}, err
+	}
+
+	provingKey, verifyingKey, err := b.Setup(compliance_predicate)
+	if err != nil {
+		return PK_PP{}, VK_PP{}, err
 	}
-	vk_PCD := VK_PP{VerifyingKey: verifyingKey, PublicKey: publicKey}
-	pk_PCD := PK_PP{ProvingKey: provingKey, PublicKey: publicKey}
 
-	return pk_PCD, vk_PCD, SK_PP{SecretKey: secretKey}, err
+	vk_PCD := VK_PP{VerifyingKey: verifyingKey, PublicKey: publicKey, Circuit: id, Backend: b.ID()}
+	pk_PCD := PK_PP{ProvingKey: provingKey, PublicKey: publicKey, Circuit: id, Backend: b.ID()}
+	return pk_PCD, vk_PCD, nil
 }
@@ -0,0 +1,21 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"src/backend"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// A cancelled Generator gives up with the error of its context, before compiling anything.
+func TestGeneratorWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	identity := myTransformations.Transformation{T: myTransformations.Identity}
+	if _, _, _, err := GeneratorWithContext(ctx, backend.Default, myImage.AllWhiteImage(), identity); !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error %v for a cancelled Generator", err)
+	}
+}
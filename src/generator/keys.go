@@ -0,0 +1,202 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/signature"
+
+	"src/backend"
+	myTransformations "src/transformations"
+)
+
+// Version of the key encoding written by MarshalJSON.
+// Keys written before the encoding was versioned have no version, which reads as 0.
+//
+//	0: crop circuit v1 keys, groth16
+//	1: records the circuit, groth16
+//	2: records the circuit and the backend
+//	3: records the editor key
+//	4: keeps the editor key with the secret key, out of the proving key
+const KeyFormatVersion = 4
+
+// JSON encodings of the Generator's outputs, so keys can be stored and handed to provers
+// and verifiers on other machines. Gnark keys are stored in their binary (compressed) form.
+type pkJSON struct {
+	Version    int                         `json:"version"`
+	Circuit    myTransformations.CircuitID `json:"circuit"`
+	Backend    backend.ID                  `json:"backend"`
+	ProvingKey []byte                      `json:"provingKey"`
+	PublicKey  []byte                      `json:"publicKey"`
+}
+
+type vkJSON struct {
+	Version      int                         `json:"version"`
+	Circuit      myTransformations.CircuitID `json:"circuit"`
+	Backend      backend.ID                  `json:"backend"`
+	VerifyingKey []byte                      `json:"verifyingKey"`
+	PublicKey    []byte                      `json:"publicKey"`
+	EditorKey    []byte                      `json:"editorKey,omitempty"`
+}
+
+type skJSON struct {
+	SecretKey []byte `json:"secretKey"`
+	EditorKey []byte `json:"editorKey,omitempty"`
+}
+
+func (pk PK_PP) MarshalJSON() ([]byte, error) {
+	var provingKey bytes.Buffer
+	if _, err := pk.ProvingKey.WriteTo(&provingKey); err != nil {
+		return nil, err
+	}
+	encoded := pkJSON{
+		Version:    KeyFormatVersion,
+		Circuit:    pk.Circuit,
+		Backend:    pk.Backend,
+		ProvingKey: provingKey.Bytes(),
+		PublicKey:  pk.PublicKey.Bytes(),
+	}
+	return json.Marshal(encoded)
+}
+
+func (pk *PK_PP) UnmarshalJSON(data []byte) error {
+	var decoded pkJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if err := migrateKeys(decoded.Version, &decoded.Circuit, &decoded.Backend); err != nil {
+		return err
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	provingKey := b.NewProvingKey()
+	if _, err := provingKey.ReadFrom(bytes.NewReader(decoded.ProvingKey)); err != nil {
+		return fmt.Errorf("invalid proving key: %w", err)
+	}
+	publicKey, err := PublicKeyFromBytes(decoded.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	*pk = PK_PP{ProvingKey: provingKey, PublicKey: publicKey, Circuit: decoded.Circuit, Backend: decoded.Backend}
+	return nil
+}
+
+func (vk VK_PP) MarshalJSON() ([]byte, error) {
+	var verifyingKey bytes.Buffer
+	if _, err := vk.VerifyingKey.WriteTo(&verifyingKey); err != nil {
+		return nil, err
+	}
+	encoded := vkJSON{
+		Version:      KeyFormatVersion,
+		Circuit:      vk.Circuit,
+		Backend:      vk.Backend,
+		VerifyingKey: verifyingKey.Bytes(),
+		PublicKey:    vk.PublicKey.Bytes(),
+	}
+	if vk.EditorKey != nil {
+		encoded.EditorKey = vk.EditorKey.Bytes()
+	}
+	return json.Marshal(encoded)
+}
+
+func (vk *VK_PP) UnmarshalJSON(data []byte) error {
+	var decoded vkJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if err := migrateKeys(decoded.Version, &decoded.Circuit, &decoded.Backend); err != nil {
+		return err
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	verifyingKey := b.NewVerifyingKey()
+	if _, err := verifyingKey.ReadFrom(bytes.NewReader(decoded.VerifyingKey)); err != nil {
+		return fmt.Errorf("invalid verifying key: %w", err)
+	}
+	publicKey, err := PublicKeyFromBytes(decoded.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	var editorKey signature.PublicKey
+	if decoded.EditorKey != nil {
+		if editorKey, err = PublicKeyFromBytes(decoded.EditorKey); err != nil {
+			return err
+		}
+	}
+
+	*vk = VK_PP{VerifyingKey: verifyingKey, PublicKey: publicKey, EditorKey: editorKey, Circuit: decoded.Circuit, Backend: decoded.Backend}
+	return nil
+}
+
+func (sk SK_PP) MarshalJSON() ([]byte, error) {
+	encoded := skJSON{SecretKey: sk.SecretKey.Bytes()}
+	if sk.EditorKey != nil {
+		encoded.EditorKey = sk.EditorKey.Bytes()
+	}
+	return json.Marshal(encoded)
+}
+
+func (sk *SK_PP) UnmarshalJSON(data []byte) error {
+	var decoded skJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	secretKey, err := SecretKeyFromBytes(decoded.SecretKey)
+	if err != nil {
+		return err
+	}
+
+	var editorKey signature.Signer
+	if decoded.EditorKey != nil {
+		if editorKey, err = SecretKeyFromBytes(decoded.EditorKey); err != nil {
+			return err
+		}
+	}
+
+	*sk = SK_PP{SecretKey: secretKey, EditorKey: editorKey}
+	return nil
+}
+
+// Bring the fields of keys encoded with the given format version up to KeyFormatVersion.
+func migrateKeys(version int, circuit *myTransformations.CircuitID, b *backend.ID) error {
+	if version > KeyFormatVersion {
+		return fmt.Errorf("key format version %d is newer than this build supports (%d): upgrade PhotoGnark to read these keys", version, KeyFormatVersion)
+	}
+	if version < 1 {
+		// Unversioned keys were all generated for the first version of the crop circuit.
+		*circuit = myTransformations.CircuitID{Name: "crop", Version: 1}
+	}
+	if version < 2 {
+		*b = backend.Groth16
+	}
+	return nil
+}
+
+// Decode a public digital signature key, as returned by signature.PublicKey.Bytes().
+func PublicKeyFromBytes(data []byte) (signature.PublicKey, error) {
+	var publicKey eddsa.PublicKey
+	if _, err := publicKey.SetBytes(data); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return &publicKey, nil
+}
+
+// Decode a secret digital signature key, as returned by signature.Signer.Bytes().
+func SecretKeyFromBytes(data []byte) (signature.Signer, error) {
+	var secretKey eddsa.PrivateKey
+	if _, err := secretKey.SetBytes(data); err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	return &secretKey, nil
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+
+	"src/backend"
+	myTransformations "src/transformations"
+)
+
+// The editor key of an SK_PP, and its public key in the VK_PP, survive their JSON encoding, and the proving key,
+// which is handed to provers, does not hold it.
+func TestEditorKey(t *testing.T) {
+	cameraKey, err := ceddsa.New(1, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	editorKey, err := ceddsa.New(1, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := circuitGenerator(backend.Default, cameraKey.Public(), &squareCircuit{}, myTransformations.CircuitID{Name: "square", Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vk.EditorKey != nil {
+		t.Fatal("keys without edits have an editor key")
+	}
+	vk.EditorKey = editorKey.Public()
+	sk := SK_PP{SecretKey: cameraKey, EditorKey: editorKey}
+
+	encoded, err := json.Marshal(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodedSK SK_PP
+	if err := json.Unmarshal(encoded, &decodedSK); err != nil {
+		t.Fatal(err)
+	}
+	if decodedSK.EditorKey == nil || !decodedSK.EditorKey.Public().Equal(editorKey.Public()) {
+		t.Error("the secret key lost its editor key")
+	}
+
+	if encoded, err = json.Marshal(pk); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encoded, []byte("editorKey")) {
+		t.Error("the proving key holds an editor key")
+	}
+
+	if encoded, err = json.Marshal(vk); err != nil {
+		t.Fatal(err)
+	}
+	var decodedVK VK_PP
+	if err := json.Unmarshal(encoded, &decodedVK); err != nil {
+		t.Fatal(err)
+	}
+	if decodedVK.EditorKey == nil || !decodedVK.EditorKey.Equal(editorKey.Public()) {
+		t.Error("the verifying key lost the public editor key")
+	}
+}
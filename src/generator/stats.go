@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+
+	"src/backend"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// Stats describe the compliance predicate of a transformation, so that users can budget the hardware that
+// proves it before generating its keys.
+type Stats struct {
+	Circuit       myTransformations.CircuitID `json:"circuit"`
+	Backend       backend.ID                  `json:"backend"`
+	Width         int                         `json:"width"`         // Width of the images of the predicate
+	Height        int                         `json:"height"`        // Height of the images of the predicate
+	Constraints   int                         `json:"constraints"`   // Constraints of the constraint system
+	Wires         int                         `json:"wires"`         // Public, secret and internal variables
+	PublicInputs  int                         `json:"publicInputs"`  // Inputs of the public witness
+	ProvingMemory int64                       `json:"provingMemory"` // Estimated bytes to hold the proving key and prove, see ProvingMemory
+}
+
+// Sizes of the affine points, and field elements, held by proving keys and provers over BN254.
+const (
+	g1Size = 64
+	g2Size = 128
+	frSize = 32
+)
+
+// Statistics compiles the compliance predicate of t for b, as the Generator does, and returns its Stats. The
+// predicate is compiled once per process, see Compile, so generating its keys afterwards does not compile it
+// again.
+func Statistics(b backend.Backend, t myTransformations.Transformation) (Stats, error) {
+	id, circuit, _, _, err := compliancePredicate(myImage.AllWhiteImage(), t)
+	if err != nil {
+		return Stats{}, err
+	}
+	ccs, err := Compile(b, id, circuit)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{
+		Circuit:       id,
+		Backend:       b.ID(),
+		Width:         myImage.Width,
+		Height:        myImage.Height,
+		Constraints:   ccs.GetNbConstraints(),
+		Wires:         ccs.GetNbPublicVariables() + ccs.GetNbSecretVariables() + ccs.GetNbInternalVariables(),
+		PublicInputs:  ccs.GetNbPublicVariables() - 1, // The first public variable is the constant 1
+		ProvingMemory: ProvingMemory(b.ID(), ccs),
+	}, nil
+}
+
+// ProvingMemory estimates the bytes a process proving ccs with the backend b needs, once ccs is compiled: the
+// constraint system, the proving key, and the values of the wires and polynomials of the prover, evaluated
+// over a domain of the next power of two of the constraints. The estimate doubles what is live, as Go's
+// garbage collector lets the heap grow to twice that by default. It is meant to compare predicates and size
+// machines within a factor of two, not to set memory limits; compiling takes more than proving.
+//
+//   - the constraint system takes about twice its encoding;
+//   - groth16 keys hold about two G1 points and one G2 point per wire, and one G1 point per constraint, and
+//     the prover about eight field elements per constraint for its FFTs;
+//   - plonk keys hold the canonical and Lagrange SRS, one G1 point each per constraint, and about fifteen
+//     field elements per constraint of selectors and permutations, and the prover about twenty more.
+func ProvingMemory(b backend.ID, ccs constraint.ConstraintSystem) int64 {
+	system, _ := ccs.WriteTo(io.Discard)
+	wires := int64(ccs.GetNbPublicVariables() + ccs.GetNbSecretVariables() + ccs.GetNbInternalVariables())
+	domain := int64(ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints() + ccs.GetNbPublicVariables())))
+
+	live := 2*system + wires*frSize
+	if b == backend.PLONK {
+		live += 2*domain*g1Size + 35*domain*frSize
+	} else {
+		live += wires*(2*g1Size+g2Size) + domain*g1Size + 8*domain*frSize
+	}
+	return 2 * live
+}
+
+// Scale estimates the Stats of the predicate for images of width x height pixels, assuming its constraints,
+// wires and memory grow with the number of pixels. The fixed costs of a predicate, e.g. verifying the
+// signature of its image, do not, so Scale overestimates larger images and underestimates smaller ones; the
+// predicates of this build are only compiled for image.Width x image.Height pixels.
+func (stats Stats) Scale(width, height int) Stats {
+	scale := func(n int64) int64 {
+		return n * int64(width*height) / int64(stats.Width*stats.Height)
+	}
+	scaled := stats
+	scaled.Width, scaled.Height = width, height
+	scaled.Constraints = int(scale(int64(stats.Constraints)))
+	scaled.Wires = int(scale(int64(stats.Wires)))
+	scaled.ProvingMemory = scale(stats.ProvingMemory)
+	return scaled
+}
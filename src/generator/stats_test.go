@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"testing"
+
+	"src/backend"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// The Stats of a predicate are those of its constraint system, and scale with the pixels of the images.
+func TestStatistics(t *testing.T) {
+	identity := myTransformations.Transformation{T: myTransformations.Identity}
+	stats, err := Statistics(backend.Default, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ccs, err := Compile(backend.Default, stats.Circuit, nil) // Compiled by Statistics, so the circuit is not needed
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Circuit != myTransformations.CropCircuitID || stats.Backend != backend.Default.ID() || stats.Width != myImage.Width || stats.Height != myImage.Height {
+		t.Errorf("unexpected predicate %+v", stats)
+	}
+	if stats.Constraints != ccs.GetNbConstraints() || stats.Wires <= stats.Constraints/2 || stats.ProvingMemory != ProvingMemory(backend.Default.ID(), ccs) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	// The digest of the statement, and no crop parameters for a crop disclosing none
+	if stats.PublicInputs != 1 {
+		t.Errorf("%d public inputs", stats.PublicInputs)
+	}
+
+	scaled := stats.Scale(2*myImage.Width, 2*myImage.Height)
+	if scaled.Width != 2*myImage.Width || scaled.Constraints != 4*stats.Constraints || scaled.ProvingMemory != 4*stats.ProvingMemory || scaled.PublicInputs != stats.PublicInputs {
+		t.Errorf("unexpected scaled stats %+v", scaled)
+	}
+
+	if _, err := Statistics(backend.Default, myTransformations.Transformation{T: -1}); err == nil {
+		t.Error("returned the stats of an unknown transformation")
+	}
+}
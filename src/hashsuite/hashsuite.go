@@ -0,0 +1,50 @@
+// Package hashsuite pairs the native hash functions used for signing images with the gadgets
+// computing the same functions inside the compliance predicates.
+package hashsuite
+
+import (
+	stdhash "hash"
+
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	gnarkhash "github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// A HashSuite is a hash function available both natively, to sign images and commit to them,
+// and as a gadget, to verify those signatures and commitments inside a circuit.
+// Both must compute the same function over BN254 field elements.
+type HashSuite interface {
+	// Name of the hash function.
+	Name() string
+
+	// New returns the native hash function.
+	New() stdhash.Hash
+
+	// NewGadget returns the hash function as a gadget of the circuit being defined with api.
+	NewGadget(api frontend.API) (gnarkhash.FieldHasher, error)
+}
+
+// MiMC over the BN254 scalar field.
+// (Poseidon is not available as a gadget in the gnark version this module depends on.)
+type MiMC struct{}
+
+func (MiMC) Name() string {
+	return "mimc-bn254"
+}
+
+func (MiMC) New() stdhash.Hash {
+	return hash.MIMC_BN254.New()
+}
+
+func (MiMC) NewGadget(api frontend.API) (gnarkhash.FieldHasher, error) {
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return nil, err
+	}
+	return &mimc, nil
+}
+
+// Default is the HashSuite used by the camera, the circuits and the verifier.
+// Keys, signatures and proofs are only compatible between builds using the same Default.
+var Default HashSuite = MiMC{}
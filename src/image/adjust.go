@@ -0,0 +1,247 @@
+package image
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+)
+
+// Largest brightness change of Brighten, which turns any channel black or white.
+const MaxBrightnessDelta = 255
+
+// Brighten returns the image with delta added to every channel within the width and height of its metadata,
+// clamped to [0, 255], as a new image with a copy of the metadata, and its perceptual hash updated if it has
+// one. A negative delta darkens the image; delta must lie within [-MaxBrightnessDelta, MaxBrightnessDelta].
+func (img I) Brighten(delta int) (I, error) {
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		return I{}, fmt.Errorf("invalid image metadata for width and height")
+	}
+	if delta < -MaxBrightnessDelta || delta > MaxBrightnessDelta {
+		return I{}, fmt.Errorf("invalid brightness delta %d: expected a value in [%d, %d]", delta, -MaxBrightnessDelta, MaxBrightnessDelta)
+	}
+
+	brightened := img.Clone()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := &brightened.Pixels[y][x]
+			p.R, p.G, p.B = clampChannel(int(p.R)+delta), clampChannel(int(p.G)+delta), clampChannel(int(p.B)+delta)
+		}
+	}
+	if brightened.M.DHash != nil {
+		brightened.SetDHash()
+	}
+	return brightened, nil
+}
+
+// Gammas are the permissible gamma corrections, in hundredths: 220 encodes linear values for display,
+// brightening the midtones, 45 decodes them, and 100 keeps the image.
+var Gammas = [...]int{45, 50, 80, 100, 125, 180, 220}
+
+// GammaLUT returns the lookup table of the gamma correction gamma, in hundredths, one of Gammas: every channel
+// value v maps to 255 * (v / 255)^(100 / gamma), rounded down, which maps 0 and 255 to themselves. The power
+// is computed on integers, as the largest value out with (out / 255)^q <= (v / 255)^p for 100 / gamma = p / q,
+// so that every build has the same table.
+func GammaLUT(gamma int) (*[256]uint8, error) {
+	if !slices.Contains(Gammas[:], gamma) {
+		return nil, fmt.Errorf("invalid gamma %d: expected one of %v, in hundredths", gamma, Gammas)
+	}
+	divisor := new(big.Int).GCD(nil, nil, big.NewInt(100), big.NewInt(int64(gamma))).Int64()
+	p, q := big.NewInt(100/divisor), big.NewInt(int64(gamma)/divisor)
+	white := big.NewInt(255)
+
+	var lut [256]uint8
+	for v := range lut {
+		// v^p * 255^q, compared against out^q * 255^p
+		bound := new(big.Int).Mul(new(big.Int).Exp(big.NewInt(int64(v)), p, nil), new(big.Int).Exp(white, q, nil))
+		whiteP := new(big.Int).Exp(white, p, nil)
+		lut[v] = uint8(sort.Search(256, func(out int) bool {
+			power := new(big.Int).Exp(big.NewInt(int64(out)), q, nil)
+			return power.Mul(power, whiteP).Cmp(bound) > 0
+		}) - 1)
+	}
+	return &lut, nil
+}
+
+// GammaCorrect returns the image with every channel mapped by the GammaLUT of gamma, in hundredths, as a new
+// image with a copy of the metadata, and its perceptual hash updated if it has one. Black maps to black, so
+// the pixels outside the size of the image stay black.
+func (img I) GammaCorrect(gamma int) (I, error) {
+	lut, err := GammaLUT(gamma)
+	if err != nil {
+		return I{}, err
+	}
+
+	corrected := img.Clone()
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := &corrected.Pixels[y][x]
+			p.R, p.G, p.B = lut[p.R], lut[p.G], lut[p.B]
+		}
+	}
+	if corrected.M.DHash != nil {
+		corrected.SetDHash()
+	}
+	return corrected, nil
+}
+
+// Largest saturation of SaturationMatrix, out of 256, which doubles the saturation of an image.
+const MaxSaturation = 512
+
+// SaturationMatrix returns the ColorMatrix that scales the saturation of a pixel by saturation out of 256, in
+// [0, MaxSaturation]: every channel moves away from the luma of the pixel, with the weights of Luma, or
+// towards it, so that 0 makes the pixel gray and 256 keeps it. Grays keep their value.
+func SaturationMatrix(saturation int) (ColorMatrix, error) {
+	if saturation < 0 || saturation > MaxSaturation {
+		return ColorMatrix{}, fmt.Errorf("invalid saturation %d: expected a value in [0, %d], out of 256", saturation, MaxSaturation)
+	}
+	weights := [3]int{77, 150, 29}
+	var matrix ColorMatrix
+	for c := range matrix {
+		for k := range matrix[c] {
+			matrix[c][k] = weights[k] * (256 - saturation)
+		}
+		matrix[c][c] += 256 * saturation
+	}
+	return matrix, nil
+}
+
+// Hues are the permissible hue rotations of HueMatrix, in degrees.
+var Hues = [...]int{0, 30, 60, 90, 120, 150, 180, 210, 240, 270, 300, 330}
+
+// The rotation of the chroma plane of YIQ by a right angle, in RGB, out of 65536. Every row sums to 0.
+var hueQuarter = ColorMatrix{
+	{11051, 21614, -32665},
+	{-21493, 2268, 19225},
+	{81688, -68369, -13319},
+}
+
+// The cosine and sine of 0, 30 and 60 degrees, out of 65536.
+var hueCosSin = [3][2]int{{65536, 0}, {56756, 32768}, {32768, 56756}}
+
+// HueMatrix returns the ColorMatrix that rotates the hue of a pixel by hue degrees, one of Hues: the chroma
+// plane of YIQ is rotated and the luma, with the weights of Luma, is kept. The weights are computed on integers
+// so that every build has the same matrices, and every row sums to 65536, so that grays keep their value. A hue
+// of 0 keeps every pixel.
+func HueMatrix(hue int) (ColorMatrix, error) {
+	if !slices.Contains(Hues[:], hue) {
+		return ColorMatrix{}, fmt.Errorf("invalid hue %d: expected one of %v, in degrees", hue, Hues)
+	}
+	cos, sin := hueCosSin[hue%90/30][0], hueCosSin[hue%90/30][1]
+	for range hue / 90 {
+		cos, sin = -sin, cos
+	}
+
+	weights := [3]int{77, 150, 29}
+	var matrix ColorMatrix
+	for c := range matrix {
+		for k := range matrix[c] {
+			luma := weights[k] << (ColorMatrixBits - 8)
+			chroma := -luma
+			if c == k {
+				chroma += 1 << ColorMatrixBits
+			}
+			matrix[c][k] = luma + (cos*chroma+sin*hueQuarter[c][k]+1<<(ColorMatrixBits-1))>>ColorMatrixBits
+		}
+		matrix[c][c] = 1 << ColorMatrixBits
+		for k := range matrix[c] {
+			if k != c {
+				matrix[c][c] -= matrix[c][k]
+			}
+		}
+	}
+	return matrix, nil
+}
+
+// AdjustHueSaturation returns the image with its hue rotated by hue degrees, one of Hues, and then its
+// saturation scaled by saturation out of 256, in [0, MaxSaturation]: every pixel mapped by the HueMatrix and then
+// by the SaturationMatrix, each rounded and clamped like ApplyColorMatrix does. It is a new image with a copy
+// of the metadata, and its perceptual hash updated if it has one. A hue of 0 and a saturation of 256 keep the
+// image.
+func (img I) AdjustHueSaturation(hue, saturation int) (I, error) {
+	hueMatrix, err := HueMatrix(hue)
+	if err != nil {
+		return I{}, err
+	}
+	saturationMatrix, err := SaturationMatrix(saturation)
+	if err != nil {
+		return I{}, err
+	}
+	return img.ApplyColorMatrix(&hueMatrix).ApplyColorMatrix(&saturationMatrix), nil
+}
+
+// Largest gain of GainMatrix, out of 256, which triples a channel.
+const MaxChannelGain = 768
+
+// GainMatrix returns the ColorMatrix that multiplies the R, G and B of a pixel by their gains, out of 256, in
+// [0, MaxChannelGain]: a white balance when the gains differ, an exposure correction when they do not. Gains
+// of 256 keep every pixel.
+func GainMatrix(gains [3]int) (ColorMatrix, error) {
+	var matrix ColorMatrix
+	for c, gain := range gains {
+		if gain < 0 || gain > MaxChannelGain {
+			return ColorMatrix{}, fmt.Errorf("invalid channel gain %d: expected a gain in [0, %d], out of 256", gain, MaxChannelGain)
+		}
+		matrix[c][c] = gain << (ColorMatrixBits - 8)
+	}
+	return matrix, nil
+}
+
+// ApplyGains returns the image with its R, G and B multiplied by their gains, out of 256, in
+// [0, MaxChannelGain]: every pixel mapped by the GainMatrix, rounded and clamped like ApplyColorMatrix does. It
+// is a new image with a copy of the metadata, and its perceptual hash updated if it has one.
+func (img I) ApplyGains(gains [3]int) (I, error) {
+	matrix, err := GainMatrix(gains)
+	if err != nil {
+		return I{}, err
+	}
+	return img.ApplyColorMatrix(&matrix), nil
+}
+
+// Largest threshold of Binarize, which makes every pixel black.
+const MaxThreshold = 255
+
+// Binarize returns the image thresholded at threshold, in [0, MaxThreshold]: every pixel whose Luma is above the
+// threshold becomes white, and every other pixel black, e.g. to clean up the photo of a document. It is a new
+// image with a copy of the metadata, and its perceptual hash updated if it has one. Black stays black.
+func (img I) Binarize(threshold int) (I, error) {
+	if threshold < 0 || threshold > MaxThreshold {
+		return I{}, fmt.Errorf("invalid threshold %d: expected a luma in [0, %d]", threshold, MaxThreshold)
+	}
+	binarized := img.Clone()
+	binarized.Map(func(_, _ int, p RGBPixel) RGBPixel {
+		if int(Luma(p)) > threshold {
+			return RGBPixel{R: 255, G: 255, B: 255}
+		}
+		return RGBPixel{}
+	})
+	if binarized.M.DHash != nil {
+		binarized.SetDHash()
+	}
+	return binarized, nil
+}
+
+// Source of a channel of SwapChannels that drops it: the channel becomes 0.
+const DroppedChannel = 3
+
+// SwapChannels returns the image with its channels reordered or dropped: channel c of every pixel, R, G or B,
+// becomes channel sources[c] of the pixel, in [0, 2], or 0 for a source of DroppedChannel, e.g. sources of
+// {2, 1, 0} swap red and blue, and {0, 1, DroppedChannel} drop blue. It is a new image with a copy of the
+// metadata, and its perceptual hash updated if it has one. Sources of {0, 1, 2} keep the image.
+func (img I) SwapChannels(sources [3]int) (I, error) {
+	for _, source := range sources {
+		if source < 0 || source > DroppedChannel {
+			return I{}, fmt.Errorf("invalid channel source %d: expected a channel in [0, 2], or %d to drop it", source, DroppedChannel)
+		}
+	}
+	swapped := img.Clone()
+	swapped.Map(func(_, _ int, p RGBPixel) RGBPixel {
+		channels := [DroppedChannel + 1]uint8{p.R, p.G, p.B, 0}
+		return RGBPixel{R: channels[sources[0]], G: channels[sources[1]], B: channels[sources[2]]}
+	})
+	if swapped.M.DHash != nil {
+		swapped.SetDHash()
+	}
+	return swapped, nil
+}
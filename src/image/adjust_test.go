@@ -0,0 +1,264 @@
+package image
+
+import "testing"
+
+func TestBrighten(t *testing.T) {
+	img, err := CoordinateImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	brightened, err := img.Brighten(200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// R is x and G is y, so they do not reach 255, and B is x*12+y, which does past x = 4
+	if brightened.Pixels[1][2] != (RGBPixel{R: 202, G: 201, B: 225}) || brightened.Pixels[0][9].B != 255 || brightened.Pixels[0][10] != (RGBPixel{}) {
+		t.Errorf("the image is not brightened within its size, clamped to 255")
+	}
+	if *brightened.M.DHash != brightened.DHash() || img.Pixels[1][2].R != 2 {
+		t.Errorf("the perceptual hash is stale, or Brighten changed the image")
+	}
+	if darkened, _ := img.Brighten(-20); darkened.Pixels[1][2] != (RGBPixel{R: 0, G: 0, B: 5}) {
+		t.Errorf("a darkened pixel is %v", darkened.Pixels[1][2])
+	}
+	for _, delta := range []int{-MaxBrightnessDelta - 1, MaxBrightnessDelta + 1} {
+		if _, err := img.Brighten(delta); err == nil {
+			t.Errorf("the image was brightened by %d", delta)
+		}
+	}
+	if _, err := NewImage().Brighten(1); err == nil {
+		t.Errorf("an image without a size was brightened")
+	}
+}
+
+func TestGammaLUT(t *testing.T) {
+	// 255 * (v / 255)^(100 / gamma), rounded down
+	for gamma, want := range map[int][3]uint8{45: {11, 55, 148}, 100: {64, 128, 200}, 220: {136, 186, 228}} {
+		lut, err := GammaLUT(gamma)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := [3]uint8{lut[64], lut[128], lut[200]}; got != want {
+			t.Errorf("gamma %d maps 64, 128 and 200 to %v, expected %v", gamma, got, want)
+		}
+	}
+	for _, gamma := range Gammas {
+		lut, _ := GammaLUT(gamma)
+		if lut[0] != 0 || lut[255] != 255 {
+			t.Errorf("gamma %d does not keep black and white", gamma)
+		}
+		for v := 1; v < len(lut); v++ {
+			if lut[v] < lut[v-1] {
+				t.Errorf("gamma %d is not monotonic at %d", gamma, v)
+			}
+		}
+	}
+	if _, err := GammaLUT(0); err == nil {
+		t.Errorf("a gamma of 0 has a table")
+	}
+}
+
+func TestGammaCorrect(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	corrected, err := img.GammaCorrect(220)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lut, _ := GammaLUT(220)
+	p := img.Pixels[3][7]
+	if corrected.Pixels[3][7] != (RGBPixel{R: lut[p.R], G: lut[p.G], B: lut[p.B]}) || corrected.Pixels[0][10] != (RGBPixel{}) {
+		t.Errorf("the image is not gamma corrected, or its black border is not kept")
+	}
+	if *corrected.M.DHash != corrected.DHash() || img.Pixels[3][7] != p {
+		t.Errorf("the perceptual hash is stale, or GammaCorrect changed the image")
+	}
+	if same, _ := img.GammaCorrect(100); same.Pixels != img.Pixels {
+		t.Errorf("a gamma of 100 changed the image")
+	}
+	if _, err := img.GammaCorrect(221); err == nil {
+		t.Errorf("the image was corrected with a gamma outside Gammas")
+	}
+}
+
+func TestHueSaturationMatrices(t *testing.T) {
+	identity := ColorMatrix{{1 << ColorMatrixBits, 0, 0}, {0, 1 << ColorMatrixBits, 0}, {0, 0, 1 << ColorMatrixBits}}
+	if m, _ := HueMatrix(0); m != identity {
+		t.Errorf("a hue of 0 is %v", m)
+	}
+	if m, _ := SaturationMatrix(256); m != identity {
+		t.Errorf("a saturation of 256 is %v", m)
+	}
+	if m, _ := HueMatrix(90); m != (ColorMatrix{{30763, 60014, -25241}, {-1781, 40668, 26649}, {101400, -29969, -5895}}) {
+		t.Errorf("a hue of 90 is %v", m)
+	}
+
+	// Grays keep their value, and a saturation of 0 makes every pixel gray
+	for _, hue := range Hues {
+		m, _ := HueMatrix(hue)
+		for v := 0; v < 256; v += 15 {
+			gray := RGBPixel{R: uint8(v), G: uint8(v), B: uint8(v)}
+			if got := m.Apply(gray); got != gray {
+				t.Errorf("hue %d maps gray %v to %v", hue, gray, got)
+			}
+		}
+	}
+	gray, _ := SaturationMatrix(0)
+	p := RGBPixel{R: 200, G: 100, B: 50}
+	if got := gray.Apply(p); got != (RGBPixel{R: Luma(p), G: Luma(p), B: Luma(p)}) {
+		t.Errorf("a saturation of 0 maps %v to %v", p, got)
+	}
+
+	for _, hue := range []int{-30, 45, 360} {
+		if _, err := HueMatrix(hue); err == nil {
+			t.Errorf("a hue of %d has a matrix", hue)
+		}
+	}
+	for _, saturation := range []int{-1, MaxSaturation + 1} {
+		if _, err := SaturationMatrix(saturation); err == nil {
+			t.Errorf("a saturation of %d has a matrix", saturation)
+		}
+	}
+}
+
+func TestAdjustHueSaturation(t *testing.T) {
+	img, err := NoiseImage(4).SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	adjusted, err := img.AdjustHueSaturation(120, 384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hue, _ := HueMatrix(120)
+	saturation, _ := SaturationMatrix(384)
+	if adjusted.Pixels[2][5] != saturation.Apply(hue.Apply(img.Pixels[2][5])) || adjusted.Pixels[0][10] != (RGBPixel{}) {
+		t.Errorf("the image is not rotated in hue and then saturated")
+	}
+	if *adjusted.M.DHash != adjusted.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+	if kept, _ := img.AdjustHueSaturation(0, 256); kept.Pixels != img.Pixels {
+		t.Errorf("a hue of 0 and a saturation of 256 changed the image")
+	}
+	if _, err := img.AdjustHueSaturation(0, MaxSaturation+1); err == nil {
+		t.Errorf("the image was saturated past MaxSaturation")
+	}
+}
+
+func TestApplyGains(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	// A warm correction: red up by half, blue down by a quarter, green kept
+	balanced, err := img.ApplyGains([3]int{384, 256, 192})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := img.Pixels[3][7]
+	want := RGBPixel{R: uint8(min((3*int(p.R)+1)/2, 255)), G: p.G, B: uint8((3*int(p.B) + 2) / 4)}
+	if balanced.Pixels[3][7] != want || balanced.Pixels[0][10] != (RGBPixel{}) {
+		t.Errorf("%v is balanced to %v, expected %v", p, balanced.Pixels[3][7], want)
+	}
+	if *balanced.M.DHash != balanced.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+
+	// Tripled and clamped
+	if exposed, _ := img.ApplyGains([3]int{MaxChannelGain, MaxChannelGain, MaxChannelGain}); exposed.Pixels[3][7] != (RGBPixel{R: uint8(min(3*int(p.R), 255)), G: uint8(min(3*int(p.G), 255)), B: uint8(min(3*int(p.B), 255))}) {
+		t.Errorf("a gain of 3 maps %v to %v", p, exposed.Pixels[3][7])
+	}
+	if kept, _ := img.ApplyGains([3]int{256, 256, 256}); kept.Pixels != img.Pixels {
+		t.Errorf("gains of 256 changed the image")
+	}
+	for _, gains := range [][3]int{{-1, 256, 256}, {256, 256, MaxChannelGain + 1}} {
+		if _, err := img.ApplyGains(gains); err == nil {
+			t.Errorf("the image was balanced with gains %v", gains)
+		}
+	}
+}
+
+func TestBinarize(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	binarized, err := img.Binarize(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			want := RGBPixel{}
+			if Luma(img.Pixels[y][x]) > 128 {
+				want = RGBPixel{R: 255, G: 255, B: 255}
+			}
+			if binarized.Pixels[y][x] != want {
+				t.Fatalf("%v is binarized to %v, expected %v", img.Pixels[y][x], binarized.Pixels[y][x], want)
+			}
+		}
+	}
+	if *binarized.M.DHash != binarized.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+
+	// White is above every threshold but the largest, which makes the image black
+	white := AllWhiteImage()
+	if kept, _ := white.Binarize(MaxThreshold - 1); kept.Pixels != white.Pixels {
+		t.Errorf("white is not above threshold %d", MaxThreshold-1)
+	}
+	if black, _ := white.Binarize(MaxThreshold); black.Pixels != (I{}).Pixels {
+		t.Errorf("white is above threshold %d", MaxThreshold)
+	}
+	for _, threshold := range []int{-1, MaxThreshold + 1} {
+		if _, err := img.Binarize(threshold); err == nil {
+			t.Errorf("the image was binarized at threshold %d", threshold)
+		}
+	}
+}
+
+func TestSwapChannels(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	// Red and blue swapped, then blue dropped
+	swapped, err := img.SwapChannels([3]int{2, 1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := img.Pixels[3][7]
+	if swapped.Pixels[3][7] != (RGBPixel{R: p.B, G: p.G, B: p.R}) {
+		t.Errorf("%v is swapped to %v", p, swapped.Pixels[3][7])
+	}
+	if *swapped.M.DHash != swapped.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+	if dropped, _ := img.SwapChannels([3]int{0, 1, DroppedChannel}); dropped.Pixels[3][7] != (RGBPixel{R: p.R, G: p.G}) {
+		t.Errorf("%v is dropped to %v", p, dropped.Pixels[3][7])
+	}
+
+	if kept, _ := img.SwapChannels([3]int{0, 1, 2}); kept.Pixels != img.Pixels {
+		t.Errorf("sources {0, 1, 2} changed the image")
+	}
+	for _, sources := range [][3]int{{-1, 1, 2}, {0, 1, DroppedChannel + 1}} {
+		if _, err := img.SwapChannels(sources); err == nil {
+			t.Errorf("the channels were swapped with sources %v", sources)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package image
+
+import "fmt"
+
+// A Rect is a rectangle of pixels, from (X0, Y0) to (X1, Y1) included, like the rectangle of Crop.
+type Rect struct {
+	X0, Y0, X1, Y1 int
+}
+
+// Fill sets every pixel of the image to color. Like SetPixel, the bulk operations change pixels only: a
+// perceptual hash stored in the metadata is not updated, see SetDHash.
+func (img *I) Fill(color RGBPixel) {
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			img.Pixels[y][x] = color
+		}
+	}
+}
+
+// SetRegion sets the pixels of rect to pixels, indexed [y][x] from the top left corner of rect like the pixels
+// of an image. It returns an error, and changes nothing, if rect is not within the image or pixels is not of
+// its size.
+func (img *I) SetRegion(rect Rect, pixels [][]RGBPixel) error {
+	if rect.X0 < 0 || rect.Y0 < 0 || rect.X1 >= Width || rect.Y1 >= Height || rect.X0 > rect.X1 || rect.Y0 > rect.Y1 {
+		return fmt.Errorf("invalid region %+v: out of bounds", rect)
+	}
+	width, height := rect.X1-rect.X0+1, rect.Y1-rect.Y0+1
+	if len(pixels) != height {
+		return fmt.Errorf("invalid region: %d rows of pixels, expected %d", len(pixels), height)
+	}
+	for y, row := range pixels {
+		if len(row) != width {
+			return fmt.Errorf("invalid region: %d pixels in row %d, expected %d", len(row), y, width)
+		}
+	}
+	for y, row := range pixels {
+		copy(img.Pixels[rect.Y0+y][rect.X0:rect.X1+1], row)
+	}
+	return nil
+}
+
+// Map sets every pixel of the image to f of its coordinates and color, row by row.
+func (img *I) Map(f func(x, y int, p RGBPixel) RGBPixel) {
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			img.Pixels[y][x] = f(x, y, img.Pixels[y][x])
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package image
+
+import "testing"
+
+func TestBulk(t *testing.T) {
+	img := NewImage()
+	img.Fill(RGBPixel{R: 9})
+	if img.Pixels[0][0] != (RGBPixel{R: 9}) || img.Pixels[Height-1][Width-1] != (RGBPixel{R: 9}) {
+		t.Errorf("Fill left pixels unset")
+	}
+
+	img.Map(func(x, y int, p RGBPixel) RGBPixel {
+		return RGBPixel{R: p.R, G: uint8(x), B: uint8(y)}
+	})
+	if img.Pixels[3][5] != (RGBPixel{R: 9, G: 5, B: 3}) {
+		t.Errorf("Map set pixel (5, 3) to %v", img.Pixels[3][5])
+	}
+
+	region := [][]RGBPixel{{{R: 1}, {R: 2}, {R: 3}}, {{R: 4}, {R: 5}, {R: 6}}}
+	if err := img.SetRegion(Rect{X0: 2, Y0: 7, X1: 4, Y1: 8}, region); err != nil {
+		t.Fatal(err)
+	}
+	if img.Pixels[7][2] != (RGBPixel{R: 1}) || img.Pixels[8][4] != (RGBPixel{R: 6}) || img.Pixels[7][5] != (RGBPixel{R: 9, G: 5, B: 7}) {
+		t.Errorf("SetRegion set the wrong pixels")
+	}
+
+	before := img.Pixels
+	for name, rect := range map[string]Rect{
+		"past the edge":   {X0: Width - 2, Y0: 0, X1: Width, Y1: 1},
+		"negative":        {X0: -1, Y0: 0, X1: 1, Y1: 1},
+		"of another size": {X0: 0, Y0: 0, X1: 1, Y1: 1},
+	} {
+		if err := img.SetRegion(rect, region); err == nil {
+			t.Errorf("set a region %s", name)
+		}
+	}
+	if img.Pixels != before {
+		t.Errorf("a rejected region changed the image")
+	}
+}
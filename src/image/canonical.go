@@ -0,0 +1,361 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+/*
+The canonical encoding of an image is what its Digest commits to, so it must be the same for the
+same image on every run and every machine, which JSON is not: map order and float formatting vary. It is, in
+this fixed order, big endian:
+
+	magic       "PGI"
+	version     1 byte, CanonicalVersion
+	kind        1 byte: 'I' for an image I, 'G' for a Gray, 'D' for a Deep, 'R' for a RAW
+	width       uint16, the constant Width
+	height      uint16, the constant Height
+	pixels      row by row: R, G, B of 1 byte (I), 1 byte (Gray), R, G, B of 2 bytes (Deep), 2 bytes (RAW)
+	author      a string: its length as uint32, then its bytes
+	timestamp   1 byte, 0 if unknown, or 1 then the Unix time as int64 seconds and uint32 nanoseconds
+	GPS         1 byte, 0 if unknown, or 1 then the latitude, longitude and altitude as the bits of float64s
+	device ID   a string
+	dHash       1 byte, 0 if not stored, or 1 then the perceptual hash as uint64
+	size        width and height of the Metadata as uint16
+
+The fields after the pixels are those of Metadata, in its order. Version 1 encoded the metadata as a
+free-form map, and version 2 had no perceptual hash; they are no longer read.
+*/
+
+// Version of the canonical encoding written by MarshalBinary.
+const CanonicalVersion = 3
+
+var canonicalMagic = []byte("PGI")
+
+// Kinds of images of the canonical encoding.
+const (
+	kindImage = 'I'
+	kindGray  = 'G'
+	kindDeep  = 'D'
+	kindRAW   = 'R'
+)
+
+// MarshalBinary returns the canonical encoding of the image, which its Digest commits to (see digest.go).
+func (img I) MarshalBinary() ([]byte, error) {
+	data := canonicalHeader(kindImage)
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := img.Pixels[y][x]
+			data = append(data, p.R, p.G, p.B)
+		}
+	}
+	return appendMetadata(data, img.M)
+}
+
+// UnmarshalBinary decodes the canonical encoding of an image, as returned by MarshalBinary.
+func (img *I) UnmarshalBinary(data []byte) error {
+	r, err := readCanonicalHeader(data, kindImage)
+	if err != nil {
+		return err
+	}
+	var decoded I
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			channels := r.next(3)
+			if channels == nil {
+				return r.err
+			}
+			decoded.Pixels[y][x] = RGBPixel{R: channels[0], G: channels[1], B: channels[2]}
+		}
+	}
+	if decoded.M, err = r.metadata(); err != nil {
+		return err
+	}
+	*img = decoded
+	return nil
+}
+
+// MarshalBinary returns the canonical encoding of the grayscale image, which its Digest commits to (see digest.go).
+func (gray Gray) MarshalBinary() ([]byte, error) {
+	data := canonicalHeader(kindGray)
+	for y := 0; y < Height; y++ {
+		data = append(data, gray.Pixels[y][:]...)
+	}
+	return appendMetadata(data, gray.M)
+}
+
+// UnmarshalBinary decodes the canonical encoding of a grayscale image, as returned by MarshalBinary.
+func (gray *Gray) UnmarshalBinary(data []byte) error {
+	r, err := readCanonicalHeader(data, kindGray)
+	if err != nil {
+		return err
+	}
+	var decoded Gray
+	for y := 0; y < Height; y++ {
+		row := r.next(Width)
+		if row == nil {
+			return r.err
+		}
+		copy(decoded.Pixels[y][:], row)
+	}
+	if decoded.M, err = r.metadata(); err != nil {
+		return err
+	}
+	*gray = decoded
+	return nil
+}
+
+// MarshalBinary returns the canonical encoding of the deep color image, which its Digest commits to (see digest.go).
+func (deep Deep) MarshalBinary() ([]byte, error) {
+	data := canonicalHeader(kindDeep)
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := deep.Pixels[y][x]
+			data = binary.BigEndian.AppendUint16(data, p.R)
+			data = binary.BigEndian.AppendUint16(data, p.G)
+			data = binary.BigEndian.AppendUint16(data, p.B)
+		}
+	}
+	return appendMetadata(data, deep.M)
+}
+
+// UnmarshalBinary decodes the canonical encoding of a deep color image, as returned by MarshalBinary.
+func (deep *Deep) UnmarshalBinary(data []byte) error {
+	r, err := readCanonicalHeader(data, kindDeep)
+	if err != nil {
+		return err
+	}
+	var decoded Deep
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			channels := r.next(6)
+			if channels == nil {
+				return r.err
+			}
+			decoded.Pixels[y][x] = DeepPixel{
+				R: binary.BigEndian.Uint16(channels),
+				G: binary.BigEndian.Uint16(channels[2:]),
+				B: binary.BigEndian.Uint16(channels[4:]),
+			}
+		}
+	}
+	if decoded.M, err = r.metadata(); err != nil {
+		return err
+	}
+	*deep = decoded
+	return nil
+}
+
+// MarshalBinary returns the canonical encoding of the RAW capture, which its Digest commits to (see digest.go).
+func (raw RAW) MarshalBinary() ([]byte, error) {
+	data := canonicalHeader(kindRAW)
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			data = binary.BigEndian.AppendUint16(data, raw.Samples[y][x])
+		}
+	}
+	return appendMetadata(data, raw.M)
+}
+
+// UnmarshalBinary decodes the canonical encoding of a RAW capture, as returned by MarshalBinary.
+func (raw *RAW) UnmarshalBinary(data []byte) error {
+	r, err := readCanonicalHeader(data, kindRAW)
+	if err != nil {
+		return err
+	}
+	var decoded RAW
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			sample := r.next(2)
+			if sample == nil {
+				return r.err
+			}
+			decoded.Samples[y][x] = binary.BigEndian.Uint16(sample)
+		}
+	}
+	if decoded.M, err = r.metadata(); err != nil {
+		return err
+	}
+	*raw = decoded
+	return nil
+}
+
+// The magic, version, kind and size that start the canonical encoding of an image of kind.
+func canonicalHeader(kind byte) []byte {
+	data := append([]byte{}, canonicalMagic...)
+	data = append(data, CanonicalVersion, kind)
+	data = binary.BigEndian.AppendUint16(data, Width)
+	return binary.BigEndian.AppendUint16(data, Height)
+}
+
+// Append the canonical encoding of metadata, after checking it.
+func appendMetadata(data []byte, m Metadata) ([]byte, error) {
+	if err := m.Check(); err != nil {
+		return nil, err
+	}
+	data = appendString(data, m.Author)
+	if m.Timestamp.IsZero() {
+		data = append(data, 0)
+	} else {
+		data = binary.BigEndian.AppendUint64(append(data, 1), uint64(m.Timestamp.Unix()))
+		data = binary.BigEndian.AppendUint32(data, uint32(m.Timestamp.Nanosecond()))
+	}
+	if m.GPS == nil {
+		data = append(data, 0)
+	} else {
+		data = append(data, 1)
+		for _, v := range []float64{m.GPS.Latitude, m.GPS.Longitude, m.GPS.Altitude} {
+			data = binary.BigEndian.AppendUint64(data, math.Float64bits(v))
+		}
+	}
+	data = appendString(data, m.DeviceID)
+	if m.DHash == nil {
+		data = append(data, 0)
+	} else {
+		data = binary.BigEndian.AppendUint64(append(data, 1), *m.DHash)
+	}
+	data = binary.BigEndian.AppendUint16(data, uint16(m.Width))
+	return binary.BigEndian.AppendUint16(data, uint16(m.Height)), nil
+}
+
+func appendString(data []byte, s string) []byte {
+	data = binary.BigEndian.AppendUint32(data, uint32(len(s)))
+	return append(data, s...)
+}
+
+// A reader of a canonical encoding. next returns nil past the end of the data, and sets err.
+type canonicalReader struct {
+	data []byte
+	err  error
+}
+
+func (r *canonicalReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("invalid canonical encoding: truncated")
+		return nil
+	}
+	next := r.data[:n]
+	r.data = r.data[n:]
+	return next
+}
+
+// Check the header of the canonical encoding of an image of kind, and return a reader of what follows it.
+func readCanonicalHeader(data []byte, kind byte) (*canonicalReader, error) {
+	r := &canonicalReader{data: data}
+	header := r.next(len(canonicalMagic) + 6)
+	if header == nil || !bytes.HasPrefix(header, canonicalMagic) {
+		return nil, fmt.Errorf("invalid canonical encoding: no header")
+	}
+	header = header[len(canonicalMagic):]
+	if header[0] != CanonicalVersion {
+		return nil, fmt.Errorf("canonical encoding version %d is not supported by this build (%d): upgrade PhotoGnark to read this image", header[0], CanonicalVersion)
+	}
+	if header[1] != kind {
+		return nil, fmt.Errorf("invalid canonical encoding: an image of kind %q, expected %q", header[1], kind)
+	}
+	if width, height := binary.BigEndian.Uint16(header[2:]), binary.BigEndian.Uint16(header[4:]); width != Width || height != Height {
+		return nil, fmt.Errorf("invalid image: %d x %d pixels, expected %d x %d", width, height, Width, Height)
+	}
+	return r, nil
+}
+
+// Read the metadata that ends a canonical encoding.
+func (r *canonicalReader) metadata() (Metadata, error) {
+	var m Metadata
+	var err error
+	if m.Author, err = r.readString(); err != nil {
+		return Metadata{}, err
+	}
+	if known, err := r.readFlag(); err != nil {
+		return Metadata{}, err
+	} else if known {
+		data := r.next(12)
+		if data == nil {
+			return Metadata{}, r.err
+		}
+		nanoseconds := binary.BigEndian.Uint32(data[8:])
+		if nanoseconds >= uint32(time.Second) {
+			return Metadata{}, fmt.Errorf("invalid canonical encoding: a timestamp of %d nanoseconds", nanoseconds)
+		}
+		m.Timestamp = time.Unix(int64(binary.BigEndian.Uint64(data)), int64(nanoseconds)).UTC()
+		if m.Timestamp.IsZero() {
+			return Metadata{}, fmt.Errorf("invalid canonical encoding: a known zero timestamp")
+		}
+	}
+	if known, err := r.readFlag(); err != nil {
+		return Metadata{}, err
+	} else if known {
+		data := r.next(24)
+		if data == nil {
+			return Metadata{}, r.err
+		}
+		m.GPS = &GPS{
+			Latitude:  math.Float64frombits(binary.BigEndian.Uint64(data)),
+			Longitude: math.Float64frombits(binary.BigEndian.Uint64(data[8:])),
+			Altitude:  math.Float64frombits(binary.BigEndian.Uint64(data[16:])),
+		}
+	}
+	if m.DeviceID, err = r.readString(); err != nil {
+		return Metadata{}, err
+	}
+	if known, err := r.readFlag(); err != nil {
+		return Metadata{}, err
+	} else if known {
+		data := r.next(8)
+		if data == nil {
+			return Metadata{}, r.err
+		}
+		hash := binary.BigEndian.Uint64(data)
+		m.DHash = &hash
+	}
+	size := r.next(4)
+	if size == nil {
+		return Metadata{}, r.err
+	}
+	m.Width, m.Height = int(binary.BigEndian.Uint16(size)), int(binary.BigEndian.Uint16(size[2:]))
+	if err := m.Check(); err != nil {
+		return Metadata{}, err
+	}
+	if len(r.data) != 0 {
+		return Metadata{}, fmt.Errorf("invalid canonical encoding: %d bytes past the metadata", len(r.data))
+	}
+	return m, nil
+}
+
+// Read a flag byte, which must be 0 or 1, so that every metadata has a single encoding.
+func (r *canonicalReader) readFlag() (bool, error) {
+	flag := r.next(1)
+	if flag == nil {
+		return false, r.err
+	}
+	if flag[0] > 1 {
+		return false, fmt.Errorf("invalid canonical encoding: a flag of %d", flag[0])
+	}
+	return flag[0] == 1, nil
+}
+
+func (r *canonicalReader) readUint32() (uint32, error) {
+	data := r.next(4)
+	if data == nil {
+		return 0, r.err
+	}
+	return binary.BigEndian.Uint32(data), nil
+}
+
+func (r *canonicalReader) readString() (string, error) {
+	n, err := r.readUint32()
+	if err != nil {
+		return "", err
+	}
+	data := r.next(int(n))
+	if data == nil {
+		return "", r.err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,117 @@
+package image
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+// The canonical encoding of an image is the same on every run and through JSON, reads back as it was, and is
+// rejected when truncated, of another version or of another kind, or when its metadata is invalid.
+func TestCanonical(t *testing.T) {
+	img := AllWhiteImage()
+	img.SetPixel(3, 2, RGBPixel{R: 1, G: 2, B: 3})
+	img.M.Timestamp = time.Date(2024, 5, 17, 9, 30, 0, 250, time.FixedZone("CEST", 2*60*60))
+	img.M.GPS = &GPS{Latitude: 59.3293, Longitude: 18.0686, Altitude: 28.5}
+	img.M.DeviceID = "PhotoGnark Test Sensor"
+
+	encoded, err := img.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if again, _ := img.MarshalBinary(); !bytes.Equal(again, encoded) {
+			t.Fatalf("encoded the same image twice differently")
+		}
+	}
+
+	// Through JSON, the timestamp keeps its time zone and the same encoding
+	var fromJSON I
+	if err := json.Unmarshal(img.ToByte(), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := fromJSON.MarshalBinary(); !bytes.Equal(again, encoded) {
+		t.Errorf("the image read back from JSON has another encoding")
+	}
+
+	var decoded I
+	if err := decoded.UnmarshalBinary(encoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Pixels != img.Pixels || !decoded.M.Timestamp.Equal(img.M.Timestamp) || *decoded.M.GPS != *img.M.GPS ||
+		decoded.M.Author != img.M.Author || decoded.M.DeviceID != img.M.DeviceID || decoded.M.Width != Width || decoded.M.Height != Height {
+		t.Errorf("decoded the image with the metadata %+v, or changed its pixels", decoded.M)
+	}
+	if string(decoded.Digest()) != string(img.Digest()) {
+		t.Errorf("the decoded image has another digest")
+	}
+
+	gray, _ := img.ToGray().MarshalBinary()
+	outdated := append([]byte{}, encoded...)
+	outdated[len(canonicalMagic)] = CanonicalVersion + 1
+	oversized := append([]byte{}, encoded...)
+	oversized[len(oversized)-1] = Height + 1
+	for name, data := range map[string][]byte{
+		"truncated":          encoded[:len(encoded)-1],
+		"longer":             append(append([]byte{}, encoded...), 0),
+		"of another version": outdated,
+		"grayscale":          gray,
+		"JSON":               img.ToByte(),
+		"oversized":          oversized,
+	} {
+		if err := decoded.UnmarshalBinary(data); err == nil {
+			t.Errorf("decoded a %s encoding", name)
+		}
+	}
+
+	for name, change := range map[string]func(*Metadata){
+		"size":         func(m *Metadata) { m.Width = Width + 1 },
+		"negative":     func(m *Metadata) { m.Height = -1 },
+		"GPS":          func(m *Metadata) { m.GPS = &GPS{Latitude: 91} },
+		"GPS altitude": func(m *Metadata) { m.GPS = &GPS{Altitude: math.Inf(1)} },
+	} {
+		invalid := img
+		invalid.M = img.M.Copy()
+		change(&invalid.M)
+		if _, err := invalid.MarshalBinary(); err == nil {
+			t.Errorf("encoded metadata with an invalid %s", name)
+		}
+	}
+}
+
+// Every kind of image reads back from its canonical encoding.
+func TestCanonicalKinds(t *testing.T) {
+	img := AllWhiteImage()
+	img.SetPixel(1, 1, RGBPixel{R: 200})
+
+	gray := img.ToGray()
+	data, err := gray.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodedGray Gray
+	if err := decodedGray.UnmarshalBinary(data); err != nil || decodedGray.Pixels != gray.Pixels {
+		t.Errorf("decoded the grayscale image with %v, or changed its pixels", err)
+	}
+
+	deep := img.ToDeep()
+	if data, err = deep.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	var decodedDeep Deep
+	if err := decodedDeep.UnmarshalBinary(data); err != nil || decodedDeep.Pixels != deep.Pixels {
+		t.Errorf("decoded the deep color image with %v, or changed its pixels", err)
+	}
+
+	raw := RAW{M: Metadata{Width: Width, Height: Height}}
+	raw.Samples[2][3] = 1000
+	if data, err = raw.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	var decodedRAW RAW
+	if err := decodedRAW.UnmarshalBinary(data); err != nil || decodedRAW.Samples != raw.Samples || decodedRAW.M != raw.M {
+		t.Errorf("decoded the RAW capture with %v, or changed its samples", err)
+	}
+}
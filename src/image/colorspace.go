@@ -0,0 +1,183 @@
+package image
+
+import "math"
+
+/*
+Color spaces of the photometric transformations. Channels of an image I are sRGB encoded, as cameras and
+screens expect, but light adds up in linear RGB: brightness and exposure are defined on linear channels, and
+contrast and color on YCbCr, so that a predicate's arithmetic means the same on every image. The conversions
+are in integer arithmetic, by lookup table or with weights out of 256, which a circuit computes exactly like
+the Go code, so that the Go code is the reference the circuits are checked against.
+
+Linear channels have DeepBits, as sRGB's dark tones need more than 8 bits to read back, and are held in a
+Deep image. YCbCr is the full range YCbCr of JPEG (ITU-R BT.601): Y is Luma, Cb and Cr are centered on 128.
+*/
+
+// The linear value of every sRGB channel value, out of 1<<DeepBits - 1.
+var srgbToLinear [256]uint16
+
+func init() {
+	for v := range srgbToLinear {
+		c := float64(v) / 255
+		if c <= 0.04045 {
+			c /= 12.92
+		} else {
+			c = math.Pow((c+0.055)/1.055, 2.4)
+		}
+		srgbToLinear[v] = uint16(math.Round(c * math.MaxUint16))
+	}
+}
+
+// SRGBToLinear returns the linear value of an sRGB channel value, out of 1<<DeepBits - 1.
+func SRGBToLinear(v uint8) uint16 {
+	return srgbToLinear[v]
+}
+
+// LinearToSRGB returns the sRGB channel value closest to a linear value, out of 1<<DeepBits - 1: the v whose
+// SRGBToLinear is closest, the lower one on a tie. It is the inverse of SRGBToLinear.
+func LinearToSRGB(linear uint16) uint8 {
+	// The first value whose linear value is above linear, then the closer of it and the one before it
+	low, high := 0, 256
+	for low < high {
+		mid := (low + high) / 2
+		if srgbToLinear[mid] > linear {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	if low == 256 || (low > 0 && linear-srgbToLinear[low-1] <= srgbToLinear[low]-linear) {
+		return uint8(low - 1)
+	}
+	return uint8(low)
+}
+
+// ToLinear returns the linear RGB image of the image, with a copy of its metadata.
+func (img I) ToLinear() Deep {
+	linear := Deep{M: img.M.Copy()}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := img.Pixels[y][x]
+			linear.Pixels[y][x] = DeepPixel{R: SRGBToLinear(p.R), G: SRGBToLinear(p.G), B: SRGBToLinear(p.B)}
+		}
+	}
+	return linear
+}
+
+// FromLinear returns the sRGB image of a linear RGB image, as returned by ToLinear, with a copy of its metadata.
+func FromLinear(linear Deep) I {
+	img := I{M: linear.M.Copy()}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := linear.Pixels[y][x]
+			img.Pixels[y][x] = RGBPixel{R: LinearToSRGB(p.R), G: LinearToSRGB(p.G), B: LinearToSRGB(p.B)}
+		}
+	}
+	return img
+}
+
+type YCbCrPixel struct {
+	Y  uint8
+	Cb uint8
+	Cr uint8
+}
+
+// A YCbCr is an image in YCbCr: the luma of every pixel, and its blue and red difference.
+type YCbCr struct {
+	Pixels [Height][Width]YCbCrPixel // Fixed-sized 2D array, indexed [y][x] like the pixels of an image.
+
+	M Metadata // Image metadata.
+}
+
+// RGBToYCbCr returns the YCbCr of an RGB pixel: its Luma, and its blue and red differences with the weights
+// of ITU-R BT.601 out of 256, rounded and centered on 128. Grays have a Cb and Cr of 128.
+func RGBToYCbCr(p RGBPixel) YCbCrPixel {
+	r, g, b := int(p.R), int(p.G), int(p.B)
+	return YCbCrPixel{
+		Y:  Luma(p),
+		Cb: clampChannel(128 + (-43*r-85*g+128*b+128)>>8),
+		Cr: clampChannel(128 + (128*r-107*g-21*b+128)>>8),
+	}
+}
+
+// YCbCrToRGB returns the RGB pixel of a YCbCr pixel, with the weights of ITU-R BT.601 out of 256, rounded and
+// clamped to 0-255. It is the inverse of RGBToYCbCr within a few values of every channel, and exactly for grays.
+func YCbCrToRGB(p YCbCrPixel) RGBPixel {
+	y, cb, cr := int(p.Y), int(p.Cb)-128, int(p.Cr)-128
+	return RGBPixel{
+		R: clampChannel(y + (359*cr+128)>>8),
+		G: clampChannel(y + (-88*cb-183*cr+128)>>8),
+		B: clampChannel(y + (454*cb+128)>>8),
+	}
+}
+
+func clampChannel(v int) uint8 {
+	return uint8(min(max(v, 0), 255))
+}
+
+// ToYCbCr returns the YCbCr image of the image, with a copy of its metadata.
+func (img I) ToYCbCr() YCbCr {
+	ycbcr := YCbCr{M: img.M.Copy()}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			ycbcr.Pixels[y][x] = RGBToYCbCr(img.Pixels[y][x])
+		}
+	}
+	return ycbcr
+}
+
+// ToImage returns the RGB image of the YCbCr image, with a copy of its metadata.
+func (ycbcr YCbCr) ToImage() I {
+	img := I{M: ycbcr.M.Copy()}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			img.Pixels[y][x] = YCbCrToRGB(ycbcr.Pixels[y][x])
+		}
+	}
+	return img
+}
+
+// Weights of a ColorMatrix are out of 1 << ColorMatrixBits, finer than those of RGBToYCbCr, so that the
+// product of two weights out of 256, e.g. of a luma weight and a saturation, is a weight too.
+const ColorMatrixBits = 16
+
+// A ColorMatrix maps the R, G and B of a pixel to new ones: row c weighs them into channel c, with weights out
+// of 1 << ColorMatrixBits, so that 65536 on the diagonal and 0 elsewhere keeps every pixel.
+type ColorMatrix [3][3]int
+
+// SepiaMatrix is the ColorMatrix of a sepia tone, the weights of the usual one rounded out of 65536.
+var SepiaMatrix = ColorMatrix{
+	{25756, 50397, 12386},
+	{22872, 44958, 11010},
+	{17826, 34996, 8585},
+}
+
+// Apply returns the pixel mapped by the matrix, rounded and clamped to 0-255. Black maps to black.
+func (matrix *ColorMatrix) Apply(p RGBPixel) RGBPixel {
+	in := [3]int{int(p.R), int(p.G), int(p.B)}
+	var out [3]uint8
+	for c, weights := range matrix {
+		sum := 1 << (ColorMatrixBits - 1)
+		for k, weight := range weights {
+			sum += weight * in[k]
+		}
+		out[c] = clampChannel(sum >> ColorMatrixBits)
+	}
+	return RGBPixel{R: out[0], G: out[1], B: out[2]}
+}
+
+// ApplyColorMatrix returns the image with every pixel mapped by the matrix, as a new image with a copy of the
+// metadata, and its perceptual hash updated if it has one. The pixels outside the size of the image stay black.
+func (img I) ApplyColorMatrix(matrix *ColorMatrix) I {
+	mapped := img.Clone()
+	mapped.Map(func(_, _ int, p RGBPixel) RGBPixel { return matrix.Apply(p) })
+	if mapped.M.DHash != nil {
+		mapped.SetDHash()
+	}
+	return mapped
+}
+
+// Sepia returns the image in a sepia tone, with every pixel mapped by the SepiaMatrix.
+func (img I) Sepia() I {
+	return img.ApplyColorMatrix(&SepiaMatrix)
+}
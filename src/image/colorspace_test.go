@@ -0,0 +1,89 @@
+package image
+
+import "testing"
+
+func TestLinear(t *testing.T) {
+	if SRGBToLinear(0) != 0 || SRGBToLinear(255) != 65535 || SRGBToLinear(128) != 14146 {
+		t.Errorf("sRGB 0, 128 and 255 are linear %d, %d and %d", SRGBToLinear(0), SRGBToLinear(128), SRGBToLinear(255))
+	}
+	for v := 0; v < 256; v++ {
+		if back := LinearToSRGB(SRGBToLinear(uint8(v))); back != uint8(v) {
+			t.Errorf("sRGB %d reads back as %d", v, back)
+		}
+		if v > 0 && SRGBToLinear(uint8(v)) <= SRGBToLinear(uint8(v-1)) {
+			t.Errorf("sRGB %d is not brighter than %d", v, v-1)
+		}
+	}
+	if LinearToSRGB(65535) != 255 || LinearToSRGB(1) != 0 {
+		t.Errorf("linear 1 and 65535 are sRGB %d and %d", LinearToSRGB(1), LinearToSRGB(65535))
+	}
+
+	img := NoiseImage(1)
+	if back := FromLinear(img.ToLinear()); back.Pixels != img.Pixels || back.M.Author != img.M.Author {
+		t.Errorf("the image does not read back from linear RGB")
+	}
+}
+
+func TestYCbCr(t *testing.T) {
+	for _, c := range []struct {
+		rgb   RGBPixel
+		ycbcr YCbCrPixel
+	}{
+		{RGBPixel{}, YCbCrPixel{Y: 0, Cb: 128, Cr: 128}},
+		{RGBPixel{R: 255, G: 255, B: 255}, YCbCrPixel{Y: 255, Cb: 128, Cr: 128}},
+		{RGBPixel{R: 255}, YCbCrPixel{Y: 76, Cb: 85, Cr: 255}},
+		{RGBPixel{B: 255}, YCbCrPixel{Y: 28, Cb: 255, Cr: 107}},
+	} {
+		if got := RGBToYCbCr(c.rgb); got != c.ycbcr {
+			t.Errorf("%v is %v in YCbCr, expected %v", c.rgb, got, c.ycbcr)
+		}
+	}
+
+	// Grays read back exactly, other colors within a few values
+	for v := 0; v < 256; v++ {
+		gray := RGBPixel{R: uint8(v), G: uint8(v), B: uint8(v)}
+		if back := YCbCrToRGB(RGBToYCbCr(gray)); back != gray {
+			t.Errorf("gray %v reads back as %v", gray, back)
+		}
+	}
+	img := NoiseImage(2)
+	back := img.ToYCbCr().ToImage()
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p, q := img.Pixels[y][x], back.Pixels[y][x]
+			for _, d := range []int{int(p.R) - int(q.R), int(p.G) - int(q.G), int(p.B) - int(q.B)} {
+				if d < -3 || d > 3 {
+					t.Fatalf("pixel (%d, %d) %v reads back as %v", x, y, p, q)
+				}
+			}
+		}
+	}
+}
+
+func TestSepia(t *testing.T) {
+	for _, c := range []struct{ in, out RGBPixel }{
+		{RGBPixel{}, RGBPixel{}},
+		{RGBPixel{R: 128, G: 128, B: 128}, RGBPixel{R: 173, G: 154, B: 120}},
+		{RGBPixel{R: 200, G: 100, B: 50}, RGBPixel{R: 165, G: 147, B: 114}},
+		{RGBPixel{R: 255, G: 255, B: 255}, RGBPixel{R: 255, G: 255, B: 239}}, // R and G clamped
+	} {
+		if got := SepiaMatrix.Apply(c.in); got != c.out {
+			t.Errorf("%v is %v in sepia, expected %v", c.in, got, c.out)
+		}
+	}
+	identity := ColorMatrix{{1 << ColorMatrixBits, 0, 0}, {0, 1 << ColorMatrixBits, 0}, {0, 0, 1 << ColorMatrixBits}}
+	img := NoiseImage(3)
+	if kept := img.ApplyColorMatrix(&identity); kept.Pixels != img.Pixels {
+		t.Errorf("the identity matrix changed the image")
+	}
+
+	img, err := GradientImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+	toned := img.Sepia()
+	if toned.Pixels[2][5] != SepiaMatrix.Apply(img.Pixels[2][5]) || toned.Pixels[0][10] != (RGBPixel{}) || *toned.M.DHash != toned.DHash() {
+		t.Errorf("the image is not in sepia, or its perceptual hash is stale")
+	}
+}
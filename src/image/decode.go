@@ -0,0 +1,40 @@
+package image
+
+import (
+	"bytes"
+	"fmt"
+	stdimage "image"
+	_ "image/png" // registers PNG with stdimage.Decode, like the formats below
+	"io"
+
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// Decode decodes an image file of any supported format, which it detects from the file's content rather than
+// its name, and returns the image and the name of the format: "jpeg", "png", "tiff", "webp", or "dng" for a
+// DNG file, read by DecodeDNG. Archives of mixed formats are read without knowing the format of every file.
+//
+// Like DecodeJPEG, a picture smaller than Width x Height is placed in the top left corner, and a larger one is
+// rejected rather than cut.
+func Decode(r io.Reader) (I, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return I{}, "", err
+	}
+
+	// A DNG file is a TIFF file, whose main image the TIFF decoder does not read
+	if _, ifd0, err := readTIFF(data); err == nil {
+		if _, ok := ifd0[tagDNGVersion]; ok {
+			img, err := DecodeDNG(bytes.NewReader(data))
+			return img, "dng", err
+		}
+	}
+
+	decoded, format, err := stdimage.Decode(bytes.NewReader(data))
+	if err != nil {
+		return I{}, "", fmt.Errorf("unsupported image file: %w", err)
+	}
+	img, err := FromGoImage(decoded)
+	return img, format, err
+}
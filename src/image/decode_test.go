@@ -0,0 +1,72 @@
+package image
+
+import (
+	"bytes"
+	stdimage "image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/tiff"
+)
+
+// The smallest lossless WebP file: a single transparent black pixel.
+var testWebP = []byte("RIFF\x1a\x00\x00\x00WEBPVP8L\x0d\x00\x00\x00\x2f\x00\x00\x00\x10\x07\x10\x11\x11\x88\x88\xfe\x07\x00")
+
+// Files of every supported format are detected from their content, and decoded with their size.
+func TestDecode(t *testing.T) {
+	src := stdimage.NewRGBA(stdimage.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: uint8(80 * x), G: uint8(100 * y), B: 7, A: 255})
+		}
+	}
+	lossless, err := FromGoImage(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var encodedPNG, encodedTIFF, encodedJPEG bytes.Buffer
+	if err := png.Encode(&encodedPNG, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := tiff.Encode(&encodedTIFF, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := lossless.EncodeJPEG(&encodedJPEG, 100); err != nil {
+		t.Fatal(err)
+	}
+	dng := testDNG(2, 2, []uint16{1000, 1000, 1000, 9000, 9000, 9000, 3000, 3000, 3000, 0, 9000, 3000}, photometricLinearRaw)
+
+	for _, c := range []struct {
+		format        string
+		data          []byte
+		width, height int
+		exact         bool
+	}{
+		{"png", encodedPNG.Bytes(), 3, 2, true},
+		{"tiff", encodedTIFF.Bytes(), 3, 2, true},
+		{"jpeg", encodedJPEG.Bytes(), 3, 2, false},
+		{"webp", testWebP, 1, 1, false},
+		{"dng", dng, 2, 2, false},
+	} {
+		img, format, err := Decode(bytes.NewReader(c.data))
+		if err != nil {
+			t.Errorf("%s: %v", c.format, err)
+			continue
+		}
+		if format != c.format {
+			t.Errorf("detected a %s file as %s", c.format, format)
+		}
+		if img.M.Width != c.width || img.M.Height != c.height {
+			t.Errorf("decoded a %d x %d %s file as %v x %v", c.width, c.height, c.format, img.M.Width, img.M.Height)
+		}
+		if c.exact && img.Pixels != lossless.Pixels {
+			t.Errorf("decoding the lossless %s file changed its pixels", c.format)
+		}
+	}
+
+	if _, _, err := Decode(bytes.NewReader(AllWhiteImage().ToByte())); err == nil {
+		t.Errorf("decoded a JSON encoded image as an image file")
+	}
+}
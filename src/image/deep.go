@@ -0,0 +1,148 @@
+package image
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Bit depth of the channels of a Deep image.
+const DeepBits = 16
+
+// A Deep is a deep color image: RGB pixels of DeepBits per channel instead of 8, e.g. developed from a RAW
+// capture or read from medical imagery. An image I keeps 8 bit channels, which the lookup tables of its
+// compliance predicates are sized for.
+type Deep struct {
+	Pixels [Height][Width]DeepPixel // Fixed-sized 2D array, indexed [y][x] like the pixels of an image.
+
+	M Metadata // Image metadata.
+}
+
+type DeepPixel struct {
+	R uint16
+	G uint16
+	B uint16
+}
+
+// ToDeep returns the deep color image of the image, every channel value v scaled to v * 257, so that white
+// stays white, with a copy of its metadata.
+func (img I) ToDeep() Deep {
+	deep := Deep{M: img.M.Copy()}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := img.Pixels[y][x]
+			deep.Pixels[y][x] = DeepPixel{R: uint16(p.R) * 257, G: uint16(p.G) * 257, B: uint16(p.B) * 257}
+		}
+	}
+	return deep
+}
+
+// ToImage returns the image of the deep color image, every channel value kept to its 8 most significant bits,
+// with a copy of its metadata. It is the inverse of I.ToDeep.
+func (deep Deep) ToImage() I {
+	img := I{M: deep.M.Copy()}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := deep.Pixels[y][x]
+			img.Pixels[y][x] = RGBPixel{R: uint8(p.R >> 8), G: uint8(p.G >> 8), B: uint8(p.B >> 8)}
+		}
+	}
+	return img
+}
+
+// Clone returns a copy of the deep color image that shares nothing with it, like I.Clone.
+func (deep Deep) Clone() Deep {
+	clone := deep
+	clone.M = deep.M.Copy()
+	return clone
+}
+
+// Given a secret key, a nonce and the hash of the previous proof, sign this deep color image, like I.Sign
+// signs an image.
+func (deep *Deep) Sign(secretKey signature.Signer, nonce *big.Int, prevProofHash *big.Int) []byte {
+	return SignDigest(secretKey, deep.Digest(), nonce, prevProofHash)
+}
+
+// Digest returns the message that is signed for this deep color image: the hash of its channels, packed 15 to a
+// field element, and its MetadataDigest, like I.Digest.
+func (deep Deep) Digest() []byte {
+	channels := make([]uint16, 0, 3*Width*Height)
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := deep.Pixels[y][x]
+			channels = append(channels, p.R, p.G, p.B)
+		}
+	}
+	return channelsDigest(channels, DeepBits, deep.MetadataDigest())
+}
+
+// Crop crops the deep color image to the specified rectangle and moves the cropped area to the top-left
+// corner, like I.Crop.
+func (deep *Deep) Crop(x0, y0, x1, y1 int) error {
+	width, height := deep.M.Width, deep.M.Height
+	if width == 0 || height == 0 {
+		return fmt.Errorf("invalid image metadata for width and height")
+	}
+	if x0 < 0 || y0 < 0 || x1 >= width || y1 >= height || x0 > x1 || y0 > y1 {
+		return fmt.Errorf("invalid crop dimensions: out of bounds")
+	}
+
+	var cropped [Height][Width]DeepPixel
+	for y := 0; y <= y1-y0; y++ {
+		for x := 0; x <= x1-x0; x++ {
+			cropped[y][x] = deep.Pixels[y0+y][x0+x]
+		}
+	}
+	deep.Pixels = cropped
+
+	deep.M.Width = x1 - x0 + 1
+	deep.M.Height = y1 - y0 + 1
+
+	return nil
+}
+
+// Decode a JSON encoded deep color image, like I.UnmarshalJSON decodes an image.
+func (deep *Deep) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	// Same fields as Deep, with rows of any length
+	var decoded struct {
+		Pixels [][]DeepPixel
+		M      Metadata
+	}
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+
+	var pixels [Height][Width]DeepPixel
+	if len(decoded.Pixels) != Height {
+		return fmt.Errorf("invalid image: %d rows of pixels, expected %d", len(decoded.Pixels), Height)
+	}
+	for y, row := range decoded.Pixels {
+		if len(row) != Width {
+			return fmt.Errorf("invalid image: %d pixels in row %d, expected %d", len(row), y, Width)
+		}
+		copy(pixels[y][:], row)
+	}
+
+	*deep = Deep{Pixels: pixels, M: decoded.M}
+	return nil
+}
+
+// ToFrontendImage returns the frontend pixels of the deep color image. Frontend pixels hold channels of any
+// bit depth; the compliance predicates of deep color images range check them to DeepBits.
+func (deep Deep) ToFrontendImage() FrontendImage {
+	frontendImage := FrontendImage{}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			frontendImage.Pixels[y][x].R = frontend.Variable(deep.Pixels[y][x].R)
+			frontendImage.Pixels[y][x].G = frontend.Variable(deep.Pixels[y][x].G)
+			frontendImage.Pixels[y][x].B = frontend.Variable(deep.Pixels[y][x].B)
+		}
+	}
+	return frontendImage
+}
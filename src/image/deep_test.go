@@ -0,0 +1,39 @@
+package image
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// An image converted to deep color and back is unchanged, and a deep color image reads back from its JSON
+// encoding as it was, with its 16 bit channels.
+func TestDeep(t *testing.T) {
+	img := AllWhiteImage()
+	img.SetPixel(1, 1, RGBPixel{R: 1, G: 128, B: 0})
+	deep := img.ToDeep()
+	if deep.Pixels[0][0] != (DeepPixel{R: 65535, G: 65535, B: 65535}) {
+		t.Errorf("converted white to %v", deep.Pixels[0][0])
+	}
+	if back := deep.ToImage(); back.Pixels != img.Pixels {
+		t.Errorf("converting to deep color and back changed the pixels")
+	}
+
+	deep.Pixels[2][3] = DeepPixel{R: 40000, G: 257, B: 1}
+	if err := deep.Crop(1, 1, 9, 5); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(deep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Deep
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Pixels != deep.Pixels || decoded.M.Width != 9 || decoded.M.Height != 5 {
+		t.Errorf("decoded a %v x %v image, or changed its pixels", decoded.M.Width, decoded.M.Height)
+	}
+	if decoded.Pixels[1][2] != (DeepPixel{R: 40000, G: 257, B: 1}) {
+		t.Errorf("decoded %v, expected 16 bit channels", decoded.Pixels[1][2])
+	}
+}
@@ -0,0 +1,98 @@
+package image
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	"src/hashsuite"
+	"src/internal/field"
+)
+
+/*
+The digest of an image is the message the camera signs: the hashsuite.Default hash of its channels, packed
+field.PackedBits/bits to a field element like field.Pack does, followed by its MetadataDigest, the hash of the
+rest of its canonical encoding (see MarshalBinary). Every pixel is hashed, rather than the encoded image being
+reduced into a single field element, and the circuits compute the same hash from the pixels they are given,
+so that a signature binds the pixels a proof is about. The metadata is not needed inside a circuit: its digest
+is a secret input, bound to the signature through the image's digest.
+*/
+
+// MetadataDigest returns the hash of the canonical encoding of the image without its pixels: the version, kind
+// and size of the encoding, and the metadata. Circuits take it as a secret input to compute the Digest.
+func (img I) MetadataDigest() []byte {
+	return metadataDigest(kindImage, img.M)
+}
+
+// Size of the header of a MetadataEncoding, which the Author follows: its length as uint32, then its bytes.
+const MetadataHeaderSize = 9
+
+// MetadataEncoding returns the canonical encoding of the image without its pixels, which MetadataDigest hashes
+// as field.Chunks, or an error if its metadata cannot be encoded. The compliance predicate of an edit of the
+// metadata takes it as a witness, see Credit.
+func (img I) MetadataEncoding() ([]byte, error) {
+	return appendMetadata(canonicalHeader(kindImage), img.M)
+}
+
+// MetadataDigest returns the hash of the canonical encoding of the grayscale image without its pixels, like
+// I.MetadataDigest.
+func (gray Gray) MetadataDigest() []byte {
+	return metadataDigest(kindGray, gray.M)
+}
+
+// MetadataDigest returns the hash of the canonical encoding of the deep color image without its pixels, like
+// I.MetadataDigest.
+func (deep Deep) MetadataDigest() []byte {
+	return metadataDigest(kindDeep, deep.M)
+}
+
+// MetadataDigest returns the hash of the canonical encoding of the capture without its samples, like
+// I.MetadataDigest.
+func (raw RAW) MetadataDigest() []byte {
+	return metadataDigest(kindRAW, raw.M)
+}
+
+// The channels of the image, pixel by pixel in the order R, G, B, row by row.
+func (img I) channels() []uint8 {
+	channels := make([]uint8, 0, 3*Width*Height)
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			p := img.Pixels[y][x]
+			channels = append(channels, p.R, p.G, p.B)
+		}
+	}
+	return channels
+}
+
+// The hash of the canonical encoding of an image of kind without its pixels, or an empty digest if m cannot
+// be encoded.
+func metadataDigest(kind byte, m Metadata) []byte {
+	encoded, err := appendMetadata(canonicalHeader(kind), m)
+	if err != nil {
+		fmt.Println("Error while encoding metadata: " + err.Error())
+		return []byte{}
+	}
+	return hashElements(field.Chunks(encoded)...)
+}
+
+// The digest of an image of channels of bits each and metadataDigest, or an empty digest if the metadata
+// could not be encoded.
+func channelsDigest[T uint8 | uint16](channels []T, bits int, metadataDigest []byte) []byte {
+	if len(metadataDigest) == 0 {
+		return []byte{}
+	}
+	return hashElements(append(field.Pack(channels, bits), new(big.Int).SetBytes(metadataDigest))...)
+}
+
+// The hashsuite.Default hash of elements, as a big endian field element.
+func hashElements(elements ...*big.Int) []byte {
+	h := hashsuite.Default.New()
+	for _, value := range elements {
+		var element fr.Element
+		element.SetBigInt(value)
+		bytes := element.Bytes()
+		h.Write(bytes[:])
+	}
+	return h.Sum(nil)
+}
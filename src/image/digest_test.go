@@ -0,0 +1,43 @@
+package image
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// Every pixel and the metadata change the digest, which is a single field element however large the image.
+func TestDigest(t *testing.T) {
+	img := AllWhiteImage()
+	digest := img.Digest()
+	if len(digest) != 32 {
+		t.Fatalf("a digest of %d bytes", len(digest))
+	}
+
+	first, last := img, img
+	first.Pixels[0][0].R = 254
+	last.Pixels[Height-1][Width-1].B = 254
+	metadata, timestamp, gps, device := AllWhiteImage(), img, img, img
+	metadata.M.Author = "Jane Doe"
+	timestamp.M.Timestamp = time.Unix(1, 0)
+	gps.M.GPS = &GPS{}
+	device.M.DeviceID = "PhotoGnark"
+	for name, other := range map[string]I{"first pixel": first, "last pixel": last, "metadata": metadata, "timestamp": timestamp, "GPS position": gps, "device ID": device} {
+		if bytes.Equal(other.Digest(), digest) {
+			t.Errorf("changing the %s kept the digest", name)
+		}
+	}
+	if !bytes.Equal(metadata.MetadataDigest(), metadata.MetadataDigest()) || bytes.Equal(metadata.MetadataDigest(), img.MetadataDigest()) {
+		t.Errorf("the metadata digest does not follow the metadata")
+	}
+
+	// The same pixels and metadata, of another kind of image
+	if bytes.Equal(img.ToGray().MetadataDigest(), img.MetadataDigest()) {
+		t.Errorf("a grayscale image has the metadata digest of an RGB image")
+	}
+
+	img.M.Width = Width + 1
+	if len(img.Digest()) != 0 {
+		t.Errorf("digested metadata that cannot be encoded")
+	}
+}
@@ -0,0 +1,295 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// TIFF tags read by DecodeDNG. A DNG file is a TIFF file with the DNG tags.
+const (
+	tagNewSubfileType            = 254
+	tagImageWidth                = 256
+	tagImageLength               = 257
+	tagBitsPerSample             = 258
+	tagCompression               = 259
+	tagPhotometricInterpretation = 262
+	tagMake                      = 271
+	tagModel                     = 272
+	tagStripOffsets              = 273
+	tagSamplesPerPixel           = 277
+	tagStripByteCounts           = 279
+	tagPlanarConfiguration       = 284
+	tagDateTime                  = 306
+	tagSubIFDs                   = 330
+	tagDNGVersion                = 50706
+	tagUniqueCameraModel         = 50708
+	tagBlackLevel                = 50714
+	tagWhiteLevel                = 50717
+
+	photometricLinearRaw = 34892
+)
+
+// Layout of the DateTime tag of a TIFF file, in the camera's local time, which DNG files do not record.
+const tiffDateTime = "2006:01:02 15:04:05"
+
+// DecodeDNG decodes the sensor output of a camera, a DNG file holding demosaiced linear RGB (LinearRaw,
+// uncompressed, 8 or 16 bits per sample), into an image. Every sample is scaled between the black and white
+// levels of the capture and gamma encoded with a gamma of 2, like transformations.Develop does; there is no
+// white balance or color matrix. The capture's UniqueCameraModel, or else its Make and Model, is the device ID
+// of the metadata and its DateTime, read as UTC, the timestamp, so that the camera signs them with the pixels.
+//
+// Like DecodeJPEG, a capture smaller than Width x Height is placed in the top left corner, and a larger one is
+// rejected rather than cut. Mosaiced (CFA) and compressed DNG files are rejected: develop a mosaiced capture
+// with transformations.Develop instead.
+func DecodeDNG(r io.Reader) (I, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return I{}, err
+	}
+
+	tiff, ifd0, err := readTIFF(data)
+	if err != nil {
+		return I{}, err
+	}
+	if _, ok := ifd0[tagDNGVersion]; !ok {
+		return I{}, fmt.Errorf("invalid DNG file: no DNGVersion tag")
+	}
+
+	// The main image is the full resolution one, IFD 0 or one of its sub IFDs; IFD 0 often is a preview
+	main, err := tiff.mainIFD(ifd0)
+	if err != nil {
+		return I{}, err
+	}
+
+	width, height := main.uint(tiff, tagImageWidth, 0), main.uint(tiff, tagImageLength, 0)
+	if width < 1 || height < 1 || width > Width || height > Height {
+		return I{}, fmt.Errorf("invalid DNG image size %d x %d: expected at most %d x %d", width, height, Width, Height)
+	}
+	if photometric := main.uint(tiff, tagPhotometricInterpretation, 0); photometric != photometricLinearRaw {
+		return I{}, fmt.Errorf("unsupported DNG photometric interpretation %d: only LinearRaw (%d) is supported", photometric, photometricLinearRaw)
+	}
+	if compression := main.uint(tiff, tagCompression, 1); compression != 1 {
+		return I{}, fmt.Errorf("unsupported DNG compression %d: only uncompressed images are supported", compression)
+	}
+	if samples := main.uint(tiff, tagSamplesPerPixel, 1); samples != 3 {
+		return I{}, fmt.Errorf("unsupported DNG samples per pixel %d: expected RGB", samples)
+	}
+	if planar := main.uint(tiff, tagPlanarConfiguration, 1); planar != 1 {
+		return I{}, fmt.Errorf("unsupported DNG planar configuration %d: expected interleaved samples", planar)
+	}
+	bits := main.uint(tiff, tagBitsPerSample, 1)
+	if bits != 8 && bits != 16 {
+		return I{}, fmt.Errorf("unsupported DNG bits per sample %d: expected 8 or 16", bits)
+	}
+	black := float64(main.uint(tiff, tagBlackLevel, 0))
+	white := float64(main.uint(tiff, tagWhiteLevel, 1<<bits-1))
+	if white <= black {
+		return I{}, fmt.Errorf("invalid DNG levels: white level %v is not above black level %v", white, black)
+	}
+
+	// Samples, row by row, strip after strip
+	offsets, err := main.uints(tiff, tagStripOffsets)
+	if err != nil {
+		return I{}, err
+	}
+	counts, err := main.uints(tiff, tagStripByteCounts)
+	if err != nil {
+		return I{}, err
+	}
+	if len(offsets) != len(counts) {
+		return I{}, fmt.Errorf("invalid DNG file: %d strip offsets for %d strip byte counts", len(offsets), len(counts))
+	}
+	var samples []byte
+	for i := range offsets {
+		if uint64(offsets[i])+uint64(counts[i]) > uint64(len(data)) {
+			return I{}, fmt.Errorf("invalid DNG file: strip %d is past the end of the file", i)
+		}
+		samples = append(samples, data[offsets[i]:offsets[i]+counts[i]]...)
+	}
+	sampleBytes := bits / 8
+	if len(samples) < width*height*3*sampleBytes {
+		return I{}, fmt.Errorf("invalid DNG file: %d bytes of samples for a %d x %d image", len(samples), width, height)
+	}
+
+	channel := func(i int) uint8 {
+		v := float64(samples[i*sampleBytes])
+		if sampleBytes == 2 {
+			v = float64(tiff.order.Uint16(samples[2*i:]))
+		}
+		linear := min(max((v-black)/(white-black), 0), 1)
+		return uint8(math.Round(255 * math.Sqrt(linear)))
+	}
+	img := NewImage()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := 3 * (y*width + x)
+			img.Pixels[y][x] = RGBPixel{R: channel(i), G: channel(i + 1), B: channel(i + 2)}
+		}
+	}
+
+	ascii := func(tag uint16) string {
+		if value, ok := main.string(tag); ok {
+			return value
+		}
+		value, _ := ifd0.string(tag)
+		return value
+	}
+	img.M.DeviceID = ascii(tagUniqueCameraModel)
+	if img.M.DeviceID == "" {
+		img.M.DeviceID = strings.TrimSpace(ascii(tagMake) + " " + ascii(tagModel))
+	}
+	if dateTime := ascii(tagDateTime); dateTime != "" {
+		if img.M.Timestamp, err = time.Parse(tiffDateTime, dateTime); err != nil {
+			return I{}, fmt.Errorf("invalid DNG file: DateTime %q", dateTime)
+		}
+	}
+	img.M.Width = width
+	img.M.Height = height
+
+	return img, nil
+}
+
+// A TIFF file, as read by readTIFF.
+type tiffFile struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// An image file directory of a TIFF file: its entries, by tag.
+type tiffIFD map[uint16]tiffEntry
+
+// An entry of an image file directory: its type, count and the bytes of its values.
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// Size in bytes of a value of every TIFF type.
+var tiffTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8, 13: 4}
+
+// Read the header and the first image file directory of a TIFF file.
+func readTIFF(data []byte) (tiffFile, tiffIFD, error) {
+	if len(data) < 8 {
+		return tiffFile{}, nil, fmt.Errorf("invalid DNG file: too short")
+	}
+	var tiff tiffFile
+	switch {
+	case bytes.HasPrefix(data, []byte("II*\x00")):
+		tiff = tiffFile{data: data, order: binary.LittleEndian}
+	case bytes.HasPrefix(data, []byte("MM\x00*")):
+		tiff = tiffFile{data: data, order: binary.BigEndian}
+	default:
+		return tiffFile{}, nil, fmt.Errorf("invalid DNG file: no TIFF header")
+	}
+	ifd0, err := tiff.ifd(tiff.order.Uint32(data[4:]))
+	return tiff, ifd0, err
+}
+
+// Read the image file directory at offset.
+func (tiff tiffFile) ifd(offset uint32) (tiffIFD, error) {
+	data := tiff.data
+	if uint64(offset)+2 > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid DNG file: image file directory past the end of the file")
+	}
+	n := int(tiff.order.Uint16(data[offset:]))
+	if uint64(offset)+2+12*uint64(n) > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid DNG file: image file directory past the end of the file")
+	}
+
+	ifd := make(tiffIFD, n)
+	for i := 0; i < n; i++ {
+		entry := data[int(offset)+2+12*i:]
+		typ, count := tiff.order.Uint16(entry[2:]), tiff.order.Uint32(entry[4:])
+		size, ok := tiffTypeSizes[typ]
+		if !ok {
+			continue // unknown types are skipped, as TIFF readers must
+		}
+		length := uint64(size) * uint64(count)
+		value := entry[8:12]
+		if length > 4 {
+			valueOffset := uint64(tiff.order.Uint32(entry[8:]))
+			if valueOffset+length > uint64(len(data)) {
+				return nil, fmt.Errorf("invalid DNG file: value of tag %d past the end of the file", tiff.order.Uint16(entry))
+			}
+			value = data[valueOffset : valueOffset+length]
+		}
+		ifd[tiff.order.Uint16(entry)] = tiffEntry{typ: typ, count: count, value: value[:length]}
+	}
+	return ifd, nil
+}
+
+// The full resolution image file directory: ifd0 or one of its sub IFDs.
+func (tiff tiffFile) mainIFD(ifd0 tiffIFD) (tiffIFD, error) {
+	candidates := []tiffIFD{ifd0}
+	if _, ok := ifd0[tagSubIFDs]; ok {
+		offsets, err := ifd0.uints(tiff, tagSubIFDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, offset := range offsets {
+			sub, err := tiff.ifd(offset)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, sub)
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate.uint(tiff, tagNewSubfileType, 0) == 0 {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid DNG file: no full resolution image")
+}
+
+// The integer values of a tag, of type BYTE, SHORT, LONG or IFD. A RATIONAL value is rounded down.
+func (ifd tiffIFD) uints(tiff tiffFile, tag uint16) ([]uint32, error) {
+	entry, ok := ifd[tag]
+	if !ok {
+		return nil, fmt.Errorf("invalid DNG file: missing tag %d", tag)
+	}
+	values := make([]uint32, entry.count)
+	for i := range values {
+		switch entry.typ {
+		case 1:
+			values[i] = uint32(entry.value[i])
+		case 3:
+			values[i] = uint32(tiff.order.Uint16(entry.value[2*i:]))
+		case 4, 13:
+			values[i] = tiff.order.Uint32(entry.value[4*i:])
+		case 5:
+			numerator, denominator := tiff.order.Uint32(entry.value[8*i:]), tiff.order.Uint32(entry.value[8*i+4:])
+			if denominator == 0 {
+				return nil, fmt.Errorf("invalid DNG file: tag %d divides by 0", tag)
+			}
+			values[i] = numerator / denominator
+		default:
+			return nil, fmt.Errorf("invalid DNG file: tag %d is of type %d, not an integer", tag, entry.typ)
+		}
+	}
+	return values, nil
+}
+
+// The first integer value of a tag, or def if the tag is missing or invalid.
+func (ifd tiffIFD) uint(tiff tiffFile, tag uint16, def int) int {
+	values, err := ifd.uints(tiff, tag)
+	if err != nil || len(values) == 0 {
+		return def
+	}
+	return int(values[0])
+}
+
+// The value of an ASCII tag, without its terminating NUL.
+func (ifd tiffIFD) string(tag uint16) (string, bool) {
+	entry, ok := ifd[tag]
+	if !ok || entry.typ != 2 {
+		return "", false
+	}
+	return string(bytes.TrimRight(entry.value, "\x00")), true
+}
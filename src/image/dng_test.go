@@ -0,0 +1,136 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// An entry of a test DNG file, with its little endian values.
+type dngEntry struct {
+	tag, typ uint16
+	count    uint32
+	value    []byte
+}
+
+func dngShorts(tag uint16, values ...uint16) dngEntry {
+	value := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint16(value[2*i:], v)
+	}
+	return dngEntry{tag: tag, typ: 3, count: uint32(len(values)), value: value}
+}
+
+func dngLongs(tag uint16, values ...uint32) dngEntry {
+	value := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(value[4*i:], v)
+	}
+	return dngEntry{tag: tag, typ: 4, count: uint32(len(values)), value: value}
+}
+
+func dngASCII(tag uint16, s string) dngEntry {
+	return dngEntry{tag: tag, typ: 2, count: uint32(len(s) + 1), value: append([]byte(s), 0)}
+}
+
+// Append an image file directory to a little endian TIFF file, its values of more than 4 bytes first, and
+// return the file and the offset of the directory.
+func appendIFD(data []byte, entries []dngEntry) ([]byte, uint32) {
+	offsets := make([]uint32, len(entries))
+	for i, entry := range entries {
+		if len(entry.value) > 4 {
+			offsets[i] = uint32(len(data))
+			data = append(data, entry.value...)
+		}
+	}
+
+	offset := uint32(len(data))
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(entries)))
+	for i, entry := range entries {
+		data = binary.LittleEndian.AppendUint16(data, entry.tag)
+		data = binary.LittleEndian.AppendUint16(data, entry.typ)
+		data = binary.LittleEndian.AppendUint32(data, entry.count)
+		if len(entry.value) > 4 {
+			data = binary.LittleEndian.AppendUint32(data, offsets[i])
+		} else {
+			var value [4]byte
+			copy(value[:], entry.value)
+			data = append(data, value[:]...)
+		}
+	}
+	return binary.LittleEndian.AppendUint32(data, 0), offset
+}
+
+// A DNG file with a preview in IFD 0 and a 16 bit LinearRaw main image of width x height pixels in its sub IFD.
+// photometric replaces the photometric interpretation of the main image.
+func testDNG(width, height int, samples []uint16, photometric uint16) []byte {
+	data := []byte("II*\x00\x00\x00\x00\x00")
+
+	strip := uint32(len(data))
+	for _, sample := range samples {
+		data = binary.LittleEndian.AppendUint16(data, sample)
+	}
+
+	data, sub := appendIFD(data, []dngEntry{
+		dngLongs(tagNewSubfileType, 0),
+		dngLongs(tagImageWidth, uint32(width)),
+		dngLongs(tagImageLength, uint32(height)),
+		dngShorts(tagBitsPerSample, 16, 16, 16),
+		dngShorts(tagCompression, 1),
+		dngShorts(tagPhotometricInterpretation, photometric),
+		dngLongs(tagStripOffsets, strip),
+		dngShorts(tagSamplesPerPixel, 3),
+		dngLongs(tagStripByteCounts, uint32(2*len(samples))),
+		dngShorts(tagBlackLevel, 1000),
+		dngShorts(tagWhiteLevel, 9000),
+	})
+	data, ifd0 := appendIFD(data, []dngEntry{
+		dngLongs(tagNewSubfileType, 1),
+		dngASCII(tagMake, "PhotoGnark"),
+		dngASCII(tagModel, "Test Sensor"),
+		dngASCII(tagDateTime, "2024:05:17 09:30:00"),
+		dngLongs(tagSubIFDs, sub),
+		{tag: tagDNGVersion, typ: 1, count: 4, value: []byte{1, 4, 0, 0}},
+	})
+	binary.LittleEndian.PutUint32(data[4:], ifd0)
+	return data
+}
+
+func TestDecodeDNG(t *testing.T) {
+	// Black, white, a quarter of the way from black to white, and a sample below the black level
+	samples := []uint16{1000, 1000, 1000, 9000, 9000, 9000, 3000, 3000, 3000, 0, 9000, 3000}
+
+	img, err := DecodeDNG(bytes.NewReader(testDNG(2, 2, samples, photometricLinearRaw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		x, y int
+		want RGBPixel
+	}{
+		{0, 0, RGBPixel{}},
+		{1, 0, RGBPixel{R: 255, G: 255, B: 255}},
+		{0, 1, RGBPixel{R: 128, G: 128, B: 128}},
+		{1, 1, RGBPixel{R: 0, G: 255, B: 128}},
+		{2, 0, RGBPixel{}},
+	} {
+		if got := img.GetPixel(c.x, c.y); got != c.want {
+			t.Errorf("decoded pixel (%d,%d) as %v, expected %v", c.x, c.y, got, c.want)
+		}
+	}
+	if img.M.DeviceID != "PhotoGnark Test Sensor" || !img.M.Timestamp.Equal(time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC)) || img.M.Width != 2 || img.M.Height != 2 {
+		t.Errorf("decoded the metadata %+v", img.M)
+	}
+
+	for name, dng := range map[string][]byte{
+		"mosaiced":  testDNG(2, 2, samples, 32803),
+		"too wide":  testDNG(Width+1, 1, make([]uint16, 3*(Width+1)), photometricLinearRaw),
+		"truncated": testDNG(2, 2, samples, photometricLinearRaw)[:40],
+		"not TIFF":  AllWhiteImage().ToByte(),
+	} {
+		if _, err := DecodeDNG(bytes.NewReader(dng)); err == nil {
+			t.Errorf("decoded a %s DNG file", name)
+		}
+	}
+}
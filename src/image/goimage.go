@@ -0,0 +1,50 @@
+package image
+
+import (
+	"fmt"
+	stdimage "image"
+	"image/color"
+)
+
+// FromGoImage converts an image of the standard library, e.g. as decoded by its image/png package or
+// returned by a resizer, into an image. A picture smaller than Width x Height is placed in the top left
+// corner, black outside it, and its size is recorded in the metadata, like a cropped image; a larger one
+// is rejected rather than cut. Colors are converted to 8 bit RGB, dropping the alpha channel.
+func FromGoImage(src stdimage.Image) (I, error) {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > Width || height > Height {
+		return I{}, fmt.Errorf("invalid image size %d x %d: expected at most %d x %d", width, height, Width, Height)
+	}
+
+	img := NewImage()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBAModel.Convert(src.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+			img.Pixels[y][x] = RGBPixel{R: c.R, G: c.G, B: c.B}
+		}
+	}
+	img.M.Width = width
+	img.M.Height = height
+
+	return img, nil
+}
+
+// ToGoImage converts the image, within the width and height of its metadata, into an opaque image of the
+// standard library, for any Go imaging code to use. An image without a valid size in its metadata is
+// converted whole.
+func (img I) ToGoImage() stdimage.Image {
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		width, height = Width, Height
+	}
+
+	dst := stdimage.NewRGBA(stdimage.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := img.Pixels[y][x]
+			dst.SetRGBA(x, y, color.RGBA{R: p.R, G: p.G, B: p.B, A: 255})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,50 @@
+package image
+
+import (
+	stdimage "image"
+	"image/color"
+	"testing"
+)
+
+// Converting to the standard library and back is lossless, for whole and cropped images.
+func TestGoImage(t *testing.T) {
+	img := NewImage()
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			img.Pixels[y][x] = RGBPixel{R: uint8(x * 16), G: uint8(y * 16), B: uint8(x + y)}
+		}
+	}
+	img.M.Width = Width
+	img.M.Height = Height
+
+	cropped := img
+	cropped.M = Metadata{Width: Width, Height: Height}
+	if err := cropped.Crop(3, 2, 9, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []I{img, cropped} {
+		got, err := FromGoImage(want.ToGoImage())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Pixels != want.Pixels || got.M.Width != want.M.Width || got.M.Height != want.M.Height {
+			t.Errorf("converted a %v x %v image to %v x %v, or changed its pixels", want.M.Width, want.M.Height, got.M.Width, got.M.Height)
+		}
+	}
+
+	// Bounds need not start at the origin
+	src := stdimage.NewRGBA(stdimage.Rect(5, 5, 7, 6))
+	src.SetRGBA(6, 5, color.RGBA{R: 1, G: 2, B: 3, A: 255})
+	got, err := FromGoImage(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Pixels[0][1] != (RGBPixel{R: 1, G: 2, B: 3}) || got.M.Width != 2 || got.M.Height != 1 {
+		t.Errorf("converted a 2 x 1 image at (5, 5) to %v x %v with pixel %v", got.M.Width, got.M.Height, got.Pixels[0][1])
+	}
+
+	if _, err := FromGoImage(stdimage.NewRGBA(stdimage.Rect(0, 0, Width+1, Height))); err == nil {
+		t.Errorf("converted an image wider than %d pixels", Width)
+	}
+}
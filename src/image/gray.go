@@ -0,0 +1,128 @@
+package image
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+)
+
+// A Gray is a grayscale image: one 8 bit luma value per pixel instead of an RGB pixel, e.g. the capture of a
+// monochrome sensor or a document scan. Its compliance predicates work on a single channel, a third of the
+// channels of an image I of the same size.
+type Gray struct {
+	Pixels [Height][Width]uint8 // Fixed-sized 2D array, indexed [y][x] like the pixels of an image.
+
+	M Metadata // Image metadata.
+}
+
+// A grayscale image with frontend pixels.
+type FrontendGray struct {
+	Pixels [Height][Width]frontend.Variable
+}
+
+// Luma returns the luma of a pixel, with the weights of ITU-R BT.601 out of 256, rounded down. White and
+// black keep their value.
+func Luma(pixel RGBPixel) uint8 {
+	return uint8((77*int(pixel.R) + 150*int(pixel.G) + 29*int(pixel.B)) >> 8)
+}
+
+// ToGray returns the grayscale image of the image, pixel by pixel its Luma, with a copy of its metadata.
+func (img I) ToGray() Gray {
+	gray := Gray{M: img.M.Copy()}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			gray.Pixels[y][x] = Luma(img.Pixels[y][x])
+		}
+	}
+	return gray
+}
+
+// Clone returns a copy of the grayscale image that shares nothing with it, like I.Clone.
+func (gray Gray) Clone() Gray {
+	clone := gray
+	clone.M = gray.M.Copy()
+	return clone
+}
+
+// Given a secret key, a nonce and the hash of the previous proof, sign this grayscale image, like I.Sign
+// signs an image.
+func (gray *Gray) Sign(secretKey signature.Signer, nonce *big.Int, prevProofHash *big.Int) []byte {
+	return SignDigest(secretKey, gray.Digest(), nonce, prevProofHash)
+}
+
+// Digest returns the message that is signed for this grayscale image: the hash of its pixels, row by row, and
+// its MetadataDigest, like I.Digest.
+func (gray Gray) Digest() []byte {
+	pixels := make([]uint8, 0, Width*Height)
+	for y := 0; y < Height; y++ {
+		pixels = append(pixels, gray.Pixels[y][:]...)
+	}
+	return channelsDigest(pixels, 8, gray.MetadataDigest())
+}
+
+// Crop crops the grayscale image to the specified rectangle and moves the cropped area to the top-left
+// corner, like I.Crop.
+func (gray *Gray) Crop(x0, y0, x1, y1 int) error {
+	width, height := gray.M.Width, gray.M.Height
+	if width == 0 || height == 0 {
+		return fmt.Errorf("invalid image metadata for width and height")
+	}
+	if x0 < 0 || y0 < 0 || x1 >= width || y1 >= height || x0 > x1 || y0 > y1 {
+		return fmt.Errorf("invalid crop dimensions: out of bounds")
+	}
+
+	var cropped [Height][Width]uint8
+	for y := 0; y <= y1-y0; y++ {
+		for x := 0; x <= x1-x0; x++ {
+			cropped[y][x] = gray.Pixels[y0+y][x0+x]
+		}
+	}
+	gray.Pixels = cropped
+
+	gray.M.Width = x1 - x0 + 1
+	gray.M.Height = y1 - y0 + 1
+
+	return nil
+}
+
+// Decode a JSON encoded grayscale image, like I.UnmarshalJSON decodes an image.
+func (gray *Gray) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	// Same fields as Gray, with rows of any length
+	var decoded struct {
+		Pixels [][]uint8
+		M      Metadata
+	}
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+
+	var pixels [Height][Width]uint8
+	if len(decoded.Pixels) != Height {
+		return fmt.Errorf("invalid image: %d rows of pixels, expected %d", len(decoded.Pixels), Height)
+	}
+	for y, row := range decoded.Pixels {
+		if len(row) != Width {
+			return fmt.Errorf("invalid image: %d pixels in row %d, expected %d", len(row), y, Width)
+		}
+		copy(pixels[y][:], row)
+	}
+
+	*gray = Gray{Pixels: pixels, M: decoded.M}
+	return nil
+}
+
+func (gray Gray) ToFrontendGray() FrontendGray {
+	frontendGray := FrontendGray{}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			frontendGray.Pixels[y][x] = frontend.Variable(gray.Pixels[y][x])
+		}
+	}
+	return frontendGray
+}
@@ -0,0 +1,39 @@
+package image
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// A grayscale image keeps white and black, and reads back from its JSON encoding as it was, cropped or not.
+func TestGray(t *testing.T) {
+	img := AllWhiteImage()
+	img.SetPixel(1, 1, RGBPixel{})
+	img.SetPixel(2, 1, RGBPixel{R: 255})
+	gray := img.ToGray()
+	if gray.Pixels[0][0] != 255 || gray.Pixels[1][1] != 0 || gray.Pixels[1][2] != 76 {
+		t.Errorf("converted white, black and red to %d, %d and %d", gray.Pixels[0][0], gray.Pixels[1][1], gray.Pixels[1][2])
+	}
+	if err := gray.Crop(1, 1, 9, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := json.Marshal(gray)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Gray
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Pixels != gray.Pixels || decoded.M.Width != 9 || decoded.M.Height != 5 {
+		t.Errorf("decoded a %v x %v image, or changed its pixels", decoded.M.Width, decoded.M.Height)
+	}
+	if string(decoded.Digest()) != string(gray.Digest()) {
+		t.Errorf("the decoded image has another digest")
+	}
+
+	if err := json.Unmarshal([]byte(`{"Pixels":[[1,2,3]],"M":{}}`), &decoded); err == nil {
+		t.Errorf("decoded an image of another size")
+	}
+}
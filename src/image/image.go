@@ -1,29 +1,37 @@
+// Package image defines PhotoProof images, I = {Width x Height, M}, and their encodings.
 package image
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
-	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark-crypto/signature"
 	"github.com/consensys/gnark/frontend"
+
+	"src/hashsuite"
+	"src/internal/field"
 )
 
+// Size of an image, in pixels. The compliance predicates are compiled for one size, 4:3 like the frames of
+// most cameras; a smaller image, e.g. a cropped one, records its size in its metadata and is black outside it.
 const (
-	N = 16
+	Width  = 16
+	Height = 12
 )
 
 /*
 PhotoProof defines an image I as a matrix NxN and some metadata M, such that I = {NxN, M}.
 
-We define I as a 2D array of RGBPixel, of size N*N, and
-a key:value map, where the value can be any data types supported by Gnark.
+We define I as a 2D array of RGBPixel, of Height rows of Width pixels, and
+its Metadata: the author, time, place and device of the capture, and the size of the image.
 */
 type I struct {
-	Pixels [N][N]RGBPixel // Fixed-sized 2D array.
+	Pixels [Height][Width]RGBPixel // Fixed-sized 2D array, indexed [y][x].
 
-	M map[string]interface{} // Image metadata.
+	M Metadata // Image metadata.
 }
 
 type RGBPixel struct {
@@ -34,7 +42,7 @@ type RGBPixel struct {
 
 // An image with frontend pixels
 type FrontendImage struct {
-	Pixels [N][N]FrontendPixel
+	Pixels [Height][Width]FrontendPixel
 }
 
 // Frontend pixels are made up of frontend.Variable instead of uint8.
@@ -48,6 +56,7 @@ type FrontendPixel struct {
 type Z struct {
 	Image     I
 	PublicKey signature.PublicKey // public digital signature key
+	Hops      int                 // proofs of edits since the original image, 0 for the original image
 }
 
 func (img *I) SetPixel(x, y int, color RGBPixel) {
@@ -65,50 +74,70 @@ func (img *I) GetPixel(x, y int) RGBPixel {
 
 func NewImage() I {
 	return I{
-		Pixels: [N][N]RGBPixel{}, // Initialize with a fixed-size array
-		M:      make(map[string]interface{}),
+		Pixels: [Height][Width]RGBPixel{}, // Initialize with a fixed-size array
+		M:      Metadata{},
 	}
 }
 
-// Given a secret key, sign this image
-func (img *I) Sign(secretKey signature.Signer) []byte {
+// Given a secret key, a nonce and the hash of the previous proof, sign this image
+func (img *I) Sign(secretKey signature.Signer, nonce *big.Int, prevProofHash *big.Int) []byte {
+	return SignDigest(secretKey, img.Digest(), nonce, prevProofHash)
+}
+
+// SignDigest signs the Statement of the digest of an image, as returned by Digest, a nonce and the hash of
+// the previous proof.
+func SignDigest(secretKey signature.Signer, digest []byte, nonce *big.Int, prevProofHash *big.Int) []byte {
 	// Instantiate hash function to be used when signing the image
-	hFunc := hash.MIMC_BN254.New()
-	signature, err := secretKey.Sign(img.ToBigEndian(), hFunc)
+	hFunc := hashsuite.Default.New()
+	signature, err := secretKey.Sign(Statement(digest, nonce, prevProofHash), hFunc)
 	if err != nil {
 		fmt.Println("Error while signing image: " + err.Error())
 	}
 	return signature
 }
 
+// Statement returns the message that is signed for the digest of an image, a nonce and the hash of the
+// previous proof: their hashsuite.Default hash, as a big endian field element.
+//
+// The nonce ties a signature, and every proof carrying it, to one context: the camera's capture counter,
+// which every edit of the picture carries along. A proof that is valid for one capture therefore cannot be
+// presented again as the proof of another. The hash of the previous proof (0 for an original image) links
+// an edit to the proof it was made from, so that the edit history cannot be reordered, forked or shortened.
+func Statement(digest []byte, nonce *big.Int, prevProofHash *big.Int) []byte {
+	h := hashsuite.Default.New()
+	h.Write(field.BigEndian(digest))
+	for _, value := range []*big.Int{nonce, prevProofHash} {
+		var element fr.Element
+		element.SetBigInt(value)
+		elementBytes := element.Bytes()
+		h.Write(elementBytes[:])
+	}
+	return h.Sum(nil)
+}
+
 // Create an all white image, with some metadata.
 func AllWhiteImage() I {
 	img := NewImage()
 
 	// Set all pixels in the image to white
-	for x := 0; x < N; x++ {
-		for y := 0; y < N; y++ {
-			img.SetPixel(x, y, RGBPixel{R: 255, G: 255, B: 255})
-		}
-	}
+	img.Fill(RGBPixel{R: 255, G: 255, B: 255})
 
 	// Set some metadata
-	img.M["Author"] = "John Doe"
-	img.M["N"] = N
-	img.M["height"] = N
-	img.M["width"] = N
+	img.M.Author = "John Doe"
+	img.M.Height = Height
+	img.M.Width = Width
 
 	return img
 }
 
-// Crop crops the image to the specified rectangle and moves the cropped area to the top-left corner.
+// Crop crops the image to the specified rectangle and moves the cropped area to the top-left corner. It changes
+// the image in place: SubImage returns a cropped copy instead, for callers that still need the image.
 func (img *I) Crop(x0, y0, x1, y1 int) error {
 	// Retrieve width and height from metadata
-	width, widthOk := img.M["width"].(int)
-	height, heightOk := img.M["height"].(int)
+	width, height := img.M.Width, img.M.Height
 
 	// Ensure width and height are properly set in metadata
-	if !widthOk || !heightOk {
+	if width == 0 || height == 0 {
 		return fmt.Errorf("invalid image metadata for width and height")
 	}
 
@@ -122,7 +151,7 @@ func (img *I) Crop(x0, y0, x1, y1 int) error {
 	cropHeight := y1 - y0 + 1 // + 1 because indeces start at (0,0)
 
 	// Create a temporary array to store the cropped pixels
-	var temp [N][N]RGBPixel
+	var temp [Height][Width]RGBPixel
 
 	// Copy the cropped pixels to the temporary array
 	for y := 0; y < cropHeight; y++ {
@@ -133,8 +162,8 @@ func (img *I) Crop(x0, y0, x1, y1 int) error {
 
 	// Blacken the entire original image
 	blackPixel := RGBPixel{R: 0, G: 0, B: 0}
-	for y := 0; y < N; y++ {
-		for x := 0; x < N; x++ {
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
 			img.Pixels[y][x] = blackPixel
 		}
 	}
@@ -146,15 +175,36 @@ func (img *I) Crop(x0, y0, x1, y1 int) error {
 		}
 	}
 
-	// Update metadata to reflect the new dimensions of the cropped area
-	img.M["width"] = cropWidth
-	img.M["height"] = cropHeight
+	// Update metadata to reflect the new dimensions of the cropped area, and its perceptual hash if it has one
+	img.M.Width = cropWidth
+	img.M.Height = cropHeight
+	if img.M.DHash != nil {
+		img.SetDHash()
+	}
 
 	return nil
 }
 
+// Clone returns a copy of the image that shares nothing with it: changing the pixels or metadata of either,
+// e.g. by Crop, leaves the other unchanged.
+func (img I) Clone() I {
+	clone := img
+	clone.M = img.M.Copy()
+	return clone
+}
+
+// SubImage returns the image cropped to the specified rectangle, like Crop, with a copy of its metadata
+// updated to the size of the rectangle. The image itself is left unchanged.
+func (img I) SubImage(x0, y0, x1, y1 int) (I, error) {
+	sub := img.Clone()
+	if err := sub.Crop(x0, y0, x1, y1); err != nil {
+		return I{}, err
+	}
+	return sub, nil
+}
+
 // Return the JSON encoded version of an image as bytes.
-func (img *I) ToByte() []byte {
+func (img I) ToByte() []byte {
 	encoded_image, err := json.Marshal(img)
 	if err != nil {
 		fmt.Println("Error while encoding image: " + err.Error())
@@ -164,34 +214,53 @@ func (img *I) ToByte() []byte {
 	return encoded_image
 }
 
+// Digest returns the message that is signed for this image, and assigned to the ImageBytes of the
+// compliance predicates: the hash of its channels and its MetadataDigest, as a big endian field element.
+// Every call hashes the whole image again, so compute it once per image and pass it along.
+func (img I) Digest() []byte {
+	return channelsDigest(img.channels(), 8, img.MetadataDigest())
+}
+
 // Return the JSON encoded version of an image as a string.
 func (img I) ToString() string {
 	return string(img.ToByte())
 }
 
-// Interprets image bytes as the bytes of a big-endian unsigned integer,
-// sets z to that value, and return z value as a big endian slice.
-// If this step is skipped, you get this error:
-// "runtime error: slice bounds out of range"
-// This step is required to define an image into something that Gnark circuits understand.
-func (img I) ToBigEndian() []byte {
-	// Define the picture as a "z value of a field element (fr.element)" that's converted into a big endian
-	img_bytes := img.ToByte() // Encode image into bytes using JSON
+// Decode a JSON encoded image, as returned by ToByte.
+// An image of another size than Width x Height, e.g. encoded by a build for other dimensions, is rejected
+// rather than cut or padded.
+func (img *I) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
 
-	var msgFr fr.Element // Define a field element
+	// Same fields as I, with rows of any length
+	var decoded struct {
+		Pixels [][]RGBPixel
+		M      Metadata
+	}
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
 
-	// (https://pkg.go.dev/github.com/consensys/gnark-crypto@v0.9.1/ecc/bn254/fr#Element.SetBytes)
-	msgFr.SetBytes(img_bytes)                 // Set the image bytes as the z value for the fr.Element
-	big_endian_bytes_Image := msgFr.Marshal() // Convert z value to a big endian slice
+	var pixels [Height][Width]RGBPixel
+	if len(decoded.Pixels) != Height {
+		return fmt.Errorf("invalid image: %d rows of pixels, expected %d", len(decoded.Pixels), Height)
+	}
+	for y, row := range decoded.Pixels {
+		if len(row) != Width {
+			return fmt.Errorf("invalid image: %d pixels in row %d, expected %d", len(row), y, Width)
+		}
+		copy(pixels[y][:], row)
+	}
 
-	return big_endian_bytes_Image
+	*img = I{Pixels: pixels, M: decoded.M}
+	return nil
 }
 
 func (img I) ToFrontendImage() FrontendImage {
 	frontendImage := FrontendImage{}
 	// Zero out the pixels outside the crop area
-	for y := 0; y < N; y++ {
-		for x := 0; x < N; x++ {
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
 			frontendImage.Pixels[y][x].R = frontend.Variable(img.Pixels[y][x].R)
 			frontendImage.Pixels[y][x].G = frontend.Variable(img.Pixels[y][x].G)
 			frontendImage.Pixels[y][x].B = frontend.Variable(img.Pixels[y][x].B)
@@ -203,7 +272,7 @@ func (img I) ToFrontendImage() FrontendImage {
 
 // Helper function to print the image pixels
 func (img *I) PrintImage() {
-	for y := 0; y < N; y++ {
+	for y := 0; y < Height; y++ {
 		p := img.Pixels[y]
 		fmt.Println(p)
 	}
@@ -0,0 +1,62 @@
+package image
+
+import "testing"
+
+// SubImage returns the cropped region with its own metadata, and leaves the image as it was.
+func TestSubImage(t *testing.T) {
+	img := gradientImage()
+	img.M.GPS = &GPS{Latitude: 1}
+	img.SetDHash()
+	before := img.Pixels
+	hash := *img.M.DHash
+
+	sub, err := img.SubImage(4, 2, 9, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Pixels[0][0] != img.Pixels[2][4] || sub.Pixels[7][5] != img.Pixels[9][9] || sub.Pixels[0][6] != (RGBPixel{}) {
+		t.Errorf("the sub image does not hold the region")
+	}
+	if sub.M.Width != 6 || sub.M.Height != 8 || sub.Validate() != nil {
+		t.Errorf("a %d x %d sub image, expected 6 x 8", sub.M.Width, sub.M.Height)
+	}
+	sub.M.GPS.Latitude = 2
+	if img.Pixels != before || img.M.Width != Width || *img.M.DHash != hash || img.M.GPS.Latitude != 1 {
+		t.Errorf("SubImage changed the image")
+	}
+
+	if _, err := img.SubImage(4, 2, Width, 9); err == nil {
+		t.Errorf("a sub image past the edge of the image was returned")
+	}
+}
+
+// A clone shares neither pixels nor metadata with the image.
+func TestClone(t *testing.T) {
+	img := NoiseImage(3)
+	img.M.GPS = &GPS{Latitude: 1}
+	img.SetDHash()
+
+	clone := img.Clone()
+	if clone.Pixels != img.Pixels || string(clone.Digest()) != string(img.Digest()) {
+		t.Fatal("the clone is not the image")
+	}
+	clone.SetPixel(0, 0, RGBPixel{R: 1})
+	clone.M.GPS.Latitude = 2
+	*clone.M.DHash = 0
+	if err := clone.Crop(1, 1, 5, 5); err != nil {
+		t.Fatal(err)
+	}
+	if img.M.GPS.Latitude != 1 || *img.M.DHash != img.DHash() || img.M.Width != Width || img.Pixels == clone.Pixels {
+		t.Errorf("changing the clone changed the image")
+	}
+
+	gray := img.ToGray()
+	grayClone := gray.Clone()
+	grayClone.M.GPS.Latitude = 2
+	deep := img.ToDeep()
+	deepClone := deep.Clone()
+	deepClone.M.GPS.Latitude = 2
+	if gray.M.GPS.Latitude != 1 || deep.M.GPS.Latitude != 1 {
+		t.Errorf("changing a grayscale or deep color clone changed the image")
+	}
+}
@@ -0,0 +1,33 @@
+package image
+
+import (
+	"fmt"
+	"image/jpeg"
+	"io"
+)
+
+// Quality EncodeJPEG is called with when no other is chosen, as in the standard library.
+const DefaultJPEGQuality = jpeg.DefaultQuality
+
+// DecodeJPEG decodes a JPEG file, e.g. as written by a camera, into an image. A picture smaller than
+// Width x Height is placed in the top left corner, black outside it, and its size is recorded in the
+// metadata, like a cropped image; a larger one is rejected rather than cut.
+//
+// JPEG is lossy: the pixels are those of the decoded file, so the camera signs what DecodeJPEG returns,
+// and a file encoded again from them no longer matches the signature (see prover.ProveSimilarity).
+func DecodeJPEG(r io.Reader) (I, error) {
+	decoded, err := jpeg.Decode(r)
+	if err != nil {
+		return I{}, err
+	}
+	return FromGoImage(decoded)
+}
+
+// EncodeJPEG writes the image, within the width and height of its metadata, as a JPEG file of the given
+// quality, from 1 (smallest) to 100 (best).
+func (img I) EncodeJPEG(w io.Writer, quality int) error {
+	if quality < 1 || quality > 100 {
+		return fmt.Errorf("invalid JPEG quality %d: expected 1 to 100", quality)
+	}
+	return jpeg.Encode(w, img.ToGoImage(), &jpeg.Options{Quality: quality})
+}
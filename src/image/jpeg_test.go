@@ -0,0 +1,45 @@
+package image
+
+import (
+	"bytes"
+	"testing"
+)
+
+// A cropped image written as a JPEG file reads back with its size, and its pixels within the loss of the
+// chosen quality.
+func TestJPEG(t *testing.T) {
+	img := AllWhiteImage()
+	if err := img.Crop(0, 0, 9, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, quality := range []int{1, DefaultJPEGQuality, 100} {
+		var encoded bytes.Buffer
+		if err := img.EncodeJPEG(&encoded, quality); err != nil {
+			t.Fatalf("quality %d: %v", quality, err)
+		}
+		decoded, err := DecodeJPEG(&encoded)
+		if err != nil {
+			t.Fatalf("quality %d: %v", quality, err)
+		}
+
+		if decoded.M.Width != 10 || decoded.M.Height != 8 {
+			t.Errorf("quality %d: decoded a %v x %v image, expected 10 x 8", quality, decoded.M.Width, decoded.M.Height)
+		}
+		if p := decoded.Pixels[0][0]; p.R < 250 || p.G < 250 || p.B < 250 {
+			t.Errorf("quality %d: decoded a white pixel as %v", quality, p)
+		}
+		if p := decoded.Pixels[Height-1][Width-1]; p != (RGBPixel{}) {
+			t.Errorf("quality %d: decoded %v outside the image, expected black", quality, p)
+		}
+	}
+
+	for _, quality := range []int{0, 101} {
+		if err := img.EncodeJPEG(&bytes.Buffer{}, quality); err == nil {
+			t.Errorf("encoded an image with quality %d", quality)
+		}
+	}
+	if _, err := DecodeJPEG(bytes.NewReader(img.ToByte())); err == nil {
+		t.Errorf("decoded a JSON encoded image as a JPEG file")
+	}
+}
@@ -0,0 +1,77 @@
+package image
+
+import "fmt"
+
+// A Kernel is a 3 x 3 convolution kernel: it maps a pixel to the sum of its neighborhood weighed by Weights, the
+// pixel itself at the center, divided by Divisor, rounded and clamped to 0-255.
+type Kernel struct {
+	Weights [3][3]int
+	Divisor int
+}
+
+// IdentityKernel keeps every pixel.
+var IdentityKernel = Kernel{Weights: [3][3]int{{0, 0, 0}, {0, 1, 0}, {0, 0, 0}}, Divisor: 1}
+
+// BoxBlurKernel averages a pixel with its 8 neighbors.
+var BoxBlurKernel = Kernel{Weights: [3][3]int{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}}, Divisor: 9}
+
+// SharpenKernel adds to a pixel its difference from the average of its 4 nearest neighbors, a mild unsharp mask
+// whose sums lie within [-255, 510] before they are clamped.
+var SharpenKernel = Kernel{Weights: [3][3]int{{0, -1, 0}, {-1, 8, -1}, {0, -1, 0}}, Divisor: 4}
+
+// Kernels are the permissible kernels of a convolution, by index: the whitelist its compliance predicate holds.
+var Kernels = [...]*Kernel{&IdentityKernel, &BoxBlurKernel, &SharpenKernel}
+
+// Apply returns the pixel (x, y) of img convolved by the kernel. Its 8 neighbors must be pixels of img too.
+func (kernel *Kernel) Apply(img *I, x, y int) RGBPixel {
+	var sums [3]int
+	for j, weights := range kernel.Weights {
+		for i, weight := range weights {
+			p := img.Pixels[y+j-1][x+i-1]
+			sums[0] += weight * int(p.R)
+			sums[1] += weight * int(p.G)
+			sums[2] += weight * int(p.B)
+		}
+	}
+	var out [3]uint8
+	for c, sum := range sums {
+		// A negative sum is rounded towards zero rather than down, which clamps to 0 all the same
+		out[c] = clampChannel((sum + kernel.Divisor/2) / kernel.Divisor)
+	}
+	return RGBPixel{R: out[0], G: out[1], B: out[2]}
+}
+
+// Convolve returns the image with every pixel of rect convolved by kernel, from the pixels of the image before
+// the convolution, and every other pixel kept. rect must lie a pixel within the edges of the image, so that the
+// neighbors of every pixel it covers are pixels of the image. It is a new image with a copy of the metadata, and
+// its perceptual hash updated if it has one.
+func (img I) Convolve(rect Rect, kernel *Kernel) (I, error) {
+	if err := rect.Validate(img.M.Width, img.M.Height); err != nil {
+		return I{}, err
+	}
+	if rect.X0 < 1 || rect.Y0 < 1 || rect.X1 > img.M.Width-2 || rect.Y1 > img.M.Height-2 {
+		return I{}, fmt.Errorf("invalid region %+v: on the edges of a %d x %d image", rect, img.M.Width, img.M.Height)
+	}
+	convolved := img.Clone()
+	for y := rect.Y0; y <= rect.Y1; y++ {
+		for x := rect.X0; x <= rect.X1; x++ {
+			convolved.Pixels[y][x] = kernel.Apply(&img, x, y)
+		}
+	}
+	if convolved.M.DHash != nil {
+		convolved.SetDHash()
+	}
+	return convolved, nil
+}
+
+// BoxBlur returns the image with every pixel of rect replaced by the rounded average of its 3 x 3 neighborhood,
+// e.g. to soften a background: the image convolved by the BoxBlurKernel within rect.
+func (img I) BoxBlur(rect Rect) (I, error) {
+	return img.Convolve(rect, &BoxBlurKernel)
+}
+
+// Sharpen returns the image with every pixel of rect convolved by the SharpenKernel, clamped to 0-255, e.g. to
+// make a slightly soft photo crisper.
+func (img I) Sharpen(rect Rect) (I, error) {
+	return img.Convolve(rect, &SharpenKernel)
+}
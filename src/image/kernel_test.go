@@ -0,0 +1,67 @@
+package image
+
+import "testing"
+
+func TestBoxBlur(t *testing.T) {
+	img, err := NoiseImage(1).SubImage(0, 0, 12, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	rect := Rect{X0: 1, Y0: 2, X1: 10, Y1: 7}
+	blurred, err := img.BoxBlur(rect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			want := img.Pixels[y][x]
+			if rect.Contains(x, y) {
+				var r, g, b int
+				for _, row := range img.Pixels[y-1 : y+2] {
+					for _, p := range row[x-1 : x+2] {
+						r, g, b = r+int(p.R), g+int(p.G), b+int(p.B)
+					}
+				}
+				want = RGBPixel{R: uint8((r + 4) / 9), G: uint8((g + 4) / 9), B: uint8((b + 4) / 9)}
+			}
+			if blurred.Pixels[y][x] != want {
+				t.Fatalf("pixel (%d, %d) is %v, expected %v", x, y, blurred.Pixels[y][x], want)
+			}
+		}
+	}
+	if *blurred.M.DHash != blurred.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+
+	// Every pixel of the rectangle has its neighbors within the image
+	for _, rect := range []Rect{{X0: 0, Y0: 2, X1: 4, Y1: 4}, {X0: 1, Y0: 1, X1: 12, Y1: 4}, {X0: 1, Y0: 1, X1: 4, Y1: 9}, {X0: 3, Y0: 1, X1: 2, Y1: 4}} {
+		if _, err := img.BoxBlur(rect); err == nil {
+			t.Errorf("region %+v was blurred", rect)
+		}
+	}
+}
+
+func TestSharpen(t *testing.T) {
+	img := GradientImage()
+	img.Pixels[5][7] = RGBPixel{R: 250, G: 3, B: 100}
+
+	// The pixel gains its difference from the average of its 4 nearest neighbors, clamped
+	rect := Rect{X0: 7, Y0: 5, X1: 7, Y1: 5}
+	sharpened, err := img.Sharpen(rect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want [3]int
+	for c, channel := range []func(p RGBPixel) uint8{func(p RGBPixel) uint8 { return p.R }, func(p RGBPixel) uint8 { return p.G }, func(p RGBPixel) uint8 { return p.B }} {
+		neighbors := int(channel(img.Pixels[4][7])) + int(channel(img.Pixels[6][7])) + int(channel(img.Pixels[5][6])) + int(channel(img.Pixels[5][8]))
+		want[c] = min(max((8*int(channel(img.Pixels[5][7]))-neighbors+2)/4, 0), 255)
+	}
+	if p := sharpened.Pixels[5][7]; p != (RGBPixel{R: uint8(want[0]), G: uint8(want[1]), B: uint8(want[2])}) {
+		t.Errorf("sharpened pixel %v, expected %v", p, want)
+	}
+	if sharpened.Pixels[5][6] != img.Pixels[5][6] {
+		t.Errorf("a pixel outside of the rectangle was sharpened")
+	}
+}
@@ -0,0 +1,76 @@
+package image
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Metadata is what an image records besides its pixels. Every field is part of the image's canonical encoding,
+// and so of the digest the camera signs: changing the metadata of a signed image invalidates its signature.
+type Metadata struct {
+	Author    string    `json:"author,omitempty"`
+	Timestamp time.Time `json:"timestamp"`           // When the picture was taken, the zero time if unknown.
+	GPS       *GPS      `json:"gps,omitempty"`       // Where the picture was taken, nil if unknown.
+	DeviceID  string    `json:"device_id,omitempty"` // The camera that took the picture, e.g. its make and model.
+	DHash     *uint64   `json:"dhash,omitempty"`     // The perceptual hash of the image, nil if not stored, see I.DHash.
+
+	// Size of the image, at most Width x Height: a smaller image, e.g. a cropped one, is black outside it.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// A GPS position, in degrees and in meters above sea level.
+type GPS struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Altitude  float64 `json:"altitude"`
+}
+
+// Check returns an error if the metadata cannot be encoded: a size larger than the image or a GPS position
+// off the globe.
+func (m Metadata) Check() error {
+	if m.Width < 0 || m.Width > Width || m.Height < 0 || m.Height > Height {
+		return fmt.Errorf("invalid metadata: a size of %d x %d, expected at most %d x %d", m.Width, m.Height, Width, Height)
+	}
+	if m.GPS != nil {
+		for _, v := range []float64{m.GPS.Latitude, m.GPS.Longitude, m.GPS.Altitude} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("invalid metadata: GPS position %+v", *m.GPS)
+			}
+		}
+		if math.Abs(m.GPS.Latitude) > 90 || math.Abs(m.GPS.Longitude) > 180 {
+			return fmt.Errorf("invalid metadata: GPS position %+v is off the globe", *m.GPS)
+		}
+	}
+	return nil
+}
+
+// Copy returns a copy of the metadata that shares nothing with it.
+func (m Metadata) Copy() Metadata {
+	if m.GPS != nil {
+		gps := *m.GPS
+		m.GPS = &gps
+	}
+	if m.DHash != nil {
+		hash := *m.DHash
+		m.DHash = &hash
+	}
+	return m
+}
+
+// Longest Author of an image that Credit writes, in bytes.
+const MaxAuthor = 32
+
+// Credit returns the image with author, of at most MaxAuthor bytes, as the Author of its metadata, e.g. the
+// photographer or the agency to credit. Author is the only key of the metadata an edit may change: the pixels,
+// and the keys that record the capture, e.g. its Timestamp, GPS and DeviceID, are kept. It is a new image with a
+// copy of the metadata.
+func (img I) Credit(author string) (I, error) {
+	if len(author) > MaxAuthor {
+		return I{}, fmt.Errorf("invalid author of %d bytes: expected at most %d", len(author), MaxAuthor)
+	}
+	credited := img.Clone()
+	credited.M.Author = author
+	return credited, nil
+}
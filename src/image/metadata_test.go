@@ -0,0 +1,57 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Crediting an image changes its Author only, which its MetadataEncoding holds right after the header.
+func TestCredit(t *testing.T) {
+	img := NoiseImage(1)
+	img.M.Author = "Jane Doe"
+	img.M.Timestamp = time.Unix(1700000000, 5)
+	img.M.GPS = &GPS{Latitude: 46.2, Longitude: 6.1}
+	img.M.DeviceID = "PhotoGnark"
+
+	credited, err := img.Credit("Agency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credited.Pixels != img.Pixels || credited.M.Author != "Agency" || !credited.M.Timestamp.Equal(img.M.Timestamp) || credited.M.GPS == img.M.GPS || *credited.M.GPS != *img.M.GPS || credited.M.DeviceID != img.M.DeviceID {
+		t.Fatalf("crediting changed more than the author, or shares the metadata: %+v", credited.M)
+	}
+	if img.M.Author != "Jane Doe" {
+		t.Error("crediting changed the credited image")
+	}
+
+	// The encoding is the rest of the canonical encoding, and the author follows its header
+	for _, m := range []I{img, credited} {
+		encoding, err := m.MetadataEncoding()
+		if err != nil {
+			t.Fatal(err)
+		}
+		binary, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(binary, encoding[:MetadataHeaderSize]) || !bytes.HasSuffix(binary, encoding[MetadataHeaderSize:]) {
+			t.Error("the metadata encoding is not the canonical encoding without the pixels")
+		}
+		author := encoding[MetadataHeaderSize+4:]
+		if length := binaryLength(encoding[MetadataHeaderSize:]); length != len(m.M.Author) || string(author[:length]) != m.M.Author {
+			t.Errorf("the author %q does not follow the header", m.M.Author)
+		}
+	}
+
+	if _, err := img.Credit(strings.Repeat("a", MaxAuthor+1)); err == nil {
+		t.Error("credited an author longer than MaxAuthor")
+	}
+}
+
+// The length a string of the canonical encoding starts with.
+func binaryLength(data []byte) int {
+	return int(binary.BigEndian.Uint32(data))
+}
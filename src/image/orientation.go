@@ -0,0 +1,82 @@
+package image
+
+import "fmt"
+
+// Rotate returns the image rotated clockwise by quarters quarter turns, within the width and height of its
+// metadata, as a new image: the rotated pixels in the top left corner, every other pixel black, and a copy of
+// the metadata with the width and height of the rotated image, and its perceptual hash if it has one. Any
+// number of quarter turns is allowed, e.g. -1 for a counterclockwise quarter turn.
+//
+// An odd number of quarter turns swaps the width and height, so it returns an error if the rotated image
+// would not fit the Width x Height pixels of an image, e.g. for a landscape image wider than Height.
+func (img I) Rotate(quarters int) (I, error) {
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		return I{}, fmt.Errorf("invalid image metadata for width and height")
+	}
+	quarters = ((quarters % 4) + 4) % 4
+	if quarters%2 == 1 && (width > Height || height > Width) {
+		return I{}, fmt.Errorf("a %d x %d image rotated by a quarter turn does not fit %d x %d pixels", width, height, Width, Height)
+	}
+
+	rotated := I{M: img.M.Copy()}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			switch quarters {
+			case 0:
+				rotated.Pixels[y][x] = img.Pixels[y][x]
+			case 1:
+				rotated.Pixels[x][height-1-y] = img.Pixels[y][x]
+			case 2:
+				rotated.Pixels[height-1-y][width-1-x] = img.Pixels[y][x]
+			case 3:
+				rotated.Pixels[width-1-x][y] = img.Pixels[y][x]
+			}
+		}
+	}
+	if quarters%2 == 1 {
+		rotated.M.Width, rotated.M.Height = height, width
+	}
+	if rotated.M.DHash != nil {
+		rotated.SetDHash()
+	}
+	return rotated, nil
+}
+
+// FlipH returns the image mirrored left to right within the width and height of its metadata, as a new image
+// with a copy of the metadata, and its perceptual hash updated if it has one.
+func (img I) FlipH() (I, error) {
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		return I{}, fmt.Errorf("invalid image metadata for width and height")
+	}
+
+	flipped := I{M: img.M.Copy()}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			flipped.Pixels[y][width-1-x] = img.Pixels[y][x]
+		}
+	}
+	if flipped.M.DHash != nil {
+		flipped.SetDHash()
+	}
+	return flipped, nil
+}
+
+// FlipV returns the image mirrored top to bottom within the width and height of its metadata, as a new image
+// with a copy of the metadata, and its perceptual hash updated if it has one.
+func (img I) FlipV() (I, error) {
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		return I{}, fmt.Errorf("invalid image metadata for width and height")
+	}
+
+	flipped := I{M: img.M.Copy()}
+	for y := 0; y < height; y++ {
+		copy(flipped.Pixels[height-1-y][:width], img.Pixels[y][:width])
+	}
+	if flipped.M.DHash != nil {
+		flipped.SetDHash()
+	}
+	return flipped, nil
+}
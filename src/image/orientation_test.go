@@ -0,0 +1,109 @@
+package image
+
+import "testing"
+
+// Rotate turns the image within its size, and four quarter turns are no turn.
+func TestRotate(t *testing.T) {
+	img, err := CoordinateImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	quarter, err := img.Rotate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quarter.M.Width != 5 || quarter.M.Height != 10 || quarter.Validate() != nil {
+		t.Fatalf("a %d x %d quarter turn, expected 5 x 10", quarter.M.Width, quarter.M.Height)
+	}
+	// The top left corner turns to the top right corner, the bottom left corner to the top left one
+	if quarter.Pixels[0][4] != img.Pixels[0][0] || quarter.Pixels[0][0] != img.Pixels[4][0] || quarter.Pixels[9][0] != img.Pixels[4][9] {
+		t.Errorf("the quarter turn is not clockwise")
+	}
+	if counter, _ := img.Rotate(-1); counter.Pixels != mustRotate(t, quarter, 2).Pixels {
+		t.Errorf("a counterclockwise quarter turn is not three clockwise ones")
+	}
+	half := mustRotate(t, img, 2)
+	if half.Pixels[0][0] != img.Pixels[4][9] || half.M.Width != 10 || half.M.Height != 5 {
+		t.Errorf("the half turn is not of the image")
+	}
+
+	full := img
+	for i := 0; i < 4; i++ {
+		full = mustRotate(t, full, 1)
+	}
+	if full.Pixels != img.Pixels || *full.M.DHash != *img.M.DHash {
+		t.Errorf("four quarter turns changed the image")
+	}
+	if *quarter.M.DHash != quarter.DHash() {
+		t.Errorf("the perceptual hash of the rotated image was not updated")
+	}
+
+	if _, err := AllWhiteImage().Rotate(1); err == nil {
+		t.Errorf("a landscape image wider than Height was turned by a quarter")
+	}
+	if _, err := NewImage().Rotate(2); err == nil {
+		t.Errorf("an image without a size was rotated")
+	}
+}
+
+func mustRotate(t *testing.T, img I, quarters int) I {
+	t.Helper()
+	rotated, err := img.Rotate(quarters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rotated
+}
+
+// FlipH mirrors the image within its size, and flipping twice keeps it.
+func TestFlipH(t *testing.T) {
+	img, err := CoordinateImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	flipped, err := img.FlipH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flipped.Pixels[0][0] != img.Pixels[0][9] || flipped.Pixels[4][9] != img.Pixels[4][0] || flipped.Pixels[0][10] != (RGBPixel{}) {
+		t.Errorf("the flip is not of the image within its width")
+	}
+	if flipped.M.Width != 10 || flipped.M.Height != 5 || *flipped.M.DHash != flipped.DHash() || flipped.Validate() != nil {
+		t.Errorf("the flip does not keep the size of the image, or its perceptual hash is stale")
+	}
+	if twice, _ := flipped.FlipH(); twice.Pixels != img.Pixels {
+		t.Errorf("flipping twice changed the image")
+	}
+	if _, err := NewImage().FlipH(); err == nil {
+		t.Errorf("an image without a size was flipped")
+	}
+}
+
+func TestFlipV(t *testing.T) {
+	img, err := CoordinateImage().SubImage(0, 0, 9, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	flipped, err := img.FlipV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flipped.Pixels[0][0] != img.Pixels[4][0] || flipped.Pixels[4][9] != img.Pixels[0][9] || flipped.Pixels[5][0] != (RGBPixel{}) {
+		t.Errorf("the flip is not of the image within its height")
+	}
+	if flipped.M.Width != 10 || flipped.M.Height != 5 || *flipped.M.DHash != flipped.DHash() || flipped.Validate() != nil {
+		t.Errorf("the flip does not keep the size of the image, or its perceptual hash is stale")
+	}
+	if twice, _ := flipped.FlipV(); twice.Pixels != img.Pixels {
+		t.Errorf("flipping twice changed the image")
+	}
+	if _, err := NewImage().FlipV(); err == nil {
+		t.Errorf("an image without a size was flipped")
+	}
+}
@@ -0,0 +1,94 @@
+package image
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Size of a Watermark, in pixels.
+const (
+	WatermarkWidth  = 4
+	WatermarkHeight = 4
+)
+
+// A Watermark is a small opaque image, e.g. the logo of a press agency, which Stamp composites over an image.
+type Watermark [WatermarkHeight][WatermarkWidth]RGBPixel
+
+// Stamp returns the image with watermark composited at (x, y), its top left corner: every pixel the watermark
+// covers replaced by the pixel of the watermark, and every other pixel kept. The watermark must lie within the
+// image. It is a new image with a copy of the metadata, and its perceptual hash updated if it has one.
+func (img I) Stamp(watermark *Watermark, x, y int) (I, error) {
+	rect := Rect{X0: x, Y0: y, X1: x + WatermarkWidth - 1, Y1: y + WatermarkHeight - 1}
+	if err := rect.Validate(img.M.Width, img.M.Height); err != nil {
+		return I{}, fmt.Errorf("invalid watermark position (%d, %d): %w", x, y, err)
+	}
+	stamped := img.Clone()
+	for j, row := range watermark {
+		copy(stamped.Pixels[y+j][x:], row[:])
+	}
+	if stamped.M.DHash != nil {
+		stamped.SetDHash()
+	}
+	return stamped, nil
+}
+
+// Height of a Caption, in pixels.
+const CaptionHeight = 4
+
+// A Caption is a strip of rendered text, e.g. a credit line or a timestamp, as wide as the pixels of an image:
+// a pixel of the text is set, and white once captioned, and every other pixel black.
+type Caption [CaptionHeight][Width]bool
+
+// AddCaption returns the image with caption drawn along its top or bottom edge, from row y: every pixel of the
+// strip is white where the caption is set and black elsewhere, and every other pixel kept. The text must fit
+// within the width of the image, so that the strip keeps the pixels past it black. It is a new image with a
+// copy of the metadata, and its perceptual hash updated if it has one.
+func (img I) AddCaption(caption *Caption, y int) (I, error) {
+	if y != 0 && y != img.M.Height-CaptionHeight || img.M.Height < CaptionHeight {
+		return I{}, fmt.Errorf("invalid caption row %d: expected the top or the bottom edge of a %d x %d image", y, img.M.Width, img.M.Height)
+	}
+	for j := range caption {
+		if slices.Contains(caption[j][img.M.Width:], true) {
+			return I{}, fmt.Errorf("invalid caption: wider than a %d x %d image", img.M.Width, img.M.Height)
+		}
+	}
+	captioned := img.Clone()
+	for j := range caption {
+		for x, set := range caption[j] {
+			captioned.Pixels[y+j][x] = RGBPixel{}
+			if set {
+				captioned.Pixels[y+j][x] = RGBPixel{R: 255, G: 255, B: 255}
+			}
+		}
+	}
+	if captioned.M.DHash != nil {
+		captioned.SetDHash()
+	}
+	return captioned, nil
+}
+
+// Widest border of AddBorder, in pixels.
+const MaxBorder = 4
+
+// AddBorder returns the image with a border of width pixels, in [0, MaxBorder], drawn in color along its edges:
+// every pixel of the image less than width pixels from one of its edges takes the color, and every other pixel
+// is kept. It is a new image with a copy of the metadata, and its perceptual hash updated if it has one. A
+// width of 0 keeps the image.
+func (img I) AddBorder(width int, color RGBPixel) (I, error) {
+	if width < 0 || width > MaxBorder {
+		return I{}, fmt.Errorf("invalid border width %d: expected a width in [0, %d]", width, MaxBorder)
+	}
+	inner := Rect{X0: width, Y0: width, X1: img.M.Width - 1 - width, Y1: img.M.Height - 1 - width}
+	bordered := img.Clone()
+	for y := 0; y < img.M.Height; y++ {
+		for x := 0; x < img.M.Width; x++ {
+			if !inner.Contains(x, y) {
+				bordered.Pixels[y][x] = color
+			}
+		}
+	}
+	if bordered.M.DHash != nil {
+		bordered.SetDHash()
+	}
+	return bordered, nil
+}
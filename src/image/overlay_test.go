@@ -0,0 +1,121 @@
+package image
+
+import "testing"
+
+func TestStamp(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 11, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	var watermark Watermark
+	for j := range watermark {
+		for i := range watermark[j] {
+			watermark[j][i] = RGBPixel{R: uint8(16 * i), G: uint8(16 * j), B: 200}
+		}
+	}
+	stamped, err := img.Stamp(&watermark, 8, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			want := img.Pixels[y][x]
+			if x >= 8 && x < 8+WatermarkWidth && y >= 5 && y < 5+WatermarkHeight {
+				want = watermark[y-5][x-8]
+			}
+			if stamped.Pixels[y][x] != want {
+				t.Fatalf("pixel (%d, %d) is %v, expected %v", x, y, stamped.Pixels[y][x], want)
+			}
+		}
+	}
+	if *stamped.M.DHash != stamped.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+
+	// The watermark lies within the image, not only within its pixel matrix
+	for _, position := range [][2]int{{-1, 0}, {9, 0}, {0, 6}} {
+		if _, err := img.Stamp(&watermark, position[0], position[1]); err == nil {
+			t.Errorf("a watermark at %v was stamped", position)
+		}
+	}
+}
+
+func TestAddCaption(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 11, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	var caption Caption
+	caption[1][2], caption[2][11] = true, true
+	for _, y := range []int{0, 10 - CaptionHeight} {
+		captioned, err := img.AddCaption(&caption, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for row := 0; row < Height; row++ {
+			for x := 0; x < Width; x++ {
+				want := img.Pixels[row][x]
+				if row >= y && row < y+CaptionHeight {
+					want = RGBPixel{}
+					if caption[row-y][x] {
+						want = RGBPixel{R: 255, G: 255, B: 255}
+					}
+				}
+				if captioned.Pixels[row][x] != want {
+					t.Fatalf("pixel (%d, %d) is %v, expected %v", x, row, captioned.Pixels[row][x], want)
+				}
+			}
+		}
+		if *captioned.M.DHash != captioned.DHash() {
+			t.Errorf("the perceptual hash is stale")
+		}
+	}
+
+	// The caption lies along an edge, within the width of the image
+	if _, err := img.AddCaption(&caption, 1); err == nil {
+		t.Errorf("a caption off the edges was drawn")
+	}
+	caption[3][12] = true
+	if _, err := img.AddCaption(&caption, 0); err == nil {
+		t.Errorf("a caption wider than the image was drawn")
+	}
+}
+
+func TestAddBorder(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 10, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	color := RGBPixel{R: 250, G: 240, B: 200}
+	bordered, err := img.AddBorder(2, color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			want := img.Pixels[y][x]
+			if x < 11 && y < 7 && (x < 2 || x > 8 || y < 2 || y > 4) {
+				want = color
+			}
+			if bordered.Pixels[y][x] != want {
+				t.Fatalf("pixel (%d, %d) is %v, expected %v", x, y, bordered.Pixels[y][x], want)
+			}
+		}
+	}
+	if *bordered.M.DHash != bordered.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+
+	if kept, _ := img.AddBorder(0, color); kept.Pixels != img.Pixels {
+		t.Errorf("a border of 0 pixels changed the image")
+	}
+	if _, err := img.AddBorder(MaxBorder+1, color); err == nil {
+		t.Errorf("a border wider than MaxBorder was drawn")
+	}
+}
@@ -0,0 +1,62 @@
+package image
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+
+	"src/internal/field"
+)
+
+/*
+A packed image holds the channels of an image in few field elements instead of one field element per channel:
+its channels, pixel by pixel in the order R, G, B, row by row, packed little endian PackedChannels to a field
+element, as field.Pack does. Channel i is bits 8*(i%31) to 8*(i%31)+7 of element i/31, and the high bits of
+the last element, after the remaining channels, are 0. The 576 channels of a 16 x 12 image fit in 19 elements,
+and an image of n pixels in about n/10.
+
+These are the elements an image's Digest hashes, before its MetadataDigest, so a circuit given a packed image
+hashes it without packing it again.
+*/
+
+// Number of 8 bit channels packed into a field element, and of field elements of a packed image.
+const (
+	PackedChannels = field.PackedBits / 8
+	PackedLength   = (3*Width*Height + PackedChannels - 1) / PackedChannels
+)
+
+// A FrontendPacked is a packed image inside a circuit, see Pack.
+type FrontendPacked struct {
+	Elements [PackedLength]frontend.Variable
+}
+
+// Pack returns the PackedLength elements of the packed image.
+func (img I) Pack() []*big.Int {
+	return field.Pack(img.channels(), 8)
+}
+
+// UnpackImage returns the image of packed elements, as returned by Pack, with the width and height of the
+// whole image as its metadata. It returns an error if elements are not a packed image.
+func UnpackImage(elements []*big.Int) (I, error) {
+	channels, err := field.Unpack[uint8](elements, 8, 3*Width*Height)
+	if err != nil {
+		return I{}, fmt.Errorf("invalid packed image: %w", err)
+	}
+	img := NewImage()
+	for i := 0; i < Width*Height; i++ {
+		img.Pixels[i/Width][i%Width] = RGBPixel{R: channels[3*i], G: channels[3*i+1], B: channels[3*i+2]}
+	}
+	img.M.Width = Width
+	img.M.Height = Height
+	return img, nil
+}
+
+// ToFrontendPacked returns the packed image as the witness of a FrontendPacked.
+func (img I) ToFrontendPacked() FrontendPacked {
+	var packed FrontendPacked
+	for i, element := range img.Pack() {
+		packed.Elements[i] = element
+	}
+	return packed
+}
@@ -0,0 +1,45 @@
+package image
+
+import (
+	"math/big"
+	"testing"
+)
+
+// A packed image reads back as it was, and is rejected with too few elements or bits set beyond its channels.
+func TestPack(t *testing.T) {
+	img := AllWhiteImage()
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			img.SetPixel(x, y, RGBPixel{R: uint8(x), G: uint8(y), B: uint8(x*Height + y)})
+		}
+	}
+
+	packed := img.Pack()
+	if len(packed) != PackedLength {
+		t.Fatalf("packed %d channels into %d elements, expected %d", 3*Width*Height, len(packed), PackedLength)
+	}
+	// Channel i is byte i%31 of element i/31, little endian: the G channel of pixel (10, 1) is channel 50
+	if got := new(big.Int).Rsh(packed[1], 8*19).Uint64() & 0xff; got != 1 {
+		t.Errorf("channel 50 packed as %d, expected the G channel 1", got)
+	}
+
+	unpacked, err := UnpackImage(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unpacked.Pixels != img.Pixels || unpacked.M.Width != Width || unpacked.M.Height != Height {
+		t.Errorf("unpacked another image")
+	}
+
+	highBits := append([]*big.Int{}, packed...)
+	highBits[PackedLength-1] = new(big.Int).Lsh(big.NewInt(1), 8*(3*Width*Height%PackedChannels))
+	for name, elements := range map[string][]*big.Int{
+		"truncated":      packed[:PackedLength-1],
+		"longer":         append(append([]*big.Int{}, packed...), big.NewInt(0)),
+		"with high bits": highBits,
+	} {
+		if _, err := UnpackImage(elements); err == nil {
+			t.Errorf("unpacked a %s packed image", name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package image
+
+import "math/rand"
+
+// Test patterns, full-size images with the metadata of AllWhiteImage. Unlike an all white image, where every
+// pixel is every other pixel, they tell pixels apart, so a test over them catches a predicate that reads the
+// wrong pixel or the wrong channel, e.g. swapped x and y.
+
+// GradientImage returns an image whose red channel rises from 0 on the left to 255 on the right, whose green
+// channel rises from 0 at the top to 255 at the bottom, and whose blue channel falls along the diagonal.
+func GradientImage() I {
+	img := AllWhiteImage()
+	img.Map(func(x, y int, _ RGBPixel) RGBPixel {
+		return RGBPixel{
+			R: uint8(255 * x / (Width - 1)),
+			G: uint8(255 * y / (Height - 1)),
+			B: uint8(255 - 255*(x+y)/(Width+Height-2)),
+		}
+	})
+	return img
+}
+
+// CheckerboardImage returns a checkerboard of black and white squares of size pixels, white in the top left
+// corner. Squares are at least a pixel.
+func CheckerboardImage(size int) I {
+	size = max(size, 1)
+	img := AllWhiteImage()
+	img.Map(func(x, y int, p RGBPixel) RGBPixel {
+		if (x/size+y/size)%2 == 1 {
+			return RGBPixel{}
+		}
+		return p
+	})
+	return img
+}
+
+// CoordinateImage returns an image whose every pixel encodes its coordinates: R is x, G is y and B is
+// x*Height + y, which differs for every pixel.
+func CoordinateImage() I {
+	img := AllWhiteImage()
+	img.Map(func(x, y int, _ RGBPixel) RGBPixel {
+		return RGBPixel{R: uint8(x), G: uint8(y), B: uint8(x*Height + y)}
+	})
+	return img
+}
+
+// NoiseImage returns an image of random channels, the same for the same seed.
+func NoiseImage(seed int64) I {
+	random := rand.New(rand.NewSource(seed))
+	img := AllWhiteImage()
+	img.Map(func(_, _ int, _ RGBPixel) RGBPixel {
+		return RGBPixel{R: uint8(random.Intn(256)), G: uint8(random.Intn(256)), B: uint8(random.Intn(256))}
+	})
+	return img
+}
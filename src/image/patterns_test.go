@@ -0,0 +1,37 @@
+package image
+
+import "testing"
+
+func TestPatterns(t *testing.T) {
+	// Every pixel of the coordinate image is its own, so a transposed image is another one
+	seen := make(map[RGBPixel]bool)
+	coordinates := CoordinateImage()
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			seen[coordinates.Pixels[y][x]] = true
+		}
+	}
+	if len(seen) != Width*Height {
+		t.Errorf("%d distinct pixels in the coordinate image", len(seen))
+	}
+
+	gradient := GradientImage()
+	if gradient.Pixels[0][0] != (RGBPixel{B: 255}) || gradient.Pixels[Height-1][Width-1] != (RGBPixel{R: 255, G: 255}) {
+		t.Errorf("the gradient runs from %v to %v", gradient.Pixels[0][0], gradient.Pixels[Height-1][Width-1])
+	}
+
+	checkerboard := CheckerboardImage(3)
+	white, black := RGBPixel{R: 255, G: 255, B: 255}, RGBPixel{}
+	if checkerboard.Pixels[2][2] != white || checkerboard.Pixels[2][3] != black || checkerboard.Pixels[3][3] != white {
+		t.Errorf("the squares of the checkerboard are not of 3 pixels")
+	}
+
+	if NoiseImage(1).Pixels != NoiseImage(1).Pixels || NoiseImage(1).Pixels == NoiseImage(2).Pixels {
+		t.Errorf("the noise does not follow its seed")
+	}
+	for name, img := range map[string]I{"coordinate": coordinates, "gradient": gradient, "checkerboard": checkerboard, "noise": NoiseImage(1)} {
+		if err := img.Validate(); err != nil {
+			t.Errorf("the %s image is invalid: %v", name, err)
+		}
+	}
+}
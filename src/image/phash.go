@@ -0,0 +1,87 @@
+package image
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+/*
+The perceptual hash of an image is a difference hash (dHash): the image, within the size of its metadata, is
+shrunk to a grid of 9 x 8 cells of average luma, and bit 8*y+x of the hash is set when cell (x, y) is darker
+than cell (x+1, y). Resizing, recompression or a slight change of brightness keep most of its 64 bits, which
+cryptographic digests do not, so the Hamming distance between two hashes tells whether two images look alike.
+
+It is not a proof of anything: verifiers use it to pick, among many originals, the few worth running a SNARK
+verification against for a published image.
+*/
+
+// Size of the grid of cells a perceptual hash compares.
+const (
+	dHashWidth  = 9
+	dHashHeight = 8
+)
+
+// DHash returns the perceptual hash of the image, within the width and height of its metadata, or of the
+// whole image if its metadata has no valid size.
+func (img I) DHash() uint64 {
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		width, height = Width, Height
+	}
+
+	// The average luma of every cell, which covers at least one pixel however small the image
+	var cells [dHashHeight][dHashWidth]int
+	for cy := 0; cy < dHashHeight; cy++ {
+		y0, y1 := cellRange(cy, dHashHeight, height)
+		for cx := 0; cx < dHashWidth; cx++ {
+			x0, x1 := cellRange(cx, dHashWidth, width)
+			sum := 0
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					sum += int(Luma(img.Pixels[y][x]))
+				}
+			}
+			cells[cy][cx] = sum / ((x1 - x0) * (y1 - y0))
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < dHashHeight; y++ {
+		for x := 0; x < dHashWidth-1; x++ {
+			if cells[y][x] < cells[y][x+1] {
+				hash |= 1 << (8*y + x)
+			}
+		}
+	}
+	return hash
+}
+
+// The pixels [start, end) of cell i of n cells along size pixels.
+func cellRange(i, n, size int) (int, int) {
+	start, end := i*size/n, (i+1)*size/n
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+// SetDHash stores the perceptual hash of the image in its metadata, which the camera then signs with it.
+func (img *I) SetDHash() {
+	hash := img.DHash()
+	img.M.DHash = &hash
+}
+
+// HashDistance returns the number of bits two perceptual hashes differ in, from 0 for images that look alike
+// to 64.
+func HashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// checkDHash returns an error if the image stores a perceptual hash that is not its own, e.g. one computed
+// before its pixels were edited.
+func (img I) checkDHash() error {
+	if img.M.DHash != nil && *img.M.DHash != img.DHash() {
+		return fmt.Errorf("invalid image: a perceptual hash of %016x in its metadata, expected %016x", *img.M.DHash, img.DHash())
+	}
+	return nil
+}
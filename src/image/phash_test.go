@@ -0,0 +1,74 @@
+package image
+
+import "testing"
+
+// A gradient, whose perceptual hash has every bit set.
+func gradientImage() I {
+	img := AllWhiteImage()
+	img.Map(func(x, _ int, _ RGBPixel) RGBPixel {
+		return RGBPixel{R: uint8(16 * x), G: uint8(16 * x), B: uint8(16 * x)}
+	})
+	return img
+}
+
+// Images that look alike have close perceptual hashes, images that do not are far apart, and a stored hash
+// follows a crop and is checked by Validate.
+func TestDHash(t *testing.T) {
+	img := gradientImage()
+	if hash := img.DHash(); hash != ^uint64(0) {
+		t.Fatalf("the perceptual hash of a gradient is %016x", hash)
+	}
+
+	// Brighter, with a few pixels changed, like a recompressed copy
+	copied := img
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			copied.Pixels[y][x].R += 8
+		}
+	}
+	copied.Pixels[5][5] = RGBPixel{}
+	if d := HashDistance(img.DHash(), copied.DHash()); d > 4 {
+		t.Errorf("a copy is %d bits away", d)
+	}
+	mirrored := img
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			mirrored.Pixels[y][x] = img.Pixels[y][Width-1-x]
+		}
+	}
+	if d := HashDistance(img.DHash(), mirrored.DHash()); d < 32 {
+		t.Errorf("a mirrored image is only %d bits away", d)
+	}
+
+	img.SetDHash()
+	if err := img.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	stale := img
+	stale.M = img.M.Copy()
+	stale.Pixels[0][0] = RGBPixel{R: 255, G: 255, B: 255}
+	if err := stale.Validate(); err == nil {
+		t.Errorf("validated an image with a stale perceptual hash")
+	}
+	cropped := img
+	cropped.M = img.M.Copy()
+	if err := cropped.Crop(4, 2, 9, 9); err != nil {
+		t.Fatal(err)
+	}
+	if *cropped.M.DHash != cropped.DHash() || *img.M.DHash == *cropped.M.DHash {
+		t.Errorf("the crop kept the perceptual hash of the image")
+	}
+
+	// The stored hash is signed with the image and reads back from its encoding
+	var decoded I
+	encoded, err := img.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.UnmarshalBinary(encoded); err != nil || decoded.M.DHash == nil || *decoded.M.DHash != *img.M.DHash {
+		t.Errorf("decoded the perceptual hash %v with %v", decoded.M.DHash, err)
+	}
+	if string(img.Digest()) == string(gradientImage().Digest()) {
+		t.Errorf("the perceptual hash is not part of the digest")
+	}
+}
@@ -0,0 +1,48 @@
+package image
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Preview writes the image, within the width and height of its metadata, to a terminal as ANSI 24-bit colored
+// blocks, for developers to see what a transformation did before and after proving it. Every character is
+// a block of scale x scale pixels, averaged, above another one, so that blocks are about square; a scale below
+// 1 is 1. An image without a valid size in its metadata is previewed whole.
+func (img I) Preview(w io.Writer, scale int) error {
+	scale = max(scale, 1)
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		width, height = Width, Height
+	}
+	columns, rows := (width+scale-1)/scale, (height+scale-1)/scale
+
+	out := bufio.NewWriter(w)
+	for row := 0; row < rows; row += 2 {
+		for column := 0; column < columns; column++ {
+			top := img.blockAverage(column*scale, row*scale, scale, width, height)
+			if row+1 < rows {
+				// The upper half block in the color of the top block, on the color of the bottom one
+				bottom := img.blockAverage(column*scale, (row+1)*scale, scale, width, height)
+				fmt.Fprintf(out, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			} else {
+				fmt.Fprintf(out, "\x1b[38;2;%d;%d;%dm\x1b[49m▀", top.R, top.G, top.B)
+			}
+		}
+		fmt.Fprint(out, "\x1b[0m\n")
+	}
+	return out.Flush()
+}
+
+// The average color of the block of size x size pixels at (x0, y0), within width x height.
+func (img I) blockAverage(x0, y0, size, width, height int) RGBPixel {
+	var r, g, b, n int
+	for y := y0; y < min(y0+size, height); y++ {
+		for x := x0; x < min(x0+size, width); x++ {
+			p := img.Pixels[y][x]
+			r, g, b, n = r+int(p.R), g+int(p.G), b+int(p.B), n+1
+		}
+	}
+	return RGBPixel{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n)}
+}
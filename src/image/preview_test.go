@@ -0,0 +1,35 @@
+package image
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	img := CheckerboardImage(1)
+	var preview bytes.Buffer
+	if err := img.Preview(&preview, 1); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(preview.String(), "\n"), "\n")
+	if len(lines) != Height/2 || strings.Count(lines[0], "▀") != Width {
+		t.Fatalf("a preview of %d lines of %d blocks, expected %d of %d", len(lines), strings.Count(lines[0], "▀"), Height/2, Width)
+	}
+	// White above black, then black above white
+	if !strings.HasPrefix(lines[0], "\x1b[38;2;255;255;255m\x1b[48;2;0;0;0m▀\x1b[38;2;0;0;0m\x1b[48;2;255;255;255m▀") {
+		t.Errorf("the first line of the preview is %q", lines[0])
+	}
+
+	// Downscaled by 2, every block of the checkerboard averages to gray; a cropped image is previewed within its size
+	if err := img.Crop(0, 0, 5, 2); err != nil {
+		t.Fatal(err)
+	}
+	preview.Reset()
+	if err := img.Preview(&preview, 2); err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Repeat("\x1b[38;2;127;127;127m\x1b[48;2;127;127;127m▀", 3) + "\x1b[0m\n"; preview.String() != want {
+		t.Errorf("previewed the cropped image as %q, expected %q", preview.String(), want)
+	}
+}
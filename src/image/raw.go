@@ -0,0 +1,64 @@
+package image
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Bit depth of the samples of a RAW capture.
+const RAWBits = 10
+
+// A RAW is a capture as the image sensor reads it out, before it is developed into an image I: one sample of
+// RAWBits per pixel, behind an RGGB Bayer color filter. The samples of every 2x2 block are, row by row, red,
+// green, green and blue.
+type RAW struct {
+	Samples [Height][Width]uint16 // Fixed-sized 2D array, indexed [y][x] like the pixels of an image.
+
+	M Metadata // Capture metadata.
+}
+
+// A RAW capture with frontend samples.
+type FrontendRAW struct {
+	Samples [Height][Width]frontend.Variable
+}
+
+// Given a secret key, a nonce and the hash of the previous proof, sign this capture, like I.Sign signs an
+// image.
+func (raw *RAW) Sign(secretKey signature.Signer, nonce *big.Int, prevProofHash *big.Int) []byte {
+	return SignDigest(secretKey, raw.Digest(), nonce, prevProofHash)
+}
+
+// Digest returns the message that is signed for this capture: the hash of its samples, row by row, packed as
+// 16 bit values, and its MetadataDigest, like I.Digest.
+func (raw RAW) Digest() []byte {
+	samples := make([]uint16, 0, Width*Height)
+	for y := 0; y < Height; y++ {
+		samples = append(samples, raw.Samples[y][:]...)
+	}
+	return channelsDigest(samples, 16, raw.MetadataDigest())
+}
+
+// CheckSamples returns an error if a sample of the capture does not fit in RAWBits.
+func (raw RAW) CheckSamples() error {
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			if raw.Samples[y][x] >= 1<<RAWBits {
+				return fmt.Errorf("invalid sample %d at (%d,%d): expected at most %d bits", raw.Samples[y][x], x, y, RAWBits)
+			}
+		}
+	}
+	return nil
+}
+
+func (raw RAW) ToFrontendRAW() FrontendRAW {
+	frontendRAW := FrontendRAW{}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			frontendRAW.Samples[y][x] = frontend.Variable(raw.Samples[y][x])
+		}
+	}
+	return frontendRAW
+}
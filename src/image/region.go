@@ -0,0 +1,75 @@
+package image
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Validate returns an error if the rectangle is empty or not within an image of the given size.
+func (rect Rect) Validate(width, height int) error {
+	if rect.X0 < 0 || rect.Y0 < 0 || rect.X1 >= width || rect.Y1 >= height || rect.X0 > rect.X1 || rect.Y0 > rect.Y1 {
+		return fmt.Errorf("invalid region %+v: out of bounds of a %d x %d image", rect, width, height)
+	}
+	return nil
+}
+
+// Contains reports whether the pixel (x, y) lies within the rectangle.
+func (rect Rect) Contains(x, y int) bool {
+	return rect.X0 <= x && x <= rect.X1 && rect.Y0 <= y && y <= rect.Y1
+}
+
+// Redact returns the image with every pixel of rect blackened, e.g. a face or a license plate, and every other
+// pixel kept. rect must lie within the image. It is a new image with a copy of the metadata, and its perceptual
+// hash updated if it has one.
+func (img I) Redact(rect Rect) (I, error) {
+	if err := rect.Validate(img.M.Width, img.M.Height); err != nil {
+		return I{}, err
+	}
+	redacted := img.Clone()
+	redacted.Map(func(x, y int, p RGBPixel) RGBPixel {
+		if rect.Contains(x, y) {
+			return RGBPixel{}
+		}
+		return p
+	})
+	if redacted.M.DHash != nil {
+		redacted.SetDHash()
+	}
+	return redacted, nil
+}
+
+// Sizes of the blocks of Pixelate, in pixels. Both divide the width and the height of the pixels of an image,
+// so the blocks tile them.
+var MosaicBlocks = [...]int{2, 4}
+
+// Pixelate returns the image with every block of a mosaic of block x block pixels that lies within rect replaced
+// by its average color, rounded down, e.g. to anonymize a face, and every other pixel kept. The blocks tile the
+// pixels of the image from its top left corner, so a block that rect only covers in part is kept. rect must lie
+// within the image, and block be one of MosaicBlocks. It is a new image with a copy of the metadata, and its
+// perceptual hash updated if it has one.
+func (img I) Pixelate(rect Rect, block int) (I, error) {
+	if err := rect.Validate(img.M.Width, img.M.Height); err != nil {
+		return I{}, err
+	}
+	if !slices.Contains(MosaicBlocks[:], block) {
+		return I{}, fmt.Errorf("invalid mosaic block %d: expected one of %v", block, MosaicBlocks)
+	}
+	pixelated := img.Clone()
+	for y0 := 0; y0 < Height; y0 += block {
+		for x0 := 0; x0 < Width; x0 += block {
+			if !rect.Contains(x0, y0) || !rect.Contains(x0+block-1, y0+block-1) {
+				continue
+			}
+			average := img.blockAverage(x0, y0, block, Width, Height)
+			for y := y0; y < y0+block; y++ {
+				for x := x0; x < x0+block; x++ {
+					pixelated.Pixels[y][x] = average
+				}
+			}
+		}
+	}
+	if pixelated.M.DHash != nil {
+		pixelated.SetDHash()
+	}
+	return pixelated, nil
+}
@@ -0,0 +1,87 @@
+package image
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 9, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	rect := Rect{X0: 2, Y0: 1, X1: 5, Y1: 3}
+	redacted, err := img.Redact(rect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			want := img.Pixels[y][x]
+			if rect.Contains(x, y) {
+				want = RGBPixel{}
+			}
+			if redacted.Pixels[y][x] != want {
+				t.Fatalf("pixel (%d, %d) is %v, expected %v", x, y, redacted.Pixels[y][x], want)
+			}
+		}
+	}
+	if *redacted.M.DHash != redacted.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+	if img.Pixels[2][3] == (RGBPixel{}) {
+		t.Errorf("the image itself was redacted")
+	}
+
+	// The rectangle lies within the image, not only within its pixel matrix
+	for _, rect := range []Rect{{X0: -1, Y0: 0, X1: 2, Y1: 2}, {X0: 0, Y0: 0, X1: 10, Y1: 2}, {X0: 0, Y0: 5, X1: 2, Y1: 7}, {X0: 3, Y0: 0, X1: 2, Y1: 2}} {
+		if _, err := img.Redact(rect); err == nil {
+			t.Errorf("region %+v was redacted", rect)
+		}
+	}
+}
+
+func TestPixelate(t *testing.T) {
+	img, err := GradientImage().SubImage(0, 0, 13, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetDHash()
+
+	// Blocks of 4 x 4 within x in [4, 11], and none within y in [1, 3], which only covers a block in part
+	rect := Rect{X0: 3, Y0: 1, X1: 12, Y1: 8}
+	pixelated, err := img.Pixelate(rect, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			want := img.Pixels[y][x]
+			if x >= 4 && x <= 11 && y >= 4 && y <= 7 {
+				var r, g, b int
+				for _, row := range img.Pixels[y/4*4 : y/4*4+4] {
+					for _, p := range row[x/4*4 : x/4*4+4] {
+						r, g, b = r+int(p.R), g+int(p.G), b+int(p.B)
+					}
+				}
+				want = RGBPixel{R: uint8(r / 16), G: uint8(g / 16), B: uint8(b / 16)}
+			}
+			if pixelated.Pixels[y][x] != want {
+				t.Fatalf("pixel (%d, %d) is %v, expected %v", x, y, pixelated.Pixels[y][x], want)
+			}
+		}
+	}
+	if *pixelated.M.DHash != pixelated.DHash() {
+		t.Errorf("the perceptual hash is stale")
+	}
+
+	// A region smaller than a block keeps the image
+	if kept, _ := img.Pixelate(Rect{X0: 1, Y0: 1, X1: 2, Y1: 2}, 2); kept.Pixels != img.Pixels {
+		t.Errorf("a region within no block changed the image")
+	}
+	if _, err := img.Pixelate(rect, 3); err == nil {
+		t.Errorf("the image was pixelated with blocks of 3")
+	}
+	if _, err := img.Pixelate(Rect{X0: 0, Y0: 0, X1: 14, Y1: 3}, 2); err == nil {
+		t.Errorf("a region outside of the image was pixelated")
+	}
+}
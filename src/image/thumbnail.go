@@ -0,0 +1,51 @@
+package image
+
+import "fmt"
+
+// Thumbnail returns the image, within the width and height of its metadata, reduced so that neither side is
+// larger than maxDim pixels, with a copy of its metadata updated to the reduced size. Every pixel of the
+// thumbnail is the average, rounded down, of a block of k x k pixels of the image, for the smallest whole
+// factor k that fits, the blocks of the right and bottom edges averaging the pixels they hold: the output a
+// downscale by k is expected to prove. A maxDim below 1 is 1; an image that already fits is returned as it is.
+func (img I) Thumbnail(maxDim int) I {
+	maxDim = max(maxDim, 1)
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		width, height = Width, Height
+	}
+	k := (max(width, height) + maxDim - 1) / maxDim
+
+	thumbnail := I{M: img.M.Copy()}
+	thumbnail.M.Width, thumbnail.M.Height = (width+k-1)/k, (height+k-1)/k
+	for y := 0; y < thumbnail.M.Height; y++ {
+		for x := 0; x < thumbnail.M.Width; x++ {
+			thumbnail.Pixels[y][x] = img.blockAverage(k*x, k*y, k, width, height)
+		}
+	}
+	if thumbnail.M.DHash != nil {
+		thumbnail.SetDHash()
+	}
+	return thumbnail
+}
+
+// Downscale returns the image, within the width and height of its metadata, halved as a new image: every pixel
+// is the average, rounded down, of a block of 2 x 2 pixels, like a Thumbnail reduced by a factor of 2, and the
+// metadata is a copy updated to the halved size, rounded up, and its perceptual hash if it has one.
+func (img I) Downscale() (I, error) {
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 || width > Width || height > Height {
+		return I{}, fmt.Errorf("invalid image metadata for width and height")
+	}
+
+	downscaled := I{M: img.M.Copy()}
+	downscaled.M.Width, downscaled.M.Height = (width+1)/2, (height+1)/2
+	for y := 0; y < downscaled.M.Height; y++ {
+		for x := 0; x < downscaled.M.Width; x++ {
+			downscaled.Pixels[y][x] = img.blockAverage(2*x, 2*y, 2, width, height)
+		}
+	}
+	if downscaled.M.DHash != nil {
+		downscaled.SetDHash()
+	}
+	return downscaled, nil
+}
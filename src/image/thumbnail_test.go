@@ -0,0 +1,71 @@
+package image
+
+import "testing"
+
+func TestThumbnail(t *testing.T) {
+	img := CoordinateImage()
+	img.SetDHash()
+
+	// 16 x 12 into at most 5 pixels: blocks of 4 x 4
+	thumbnail := img.Thumbnail(5)
+	if thumbnail.M.Width != 4 || thumbnail.M.Height != 3 || thumbnail.M.Author != img.M.Author {
+		t.Fatalf("a %d x %d thumbnail with the metadata %+v", thumbnail.M.Width, thumbnail.M.Height, thumbnail.M)
+	}
+	// The block of (4..7, 0..3): R averages 4..7, G 0..3, B x*12+y
+	if p := thumbnail.Pixels[0][1]; p != (RGBPixel{R: 5, G: 1, B: 67}) {
+		t.Errorf("thumbnail pixel (1, 0) is %v", p)
+	}
+	if err := thumbnail.Validate(); err != nil {
+		t.Errorf("the thumbnail is invalid: %v", err)
+	}
+	if img.M.Width != Width {
+		t.Errorf("Thumbnail changed the image")
+	}
+
+	// 5 x 3 into at most 2 pixels: blocks of 3 x 3, the one of the right edge holding 2 x 3 pixels
+	cropped, err := img.SubImage(0, 0, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edge := cropped.Thumbnail(2)
+	if edge.M.Width != 2 || edge.M.Height != 1 || edge.Pixels[0][1] != (RGBPixel{R: 3, G: 1, B: 43}) {
+		t.Errorf("a %d x %d thumbnail with the edge pixel %v", edge.M.Width, edge.M.Height, edge.Pixels[0][1])
+	}
+
+	if fits := img.Thumbnail(Width); fits.Pixels != img.Pixels || fits.M.Width != Width || fits.M.Height != Height {
+		t.Errorf("a thumbnail of an image that fits is another image")
+	}
+}
+
+func TestDownscale(t *testing.T) {
+	img := CoordinateImage()
+	img.SetDHash()
+
+	// Halving is a thumbnail by blocks of 2 x 2, for an even size and an odd one, whose edges hold fewer pixels
+	cropped, err := img.SubImage(3, 2, 7, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		img           I
+		maxDim        int
+		width, height int
+	}{{img, Width / 2, Width / 2, Height / 2}, {cropped, 3, 3, 2}} {
+		downscaled, err := tc.img.Downscale()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if downscaled.M.Width != tc.width || downscaled.M.Height != tc.height || downscaled.Pixels != tc.img.Thumbnail(tc.maxDim).Pixels {
+			t.Errorf("a %d x %d image downscaled to %d x %d pixels unlike its thumbnail", tc.img.M.Width, tc.img.M.Height, downscaled.M.Width, downscaled.M.Height)
+		}
+		if err := downscaled.Validate(); err != nil {
+			t.Errorf("the downscaled image is invalid: %v", err)
+		}
+	}
+	if downscaled, _ := img.Downscale(); *downscaled.M.DHash != downscaled.DHash() || img.M.Width != Width {
+		t.Errorf("the perceptual hash is stale, or Downscale changed the image")
+	}
+	if _, err := NewImage().Downscale(); err == nil {
+		t.Errorf("an image without a size was downscaled")
+	}
+}
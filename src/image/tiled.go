@@ -0,0 +1,191 @@
+package image
+
+import (
+	"bytes"
+	"fmt"
+	stdimage "image"
+	"image/color"
+	"math/big"
+)
+
+/*
+A Tiled is a photo larger than an image I, e.g. of several megapixels: a grid of tiles, each an image I of
+Width x Height pixels, the tiles of the right and bottom edges recording their smaller size in their
+metadata like a cropped image. The compliance predicates never see the whole photo, only the tiles a proof
+is about, so the witness of a proof stays the size of an image however large the photo.
+
+Every tile is committed to by its Digest, the message a camera signs for an image, and the photo by its
+Commitment: the hash of its size and of the root of a Merkle tree of the digests of its tiles, row by row,
+the tree padded to a power of two leaves with zeros and every node the hashsuite.Default hash of its two
+children. A tile is opened with its Merkle path (Open), which a verifier holding only the commitment checks
+(VerifyTile): it learns that the tile is part of the photo, and nothing about the other tiles.
+*/
+
+// Largest side of a Tiled, in pixels.
+const MaxTiledSize = 1 << 15
+
+// A Tiled is a photo of any size up to MaxTiledSize a side, as a grid of images.
+type Tiled struct {
+	Width, Height int   // Size of the photo, in pixels.
+	Tiles         [][]I // Tiles, indexed [row][column], every one Width x Height pixels of the photo.
+}
+
+// NewTiled returns a black photo of width x height pixels.
+func NewTiled(width, height int) (Tiled, error) {
+	if width < 1 || height < 1 || width > MaxTiledSize || height > MaxTiledSize {
+		return Tiled{}, fmt.Errorf("invalid photo size %d x %d: expected at most %d x %d", width, height, MaxTiledSize, MaxTiledSize)
+	}
+	columns, rows := (width+Width-1)/Width, (height+Height-1)/Height
+	tiled := Tiled{Width: width, Height: height, Tiles: make([][]I, rows)}
+	for row := range tiled.Tiles {
+		tiled.Tiles[row] = make([]I, columns)
+		for column := range tiled.Tiles[row] {
+			tile := NewImage()
+			tile.M.Width = min(Width, width-column*Width)
+			tile.M.Height = min(Height, height-row*Height)
+			tiled.Tiles[row][column] = tile
+		}
+	}
+	return tiled, nil
+}
+
+// TiledFromGoImage converts an image of the standard library of any size up to MaxTiledSize a side into a
+// photo, like FromGoImage converts a small one into an image.
+func TiledFromGoImage(src stdimage.Image) (Tiled, error) {
+	bounds := src.Bounds()
+	tiled, err := NewTiled(bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return Tiled{}, err
+	}
+	for y := 0; y < tiled.Height; y++ {
+		for x := 0; x < tiled.Width; x++ {
+			c := color.RGBAModel.Convert(src.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+			tiled.SetPixel(x, y, RGBPixel{R: c.R, G: c.G, B: c.B})
+		}
+	}
+	return tiled, nil
+}
+
+// Columns returns the number of tiles of a row of the photo.
+func (tiled Tiled) Columns() int {
+	return (tiled.Width + Width - 1) / Width
+}
+
+// Rows returns the number of tiles of a column of the photo.
+func (tiled Tiled) Rows() int {
+	return (tiled.Height + Height - 1) / Height
+}
+
+// SetPixel sets the pixel (x, y) of the photo, in the tile that holds it. Pixels outside the photo are ignored.
+func (tiled *Tiled) SetPixel(x, y int, color RGBPixel) {
+	if x >= 0 && x < tiled.Width && y >= 0 && y < tiled.Height {
+		tiled.Tiles[y/Height][x/Width].Pixels[y%Height][x%Width] = color
+	}
+}
+
+// GetPixel returns the pixel (x, y) of the photo, or a black pixel outside it.
+func (tiled Tiled) GetPixel(x, y int) RGBPixel {
+	if x >= 0 && x < tiled.Width && y >= 0 && y < tiled.Height {
+		return tiled.Tiles[y/Height][x/Width].Pixels[y%Height][x%Width]
+	}
+	return RGBPixel{}
+}
+
+// Commitment returns the commitment of the photo, see Tiled, as a big endian field element.
+func (tiled Tiled) Commitment() []byte {
+	return tiledCommitment(tiled.Width, tiled.Height, merkleRoot(tiled.leaves()))
+}
+
+// A TileOpening opens one tile of a photo against the photo's Commitment.
+type TileOpening struct {
+	Width, Height int      // Size of the photo, in pixels.
+	Column, Row   int      // Position of the tile in the grid.
+	Tile          I        // The tile.
+	Path          [][]byte // Digests of the siblings of the tile's leaf and of its ancestors, leaf first.
+}
+
+// Open returns the opening of the tile at column, row.
+func (tiled Tiled) Open(column, row int) (TileOpening, error) {
+	if column < 0 || column >= tiled.Columns() || row < 0 || row >= tiled.Rows() {
+		return TileOpening{}, fmt.Errorf("invalid tile (%d, %d) of a grid of %d x %d tiles", column, row, tiled.Columns(), tiled.Rows())
+	}
+	opening := TileOpening{Width: tiled.Width, Height: tiled.Height, Column: column, Row: row, Tile: tiled.Tiles[row][column]}
+	level := tiled.leaves()
+	for index := row*tiled.Columns() + column; len(level) > 1; index /= 2 {
+		opening.Path = append(opening.Path, level[index^1])
+		level = merkleLevel(level)
+	}
+	return opening, nil
+}
+
+// VerifyTile reports whether an opening opens a tile of the photo of commitment, as returned by Commitment.
+func VerifyTile(commitment []byte, opening TileOpening) bool {
+	tiled := Tiled{Width: opening.Width, Height: opening.Height}
+	if tiled.Width < 1 || tiled.Height < 1 || tiled.Width > MaxTiledSize || tiled.Height > MaxTiledSize ||
+		opening.Column < 0 || opening.Column >= tiled.Columns() || opening.Row < 0 || opening.Row >= tiled.Rows() {
+		return false
+	}
+	index := opening.Row*tiled.Columns() + opening.Column
+	if len(opening.Path) != treeDepth(tiled.Columns()*tiled.Rows()) {
+		return false
+	}
+	node := opening.Tile.Digest()
+	if len(node) == 0 {
+		return false
+	}
+	for _, sibling := range opening.Path {
+		if index%2 == 0 {
+			node = hashElements(new(big.Int).SetBytes(node), new(big.Int).SetBytes(sibling))
+		} else {
+			node = hashElements(new(big.Int).SetBytes(sibling), new(big.Int).SetBytes(node))
+		}
+		index /= 2
+	}
+	return bytes.Equal(tiledCommitment(tiled.Width, tiled.Height, node), commitment)
+}
+
+// The digests of the tiles, row by row, padded with zeros to a power of two.
+func (tiled Tiled) leaves() [][]byte {
+	n := tiled.Columns() * tiled.Rows()
+	leaves := make([][]byte, 0, 1<<treeDepth(n))
+	for _, row := range tiled.Tiles {
+		for _, tile := range row {
+			leaves = append(leaves, tile.Digest())
+		}
+	}
+	for len(leaves) < 1<<treeDepth(n) {
+		leaves = append(leaves, make([]byte, 32))
+	}
+	return leaves
+}
+
+// The depth of a Merkle tree of n leaves.
+func treeDepth(n int) int {
+	depth := 0
+	for 1<<depth < n {
+		depth++
+	}
+	return depth
+}
+
+// The parents of a level of a Merkle tree.
+func merkleLevel(level [][]byte) [][]byte {
+	parents := make([][]byte, len(level)/2)
+	for i := range parents {
+		parents[i] = hashElements(new(big.Int).SetBytes(level[2*i]), new(big.Int).SetBytes(level[2*i+1]))
+	}
+	return parents
+}
+
+// The root of a Merkle tree of a power of two leaves.
+func merkleRoot(leaves [][]byte) []byte {
+	for len(leaves) > 1 {
+		leaves = merkleLevel(leaves)
+	}
+	return leaves[0]
+}
+
+// The commitment of a photo of width x height pixels whose tiles have the Merkle root root.
+func tiledCommitment(width, height int, root []byte) []byte {
+	return hashElements(big.NewInt(int64(width)), big.NewInt(int64(height)), new(big.Int).SetBytes(root))
+}
@@ -0,0 +1,67 @@
+package image
+
+import (
+	"bytes"
+	stdimage "image"
+	"image/color"
+	"testing"
+)
+
+// A photo of 3 x 3 tiles, whose every tile opens against its commitment, and no tile opens anywhere else.
+func TestTiled(t *testing.T) {
+	src := stdimage.NewRGBA(stdimage.Rect(0, 0, 2*Width+5, 2*Height+1))
+	for y := 0; y < src.Bounds().Dy(); y++ {
+		for x := 0; x < src.Bounds().Dx(); x++ {
+			src.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 255})
+		}
+	}
+	tiled, err := TiledFromGoImage(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tiled.Columns() != 3 || tiled.Rows() != 3 {
+		t.Fatalf("a grid of %d x %d tiles, expected 3 x 3", tiled.Columns(), tiled.Rows())
+	}
+	if p := tiled.GetPixel(2*Width+4, 2*Height); p != (RGBPixel{R: uint8(2*Width + 4), G: uint8(2 * Height), B: uint8((2*Width + 4) ^ (2 * Height))}) {
+		t.Errorf("the last pixel of the photo is %v", p)
+	}
+	corner := tiled.Tiles[2][2]
+	if corner.M.Width != 5 || corner.M.Height != 1 || corner.Validate() != nil {
+		t.Errorf("the corner tile is %d x %d", corner.M.Width, corner.M.Height)
+	}
+
+	commitment := tiled.Commitment()
+	for row := 0; row < tiled.Rows(); row++ {
+		for column := 0; column < tiled.Columns(); column++ {
+			opening, err := tiled.Open(column, row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyTile(commitment, opening) {
+				t.Errorf("tile (%d, %d) does not open", column, row)
+			}
+		}
+	}
+
+	opening, _ := tiled.Open(1, 2)
+	tampered, moved, resized := opening, opening, opening
+	tampered.Tile.Pixels[0][0].R++
+	moved.Column = 0
+	resized.Width++
+	for name, invalid := range map[string]TileOpening{"tampered": tampered, "moved": moved, "resized": resized} {
+		if VerifyTile(commitment, invalid) {
+			t.Errorf("a %s tile opens", name)
+		}
+	}
+	if _, err := tiled.Open(3, 0); err == nil {
+		t.Errorf("opened a tile outside the grid")
+	}
+
+	tiled.SetPixel(0, 0, RGBPixel{R: 1})
+	if bytes.Equal(tiled.Commitment(), commitment) {
+		t.Errorf("the commitment does not follow the pixels")
+	}
+	if _, err := NewTiled(MaxTiledSize+1, 1); err == nil {
+		t.Errorf("created a photo larger than MaxTiledSize")
+	}
+}
@@ -0,0 +1,34 @@
+package image
+
+import "fmt"
+
+// Validate returns an error if the image is not one the compliance predicates can prove: its metadata must
+// have a canonical encoding (see Metadata.Check) and a size of at least 1 x 1, its pixels outside that size,
+// which a crop blackens, must be black, and a perceptual hash it stores must be its own. Channels are 8 bit
+// by their type, so every pixel is in range.
+//
+// Validation is cheap next to compiling a circuit and proving it, so the Generator and the Prover run it
+// first and fail with its error rather than with an unsatisfied constraint.
+func (img I) Validate() error {
+	if err := img.M.Check(); err != nil {
+		return err
+	}
+	width, height := img.M.Width, img.M.Height
+	if width < 1 || height < 1 {
+		return fmt.Errorf("invalid image: a size of %d x %d in its metadata", width, height)
+	}
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			if (x >= width || y >= height) && img.Pixels[y][x] != (RGBPixel{}) {
+				return fmt.Errorf("invalid image: pixel (%d,%d) is outside its %d x %d size but not black", x, y, width, height)
+			}
+		}
+	}
+	if err := img.checkDHash(); err != nil {
+		return err
+	}
+	if _, err := img.MarshalBinary(); err != nil {
+		return err
+	}
+	return nil
+}
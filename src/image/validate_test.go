@@ -0,0 +1,28 @@
+package image
+
+import "testing"
+
+// A captured or cropped image is valid; one whose metadata or pixels disagree with its size is not.
+func TestValidate(t *testing.T) {
+	img := AllWhiteImage()
+	cropped := AllWhiteImage()
+	if err := cropped.Crop(2, 3, 10, 7); err != nil {
+		t.Fatal(err)
+	}
+	for name, valid := range map[string]I{"captured": img, "cropped": cropped} {
+		if err := valid.Validate(); err != nil {
+			t.Errorf("the %s image is invalid: %v", name, err)
+		}
+	}
+
+	unsized, oversized, outside, offGlobe := img, img, cropped, img
+	unsized.M.Width = 0
+	oversized.M.Height = Height + 1
+	outside.SetPixel(9, 0, RGBPixel{R: 1})
+	offGlobe.M.GPS = &GPS{Longitude: 181}
+	for name, invalid := range map[string]I{"unsized": unsized, "oversized": oversized, "outside": outside, "off the globe": offGlobe} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("the %s image is valid", name)
+		}
+	}
+}
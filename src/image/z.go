@@ -0,0 +1,133 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+/*
+The binary encoding of a Z, so that it can be stored next to a proof and read back by a verifier on another
+machine, is, big endian:
+
+	magic       "PGZ"
+	version     1 byte, ZFormatVersion
+	public key  its length as uint32, 0 if there is none, then its bytes, as signature.PublicKey.Bytes returns
+	hops        uint32, the Hops of the Z, from version 2 on
+	image       the canonical encoding of the image, see I.MarshalBinary
+
+The public key is a compressed EdDSA key on the twisted Edwards curve of BN254, the keys of the Generator.
+*/
+
+// Version of the encodings of a Z written by MarshalBinary and MarshalJSON.
+//
+//	1: the image and the public key
+//	2: records the hops of the image
+const ZFormatVersion = 2
+
+var zMagic = []byte("PGZ")
+
+// MarshalBinary returns the binary encoding of z.
+func (z Z) MarshalBinary() ([]byte, error) {
+	img, err := z.Image.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := append(append([]byte{}, zMagic...), ZFormatVersion)
+	var publicKey []byte
+	if z.PublicKey != nil {
+		publicKey = z.PublicKey.Bytes()
+	}
+	data = appendString(data, string(publicKey))
+	data = binary.BigEndian.AppendUint32(data, uint32(z.Hops))
+	return append(data, img...), nil
+}
+
+// UnmarshalBinary decodes the binary encoding of a Z, as returned by MarshalBinary.
+func (z *Z) UnmarshalBinary(data []byte) error {
+	r := &canonicalReader{data: data}
+	header := r.next(len(zMagic) + 1)
+	if header == nil || !bytes.HasPrefix(header, zMagic) {
+		return fmt.Errorf("invalid Z encoding: no header")
+	}
+	version := int(header[len(zMagic)])
+	if version < 1 || version > ZFormatVersion {
+		return fmt.Errorf("Z encoding version %d is not supported by this build (%d): upgrade PhotoGnark to read it", version, ZFormatVersion)
+	}
+	publicKey, err := r.readString()
+	if err != nil {
+		return err
+	}
+	var decoded Z
+	if decoded.PublicKey, err = publicKeyFromBytes([]byte(publicKey)); err != nil {
+		return err
+	}
+	// A Z of version 1 is one of an original image
+	if version >= 2 {
+		hops, err := r.readUint32()
+		if err != nil {
+			return err
+		}
+		decoded.Hops = int(hops)
+	}
+	if err := decoded.Image.UnmarshalBinary(r.data); err != nil {
+		return err
+	}
+	*z = decoded
+	return nil
+}
+
+// JSON encoding of a Z.
+type zJSON struct {
+	Version   int    `json:"version"`
+	Image     I      `json:"image"`
+	PublicKey []byte `json:"publicKey,omitempty"`
+	Hops      int    `json:"hops,omitempty"`
+}
+
+func (z Z) MarshalJSON() ([]byte, error) {
+	encoded := zJSON{Version: ZFormatVersion, Image: z.Image, Hops: z.Hops}
+	if z.PublicKey != nil {
+		encoded.PublicKey = z.PublicKey.Bytes()
+	}
+	return json.Marshal(encoded)
+}
+
+func (z *Z) UnmarshalJSON(data []byte) error {
+	var decoded zJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version < 1 || decoded.Version > ZFormatVersion {
+		return fmt.Errorf("Z encoding version %d is not supported by this build (%d): upgrade PhotoGnark to read it", decoded.Version, ZFormatVersion)
+	}
+	if decoded.Hops < 0 {
+		return fmt.Errorf("invalid Z encoding: %d hops", decoded.Hops)
+	}
+	publicKey, err := publicKeyFromBytes(decoded.PublicKey)
+	if err != nil {
+		return err
+	}
+	*z = Z{Image: decoded.Image, PublicKey: publicKey, Hops: decoded.Hops}
+	return nil
+}
+
+// Decode a public key of the Generator, as returned by signature.PublicKey.Bytes(), or no key if data is empty.
+func publicKeyFromBytes(data []byte) (signature.PublicKey, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var publicKey eddsa.PublicKey
+	n, err := publicKey.SetBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if n != len(data) {
+		return nil, fmt.Errorf("invalid public key: %d bytes past the key", len(data)-n)
+	}
+	return &publicKey, nil
+}
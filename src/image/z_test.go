@@ -0,0 +1,76 @@
+package image
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+)
+
+// A Z reads back from its binary and JSON encodings, with or without a public key, and an encoding of another
+// version or with an invalid key is rejected. A Z of version 1 has no hops.
+func TestZEncoding(t *testing.T) {
+	secretKey, err := ceddsa.New(1, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := AllWhiteImage()
+	img.SetPixel(1, 2, RGBPixel{R: 3})
+	img.SetDHash()
+
+	for name, z := range map[string]Z{"signed": {Image: img, PublicKey: secretKey.Public(), Hops: 3}, "unsigned": {Image: img}} {
+		data, err := z.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := json.Marshal(z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromBinary, fromJSON Z
+		if err := fromBinary.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := json.Unmarshal(encoded, &fromJSON); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for encoding, decoded := range map[string]Z{"binary": fromBinary, "JSON": fromJSON} {
+			if !bytes.Equal(decoded.Image.Digest(), z.Image.Digest()) || (decoded.PublicKey == nil) != (z.PublicKey == nil) ||
+				(z.PublicKey != nil && !decoded.PublicKey.Equal(z.PublicKey)) || decoded.Hops != z.Hops {
+				t.Errorf("%s: the %s encoding read back another Z", name, encoding)
+			}
+		}
+	}
+
+	z := Z{Image: img, PublicKey: secretKey.Public()}
+	data, _ := z.MarshalBinary()
+	outdated := append([]byte{}, data...)
+	outdated[len(zMagic)] = ZFormatVersion + 1
+	badKey := append([]byte{}, data...)
+	badKey[len(zMagic)+1+3]-- // a key a byte short
+	for name, invalid := range map[string][]byte{
+		"truncated":           data[:len(data)-1],
+		"of another version":  outdated,
+		"with an invalid key": badKey,
+		"image":               data[len(zMagic)+1+4+len(z.PublicKey.Bytes())+4:],
+	} {
+		var decoded Z
+		if err := decoded.UnmarshalBinary(invalid); err == nil {
+			t.Errorf("decoded a Z %s", name)
+		}
+	}
+
+	// Version 1 has no hops between the public key and the image
+	keyEnd := len(zMagic) + 1 + 4 + len(z.PublicKey.Bytes())
+	v1 := append(append([]byte{}, data[:keyEnd]...), data[keyEnd+4:]...)
+	v1[len(zMagic)] = 1
+	var decoded Z
+	if err := decoded.UnmarshalBinary(v1); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hops != 0 || !bytes.Equal(decoded.Image.Digest(), z.Image.Digest()) {
+		t.Error("a Z of version 1 read back another Z")
+	}
+}
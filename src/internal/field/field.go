@@ -0,0 +1,80 @@
+// Package field converts byte strings into BN254 field elements, the form in which
+// messages are signed and fed into the compliance predicates.
+package field
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Interprets data as the bytes of a big-endian unsigned integer,
+// sets z to that value, and return z value as a big endian slice.
+// If this step is skipped, you get this error:
+// "runtime error: slice bounds out of range"
+// This step is required to define an image into something that Gnark circuits understand.
+func BigEndian(data []byte) []byte {
+	var msgFr fr.Element // Define a field element
+
+	// (https://pkg.go.dev/github.com/consensys/gnark-crypto@v0.9.1/ecc/bn254/fr#Element.SetBytes)
+	msgFr.SetBytes(data)   // Set the bytes as the z value for the fr.Element
+	return msgFr.Marshal() // Convert z value to a big endian slice
+}
+
+// Number of bits of values packed into one field element by Pack, which holds 253 bits without overflowing the
+// BN254 scalar field: 31 values of 8 bits, or 15 of 16 bits.
+const PackedBits = 253
+
+// Pack packs values of bits each little endian into field elements, PackedBits/bits to an element, the first
+// value in the lowest bits. Packing is only unambiguous for values that fit in bits.
+func Pack[T uint8 | uint16](values []T, bits int) []*big.Int {
+	perElement := PackedBits / bits
+	var packed []*big.Int
+	for start := 0; start < len(values); start += perElement {
+		element := new(big.Int)
+		for i := min(start+perElement, len(values)) - 1; i >= start; i-- {
+			element.Lsh(element, uint(bits))
+			element.Add(element, big.NewInt(int64(values[i])))
+		}
+		packed = append(packed, element)
+	}
+	return packed
+}
+
+// Chunks splits data into field elements of 31 big endian bytes, the last one padded with zeros, after an
+// element holding the length of data, so that no two byte strings give the same elements.
+func Chunks(data []byte) []*big.Int {
+	chunks := []*big.Int{big.NewInt(int64(len(data)))}
+	for start := 0; start < len(data); start += 31 {
+		var chunk [31]byte
+		copy(chunk[:], data[start:])
+		chunks = append(chunks, new(big.Int).SetBytes(chunk[:]))
+	}
+	return chunks
+}
+
+// Unpack unpacks n values of bits each from elements, as packed by Pack. It returns an error if elements are not
+// the packing of n values: too few or too many elements, or bits set above the last value of an element.
+func Unpack[T uint8 | uint16](elements []*big.Int, bits, n int) ([]T, error) {
+	perElement := PackedBits / bits
+	if want := (n + perElement - 1) / perElement; len(elements) != want {
+		return nil, fmt.Errorf("%d packed elements for %d values of %d bits, expected %d", len(elements), n, bits, want)
+	}
+	mask := big.NewInt(1<<bits - 1)
+	values := make([]T, 0, n)
+	for i, element := range elements {
+		if element.Sign() < 0 {
+			return nil, fmt.Errorf("packed element %d is negative", i)
+		}
+		rest := new(big.Int).Set(element)
+		for j := 0; j < perElement && len(values) < n; j++ {
+			values = append(values, T(new(big.Int).And(rest, mask).Uint64()))
+			rest.Rsh(rest, uint(bits))
+		}
+		if rest.Sign() != 0 {
+			return nil, fmt.Errorf("packed element %d has bits set above its values", i)
+		}
+	}
+	return values, nil
+}
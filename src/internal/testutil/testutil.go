@@ -0,0 +1,43 @@
+// Package testutil holds the helpers shared by the tests of several packages, e.g. the golden proofs of the
+// transformations and of the edits.
+package testutil
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/consensys/gnark/constraint"
+)
+
+// ConstraintsDigest returns the SHA-256 of the wires, constraints and coefficients of an R1CS. Unlike its WriteTo
+// encoding, it leaves out the debug information recording the source lines the constraints were added at, so it
+// changes with the constraints only, not when the code around them moves.
+func ConstraintsDigest(t testing.TB, ccs constraint.ConstraintSystem) []byte {
+	t.Helper()
+	r1cs, ok := ccs.(constraint.R1CS)
+	if !ok {
+		t.Fatalf("%T is not an R1CS", ccs)
+	}
+
+	h := sha256.New()
+	write := func(values ...uint64) {
+		for _, v := range values {
+			binary.Write(h, binary.BigEndian, v)
+		}
+	}
+	write(uint64(r1cs.GetNbPublicVariables()), uint64(r1cs.GetNbSecretVariables()), uint64(r1cs.GetNbInternalVariables()))
+	for _, r1c := range r1cs.GetR1Cs() {
+		for _, l := range []constraint.LinearExpression{r1c.L, r1c.R, r1c.O} {
+			write(uint64(len(l)))
+			for _, term := range l {
+				write(uint64(term.CID), uint64(term.VID))
+			}
+		}
+	}
+	for i := 0; i < r1cs.GetNbCoefficients(); i++ {
+		coefficient := r1cs.GetCoefficient(i)
+		write(coefficient[:]...)
+	}
+	return h.Sum(nil)
+}
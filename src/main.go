@@ -1,6 +1,7 @@
 package main
 
 import (
+	"src/backend"
 	"src/camera"
 	"src/editor"
 	"src/verifier"
@@ -24,14 +25,14 @@ func main() {
 	verifier.Verifier(vk_pp, proof)
 
 	//
-	// proof.Z.Image.Crop(0, 0, myImage.N-1, myImage.N-1)
+	// proof.Z.Image.Crop(0, 0, myImage.Width-1, myImage.Height-1)
 	// proof.Z.Image.PrintImage()
 
 	// cropParams := make(map[frontend.Variable]interface{})
 	// cropParams["x0"] = 0
 	// cropParams["y0"] = 0
-	// cropParams["x1"] = myImage.N - 1
-	// cropParams["y1"] = myImage.N - 1
+	// cropParams["x1"] = myImage.Width - 1
+	// cropParams["y1"] = myImage.Height - 1
 
 	noCropParams := make(map[string]int)
 	noCropParams["x0"] = 3
@@ -39,7 +40,7 @@ func main() {
 	noCropParams["x1"] = 6
 	noCropParams["y1"] = 6
 
-	editor.EditorCrop(pk_pp, vk_pp.VerifyingKey, proof, noCropParams)
+	editor.EditorCrop(pk_pp, vk_pp.VerifyingKey, proof, noCropParams, backend.WithEditorKey(secureCamera.CameraEditorKey()))
 	// if proof.PCD_proof == nil {
 	// 	// Encode image.
 	// 	msg := z.Image.ToByte() // []byte{0xde, 0xad, 0xf0, 0x0d, 0x0d}
@@ -0,0 +1,95 @@
+// Package profiling breaks the constraints of the compliance predicates down by gadget, using gnark's
+// circuit profiler, so that optimization work targets the gadgets that actually cost constraints.
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark/profile"
+	pprof "github.com/google/pprof/profile"
+)
+
+// Gadgets the constraints are charged to.
+const (
+	Signature   = "signature"    // EdDSA signature verification, including its hashing
+	Hash        = "hash"         // Hashing outside of signatures, e.g. public digests
+	RangeChecks = "range checks" // Range checks and lookup arguments
+	Comparisons = "comparisons"  // Bounded comparisons of pixel locations
+	Pixels      = "pixels"       // Pixel constraints of the compliance predicates
+	Other       = "other"
+)
+
+// A constraint is charged to the first gadget whose packages appear in its call stack, e.g. the MiMC
+// constraints of a signature verification are charged to Signature rather than Hash.
+// Function names in gnark profiles are prefixed with the last element of their package path.
+var gadgets = []struct {
+	name     string
+	packages []string
+}{
+	{Signature, []string{"eddsa.", "twistededwards."}},
+	{RangeChecks, []string{"rangecheck.", "logderivlookup.", "logderivarg."}},
+	{Comparisons, []string{"cmp."}},
+	{Hash, []string{"mimc.", "hashsuite."}},
+	{Pixels, []string{"transformations."}},
+}
+
+// A Report counts the constraints of the circuits compiled during a Session.
+type Report struct {
+	Constraints int            `json:"constraints"`
+	Gadgets     map[string]int `json:"gadgets"` // Constraints per gadget
+	Profile     string         `json:"profile"` // pprof file, to inspect with go tool pprof
+}
+
+// A Session profiles every circuit compiled between Start and Stop.
+// Like gnark's profiler, it is not safe for concurrent use.
+type Session struct {
+	path    string
+	profile *profile.Profile
+}
+
+// Start starts a Session, which writes its pprof profile to path.
+func Start(path string) *Session {
+	return &Session{path: path, profile: profile.Start(profile.WithPath(path))}
+}
+
+// Stop stops the Session and returns its Report.
+func (session *Session) Stop() (Report, error) {
+	session.profile.Stop()
+
+	file, err := os.Open(session.path)
+	if err != nil {
+		return Report{}, err
+	}
+	defer file.Close()
+
+	profiled, err := pprof.Parse(file)
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", session.path, err)
+	}
+
+	report := Report{Gadgets: map[string]int{}, Profile: session.path}
+	for _, sample := range profiled.Sample {
+		constraints := int(sample.Value[0])
+		report.Constraints += constraints
+		report.Gadgets[gadgetOf(sample)] += constraints
+	}
+	return report, nil
+}
+
+// gadgetOf returns the gadget a sample of the profile is charged to.
+func gadgetOf(sample *pprof.Sample) string {
+	for _, gadget := range gadgets {
+		for _, location := range sample.Location {
+			for _, line := range location.Line {
+				for _, prefix := range gadget.packages {
+					if strings.HasPrefix(line.Function.Name, prefix) {
+						return gadget.name
+					}
+				}
+			}
+		}
+	}
+	return Other
+}
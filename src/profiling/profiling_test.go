@@ -0,0 +1,34 @@
+package profiling
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+
+	myTransformations "src/transformations"
+)
+
+func TestCropReport(t *testing.T) {
+	session := Start(filepath.Join(t.TempDir(), "crop.pprof"))
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &myTransformations.CropCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := session.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Constraints != ccs.GetNbConstraints() {
+		t.Errorf("report counts %d constraints, the circuit has %d", report.Constraints, ccs.GetNbConstraints())
+	}
+	for _, gadget := range []string{Signature, Comparisons, Pixels} {
+		if report.Gadgets[gadget] == 0 {
+			t.Errorf("no constraints charged to %s: %v", gadget, report.Gadgets)
+		}
+	}
+	t.Log(report.Gadgets)
+}
@@ -0,0 +1,91 @@
+package prover
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"src/backend"
+	gen "src/generator"
+	myTransformations "src/transformations"
+)
+
+// A ProofRequest asks ProveBatch or a Service for the proof of the transformation T of the image of Proof, as
+// the Prover would create it.
+type ProofRequest struct {
+	Proof   Proof
+	T       myTransformations.Transformation
+	Options []backend.ProveOption // Options of this proof only, e.g. backend.WithContext, after those of all proofs
+}
+
+// A ProofResult is the outcome of the ProofRequest of the same index: the proof it asked for, or the reason
+// it could not be created.
+type ProofResult struct {
+	Proof Proof
+	Err   error
+}
+
+// ProveBatch runs the Prover on every request, with the keys pk_pcd and the verifying key of the proofs of the
+// requests, and returns their results in the order of the requests. At most concurrency proofs are created at
+// once, one at a time if concurrency is below 1.
+//
+// The requests share the proving key, and the constraint system of the compliance predicate is compiled once
+// for the whole batch, see generator.Compile, so an agency proving hundreds of edits with the same keys only
+// pays for solving and proving each of them. Every proof of a batch uses all CPUs unless opts cap them with
+// backend.WithWorkers, so a concurrency above 2 or 3 mostly overlaps the single-threaded parts of proofs.
+//
+// opts configure every proof. The output of each proof is written to the output of opts once the proof is
+// done, rather than interleaved with the others; a progress callback is called concurrently by the proofs.
+func ProveBatch(pk_pcd gen.PK_PP, verifyingKey backend.VerifyingKey, requests []ProofRequest, concurrency int, opts ...backend.ProveOption) []ProofResult {
+	results := make([]ProofResult, len(requests))
+	options, err := backend.NewProverOptions(opts...)
+	if err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var outputMu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i, request := range requests {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, request ProofRequest) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			results[i] = proveRequest(pk_pcd, verifyingKey, request, opts, options.Output, &outputMu)
+			if results[i].Err != nil {
+				results[i].Err = fmt.Errorf("request %d: %w", i, results[i].Err)
+			}
+		}(i, request)
+	}
+	wg.Wait()
+	return results
+}
+
+// proveRequest runs the Prover on request, with opts then the options of request, and writes the output of the
+// proof to out in one piece once it is done, holding outputMu.
+func proveRequest(pk_pcd gen.PK_PP, verifyingKey backend.VerifyingKey, request ProofRequest, opts []backend.ProveOption, out io.Writer, outputMu *sync.Mutex) ProofResult {
+	var output bytes.Buffer
+	requestOpts := append(append(opts[:len(opts):len(opts)], request.Options...), backend.WithOutput(&output))
+	proof := Prover(pk_pcd, verifyingKey, request.Proof, request.T, requestOpts...)
+
+	outputMu.Lock()
+	out.Write(output.Bytes())
+	outputMu.Unlock()
+
+	if proof.PCDProof() == nil {
+		return ProofResult{Err: fmt.Errorf("could not create a PCD proof: %s", strings.TrimSpace(output.String()))}
+	}
+	return ProofResult{Proof: proof}
+}
@@ -0,0 +1,42 @@
+package prover
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"src/backend"
+	gen "src/generator"
+	myTransformations "src/transformations"
+)
+
+// The results of a batch are in the order of its requests, each with the reason its proof failed, and the
+// output of each proof is written in one piece.
+func TestProveBatch(t *testing.T) {
+	pk_pp := gen.PK_PP{Circuit: myTransformations.CropCircuitID, Backend: backend.Groth16}
+	identity := myTransformations.Transformation{T: myTransformations.Identity}
+	signed := signedProof()
+	unnumbered := NewSignedProof(signed.Z(), signed.ImageSignature(), nil)
+	requests := []ProofRequest{{Proof: unnumbered, T: identity}, {Proof: Proof{}, T: identity}, {Proof: unnumbered, T: identity}}
+
+	var output bytes.Buffer
+	results := ProveBatch(pk_pp, nil, requests, 2, backend.WithOutput(&output))
+	if len(results) != len(requests) {
+		t.Fatalf("%d results for %d requests", len(results), len(requests))
+	}
+	for i, reason := range []string{"no nonce", "neither a PCD proof nor a digital signature", "no nonce"} {
+		if results[i].Proof.PCDProof() != nil || results[i].Err == nil || !strings.Contains(results[i].Err.Error(), reason) {
+			t.Errorf("request %d: unexpected result %v", i, results[i].Err)
+		}
+	}
+	if strings.Count(output.String(), "Error while creating Proof: \n") != len(requests) || strings.Count(output.String(), "-----------------\n") != len(requests) {
+		t.Errorf("unexpected output %q", output.String())
+	}
+
+	// Invalid options fail every request
+	for i, result := range ProveBatch(pk_pp, nil, requests, 0, backend.WithWorkers(0)) {
+		if result.Err == nil {
+			t.Errorf("request %d: proved with invalid options", i)
+		}
+	}
+}
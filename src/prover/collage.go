@@ -0,0 +1,215 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	gen "src/generator"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// A Collage is the artifact published with a collage: the composed image, the proofs of its inputs, and a
+// PCD proof that the image is their composition in a grid of Rows x Cols cells. The provenance of the
+// collage is the provenance of all of its inputs.
+type Collage struct {
+	image      myImage.I
+	rows, cols int
+	inputs     []Proof
+	pcdProof   backend.Proof
+	circuit    myTransformations.CircuitID
+	backend    backend.ID
+}
+
+// Image returns the composed image.
+func (collage Collage) Image() myImage.I {
+	return collage.image
+}
+
+// Grid returns the rows and columns of the grid the inputs are composed in.
+func (collage Collage) Grid() (int, int) {
+	return collage.rows, collage.cols
+}
+
+// Inputs returns the proofs of the inputs, in row major order.
+func (collage Collage) Inputs() []Proof {
+	return collage.inputs
+}
+
+// PCDProof returns the PCD proof of the composition.
+func (collage Collage) PCDProof() backend.Proof {
+	return collage.pcdProof
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (collage Collage) Circuit() myTransformations.CircuitID {
+	return collage.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (collage Collage) Backend() backend.ID {
+	return collage.backend
+}
+
+// Statements returns the public statements of the proofs of the inputs, which the composition is proven for.
+func (collage Collage) Statements() ([]myTransformations.SignedStatement, error) {
+	statements := make([]myTransformations.SignedStatement, len(collage.inputs))
+	for i, input := range collage.inputs {
+		statement, err := input.statement()
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		statements[i] = statement
+	}
+	return statements, nil
+}
+
+// The public statement of a proof: the key and signature over its image, its nonce and the hash of the
+// previous proof.
+func (proof Proof) statement() (myTransformations.SignedStatement, error) {
+	if proof.z.PublicKey == nil || len(proof.imageSignature) == 0 || proof.nonce == nil || proof.prevProofHash == nil {
+		return myTransformations.SignedStatement{}, fmt.Errorf("the proof carries no signed statement: prove the image again")
+	}
+	return myTransformations.SignedStatement{
+		PublicKey:     proof.z.PublicKey.Bytes(),
+		Signature:     proof.imageSignature,
+		Nonce:         proof.nonce,
+		PrevProofHash: proof.prevProofHash,
+	}, nil
+}
+
+// ProveCollage proves that the collage of the images of inputs, in a grid of rows x cols cells, is their
+// composition: every cell holds the top left cell of one input, in row major order. Every input must carry
+// the signed statement of its image: a signed proof, or a PCD proof created by the Prover. pk_pp are keys
+// created by the CollageGenerator for the grid.
+func ProveCollage(pk_pp gen.PK_PP, rows, cols int, inputs []Proof, opts ...backend.ProveOption) (Collage, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return Collage{}, err
+	}
+	if pk_pp.Circuit != myTransformations.CollageCircuitID {
+		return Collage{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the CollageGenerator", pk_pp.Circuit, myTransformations.CollageCircuitID)
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return Collage{}, err
+	}
+
+	images := make([]myImage.I, len(inputs))
+	for i, input := range inputs {
+		images[i] = input.z.Image
+	}
+	image, err := myTransformations.Compose(rows, cols, images)
+	if err != nil {
+		return Collage{}, err
+	}
+
+	circuit := myTransformations.NewCollageCircuit(rows, cols)
+	circuit.Rows = rows
+	circuit.Cols = cols
+	circuit.ImageDigest = myTransformations.RegionDigest(image)
+	for i, input := range inputs {
+		if _, err := input.statement(); err != nil {
+			return Collage{}, fmt.Errorf("input %d: %w", i, err)
+		}
+
+		var eddsa_signature eddsa.Signature
+		eddsa_signature.Assign(1, input.imageSignature)
+		var eddsa_publicKey eddsa.PublicKey
+		eddsa_publicKey.Assign(1, input.z.PublicKey.Bytes())
+
+		circuit.Inputs[i] = myTransformations.CollageInput{
+			PublicKey:      eddsa_publicKey,
+			ImageSignature: eddsa_signature,
+			Nonce:          input.nonce,
+			PrevProofHash:  input.prevProofHash,
+			ImageBytes:     input.z.Image.Digest(),
+			Metadata:       input.z.Image.MetadataDigest(),
+			Image:          input.z.Image.ToFrontendPacked(),
+		}
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return Collage{}, err
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := b.Compile(circuit) // Not gen.Compile: the keys do not record the grid
+	if err != nil {
+		return Collage{}, err
+	}
+	pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		return Collage{}, err
+	}
+
+	return Collage{image: image, rows: rows, cols: cols, inputs: append([]Proof(nil), inputs...), pcdProof: pcd_proof, circuit: pk_pp.Circuit, backend: b.ID()}, nil
+}
+
+// Version of the collage encoding written by MarshalJSON.
+const CollageFormatVersion = 1
+
+// JSON encoding of a Collage, as published.
+type collageJSON struct {
+	Version  int                         `json:"version"`
+	Circuit  myTransformations.CircuitID `json:"circuit"`
+	Backend  backend.ID                  `json:"backend"`
+	Rows     int                         `json:"rows"`
+	Cols     int                         `json:"cols"`
+	Image    myImage.I                   `json:"image"`
+	Inputs   []Proof                     `json:"inputs"`
+	PCDProof []byte                      `json:"pcdProof"`
+}
+
+func (collage Collage) MarshalJSON() ([]byte, error) {
+	if collage.pcdProof == nil {
+		return nil, fmt.Errorf("the collage carries no PCD proof")
+	}
+
+	pcd_proof := getBuffer()
+	defer putBuffer(pcd_proof)
+	if _, err := collage.pcdProof.WriteTo(pcd_proof); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(collageJSON{
+		Version:  CollageFormatVersion,
+		Circuit:  collage.circuit,
+		Backend:  collage.backend,
+		Rows:     collage.rows,
+		Cols:     collage.cols,
+		Image:    collage.image,
+		Inputs:   collage.inputs,
+		PCDProof: pcd_proof.Bytes(),
+	})
+}
+
+func (collage *Collage) UnmarshalJSON(data []byte) error {
+	var decoded collageJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > CollageFormatVersion {
+		return fmt.Errorf("collage format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this collage", decoded.Version, CollageFormatVersion)
+	}
+	if err := myTransformations.CheckGrid(decoded.Rows, decoded.Cols); err != nil {
+		return err
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+
+	*collage = Collage{image: decoded.Image, rows: decoded.Rows, cols: decoded.Cols, inputs: decoded.Inputs, pcdProof: pcd_proof, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
@@ -0,0 +1,117 @@
+package prover
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// A proof container is the JSON encoding of a Proof, as written by MarshalJSON, optionally compressed so
+// that it is small enough to embed into an image file. Zstd compresses it best; gzip containers are still
+// written and read, for tools that only know gzip.
+//
+// The PCD proof and the keys are already written with gnark's compressed point encodings (WriteTo, rather
+// than WriteRawTo), which halve their size. Compressing the container mostly shrinks the image and the
+// base64 encoding of the binary fields.
+type Compression string
+
+const (
+	Uncompressed Compression = "none"
+	Gzip         Compression = "gzip"
+	Zstd         Compression = "zstd"
+)
+
+// Containers that decompress to more than this are rejected, so that a small file received from an
+// untrusted source cannot expand without bound.
+const maxContainerSize = 64 << 20
+
+// The first bytes of a gzip and of a zstd stream; a JSON encoding starts with '{'.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ParseCompression returns the Compression of the given name.
+func ParseCompression(name string) (Compression, error) {
+	switch compression := Compression(name); compression {
+	case Uncompressed, Gzip, Zstd:
+		return compression, nil
+	}
+	return "", fmt.Errorf("unknown compression %q: choose %s, %s or %s", name, Uncompressed, Gzip, Zstd)
+}
+
+// WriteContainer writes the proof container of proof to w.
+func WriteContainer(w io.Writer, proof Proof, compression Compression) error {
+	encoded, err := json.Marshal(proof)
+	if err != nil {
+		return err
+	}
+
+	switch compression {
+	case Uncompressed:
+		_, err = w.Write(encoded)
+		return err
+	case Gzip:
+		gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+		if err != nil {
+			return err
+		}
+		if _, err := gz.Write(encoded); err != nil {
+			return err
+		}
+		return gz.Close()
+	case Zstd:
+		zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+		if err != nil {
+			return err
+		}
+		if _, err := zw.Write(encoded); err != nil {
+			zw.Close()
+			return err
+		}
+		return zw.Close()
+	}
+	return fmt.Errorf("unknown compression %q", compression)
+}
+
+// ReadContainer reads a proof container written by WriteContainer, with any compression.
+func ReadContainer(r io.Reader) (Proof, error) {
+	buffered := bufio.NewReader(r)
+
+	var decompressed io.Reader = buffered
+	if magic, err := buffered.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return Proof{}, fmt.Errorf("invalid proof container: %w", err)
+		}
+		defer gz.Close()
+		decompressed = gz
+	} else if magic, err := buffered.Peek(len(zstdMagic)); err == nil && bytes.Equal(magic, zstdMagic) {
+		// The window is bounded like the container, so that a crafted frame cannot allocate without bound
+		zr, err := zstd.NewReader(buffered, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(maxContainerSize))
+		if err != nil {
+			return Proof{}, fmt.Errorf("invalid proof container: %w", err)
+		}
+		defer zr.Close()
+		decompressed = zr
+	}
+
+	encoded, err := io.ReadAll(io.LimitReader(decompressed, maxContainerSize+1))
+	if err != nil {
+		return Proof{}, fmt.Errorf("invalid proof container: %w", err)
+	}
+	if len(encoded) > maxContainerSize {
+		return Proof{}, fmt.Errorf("invalid proof container: larger than %d bytes", maxContainerSize)
+	}
+
+	var proof Proof
+	if err := json.Unmarshal(encoded, &proof); err != nil {
+		return Proof{}, err
+	}
+	return proof, nil
+}
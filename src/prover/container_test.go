@@ -0,0 +1,88 @@
+package prover
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	gen "src/generator"
+	myImage "src/image"
+)
+
+// The Proof of a signed all white image.
+func signedProof() Proof {
+	img := myImage.AllWhiteImage()
+	counter := big.NewInt(1)
+	signature, publicKey, _, _ := gen.Sign(img, counter, big.NewInt(0))
+	return NewSignedProof(myImage.Z{Image: img, PublicKey: publicKey}, signature, counter)
+}
+
+func TestContainerRoundTrip(t *testing.T) {
+	proof := signedProof()
+
+	sizes := map[Compression]int{}
+	for _, compression := range []Compression{Uncompressed, Gzip, Zstd} {
+		var container bytes.Buffer
+		if err := WriteContainer(&container, proof, compression); err != nil {
+			t.Fatal(err)
+		}
+		sizes[compression] = container.Len()
+
+		decoded, err := ReadContainer(&container)
+		if err != nil {
+			t.Fatalf("%s: %v", compression, err)
+		}
+		if decoded.Z().Image.Pixels != proof.Z().Image.Pixels || !bytes.Equal(decoded.ImageSignature(), proof.ImageSignature()) || decoded.Nonce().Cmp(proof.Nonce()) != 0 {
+			t.Errorf("%s: decoded proof differs", compression)
+		}
+	}
+
+	for _, compression := range []Compression{Gzip, Zstd} {
+		if sizes[compression] >= sizes[Uncompressed] {
+			t.Errorf("%s container of %d bytes is not smaller than %d bytes uncompressed", compression, sizes[compression], sizes[Uncompressed])
+		}
+	}
+}
+
+func TestContainerSizeLimit(t *testing.T) {
+	// A valid JSON encoding, padded with whitespace beyond the limit, compresses to a few kilobytes
+	encoded, err := json.Marshal(signedProof())
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := append(encoded, bytes.Repeat([]byte{' '}, maxContainerSize)...)
+
+	var container bytes.Buffer
+	gz := gzip.NewWriter(&container)
+	gz.Write(padded)
+	gz.Close()
+	if _, err := ReadContainer(&container); err == nil {
+		t.Error("gzip container larger than the limit was accepted")
+	}
+
+	container.Reset()
+	zw, err := zstd.NewWriter(&container)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw.Write(padded)
+	zw.Close()
+	if _, err := ReadContainer(&container); err == nil {
+		t.Error("zstd container larger than the limit was accepted")
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, name := range []string{"none", "gzip", "zstd"} {
+		if _, err := ParseCompression(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := ParseCompression("brotli"); err == nil {
+		t.Error("unknown compression was accepted")
+	}
+}
@@ -0,0 +1,198 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	gen "src/generator"
+	"src/hashsuite"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// A DeepImage is the artifact published with a deep color image: the image, and a PCD proof that it is an area
+// of a deep color capture the camera signed. Neither the capture nor its signature are part of the DeepImage,
+// only its capture counter and the area.
+type DeepImage struct {
+	image    myImage.Deep
+	nonce    *big.Int
+	area     map[string]int
+	pcdProof backend.Proof
+	circuit  myTransformations.CircuitID
+	backend  backend.ID
+}
+
+// Image returns the published deep color image.
+func (deep DeepImage) Image() myImage.Deep {
+	return deep.image
+}
+
+// Nonce returns the capture counter of the deep color capture.
+func (deep DeepImage) Nonce() *big.Int {
+	return deep.nonce
+}
+
+// Area returns the area {x0, y0, x1, y1} of the capture the image shows.
+func (deep DeepImage) Area() map[string]int {
+	return map[string]int{"x0": deep.area["x0"], "y0": deep.area["y0"], "x1": deep.area["x1"], "y1": deep.area["y1"]}
+}
+
+// PCDProof returns the PCD proof of the deep color image.
+func (deep DeepImage) PCDProof() backend.Proof {
+	return deep.pcdProof
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (deep DeepImage) Circuit() myTransformations.CircuitID {
+	return deep.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (deep DeepImage) Backend() backend.ID {
+	return deep.backend
+}
+
+// ProveDeep crops a deep color capture to the area {x0, y0, x1, y1} of params and proves the cropped image;
+// the whole image is an area too. captureSignature is the camera's signature over capture for the capture
+// counter nonce, see image.Deep.Sign. pk_pp are keys created by the DeepGenerator for the camera.
+func ProveDeep(pk_pp gen.PK_PP, capture myImage.Deep, captureSignature []byte, nonce *big.Int, params map[string]int, opts ...backend.ProveOption) (DeepImage, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return DeepImage{}, err
+	}
+	if pk_pp.Circuit != myTransformations.DeepCircuitID {
+		return DeepImage{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the DeepGenerator", pk_pp.Circuit, myTransformations.DeepCircuitID)
+	}
+	if err := myTransformations.CheckArea(params); err != nil {
+		return DeepImage{}, err
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return DeepImage{}, err
+	}
+
+	// Only the camera's signature over the capture ties the image to a picture it took
+	if nonce == nil || pk_pp.PublicKey == nil {
+		return DeepImage{}, fmt.Errorf("the deep color capture carries no nonce, or the keys no camera")
+	}
+	captureBytes := capture.Digest()
+	isVerified, err := pk_pp.PublicKey.Verify(captureSignature, myImage.Statement(captureBytes, nonce, big.NewInt(0)), hashsuite.Default.New())
+	if err != nil || !isVerified {
+		return DeepImage{}, fmt.Errorf("the deep color capture was not signed by the camera of the keys for capture %v", nonce)
+	}
+
+	// The published image keeps none of the capture's metadata, which Crop then sets to its size
+	published := capture
+	published.M = myImage.Metadata{Width: capture.M.Width, Height: capture.M.Height}
+	if err := published.Crop(params["x0"], params["y0"], params["x1"], params["y1"]); err != nil {
+		return DeepImage{}, err
+	}
+
+	var eddsa_signature eddsa.Signature
+	eddsa_signature.Assign(1, captureSignature)
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, pk_pp.PublicKey.Bytes())
+
+	circuit := myTransformations.DeepCircuit{
+		PublicKey:      eddsa_publicKey,
+		Nonce:          nonce,
+		ImageDigest:    myTransformations.DeepDigest(published),
+		Area:           myTransformations.Transformation{T: myTransformations.Crop, Params: params}.ToFr().Params,
+		ImageSignature: eddsa_signature,
+		ImageBytes:     captureBytes,
+		Metadata:       capture.MetadataDigest(),
+		Capture:        capture.ToFrontendImage(),
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return DeepImage{}, err
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pp.Circuit, &circuit)
+	if err != nil {
+		return DeepImage{}, err
+	}
+	pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		return DeepImage{}, err
+	}
+
+	area := map[string]int{"x0": params["x0"], "y0": params["y0"], "x1": params["x1"], "y1": params["y1"]}
+	return DeepImage{image: published, nonce: nonce, area: area, pcdProof: pcd_proof, circuit: pk_pp.Circuit, backend: b.ID()}, nil
+}
+
+// Version of the deep color image encoding written by MarshalJSON.
+const DeepFormatVersion = 1
+
+// JSON encoding of a DeepImage, as published.
+type deepJSON struct {
+	Version  int                         `json:"version"`
+	Circuit  myTransformations.CircuitID `json:"circuit"`
+	Backend  backend.ID                  `json:"backend"`
+	Image    myImage.Deep                `json:"image"`
+	Nonce    string                      `json:"nonce"` // decimal
+	Area     map[string]int              `json:"area"`  // x0, y0, x1, y1
+	PCDProof []byte                      `json:"pcdProof"`
+}
+
+func (deep DeepImage) MarshalJSON() ([]byte, error) {
+	if deep.pcdProof == nil {
+		return nil, fmt.Errorf("the deep color image carries no PCD proof")
+	}
+
+	pcd_proof := getBuffer()
+	defer putBuffer(pcd_proof)
+	if _, err := deep.pcdProof.WriteTo(pcd_proof); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(deepJSON{
+		Version:  DeepFormatVersion,
+		Circuit:  deep.circuit,
+		Backend:  deep.backend,
+		Image:    deep.image,
+		Nonce:    deep.nonce.String(),
+		Area:     deep.area,
+		PCDProof: pcd_proof.Bytes(),
+	})
+}
+
+func (deep *DeepImage) UnmarshalJSON(data []byte) error {
+	var decoded deepJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > DeepFormatVersion {
+		return fmt.Errorf("deep color image format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this deep color image", decoded.Version, DeepFormatVersion)
+	}
+
+	nonce, err := parseDecimal("nonce", decoded.Nonce)
+	if err != nil {
+		return err
+	}
+	if nonce == nil {
+		return fmt.Errorf("the deep color image carries no nonce")
+	}
+	if err := myTransformations.CheckArea(decoded.Area); err != nil {
+		return err
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+
+	*deep = DeepImage{image: decoded.Image, nonce: nonce, area: decoded.Area, pcdProof: pcd_proof, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
@@ -0,0 +1,201 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	gen "src/generator"
+	"src/hashsuite"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// A Development is the artifact published with a developed photo: the image, and a PCD proof that it was
+// developed from a RAW capture the camera signed, with the white balance gains. Neither the RAW capture nor
+// its signature are part of the Development, only its capture counter.
+type Development struct {
+	image    myImage.I
+	nonce    *big.Int
+	gains    [3]int
+	pcdProof backend.Proof
+	circuit  myTransformations.CircuitID
+	backend  backend.ID
+}
+
+// Image returns the developed image.
+func (development Development) Image() myImage.I {
+	return development.image
+}
+
+// Nonce returns the capture counter of the RAW capture the image was developed from.
+func (development Development) Nonce() *big.Int {
+	return development.nonce
+}
+
+// Gains returns the white balance gains of R, G and B, out of transformations.GainOne.
+func (development Development) Gains() [3]int {
+	return development.gains
+}
+
+// PCDProof returns the PCD proof of the development.
+func (development Development) PCDProof() backend.Proof {
+	return development.pcdProof
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (development Development) Circuit() myTransformations.CircuitID {
+	return development.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (development Development) Backend() backend.ID {
+	return development.backend
+}
+
+// ProveDevelopment develops raw with white balance gains and proves the development. rawSignature is the
+// camera's signature over raw for the capture counter nonce, see image.RAW.Sign. pk_pp are keys created by the
+// DevelopGenerator for the camera.
+func ProveDevelopment(pk_pp gen.PK_PP, raw myImage.RAW, rawSignature []byte, nonce *big.Int, gains [3]int, opts ...backend.ProveOption) (Development, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return Development{}, err
+	}
+	if pk_pp.Circuit != myTransformations.DevelopCircuitID {
+		return Development{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the DevelopGenerator", pk_pp.Circuit, myTransformations.DevelopCircuitID)
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return Development{}, err
+	}
+
+	// Only the camera's signature over the RAW capture ties the development to its sensor data
+	if nonce == nil || pk_pp.PublicKey == nil {
+		return Development{}, fmt.Errorf("the RAW capture carries no nonce, or the keys no camera")
+	}
+	rawBytes := raw.Digest()
+	isVerified, err := pk_pp.PublicKey.Verify(rawSignature, myImage.Statement(rawBytes, nonce, big.NewInt(0)), hashsuite.Default.New())
+	if err != nil || !isVerified {
+		return Development{}, fmt.Errorf("the RAW capture was not signed by the camera of the keys for capture %v", nonce)
+	}
+
+	developed, err := myTransformations.Develop(raw, gains)
+	if err != nil {
+		return Development{}, err
+	}
+	balanced, err := myTransformations.WhiteBalance(raw, gains)
+	if err != nil {
+		return Development{}, err
+	}
+
+	var eddsa_signature eddsa.Signature
+	eddsa_signature.Assign(1, rawSignature)
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, pk_pp.PublicKey.Bytes())
+
+	circuit := myTransformations.DevelopCircuit{
+		PublicKey:      eddsa_publicKey,
+		Nonce:          nonce,
+		ImageDigest:    myTransformations.RegionDigest(developed),
+		ImageSignature: eddsa_signature,
+		RAWBytes:       rawBytes,
+		Metadata:       raw.MetadataDigest(),
+		RAW:            raw.ToFrontendRAW(),
+		Developed:      developed.ToFrontendImage(),
+	}
+	for c := range gains {
+		circuit.Gains[c] = gains[c]
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				circuit.Balanced[c][y][x] = balanced[c][y][x]
+			}
+		}
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return Development{}, err
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pp.Circuit, &circuit)
+	if err != nil {
+		return Development{}, err
+	}
+	pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		return Development{}, err
+	}
+
+	return Development{image: developed, nonce: nonce, gains: gains, pcdProof: pcd_proof, circuit: pk_pp.Circuit, backend: b.ID()}, nil
+}
+
+// Version of the development encoding written by MarshalJSON.
+const DevelopmentFormatVersion = 1
+
+// JSON encoding of a Development, as published.
+type developmentJSON struct {
+	Version  int                         `json:"version"`
+	Circuit  myTransformations.CircuitID `json:"circuit"`
+	Backend  backend.ID                  `json:"backend"`
+	Image    myImage.I                   `json:"image"`
+	Nonce    string                      `json:"nonce"` // decimal
+	Gains    [3]int                      `json:"gains"`
+	PCDProof []byte                      `json:"pcdProof"`
+}
+
+func (development Development) MarshalJSON() ([]byte, error) {
+	if development.pcdProof == nil {
+		return nil, fmt.Errorf("the development carries no PCD proof")
+	}
+
+	pcd_proof := getBuffer()
+	defer putBuffer(pcd_proof)
+	if _, err := development.pcdProof.WriteTo(pcd_proof); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(developmentJSON{
+		Version:  DevelopmentFormatVersion,
+		Circuit:  development.circuit,
+		Backend:  development.backend,
+		Image:    development.image,
+		Nonce:    development.nonce.String(),
+		Gains:    development.gains,
+		PCDProof: pcd_proof.Bytes(),
+	})
+}
+
+func (development *Development) UnmarshalJSON(data []byte) error {
+	var decoded developmentJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > DevelopmentFormatVersion {
+		return fmt.Errorf("development format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this development", decoded.Version, DevelopmentFormatVersion)
+	}
+
+	nonce, err := parseDecimal("nonce", decoded.Nonce)
+	if err != nil {
+		return err
+	}
+	if nonce == nil {
+		return fmt.Errorf("the development carries no nonce")
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+
+	*development = Development{image: decoded.Image, nonce: nonce, gains: decoded.Gains, pcdProof: pcd_proof, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
@@ -0,0 +1,187 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	gen "src/generator"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// A Disclosure is the artifact published by a selective disclosure: a region of an original image, and a
+// PCD proof that it is an unmodified rectangle of a picture the camera signed. Neither the original, nor its
+// signature, nor the location of the region in it are part of the Disclosure.
+type Disclosure struct {
+	region   myImage.I
+	nonce    *big.Int
+	pcdProof backend.Proof
+	circuit  myTransformations.CircuitID
+	backend  backend.ID
+}
+
+// Region returns the disclosed region, translated to the top left corner like image.Crop does.
+// Its metadata only holds its width and height.
+func (disclosure Disclosure) Region() myImage.I {
+	return disclosure.region
+}
+
+// Nonce returns the capture counter of the original image.
+func (disclosure Disclosure) Nonce() *big.Int {
+	return disclosure.nonce
+}
+
+// PCDProof returns the PCD proof of the disclosure.
+func (disclosure Disclosure) PCDProof() backend.Proof {
+	return disclosure.pcdProof
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (disclosure Disclosure) Circuit() myTransformations.CircuitID {
+	return disclosure.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (disclosure Disclosure) Backend() backend.ID {
+	return disclosure.backend
+}
+
+// Disclose proves that the area {x0, y0, x1, y1} of params is a region of the original image of proof, which
+// must carry the camera's signature: a signed proof, or the PCD proof of an original image. pk_pp are keys
+// created by the DisclosureGenerator for the camera.
+func Disclose(pk_pp gen.PK_PP, original Proof, params map[string]int, opts ...backend.ProveOption) (Disclosure, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return Disclosure{}, err
+	}
+	if pk_pp.Circuit != myTransformations.DisclosureCircuitID {
+		return Disclosure{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the DisclosureGenerator", pk_pp.Circuit, myTransformations.DisclosureCircuitID)
+	}
+	if err := myTransformations.CheckArea(params); err != nil {
+		return Disclosure{}, err
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return Disclosure{}, err
+	}
+
+	// Only the camera's signature over an original image makes the region part of a picture it took
+	if len(original.imageSignature) == 0 || original.nonce == nil || original.prevProofHash == nil || original.prevProofHash.Sign() != 0 {
+		return Disclosure{}, fmt.Errorf("the proof is not of an original image signed by the camera")
+	}
+	if original.z.PublicKey == nil || !original.z.PublicKey.Equal(pk_pp.PublicKey) {
+		return Disclosure{}, fmt.Errorf("the original image was not signed by the camera of the keys")
+	}
+
+	// The region keeps none of the original's metadata, which Crop then sets to the size of the region
+	cropParams := myTransformations.Transformation{T: myTransformations.Crop, Params: params}.ToFr().Params
+	region := original.z.Image
+	region.M = myImage.Metadata{Width: original.z.Image.M.Width, Height: original.z.Image.M.Height}
+	if err := region.Crop(cropParams.X0.(int), cropParams.Y0.(int), cropParams.X1.(int), cropParams.Y1.(int)); err != nil {
+		return Disclosure{}, err
+	}
+
+	var eddsa_signature eddsa.Signature
+	eddsa_signature.Assign(1, original.imageSignature)
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, pk_pp.PublicKey.Bytes())
+
+	circuit := myTransformations.DisclosureCircuit{
+		PublicKey:      eddsa_publicKey,
+		Nonce:          original.nonce,
+		RegionDigest:   myTransformations.RegionDigest(region),
+		Width:          region.M.Width,
+		Height:         region.M.Height,
+		ImageSignature: eddsa_signature,
+		ImageBytes:     original.z.Image.Digest(),
+		Metadata:       original.z.Image.MetadataDigest(),
+		Original:       original.z.Image.ToFrontendPacked(),
+		Params:         cropParams,
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return Disclosure{}, err
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pp.Circuit, &circuit)
+	if err != nil {
+		return Disclosure{}, err
+	}
+	pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		return Disclosure{}, err
+	}
+
+	return Disclosure{region: region, nonce: original.nonce, pcdProof: pcd_proof, circuit: pk_pp.Circuit, backend: b.ID()}, nil
+}
+
+// Version of the disclosure encoding written by MarshalJSON.
+const DisclosureFormatVersion = 1
+
+// JSON encoding of a Disclosure, as published.
+type disclosureJSON struct {
+	Version  int                         `json:"version"`
+	Circuit  myTransformations.CircuitID `json:"circuit"`
+	Backend  backend.ID                  `json:"backend"`
+	Region   myImage.I                   `json:"region"`
+	Nonce    string                      `json:"nonce"` // decimal
+	PCDProof []byte                      `json:"pcdProof"`
+}
+
+func (disclosure Disclosure) MarshalJSON() ([]byte, error) {
+	if disclosure.pcdProof == nil {
+		return nil, fmt.Errorf("the disclosure carries no PCD proof")
+	}
+
+	pcd_proof := getBuffer()
+	defer putBuffer(pcd_proof)
+	if _, err := disclosure.pcdProof.WriteTo(pcd_proof); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(disclosureJSON{
+		Version:  DisclosureFormatVersion,
+		Circuit:  disclosure.circuit,
+		Backend:  disclosure.backend,
+		Region:   disclosure.region,
+		Nonce:    disclosure.nonce.String(),
+		PCDProof: pcd_proof.Bytes(),
+	})
+}
+
+func (disclosure *Disclosure) UnmarshalJSON(data []byte) error {
+	var decoded disclosureJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > DisclosureFormatVersion {
+		return fmt.Errorf("disclosure format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this disclosure", decoded.Version, DisclosureFormatVersion)
+	}
+
+	nonce, err := parseDecimal("nonce", decoded.Nonce)
+	if err != nil {
+		return err
+	}
+	if nonce == nil {
+		return fmt.Errorf("the disclosure carries no nonce")
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+
+	*disclosure = Disclosure{region: decoded.Region, nonce: nonce, pcdProof: pcd_proof, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
@@ -0,0 +1,124 @@
+package prover
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	gen "src/generator"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// proveEdit is the Prover of the edits other than a crop, e.g. a Rotate: it verifies proof_in, applies t to its
+// image and creates the PCD proof of the result with keys of the compliance predicate of t, like the Prover
+// does for a crop. options are those opts set.
+func proveEdit(b backend.Backend, pk_pcd gen.PK_PP, verifyingKey backend.VerifyingKey, proof_in Proof, t myTransformations.Transformation, options backend.ProverOptions, opts ...backend.ProveOption) Proof {
+	out := options.Output
+
+	// Verify the PCD proof.
+	if err := b.Verify(proof_in.pcdProof, verifyingKey, proof_in.publicWitness); err != nil {
+		fmt.Fprintln(out, "FAIL: Image did not pass verification against PCD Proof.")
+		return Proof{}
+	}
+	fmt.Fprintln(out, "SUCCESS: Image verified against PCD Proof.")
+
+	// Apply the transformation to z_in's image, which returns a new image that shares nothing with z_in
+	z_in := proof_in.z
+	image_out, err := t.Apply(z_in.Image)
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+
+	// Sign image_out, for the capture and the proof it was edited from
+	prevProofHash, err := ProofHash(proof_in)
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+	normalSignature, publicKey, big_endian_bytes_Image, err := signEdit(options, image_out, proof_in.nonce, prevProofHash)
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+	z_out := myImage.Z{Image: image_out, PublicKey: publicKey, Hops: z_in.Hops + 1} // The edit is one hop past proof_in
+
+	var eddsa_signature eddsa.Signature
+	eddsa_signature.Assign(1, normalSignature)
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, publicKey.Bytes())
+
+	// The statement of the proof is its single public input, along with the parameters of the edit
+	statementDigest, err := myTransformations.StatementDigest(publicKey.Bytes(), normalSignature, proof_in.nonce, prevProofHash, proof_in.nullifier, z_out.Hops, big_endian_bytes_Image, z_in.Image.Digest(), z_in.PublicKey.Bytes())
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+	statement := myTransformations.EditStatement{
+		Statement:      statementDigest,
+		PublicKey:      eddsa_publicKey,
+		ImageSignature: eddsa_signature,
+		Nonce:          proof_in.nonce,
+		PrevProofHash:  prevProofHash,
+		Nullifier:      proof_in.nullifier, // The edit carries the nullifier of the capture along
+		Hops:           z_out.Hops,
+		ImageBytes:     big_endian_bytes_Image,
+		Metadata:       z_out.Image.MetadataDigest(),
+		PrevImageBytes: z_in.Image.Digest(), // The image of proof_in
+		PrevMetadata:   z_in.Image.MetadataDigest(),
+		Prev:           myTransformations.NewPrevSignature(z_in.PublicKey.Bytes(), proof_in.imageSignature, proof_in.prevProofHash), // The signature of proof_in
+	}
+	circuit, err := myTransformations.EditAssignment(pk_pcd.Circuit, t, z_in.Image, z_out.Image, statement)
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Witness: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+	if err := options.Context.Err(); err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \nproving cancelled: "+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pcd.Circuit, circuit)
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+	proof_out, err := b.Prove(compliance_predicate, pk_pcd.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+	publicWitness, err := secret_witness.Public()
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Public Witness: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+
+	params := myTransformations.PublicParams(pk_pcd.Circuit, t)
+	return Proof{pcdProof: proof_out, z: z_out, imageSignature: normalSignature, nonce: proof_in.nonce, prevProofHash: prevProofHash, nullifier: proof_in.nullifier, prevImageBytes: z_in.Image.Digest(), params: params, publicWitness: publicWitness, circuit: pk_pcd.Circuit, backend: b.ID()}
+}
+
+// signEdit signs the image of an edit, for the capture nonce and the proof prevProofHash it was edited from,
+// with the editor key of options, see backend.WithEditorKey, so that every edit proven with the same keys is
+// signed by the same key, the EditorKey of their VK_PP. It returns the signature, the public key it verifies
+// with and the digest of the image.
+func signEdit(options backend.ProverOptions, image myImage.I, nonce *big.Int, prevProofHash *big.Int) ([]byte, signature.PublicKey, []byte, error) {
+	if options.EditorKey == nil {
+		return nil, nil, nil, fmt.Errorf("no editor key to sign the edit with: pass the EditorKey of the SK_PP of the keys with backend.WithEditorKey")
+	}
+	normalSignature, digest := gen.SignWith(options.EditorKey, image, nonce, prevProofHash)
+	return normalSignature, options.EditorKey.Public(), digest, nil
+}
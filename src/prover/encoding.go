@@ -0,0 +1,191 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"src/backend"
+	gen "src/generator"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// Version of the proof encoding written by MarshalJSON.
+// Proofs written before the encoding was versioned have no version, which reads as 0.
+//
+//	0: crop circuit v1 proofs, groth16
+//	1: records the circuit, groth16
+//	2: records the circuit and the backend
+//	3: records the nonce, and the signature of edited images
+//	4: records the hash of the previous proof; signatures are over the statement of image.Statement
+//	5: records the nullifier of the capture
+//	6: records the public parameters of the edit
+//	7: records the hops of the image
+//	8: records the digest of the image an edit was made from
+const ProofFormatVersion = 8
+
+// JSON encoding of a Proof, so that it can be stored or sent next to the image it proves.
+// PCDProof and PublicWitness are absent for an original image that only carries the
+// camera's digital signature.
+type proofJSON struct {
+	Version        int                         `json:"version"`
+	Circuit        myTransformations.CircuitID `json:"circuit,omitempty"`
+	Backend        backend.ID                  `json:"backend,omitempty"`
+	PCDProof       []byte                      `json:"pcdProof,omitempty"`
+	PublicWitness  []byte                      `json:"publicWitness,omitempty"`
+	Image          myImage.I                   `json:"image"`
+	PublicKey      []byte                      `json:"publicKey"`
+	ImageSignature []byte                      `json:"imageSignature,omitempty"`
+	Nonce          string                      `json:"nonce,omitempty"`         // decimal, as JSON numbers lose precision beyond 2^53 in many decoders
+	PrevProofHash  string                      `json:"prevProofHash,omitempty"` // decimal
+	Nullifier      string                      `json:"nullifier,omitempty"`     // decimal
+	Params         map[string]int              `json:"params,omitempty"`
+	Hops           int                         `json:"hops,omitempty"`
+	PrevImageBytes []byte                      `json:"prevImageBytes,omitempty"`
+}
+
+func (proof Proof) MarshalJSON() ([]byte, error) {
+	encoded := proofJSON{Version: ProofFormatVersion, Image: proof.z.Image, ImageSignature: proof.imageSignature, Hops: proof.z.Hops}
+
+	if proof.z.PublicKey != nil {
+		encoded.PublicKey = proof.z.PublicKey.Bytes()
+	}
+	if proof.nonce != nil {
+		encoded.Nonce = proof.nonce.String()
+	}
+	if proof.prevProofHash != nil {
+		encoded.PrevProofHash = proof.prevProofHash.String()
+	}
+	if proof.nullifier != nil {
+		encoded.Nullifier = proof.nullifier.String()
+	}
+
+	if proof.pcdProof != nil {
+		// The buffers are only referenced until json.Marshal returns
+		pcd_proof := getBuffer()
+		defer putBuffer(pcd_proof)
+		if _, err := proof.pcdProof.WriteTo(pcd_proof); err != nil {
+			return nil, err
+		}
+		encoded.PCDProof = pcd_proof.Bytes()
+		encoded.Circuit = proof.circuit
+		encoded.Backend = proof.backend
+		encoded.Params = proof.params
+		encoded.PrevImageBytes = proof.prevImageBytes
+
+		publicWitness := getBuffer()
+		defer putBuffer(publicWitness)
+		if _, err := proof.publicWitness.WriteTo(publicWitness); err != nil {
+			return nil, err
+		}
+		encoded.PublicWitness = publicWitness.Bytes()
+	}
+
+	return json.Marshal(encoded)
+}
+
+func (proof *Proof) UnmarshalJSON(data []byte) error {
+	var decoded proofJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if err := migrateProof(&decoded); err != nil {
+		return err
+	}
+
+	publicKey, err := gen.PublicKeyFromBytes(decoded.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	// Proofs written before version 7 have no hops, and neither have their circuits
+	if decoded.Hops < 0 {
+		return fmt.Errorf("invalid hops %d", decoded.Hops)
+	}
+	z := myImage.Z{Image: decoded.Image, PublicKey: publicKey, Hops: decoded.Hops}
+
+	// Proofs written before version 3 have no nonce, before version 4 no hash of the previous proof, and before
+	// version 5 no nullifier
+	nonce, err := parseDecimal("nonce", decoded.Nonce)
+	if err != nil {
+		return err
+	}
+	prevProofHash, err := parseDecimal("prevProofHash", decoded.PrevProofHash)
+	if err != nil {
+		return err
+	}
+
+	nullifier, err := parseDecimal("nullifier", decoded.Nullifier)
+	if err != nil {
+		return err
+	}
+
+	// The nullifier of an original image is derived from its key and nonce rather than read
+	if decoded.PCDProof == nil {
+		*proof = NewSignedProof(z, decoded.ImageSignature, nonce)
+		return nil
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+
+	// Proofs written before version 8 have no digest of the previous image, and neither have their circuits
+	*proof, err = DecodeProof(b, z, decoded.ImageSignature, nonce, prevProofHash, nullifier, decoded.PrevImageBytes, decoded.Circuit, decoded.PCDProof, decoded.PublicWitness)
+	if err != nil {
+		return err
+	}
+
+	// Proofs written before version 6 have no public parameters, and neither have their circuits
+	proof.params = decoded.Params
+	return nil
+}
+
+// DecodeProof returns the Proof of z, its signature, nonce, hash of the previous proof, nullifier and digest of the
+// previous image for the given circuit and backend, given its PCD proof (as written by WriteTo) and public
+// witness (as written by witness.MarshalBinary) received from an untrusted source.
+func DecodeProof(b backend.Backend, z myImage.Z, imageSignature []byte, nonce *big.Int, prevProofHash *big.Int, nullifier *big.Int, prevImageBytes []byte, circuit myTransformations.CircuitID, proofBytes []byte, witnessBytes []byte) (Proof, error) {
+	pcd_proof, err := b.ReadProof(proofBytes)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	publicWitness, err := backend.ReadWitness(witnessBytes)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	return Proof{pcdProof: pcd_proof, z: z, imageSignature: imageSignature, nonce: nonce, prevProofHash: prevProofHash, nullifier: nullifier, prevImageBytes: prevImageBytes, publicWitness: publicWitness, circuit: circuit, backend: b.ID()}, nil
+}
+
+// Parse an optional decimal field of the encoding, nil if absent.
+func parseDecimal(name string, value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	decimal, ok := new(big.Int).SetString(value, 10)
+	if !ok || decimal.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return decimal, nil
+}
+
+// Bring a proof encoded with an older format version up to ProofFormatVersion.
+func migrateProof(decoded *proofJSON) error {
+	if decoded.Version > ProofFormatVersion {
+		return fmt.Errorf("proof format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this proof", decoded.Version, ProofFormatVersion)
+	}
+	if decoded.PCDProof == nil {
+		return nil
+	}
+	if decoded.Version < 1 {
+		// Unversioned PCD proofs were all created with the first version of the crop circuit.
+		decoded.Circuit = myTransformations.CircuitID{Name: "crop", Version: 1}
+	}
+	if decoded.Version < 2 {
+		decoded.Backend = backend.Groth16
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package prover
+
+import (
+	"bytes"
+	"testing"
+)
+
+// FuzzReadContainer feeds attacker controlled bytes to ReadContainer, the way a verifier reading a proof
+// container from an untrusted file would, through its gzip and zstd decompressors. It must never panic nor
+// decompress beyond the size of a container, and a container it accepts must be written back and read again.
+func FuzzReadContainer(f *testing.F) {
+	proof := signedProof()
+	for _, compression := range []Compression{Uncompressed, Gzip, Zstd} {
+		var container bytes.Buffer
+		if err := WriteContainer(&container, proof, compression); err != nil {
+			f.Fatal(err)
+		}
+		f.Add(container.Bytes())
+		f.Add(container.Bytes()[:container.Len()/2])
+	}
+	f.Add([]byte{})
+	f.Add(gzipMagic)
+	f.Add(zstdMagic)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, err := ReadContainer(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		var container bytes.Buffer
+		if err := WriteContainer(&container, decoded, Uncompressed); err != nil {
+			return
+		}
+		if _, err := ReadContainer(&container); err != nil {
+			t.Fatalf("a container was read, but not once written back: %v", err)
+		}
+	})
+}
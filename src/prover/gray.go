@@ -0,0 +1,198 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	gen "src/generator"
+	"src/hashsuite"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// A GrayImage is the artifact published with a grayscale image: the image, and a PCD proof that it is an area
+// of a grayscale capture the camera signed. Neither the capture nor its signature are part of the GrayImage,
+// only its capture counter and the area.
+type GrayImage struct {
+	image    myImage.Gray
+	nonce    *big.Int
+	area     map[string]int
+	pcdProof backend.Proof
+	circuit  myTransformations.CircuitID
+	backend  backend.ID
+}
+
+// Image returns the published grayscale image.
+func (gray GrayImage) Image() myImage.Gray {
+	return gray.image
+}
+
+// Nonce returns the capture counter of the grayscale capture.
+func (gray GrayImage) Nonce() *big.Int {
+	return gray.nonce
+}
+
+// Area returns the area {x0, y0, x1, y1} of the capture the image shows.
+func (gray GrayImage) Area() map[string]int {
+	return map[string]int{"x0": gray.area["x0"], "y0": gray.area["y0"], "x1": gray.area["x1"], "y1": gray.area["y1"]}
+}
+
+// PCDProof returns the PCD proof of the grayscale image.
+func (gray GrayImage) PCDProof() backend.Proof {
+	return gray.pcdProof
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (gray GrayImage) Circuit() myTransformations.CircuitID {
+	return gray.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (gray GrayImage) Backend() backend.ID {
+	return gray.backend
+}
+
+// ProveGray crops a grayscale capture to the area {x0, y0, x1, y1} of params and proves the cropped image;
+// the whole image is an area too. captureSignature is the camera's signature over capture for the capture
+// counter nonce, see image.Gray.Sign. pk_pp are keys created by the GrayGenerator for the camera.
+func ProveGray(pk_pp gen.PK_PP, capture myImage.Gray, captureSignature []byte, nonce *big.Int, params map[string]int, opts ...backend.ProveOption) (GrayImage, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return GrayImage{}, err
+	}
+	if pk_pp.Circuit != myTransformations.GrayCircuitID {
+		return GrayImage{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the GrayGenerator", pk_pp.Circuit, myTransformations.GrayCircuitID)
+	}
+	if err := myTransformations.CheckArea(params); err != nil {
+		return GrayImage{}, err
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return GrayImage{}, err
+	}
+
+	// Only the camera's signature over the capture ties the image to a picture it took
+	if nonce == nil || pk_pp.PublicKey == nil {
+		return GrayImage{}, fmt.Errorf("the grayscale capture carries no nonce, or the keys no camera")
+	}
+	captureBytes := capture.Digest()
+	isVerified, err := pk_pp.PublicKey.Verify(captureSignature, myImage.Statement(captureBytes, nonce, big.NewInt(0)), hashsuite.Default.New())
+	if err != nil || !isVerified {
+		return GrayImage{}, fmt.Errorf("the grayscale capture was not signed by the camera of the keys for capture %v", nonce)
+	}
+
+	// The published image keeps none of the capture's metadata, which Crop then sets to its size
+	published := capture
+	published.M = myImage.Metadata{Width: capture.M.Width, Height: capture.M.Height}
+	if err := published.Crop(params["x0"], params["y0"], params["x1"], params["y1"]); err != nil {
+		return GrayImage{}, err
+	}
+
+	var eddsa_signature eddsa.Signature
+	eddsa_signature.Assign(1, captureSignature)
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, pk_pp.PublicKey.Bytes())
+
+	circuit := myTransformations.GrayCircuit{
+		PublicKey:      eddsa_publicKey,
+		Nonce:          nonce,
+		ImageDigest:    myTransformations.GrayDigest(published),
+		Area:           myTransformations.Transformation{T: myTransformations.Crop, Params: params}.ToFr().Params,
+		ImageSignature: eddsa_signature,
+		ImageBytes:     captureBytes,
+		Metadata:       capture.MetadataDigest(),
+		Capture:        capture.ToFrontendGray(),
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return GrayImage{}, err
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pp.Circuit, &circuit)
+	if err != nil {
+		return GrayImage{}, err
+	}
+	pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		return GrayImage{}, err
+	}
+
+	area := map[string]int{"x0": params["x0"], "y0": params["y0"], "x1": params["x1"], "y1": params["y1"]}
+	return GrayImage{image: published, nonce: nonce, area: area, pcdProof: pcd_proof, circuit: pk_pp.Circuit, backend: b.ID()}, nil
+}
+
+// Version of the grayscale image encoding written by MarshalJSON.
+const GrayFormatVersion = 1
+
+// JSON encoding of a GrayImage, as published.
+type grayJSON struct {
+	Version  int                         `json:"version"`
+	Circuit  myTransformations.CircuitID `json:"circuit"`
+	Backend  backend.ID                  `json:"backend"`
+	Image    myImage.Gray                `json:"image"`
+	Nonce    string                      `json:"nonce"` // decimal
+	Area     map[string]int              `json:"area"`  // x0, y0, x1, y1
+	PCDProof []byte                      `json:"pcdProof"`
+}
+
+func (gray GrayImage) MarshalJSON() ([]byte, error) {
+	if gray.pcdProof == nil {
+		return nil, fmt.Errorf("the grayscale image carries no PCD proof")
+	}
+
+	pcd_proof := getBuffer()
+	defer putBuffer(pcd_proof)
+	if _, err := gray.pcdProof.WriteTo(pcd_proof); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(grayJSON{
+		Version:  GrayFormatVersion,
+		Circuit:  gray.circuit,
+		Backend:  gray.backend,
+		Image:    gray.image,
+		Nonce:    gray.nonce.String(),
+		Area:     gray.area,
+		PCDProof: pcd_proof.Bytes(),
+	})
+}
+
+func (gray *GrayImage) UnmarshalJSON(data []byte) error {
+	var decoded grayJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > GrayFormatVersion {
+		return fmt.Errorf("grayscale image format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this grayscale image", decoded.Version, GrayFormatVersion)
+	}
+
+	nonce, err := parseDecimal("nonce", decoded.Nonce)
+	if err != nil {
+		return err
+	}
+	if nonce == nil {
+		return fmt.Errorf("the grayscale image carries no nonce")
+	}
+	if err := myTransformations.CheckArea(decoded.Area); err != nil {
+		return err
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+
+	*gray = GrayImage{image: decoded.Image, nonce: nonce, area: decoded.Area, pcdProof: pcd_proof, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
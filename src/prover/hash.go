@@ -0,0 +1,52 @@
+package prover
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// ProofHash returns the hash of a PCD proof and its statement, as a BN254 field element.
+// It is the PrevProofHash of every proof created by editing the image of proof, so that the edit history
+// of an image is a hash chain: an edit cannot be presented as the edit of another proof, and proofs cannot
+// be reordered or dropped from the history without breaking the chain.
+//
+// The statement (public key, signature, nonce, hash of the previous proof and the public parameters of the
+// edit, if its circuit has any) is hashed rather than the public witness sent along, which the verifier does
+// not use. The hash is only ever computed outside the circuits, so it is SHA-256 rather than
+// hashsuite.Default, which can only hash field elements.
+func ProofHash(proof Proof) (*big.Int, error) {
+	if proof.pcdProof == nil {
+		return nil, fmt.Errorf("the proof carries no PCD proof")
+	}
+	if proof.z.PublicKey == nil || proof.nonce == nil || proof.prevProofHash == nil {
+		return nil, fmt.Errorf("the proof carries no statement: it was created by an older release")
+	}
+
+	h := sha256.New()
+	if _, err := proof.pcdProof.WriteTo(h); err != nil {
+		return nil, err
+	}
+	// The PCD proof is followed by fixed size fields only, as many parameters as its circuit has, so
+	// the encoding is unambiguous
+	h.Write(proof.z.PublicKey.Bytes())
+	h.Write(proof.imageSignature)
+	for _, value := range []*big.Int{proof.nonce, proof.prevProofHash} {
+		var element fr.Element
+		element.SetBigInt(value)
+		elementBytes := element.Bytes()
+		h.Write(elementBytes[:])
+	}
+	for _, param := range proof.circuit.EditParams() {
+		var element fr.Element
+		element.SetInt64(int64(proof.params[param.Name]))
+		elementBytes := element.Bytes()
+		h.Write(elementBytes[:])
+	}
+
+	hash := new(big.Int).SetBytes(h.Sum(nil))
+	return hash.Mod(hash, ecc.BN254.ScalarField()), nil
+}
@@ -0,0 +1,198 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+
+	"src/backend"
+	gen "src/generator"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// An HDR is the artifact published with an HDR merge: the merged image, and a PCD proof that it was merged from
+// bracketed exposures the camera signed. Neither the exposures nor their signatures are part of the HDR, only
+// their capture counters and weights.
+type HDR struct {
+	image    myImage.I
+	nonces   [myTransformations.HDRExposures]*big.Int
+	weights  [myTransformations.HDRExposures]int
+	pcdProof backend.Proof
+	circuit  myTransformations.CircuitID
+	backend  backend.ID
+}
+
+// Image returns the merged image.
+func (hdr HDR) Image() myImage.I {
+	return hdr.image
+}
+
+// Nonces returns the capture counters of the exposures.
+func (hdr HDR) Nonces() [myTransformations.HDRExposures]*big.Int {
+	return hdr.nonces
+}
+
+// Weights returns the weight of every exposure in the merge.
+func (hdr HDR) Weights() [myTransformations.HDRExposures]int {
+	return hdr.weights
+}
+
+// PCDProof returns the PCD proof of the merge.
+func (hdr HDR) PCDProof() backend.Proof {
+	return hdr.pcdProof
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (hdr HDR) Circuit() myTransformations.CircuitID {
+	return hdr.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (hdr HDR) Backend() backend.ID {
+	return hdr.backend
+}
+
+// ProveHDR merges the original images of exposures with weights and proves the merge. Every proof must carry
+// the camera's signature over an original image: a signed proof, or the PCD proof of an original image.
+// pk_pp are keys created by the HDRGenerator for the camera.
+func ProveHDR(pk_pp gen.PK_PP, exposures [myTransformations.HDRExposures]Proof, weights [myTransformations.HDRExposures]int, opts ...backend.ProveOption) (HDR, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return HDR{}, err
+	}
+	if pk_pp.Circuit != myTransformations.HDRCircuitID {
+		return HDR{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the HDRGenerator", pk_pp.Circuit, myTransformations.HDRCircuitID)
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return HDR{}, err
+	}
+
+	// Only the camera's signatures over original images make the merge one of pictures it took
+	var images [myTransformations.HDRExposures]myImage.I
+	var nonces [myTransformations.HDRExposures]*big.Int
+	for i, original := range exposures {
+		if len(original.imageSignature) == 0 || original.nonce == nil || original.prevProofHash == nil || original.prevProofHash.Sign() != 0 {
+			return HDR{}, fmt.Errorf("exposure %d: the proof is not of an original image signed by the camera", i)
+		}
+		if original.z.PublicKey == nil || !original.z.PublicKey.Equal(pk_pp.PublicKey) {
+			return HDR{}, fmt.Errorf("exposure %d: the original image was not signed by the camera of the keys", i)
+		}
+		for j := 0; j < i; j++ {
+			if nonces[j].Cmp(original.nonce) == 0 {
+				return HDR{}, fmt.Errorf("an HDR merge is of different captures, not capture %v twice", original.nonce)
+			}
+		}
+		images[i] = original.z.Image
+		nonces[i] = original.nonce
+	}
+
+	merged, err := myTransformations.MergeHDR(images, weights)
+	if err != nil {
+		return HDR{}, err
+	}
+
+	circuit := myTransformations.HDRCircuit{ImageDigest: myTransformations.RegionDigest(merged), Merged: merged.ToFrontendImage()}
+	circuit.PublicKey.Assign(1, pk_pp.PublicKey.Bytes())
+	for i, original := range exposures {
+		circuit.Nonces[i] = original.nonce
+		circuit.Weights[i] = weights[i]
+		circuit.Signatures[i].Assign(1, original.imageSignature)
+		circuit.ImageBytes[i] = original.z.Image.Digest()
+		circuit.Metadata[i] = original.z.Image.MetadataDigest()
+		circuit.Exposures[i] = original.z.Image.ToFrontendPacked()
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return HDR{}, err
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pp.Circuit, &circuit)
+	if err != nil {
+		return HDR{}, err
+	}
+	pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		return HDR{}, err
+	}
+
+	return HDR{image: merged, nonces: nonces, weights: weights, pcdProof: pcd_proof, circuit: pk_pp.Circuit, backend: b.ID()}, nil
+}
+
+// Version of the HDR encoding written by MarshalJSON.
+const HDRFormatVersion = 1
+
+// JSON encoding of an HDR, as published.
+type hdrJSON struct {
+	Version  int                                    `json:"version"`
+	Circuit  myTransformations.CircuitID            `json:"circuit"`
+	Backend  backend.ID                             `json:"backend"`
+	Image    myImage.I                              `json:"image"`
+	Nonces   [myTransformations.HDRExposures]string `json:"nonces"` // decimal
+	Weights  [myTransformations.HDRExposures]int    `json:"weights"`
+	PCDProof []byte                                 `json:"pcdProof"`
+}
+
+func (hdr HDR) MarshalJSON() ([]byte, error) {
+	if hdr.pcdProof == nil {
+		return nil, fmt.Errorf("the HDR merge carries no PCD proof")
+	}
+
+	pcd_proof := getBuffer()
+	defer putBuffer(pcd_proof)
+	if _, err := hdr.pcdProof.WriteTo(pcd_proof); err != nil {
+		return nil, err
+	}
+
+	encoded := hdrJSON{
+		Version:  HDRFormatVersion,
+		Circuit:  hdr.circuit,
+		Backend:  hdr.backend,
+		Image:    hdr.image,
+		Weights:  hdr.weights,
+		PCDProof: pcd_proof.Bytes(),
+	}
+	for i, nonce := range hdr.nonces {
+		encoded.Nonces[i] = nonce.String()
+	}
+	return json.Marshal(encoded)
+}
+
+func (hdr *HDR) UnmarshalJSON(data []byte) error {
+	var decoded hdrJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > HDRFormatVersion {
+		return fmt.Errorf("HDR format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this HDR merge", decoded.Version, HDRFormatVersion)
+	}
+
+	var nonces [myTransformations.HDRExposures]*big.Int
+	for i, value := range decoded.Nonces {
+		nonce, err := parseDecimal("nonces", value)
+		if err != nil {
+			return err
+		}
+		if nonce == nil {
+			return fmt.Errorf("the HDR merge carries no nonce for exposure %d", i)
+		}
+		nonces[i] = nonce
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+
+	*hdr = HDR{image: decoded.Image, nonces: nonces, weights: decoded.Weights, pcdProof: pcd_proof, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
@@ -0,0 +1,209 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	gen "src/generator"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// A Panorama is the artifact published with a panorama: the stitched image, and a PCD proof that it was
+// stitched from two pictures the camera signed. Neither the pictures nor their signatures are part of the
+// Panorama, only their capture counters.
+type Panorama struct {
+	image         myImage.I
+	leftNonce     *big.Int
+	rightNonce    *big.Int
+	seam, overlap int
+	pcdProof      backend.Proof
+	circuit       myTransformations.CircuitID
+	backend       backend.ID
+}
+
+// Image returns the stitched image.
+func (panorama Panorama) Image() myImage.I {
+	return panorama.image
+}
+
+// Nonces returns the capture counters of the left and right pictures.
+func (panorama Panorama) Nonces() (*big.Int, *big.Int) {
+	return panorama.leftNonce, panorama.rightNonce
+}
+
+// Seam returns the first column that only shows the right picture, and the number of columns before it that
+// blend both pictures.
+func (panorama Panorama) Seam() (int, int) {
+	return panorama.seam, panorama.overlap
+}
+
+// PCDProof returns the PCD proof of the panorama.
+func (panorama Panorama) PCDProof() backend.Proof {
+	return panorama.pcdProof
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (panorama Panorama) Circuit() myTransformations.CircuitID {
+	return panorama.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (panorama Panorama) Backend() backend.ID {
+	return panorama.backend
+}
+
+// ProvePanorama stitches the original images of left and right at seam, blended over overlap columns, and
+// proves the panorama. Both proofs must carry the camera's signature over an original image: a signed proof, or
+// the PCD proof of an original image. pk_pp are keys created by the PanoramaGenerator for the camera.
+func ProvePanorama(pk_pp gen.PK_PP, left, right Proof, seam, overlap int, opts ...backend.ProveOption) (Panorama, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return Panorama{}, err
+	}
+	if pk_pp.Circuit != myTransformations.PanoramaCircuitID {
+		return Panorama{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the PanoramaGenerator", pk_pp.Circuit, myTransformations.PanoramaCircuitID)
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return Panorama{}, err
+	}
+
+	// Only the camera's signatures over two original images make the panorama one of pictures it took
+	for _, original := range []Proof{left, right} {
+		if len(original.imageSignature) == 0 || original.nonce == nil || original.prevProofHash == nil || original.prevProofHash.Sign() != 0 {
+			return Panorama{}, fmt.Errorf("the proof is not of an original image signed by the camera")
+		}
+		if original.z.PublicKey == nil || !original.z.PublicKey.Equal(pk_pp.PublicKey) {
+			return Panorama{}, fmt.Errorf("the original image was not signed by the camera of the keys")
+		}
+	}
+	if left.nonce.Cmp(right.nonce) == 0 {
+		return Panorama{}, fmt.Errorf("a panorama is stitched from two different captures, not capture %v twice", left.nonce)
+	}
+
+	image, err := myTransformations.Stitch(left.z.Image, right.z.Image, seam, overlap)
+	if err != nil {
+		return Panorama{}, err
+	}
+
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, pk_pp.PublicKey.Bytes())
+	var left_signature, right_signature eddsa.Signature
+	left_signature.Assign(1, left.imageSignature)
+	right_signature.Assign(1, right.imageSignature)
+
+	circuit := myTransformations.PanoramaCircuit{
+		PublicKey:      eddsa_publicKey,
+		LeftNonce:      left.nonce,
+		RightNonce:     right.nonce,
+		ImageDigest:    myTransformations.RegionDigest(image),
+		Seam:           seam,
+		Overlap:        overlap,
+		LeftSignature:  left_signature,
+		RightSignature: right_signature,
+		LeftBytes:      left.z.Image.Digest(),
+		RightBytes:     right.z.Image.Digest(),
+		LeftMetadata:   left.z.Image.MetadataDigest(),
+		RightMetadata:  right.z.Image.MetadataDigest(),
+		Left:           left.z.Image.ToFrontendPacked(),
+		Right:          right.z.Image.ToFrontendPacked(),
+		Panorama:       image.ToFrontendImage(),
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return Panorama{}, err
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pp.Circuit, &circuit)
+	if err != nil {
+		return Panorama{}, err
+	}
+	pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		return Panorama{}, err
+	}
+
+	return Panorama{image: image, leftNonce: left.nonce, rightNonce: right.nonce, seam: seam, overlap: overlap, pcdProof: pcd_proof, circuit: pk_pp.Circuit, backend: b.ID()}, nil
+}
+
+// Version of the panorama encoding written by MarshalJSON.
+const PanoramaFormatVersion = 1
+
+// JSON encoding of a Panorama, as published.
+type panoramaJSON struct {
+	Version    int                         `json:"version"`
+	Circuit    myTransformations.CircuitID `json:"circuit"`
+	Backend    backend.ID                  `json:"backend"`
+	Image      myImage.I                   `json:"image"`
+	LeftNonce  string                      `json:"leftNonce"`  // decimal
+	RightNonce string                      `json:"rightNonce"` // decimal
+	Seam       int                         `json:"seam"`
+	Overlap    int                         `json:"overlap"`
+	PCDProof   []byte                      `json:"pcdProof"`
+}
+
+func (panorama Panorama) MarshalJSON() ([]byte, error) {
+	if panorama.pcdProof == nil {
+		return nil, fmt.Errorf("the panorama carries no PCD proof")
+	}
+
+	pcd_proof := getBuffer()
+	defer putBuffer(pcd_proof)
+	if _, err := panorama.pcdProof.WriteTo(pcd_proof); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(panoramaJSON{
+		Version:    PanoramaFormatVersion,
+		Circuit:    panorama.circuit,
+		Backend:    panorama.backend,
+		Image:      panorama.image,
+		LeftNonce:  panorama.leftNonce.String(),
+		RightNonce: panorama.rightNonce.String(),
+		Seam:       panorama.seam,
+		Overlap:    panorama.overlap,
+		PCDProof:   pcd_proof.Bytes(),
+	})
+}
+
+func (panorama *Panorama) UnmarshalJSON(data []byte) error {
+	var decoded panoramaJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > PanoramaFormatVersion {
+		return fmt.Errorf("panorama format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this panorama", decoded.Version, PanoramaFormatVersion)
+	}
+
+	leftNonce, err := parseDecimal("leftNonce", decoded.LeftNonce)
+	if err != nil {
+		return err
+	}
+	rightNonce, err := parseDecimal("rightNonce", decoded.RightNonce)
+	if err != nil {
+		return err
+	}
+	if leftNonce == nil || rightNonce == nil {
+		return fmt.Errorf("the panorama carries no nonces")
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+
+	*panorama = Panorama{image: decoded.Image, leftNonce: leftNonce, rightNonce: rightNonce, seam: decoded.Seam, overlap: decoded.Overlap, pcdProof: pcd_proof, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
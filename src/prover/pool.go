@@ -0,0 +1,36 @@
+package prover
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Encoding a Proof writes its PCD proof and public witness into scratch buffers, which are only needed
+// until the JSON encoding is done. A proving server encodes every proof it creates, so the buffers are
+// recycled across proofs instead of being reallocated for each one.
+//
+// Nothing else of a proof is recycled. Most of the scratch memory of a proof, its wire values and the buffers of
+// its MSMs and FFTs, is allocated inside gnark's Prove, which takes no buffers from its caller. The fr.Element
+// vectors of the witnesses cannot be recycled either: gnark allocates a new vector whenever a witness is filled
+// (witness.Fill) or its public part is taken (Witness.Public), and the public witness stays with the Proof.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// Buffers that grew beyond this are dropped rather than kept alive by the pool.
+const maxPooledBuffer = 1 << 20
+
+// getBuffer returns an empty buffer from the pool. Return it with putBuffer once its bytes are no longer used.
+func getBuffer() *bytes.Buffer {
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+// putBuffer returns a buffer to the pool.
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buffer)
+}
@@ -1,47 +1,211 @@
+// Package prover implements the Prover of PhotoProof, which turns a signed or proven image into a
+// PCD proof of a transformed image.
 package prover
 
 import (
 	"fmt"
+	"math/big"
+	"src/backend"
 	gen "src/generator"
 	myImage "src/image"
 
 	myTransformations "src/transformations"
 
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 
-	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/std/signature/eddsa"
 )
 
+// A Proof travels with an image z = {Image, PublicKey}. It carries either the camera's digital
+// signature over an original image, or a PCD proof (and its public witness) that the image was
+// derived from a signed image through permissible transformations.
+// Either way it holds for one nonce, and one place in the edit history of the image, only; see image.Statement.
 type Proof struct {
-	PCD_proof      groth16.Proof
-	Z              myImage.Z
-	ImageSignature []byte
-	Public_Witness witness.Witness
+	pcdProof       backend.Proof
+	z              myImage.Z
+	imageSignature []byte
+	nonce          *big.Int       // capture counter of the original image
+	prevProofHash  *big.Int       // ProofHash of the proof an edit was made from, 0 for an original image
+	nullifier      *big.Int       // Nullifier of the capture, see transformations.Nullifier
+	prevImageBytes []byte         // Digest of the image an edit was made from, of the image itself for an original image
+	params         map[string]int // public parameters of the edit, see transformations.PublicParams
+	publicWitness  witness.Witness
+	circuit        myTransformations.CircuitID // compliance predicate of the PCD proof
+	backend        backend.ID                  // proving system of the PCD proof
+}
+
+// NewSignedProof returns the Proof of an original image, carrying only the camera's digital signature
+// over the image and the camera's capture counter. Its Nullifier is derived from the camera's key and the
+// counter, and is nil if either is missing.
+func NewSignedProof(z myImage.Z, imageSignature []byte, counter *big.Int) Proof {
+	proof := Proof{z: z, imageSignature: imageSignature, nonce: counter, prevProofHash: big.NewInt(0)}
+	if z.PublicKey != nil && counter != nil {
+		proof.nullifier, _ = myTransformations.Nullifier(z.PublicKey.Bytes(), counter)
+	}
+	return proof
+}
+
+// PCDProof returns the PCD proof, or nil if this is an original image with a digital signature.
+func (proof Proof) PCDProof() backend.Proof {
+	return proof.pcdProof
+}
+
+// Z returns the image and public key this Proof is about.
+func (proof Proof) Z() myImage.Z {
+	return proof.z
+}
+
+// ImageSignature returns the digital signature over the image, nonce and hash of the previous proof: the
+// camera's for an original image, the Prover's for an edited one.
+func (proof Proof) ImageSignature() []byte {
+	return proof.imageSignature
+}
+
+// Nonce returns the nonce the Proof holds for: the camera's capture counter of the original image, which
+// every edit carries along. A verifier checks it against the capture it expects the proof of.
+func (proof Proof) Nonce() *big.Int {
+	return proof.nonce
+}
+
+// PrevProofHash returns the ProofHash of the proof this edit was made from, or 0 for an original image.
+// The chain verifier checks it against the previous proof of the edit history.
+func (proof Proof) PrevProofHash() *big.Int {
+	return proof.prevProofHash
+}
+
+// Nullifier returns the nullifier of the capture the Proof holds for: derived from the camera's key and
+// capture counter for an original image, and carried along by every edit. Every proof of the edit history of
+// a picture has the same one, so a contest or claims system detects the same capture submitted twice, under
+// different edits, see verifier.VerifySubmission.
+func (proof Proof) Nullifier() *big.Int {
+	return proof.nullifier
+}
+
+// PrevImageBytes returns the digest of the image the edit was made from, see image.I.Digest, or of the image
+// itself for an original image, or nil for proofs of predicates that do not BindImageBytes. The chain verifier
+// checks it against the image of the previous proof of the edit history.
+func (proof Proof) PrevImageBytes() []byte {
+	return proof.prevImageBytes
+}
+
+// Params returns the public parameters of the edit the PCD proof holds for, e.g. the delta of a Brightness, or
+// nil for edits without any, see transformations.PublicParams. They are part of the statement the verifier
+// checks, so a proof does not hold for other parameters than the ones it was created for.
+func (proof Proof) Params() map[string]int {
+	return proof.params
+}
+
+// PublicWitness returns the public witness of the PCD proof.
+func (proof Proof) PublicWitness() witness.Witness {
+	return proof.publicWitness
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (proof Proof) Circuit() myTransformations.CircuitID {
+	return proof.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (proof Proof) Backend() backend.ID {
+	return proof.backend
 }
 
 // A prover is able to run a transformation on an image and
 // if the proof_in is just a digital signature,
 //
-//	then create a groth16.Proof from the proving key (PK_PP.pk_PCD) and z_out
+//	then create a PCD proof from the proving key (PK_PP.pk_PCD) and z_out
 //
 // else
 //
-//	the
-func Prover(pk_pcd gen.PK_PP, verifyingKey groth16.VerifyingKey, proof_in Proof, t myTransformations.Transformation) Proof {
+//	verify proof_in, apply the transformation t to its image and create a PCD proof for the result.
+//
+// Proofs are created with the backend the proving key was generated for, configured by opts,
+// e.g. backend.WithWorkers to cap the CPUs a proof uses, backend.WithOutput to report elsewhere than
+// os.Stdout, or backend.WithContext to cancel it between its phases. An edit needs backend.WithEditorKey, to
+// sign its image with.
+func Prover(pk_pcd gen.PK_PP, verifyingKey backend.VerifyingKey, proof_in Proof, t myTransformations.Transformation, opts ...backend.ProveOption) Proof {
+	// The options are checked before anything is compiled, and tell where the Prover reports to
+	options, err := backend.NewProverOptions(opts...)
+	if err != nil {
+		fmt.Println("Error while creating Proof: \n" + err.Error() + "\n-----------------")
+		return Proof{}
+	}
+	out := options.Output
+	if options.Backend != "" && options.Backend != pk_pcd.Backend {
+		fmt.Fprintf(out, "Error while creating Proof: \nthe keys are for %s, not %s\n-----------------\n", pk_pcd.Backend, options.Backend)
+		return Proof{}
+	}
+	if err := options.Context.Err(); err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \nproving cancelled: "+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+
+	// The keys must have been generated for the current version of the compliance predicate
+	if err := myTransformations.CheckProvable(pk_pcd.Circuit); err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+
+	b, err := backend.Get(pk_pcd.Backend)
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+
+	// Neither a PCD proof nor a digital signature, e.g. the result of a failed transformation
+	if proof_in.pcdProof == nil && len(proof_in.imageSignature) == 0 {
+		fmt.Fprintln(out, "Error while creating Proof: \nproof_in carries neither a PCD proof nor a digital signature\n-----------------")
+		return Proof{}
+	}
+	if proof_in.nonce == nil || proof_in.prevProofHash == nil {
+		fmt.Fprintln(out, "Error while creating Proof: \nproof_in carries no nonce: sign the image for a capture counter, or prove it again\n-----------------")
+		return Proof{}
+	}
+	if proof_in.nullifier == nil {
+		fmt.Fprintln(out, "Error while creating Proof: \nproof_in carries no nullifier: prove the image again\n-----------------")
+		return Proof{}
+	}
+	// An edit proves the signature of the image it is made from, with the key of that image
+	if proof_in.pcdProof != nil && proof_in.z.PublicKey == nil {
+		fmt.Fprintln(out, "Error while creating Proof: \nproof_in carries no public key of its image: prove the image again\n-----------------")
+		return Proof{}
+	}
+	if err := proof_in.z.Image.Validate(); err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+
+	// Keys generated for a Policy prove each of its transformations, as the Policy that makes the same image
+	if pk_pcd.Circuit.Name == myTransformations.PolicyCircuitID.Name {
+		if t, err = myTransformations.PolicyOf(t); err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
+	}
+
+	// An edit is proven with the keys of its compliance predicate
+	id, err := t.Circuit()
+	if err != nil {
+		fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+		return Proof{}
+	}
+	if proof_in.pcdProof != nil && (id.Name != pk_pcd.Circuit.Name || id.Steps != pk_pcd.Circuit.Steps) {
+		fmt.Fprintf(out, "Error while creating Proof: \nthe keys are for circuit %s, not %s: create them with the Generator for the transformation\n-----------------\n", pk_pcd.Circuit, id)
+		return Proof{}
+	}
+
 	// Generate a non-compile compliance predicate
 	var compliance_predicate constraint.ConstraintSystem
 
 	// No PCD Proof yet; this is the original image + a digital signature.
-	if proof_in.PCD_proof == nil {
+	if proof_in.pcdProof == nil {
 		// Set circuit's public and secret fields
 		// Assign the eddsa_signature into an eddsa.Signature
 		var eddsa_signature eddsa.Signature
-		eddsa_signature.Assign(1, proof_in.ImageSignature)
+		eddsa_signature.Assign(1, proof_in.imageSignature)
 
 		// Assign publicKey to an eddsa.PublicKey
 		var eddsa_publicKey eddsa.PublicKey
@@ -52,74 +216,138 @@ func Prover(pk_pcd gen.PK_PP, verifyingKey groth16.VerifyingKey, proof_in Proof,
 
 		circuit.PublicKey = eddsa_publicKey
 		circuit.ImageSignature = eddsa_signature
-		circuit.ImageBytes = proof_in.Z.Image.ToBigEndian()
-		circuit.FrImage = proof_in.Z.Image.ToFrontendImage()
-		circuit.CroppedImage_in = proof_in.Z.Image.ToFrontendImage()
+		circuit.Nonce = proof_in.nonce
+		circuit.PrevProofHash = proof_in.prevProofHash
+		circuit.Nullifier = proof_in.nullifier
+		circuit.Hops = proof_in.z.Hops
+		circuit.ImageBytes = proof_in.z.Image.Digest()
+		circuit.Metadata = proof_in.z.Image.MetadataDigest()
+		circuit.PrevImageBytes = circuit.ImageBytes // The original image is its own previous image
+		circuit.PrevMetadata = circuit.Metadata
+		circuit.Prev = myTransformations.NewPrevSignature(pk_pcd.PublicKey.Bytes(), proof_in.imageSignature, proof_in.prevProofHash) // Signed by the camera too
+		circuit.Statement, err = myTransformations.StatementDigest(pk_pcd.PublicKey.Bytes(), proof_in.imageSignature, proof_in.nonce, proof_in.prevProofHash, proof_in.nullifier, proof_in.z.Hops, proof_in.z.Image.Digest(), proof_in.z.Image.Digest(), pk_pcd.PublicKey.Bytes())
+		if err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
+		circuit.FrImage = proof_in.z.Image.ToFrontendImage()
+		circuit.CroppedImage_in = proof_in.z.Image.ToFrontendImage()
 		circuit.Params = t.ToFr().Params
 
 		// Dereferencing the circuit into a frontend.Circuit
 		var frontendCircuit frontend.Circuit = &circuit
 
+		// The keys of a crop fix which of its parameters are public, and the keys of another edit than a crop
+		// prove the edit of their predicate that keeps the original image
+		if pk_pcd.Circuit.Name == myTransformations.CropCircuitID.Name {
+			if err := circuit.SetDisclosed(pk_pcd.Circuit); err != nil {
+				fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+				return Proof{}
+			}
+		} else {
+			statement := myTransformations.EditStatement{
+				Statement:      circuit.Statement,
+				PublicKey:      circuit.PublicKey,
+				ImageSignature: circuit.ImageSignature,
+				Nonce:          circuit.Nonce,
+				PrevProofHash:  circuit.PrevProofHash,
+				Nullifier:      circuit.Nullifier,
+				Hops:           circuit.Hops,
+				ImageBytes:     circuit.ImageBytes,
+				Metadata:       circuit.Metadata,
+				PrevImageBytes: circuit.PrevImageBytes,
+				PrevMetadata:   circuit.PrevMetadata,
+				Prev:           circuit.Prev,
+			}
+			frontendCircuit, err = myTransformations.EditAssignment(pk_pcd.Circuit, myTransformations.Transformation{T: myTransformations.Identity}, proof_in.z.Image, proof_in.z.Image, statement)
+			if err != nil {
+				fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+				return Proof{}
+			}
+		}
+
+		backend.Report(opts, backend.PhaseSolving)
 		// Construct the secret_witness BEFORE compiling
 		secret_witness, err := frontend.NewWitness(frontendCircuit, ecc.BN254.ScalarField())
 		if err != nil {
-			fmt.Println("Error while creating Witness: \n" + err.Error() + "\n-----------------")
+			fmt.Fprintln(out, "Error while creating Witness: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
+
+		// A cancelled proof gives up before it compiles, and the backend checks the context again before proving
+		if err := options.Context.Err(); err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \nproving cancelled: "+err.Error()+"\n-----------------")
+			return Proof{}
 		}
 
+		backend.Report(opts, backend.PhaseCompiling)
+
 		// When compiling a compliance_predicate (aka constraint system) in Gnark, we require:
 		//        - elliptic curve (the security parameter of the bn254 curve has 254-bit prime number, 128-bit security)
-		// 		  - R1CS builder (i.e. a frontend.builder interface)
+		// 		  - a builder for the backend's constraint system (i.e. a frontend.builder interface)
 		//        - a specific circuit (i.e. a circuit that has already undergone the NewWitness() function)
-		compliance_predicate, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, frontendCircuit)
+		compliance_predicate, err = gen.Compile(b, pk_pcd.Circuit, frontendCircuit)
 		if err != nil {
-			fmt.Println(err.Error())
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
 		}
 
 		// use the witness directly in zk-SNARK backend APIs to create a proof_out
-		proof_out, err := groth16.Prove(compliance_predicate, pk_pcd.ProvingKey, secret_witness)
+		proof_out, err := b.Prove(compliance_predicate, pk_pcd.ProvingKey, secret_witness, opts...)
 		if err != nil {
-			fmt.Println("Error while creating Proof: \n" + err.Error() + "\n-----------------")
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
 		}
 
 		// Create public witness
 		publicWitness, err := secret_witness.Public()
 		if err != nil {
-			fmt.Println("Error while creating Public Witness: \n" + err.Error() + "\n-----------------")
+			fmt.Fprintln(out, "Error while creating Public Witness: \n"+err.Error()+"\n-----------------")
+			return Proof{}
 		}
 
-		return Proof{PCD_proof: proof_out, Z: proof_in.Z, ImageSignature: proof_in.ImageSignature, Public_Witness: publicWitness}
+		params := myTransformations.PublicParams(pk_pcd.Circuit, myTransformations.Transformation{T: myTransformations.Identity})
+		return Proof{pcdProof: proof_out, z: proof_in.z, imageSignature: proof_in.imageSignature, nonce: proof_in.nonce, prevProofHash: proof_in.prevProofHash, nullifier: proof_in.nullifier, prevImageBytes: proof_in.z.Image.Digest(), params: params, publicWitness: publicWitness, circuit: pk_pcd.Circuit, backend: b.ID()}
 	} else if t.T == myTransformations.Crop || t.T == myTransformations.Identity {
 
+		// An Identity is converted into a crop of the whole image
 		frT := t.ToFr()
 
-		// If the transformation is identity, then set the params accordingly
-		if t.T == myTransformations.Identity {
-			frT.Params.X0 = 0
-			frT.Params.Y0 = 0
-			frT.Params.X1 = myImage.N - 1
-			frT.Params.Y1 = myImage.N - 1
-		}
-
 		// Verify the PCD proof.
-		err := groth16.Verify(proof_in.PCD_proof, verifyingKey, proof_in.Public_Witness)
+		err = b.Verify(proof_in.pcdProof, verifyingKey, proof_in.publicWitness)
 		if err != nil {
 			// Invalid proof.
-			fmt.Println("FAIL: Image did not pass verification against PCD Proof.")
+			fmt.Fprintln(out, "FAIL: Image did not pass verification against PCD Proof.")
+			return Proof{}
 		} else {
 			// Valid proof.
-			fmt.Println("SUCCESS: Image verified against PCD Proof.")
+			fmt.Fprintln(out, "SUCCESS: Image verified against PCD Proof.")
 		}
 
 		// Record the z_in
-		z_in := proof_in.Z
+		z_in := proof_in.z
 
-		// Crop the image, using the parameters
-		proof_in.Z.Image.Crop(frT.Params.X0.(int), frT.Params.Y0.(int), frT.Params.X1.(int), frT.Params.Y1.(int))
+		// Apply the transformation to z_in's image, which returns a new image that shares nothing with z_in
+		image_out, err := t.Apply(z_in.Image)
+		if err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
 
-		// Sign image_out
-		normalSignature, publicKey, _, big_endian_bytes_Image := gen.Sign(proof_in.Z.Image)
+		// Sign image_out, for the capture and the proof it was edited from
+		prevProofHash, err := ProofHash(proof_in)
+		if err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
+		normalSignature, publicKey, big_endian_bytes_Image, err := signEdit(options, image_out, proof_in.nonce, prevProofHash)
+		if err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
 
-		z_out := myImage.Z{Image: proof_in.Z.Image, PublicKey: publicKey}
+		// The edit is one hop past the proof it was made from
+		z_out := myImage.Z{Image: image_out, PublicKey: publicKey, Hops: z_in.Hops + 1}
 
 		// Assign the eddsa_signature into an eddsa.Signature
 		var eddsa_signature eddsa.Signature
@@ -129,48 +357,85 @@ func Prover(pk_pcd gen.PK_PP, verifyingKey groth16.VerifyingKey, proof_in Proof,
 		var eddsa_publicKey eddsa.PublicKey
 		eddsa_publicKey.Assign(1, publicKey.Bytes())
 
+		// The statement of the proof is its single public input, along with the disclosed parameters
+		statementDigest, err := myTransformations.StatementDigest(publicKey.Bytes(), normalSignature, proof_in.nonce, prevProofHash, proof_in.nullifier, z_out.Hops, big_endian_bytes_Image, z_in.Image.Digest(), z_in.PublicKey.Bytes())
+		if err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
+
 		// Create the CropCiruit
 		circuit := myTransformations.CropCircuit{
-			PublicKey:       eddsa_publicKey,        // This is done redundantly to handle the final assert
-			ImageSignature:  eddsa_signature,        // This is done redundantly
+			Statement:       statementDigest,
+			PublicKey:       eddsa_publicKey, // This is done redundantly to handle the final assert
+			ImageSignature:  eddsa_signature, // This is done redundantly
+			Nonce:           proof_in.nonce,
+			PrevProofHash:   prevProofHash,
+			Nullifier:       proof_in.nullifier, // The edit carries the nullifier of the capture along
+			Hops:            z_out.Hops,
 			ImageBytes:      big_endian_bytes_Image, // This is done redundantly
+			Metadata:        z_out.Image.MetadataDigest(),
+			PrevImageBytes:  z_in.Image.Digest(), // The image of proof_in
+			PrevMetadata:    z_in.Image.MetadataDigest(),
+			Prev:            myTransformations.NewPrevSignature(z_in.PublicKey.Bytes(), proof_in.imageSignature, proof_in.prevProofHash), // The signature of proof_in
 			FrImage:         z_in.Image.ToFrontendImage(),
 			CroppedImage_in: z_out.Image.ToFrontendImage(),
 			Params:          frT.Params,
 		}
 
+		// The keys of the crop fix which of its parameters are public
+		if err := circuit.SetDisclosed(pk_pcd.Circuit); err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
+
 		// Dereferencing the circuit into a frontend.Circuit
 		var frontendCircuit frontend.Circuit = &circuit
 
+		backend.Report(opts, backend.PhaseSolving)
 		// Construct the secret_witness BEFORE compiling
 		secret_witness, err := frontend.NewWitness(frontendCircuit, ecc.BN254.ScalarField())
 		if err != nil {
-			fmt.Println("Error while creating Witness: \n" + err.Error() + "\n-----------------")
+			fmt.Fprintln(out, "Error while creating Witness: \n"+err.Error()+"\n-----------------")
+			return Proof{}
+		}
+
+		// A cancelled proof gives up before it compiles, and the backend checks the context again before proving
+		if err := options.Context.Err(); err != nil {
+			fmt.Fprintln(out, "Error while creating Proof: \nproving cancelled: "+err.Error()+"\n-----------------")
+			return Proof{}
 		}
 
+		backend.Report(opts, backend.PhaseCompiling)
+
 		// When compiling a compliance_predicate (aka constraint system) in Gnark, we require:
 		//        - elliptic curve (the security parameter of the bn254 curve has 254-bit prime number, 128-bit security)
-		// 		  - R1CS builder (i.e. a frontend.builder interface)
+		// 		  - a builder for the backend's constraint system (i.e. a frontend.builder interface)
 		//        - a specific circuit (i.e. a circuit that has already undergone the NewWitness() function)
-		compliance_predicate, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, frontendCircuit)
+		compliance_predicate, err = gen.Compile(b, pk_pcd.Circuit, frontendCircuit)
 		if err != nil {
-			fmt.Println(err.Error())
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
 		}
 
 		// use the witness directly in zk-SNARK backend APIs to create a proof_out
-		proof_out, err := groth16.Prove(compliance_predicate, pk_pcd.ProvingKey, secret_witness)
+		proof_out, err := b.Prove(compliance_predicate, pk_pcd.ProvingKey, secret_witness, opts...)
 		if err != nil {
-			fmt.Println("Error while creating Proof: \n" + err.Error() + "\n-----------------")
+			fmt.Fprintln(out, "Error while creating Proof: \n"+err.Error()+"\n-----------------")
+			return Proof{}
 		}
 
 		// Create public witness
 		publicWitness, err := secret_witness.Public()
 		if err != nil {
-			fmt.Println("Error while creating Public Witness: \n" + err.Error() + "\n-----------------")
+			fmt.Fprintln(out, "Error while creating Public Witness: \n"+err.Error()+"\n-----------------")
+			return Proof{}
 		}
 
-		return Proof{PCD_proof: proof_out, Z: z_out, Public_Witness: publicWitness}
+		params := myTransformations.PublicParams(pk_pcd.Circuit, t)
+		return Proof{pcdProof: proof_out, z: z_out, imageSignature: normalSignature, nonce: proof_in.nonce, prevProofHash: prevProofHash, nullifier: proof_in.nullifier, prevImageBytes: z_in.Image.Digest(), params: params, publicWitness: publicWitness, circuit: pk_pcd.Circuit, backend: b.ID()}
 	}
 
-	return Proof{}
+	// Any other edit, e.g. a Rotate, is proven with the compliance predicate of its own
+	return proveEdit(b, pk_pcd, verifyingKey, proof_in, t, options, opts...)
 }
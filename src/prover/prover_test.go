@@ -0,0 +1,46 @@
+package prover
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"src/backend"
+	gen "src/generator"
+	myTransformations "src/transformations"
+)
+
+// The Prover reports to the output of its options, and checks them before proving anything.
+func TestProverOptions(t *testing.T) {
+	pk_pp := gen.PK_PP{Circuit: myTransformations.CropCircuitID, Backend: backend.Groth16}
+	identity := myTransformations.Transformation{T: myTransformations.Identity}
+
+	var output bytes.Buffer
+	proof := Prover(pk_pp, nil, signedProof(), identity, backend.WithBackend(backend.PLONK), backend.WithOutput(&output))
+	if proof.PCDProof() != nil {
+		t.Error("proved with groth16 keys asserted to be plonk keys")
+	}
+	if !strings.Contains(output.String(), "the keys are for groth16, not plonk") {
+		t.Errorf("unexpected output %q", output.String())
+	}
+
+	output.Reset()
+	if proof := Prover(pk_pp, nil, Proof{}, identity, backend.WithOutput(&output)); proof.PCDProof() != nil {
+		t.Error("proved an image without a signature")
+	}
+	if !strings.Contains(output.String(), "neither a PCD proof nor a digital signature") {
+		t.Errorf("unexpected output %q", output.String())
+	}
+
+	// A cancelled proof gives up before verifying or compiling anything
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	output.Reset()
+	if proof := Prover(pk_pp, nil, signedProof(), identity, backend.WithContext(ctx), backend.WithOutput(&output)); proof.PCDProof() != nil {
+		t.Error("proved with a cancelled context")
+	}
+	if !strings.Contains(output.String(), "proving cancelled") {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
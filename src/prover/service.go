@@ -0,0 +1,131 @@
+package prover
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"src/backend"
+	gen "src/generator"
+	myTransformations "src/transformations"
+)
+
+// A Service is a long-lived Prover for one set of keys, for servers that create proofs on request: it holds
+// the keys in memory, and a pool of workers creates the proofs submitted to it. The compliance predicate of the
+// keys is compiled by the first proof and reused by the next ones, see generator.Compile, so no request
+// reloads keys or recompiles the predicate.
+//
+// A Service is safe for concurrent use. Close it to stop its workers.
+type Service struct {
+	pk_pcd       gen.PK_PP
+	verifyingKey backend.VerifyingKey
+	opts         []backend.ProveOption
+	options      backend.ProverOptions
+
+	queue      chan *Ticket
+	workers    sync.WaitGroup
+	mu         sync.Mutex // guards closed, and adding to submitting
+	closed     bool
+	stop       chan struct{}  // closed by Close, to give up the Submits waiting for room in the queue
+	submitting sync.WaitGroup // Submits sending to queue, which Close waits for before closing it
+	outputMu   sync.Mutex
+}
+
+// A Ticket is a request submitted to a Service, whose result Wait returns once its proof is done.
+type Ticket struct {
+	request ProofRequest
+	done    chan struct{}
+	result  ProofResult
+}
+
+// NewService returns a Service proving with the keys pk_pcd, and the verifying key of the proofs it is asked
+// to edit, with workers proofs created at once, at least one. opts configure every proof, before the options
+// of each request; the output of each proof is written to the output of opts in one piece once it is done.
+func NewService(pk_pcd gen.PK_PP, verifyingKey backend.VerifyingKey, workers int, opts ...backend.ProveOption) (*Service, error) {
+	options, err := backend.NewProverOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := myTransformations.CheckProvable(pk_pcd.Circuit); err != nil {
+		return nil, err
+	}
+	if _, err := backend.Get(pk_pcd.Backend); err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	service := &Service{pk_pcd: pk_pcd, verifyingKey: verifyingKey, opts: opts, options: options, queue: make(chan *Ticket, workers), stop: make(chan struct{})}
+	service.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go service.work()
+	}
+	return service, nil
+}
+
+// work proves the tickets of the queue until it is closed.
+func (service *Service) work() {
+	defer service.workers.Done()
+	for ticket := range service.queue {
+		ticket.result = proveRequest(service.pk_pcd, service.verifyingKey, ticket.request, service.opts, service.options.Output, &service.outputMu)
+		close(ticket.done)
+	}
+}
+
+// Submit queues request, and returns the Ticket to wait for its result with. It blocks while the queue is full,
+// i.e. while as many requests as there are workers wait for one, and fails once the Service is closed or the
+// context of the request, see backend.WithContext, is done.
+func (service *Service) Submit(request ProofRequest) (*Ticket, error) {
+	service.mu.Lock()
+	if service.closed {
+		service.mu.Unlock()
+		return nil, fmt.Errorf("the prover service is closed")
+	}
+	service.submitting.Add(1)
+	service.mu.Unlock()
+	defer service.submitting.Done()
+
+	ctx := context.Background()
+	if options, err := backend.NewProverOptions(append(service.opts[:len(service.opts):len(service.opts)], request.Options...)...); err == nil {
+		ctx = options.Context
+	}
+
+	// The queue is sent to without holding mu, so a full queue blocks neither Close nor the other Submits
+	ticket := &Ticket{request: request, done: make(chan struct{})}
+	select {
+	case service.queue <- ticket:
+		return ticket, nil
+	case <-service.stop:
+		return nil, fmt.Errorf("the prover service is closed")
+	case <-ctx.Done():
+		return nil, fmt.Errorf("submitting the request cancelled: %w", ctx.Err())
+	}
+}
+
+// Close stops accepting requests, and returns once the requests already submitted are proven.
+func (service *Service) Close() {
+	service.mu.Lock()
+	if !service.closed {
+		service.closed = true
+		close(service.stop)
+		service.mu.Unlock()
+		// No Submit sends to the queue anymore once those in progress gave up or are done
+		service.submitting.Wait()
+		close(service.queue)
+	} else {
+		service.mu.Unlock()
+	}
+	service.workers.Wait()
+}
+
+// Wait blocks until the proof of the ticket is done, and returns its result.
+func (ticket *Ticket) Wait() ProofResult {
+	<-ticket.done
+	return ticket.result
+}
+
+// Done returns a channel that is closed once the proof of the ticket is done, to wait for it in a select.
+func (ticket *Ticket) Done() <-chan struct{} {
+	return ticket.done
+}
@@ -0,0 +1,102 @@
+package prover
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"src/backend"
+	gen "src/generator"
+	myTransformations "src/transformations"
+)
+
+// A Service proves the requests submitted to it with the options of each, until it is closed.
+func TestService(t *testing.T) {
+	pk_pp := gen.PK_PP{Circuit: myTransformations.CropCircuitID, Backend: backend.Groth16}
+	identity := myTransformations.Transformation{T: myTransformations.Identity}
+
+	if _, err := NewService(gen.PK_PP{Circuit: myTransformations.CircuitID{Name: "crop", Version: 1}, Backend: backend.Groth16}, nil, 2); err == nil {
+		t.Error("created a service with keys of an old circuit")
+	}
+	if _, err := NewService(pk_pp, nil, 2, backend.WithWorkers(0)); err == nil {
+		t.Error("created a service with invalid options")
+	}
+
+	var output bytes.Buffer
+	service, err := NewService(pk_pp, nil, 2, backend.WithOutput(&output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tickets []*Ticket
+	for _, request := range []ProofRequest{
+		{Proof: Proof{}, T: identity},
+		{Proof: signedProof(), T: identity, Options: []backend.ProveOption{backend.WithBackend(backend.PLONK)}},
+		{Proof: Proof{}, T: identity},
+	} {
+		ticket, err := service.Submit(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tickets = append(tickets, ticket)
+	}
+	for i, reason := range []string{"neither a PCD proof nor a digital signature", "the keys are for groth16, not plonk", "neither a PCD proof nor a digital signature"} {
+		<-tickets[i].Done()
+		if result := tickets[i].Wait(); result.Proof.PCDProof() != nil || result.Err == nil || !strings.Contains(result.Err.Error(), reason) {
+			t.Errorf("request %d: unexpected result %v", i, result.Err)
+		}
+	}
+
+	service.Close()
+	service.Close()
+	if _, err := service.Submit(ProofRequest{Proof: Proof{}, T: identity}); err == nil {
+		t.Error("submitted a request to a closed service")
+	}
+	if strings.Count(output.String(), "-----------------\n") != len(tickets) {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
+
+// A Submit waiting for room in a full queue neither blocks Close nor outlives it, and gives up once the context
+// of its request is done.
+func TestServiceFullQueue(t *testing.T) {
+	identity := myTransformations.Transformation{T: myTransformations.Identity}
+
+	// A service without workers, whose queue is full after one request
+	service := &Service{queue: make(chan *Ticket, 1), stop: make(chan struct{})}
+	if _, err := service.Submit(ProofRequest{Proof: Proof{}, T: identity}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.Submit(ProofRequest{Proof: Proof{}, T: identity, Options: []backend.ProveOption{backend.WithContext(ctx)}}); err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	errs := make(chan error)
+	go func() {
+		_, err := service.Submit(ProofRequest{Proof: Proof{}, T: identity})
+		errs <- err
+	}()
+	closed := make(chan struct{})
+	go func() {
+		service.Close()
+		close(closed)
+	}()
+
+	select {
+	case err := <-errs:
+		if err == nil || !strings.Contains(err.Error(), "closed") {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("a submit to a full queue outlived Close")
+	}
+	select {
+	case <-closed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("a submit to a full queue blocked Close")
+	}
+}
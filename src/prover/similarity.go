@@ -0,0 +1,210 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	gen "src/generator"
+	myImage "src/image"
+	myTransformations "src/transformations"
+)
+
+// A Similarity is the artifact published with an image that is not exactly the one the camera signed, e.g.
+// after recompression: the image, and a PCD proof that it is within Bound, in Norm, of a picture the camera
+// signed. Neither the original nor its signature are part of the Similarity.
+type Similarity struct {
+	image    myImage.I
+	nonce    *big.Int
+	norm     myTransformations.Norm
+	bound    *big.Int
+	pcdProof backend.Proof
+	circuit  myTransformations.CircuitID
+	backend  backend.ID
+}
+
+// Image returns the published image.
+func (similarity Similarity) Image() myImage.I {
+	return similarity.image
+}
+
+// Nonce returns the capture counter of the original image.
+func (similarity Similarity) Nonce() *big.Int {
+	return similarity.nonce
+}
+
+// Norm returns the norm the distance to the original is measured in.
+func (similarity Similarity) Norm() myTransformations.Norm {
+	return similarity.norm
+}
+
+// Bound returns the largest distance to the original the proof accepts.
+func (similarity Similarity) Bound() *big.Int {
+	return similarity.bound
+}
+
+// PCDProof returns the PCD proof of the similarity.
+func (similarity Similarity) PCDProof() backend.Proof {
+	return similarity.pcdProof
+}
+
+// Circuit returns the compliance predicate the PCD proof was created with.
+func (similarity Similarity) Circuit() myTransformations.CircuitID {
+	return similarity.circuit
+}
+
+// Backend returns the proving system the PCD proof was created with.
+func (similarity Similarity) Backend() backend.ID {
+	return similarity.backend
+}
+
+// ProveSimilarity proves that published is within bound, in norm, of the original image of proof, which must
+// carry the camera's signature: a signed proof, or the PCD proof of an original image. pk_pp are keys created
+// by the SimilarityGenerator for the camera.
+func ProveSimilarity(pk_pp gen.PK_PP, original Proof, published myImage.I, norm myTransformations.Norm, bound *big.Int, opts ...backend.ProveOption) (Similarity, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return Similarity{}, err
+	}
+	if pk_pp.Circuit != myTransformations.SimilarityCircuitID {
+		return Similarity{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the SimilarityGenerator", pk_pp.Circuit, myTransformations.SimilarityCircuitID)
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return Similarity{}, err
+	}
+
+	// Only the camera's signature over an original image makes the published image close to a picture it took
+	if len(original.imageSignature) == 0 || original.nonce == nil || original.prevProofHash == nil || original.prevProofHash.Sign() != 0 {
+		return Similarity{}, fmt.Errorf("the proof is not of an original image signed by the camera")
+	}
+	if original.z.PublicKey == nil || !original.z.PublicKey.Equal(pk_pp.PublicKey) {
+		return Similarity{}, fmt.Errorf("the original image was not signed by the camera of the keys")
+	}
+	if err := myTransformations.CheckSimilarity(original.z.Image, published, norm, bound); err != nil {
+		return Similarity{}, err
+	}
+
+	var eddsa_signature eddsa.Signature
+	eddsa_signature.Assign(1, original.imageSignature)
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, pk_pp.PublicKey.Bytes())
+
+	circuit := myTransformations.SimilarityCircuit{
+		PublicKey:      eddsa_publicKey,
+		Nonce:          original.nonce,
+		ImageDigest:    myTransformations.RegionDigest(published),
+		Norm:           int(norm),
+		Bound:          bound,
+		ImageSignature: eddsa_signature,
+		ImageBytes:     original.z.Image.Digest(),
+		Metadata:       original.z.Image.MetadataDigest(),
+		Original:       original.z.Image.ToFrontendPacked(),
+		Published:      published.ToFrontendImage(),
+	}
+
+	backend.Report(opts, backend.PhaseSolving)
+	secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return Similarity{}, err
+	}
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pp.Circuit, &circuit)
+	if err != nil {
+		return Similarity{}, err
+	}
+	pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+	if err != nil {
+		return Similarity{}, err
+	}
+
+	return Similarity{
+		image:    published,
+		nonce:    original.nonce,
+		norm:     norm,
+		bound:    new(big.Int).Set(bound),
+		pcdProof: pcd_proof,
+		circuit:  pk_pp.Circuit,
+		backend:  b.ID(),
+	}, nil
+}
+
+// Version of the similarity encoding written by MarshalJSON.
+const SimilarityFormatVersion = 1
+
+// JSON encoding of a Similarity, as published.
+type similarityJSON struct {
+	Version  int                         `json:"version"`
+	Circuit  myTransformations.CircuitID `json:"circuit"`
+	Backend  backend.ID                  `json:"backend"`
+	Image    myImage.I                   `json:"image"`
+	Nonce    string                      `json:"nonce"` // decimal
+	Norm     string                      `json:"norm"`  // L1 or L2
+	Bound    string                      `json:"bound"` // decimal
+	PCDProof []byte                      `json:"pcdProof"`
+}
+
+func (similarity Similarity) MarshalJSON() ([]byte, error) {
+	if similarity.pcdProof == nil {
+		return nil, fmt.Errorf("the similarity carries no PCD proof")
+	}
+
+	pcd_proof := getBuffer()
+	defer putBuffer(pcd_proof)
+	if _, err := similarity.pcdProof.WriteTo(pcd_proof); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(similarityJSON{
+		Version:  SimilarityFormatVersion,
+		Circuit:  similarity.circuit,
+		Backend:  similarity.backend,
+		Image:    similarity.image,
+		Nonce:    similarity.nonce.String(),
+		Norm:     similarity.norm.String(),
+		Bound:    similarity.bound.String(),
+		PCDProof: pcd_proof.Bytes(),
+	})
+}
+
+func (similarity *Similarity) UnmarshalJSON(data []byte) error {
+	var decoded similarityJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > SimilarityFormatVersion {
+		return fmt.Errorf("similarity format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this similarity", decoded.Version, SimilarityFormatVersion)
+	}
+
+	nonce, err := parseDecimal("nonce", decoded.Nonce)
+	if err != nil {
+		return err
+	}
+	bound, err := parseDecimal("bound", decoded.Bound)
+	if err != nil {
+		return err
+	}
+	if nonce == nil || bound == nil {
+		return fmt.Errorf("the similarity carries no nonce or bound")
+	}
+	norm, err := myTransformations.ParseNorm(decoded.Norm)
+	if err != nil {
+		return err
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+
+	*similarity = Similarity{image: decoded.Image, nonce: nonce, norm: norm, bound: bound, pcdProof: pcd_proof, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
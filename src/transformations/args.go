@@ -0,0 +1,492 @@
+package transformations
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	myImage "src/image"
+)
+
+// Args are the typed parameters of a type of transformation, e.g. the CropArgs of a Crop, which New turns into
+// a Transformation. The keys of the Params of a Transformation are strings, and a misspelled one reads as 0;
+// the fields of Args are checked by the compiler, and their values by New.
+type Args interface {
+	// Type returns the type of transformation the arguments are for.
+	Type() int
+	// Validate returns an error if the arguments are out of the range of the transformation, whatever the
+	// image it transforms; Transformation.Apply checks the rest against the image.
+	Validate() error
+	// Params returns the arguments as the Params of a Transformation.
+	Params() map[string]int
+}
+
+// New returns the Transformation of args, or an error if they are invalid.
+func New(args Args) (Transformation, error) {
+	if err := args.Validate(); err != nil {
+		return Transformation{}, err
+	}
+	return Transformation{T: args.Type(), Params: args.Params()}, nil
+}
+
+// Args of every type of transformation with keys of its own, zero valued, by type: their Params are the keys
+// of the type. The keys of a Chain are those of its steps, see ChainOf.
+var typeArgs = map[int]Args{
+	Identity:      IdentityArgs{},
+	Crop:          CropArgs{},
+	Rotate:        RotateArgs{},
+	FlipH:         FlipHArgs{},
+	FlipV:         FlipVArgs{},
+	Downscale:     DownscaleArgs{},
+	RotateCrop:    RotateCropArgs{},
+	Brightness:    BrightnessArgs{},
+	Gamma:         GammaArgs{},
+	Sepia:         SepiaArgs{},
+	HueSaturation: HueSaturationArgs{},
+	Gain:          GainArgs{},
+	Threshold:     ThresholdArgs{},
+	ChannelSwap:   ChannelSwapArgs{},
+	Redact:        RedactArgs{},
+	Mosaic:        MosaicArgs{},
+	Blur:          BlurArgs{},
+	Sharpen:       SharpenArgs{},
+	Watermark:     WatermarkArgs{},
+	Caption:       CaptionArgs{},
+	Border:        BorderArgs{},
+	Convolution:   ConvolutionArgs{},
+	Policy:        PolicyArgs{},
+	Credit:        CreditArgs{},
+}
+
+// CheckParams returns an error if the Params of t are not exactly the keys of its type: one it misses, or one
+// it does not have, e.g. a misspelled one. The parameters of an Identity are ignored, and so are those of
+// types registered without Args; those of a Chain are checked against its steps.
+func (t Transformation) CheckParams() error {
+	if t.T == Chain {
+		_, err := chainStepsOf(t.Params)
+		return err
+	}
+	args, ok := typeArgs[t.T]
+	if !ok || t.T == Identity {
+		return nil
+	}
+	keys := args.Params()
+	for key := range t.Params {
+		if _, ok := keys[key]; !ok {
+			return fmt.Errorf("unknown parameter %q of a transformation of type %d: expected %v", key, t.T, slices.Sorted(maps.Keys(keys)))
+		}
+	}
+	for key := range keys {
+		if _, ok := t.Params[key]; !ok {
+			return fmt.Errorf("missing parameter %q of a transformation of type %d", key, t.T)
+		}
+	}
+	return nil
+}
+
+// The parameters of an area or a region, {x0, y0, x1, y1}.
+func rectParams(rect myImage.Rect) map[string]int {
+	return map[string]int{"x0": rect.X0, "y0": rect.Y0, "x1": rect.X1, "y1": rect.Y1}
+}
+
+// IdentityArgs keep the image.
+type IdentityArgs struct{}
+
+func (IdentityArgs) Type() int              { return Identity }
+func (IdentityArgs) Validate() error        { return nil }
+func (IdentityArgs) Params() map[string]int { return nil }
+
+// CropArgs crop an image to Area.
+type CropArgs struct {
+	Area myImage.Rect
+}
+
+func (CropArgs) Type() int { return Crop }
+
+func (args CropArgs) Validate() error {
+	return args.Area.Validate(myImage.Width, myImage.Height)
+}
+
+func (args CropArgs) Params() map[string]int {
+	return rectParams(args.Area)
+}
+
+// ToFr converts the Area into frontend variables, as the Params of a FrTransformation.
+func (args CropArgs) ToFr() CropParams {
+	return CropParams{X0: args.Area.X0, Y0: args.Area.Y0, X1: args.Area.X1, Y1: args.Area.Y1}
+}
+
+// RotateArgs rotate an image clockwise by Quarters quarter turns.
+type RotateArgs struct {
+	Quarters int
+}
+
+func (RotateArgs) Type() int       { return Rotate }
+func (RotateArgs) Validate() error { return nil }
+
+func (args RotateArgs) Params() map[string]int {
+	return map[string]int{"quarters": args.Quarters}
+}
+
+// FlipHArgs mirror an image left to right.
+type FlipHArgs struct{}
+
+func (FlipHArgs) Type() int              { return FlipH }
+func (FlipHArgs) Validate() error        { return nil }
+func (FlipHArgs) Params() map[string]int { return nil }
+
+// FlipVArgs mirror an image top to bottom.
+type FlipVArgs struct{}
+
+func (FlipVArgs) Type() int              { return FlipV }
+func (FlipVArgs) Validate() error        { return nil }
+func (FlipVArgs) Params() map[string]int { return nil }
+
+// DownscaleArgs halve an image.
+type DownscaleArgs struct{}
+
+func (DownscaleArgs) Type() int              { return Downscale }
+func (DownscaleArgs) Validate() error        { return nil }
+func (DownscaleArgs) Params() map[string]int { return nil }
+
+// RotateCropArgs rotate an image clockwise by Quarters quarter turns, and crop the rotated image to Area.
+type RotateCropArgs struct {
+	Quarters int
+	Area     myImage.Rect
+}
+
+func (RotateCropArgs) Type() int { return RotateCrop }
+
+func (args RotateCropArgs) Validate() error {
+	return args.Area.Validate(myImage.Width, myImage.Height)
+}
+
+func (args RotateCropArgs) Params() map[string]int {
+	params := rectParams(args.Area)
+	params["quarters"] = args.Quarters
+	return params
+}
+
+// BrightnessArgs add Delta to every channel of an image.
+type BrightnessArgs struct {
+	Delta int
+}
+
+func (BrightnessArgs) Type() int { return Brightness }
+
+func (args BrightnessArgs) Validate() error {
+	if args.Delta < -myImage.MaxBrightnessDelta || args.Delta > myImage.MaxBrightnessDelta {
+		return fmt.Errorf("invalid brightness delta %d: expected a value in [%d, %d]", args.Delta, -myImage.MaxBrightnessDelta, myImage.MaxBrightnessDelta)
+	}
+	return nil
+}
+
+func (args BrightnessArgs) Params() map[string]int {
+	return map[string]int{"delta": args.Delta}
+}
+
+// GammaArgs gamma correct an image with Gamma in hundredths, one of image.Gammas.
+type GammaArgs struct {
+	Gamma int
+}
+
+func (GammaArgs) Type() int { return Gamma }
+
+func (args GammaArgs) Validate() error {
+	_, err := myImage.GammaLUT(args.Gamma)
+	return err
+}
+
+func (args GammaArgs) Params() map[string]int {
+	return map[string]int{"gamma": args.Gamma}
+}
+
+// SepiaArgs tone an image in sepia.
+type SepiaArgs struct{}
+
+func (SepiaArgs) Type() int              { return Sepia }
+func (SepiaArgs) Validate() error        { return nil }
+func (SepiaArgs) Params() map[string]int { return nil }
+
+// HueSaturationArgs rotate the hue of an image by Hue degrees, one of image.Hues, and scale its saturation by
+// Saturation out of 256.
+type HueSaturationArgs struct {
+	Hue        int
+	Saturation int
+}
+
+func (HueSaturationArgs) Type() int { return HueSaturation }
+
+func (args HueSaturationArgs) Validate() error {
+	if _, err := myImage.HueMatrix(args.Hue); err != nil {
+		return err
+	}
+	_, err := myImage.SaturationMatrix(args.Saturation)
+	return err
+}
+
+func (args HueSaturationArgs) Params() map[string]int {
+	return map[string]int{"hue": args.Hue, "saturation": args.Saturation}
+}
+
+// GainArgs multiply the R, G and B of an image by Gains out of 256.
+type GainArgs struct {
+	Gains [3]int
+}
+
+func (GainArgs) Type() int { return Gain }
+
+func (args GainArgs) Validate() error {
+	_, err := myImage.GainMatrix(args.Gains)
+	return err
+}
+
+func (args GainArgs) Params() map[string]int {
+	return map[string]int{"gain_r": args.Gains[0], "gain_g": args.Gains[1], "gain_b": args.Gains[2]}
+}
+
+// ThresholdArgs binarize an image at Threshold.
+type ThresholdArgs struct {
+	Threshold int
+}
+
+func (ThresholdArgs) Type() int { return Threshold }
+
+func (args ThresholdArgs) Validate() error {
+	if args.Threshold < 0 || args.Threshold > myImage.MaxThreshold {
+		return fmt.Errorf("invalid threshold %d: expected a luma in [0, %d]", args.Threshold, myImage.MaxThreshold)
+	}
+	return nil
+}
+
+func (args ThresholdArgs) Params() map[string]int {
+	return map[string]int{"threshold": args.Threshold}
+}
+
+// ChannelSwapArgs make channel c of an image its channel Sources[c], or 0 for image.DroppedChannel.
+type ChannelSwapArgs struct {
+	Sources [3]int
+}
+
+func (ChannelSwapArgs) Type() int { return ChannelSwap }
+
+func (args ChannelSwapArgs) Validate() error {
+	for _, source := range args.Sources {
+		if source < 0 || source > myImage.DroppedChannel {
+			return fmt.Errorf("invalid channel source %d: expected a channel in [0, 2], or %d to drop it", source, myImage.DroppedChannel)
+		}
+	}
+	return nil
+}
+
+func (args ChannelSwapArgs) Params() map[string]int {
+	return map[string]int{"source_r": args.Sources[0], "source_g": args.Sources[1], "source_b": args.Sources[2]}
+}
+
+// RedactArgs blacken Rect.
+type RedactArgs struct {
+	Rect myImage.Rect
+}
+
+func (RedactArgs) Type() int { return Redact }
+
+func (args RedactArgs) Validate() error {
+	return args.Rect.Validate(myImage.Width, myImage.Height)
+}
+
+func (args RedactArgs) Params() map[string]int {
+	return rectParams(args.Rect)
+}
+
+// MosaicArgs pixelate Rect by blocks of Block x Block pixels, one of image.MosaicBlocks.
+type MosaicArgs struct {
+	Rect  myImage.Rect
+	Block int
+}
+
+func (MosaicArgs) Type() int { return Mosaic }
+
+func (args MosaicArgs) Validate() error {
+	if !slices.Contains(myImage.MosaicBlocks[:], args.Block) {
+		return fmt.Errorf("invalid mosaic block %d: expected one of %v", args.Block, myImage.MosaicBlocks)
+	}
+	return args.Rect.Validate(myImage.Width, myImage.Height)
+}
+
+func (args MosaicArgs) Params() map[string]int {
+	params := rectParams(args.Rect)
+	params["block"] = args.Block
+	return params
+}
+
+// BlurArgs box blur Rect.
+type BlurArgs struct {
+	Rect myImage.Rect
+}
+
+func (BlurArgs) Type() int { return Blur }
+
+func (args BlurArgs) Validate() error {
+	return args.Rect.Validate(myImage.Width, myImage.Height)
+}
+
+func (args BlurArgs) Params() map[string]int {
+	return rectParams(args.Rect)
+}
+
+// SharpenArgs sharpen Rect.
+type SharpenArgs struct {
+	Rect myImage.Rect
+}
+
+func (SharpenArgs) Type() int { return Sharpen }
+
+func (args SharpenArgs) Validate() error {
+	return args.Rect.Validate(myImage.Width, myImage.Height)
+}
+
+func (args SharpenArgs) Params() map[string]int {
+	return rectParams(args.Rect)
+}
+
+// WatermarkArgs stamp Watermark with its top left pixel at (X, Y).
+type WatermarkArgs struct {
+	Watermark myImage.Watermark
+	X, Y      int
+}
+
+func (WatermarkArgs) Type() int       { return Watermark }
+func (WatermarkArgs) Validate() error { return nil }
+
+func (args WatermarkArgs) Params() map[string]int {
+	return WatermarkParams(&args.Watermark, args.X, args.Y)
+}
+
+// CaptionArgs draw Caption along the edge of an image at row Y, its top or its bottom.
+type CaptionArgs struct {
+	Caption myImage.Caption
+	Y       int
+}
+
+func (CaptionArgs) Type() int       { return Caption }
+func (CaptionArgs) Validate() error { return nil }
+
+func (args CaptionArgs) Params() map[string]int {
+	return CaptionParams(&args.Caption, args.Y)
+}
+
+// BorderArgs frame an image with a border of Width pixels in Color.
+type BorderArgs struct {
+	Width int
+	Color myImage.RGBPixel
+}
+
+func (BorderArgs) Type() int { return Border }
+
+func (args BorderArgs) Validate() error {
+	if args.Width < 0 || args.Width > myImage.MaxBorder {
+		return fmt.Errorf("invalid border width %d: expected a width in [0, %d]", args.Width, myImage.MaxBorder)
+	}
+	return nil
+}
+
+func (args BorderArgs) Params() map[string]int {
+	return map[string]int{"width": args.Width, "r": int(args.Color.R), "g": int(args.Color.G), "b": int(args.Color.B)}
+}
+
+// ConvolutionArgs convolve Rect by image.Kernels[Kernel].
+type ConvolutionArgs struct {
+	Rect   myImage.Rect
+	Kernel int
+}
+
+func (ConvolutionArgs) Type() int { return Convolution }
+
+func (args ConvolutionArgs) Validate() error {
+	if args.Kernel < 0 || args.Kernel >= len(myImage.Kernels) {
+		return fmt.Errorf("invalid kernel %d: expected an index of the %d permissible kernels", args.Kernel, len(myImage.Kernels))
+	}
+	return args.Rect.Validate(myImage.Width, myImage.Height)
+}
+
+func (args ConvolutionArgs) Params() map[string]int {
+	params := rectParams(args.Rect)
+	params["kernel"] = args.Kernel
+	return params
+}
+
+// PolicyArgs transform an image by Edit, the Args of one of the PolicyTypes, with keys generated for a Policy;
+// no Edit keeps the image.
+type PolicyArgs struct {
+	Edit Args
+}
+
+func (PolicyArgs) Type() int { return Policy }
+
+func (args PolicyArgs) Validate() error {
+	edit := args.edit()
+	if !slices.Contains(PolicyTypes[:], edit.Type()) {
+		return fmt.Errorf("transformation type %d is not one of the policy %v", edit.Type(), PolicyTypes)
+	}
+	return edit.Validate()
+}
+
+func (args PolicyArgs) Params() map[string]int {
+	edit := args.edit()
+	return policyParams(edit.Type(), edit.Params())
+}
+
+func (args PolicyArgs) edit() Args {
+	if args.Edit == nil {
+		return IdentityArgs{}
+	}
+	return args.Edit
+}
+
+// ChainArgs transform an image by every one of Steps in turn, in a single proof, see ChainOf.
+type ChainArgs struct {
+	Steps []Args
+}
+
+func (ChainArgs) Type() int { return Chain }
+
+func (args ChainArgs) Validate() error {
+	if len(args.Steps) == 0 {
+		return fmt.Errorf("a chain needs at least one step")
+	}
+	for i, step := range args.Steps {
+		if _, ok := chainSteps[step.Type()]; !ok {
+			return fmt.Errorf("transformation type %d of step %d cannot be chained", step.Type(), i)
+		}
+		if err := step.Validate(); err != nil {
+			return fmt.Errorf("invalid step %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (args ChainArgs) Params() map[string]int {
+	params := make(map[string]int)
+	for i, step := range args.Steps {
+		addChainStep(params, i, step.Type(), step.Params())
+	}
+	return params
+}
+
+// CreditArgs credit an image to Author, of at most image.MaxAuthor bytes, and keep the rest of its metadata.
+type CreditArgs struct {
+	Author string
+}
+
+func (CreditArgs) Type() int { return Credit }
+
+func (args CreditArgs) Validate() error {
+	if len(args.Author) > myImage.MaxAuthor {
+		return fmt.Errorf("invalid author of %d bytes: expected at most %d", len(args.Author), myImage.MaxAuthor)
+	}
+	return nil
+}
+
+func (args CreditArgs) Params() map[string]int {
+	return CreditParams(args.Author)
+}
@@ -0,0 +1,93 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+func TestNew(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	rect := myImage.Rect{X0: 2, Y0: 3, X1: 9, Y1: 7}
+	for _, c := range []struct {
+		args   Args
+		params map[string]int
+	}{
+		{CropArgs{Area: rect}, map[string]int{"x0": 2, "y0": 3, "x1": 9, "y1": 7}},
+		{RotateCropArgs{Quarters: 2, Area: rect}, map[string]int{"quarters": 2, "x0": 2, "y0": 3, "x1": 9, "y1": 7}},
+		{BrightnessArgs{Delta: -40}, map[string]int{"delta": -40}},
+		{GainArgs{Gains: [3]int{256, 300, 200}}, map[string]int{"gain_r": 256, "gain_g": 300, "gain_b": 200}},
+		{MosaicArgs{Rect: rect, Block: 2}, map[string]int{"x0": 2, "y0": 3, "x1": 9, "y1": 7, "block": 2}},
+		{BorderArgs{Width: 2, Color: myImage.RGBPixel{R: 1, G: 2, B: 3}}, map[string]int{"width": 2, "r": 1, "g": 2, "b": 3}},
+		{ConvolutionArgs{Rect: rect, Kernel: 2}, map[string]int{"x0": 2, "y0": 3, "x1": 9, "y1": 7, "kernel": 2}},
+		{FlipHArgs{}, nil},
+	} {
+		transformation, err := New(c.args)
+		assert.NoError(err, "args %+v", c.args)
+		assert.Equal(Transformation{T: c.args.Type(), Params: c.params}, transformation)
+
+		out, err := transformation.Apply(in)
+		assert.NoError(err, "args %+v", c.args)
+		want, err := Transformation{T: c.args.Type(), Params: c.params}.Apply(in)
+		assert.NoError(err)
+		assert.Equal(want.Pixels, out.Pixels)
+	}
+
+	// Arguments out of the range of their transformation are rejected before any image
+	for _, args := range []Args{
+		CropArgs{Area: myImage.Rect{X0: 3, Y0: 0, X1: 2, Y1: 4}},
+		BrightnessArgs{Delta: myImage.MaxBrightnessDelta + 1},
+		GammaArgs{Gamma: 101},
+		HueSaturationArgs{Hue: 0, Saturation: myImage.MaxSaturation + 1},
+		ThresholdArgs{Threshold: -1},
+		ChannelSwapArgs{Sources: [3]int{0, 1, myImage.DroppedChannel + 1}},
+		MosaicArgs{Rect: rect, Block: 3},
+		BorderArgs{Width: myImage.MaxBorder + 1},
+		ConvolutionArgs{Rect: rect, Kernel: len(myImage.Kernels)},
+	} {
+		if _, err := New(args); err == nil {
+			t.Errorf("args %+v were accepted", args)
+		}
+	}
+}
+
+func TestCheckParams(t *testing.T) {
+	// The Args of every type have the keys its predicate reads its public parameters from
+	for T, args := range typeArgs {
+		id, err := Transformation{T: T}.Circuit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, param := range id.EditParams() {
+			if _, ok := args.Params()[param.Name]; !ok {
+				t.Errorf("the args of type %d have no parameter %q of circuit %s", T, param.Name, id)
+			}
+		}
+	}
+
+	// A misspelled key, or a missing one, is rejected rather than read as 0
+	img := myImage.NoiseImage(testSeed)
+	for _, params := range []map[string]int{
+		{"xo": 2, "y0": 3, "x1": 9, "y1": 7},
+		{"x0": 2, "y0": 3, "x1": 9},
+		{"x0": 2, "y0": 3, "x1": 9, "y1": 7, "quarters": 1},
+	} {
+		transformation := Transformation{T: Crop, Params: params}
+		if err := transformation.CheckParams(); err == nil {
+			t.Errorf("params %v were accepted", params)
+		}
+		if _, err := transformation.Apply(img); err == nil {
+			t.Errorf("params %v were applied", params)
+		}
+		if err := CheckArea(params); err == nil {
+			t.Errorf("area %v was accepted", params)
+		}
+	}
+	if err := (Transformation{T: Identity, Params: map[string]int{"x0": 1}}).CheckParams(); err != nil {
+		t.Errorf("the parameters of an Identity were checked: %v", err)
+	}
+}
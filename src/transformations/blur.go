@@ -0,0 +1,47 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Blur transformations: the signed image is the image FrImage with every pixel of the
+// public Region replaced by the rounded average of its 3 x 3 neighborhood, like image.I.BoxBlur does, e.g. a
+// background softened, and every pixel outside of it unchanged. The Region lies a pixel within the edges of the
+// pixels of an image, so that every neighborhood does; an empty Region, e.g. one whose X1 is X0-1, keeps the
+// image.
+// Public fields: Statement, Region
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, BlurredImage_in
+type BlurCircuit struct {
+	Statement       frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey       // Key the image is signed with
+	ImageSignature  eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable     // Digest of the signed image, BlurredImage_in
+	PrevImageBytes  frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Region          CropParams            `gnark:",public"` // Blurred rectangle, possibly empty
+	Metadata        frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable     // MetadataDigest of the previous image
+	Prev            PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage         myImage.FrontendImage // z_in as a FrontendImage
+	BlurredImage_in myImage.FrontendImage // Blurred previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a box blur.
+func (circuit *BlurCircuit) Define(api frontend.API) error {
+	// Blur the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	if err := convolvePlanes(api, planes[:], circuit.Region, &myImage.BoxBlurKernel); err != nil {
+		return err
+	}
+	blurredImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.BlurredImage_in, &blurredImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.BlurredImage_in)
+}
@@ -0,0 +1,80 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Border transformations: the signed image is the image FrImage with a border of the
+// public Width drawn in the public Color along its edges, like image.I.AddBorder does, e.g. the frame of a
+// published photo: every pixel of the image less than Width pixels from one of its edges has the Color, and
+// every other pixel is unchanged. The edges are those of the image, whose size is secret, like the size of a
+// DownscaleCircuit, and every pixel outside of it is asserted black. A Width of 0 keeps the image.
+// Public fields: Statement, Width, Color
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, BorderedImage_in, ImageWidth, ImageHeight
+type BorderCircuit struct {
+	Statement        frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey        eddsa.PublicKey       // Key the image is signed with
+	ImageSignature   eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce            frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash    frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier        frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops             frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes       frontend.Variable     // Digest of the signed image, BorderedImage_in
+	PrevImageBytes   frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Width            frontend.Variable     `gnark:",public"` // Width of the border, in [0, image.MaxBorder]
+	Color            [3]frontend.Variable  `gnark:",public"` // R, G and B of the border
+	Metadata         frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata     frontend.Variable     // MetadataDigest of the previous image
+	Prev             PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage          myImage.FrontendImage // z_in as a FrontendImage
+	BorderedImage_in myImage.FrontendImage // Bordered previous image as a FrontendImage
+	ImageWidth       frontend.Variable     // Width of the image, every pixel past it black
+	ImageHeight      frontend.Variable     // Height of the image, every pixel past it black
+}
+
+// Defines the Compliance Predicate of a border.
+func (circuit *BorderCircuit) Define(api frontend.API) error {
+	// Draw the border on the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	borderPlanes(api, planes[:], circuit.Width, &circuit.Color, circuit.ImageWidth, circuit.ImageHeight)
+	borderedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.BorderedImage_in, &borderedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.BorderedImage_in)
+}
+
+// borderPlanes draws a border of width pixels in color along the edges of the image of the given size, in
+// place, exactly like image.I.AddBorder does outside the circuit, and keeps every other pixel. It asserts width
+// is in [0, image.MaxBorder], every channel of color has 8 bits, and that the image lies within the planes with
+// every pixel outside of it black, like downscalePlanes does.
+func borderPlanes(api frontend.API, planes []channelPlane, width frontend.Variable, color *[3]frontend.Variable, imageWidth, imageHeight frontend.Variable) {
+	comparator := newLocationComparator(api)
+	comparator.AssertIsLessEq(0, width)
+	comparator.AssertIsLessEq(width, myImage.MaxBorder)
+	for c := range color {
+		api.ToBinary(color[c], channelBits)
+	}
+	inWidth, inHeight := imageIndicators(api, comparator, planes, imageWidth, imageHeight)
+
+	// Whether every column and every row lies less than width pixels from an edge of the image
+	var edgeColumn [myImage.Width]frontend.Variable
+	for x := range edgeColumn {
+		edgeColumn[x] = api.Or(comparator.IsLess(x, width), comparator.IsLessEq(imageWidth, api.Add(x, width)))
+	}
+	var edgeRow [myImage.Height]frontend.Variable
+	for y := range edgeRow {
+		edgeRow[y] = api.Or(comparator.IsLess(y, width), comparator.IsLessEq(imageHeight, api.Add(y, width)))
+	}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			border := api.And(api.Or(edgeColumn[x], edgeRow[y]), api.And(inWidth[x], inHeight[y]))
+			for c := range planes {
+				planes[c][y][x] = api.Select(border, color[c], planes[c][y][x])
+			}
+		}
+	}
+}
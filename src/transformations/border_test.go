@@ -0,0 +1,74 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts borderPlanes(In, Width, Color, ImageWidth, ImageHeight) == Out, without the signature check of the
+// BorderCircuit.
+type borderPixelsCircuit struct {
+	In          myImage.FrontendImage
+	Out         myImage.FrontendImage
+	Width       frontend.Variable
+	Color       [3]frontend.Variable
+	ImageWidth  frontend.Variable
+	ImageHeight frontend.Variable
+}
+
+func (circuit *borderPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	borderPlanes(api, planes[:], circuit.Width, &circuit.Color, circuit.ImageWidth, circuit.ImageHeight)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestBorderPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	noise := myImage.NoiseImage(testSeed)
+	small, err := noise.SubImage(0, 0, 10, 7)
+	assert.NoError(err)
+	color := myImage.RGBPixel{R: 30, G: 60, B: 90}
+	for _, in := range []myImage.I{noise, small} {
+		for width := 0; width <= myImage.MaxBorder; width++ {
+			out, err := in.AddBorder(width, color)
+			assert.NoError(err)
+
+			assignment := borderPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Width: width, Color: [3]frontend.Variable{30, 60, 90}, ImageWidth: in.M.Width, ImageHeight: in.M.Height}
+			assert.NoError(test.IsSolved(&borderPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d x %d image, width %d", in.M.Width, in.M.Height, width)
+
+			// The border follows the edges of the image, and has the color
+			assignment.ImageWidth = in.M.Width - 1
+			assert.Error(test.IsSolved(&borderPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d x %d image, width %d", in.M.Width, in.M.Height, width)
+			if width > 0 {
+				assignment.ImageWidth = in.M.Width
+				assignment.Color[1] = 61
+				assert.Error(test.IsSolved(&borderPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d x %d image, width %d", in.M.Width, in.M.Height, width)
+			}
+		}
+	}
+
+	// The width of the border is at most image.MaxBorder, and the image lies within the pixels
+	white := myImage.AllWhiteImage()
+	wide := white.Clone()
+	wide.Map(func(x, y int, p myImage.RGBPixel) myImage.RGBPixel {
+		if x <= myImage.MaxBorder || x >= myImage.Width-1-myImage.MaxBorder || y <= myImage.MaxBorder || y >= myImage.Height-1-myImage.MaxBorder {
+			return color
+		}
+		return p
+	})
+	for _, assignment := range []borderPixelsCircuit{
+		{In: white.ToFrontendImage(), Out: wide.ToFrontendImage(), Width: myImage.MaxBorder + 1, Color: [3]frontend.Variable{30, 60, 90}, ImageWidth: myImage.Width, ImageHeight: myImage.Height},
+		{In: white.ToFrontendImage(), Out: white.ToFrontendImage(), Width: 0, Color: [3]frontend.Variable{30, 60, 90}, ImageWidth: myImage.Width + 1, ImageHeight: myImage.Height},
+		{In: white.ToFrontendImage(), Out: white.ToFrontendImage(), Width: 0, Color: [3]frontend.Variable{30, 256, 90}, ImageWidth: myImage.Width, ImageHeight: myImage.Height},
+	} {
+		assert.Error(test.IsSolved(&borderPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "width %v, size %v x %v", assignment.Width, assignment.ImageWidth, assignment.ImageHeight)
+	}
+}
@@ -0,0 +1,112 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// Number of bits that holds a brightness delta plus image.MaxBrightnessDelta, i.e. in [0, 2 * 255].
+const brightnessDeltaBits = channelBits + 1
+
+// This circuit is only for Brightness transformations: the signed image is the image FrImage, of the Width and
+// Height of Params, with the public Delta added to every channel within its size and clamped to [0, 255],
+// like image.I.Brighten does. Besides the public fields of the CropCircuit, it exposes the Delta, so a
+// verifier knows how much the exposure was changed; a Delta of 0 keeps the image.
+// Public fields: Statement, Delta
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, BrightenedImage_in, Params
+type BrightnessCircuit struct {
+	Statement          frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey          eddsa.PublicKey       // Key the image is signed with
+	ImageSignature     eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce              frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash      frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier          frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops               frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes         frontend.Variable     // Digest of the signed image, BrightenedImage_in
+	PrevImageBytes     frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Delta              frontend.Variable     `gnark:",public"` // Added to every channel, in [-image.MaxBrightnessDelta, image.MaxBrightnessDelta]
+	Metadata           frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata       frontend.Variable     // MetadataDigest of the previous image
+	Prev               PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage            myImage.FrontendImage // z_in as a FrontendImage
+	BrightenedImage_in myImage.FrontendImage // Brightened previous image as a FrontendImage
+	Params             SizeParams            // Size of the image
+}
+
+// Parameters of an edit of the pixels of an image within its size: the width and height of the image.
+type SizeParams struct {
+	Width  frontend.Variable
+	Height frontend.Variable
+}
+
+// Defines the Compliance Predicate of a brightness adjustment.
+func (circuit *BrightnessCircuit) Define(api frontend.API) error {
+	// Brighten the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	brightenPlanes(api, planes[:], circuit.Delta, circuit.Params)
+	brightenedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.BrightenedImage_in, &brightenedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.BrightenedImage_in)
+}
+
+// brightenPlanes adds delta to every channel of the planes within the size of params, in place, clamped to
+// [0, 255] by a clampTable, exactly like image.I.Brighten does outside the circuit. The pixels outside the
+// size are kept.
+func brightenPlanes(api frontend.API, planes []channelPlane, delta frontend.Variable, params SizeParams) {
+	// The delta is bounded, so a brightened channel lies within the clampTable
+	rangeChecker := rangecheck.New(api)
+	rangeChecker.Check(api.Add(delta, myImage.MaxBrightnessDelta), brightnessDeltaBits)
+	rangeChecker.Check(api.Sub(myImage.MaxBrightnessDelta, delta), brightnessDeltaBits)
+
+	inside := insideSize(api, params)
+	values := make([]frontend.Variable, 0, len(planes)*myImage.Width*myImage.Height)
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				values = append(values, api.Add(planes[c][y][x], api.Mul(inside[y][x], delta)))
+			}
+		}
+	}
+	clamped := newClampTable(api).clamp(values...)
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				planes[c][y][x] = clamped[(c*myImage.Height+y)*myImage.Width+x]
+			}
+		}
+	}
+}
+
+// insideSize returns, for every pixel, 1 if it lies within the size of params and 0 if not. It asserts that
+// the size is a valid size of an image, at least 1 x 1 and at most image.Width x image.Height.
+func insideSize(api frontend.API, params SizeParams) channelPlane {
+	comparator := newLocationComparator(api)
+	comparator.AssertIsLessEq(1, params.Width)
+	comparator.AssertIsLessEq(1, params.Height)
+	comparator.AssertIsLessEq(params.Width, myImage.Width)
+	comparator.AssertIsLessEq(params.Height, myImage.Height)
+
+	var inWidth [myImage.Width]frontend.Variable
+	for x := range inWidth {
+		inWidth[x] = comparator.IsLess(x, params.Width)
+	}
+	var inside channelPlane
+	for y := 0; y < myImage.Height; y++ {
+		inHeight := comparator.IsLess(y, params.Height)
+		for x := 0; x < myImage.Width; x++ {
+			inside[y][x] = api.And(inWidth[x], inHeight)
+		}
+	}
+	return inside
+}
+
+// SizeCircuitParams returns the size parameters of img, for the compliance predicates of edits within it, e.g.
+// the BrightnessCircuit.
+func SizeCircuitParams(img myImage.I) SizeParams {
+	return SizeParams{Width: img.M.Width, Height: img.M.Height}
+}
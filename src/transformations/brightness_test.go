@@ -0,0 +1,62 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts brightenPlanes(In, Delta, Params) == Out, without the signature check of the BrightnessCircuit.
+type brightenPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Delta  frontend.Variable
+	Params SizeParams
+}
+
+func (circuit *brightenPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	brightenPlanes(api, planes[:], circuit.Delta, circuit.Params)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestBrightenPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in, err := myImage.NoiseImage(testSeed).SubImage(0, 0, 10, 8)
+	assert.NoError(err)
+	for _, delta := range []int{-myImage.MaxBrightnessDelta, -40, 0, 40, myImage.MaxBrightnessDelta} {
+		out, err := in.Brighten(delta)
+		assert.NoError(err)
+
+		assignment := brightenPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Delta: delta, Params: SizeCircuitParams(in)}
+		assert.NoError(test.IsSolved(&brightenPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "delta %d", delta)
+
+		// The image brightened by another delta is not the brightened image
+		if delta != 0 {
+			assignment.Delta = delta / 2
+			assert.Error(test.IsSolved(&brightenPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "delta %d", delta)
+		}
+	}
+
+	// The delta is bounded, and the image cannot be brightened past its size
+	for name, assignment := range map[string]brightenPixelsCircuit{
+		"too bright": {Delta: myImage.MaxBrightnessDelta + 1},
+		"too dark":   {Delta: -myImage.MaxBrightnessDelta - 1},
+		"no size":    {Delta: 0, Params: SizeParams{Width: 0, Height: 0}},
+		"too wide":   {Delta: 0, Params: SizeParams{Width: myImage.Width + 1, Height: myImage.Height}},
+	} {
+		if assignment.Params.Width == nil {
+			assignment.Params = SizeParams{Width: myImage.Width, Height: myImage.Height}
+		}
+		white := myImage.AllWhiteImage().ToFrontendImage()
+		assignment.In, assignment.Out = white, white
+		assert.Error(test.IsSolved(&brightenPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+}
@@ -0,0 +1,90 @@
+package transformations
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// Recorded constraint budget of every compliance predicate, keyed by circuit name.
+var budgetPath = filepath.Join("testdata", "constraint_budget.json")
+
+// TestConstraintBudget compiles every compliance predicate and fails when one of them
+// needs more constraints than recorded in testdata/constraint_budget.json, so that a new
+// feature cannot silently multiply the prover's cost. Run go test -update to record the
+// current counts as the new budget.
+func TestConstraintBudget(t *testing.T) {
+	budget := map[string]int{}
+	if !*update {
+		data, err := os.ReadFile(budgetPath)
+		if err != nil {
+			t.Fatalf("%v (run go test -update to create it)", err)
+		}
+		if err := json.Unmarshal(data, &budget); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts := map[string]int{}
+	for _, c := range circuitCases(t) {
+		t.Run(c.name, func(t *testing.T) {
+			if c.skip != "" {
+				t.Skip(c.skip)
+			}
+
+			compliance_predicate, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, c.circuit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[c.name] = compliance_predicate.GetNbConstraints()
+
+			if *update {
+				return
+			}
+			max, ok := budget[c.name]
+			if !ok {
+				t.Fatalf("no constraint budget recorded for %s (run go test -update)", c.name)
+			}
+			if counts[c.name] > max {
+				t.Errorf("%s needs %d constraints, over its budget of %d", c.name, counts[c.name], max)
+			}
+		})
+	}
+
+	if *update {
+		data, err := json.MarshalIndent(counts, "", "\t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(budgetPath, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCompile measures the compile time of every compliance predicate and reports
+// its constraint count.
+func BenchmarkCompile(b *testing.B) {
+	for _, c := range circuitCases(b) {
+		b.Run(c.name, func(b *testing.B) {
+			if c.skip != "" {
+				b.Skip(c.skip)
+			}
+
+			nbConstraints := 0
+			for i := 0; i < b.N; i++ {
+				compliance_predicate, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, c.circuit)
+				if err != nil {
+					b.Fatal(err)
+				}
+				nbConstraints = compliance_predicate.GetNbConstraints()
+			}
+			b.ReportMetric(float64(nbConstraints), "constraints")
+		})
+	}
+}
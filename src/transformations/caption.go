@@ -0,0 +1,130 @@
+package transformations
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// Row of the caption of the CaptionCircuit that keeps the image, past the bottom edge of its pixels: the
+// Identity of the predicate.
+const keepCaption = myImage.Height
+
+// This circuit is only for Caption transformations: the signed image is the image FrImage with the public
+// Caption drawn from row Y, like image.I.AddCaption does, e.g. a credit line or the time a photo was
+// published, and every pixel outside of the strip unchanged. Every row of the Caption is a public input, a bit
+// per pixel from the left, so a proof commits to the very text it drew. Y lies within the pixels of an image, or
+// is image.Height, which keeps the image.
+// Public fields: Statement, Y, Caption
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, CaptionedImage_in
+type CaptionCircuit struct {
+	Statement         frontend.Variable                        `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey         eddsa.PublicKey                          // Key the image is signed with
+	ImageSignature    eddsa.Signature                          // Digital signature as eddsa.Signature
+	Nonce             frontend.Variable                        // Capture counter, see image.Statement
+	PrevProofHash     frontend.Variable                        // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier         frontend.Variable                        // Nullifier of the capture, see Nullifier
+	Hops              frontend.Variable                        // Edits since the original image, see MaxHops
+	ImageBytes        frontend.Variable                        // Digest of the signed image, CaptionedImage_in
+	PrevImageBytes    frontend.Variable                        // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Y                 frontend.Variable                        `gnark:",public"` // First row of the caption
+	Caption           [myImage.CaptionHeight]frontend.Variable `gnark:",public"` // Rows of the caption, bit x set for a white pixel
+	Metadata          frontend.Variable                        // MetadataDigest of the signed image
+	PrevMetadata      frontend.Variable                        // MetadataDigest of the previous image
+	Prev              PrevSignature                            // Signature of the previous image, see PrevSignature
+	FrImage           myImage.FrontendImage                    // z_in as a FrontendImage
+	CaptionedImage_in myImage.FrontendImage                    // Captioned previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a caption.
+func (circuit *CaptionCircuit) Define(api frontend.API) error {
+	// Draw the caption on the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	captionPlanes(api, planes[:], circuit.Y, &circuit.Caption)
+	captionedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.CaptionedImage_in, &captionedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.CaptionedImage_in)
+}
+
+// captionPlanes draws caption, whose rows have a bit per pixel, from row y of the planes, in place, exactly like
+// image.I.AddCaption does outside the circuit, and keeps every other pixel. It asserts every row of the caption
+// has image.Width bits, and that the caption lies within the pixels of an image, or that y is keepCaption.
+// Unlike image.I.AddCaption, it does not assert the caption lies along an edge of the image, whose size is
+// secret: a verifier reads the row from the proof.
+func captionPlanes(api frontend.API, planes []channelPlane, y frontend.Variable, caption *[myImage.CaptionHeight]frontend.Variable) {
+	var bits [myImage.CaptionHeight][]frontend.Variable
+	for j := range caption {
+		bits[j] = api.ToBinary(caption[j], myImage.Width)
+	}
+	rows := make([]int, 0, myImage.Height-myImage.CaptionHeight+2)
+	for v := 0; v <= myImage.Height-myImage.CaptionHeight; v++ {
+		rows = append(rows, v)
+	}
+	isY := valueIndicators(api, y, append(rows, keepCaption))
+
+	for row := 0; row < myImage.Height; row++ {
+		// Whether the caption covers the row, and with which of its rows
+		var covered frontend.Variable = 0
+		var set [myImage.Width]frontend.Variable
+		for x := range set {
+			set[x] = 0
+		}
+		for j := max(row-len(rows)+1, 0); j <= min(row, myImage.CaptionHeight-1); j++ {
+			covered = api.Add(covered, isY[row-j])
+			for x := range set {
+				set[x] = api.Add(set[x], api.Mul(isY[row-j], bits[j][x]))
+			}
+		}
+		for x := 0; x < myImage.Width; x++ {
+			drawn := api.Mul(set[x], channelMax)
+			for c := range planes {
+				planes[c][row][x] = api.Select(covered, drawn, planes[c][row][x])
+			}
+		}
+	}
+}
+
+// Names of the EditParams of the CaptionCircuit, y, then the rows of the caption.
+func captionEditParams() []EditParam {
+	params := []EditParam{{Name: "y", Identity: keepCaption}}
+	for j := range myImage.CaptionHeight {
+		params = append(params, EditParam{Name: fmt.Sprintf("caption_%d", j), Identity: 0})
+	}
+	return params
+}
+
+// CaptionParams returns the parameters of a Caption transformation that draws caption from row y.
+func CaptionParams(caption *myImage.Caption, y int) map[string]int {
+	params := map[string]int{"y": y}
+	for j, row := range caption {
+		bits := 0
+		for x, set := range row {
+			if set {
+				bits |= 1 << x
+			}
+		}
+		params[fmt.Sprintf("caption_%d", j)] = bits
+	}
+	return params
+}
+
+// captionOf returns the caption of the parameters of a Caption transformation.
+func captionOf(params map[string]int) (myImage.Caption, error) {
+	var caption myImage.Caption
+	for j := range caption {
+		name := fmt.Sprintf("caption_%d", j)
+		bits := params[name]
+		if bits < 0 || bits >= 1<<myImage.Width {
+			return myImage.Caption{}, fmt.Errorf("invalid %s %#x: expected a bit per pixel of a row", name, bits)
+		}
+		for x := range caption[j] {
+			caption[j][x] = bits>>x&1 == 1
+		}
+	}
+	return caption, nil
+}
@@ -0,0 +1,78 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts captionPlanes(In, Y, Caption) == Out, without the signature check of the CaptionCircuit.
+type captionPixelsCircuit struct {
+	In      myImage.FrontendImage
+	Out     myImage.FrontendImage
+	Y       frontend.Variable
+	Caption [myImage.CaptionHeight]frontend.Variable
+}
+
+func (circuit *captionPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	captionPlanes(api, planes[:], circuit.Y, &circuit.Caption)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+// captionAssignment returns the assignment of a captionPixelsCircuit for the parameters of a Caption
+// transformation.
+func captionAssignment(in, out myImage.I, params map[string]int) *captionPixelsCircuit {
+	assignment := &captionPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Y: params["y"]}
+	for j, param := range captionEditParams()[1:] {
+		assignment.Caption[j] = params[param.Name]
+	}
+	return assignment
+}
+
+func TestCaptionPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	var caption myImage.Caption
+	for j := range caption {
+		for x := range caption[j] {
+			caption[j][x] = (x+j)%3 == 0
+		}
+	}
+	for _, y := range []int{0, myImage.Height - myImage.CaptionHeight} {
+		out, err := in.AddCaption(&caption, y)
+		assert.NoError(err)
+
+		params := CaptionParams(&caption, y)
+		assert.NoError(test.IsSolved(&captionPixelsCircuit{}, captionAssignment(in, out, params), ecc.BN254.ScalarField()), "row %d", y)
+
+		// The caption is drawn from its row, and is the caption of the parameters
+		params["y"] = 3
+		assert.Error(test.IsSolved(&captionPixelsCircuit{}, captionAssignment(in, out, params), ecc.BN254.ScalarField()), "row %d", y)
+		params["y"] = y
+		params["caption_2"] ^= 1 << 7
+		assert.Error(test.IsSolved(&captionPixelsCircuit{}, captionAssignment(in, out, params), ecc.BN254.ScalarField()), "row %d", y)
+	}
+
+	// A caption past the bottom edge keeps the image
+	identity := map[string]int{}
+	for _, param := range captionEditParams() {
+		identity[param.Name] = param.Identity
+	}
+	assert.NoError(test.IsSolved(&captionPixelsCircuit{}, captionAssignment(in, in, identity), ecc.BN254.ScalarField()))
+
+	// The caption lies within the pixels of an image, and its rows have a bit per pixel
+	black := myImage.I{}
+	params := CaptionParams(&myImage.Caption{}, myImage.Height-myImage.CaptionHeight+1)
+	assert.Error(test.IsSolved(&captionPixelsCircuit{}, captionAssignment(black, black, params), ecc.BN254.ScalarField()))
+	params = CaptionParams(&myImage.Caption{}, 0)
+	params["caption_0"] = 1 << myImage.Width
+	assert.Error(test.IsSolved(&captionPixelsCircuit{}, captionAssignment(black, black, params), ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,380 @@
+package transformations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Chain transformations: the signed image is the image FrImage transformed by every
+// edit of its Steps in turn, e.g. a crop, then a brightness adjustment, then a downscale, like a proof of each
+// edit would chain them, in a single proof instead. The intermediate images are never signed nor proven, and
+// need no witness: every step transforms the planes the previous one left. Every step has the public
+// parameters of the predicate of its edit, e.g. the delta of a brightness, and the secret ones, e.g. the area
+// of a crop. The Steps are fixed when the circuit is compiled, so the keys of a Chain are for a composition,
+// named by the Steps of their CircuitID.
+// Public fields: Statement, Params
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, ChainedImage_in, Secrets
+type ChainCircuit struct {
+	Statement       frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey       // Key the image is signed with
+	ImageSignature  eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable     // Digest of the signed image, ChainedImage_in
+	PrevImageBytes  frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Params          []frontend.Variable   `gnark:",public"` // Public parameters of every step in turn, in the order of the EditParams of its predicate
+	Metadata        frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable     // MetadataDigest of the previous image
+	Prev            PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage         myImage.FrontendImage // z_in as a FrontendImage
+	ChainedImage_in myImage.FrontendImage // Previous image transformed by every step as a FrontendImage
+	Secrets         []frontend.Variable   // Secret parameters of every step in turn, see chainStep
+	Steps           []int                 `gnark:"-"` // Types of the transformations of the steps, constants of the predicate
+}
+
+// Defines the Compliance Predicate of a chain of edits.
+func (circuit *ChainCircuit) Define(api frontend.API) error {
+	// Transform the FrImage by every step
+	planes := channelPlanes(&circuit.FrImage)
+	if err := chainPlanes(api, &planes, circuit.Steps, circuit.Params, circuit.Secrets); err != nil {
+		return err
+	}
+	chainedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.ChainedImage_in, &chainedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.ChainedImage_in)
+}
+
+// chainPlanes transforms the planes of an image, in place, by the edit of every one of steps in turn, with its
+// public params and its secrets, which follow those of the previous steps.
+func chainPlanes(api frontend.API, planes *[3]channelPlane, steps []int, params, secrets []frontend.Variable) error {
+	nbParams, nbSecrets := 0, 0
+	for _, t := range steps {
+		step, ok := chainSteps[t]
+		if !ok {
+			return fmt.Errorf("transformation type %d cannot be chained", t)
+		}
+		nbParams += len(stepCircuit(t).EditParams())
+		nbSecrets += step.nbSecrets
+	}
+	if len(params) != nbParams || len(secrets) != nbSecrets {
+		return fmt.Errorf("%d public and %d secret parameters for steps with %d and %d", len(params), len(secrets), nbParams, nbSecrets)
+	}
+	for _, t := range steps {
+		step := chainSteps[t]
+		nbParams = len(stepCircuit(t).EditParams())
+		if err := step.define(api, planes, params[:nbParams], secrets[:step.nbSecrets]); err != nil {
+			return err
+		}
+		params, secrets = params[nbParams:], secrets[step.nbSecrets:]
+	}
+	return nil
+}
+
+// A chainStep is an edit that a ChainCircuit composes: the part of the compliance predicate of the edit that
+// transforms the planes of the image, before the signature check of its own circuit.
+type chainStep struct {
+	// define transforms the planes, in place, with the public parameters of the predicate of the edit, in the
+	// order of its EditParams, and its secret ones.
+	define func(api frontend.API, planes *[3]channelPlane, params, secrets []frontend.Variable) error
+	// secrets returns the nbSecrets secret parameters of the edit t of the image in, t either of the type of
+	// the step or an Identity.
+	secrets   func(t Transformation, in myImage.I) []frontend.Variable
+	nbSecrets int
+}
+
+// Edits that a Chain composes, by type of transformation: those whose predicates transform the planes of a
+// single image.
+var chainSteps = map[int]chainStep{
+	Crop: {
+		define: func(api frontend.API, planes *[3]channelPlane, _, secrets []frontend.Variable) error {
+			cropPlanes(api, planes[:], CropParams{X0: secrets[0], Y0: secrets[1], X1: secrets[2], Y1: secrets[3]})
+			return nil
+		},
+		secrets: func(t Transformation, in myImage.I) []frontend.Variable {
+			if t.T == Identity {
+				return []frontend.Variable{0, 0, in.M.Width - 1, in.M.Height - 1}
+			}
+			return []frontend.Variable{t.Params["x0"], t.Params["y0"], t.Params["x1"], t.Params["y1"]}
+		},
+		nbSecrets: 4,
+	},
+	Rotate: {
+		define: func(api frontend.API, planes *[3]channelPlane, _, secrets []frontend.Variable) error {
+			cropPlanes(api, planes[:], rotatePlanes(api, planes, RotateParams{Quarters: secrets[0], Width: secrets[1], Height: secrets[2]}))
+			return nil
+		},
+		secrets: func(t Transformation, in myImage.I) []frontend.Variable {
+			params := RotateCircuitParams(in, t.Params["quarters"])
+			return []frontend.Variable{params.Quarters, params.Width, params.Height}
+		},
+		nbSecrets: 3,
+	},
+	FlipH: {
+		define: func(api frontend.API, planes *[3]channelPlane, _, secrets []frontend.Variable) error {
+			flipPlanesH(api, planes[:], FlipParams{Flip: secrets[0], Size: secrets[1]})
+			return nil
+		},
+		secrets: func(t Transformation, in myImage.I) []frontend.Variable {
+			params := FlipHCircuitParams(in, t.T == FlipH)
+			return []frontend.Variable{params.Flip, params.Size}
+		},
+		nbSecrets: 2,
+	},
+	FlipV: {
+		define: func(api frontend.API, planes *[3]channelPlane, _, secrets []frontend.Variable) error {
+			flipPlanesV(api, planes[:], FlipParams{Flip: secrets[0], Size: secrets[1]})
+			return nil
+		},
+		secrets: func(t Transformation, in myImage.I) []frontend.Variable {
+			params := FlipVCircuitParams(in, t.T == FlipV)
+			return []frontend.Variable{params.Flip, params.Size}
+		},
+		nbSecrets: 2,
+	},
+	Downscale: {
+		define: func(api frontend.API, planes *[3]channelPlane, _, secrets []frontend.Variable) error {
+			return downscalePlanes(api, planes[:], DownscaleParams{Scale: secrets[0], Width: secrets[1], Height: secrets[2]})
+		},
+		secrets: func(t Transformation, in myImage.I) []frontend.Variable {
+			params := DownscaleCircuitParams(in, t.T == Downscale)
+			return []frontend.Variable{params.Scale, params.Width, params.Height}
+		},
+		nbSecrets: 3,
+	},
+	Brightness: {
+		define: func(api frontend.API, planes *[3]channelPlane, params, secrets []frontend.Variable) error {
+			brightenPlanes(api, planes[:], params[0], SizeParams{Width: secrets[0], Height: secrets[1]})
+			return nil
+		},
+		secrets: func(_ Transformation, in myImage.I) []frontend.Variable {
+			return []frontend.Variable{in.M.Width, in.M.Height}
+		},
+		nbSecrets: 2,
+	},
+	Gamma: {
+		define: func(api frontend.API, planes *[3]channelPlane, params, _ []frontend.Variable) error {
+			return gammaCorrectPlanes(api, planes[:], params[0])
+		},
+		secrets: func(Transformation, myImage.I) []frontend.Variable {
+			return nil
+		},
+	},
+	Gain: {
+		define: func(api frontend.API, planes *[3]channelPlane, params, _ []frontend.Variable) error {
+			return gainPlanes(api, planes[:], [3]frontend.Variable{params[0], params[1], params[2]})
+		},
+		secrets: func(Transformation, myImage.I) []frontend.Variable {
+			return nil
+		},
+	},
+}
+
+// ChainOf returns the Chain transformation of steps, which transforms an image by every one of them in turn,
+// or an error if there are none, or one that cannot be chained. The Params of the Chain are the type of every
+// step i, "step_i", and its Params, suffixed by "_i", e.g. "delta_1" for the delta of a second step.
+func ChainOf(steps ...Transformation) (Transformation, error) {
+	if len(steps) == 0 {
+		return Transformation{}, fmt.Errorf("a chain needs at least one step")
+	}
+	params := make(map[string]int)
+	for i, step := range steps {
+		if _, ok := chainSteps[step.T]; !ok {
+			return Transformation{}, fmt.Errorf("transformation type %d cannot be chained", step.T)
+		}
+		if err := step.CheckParams(); err != nil {
+			return Transformation{}, err
+		}
+		addChainStep(params, i, step.T, step.Params)
+	}
+	return Transformation{T: Chain, Params: params}, nil
+}
+
+// addChainStep adds step i, of type t with stepParams, to the Params of a Chain.
+func addChainStep(params map[string]int, i, t int, stepParams map[string]int) {
+	params[fmt.Sprintf("step_%d", i)] = t
+	for key, value := range stepParams {
+		params[fmt.Sprintf("%s_%d", key, i)] = value
+	}
+}
+
+// chainStepsOf returns the steps of the Params of a Chain, see ChainOf.
+func chainStepsOf(params map[string]int) ([]Transformation, error) {
+	var steps []Transformation
+	read := 0
+	for i := 0; ; i++ {
+		t, ok := params[fmt.Sprintf("step_%d", i)]
+		if !ok {
+			break
+		}
+		if _, ok := chainSteps[t]; !ok {
+			return nil, fmt.Errorf("transformation type %d of step %d cannot be chained", t, i)
+		}
+		step := Transformation{T: t, Params: map[string]int{}}
+		for key := range typeArgs[t].Params() {
+			value, ok := params[fmt.Sprintf("%s_%d", key, i)]
+			if !ok {
+				return nil, fmt.Errorf("missing parameter %q of step %d", key, i)
+			}
+			step.Params[key] = value
+		}
+		read += 1 + len(step.Params)
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("a chain needs at least one step")
+	}
+	if read != len(params) {
+		return nil, fmt.Errorf("unknown parameters of a chain of %d steps", len(steps))
+	}
+	return steps, nil
+}
+
+// chainTypes returns the types of the transformations of the steps of the Chain predicate id.
+func chainTypes(id CircuitID) ([]int, error) {
+	if id.Name != ChainCircuitID.Name || id.Steps == "" {
+		return nil, fmt.Errorf("circuit %s is not the predicate of a chain", id)
+	}
+	var types []int
+	for _, name := range strings.Split(id.Steps, ",") {
+		t, ok := chainType(name)
+		if !ok {
+			return nil, fmt.Errorf("circuit %s chains unknown predicate %q", id, name)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// chainType returns the type of the transformation of the chainSteps whose predicate is named name.
+func chainType(name string) (int, bool) {
+	for t := range chainSteps {
+		if stepCircuit(t).Name == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// stepCircuit returns the predicate of a transformation of type t, of the chainSteps.
+func stepCircuit(t int) CircuitID {
+	id, _ := Transformation{T: t}.Circuit()
+	return id
+}
+
+// chainEdit is the Edit of a Chain, whose predicate is composed of those of its steps.
+type chainEdit struct{}
+
+func (chainEdit) Circuit() CircuitID {
+	return ChainCircuitID
+}
+
+func (chainEdit) Params() []EditParam {
+	return nil
+}
+
+func (chainEdit) Apply(img myImage.I, params map[string]int) (myImage.I, error) {
+	steps, err := chainStepsOf(params)
+	if err != nil {
+		return myImage.I{}, err
+	}
+	out := img
+	for _, step := range steps {
+		if out, err = step.Apply(out); err != nil {
+			return myImage.I{}, err
+		}
+	}
+	return out, nil
+}
+
+func (edit chainEdit) Assignment(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+	id, err := edit.circuitOf(t.Params)
+	if err != nil {
+		return nil, err
+	}
+	return edit.assignment(id, t, in, out, statement)
+}
+
+func (chainEdit) circuitOf(params map[string]int) (CircuitID, error) {
+	steps, err := chainStepsOf(params)
+	if err != nil {
+		return CircuitID{}, err
+	}
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = stepCircuit(step.T).Name
+	}
+	id := ChainCircuitID
+	id.Steps = strings.Join(names, ",")
+	return id, nil
+}
+
+func (chainEdit) paramsOf(id CircuitID) []EditParam {
+	types, err := chainTypes(id)
+	if err != nil {
+		return nil
+	}
+	var params []EditParam
+	for i, t := range types {
+		for _, param := range stepCircuit(t).EditParams() {
+			params = append(params, EditParam{Name: fmt.Sprintf("%s_%d", param.Name, i), Identity: param.Identity})
+		}
+	}
+	return params
+}
+
+// assignment returns the assignment of the Chain predicate id that out is in transformed by t, a Chain of its
+// steps or an Identity, the Identity of every step.
+func (chainEdit) assignment(id CircuitID, t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+	types, err := chainTypes(id)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]Transformation, len(types))
+	if t.T == Chain {
+		if steps, err = chainStepsOf(t.Params); err != nil {
+			return nil, err
+		}
+	}
+
+	circuit := &ChainCircuit{
+		Statement:       statement.Statement,
+		PublicKey:       statement.PublicKey,
+		ImageSignature:  statement.ImageSignature,
+		Nonce:           statement.Nonce,
+		PrevProofHash:   statement.PrevProofHash,
+		Nullifier:       statement.Nullifier,
+		Hops:            statement.Hops,
+		ImageBytes:      statement.ImageBytes,
+		Metadata:        statement.Metadata,
+		PrevImageBytes:  statement.PrevImageBytes,
+		PrevMetadata:    statement.PrevMetadata,
+		Prev:            statement.Prev,
+		FrImage:         in.ToFrontendImage(),
+		ChainedImage_in: out.ToFrontendImage(),
+		Steps:           types,
+	}
+	img := in
+	for i, step := range steps {
+		stepID := stepCircuit(types[i])
+		public := PublicParams(stepID, step)
+		params := make([]frontend.Variable, 0, len(public))
+		for _, param := range stepID.EditParams() {
+			params = append(params, public[param.Name])
+		}
+		circuit.Params = append(circuit.Params, params...)
+		circuit.Secrets = append(circuit.Secrets, chainSteps[types[i]].secrets(step, img)...)
+		if img, err = step.Apply(img); err != nil {
+			return nil, err
+		}
+	}
+	return circuit, nil
+}
@@ -0,0 +1,115 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts chainPlanes(In, Steps, Params, Secrets) == Out, without the signature check of the ChainCircuit.
+type chainPixelsCircuit struct {
+	In      myImage.FrontendImage
+	Out     myImage.FrontendImage
+	Params  []frontend.Variable
+	Secrets []frontend.Variable
+	Steps   []int `gnark:"-"`
+}
+
+func (circuit *chainPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := chainPlanes(api, &planes, circuit.Steps, circuit.Params, circuit.Secrets); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+// chainPixels returns the circuit and the assignment of chainPixelsCircuit that the Chain t makes out of in.
+func chainPixels(t *testing.T, chain Transformation, in, out myImage.I) (*chainPixelsCircuit, *chainPixelsCircuit) {
+	id, err := chain.Circuit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assignment, err := EditAssignment(id, chain, in, out, EditStatement{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chained := assignment.(*ChainCircuit)
+	circuit := chainPixelsCircuit{Params: make([]frontend.Variable, len(chained.Params)), Secrets: make([]frontend.Variable, len(chained.Secrets)), Steps: chained.Steps}
+	return &circuit, &chainPixelsCircuit{In: chained.FrImage, Out: chained.ChainedImage_in, Params: chained.Params, Secrets: chained.Secrets, Steps: chained.Steps}
+}
+
+func TestChainPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// A crop, then a brightness adjustment, then a downscale, is the image the edits make one after the other
+	in := myImage.NoiseImage(testSeed)
+	steps := []Transformation{
+		{T: Crop, Params: map[string]int{"x0": 1, "y0": 2, "x1": 12, "y1": 9}},
+		{T: Brightness, Params: map[string]int{"delta": 30}},
+		{T: Downscale},
+	}
+	chain, err := ChainOf(steps...)
+	assert.NoError(err)
+	out, err := chain.Apply(in)
+	assert.NoError(err)
+	want := in
+	for _, step := range steps {
+		want, err = step.Apply(want)
+		assert.NoError(err)
+	}
+	assert.Equal(want.Pixels, out.Pixels)
+	assert.Equal(6, out.M.Width)
+
+	circuit, assignment := chainPixels(t, chain, in, out)
+	assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The public delta of the brightness is the parameter of the second step
+	id, err := chain.Circuit()
+	assert.NoError(err)
+	assert.Equal(CircuitID{Name: "chain", Version: ChainCircuitID.Version, Steps: "crop,brightness,downscale"}, id)
+	assert.Equal([]EditParam{{Name: "delta_1", Identity: 0}}, id.EditParams())
+	assert.Equal(map[string]int{"delta_1": 30}, PublicParams(id, chain))
+
+	// Another image, e.g. one brightened by another delta, is not the chain
+	other := *assignment
+	other.Params = []frontend.Variable{31}
+	assert.Error(test.IsSolved(circuit, &other, ecc.BN254.ScalarField()))
+
+	// The steps apply in their order: a flip then a rotation is not a rotation then a flip
+	portrait, err := in.SubImage(0, 0, 9, myImage.Height-1)
+	assert.NoError(err)
+	rotateFlip, err := ChainOf(Transformation{T: Rotate, Params: map[string]int{"quarters": 1}}, Transformation{T: FlipH})
+	assert.NoError(err)
+	flipRotate, err := ChainOf(Transformation{T: FlipH}, Transformation{T: Rotate, Params: map[string]int{"quarters": 1}})
+	assert.NoError(err)
+	flippedRotated, err := flipRotate.Apply(portrait)
+	assert.NoError(err)
+	swappedCircuit, swapped := chainPixels(t, rotateFlip, portrait, flippedRotated)
+	assert.Error(test.IsSolved(swappedCircuit, swapped, ecc.BN254.ScalarField()))
+
+	// An Identity keeps the image through every step, as the Generator compiles the chain
+	identity, err := EditAssignment(id, Transformation{T: Identity}, in, in, EditStatement{})
+	assert.NoError(err)
+	kept := identity.(*ChainCircuit)
+	assert.NoError(test.IsSolved(circuit, &chainPixelsCircuit{In: kept.FrImage, Out: kept.ChainedImage_in, Params: kept.Params, Secrets: kept.Secrets, Steps: kept.Steps}, ecc.BN254.ScalarField()))
+
+	// Only the edits of the chainSteps, with their parameters, are chained
+	for name, steps := range map[string][]Transformation{
+		"no step":           nil,
+		"a sharpen":         {{T: Sharpen, Params: map[string]int{"x0": 1, "y0": 1, "x1": 3, "y1": 3}}},
+		"a misspelled crop": {{T: Crop, Params: map[string]int{"x0": 1, "y0": 2, "x1": 12, "yl": 9}}},
+	} {
+		if _, err := ChainOf(steps...); err == nil {
+			t.Errorf("%s was chained", name)
+		}
+	}
+	if err := (Transformation{T: Chain, Params: map[string]int{"step_0": Brightness, "delta_0": 3, "delta_1": 4}}).CheckParams(); err == nil {
+		t.Error("the parameter of a missing step was accepted")
+	}
+}
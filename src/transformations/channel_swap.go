@@ -0,0 +1,63 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for ChannelSwap transformations: the signed image is the image FrImage with its channels
+// reordered or dropped by the public Sources, like image.I.SwapChannels does, e.g. red and blue swapped, or blue
+// dropped. Channel c of every pixel is channel Sources[c] of the pixel, or 0 for a source of
+// image.DroppedChannel. Sources of {0, 1, 2} keep the image.
+// Public fields: Statement, Sources
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, SwappedImage_in
+type ChannelSwapCircuit struct {
+	Statement       frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey       // Key the image is signed with
+	ImageSignature  eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable     // Digest of the signed image, SwappedImage_in
+	PrevImageBytes  frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Sources         [3]frontend.Variable  `gnark:",public"` // Sources of R, G and B, each in [0, 2] or image.DroppedChannel
+	Metadata        frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable     // MetadataDigest of the previous image
+	Prev            PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage         myImage.FrontendImage // z_in as a FrontendImage
+	SwappedImage_in myImage.FrontendImage // Swapped previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a channel swap.
+func (circuit *ChannelSwapCircuit) Define(api frontend.API) error {
+	// Swap the channels of the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	swapChannelPlanes(api, &planes, circuit.Sources)
+	swappedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.SwappedImage_in, &swappedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.SwappedImage_in)
+}
+
+// swapChannelPlanes reorders or drops the R, G and B planes by sources, in place, exactly like
+// image.I.SwapChannels does outside the circuit. It asserts every source is one of the channels or
+// image.DroppedChannel, whose indicator selects no plane.
+func swapChannelPlanes(api frontend.API, planes *[3]channelPlane, sources [3]frontend.Variable) {
+	in := *planes
+	for c, source := range sources {
+		isSource := valueIndicators(api, source, []int{0, 1, 2, myImage.DroppedChannel})
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				value := frontend.Variable(0)
+				for k := range in {
+					value = api.Add(value, api.Mul(isSource[k], in[k][y][x]))
+				}
+				planes[c][y][x] = value
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts swapChannelPlanes(In, Sources) == Out, without the signature check of the ChannelSwapCircuit.
+type channelSwapPixelsCircuit struct {
+	In      myImage.FrontendImage
+	Out     myImage.FrontendImage
+	Sources [3]frontend.Variable
+}
+
+func (circuit *channelSwapPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	swapChannelPlanes(api, &planes, circuit.Sources)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestSwapChannelPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	for _, sources := range [][3]int{{0, 1, 2}, {2, 1, 0}, {1, 2, 0}, {0, 1, myImage.DroppedChannel}, {1, 1, myImage.DroppedChannel}} {
+		out, err := in.SwapChannels(sources)
+		assert.NoError(err)
+
+		assignment := channelSwapPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage()}
+		for c, source := range sources {
+			assignment.Sources[c] = source
+		}
+		assert.NoError(test.IsSolved(&channelSwapPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "sources %v", sources)
+
+		// The image swapped from another source is not the swapped image
+		assignment.Sources[0] = (sources[0] + 1) % (myImage.DroppedChannel + 1)
+		assert.Error(test.IsSolved(&channelSwapPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "sources %v", sources)
+	}
+
+	// Every source is a channel, or the dropped channel
+	black := myImage.I{}.ToFrontendImage()
+	for _, sources := range [][3]frontend.Variable{{0, 1, myImage.DroppedChannel + 1}, {-1, 1, 2}} {
+		assignment := channelSwapPixelsCircuit{In: black, Out: black, Sources: sources}
+		assert.Error(test.IsSolved(&channelSwapPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "sources %v", sources)
+	}
+}
@@ -0,0 +1,903 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// Seed used to derive the camera key in circuit tests, so that the signature and
+// therefore the public witness are identical on every run.
+const testSeed = 1
+
+// Nonce the test image is signed for.
+const testNonce = 7
+
+// A compliance predicate under test.
+// circuit is the empty circuit used for compiling, assignment is a valid witness for it.
+// skip, when set, is the reason the circuit cannot be exercised in the current tree.
+type circuitCase struct {
+	name       string
+	circuit    frontend.Circuit
+	assignment frontend.Circuit
+	params     map[string]int // public parameters of an edit, see PublicParams
+	edit       bool           // a single image edit, whose golden proof package edits records
+	steps      string         // steps of a composed predicate, see CircuitID
+	skip       string
+}
+
+// The CircuitID of the predicate of a case.
+func (c circuitCase) circuitID() CircuitID {
+	id := circuits[c.name]
+	id.Steps = c.steps
+	return id
+}
+
+// Sign the given image and testNonce with a key derived from testSeed and return the
+// eddsa.PublicKey and eddsa.Signature as circuit assignments.
+func signedTestImage(t testing.TB, img myImage.I) (eddsa.PublicKey, eddsa.Signature) {
+	t.Helper()
+	return signedTestCapture(t, img, testNonce)
+}
+
+// Like signedTestImage, for the capture nonce.
+func signedTestCapture(t testing.TB, img myImage.I, nonce int64) (eddsa.PublicKey, eddsa.Signature) {
+	t.Helper()
+	return signedTestDigest(t, img.Digest(), nonce)
+}
+
+// Like signedTestCapture, for the digest of a capture, e.g. of a RAW capture.
+func signedTestDigest(t testing.TB, digest []byte, nonce int64) (eddsa.PublicKey, eddsa.Signature) {
+	t.Helper()
+	return signedTestStatement(t, digest, nonce, 0)
+}
+
+// Like signedTestImage, for an edit of the image made from the proof whose hash is prevProofHash.
+func signedTestEdit(t testing.TB, img myImage.I, prevProofHash int64) (eddsa.PublicKey, eddsa.Signature) {
+	t.Helper()
+	return signedTestStatement(t, img.Digest(), testNonce, prevProofHash)
+}
+
+// Sign the statement of digest, nonce and prevProofHash, see image.Statement.
+func signedTestStatement(t testing.TB, digest []byte, nonce int64, prevProofHash int64) (eddsa.PublicKey, eddsa.Signature) {
+	t.Helper()
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	normalSignature, err := secretKey.Sign(myImage.Statement(digest, big.NewInt(nonce), big.NewInt(prevProofHash)), hashsuite.Default.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, secretKey.Public().Bytes())
+
+	var eddsa_signature eddsa.Signature
+	eddsa_signature.Assign(1, normalSignature)
+
+	return eddsa_publicKey, eddsa_signature
+}
+
+// The StatementDigest of a statement assigned to a compliance predicate, e.g. with the key and signature of
+// signedTestStatement, which is the Statement the predicate is assigned along with it.
+func testStatement(t testing.TB, publicKey eddsa.PublicKey, signature eddsa.Signature, nonce, prevProofHash, nullifier, hops, imageBytes, prevImageBytes frontend.Variable, prevPublicKey eddsa.PublicKey) *big.Int {
+	t.Helper()
+
+	values := []frontend.Variable{publicKey.A.X, publicKey.A.Y, signature.R.X, signature.R.Y, signature.S, nonce, prevProofHash, nullifier, hops, imageBytes, prevImageBytes, prevPublicKey.A.X, prevPublicKey.A.Y}
+	elements := make([]*big.Int, len(values))
+	for i, value := range values {
+		switch value := value.(type) {
+		case []byte:
+			elements[i] = new(big.Int).SetBytes(value)
+		case *big.Int:
+			elements[i] = value
+		case int:
+			elements[i] = big.NewInt(int64(value))
+		case int64:
+			elements[i] = big.NewInt(value)
+		default:
+			t.Fatalf("unexpected value %v of a statement", value)
+		}
+	}
+	return PublicDigest(elements...)
+}
+
+// The Nullifier of testNonce for the key derived from testSeed.
+func testNullifier(t testing.TB) *big.Int {
+	t.Helper()
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nullifier, err := Nullifier(secretKey.Public().Bytes(), big.NewInt(testNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nullifier
+}
+
+// Every compliance predicate in this package, with a valid assignment over an all white image.
+func circuitCases(t testing.TB) []circuitCase {
+	img := myImage.AllWhiteImage()
+	publicKey, signature := signedTestImage(t, img)
+	_, nextSignature := signedTestCapture(t, img, testNonce+1)
+	nullifier := testNullifier(t)
+	prev := PrevSignature{PublicKey: publicKey, Signature: signature, PrevProofHash: 0} // The original image is its own previous image
+
+	// The metadata of the image, credited to its own author, which keeps it
+	credit, err := CreditCircuitParams(img, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The image, merged with itself from three captures
+	var hdr HDRCircuit
+	hdr.PublicKey = publicKey
+	hdr.ImageDigest = RegionDigest(img)
+	hdr.Merged = img.ToFrontendImage()
+	for i := range hdr.Exposures {
+		_, hdr.Signatures[i] = signedTestCapture(t, img, testNonce+int64(i))
+		hdr.Nonces[i] = testNonce + i
+		hdr.Weights[i] = i + 1
+		hdr.ImageBytes[i] = img.Digest()
+		hdr.Metadata[i] = img.MetadataDigest()
+		hdr.Exposures[i] = img.ToFrontendPacked()
+	}
+
+	// The image, developed from a white RAW capture without white balance
+	raw := myImage.RAW{M: myImage.Metadata{Width: myImage.Width, Height: myImage.Height}}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			raw.Samples[y][x] = rawMax
+		}
+	}
+	develop := DevelopCircuit{Nonce: testNonce, ImageDigest: RegionDigest(img), RAWBytes: raw.Digest(), Metadata: raw.MetadataDigest(), RAW: raw.ToFrontendRAW(), Developed: img.ToFrontendImage()}
+	develop.PublicKey, develop.ImageSignature = signedTestDigest(t, raw.Digest(), testNonce)
+	for c := range develop.Gains {
+		develop.Gains[c] = GainOne
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				develop.Balanced[c][y][x] = rawMax
+			}
+		}
+	}
+
+	// The grayscale image of the image, not cropped
+	gray := img.ToGray()
+	grayscale := GrayCircuit{
+		Nonce:       testNonce,
+		ImageDigest: GrayDigest(gray),
+		Area:        CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+		ImageBytes:  gray.Digest(),
+		Metadata:    gray.MetadataDigest(),
+		Capture:     gray.ToFrontendGray(),
+	}
+	grayscale.PublicKey, grayscale.ImageSignature = signedTestDigest(t, gray.Digest(), testNonce)
+
+	// The deep color image of the image, not cropped
+	deepImage := img.ToDeep()
+	deep := DeepCircuit{
+		Nonce:       testNonce,
+		ImageDigest: DeepDigest(deepImage),
+		Area:        CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+		ImageBytes:  deepImage.Digest(),
+		Metadata:    deepImage.MetadataDigest(),
+		Capture:     deepImage.ToFrontendImage(),
+	}
+	deep.PublicKey, deep.ImageSignature = signedTestDigest(t, deepImage.Digest(), testNonce)
+
+	// The image, twice, as a 1 x 2 collage
+	diptych := NewCollageCircuit(1, 2)
+	diptych.Rows = 1
+	diptych.Cols = 2
+	diptych.ImageDigest = RegionDigest(img)
+	for i := range diptych.Inputs {
+		diptych.Inputs[i] = CollageInput{
+			PublicKey:      publicKey,
+			ImageSignature: signature,
+			Nonce:          testNonce,
+			PrevProofHash:  0,
+			ImageBytes:     img.Digest(),
+			Metadata:       img.MetadataDigest(),
+			Image:          img.ToFrontendPacked(),
+		}
+	}
+
+	// An all white watermark, stamped as it is on the image
+	var white myImage.Watermark
+	var whiteWatermark [myImage.WatermarkHeight][myImage.WatermarkWidth]frontend.Variable
+	for j := range white {
+		for i := range white[j] {
+			white[j][i] = myImage.RGBPixel{R: 255, G: 255, B: 255}
+			whiteWatermark[j][i] = 0xffffff
+		}
+	}
+
+	return []circuitCase{
+		{
+			name:    "identity",
+			circuit: &IdentityCircuit{},
+			assignment: &IdentityCircuit{
+				Statement:      testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:      publicKey,
+				ImageSignature: signature,
+				Nonce:          testNonce,
+				PrevProofHash:  0,
+				Nullifier:      nullifier,
+				Hops:           0,
+				ImageBytes:     img.Digest(),
+				PrevImageBytes: img.Digest(),
+				Metadata:       img.MetadataDigest(),
+				FrImage:        img.ToFrontendImage(),
+			},
+		},
+		{
+			name:    "crop",
+			circuit: &CropCircuit{},
+			assignment: &CropCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				CroppedImage_in: img.ToFrontendImage(),
+				Params:          CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+			},
+		},
+		{
+			name:    "rotate",
+			edit:    true,
+			circuit: &RotateCircuit{},
+			assignment: &RotateCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				RotatedImage_in: img.ToFrontendImage(),
+				Params:          RotateCircuitParams(img, 2), // all white, so a half turn keeps the image
+			},
+		},
+		{
+			name:    "fliph",
+			edit:    true,
+			circuit: &FlipHCircuit{},
+			assignment: &FlipHCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				FlippedImage_in: img.ToFrontendImage(),
+				Params:          FlipHCircuitParams(img, true), // all white, so the flip keeps the image
+			},
+		},
+		{
+			name:    "flipv",
+			edit:    true,
+			circuit: &FlipVCircuit{},
+			assignment: &FlipVCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				FlippedImage_in: img.ToFrontendImage(),
+				Params:          FlipVCircuitParams(img, true), // all white, so the flip keeps the image
+			},
+		},
+		{
+			name:    "downscale",
+			edit:    true,
+			circuit: &DownscaleCircuit{},
+			assignment: &DownscaleCircuit{
+				Statement:      testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:      publicKey,
+				ImageSignature: signature,
+				Nonce:          testNonce,
+				PrevProofHash:  0,
+				Nullifier:      nullifier,
+				Hops:           0,
+				ImageBytes:     img.Digest(),
+				PrevImageBytes: img.Digest(),
+				Metadata:       img.MetadataDigest(),
+				PrevMetadata:   img.MetadataDigest(),
+				Prev:           prev,
+				FrImage:        img.ToFrontendImage(),
+				ScaledImage_in: img.ToFrontendImage(),
+				Params:         DownscaleCircuitParams(img, false), // an original image, proven with the keys of a downscale
+			},
+		},
+		{
+			name:    "rotatecrop",
+			edit:    true,
+			circuit: &RotateCropCircuit{},
+			assignment: &RotateCropCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				CroppedImage_in: img.ToFrontendImage(),
+				Params:          RotateCropCircuitParams(img, 2, 0, 0, myImage.Width-1, myImage.Height-1), // all white, so the half turn keeps the image
+			},
+		},
+		{
+			name:    "brightness",
+			edit:    true,
+			circuit: &BrightnessCircuit{},
+			assignment: &BrightnessCircuit{
+				Statement:          testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:          publicKey,
+				ImageSignature:     signature,
+				Nonce:              testNonce,
+				PrevProofHash:      0,
+				Nullifier:          nullifier,
+				Hops:               0,
+				Delta:              40, // all white, so the brightened image is clamped to white
+				ImageBytes:         img.Digest(),
+				PrevImageBytes:     img.Digest(),
+				Metadata:           img.MetadataDigest(),
+				PrevMetadata:       img.MetadataDigest(),
+				Prev:               prev,
+				FrImage:            img.ToFrontendImage(),
+				BrightenedImage_in: img.ToFrontendImage(),
+				Params:             SizeCircuitParams(img),
+			},
+			params: map[string]int{"delta": 40},
+		},
+		{
+			name:    "gamma",
+			edit:    true,
+			circuit: &GammaCircuit{},
+			assignment: &GammaCircuit{
+				Statement:         testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:         publicKey,
+				ImageSignature:    signature,
+				Nonce:             testNonce,
+				PrevProofHash:     0,
+				Nullifier:         nullifier,
+				Hops:              0,
+				Gamma:             220, // all white, which every gamma keeps
+				ImageBytes:        img.Digest(),
+				PrevImageBytes:    img.Digest(),
+				Metadata:          img.MetadataDigest(),
+				PrevMetadata:      img.MetadataDigest(),
+				Prev:              prev,
+				FrImage:           img.ToFrontendImage(),
+				CorrectedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"gamma": 220},
+		},
+		{
+			name:    "sepia",
+			edit:    true,
+			circuit: &SepiaCircuit{},
+			assignment: &SepiaCircuit{
+				Statement:      testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:      publicKey,
+				ImageSignature: signature,
+				Nonce:          testNonce,
+				PrevProofHash:  0,
+				Nullifier:      nullifier,
+				Hops:           0,
+				ImageBytes:     img.Digest(),
+				PrevImageBytes: img.Digest(),
+				Metadata:       img.MetadataDigest(),
+				PrevMetadata:   img.MetadataDigest(),
+				Prev:           prev,
+				FrImage:        img.ToFrontendImage(),
+				TonedImage_in:  img.ToFrontendImage(),
+				Params:         SepiaCircuitParams(false), // white is not white in sepia, so the tone keeps the image
+			},
+		},
+		{
+			name:    "huesaturation",
+			edit:    true,
+			circuit: &HueSaturationCircuit{},
+			assignment: &HueSaturationCircuit{
+				Statement:        testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:        publicKey,
+				ImageSignature:   signature,
+				Nonce:            testNonce,
+				PrevProofHash:    0,
+				Nullifier:        nullifier,
+				Hops:             0,
+				Hue:              90, // all white, which every hue rotation and saturation keeps
+				Saturation:       384,
+				ImageBytes:       img.Digest(),
+				PrevImageBytes:   img.Digest(),
+				Metadata:         img.MetadataDigest(),
+				PrevMetadata:     img.MetadataDigest(),
+				Prev:             prev,
+				FrImage:          img.ToFrontendImage(),
+				AdjustedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"hue": 90, "saturation": 384},
+		},
+		{
+			name:    "gain",
+			edit:    true,
+			circuit: &GainCircuit{},
+			assignment: &GainCircuit{
+				Statement:        testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:        publicKey,
+				ImageSignature:   signature,
+				Nonce:            testNonce,
+				PrevProofHash:    0,
+				Nullifier:        nullifier,
+				Hops:             0,
+				Gains:            [3]frontend.Variable{384, 512, 256}, // all white, so every gain of at least 1 keeps the image
+				ImageBytes:       img.Digest(),
+				PrevImageBytes:   img.Digest(),
+				Metadata:         img.MetadataDigest(),
+				PrevMetadata:     img.MetadataDigest(),
+				Prev:             prev,
+				FrImage:          img.ToFrontendImage(),
+				BalancedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"gain_r": 384, "gain_g": 512, "gain_b": 256},
+		},
+		{
+			name:    "threshold",
+			edit:    true,
+			circuit: &ThresholdCircuit{},
+			assignment: &ThresholdCircuit{
+				Statement:         testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:         publicKey,
+				ImageSignature:    signature,
+				Nonce:             testNonce,
+				PrevProofHash:     0,
+				Nullifier:         nullifier,
+				Hops:              0,
+				Threshold:         200, // all white, whose luma is above the threshold
+				ImageBytes:        img.Digest(),
+				PrevImageBytes:    img.Digest(),
+				Metadata:          img.MetadataDigest(),
+				PrevMetadata:      img.MetadataDigest(),
+				Prev:              prev,
+				FrImage:           img.ToFrontendImage(),
+				BinarizedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"threshold": 200},
+		},
+		{
+			name:    "channelswap",
+			edit:    true,
+			circuit: &ChannelSwapCircuit{},
+			assignment: &ChannelSwapCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				Sources:         [3]frontend.Variable{2, 0, 1}, // all white, which every permutation keeps
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				SwappedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"source_r": 2, "source_g": 0, "source_b": 1},
+		},
+		{
+			name:    "redact",
+			edit:    true,
+			circuit: &RedactCircuit{},
+			assignment: &RedactCircuit{
+				Statement:        testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:        publicKey,
+				ImageSignature:   signature,
+				Nonce:            testNonce,
+				PrevProofHash:    0,
+				Nullifier:        nullifier,
+				Hops:             0,
+				Region:           CropParams{X0: 0, Y0: 0, X1: -1, Y1: -1}, // an empty region, which keeps the image
+				ImageBytes:       img.Digest(),
+				PrevImageBytes:   img.Digest(),
+				Metadata:         img.MetadataDigest(),
+				PrevMetadata:     img.MetadataDigest(),
+				Prev:             prev,
+				FrImage:          img.ToFrontendImage(),
+				RedactedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"x0": 0, "y0": 0, "x1": -1, "y1": -1},
+		},
+		{
+			name:    "mosaic",
+			edit:    true,
+			circuit: &MosaicCircuit{},
+			assignment: &MosaicCircuit{
+				Statement:         testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:         publicKey,
+				ImageSignature:    signature,
+				Nonce:             testNonce,
+				PrevProofHash:     0,
+				Nullifier:         nullifier,
+				Hops:              0,
+				Region:            CropParams{X0: 2, Y0: 1, X1: 13, Y1: 10}, // all white, whose blocks average white
+				Block:             4,
+				ImageBytes:        img.Digest(),
+				PrevImageBytes:    img.Digest(),
+				Metadata:          img.MetadataDigest(),
+				PrevMetadata:      img.MetadataDigest(),
+				Prev:              prev,
+				FrImage:           img.ToFrontendImage(),
+				PixelatedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"x0": 2, "y0": 1, "x1": 13, "y1": 10, "block": 4},
+		},
+		{
+			name:    "blur",
+			edit:    true,
+			circuit: &BlurCircuit{},
+			assignment: &BlurCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				Region:          CropParams{X0: 1, Y0: 1, X1: 0, Y1: 0}, // an empty region, which keeps the image
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				BlurredImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"x0": 1, "y0": 1, "x1": 0, "y1": 0},
+		},
+		{
+			name:    "sharpen",
+			edit:    true,
+			circuit: &SharpenCircuit{},
+			assignment: &SharpenCircuit{
+				Statement:         testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:         publicKey,
+				ImageSignature:    signature,
+				Nonce:             testNonce,
+				PrevProofHash:     0,
+				Nullifier:         nullifier,
+				Hops:              0,
+				Region:            CropParams{X0: 1, Y0: 1, X1: 0, Y1: 0}, // an empty region, which keeps the image
+				ImageBytes:        img.Digest(),
+				PrevImageBytes:    img.Digest(),
+				Metadata:          img.MetadataDigest(),
+				PrevMetadata:      img.MetadataDigest(),
+				Prev:              prev,
+				FrImage:           img.ToFrontendImage(),
+				SharpenedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"x0": 1, "y0": 1, "x1": 0, "y1": 0},
+		},
+		{
+			name:    "watermark",
+			edit:    true,
+			circuit: &WatermarkCircuit{},
+			assignment: &WatermarkCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				X:               3, // a white watermark, which keeps the image
+				Y:               2,
+				Watermark:       whiteWatermark,
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				StampedImage_in: img.ToFrontendImage(),
+			},
+			params: WatermarkParams(&white, 3, 2),
+		},
+		{
+			name:    "caption",
+			edit:    true,
+			circuit: &CaptionCircuit{},
+			assignment: &CaptionCircuit{
+				Statement:         testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:         publicKey,
+				ImageSignature:    signature,
+				Nonce:             testNonce,
+				PrevProofHash:     0,
+				Nullifier:         nullifier,
+				Hops:              0,
+				Y:                 myImage.Height, // past the bottom edge, which keeps the image
+				Caption:           [myImage.CaptionHeight]frontend.Variable{0, 0, 0, 0},
+				ImageBytes:        img.Digest(),
+				PrevImageBytes:    img.Digest(),
+				Metadata:          img.MetadataDigest(),
+				PrevMetadata:      img.MetadataDigest(),
+				Prev:              prev,
+				FrImage:           img.ToFrontendImage(),
+				CaptionedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"y": myImage.Height, "caption_0": 0, "caption_1": 0, "caption_2": 0, "caption_3": 0},
+		},
+		{
+			name:    "border",
+			edit:    true,
+			circuit: &BorderCircuit{},
+			assignment: &BorderCircuit{
+				Statement:        testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:        publicKey,
+				ImageSignature:   signature,
+				Nonce:            testNonce,
+				PrevProofHash:    0,
+				Nullifier:        nullifier,
+				Hops:             0,
+				Width:            2,
+				Color:            [3]frontend.Variable{255, 255, 255}, // white, which keeps the image
+				ImageBytes:       img.Digest(),
+				PrevImageBytes:   img.Digest(),
+				Metadata:         img.MetadataDigest(),
+				PrevMetadata:     img.MetadataDigest(),
+				Prev:             prev,
+				FrImage:          img.ToFrontendImage(),
+				BorderedImage_in: img.ToFrontendImage(),
+				ImageWidth:       myImage.Width,
+				ImageHeight:      myImage.Height,
+			},
+			params: map[string]int{"width": 2, "r": 255, "g": 255, "b": 255},
+		},
+		{
+			name:    "convolution",
+			edit:    true,
+			circuit: &ConvolutionCircuit{},
+			assignment: &ConvolutionCircuit{
+				Statement:         testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:         publicKey,
+				ImageSignature:    signature,
+				Nonce:             testNonce,
+				PrevProofHash:     0,
+				Nullifier:         nullifier,
+				Hops:              0,
+				Region:            CropParams{X0: 1, Y0: 1, X1: myImage.Width - 2, Y1: myImage.Height - 2},
+				Kernel:            1, // the box blur, which keeps an all white image
+				ImageBytes:        img.Digest(),
+				PrevImageBytes:    img.Digest(),
+				Metadata:          img.MetadataDigest(),
+				PrevMetadata:      img.MetadataDigest(),
+				Prev:              prev,
+				FrImage:           img.ToFrontendImage(),
+				ConvolvedImage_in: img.ToFrontendImage(),
+			},
+			params: map[string]int{"x0": 1, "y0": 1, "x1": myImage.Width - 2, "y1": myImage.Height - 2, "kernel": 1},
+		},
+		{
+			name:    "policy",
+			edit:    true,
+			circuit: &PolicyCircuit{},
+			assignment: &PolicyCircuit{
+				Statement:      testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:      publicKey,
+				ImageSignature: signature,
+				Nonce:          testNonce,
+				PrevProofHash:  0,
+				Nullifier:      nullifier,
+				Hops:           0,
+				Selector:       Rotate,
+				ImageBytes:     img.Digest(),
+				PrevImageBytes: img.Digest(),
+				Metadata:       img.MetadataDigest(),
+				PrevMetadata:   img.MetadataDigest(),
+				Prev:           prev,
+				FrImage:        img.ToFrontendImage(),
+				EditedImage_in: img.ToFrontendImage(),
+				Params:         RotateCropCircuitParams(img, 2, 0, 0, myImage.Width-1, myImage.Height-1), // all white, so the half turn keeps the image
+			},
+			params: map[string]int{"selector": Rotate},
+		},
+		{
+			name:  "chain",
+			edit:  true,
+			steps: "crop,brightness,downscale",
+			circuit: &ChainCircuit{
+				Params:  make([]frontend.Variable, 1),
+				Secrets: make([]frontend.Variable, 4+2+3),
+				Steps:   []int{Crop, Brightness, Downscale},
+			},
+			assignment: &ChainCircuit{
+				Statement:       testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:       publicKey,
+				ImageSignature:  signature,
+				Nonce:           testNonce,
+				PrevProofHash:   0,
+				Nullifier:       nullifier,
+				Hops:            0,
+				Params:          []frontend.Variable{40}, // all white, so the brightness keeps the image
+				ImageBytes:      img.Digest(),
+				PrevImageBytes:  img.Digest(),
+				Metadata:        img.MetadataDigest(),
+				PrevMetadata:    img.MetadataDigest(),
+				Prev:            prev,
+				FrImage:         img.ToFrontendImage(),
+				ChainedImage_in: img.ToFrontendImage(),
+				Secrets: []frontend.Variable{
+					0, 0, myImage.Width - 1, myImage.Height - 1, // crop of the whole image
+					myImage.Width, myImage.Height, // size of the brightened image
+					0, myImage.Width, myImage.Height, // no downscale
+				},
+				Steps: []int{Crop, Brightness, Downscale},
+			},
+			params: map[string]int{"delta_1": 40},
+		},
+		{
+			name:    "credit",
+			edit:    true,
+			circuit: &CreditCircuit{},
+			assignment: &CreditCircuit{
+				Statement:        testStatement(t, publicKey, signature, testNonce, 0, nullifier, 0, img.Digest(), img.Digest(), publicKey),
+				PublicKey:        publicKey,
+				ImageSignature:   signature,
+				Nonce:            testNonce,
+				PrevProofHash:    0,
+				Nullifier:        nullifier,
+				Hops:             0,
+				ImageBytes:       img.Digest(),
+				PrevImageBytes:   img.Digest(),
+				Metadata:         img.MetadataDigest(),
+				PrevMetadata:     img.MetadataDigest(),
+				Prev:             prev,
+				FrImage:          img.ToFrontendImage(),
+				CreditedImage_in: img.ToFrontendImage(),
+				Params:           credit,
+			},
+		},
+		{
+			name:    "disclosure",
+			circuit: &DisclosureCircuit{},
+			assignment: &DisclosureCircuit{
+				PublicKey:      publicKey,
+				Nonce:          testNonce,
+				RegionDigest:   RegionDigest(img),
+				Width:          myImage.Width,
+				Height:         myImage.Height,
+				ImageSignature: signature,
+				ImageBytes:     img.Digest(),
+				Metadata:       img.MetadataDigest(),
+				Original:       img.ToFrontendPacked(),
+				Params:         CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+			},
+		},
+		{
+			name:    "similarity",
+			circuit: &SimilarityCircuit{},
+			assignment: &SimilarityCircuit{
+				PublicKey:      publicKey,
+				Nonce:          testNonce,
+				ImageDigest:    RegionDigest(img),
+				Norm:           int(L2),
+				Bound:          0,
+				ImageSignature: signature,
+				ImageBytes:     img.Digest(),
+				Metadata:       img.MetadataDigest(),
+				Original:       img.ToFrontendPacked(),
+				Published:      img.ToFrontendImage(),
+			},
+		},
+		{
+			name:    "panorama",
+			circuit: &PanoramaCircuit{},
+			assignment: &PanoramaCircuit{
+				PublicKey:      publicKey,
+				LeftNonce:      testNonce,
+				RightNonce:     testNonce + 1,
+				ImageDigest:    RegionDigest(img),
+				Seam:           myImage.Width / 2,
+				Overlap:        MaxPanoramaOverlap,
+				LeftSignature:  signature,
+				RightSignature: nextSignature,
+				LeftBytes:      img.Digest(),
+				LeftMetadata:   img.MetadataDigest(),
+				RightBytes:     img.Digest(),
+				RightMetadata:  img.MetadataDigest(),
+				Left:           img.ToFrontendPacked(),
+				Right:          img.ToFrontendPacked(),
+				Panorama:       img.ToFrontendImage(),
+			},
+		},
+		{
+			name:       "hdr",
+			circuit:    &HDRCircuit{},
+			assignment: &hdr,
+		},
+		{
+			name:       "develop",
+			circuit:    &DevelopCircuit{},
+			assignment: &develop,
+		},
+		{
+			name:       "gray",
+			circuit:    &GrayCircuit{},
+			assignment: &grayscale,
+		},
+		{
+			name:       "deep",
+			circuit:    &DeepCircuit{},
+			assignment: &deep,
+		},
+		{
+			name:    "frame",
+			circuit: &FrameCircuit{},
+			assignment: &FrameCircuit{
+				PublicKey:      publicKey,
+				Nonce:          testNonce,
+				FrameDigest:    RegionDigest(img),
+				Area:           CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+				ImageSignature: signature,
+				ImageBytes:     img.Digest(),
+				Metadata:       img.MetadataDigest(),
+				Capture:        img.ToFrontendPacked(),
+			},
+		},
+		{
+			name:       "collage",
+			circuit:    NewCollageCircuit(1, 2),
+			assignment: diptych,
+		},
+	}
+}
@@ -0,0 +1,176 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit proves that an image is a collage: a grid of Rows x Cols cells, each holding the top left
+// cell of one input image, inputs in row major order. Crop an input first to choose the part of it that
+// shows; a diptych is a 1 x 2 collage.
+//
+// Every input is bound like the input of the CropCircuit: through the public statement of its proof, i.e.
+// the key, signature, nonce and hash of the previous proof of the input's image, so the verifier checks the
+// provenance of every input with its own proof. The collage itself is bound through a single public input,
+// its ImageDigest (see RegionDigest), which the verifier recomputes from the published collage.
+//
+// The Image of every input is packed (see image.I.Pack), and its signed ImageBytes are its digest, which the
+// circuit recomputes from the packed elements and Metadata.
+//
+// A circuit is compiled for one grid, see NewCollageCircuit.
+//
+// Public fields: Rows, Cols, ImageDigest, and PublicKey, ImageSignature, Nonce, PrevProofHash of every input
+// Secret fields: ImageBytes, Metadata, Image of every input
+type CollageCircuit struct {
+	Rows        frontend.Variable `gnark:",public"` // rows of the grid
+	Cols        frontend.Variable `gnark:",public"` // columns of the grid
+	ImageDigest frontend.Variable `gnark:",public"` // RegionDigest of the collage
+	Inputs      []CollageInput
+
+	rows, cols int // grid the circuit is compiled for
+}
+
+// An input image of a collage, and the statement of its proof.
+type CollageInput struct {
+	PublicKey      eddsa.PublicKey        `gnark:",public"`
+	ImageSignature eddsa.Signature        `gnark:",public"` // Digital signature as eddsa.Signature
+	Nonce          frontend.Variable      `gnark:",public"` // Capture counter, see image.Statement
+	PrevProofHash  frontend.Variable      `gnark:",public"` // Hash of the proof the input was edited from
+	ImageBytes     frontend.Variable      // Digest of the input
+	Metadata       frontend.Variable      // MetadataDigest of the input
+	Image          myImage.FrontendPacked // input as a FrontendPacked
+}
+
+// NewCollageCircuit returns an empty CollageCircuit for a grid of rows x cols cells, for compiling or to
+// assign. Its grid must pass CheckGrid.
+func NewCollageCircuit(rows, cols int) *CollageCircuit {
+	return &CollageCircuit{Inputs: make([]CollageInput, rows*cols), rows: rows, cols: cols}
+}
+
+// CheckGrid returns an error if images cannot be composed into a grid of rows x cols cells: the cells must
+// divide the image evenly.
+func CheckGrid(rows, cols int) error {
+	if rows < 1 || cols < 1 || myImage.Height%rows != 0 || myImage.Width%cols != 0 {
+		return fmt.Errorf("invalid collage grid %d x %d: rows must divide the image height %d, and columns its width %d", rows, cols, myImage.Height, myImage.Width)
+	}
+	return nil
+}
+
+// Defines the Compliance Predicate of a collage.
+func (circuit *CollageCircuit) Define(api frontend.API) error {
+	if err := CheckGrid(circuit.rows, circuit.cols); err != nil {
+		return err
+	}
+	if len(circuit.Inputs) != circuit.rows*circuit.cols {
+		return fmt.Errorf("a %d x %d collage has %d inputs, not %d", circuit.rows, circuit.cols, circuit.rows*circuit.cols, len(circuit.Inputs))
+	}
+	api.AssertIsEqual(circuit.Rows, circuit.rows)
+	api.AssertIsEqual(circuit.Cols, circuit.cols)
+
+	// Every pixel of the collage is a constant pixel of an input, so the composition costs no constraints.
+	// Unpacking the inputs range checks their channels to 8 bits, so the collage packs unambiguously.
+	images := make([]myImage.FrontendImage, len(circuit.Inputs))
+	for i := range circuit.Inputs {
+		var err error
+		if images[i], err = unpackImage(api, &circuit.Inputs[i].Image); err != nil {
+			return err
+		}
+	}
+	var collage myImage.FrontendImage
+	cellWidth, cellHeight := myImage.Width/circuit.cols, myImage.Height/circuit.rows
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			input := images[(y/cellHeight)*circuit.cols+x/cellWidth]
+			collage.Pixels[y][x] = input.Pixels[y%cellHeight][x%cellWidth]
+		}
+	}
+	if err := assertPublicDigest(api, circuit.ImageDigest, packChannels(api, regionChannels(&collage))...); err != nil {
+		return err
+	}
+
+	// Verify the signature over the statement of every input, whose ImageBytes are the digest of its Image
+	for _, input := range circuit.Inputs {
+		if err := assertPackedDigest(api, input.ImageBytes, input.Metadata, input.Image.Elements[:]); err != nil {
+			return err
+		}
+		if err := assertSignedStatement(api, input.PublicKey, input.ImageSignature, input.ImageBytes, input.Nonce, input.PrevProofHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compose returns the collage of inputs in a grid of rows x cols cells, as the CollageCircuit composes it.
+// Its metadata only holds its width and height.
+func Compose(rows, cols int, inputs []myImage.I) (myImage.I, error) {
+	if err := CheckGrid(rows, cols); err != nil {
+		return myImage.I{}, err
+	}
+	if len(inputs) != rows*cols {
+		return myImage.I{}, fmt.Errorf("a %d x %d collage has %d inputs, not %d", rows, cols, rows*cols, len(inputs))
+	}
+
+	collage := myImage.I{M: myImage.Metadata{Width: myImage.Width, Height: myImage.Height}}
+	cellWidth, cellHeight := myImage.Width/cols, myImage.Height/rows
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			collage.Pixels[y][x] = inputs[(y/cellHeight)*cols+x/cellWidth].Pixels[y%cellHeight][x%cellWidth]
+		}
+	}
+	return collage, nil
+}
+
+// A SignedStatement is the public statement of a proof: the key and signature over its image, its nonce and
+// the hash of the previous proof, see image.Statement.
+type SignedStatement struct {
+	PublicKey     []byte
+	Signature     []byte
+	Nonce         *big.Int
+	PrevProofHash *big.Int
+}
+
+// CollageWitness returns the public witness of a proof that collage is the composition, in a grid of rows x
+// cols cells, of the images of the proofs with the given statements. The verifier builds it from the published
+// collage and the proofs of its inputs.
+func CollageWitness(rows, cols int, collage myImage.I, inputs []SignedStatement) (witness.Witness, error) {
+	if err := CheckGrid(rows, cols); err != nil {
+		return nil, err
+	}
+	if len(inputs) != rows*cols {
+		return nil, fmt.Errorf("a %d x %d collage has %d inputs, not %d", rows, cols, rows*cols, len(inputs))
+	}
+
+	assignment := NewCollageCircuit(rows, cols)
+	assignment.Rows = rows
+	assignment.Cols = cols
+	assignment.ImageDigest = RegionDigest(collage)
+	for i, input := range inputs {
+		// Assign panics on invalid points, and both come from the proofs being verified
+		var key eddsabn254.PublicKey
+		if _, err := key.SetBytes(input.PublicKey); err != nil {
+			return nil, fmt.Errorf("input %d: invalid public key: %w", i, err)
+		}
+		var sig eddsabn254.Signature
+		if _, err := sig.SetBytes(input.Signature); err != nil {
+			return nil, fmt.Errorf("input %d: invalid signature: %w", i, err)
+		}
+		if input.Nonce == nil || input.PrevProofHash == nil {
+			return nil, fmt.Errorf("input %d: missing nonce or hash of the previous proof", i)
+		}
+
+		assignment.Inputs[i].PublicKey.Assign(1, input.PublicKey)
+		assignment.Inputs[i].ImageSignature.Assign(1, input.Signature)
+		assignment.Inputs[i].Nonce = input.Nonce
+		assignment.Inputs[i].PrevProofHash = input.PrevProofHash
+	}
+
+	return frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
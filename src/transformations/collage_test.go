@@ -0,0 +1,127 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+func TestCompose(t *testing.T) {
+	left := myImage.CoordinateImage()
+	right := myImage.AllWhiteImage()
+
+	collage, err := Compose(1, 2, []myImage.I{left, right})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			want := left.Pixels[y][x]
+			if x >= myImage.Width/2 {
+				want = right.Pixels[y][x-myImage.Width/2]
+			}
+			if collage.Pixels[y][x] != want {
+				t.Fatalf("pixel (%d, %d) is %v, expected %v", x, y, collage.Pixels[y][x], want)
+			}
+		}
+	}
+
+	for _, grid := range [][2]int{{0, 1}, {1, 3}, {myImage.Height * 2, 1}, {1, myImage.Height}} {
+		if err := CheckGrid(grid[0], grid[1]); err == nil {
+			t.Errorf("grid %v was accepted", grid)
+		}
+	}
+	if err := CheckGrid(3, 4); err != nil {
+		t.Errorf("grid 3 x 4 was rejected: %v", err)
+	}
+	if _, err := Compose(2, 2, []myImage.I{left, right}); err == nil {
+		t.Error("a 2 x 2 collage of 2 inputs was composed")
+	}
+}
+
+func TestCollageCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// A diptych of two captures of one camera, the second one an edit
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	inputs := []myImage.I{myImage.CoordinateImage(), myImage.AllWhiteImage()}
+	statements := make([]SignedStatement, len(inputs))
+	for i, input := range inputs {
+		nonce, prevProofHash := big.NewInt(testNonce+int64(i)), big.NewInt(int64(i))
+		signature, err := secretKey.Sign(myImage.Statement(input.Digest(), nonce, prevProofHash), hashsuite.Default.New())
+		assert.NoError(err)
+		statements[i] = SignedStatement{PublicKey: secretKey.Public().Bytes(), Signature: signature, Nonce: nonce, PrevProofHash: prevProofHash}
+	}
+	collage, err := Compose(1, 2, inputs)
+	assert.NoError(err)
+
+	valid := func() *CollageCircuit {
+		assignment := NewCollageCircuit(1, 2)
+		assignment.Rows = 1
+		assignment.Cols = 2
+		assignment.ImageDigest = RegionDigest(collage)
+		for i, statement := range statements {
+			assignment.Inputs[i].PublicKey.Assign(1, statement.PublicKey)
+			assignment.Inputs[i].ImageSignature.Assign(1, statement.Signature)
+			assignment.Inputs[i].Nonce = statement.Nonce
+			assignment.Inputs[i].PrevProofHash = statement.PrevProofHash
+			assignment.Inputs[i].ImageBytes = inputs[i].Digest()
+			assignment.Inputs[i].Metadata = inputs[i].MetadataDigest()
+			assignment.Inputs[i].Image = inputs[i].ToFrontendPacked()
+		}
+		return assignment
+	}
+
+	assert.NoError(test.IsSolved(NewCollageCircuit(1, 2), valid(), ecc.BN254.ScalarField()))
+
+	// The collage, its grid and the statements of the inputs are all bound
+	swapped, err := Compose(1, 2, []myImage.I{inputs[1], inputs[0]})
+	assert.NoError(err)
+	for name, tamper := range map[string]func(*CollageCircuit){
+		"collage": func(c *CollageCircuit) { c.ImageDigest = RegionDigest(swapped) },
+		"rows":    func(c *CollageCircuit) { c.Rows = 2 },
+		"cols":    func(c *CollageCircuit) { c.Cols = 1 },
+		"nonce":   func(c *CollageCircuit) { c.Inputs[1].Nonce = testNonce },
+		"link":    func(c *CollageCircuit) { c.Inputs[1].PrevProofHash = 0 },
+		"signed": func(c *CollageCircuit) {
+			c.Inputs[0].Image.Elements[myImage.PackedLength-1] = flipLowBit(inputs[0].Pack()[myImage.PackedLength-1])
+		},
+		"metadata": func(c *CollageCircuit) { c.Inputs[0].Metadata = 1 },
+		"order": func(c *CollageCircuit) {
+			c.Inputs[0], c.Inputs[1] = c.Inputs[1], c.Inputs[0]
+		},
+	} {
+		assignment := valid()
+		tamper(assignment)
+		assert.Error(test.IsSolved(NewCollageCircuit(1, 2), assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// A circuit compiled for another grid does not accept the collage
+	assert.Error(test.IsSolved(NewCollageCircuit(2, 1), valid(), ecc.BN254.ScalarField()))
+
+	// The verifier's public witness is the prover's
+	secret_witness, err := frontend.NewWitness(valid(), ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	collageWitness, err := CollageWitness(1, 2, collage, statements)
+	assert.NoError(err)
+	got, err := collageWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+
+	_, err = CollageWitness(1, 2, collage, []SignedStatement{statements[0], {PublicKey: statements[1].PublicKey, Signature: statements[1].Signature[:10], Nonce: big.NewInt(1), PrevProofHash: big.NewInt(0)}})
+	assert.Error(err)
+}
@@ -0,0 +1,57 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+
+	myImage "src/image"
+)
+
+// colorMatrixPlanes maps every pixel of the R, G and B planes by matrix, in place, exactly like
+// image.I.ApplyColorMatrix does outside the circuit: channel c becomes the sum of matrix[c][k] times channel k,
+// divided by 1 << image.ColorMatrixBits, rounded, and clamped to [0, 255] by a clampTable. The weights may be
+// variables, e.g. a public adjustment, as long as every rounded sum lies within the range of the clampTable.
+//
+// The division is a quotientHint, checked by the remainder, which is range checked to image.ColorMatrixBits.
+// The clampTable range checks the quotient too, so no other quotient and remainder make the same sum. The hint
+// divides integers, not field elements, so every sum is offset by clampOffset divisors, which makes it
+// nonnegative within the range of the clampTable, and the quotient is offset back.
+func colorMatrixPlanes(api frontend.API, planes []channelPlane, matrix *[3][3]frontend.Variable) error {
+	const divisor = 1 << myImage.ColorMatrixBits
+
+	sums := make([]frontend.Variable, 0, 2*len(planes)*myImage.Width*myImage.Height)
+	for c := range matrix {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				sum := frontend.Variable(clampOffset*divisor + divisor/2)
+				for k := range matrix[c] {
+					sum = api.Add(sum, api.Mul(matrix[c][k], planes[k][y][x]))
+				}
+				sums = append(sums, sum)
+			}
+		}
+	}
+	n := len(sums)
+	for range n {
+		sums = append(sums, divisor)
+	}
+	quotients, err := api.Compiler().NewHint(quotientHint, n, sums...)
+	if err != nil {
+		return err
+	}
+	rangeChecker := rangecheck.New(api)
+	for i := range quotients {
+		rangeChecker.Check(api.Sub(sums[i], api.Mul(quotients[i], divisor)), myImage.ColorMatrixBits)
+	}
+
+	for i := range quotients {
+		quotients[i] = api.Sub(quotients[i], clampOffset)
+	}
+	clamped := newClampTable(api).clamp(quotients...)
+	for c := range matrix {
+		for y := 0; y < myImage.Height; y++ {
+			copy(planes[c][y][:], clamped[(c*myImage.Height+y)*myImage.Width:])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package transformations
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+
+	myImage "src/image"
+)
+
+// convolvePlanes convolves region in every plane by kernel, in place, exactly like image.I.Convolve does outside
+// the circuit, and keeps every other pixel. It asserts region lies a pixel within the edges of the pixels of an
+// image, or is empty.
+func convolvePlanes(api frontend.API, planes []channelPlane, region CropParams, kernel *myImage.Kernel) error {
+	inRegion := regionIndicators(api, region, 1)
+	convolved, err := convolvedPlanes(api, planes, kernel)
+	if err != nil {
+		return err
+	}
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				planes[c][y][x] = api.Select(inRegion[y][x], convolved[c][y][x], planes[c][y][x])
+			}
+		}
+	}
+	return nil
+}
+
+// kernelPlanes convolves region in every plane by image.Kernels[kernel], in place, like convolvePlanes does with
+// a kernel of its own. It asserts kernel is an index of image.Kernels, and region lies a pixel within the edges
+// of the pixels of an image, or is empty.
+//
+// Every permissible kernel convolves the planes, and the indicator of kernel selects one, so the cost of the
+// circuit grows with the whitelist, not with the public kernel.
+func kernelPlanes(api frontend.API, planes []channelPlane, region CropParams, kernel frontend.Variable) error {
+	indices := make([]int, len(myImage.Kernels))
+	for k := range indices {
+		indices[k] = k
+	}
+	isKernel := valueIndicators(api, kernel, indices)
+	inRegion := regionIndicators(api, region, 1)
+
+	selected := make([]channelPlane, len(planes))
+	for c := range selected {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				selected[c][y][x] = 0
+			}
+		}
+	}
+	for k, permissible := range myImage.Kernels {
+		convolved, err := convolvedPlanes(api, planes, permissible)
+		if err != nil {
+			return err
+		}
+		for c := range selected {
+			for y := 0; y < myImage.Height; y++ {
+				for x := 0; x < myImage.Width; x++ {
+					selected[c][y][x] = api.Add(selected[c][y][x], api.Mul(isKernel[k], convolved[c][y][x]))
+				}
+			}
+		}
+	}
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				planes[c][y][x] = api.Select(inRegion[y][x], selected[c][y][x], planes[c][y][x])
+			}
+		}
+	}
+	return nil
+}
+
+// convolvedPlanes returns the planes convolved by kernel, exactly like image.Kernel.Apply does outside the
+// circuit, at every pixel whose 3 x 3 neighborhood lies within the pixels of an image, and the pixels on their
+// edges as they are. The kernel is a constant of the circuit, so its weights cost no multiplication.
+//
+// The division is a quotientHint, checked like those of colorMatrixPlanes: the remainder is in [0, Divisor),
+// with a second range check when the Divisor is not a power of two, and the clampTable range checks the
+// quotient. Every sum is offset by clampOffset divisors, so every rounded sum of the kernel must lie within the
+// range of the clampTable, which it does for a kernel whose weights add up to its Divisor and whose negative
+// weights add up to at most its Divisor in magnitude.
+func convolvedPlanes(api frontend.API, planes []channelPlane, kernel *myImage.Kernel) ([]channelPlane, error) {
+	const width, height = myImage.Width - 2, myImage.Height - 2
+	divisor := kernel.Divisor
+
+	sums := make([]frontend.Variable, 0, 2*len(planes)*width*height)
+	for c := range planes {
+		for y := 1; y <= height; y++ {
+			for x := 1; x <= width; x++ {
+				sum := frontend.Variable(clampOffset*divisor + divisor/2)
+				for j, weights := range kernel.Weights {
+					for i, weight := range weights {
+						if weight != 0 {
+							sum = api.Add(sum, api.Mul(weight, planes[c][y+j-1][x+i-1]))
+						}
+					}
+				}
+				sums = append(sums, sum)
+			}
+		}
+	}
+	n := len(sums)
+	for range n {
+		sums = append(sums, divisor)
+	}
+	quotients, err := api.Compiler().NewHint(quotientHint, n, sums...)
+	if err != nil {
+		return nil, err
+	}
+	rangeChecker := rangecheck.New(api)
+	remainderBits := bits.Len(uint(divisor - 1))
+	for i := range quotients {
+		remainder := api.Sub(sums[i], api.Mul(quotients[i], divisor))
+		switch {
+		case divisor == 1:
+			api.AssertIsEqual(remainder, 0)
+		case divisor&(divisor-1) == 0:
+			rangeChecker.Check(remainder, remainderBits)
+		default:
+			rangeChecker.Check(remainder, remainderBits)
+			rangeChecker.Check(api.Sub(divisor-1, remainder), remainderBits)
+		}
+		quotients[i] = api.Sub(quotients[i], clampOffset)
+	}
+
+	clamped := newClampTable(api).clamp(quotients...)
+	convolved := make([]channelPlane, len(planes))
+	for c := range planes {
+		convolved[c] = planes[c]
+		for y := 1; y <= height; y++ {
+			copy(convolved[c][y][1:1+width], clamped[(c*height+y-1)*width:])
+		}
+	}
+	return convolved, nil
+}
@@ -0,0 +1,49 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Convolution transformations: the signed image is the image FrImage with every pixel
+// of the public Region convolved by the kernel image.Kernels[Kernel], like image.I.Convolve does, and every
+// pixel outside of it unchanged. The permissible kernels are constants of the predicate, the identity, a box
+// blur and a sharpen, and the public Kernel selects one, so a filter that the whitelist holds needs no circuit
+// of its own. The Region lies a pixel within the edges of the pixels of an image, like the Region of a
+// BlurCircuit; an empty Region keeps the image.
+// Public fields: Statement, Region, Kernel
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, ConvolvedImage_in
+type ConvolutionCircuit struct {
+	Statement         frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey         eddsa.PublicKey       // Key the image is signed with
+	ImageSignature    eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce             frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash     frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier         frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops              frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes        frontend.Variable     // Digest of the signed image, ConvolvedImage_in
+	PrevImageBytes    frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Region            CropParams            `gnark:",public"` // Convolved rectangle, possibly empty
+	Kernel            frontend.Variable     `gnark:",public"` // Index of the kernel in image.Kernels
+	Metadata          frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata      frontend.Variable     // MetadataDigest of the previous image
+	Prev              PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage           myImage.FrontendImage // z_in as a FrontendImage
+	ConvolvedImage_in myImage.FrontendImage // Convolved previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a whitelisted convolution.
+func (circuit *ConvolutionCircuit) Define(api frontend.API) error {
+	// Convolve the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	if err := kernelPlanes(api, planes[:], circuit.Region, circuit.Kernel); err != nil {
+		return err
+	}
+	convolvedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.ConvolvedImage_in, &convolvedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.ConvolvedImage_in)
+}
@@ -0,0 +1,122 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts convolvePlanes(In, Region, Kernel) == Out, without the signature check of the BlurCircuit or the
+// SharpenCircuit.
+type convolvePixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Region CropParams
+	Kernel *myImage.Kernel `gnark:"-"`
+}
+
+func (circuit *convolvePixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := convolvePlanes(api, planes[:], circuit.Region, circuit.Kernel); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestConvolvePlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	for _, kernel := range []*myImage.Kernel{&myImage.BoxBlurKernel, &myImage.SharpenKernel} {
+		for _, rect := range []myImage.Rect{{X0: 3, Y0: 2, X1: 9, Y1: 6}, {X0: 1, Y0: 1, X1: myImage.Width - 2, Y1: myImage.Height - 2}} {
+			out, err := in.Convolve(rect, kernel)
+			assert.NoError(err)
+
+			region := CropParams{X0: rect.X0, Y0: rect.Y0, X1: rect.X1, Y1: rect.Y1}
+			assignment := convolvePixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Region: region}
+			assert.NoError(test.IsSolved(&convolvePixelsCircuit{Kernel: kernel}, &assignment, ecc.BN254.ScalarField()), "kernel %v, region %+v", kernel, rect)
+
+			// The image is not convolved outside of the region, nor kept inside of it
+			assignment.Region = CropParams{X0: rect.X0, Y0: rect.Y0, X1: rect.X1 - 1, Y1: rect.Y1}
+			assert.Error(test.IsSolved(&convolvePixelsCircuit{Kernel: kernel}, &assignment, ecc.BN254.ScalarField()), "kernel %v, region %+v", kernel, rect)
+
+			// The sums are rounded
+			out.Pixels[rect.Y1][rect.X1].B++
+			assignment = convolvePixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Region: region}
+			assert.Error(test.IsSolved(&convolvePixelsCircuit{Kernel: kernel}, &assignment, ecc.BN254.ScalarField()), "kernel %v, region %+v", kernel, rect)
+		}
+	}
+
+	// The sharpened pixels are clamped
+	edges := myImage.AllWhiteImage()
+	edges.Map(func(x, _ int, _ myImage.RGBPixel) myImage.RGBPixel {
+		return myImage.RGBPixel{R: uint8(255 * (x % 2)), G: uint8(255 * (1 - x%2)), B: 128}
+	})
+	rect := myImage.Rect{X0: 1, Y0: 1, X1: myImage.Width - 2, Y1: myImage.Height - 2}
+	sharpened, err := edges.Convolve(rect, &myImage.SharpenKernel)
+	assert.NoError(err)
+	assignment := convolvePixelsCircuit{In: edges.ToFrontendImage(), Out: sharpened.ToFrontendImage(), Region: CropParams{X0: rect.X0, Y0: rect.Y0, X1: rect.X1, Y1: rect.Y1}}
+	assert.NoError(test.IsSolved(&convolvePixelsCircuit{Kernel: &myImage.SharpenKernel}, &assignment, ecc.BN254.ScalarField()))
+
+	// An empty region keeps the image
+	assignment = convolvePixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Region: CropParams{X0: 1, Y0: 1, X1: 0, Y1: 0}}
+	assert.NoError(test.IsSolved(&convolvePixelsCircuit{Kernel: &myImage.BoxBlurKernel}, &assignment, ecc.BN254.ScalarField()))
+
+	// The region lies a pixel within the edges of the pixels of an image
+	black := myImage.I{}.ToFrontendImage()
+	for _, region := range []CropParams{{X0: 0, Y0: 1, X1: 2, Y1: 2}, {X0: 1, Y0: 1, X1: myImage.Width - 1, Y1: 2}, {X0: 1, Y0: 1, X1: 2, Y1: myImage.Height - 1}, {X0: 1, Y0: 0, X1: 0, Y1: 0}} {
+		assignment := convolvePixelsCircuit{In: black, Out: black, Region: region}
+		assert.Error(test.IsSolved(&convolvePixelsCircuit{Kernel: &myImage.BoxBlurKernel}, &assignment, ecc.BN254.ScalarField()), "region %+v", region)
+	}
+}
+
+// Asserts kernelPlanes(In, Region, Kernel) == Out, without the signature check of the ConvolutionCircuit.
+type kernelPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Region CropParams
+	Kernel frontend.Variable
+}
+
+func (circuit *kernelPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := kernelPlanes(api, planes[:], circuit.Region, circuit.Kernel); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestKernelPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	rect := myImage.Rect{X0: 2, Y0: 3, X1: 11, Y1: 8}
+	region := CropParams{X0: rect.X0, Y0: rect.Y0, X1: rect.X1, Y1: rect.Y1}
+	for k, kernel := range myImage.Kernels {
+		out, err := in.Convolve(rect, kernel)
+		assert.NoError(err)
+
+		assignment := kernelPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Region: region, Kernel: k}
+		assert.NoError(test.IsSolved(&kernelPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "kernel %d", k)
+
+		// The image is convolved by the kernel of the index only
+		if k > 0 {
+			assignment.Kernel = (k + 1) % len(myImage.Kernels)
+			assert.Error(test.IsSolved(&kernelPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "kernel %d", k)
+		}
+	}
+
+	// The identity keeps the image, and kernels are indices of the whitelist
+	assignment := kernelPixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Region: region, Kernel: 0}
+	assert.NoError(test.IsSolved(&kernelPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	assignment.Kernel = len(myImage.Kernels)
+	assert.Error(test.IsSolved(&kernelPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()))
+}
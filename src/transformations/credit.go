@@ -0,0 +1,247 @@
+package transformations
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+const (
+	chunkBytes     = 31                          // Bytes of a chunk of an encoding, see field.Chunks
+	metadataChunks = 8                           // Chunks of the longest MetadataEncoding the CreditCircuit takes
+	metadataBytes  = metadataChunks * chunkBytes // Bytes of the longest MetadataEncoding the CreditCircuit takes
+
+	// Offset of the Author in a MetadataEncoding, past the header and the length of the Author.
+	authorOffset = myImage.MetadataHeaderSize + 4
+)
+
+// This circuit is only for Credit transformations: the signed image is the image FrImage credited to another
+// Author, like image.I.Credit does, with the very same pixels. The Author is the only key of the metadata
+// that the predicate lets an edit change: every byte of the MetadataEncoding of the FrImage past its Author,
+// e.g. its Timestamp, GPS position and DeviceID, is kept in the metadata of the signed image, whose
+// MetadataDigest is the signed Metadata. The credited author is secret: a verifier learns that the image was
+// credited, not to whom.
+// Public fields: Statement
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, CreditedImage_in, Params
+type CreditCircuit struct {
+	Statement        frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey        eddsa.PublicKey       // Key the image is signed with
+	ImageSignature   eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce            frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash    frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier        frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops             frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes       frontend.Variable     // Digest of the signed image, CreditedImage_in
+	PrevImageBytes   frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Metadata         frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata     frontend.Variable     // MetadataDigest of the previous image
+	Prev             PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage          myImage.FrontendImage // z_in as a FrontendImage
+	CreditedImage_in myImage.FrontendImage // Credited previous image as a FrontendImage
+	Params           CreditMetadata        // Metadata of the images
+}
+
+// The metadata of a credit: the MetadataEncoding of the image before and after it, byte by byte, zero padded to
+// metadataBytes, and their lengths.
+type CreditMetadata struct {
+	In        [metadataBytes]frontend.Variable
+	Out       [metadataBytes]frontend.Variable
+	InLength  frontend.Variable
+	OutLength frontend.Variable
+}
+
+// Defines the Compliance Predicate of a credit.
+func (circuit *CreditCircuit) Define(api frontend.API) error {
+	// The pixels are kept
+	assertEqualImages(api, &circuit.CreditedImage_in, &circuit.FrImage)
+
+	// The metadata is that of the FrImage, its PrevMetadata, but for its Author, and is the signed Metadata
+	if err := assertCredit(api, circuit.PrevMetadata, circuit.Metadata, &circuit.Params); err != nil {
+		return err
+	}
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.CreditedImage_in)
+}
+
+// assertCredit asserts that the Out encoding of params is the In encoding with another Author of at most
+// image.MaxAuthor bytes, and that metadata and prevMetadata are their MetadataDigest, as image.I.MetadataDigest
+// computes it outside the circuit.
+//
+// Byte locations inside a circuit must be constants, and the bytes past the Author move by the difference of
+// the lengths of the authors. So every byte of Out past its Author and within its length is looked up in a
+// table of the In bytes, at its location moved back by that difference, and every byte past its length is 0.
+// The digest hashes the length, then every chunk, and is the state of the hash after the last chunk of the
+// length, which an indicator of the length selects.
+func assertCredit(api frontend.API, prevMetadata, metadata frontend.Variable, params *CreditMetadata) error {
+	rangeChecker := rangecheck.New(api)
+	for j := range metadataBytes {
+		rangeChecker.Check(params.In[j], 8)
+		rangeChecker.Check(params.Out[j], 8)
+	}
+
+	// The header is kept
+	for j := range myImage.MetadataHeaderSize {
+		api.AssertIsEqual(params.Out[j], params.In[j])
+	}
+
+	// The lengths of the authors, big endian, are at most MaxAuthor bytes, and the rest of the metadata is as long
+	authors := make([]int, myImage.MaxAuthor+1)
+	for length := range authors {
+		authors[length] = length
+	}
+	inAuthor := bigEndianBytes(api, params.In[myImage.MetadataHeaderSize:authorOffset])
+	outAuthor := bigEndianBytes(api, params.Out[myImage.MetadataHeaderSize:authorOffset])
+	valueIndicators(api, inAuthor, authors)
+	isOutAuthor := valueIndicators(api, outAuthor, authors)
+	rangeChecker.Check(api.Sub(params.OutLength, api.Add(authorOffset, outAuthor)), 8)
+	api.AssertIsEqual(api.Sub(params.OutLength, outAuthor), api.Sub(params.InLength, inAuthor))
+
+	// pastAuthor[j] is 1 if byte j of Out is past its Author, past[j] if it is past its length
+	lengths := make([]int, metadataBytes+1)
+	for length := range lengths {
+		lengths[length] = length
+	}
+	past := pastLength(api, params.OutLength, lengths)
+	pastAuthor := make([]frontend.Variable, metadataBytes)
+	pastAuthor[0] = 0
+	for j := 1; j < metadataBytes; j++ {
+		pastAuthor[j] = pastAuthor[j-1]
+		if length := j - authorOffset; length >= 0 && length <= myImage.MaxAuthor {
+			pastAuthor[j] = api.Add(pastAuthor[j], isOutAuthor[length])
+		}
+	}
+
+	// The rest of Out is the rest of In, and the padding is 0
+	in := logderivlookup.New(api)
+	for j := range 1 << 8 {
+		if j < metadataBytes {
+			in.Insert(params.In[j])
+		} else {
+			in.Insert(0)
+		}
+	}
+	for j := authorOffset; j < metadataBytes; j++ {
+		rest := api.Sub(pastAuthor[j], past[j])
+		index := api.Mul(rest, api.Add(j, api.Sub(inAuthor, outAuthor)))
+		rangeChecker.Check(index, 8)
+		api.AssertIsEqual(api.Mul(rest, api.Sub(params.Out[j], in.Lookup(index)[0])), 0)
+	}
+	for j := range metadataBytes {
+		api.AssertIsEqual(api.Mul(past[j], params.Out[j]), 0)
+	}
+
+	// The Metadata is the digest of the chunks of Out, and the PrevMetadata of the chunks of In
+	digest, err := encodingDigest(api, params.Out[:], params.OutLength, past)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(digest, metadata)
+	prevDigest, err := encodingDigest(api, params.In[:], params.InLength, pastLength(api, params.InLength, lengths))
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(prevDigest, prevMetadata)
+	return nil
+}
+
+// pastLength returns, for every j in [0, metadataBytes], 1 if byte j of an encoding of length, one of lengths,
+// is past its length, and 0 otherwise.
+func pastLength(api frontend.API, length frontend.Variable, lengths []int) []frontend.Variable {
+	isLength := valueIndicators(api, length, lengths)
+	past := make([]frontend.Variable, metadataBytes+1)
+	past[0] = isLength[0]
+	for j := 1; j <= metadataBytes; j++ {
+		past[j] = api.Add(past[j-1], isLength[j])
+	}
+	return past
+}
+
+// encodingDigest returns the digest of the chunks of an encoding of length, whose bytes past is 1 past, as
+// image.I.MetadataDigest computes it: the state of the hash after the length and the last chunk of the length.
+func encodingDigest(api frontend.API, encoding []frontend.Variable, length frontend.Variable, past []frontend.Variable) (frontend.Variable, error) {
+	h, err := hashsuite.Default.NewGadget(api)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(length)
+	var digest frontend.Variable = 0
+	for i := range metadataChunks {
+		h.Write(bigEndianBytes(api, encoding[i*chunkBytes:(i+1)*chunkBytes]))
+		last := api.Sub(past[(i+1)*chunkBytes], past[i*chunkBytes])
+		digest = api.Add(digest, api.Mul(last, h.Sum()))
+	}
+	return digest, nil
+}
+
+// bigEndianBytes returns the value of bytes, big endian, which must be range checked to 8 bits.
+func bigEndianBytes(api frontend.API, bytes []frontend.Variable) frontend.Variable {
+	var value frontend.Variable = 0
+	for _, b := range bytes {
+		value = api.Add(api.Mul(value, 1<<8), b)
+	}
+	return value
+}
+
+// CreditCircuitParams returns the metadata of the CreditCircuit for crediting the image in as the image out,
+// or an error if the metadata of either cannot be encoded in metadataBytes.
+func CreditCircuitParams(in, out myImage.I) (CreditMetadata, error) {
+	var params CreditMetadata
+	for _, img := range []struct {
+		img    myImage.I
+		bytes  *[metadataBytes]frontend.Variable
+		length *frontend.Variable
+	}{{in, &params.In, &params.InLength}, {out, &params.Out, &params.OutLength}} {
+		encoding, err := img.img.MetadataEncoding()
+		if err != nil {
+			return CreditMetadata{}, err
+		}
+		if len(encoding) > metadataBytes {
+			return CreditMetadata{}, fmt.Errorf("metadata of %d bytes cannot be credited: expected at most %d", len(encoding), metadataBytes)
+		}
+		for j := range img.bytes {
+			img.bytes[j] = 0
+			if j < len(encoding) {
+				img.bytes[j] = encoding[j]
+			}
+		}
+		*img.length = len(encoding)
+	}
+	return params, nil
+}
+
+// CreditParams returns the parameters of a Credit transformation to author: its length, then its bytes.
+func CreditParams(author string) map[string]int {
+	params := map[string]int{"author_length": len(author)}
+	for j := range myImage.MaxAuthor {
+		params[fmt.Sprintf("author_%d", j)] = 0
+		if j < len(author) {
+			params[fmt.Sprintf("author_%d", j)] = int(author[j])
+		}
+	}
+	return params
+}
+
+// authorOf returns the author of the parameters of a Credit transformation.
+func authorOf(params map[string]int) (string, error) {
+	length := params["author_length"]
+	if length < 0 || length > myImage.MaxAuthor {
+		return "", fmt.Errorf("invalid author_length %d: expected at most %d bytes", length, myImage.MaxAuthor)
+	}
+	author := make([]byte, length)
+	for j := range myImage.MaxAuthor {
+		name := fmt.Sprintf("author_%d", j)
+		b := params[name]
+		if b < 0 || b > 255 || (j >= length && b != 0) {
+			return "", fmt.Errorf("invalid %s %d: expected a byte of the author, 0 past its length", name, b)
+		}
+		if j < length {
+			author[j] = byte(b)
+		}
+	}
+	return string(author), nil
+}
@@ -0,0 +1,87 @@
+package transformations
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts assertCredit(PrevMetadata, Metadata, Params), without the pixels and the signature check of the
+// CreditCircuit.
+type creditMetadataCircuit struct {
+	PrevMetadata frontend.Variable
+	Metadata     frontend.Variable
+	Params       CreditMetadata
+}
+
+func (circuit *creditMetadataCircuit) Define(api frontend.API) error {
+	return assertCredit(api, circuit.PrevMetadata, circuit.Metadata, &circuit.Params)
+}
+
+// creditAssignment returns the assignment of a creditMetadataCircuit that out is the image in credited.
+func creditAssignment(t *testing.T, in, out myImage.I) *creditMetadataCircuit {
+	t.Helper()
+	params, err := CreditCircuitParams(in, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &creditMetadataCircuit{PrevMetadata: in.MetadataDigest(), Metadata: out.MetadataDigest(), Params: params}
+}
+
+func TestCredit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	in.M.Author = "Jane Doe"
+	in.M.Timestamp = time.Unix(1700000000, 0)
+	in.M.GPS = &myImage.GPS{Latitude: 46.2, Longitude: 6.1, Altitude: 375}
+	in.M.DeviceID = "PhotoGnark test camera"
+
+	// A longer, a shorter or no author, and the author it has
+	for _, author := range []string{"Associated Press Photo Agency", "AP", "", in.M.Author} {
+		out, err := Transformation{T: Credit, Params: CreditParams(author)}.Apply(in)
+		assert.NoError(err)
+		assert.Equal(author, out.M.Author)
+		assert.NoError(test.IsSolved(&creditMetadataCircuit{}, creditAssignment(t, in, out), ecc.BN254.ScalarField()), "author %q", author)
+	}
+
+	// The keys that record the capture are kept
+	out, err := in.Credit("AP")
+	assert.NoError(err)
+	for _, protect := range []func(m *myImage.Metadata){
+		func(m *myImage.Metadata) { m.Timestamp = m.Timestamp.Add(time.Second) },
+		func(m *myImage.Metadata) { m.GPS = nil },
+		func(m *myImage.Metadata) { m.DeviceID = "PhotoGnark test camerA" },
+		func(m *myImage.Metadata) { m.Width-- },
+	} {
+		changed := out.Clone()
+		protect(&changed.M)
+		assert.Error(test.IsSolved(&creditMetadataCircuit{}, creditAssignment(t, in, changed), ecc.BN254.ScalarField()))
+	}
+
+	// The Metadata is the digest of the credited metadata, which is zero past its length
+	assignment := creditAssignment(t, in, out)
+	assignment.Metadata = new(big.Int).SetBytes(in.MetadataDigest())
+	assert.Error(test.IsSolved(&creditMetadataCircuit{}, assignment, ecc.BN254.ScalarField()))
+	assignment = creditAssignment(t, in, out)
+	assignment.Params.Out[metadataBytes-1] = 1
+	assert.Error(test.IsSolved(&creditMetadataCircuit{}, assignment, ecc.BN254.ScalarField()))
+
+	// The PrevMetadata is the digest of the metadata before the credit
+	assignment = creditAssignment(t, in, out)
+	assignment.PrevMetadata = new(big.Int).SetBytes(out.MetadataDigest())
+	assert.Error(test.IsSolved(&creditMetadataCircuit{}, assignment, ecc.BN254.ScalarField()))
+
+	// An author is at most MaxAuthor bytes
+	long := "Associated Press Photo Agency, Geneva"
+	_, err = Transformation{T: Credit, Params: CreditParams(long)}.Apply(in)
+	assert.Error(err)
+	out.M.Author = long
+	assert.Error(test.IsSolved(&creditMetadataCircuit{}, creditAssignment(t, in, out), ecc.BN254.ScalarField()))
+}
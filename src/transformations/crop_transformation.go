@@ -1,217 +1,340 @@
 package transformations
 
 import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/std/algebra/native/twistededwards"
-	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/math/cmp"
 	"github.com/consensys/gnark/std/signature/eddsa"
 
 	myImage "src/image"
 )
 
-// This circuit is only for Crop transformations.
-// Public fields: PublicKey, ImageSignature
-// Secret fields: ImageBytes
+// This circuit is only for Crop transformations. Its proof is one step of an edit history, which a verifier
+// checks with the proof of the image it was cropped from, see verifier.VerifyChain, so the original is published
+// along with the crop. To publish a crop of an original that stays entirely private, only its camera and
+// capture counter public, prove a selective disclosure with the DisclosureCircuit instead.
+// The area of the crop is secret, but for the parameters its keys disclose, named by the Disclosed of their
+// CircuitID: e.g. keys disclosing x1 and y1 prove crops of a public size, at 0, 0 once translated, from a
+// secret offset. The disclosed parameters are fixed when the circuit is compiled, see Transformation.Public.
+// Public fields: Statement, Disclosed
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, CroppedImage_in, Params
 type CropCircuit struct {
-	PublicKey       eddsa.PublicKey       `gnark:",public"`
-	ImageSignature  eddsa.Signature       `gnark:",public"` // Digital signature as eddsa.Signature
-	ImageBytes      frontend.Variable     // z_in as Big Endian
+	Statement       frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey       // Key the image is signed with
+	ImageSignature  eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable     // Digest of the signed image, CroppedImage_in
+	PrevImageBytes  frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Disclosed       []frontend.Variable   `gnark:",public"` // Disclosed Params, in the order of Disclose
+	Metadata        frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable     // MetadataDigest of the previous image
+	Prev            PrevSignature         // Signature of the previous image, see PrevSignature
 	FrImage         myImage.FrontendImage // z_in as a FrontendImage
 	CroppedImage_in myImage.FrontendImage // Cropped previous image as a FrontendImage
 	Params          CropParams            // Crop transformation parameters
+	Disclose        []string              `gnark:"-"` // Names of the disclosed Params, constants of the predicate, see SetDisclosed
 }
 
+// Parameters of a crop: the area {(X0,Y0), (X1,Y1)} to keep. The image size is the constants image.Width and
+// image.Height.
 type CropParams struct {
-	N  frontend.Variable
 	X0 frontend.Variable
 	Y0 frontend.Variable
 	X1 frontend.Variable
 	Y1 frontend.Variable
 }
 
-type Fr_SquareArea struct {
-	topLeft     Fr_Location
-	bottomRight Fr_Location
-}
+// Defines the Compliance Predicate of a crop: the CroppedImage_in is the FrImage cropped by the secret Params and
+// translated to its top left corner, and the Disclosed values are the parameters named by Disclose. Like the
+// IdentityCircuit, it verifies the ImageSignature inside the Compliance Predicate, so the FrImage and the
+// parameters that are not disclosed remain secret when creating proofs or verifying proofs.
+func (circuit *CropCircuit) Define(api frontend.API) error {
 
-type Fr_Location struct {
-	X frontend.Variable
-	Y frontend.Variable
-}
+	// The FrImage is an image, also outside of the area the crop keeps
+	assertPixels(api, &circuit.FrImage)
 
-// Defines the Compliance Predicate for the IdentityCircuit, which is used to enforce Identity tranformations only,
-// in this case. This function utilizes the frontend.API to verify the circuit's ImageSignature inside the
-// Compliance Predicate, so secret fields remain secret when creating proofs or verifyin proofs.
-func (circuit *CropCircuit) Define(api frontend.API) error {
+	// The disclosed parameters are those of the crop
+	if len(circuit.Disclosed) != len(circuit.Disclose) {
+		return fmt.Errorf("%d disclosed parameters of a crop disclosing %v", len(circuit.Disclosed), circuit.Disclose)
+	}
+	for i, name := range circuit.Disclose {
+		param, err := circuit.Params.byName(name)
+		if err != nil {
+			return err
+		}
+		api.AssertIsEqual(circuit.Disclosed[i], param)
+	}
 
 	// Crop and translate the FRImage
-	croppedImage_out := circuit.CropFrontendImage(api)
+	croppedImage_out := cropFrontendImage(api, &circuit.FrImage, circuit.Params)
 
 	// Assert the transformed_image_out and the transformed_image_in have equal pixels
-	for x := 0; x < myImage.N; x++ {
-		for y := 0; y < myImage.N; y++ {
-			api.AssertIsEqual(
-				circuit.CroppedImage_in.Pixels[x][y],
-				croppedImage_out.Pixels[x][y],
-			)
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			api.AssertIsEqual(circuit.CroppedImage_in.Pixels[y][x].R, croppedImage_out.Pixels[y][x].R)
+			api.AssertIsEqual(circuit.CroppedImage_in.Pixels[y][x].G, croppedImage_out.Pixels[y][x].G)
+			api.AssertIsEqual(circuit.CroppedImage_in.Pixels[y][x].B, croppedImage_out.Pixels[y][x].B)
 		}
 	}
 
-	// Set the twisted edwards curve
-	curve, err := twistededwards.NewEdCurve(api, 1)
-	if err != nil {
+	// The FrImage is the image of the proof the crop was made from
+	if err := assertPrevImage(api, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.ImageBytes, circuit.PrevProofHash, &circuit.FrImage); err != nil {
 		return err
 	}
 
-	// Get the hash function that can be used in verifying signatures inside a Gnark ZKP-circuit.
-	// i.e. without revealing secret fields in the circuit.
-	mimc, err := mimc.NewMiMC(api)
-	if err != nil {
+	// An original image is signed by the camera, whose key and capture counter make the Nullifier
+	if err := assertNullifier(api, circuit.Nullifier, circuit.PublicKey, circuit.Nonce, circuit.PrevProofHash); err != nil {
 		return err
 	}
 
-	// Verify the circuit's ImageSignature using a ZKP-circuit function for EdDSA signatures.
-	// This involves using the same hash function MiMC(ImageBytes + public key) to generate a secondary
-	// signature, and then verifying if the signatures match. This is done in a ZKP-circuit so the secret
-	// fields are not revealed.
-	eddsa.Verify(curve, circuit.ImageSignature, circuit.ImageBytes, circuit.PublicKey, &mimc)
+	// The image is at most MaxHops edits past the original
+	assertHops(api, circuit.Hops, circuit.PrevProofHash)
 
-	return nil
+	// The signed ImageBytes are the digest of the cropped image
+	if err := assertImageDigest(api, circuit.ImageBytes, circuit.Metadata, regionChannels(&circuit.CroppedImage_in), channelBits); err != nil {
+		return err
+	}
+
+	// Verify the ImageSignature over the statement of ImageBytes, the Nonce and the PrevProofHash
+	if err := assertSignedStatement(api, circuit.PublicKey, circuit.ImageSignature, circuit.ImageBytes, circuit.Nonce, circuit.PrevProofHash); err != nil {
+		return err
+	}
+
+	// The FrImage was signed for the same capture, by the key the statement exposes
+	if err := assertPrevSignature(api, circuit.Prev, circuit.PrevImageBytes, circuit.Nonce); err != nil {
+		return err
+	}
+
+	// The public Statement is the digest of the secret values of the statement
+	return assertStatementDigest(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.PrevImageBytes, circuit.Prev.PublicKey)
+}
+
+// Names of the parameters of a crop, in the order of CropParams, which is the order they are disclosed in.
+var cropParamNames = []string{"x0", "y0", "x1", "y1"}
+
+// byName returns the parameter of the crop named name, one of cropParamNames.
+func (params CropParams) byName(name string) (frontend.Variable, error) {
+	switch name {
+	case "x0":
+		return params.X0, nil
+	case "y0":
+		return params.Y0, nil
+	case "x1":
+		return params.X1, nil
+	case "y1":
+		return params.Y1, nil
+	}
+	return nil, fmt.Errorf("unknown parameter %q of a crop: expected %v", name, cropParamNames)
 }
 
-// CropFrontendImage crops and translates the FrontendImage using frontend.API and Compiler.
-func (circuit *CropCircuit) CropFrontendImage(api frontend.API) myImage.FrontendImage {
-	// Create constants for comparison (0, 1 & N) using Compiler
-	zero, _ := api.Compiler().ConstantValue(0)
-	one, _ := api.Compiler().ConstantValue(1)
-	N := frontend.Variable(circuit.Params.N)
-	N_minus_one := api.Sub(N, one)
-	blackPixel := myImage.FrontendPixel{R: zero, G: zero, B: zero}
-
-	oldImage := circuit.FrImage         // The previous image
-	newImage := myImage.FrontendImage{} // The new image, to be set to transformed pixels
-
-	// Area to crop,
-	// {(X0,Y0), (X1, Y1)}
-	cropArea := Fr_SquareArea{
-		topLeft:     Fr_Location{X: circuit.Params.X0, Y: circuit.Params.Y0},
-		bottomRight: Fr_Location{X: circuit.Params.X1, Y: circuit.Params.Y1},
-	}
-
-	// Area to bound pixels in,
-	// {(0,0), (N-1, N-1)}
-	imageBounds := Fr_SquareArea{
-		topLeft:     Fr_Location{X: zero, Y: zero},
-		bottomRight: Fr_Location{X: N_minus_one, Y: N_minus_one},
-	}
-
-	// Iterate over the entire N x N matrix
-
-	for y := 0; y < (myImage.N - 1); y++ {
-		for x := 0; x < (myImage.N - 1); x++ {
-			xFr := frontend.Variable(x)
-			yFr := frontend.Variable(y)
-
-			// any pixels outside area should return false
-			inCropArea := InArea(api, xFr, yFr, cropArea)
-
-			// Calculate the new location
-			newXFr := api.Sub(xFr, cropArea.topLeft.X)
-			newX := newXFr.(int)
-
-			newYFr := api.Sub(xFr, cropArea.topLeft.Y)
-			newY := newYFr.(int)
-
-			// any pixels outisde area should return false
-			inBounds := InArea(api, newXFr, newYFr, imageBounds)
-
-			// Get the current pixel
-			currentPixel := oldImage.Pixels[x][y]
-
-			// This essentially translates to:
-			// 		if inBoundArea(newX, newY):
-			//			if inCropArea(currentX, currentY):
-			//				SELECT currentPixel
-			//			else: SELECT blackPixel
-			//		else: SELECT blackPixel
-			//
-			//	Where CropArea{(X0, Y1), (X1, Y1)} and BoundArea{(0,0), (N-1, N-1)}
-			newImage.Pixels[newX][newY].R = api.Select(
-				inBounds,
-				api.Select(
-					inCropArea,
-					currentPixel.R,
-					blackPixel.R,
-				),
-				blackPixel.R,
-			)
-			newImage.Pixels[newXFr.(int)][newYFr.(int)].G = api.Select(
-				inBounds,
-				api.Select(
-					inCropArea,
-					currentPixel.G,
-					blackPixel.G,
-				),
-				blackPixel.G,
-			)
-			newImage.Pixels[newXFr.(int)][newYFr.(int)].B = api.Select(
-				inBounds,
-				api.Select(
-					inCropArea,
-					currentPixel.B,
-					blackPixel.B,
-				),
-				blackPixel.B,
-			)
+// cropCircuitDisclosing returns the CropCircuit that discloses the parameters named public, each once, and no
+// other. The same parameters name the same predicate in whichever order they are given.
+func cropCircuitDisclosing(public []string) (CircuitID, error) {
+	id := CropCircuitID
+	var disclosed []string
+	for _, name := range cropParamNames {
+		if slices.Contains(public, name) {
+			disclosed = append(disclosed, name)
 		}
 	}
+	if len(disclosed) != len(public) {
+		return CircuitID{}, fmt.Errorf("invalid disclosed parameters %v of a crop: expected each of %v at most once", public, cropParamNames)
+	}
+	id.Disclosed = strings.Join(disclosed, ",")
+	return id, nil
+}
+
+// cropDisclosed returns the names of the parameters the crop predicate id discloses, nil for none.
+func cropDisclosed(id CircuitID) []string {
+	if id.Name != CropCircuitID.Name || id.Disclosed == "" {
+		return nil
+	}
+	return strings.Split(id.Disclosed, ",")
+}
+
+// cropEditParams returns the public parameters of proofs of the crop predicate id, those it discloses. An
+// Identity is the crop of the whole image.
+func cropEditParams(id CircuitID) []EditParam {
+	identity := map[string]int{"x0": 0, "y0": 0, "x1": myImage.Width - 1, "y1": myImage.Height - 1}
+	var params []EditParam
+	for _, name := range cropDisclosed(id) {
+		params = append(params, EditParam{Name: name, Identity: identity[name]})
+	}
+	return params
+}
+
+// SetDisclosed sets the parameters the CropCircuit discloses, those of the crop predicate id, to its Params.
+// An assignment of a crop predicate that discloses parameters is only complete once they are set.
+func (circuit *CropCircuit) SetDisclosed(id CircuitID) error {
+	if id.Name != CropCircuitID.Name {
+		return fmt.Errorf("circuit %s is not the crop predicate", id)
+	}
+	circuit.Disclose = cropDisclosed(id)
+	circuit.Disclosed = make([]frontend.Variable, len(circuit.Disclose))
+	for i, name := range circuit.Disclose {
+		param, err := circuit.Params.byName(name)
+		if err != nil {
+			return err
+		}
+		circuit.Disclosed[i] = param
+	}
+	return nil
+}
+
+// cropFrontendImage crops img to the area {(X0,Y0), (X1,Y1)} of params and translates the area to the top left
+// corner, blackening all other pixels, exactly like image.Crop does outside the circuit.
+//
+// Pixel locations inside a circuit must be constants, so a pixel cannot be read at the variable location
+// (x+X0, y+Y0). Instead the translation loops over constant offsets: every offset k gets an indicator
+// (k == X0), and a destination pixel sums indicator * (source pixel at offset k) over all offsets. Exactly one
+// indicator is set, so exactly the right source pixel is selected. Translating along x and then along y keeps
+// this to W*(W+1)/2 selects per row of W pixels and H*(H+1)/2 per column of H pixels, instead of (W*H)^2.
+//
+// The translations work on one row (or column) of one channel at a time.
+func cropFrontendImage(api frontend.API, img *myImage.FrontendImage, params CropParams) myImage.FrontendImage {
+	planes := channelPlanes(img)
+	cropPlanes(api, planes[:], params)
 
 	// TODO: Metadata updates for width and height
 	// Update metadata to reflect the new dimensions of the cropped area
-	// img.M["width"] = cropWidth
-	// img.M["height"] = cropHeight
+	// img.M.Width = cropWidth
+	// img.M.Height = cropHeight
 
-	return newImage
+	return fromChannelPlanes(&planes)
 }
 
-// InArea leverages the api.IsZero and api.Cmp() functions to return true if (x,y) are within the given area,
-// false if not. The area bounds are included, i.e. all variables are index locations.
-func InArea(api frontend.API, x frontend.Variable, y frontend.Variable, area Fr_SquareArea) frontend.Variable {
-
-	// inCropAreaX translates to:
-	// 		isZero(Cmp(area.topLeft.X, x))              ->   true if (topLeft.X == x)
-	// 		isZero(Cmp(x, area.bottomRight.X))          ->   true if (x == bottomRight.X)
-	// 		isZero(Cmp(area.topLeft.X, x) + 1)          ->   true if (topLeft.X < x)
-	// 		isZero(Cmp(x, area.bottomRight.X) + 1)      ->   true if (x == bottomRight.X)
-	inCropAreaX := api.And(
-		api.And(
-			api.IsZero(api.Cmp(area.topLeft.X, x)),
-			api.IsZero(api.Cmp(x, area.bottomRight.X)),
-		),
-		api.And(
-			api.IsZero(api.Add(api.Cmp(area.topLeft.X, x), 1)),
-			api.IsZero(api.Add(api.Cmp(x, area.bottomRight.X), 1)),
-		),
-	)
+// cropPlanes crops every plane in place, like cropFrontendImage crops the planes of an image, e.g. the single
+// plane of a grayscale image.
+func cropPlanes(api frontend.API, planes []channelPlane, params CropParams) {
+	comparator := newLocationComparator(api)
+
+	// The crop area must lie within the image, with its top left corner before its bottom right corner.
+	// (X0, Y0) >= 0 is enforced by offsetIndicators.
+	comparator.AssertIsLessEq(params.X0, params.X1)
+	comparator.AssertIsLessEq(params.Y0, params.Y1)
+	comparator.AssertIsLessEq(params.X1, myImage.Width-1)
+	comparator.AssertIsLessEq(params.Y1, myImage.Height-1)
+
+	isOffsetX := offsetIndicators(api, params.X0, myImage.Width)
+	isOffsetY := offsetIndicators(api, params.Y0, myImage.Height)
+
+	// Only the translated crop area {(0,0), (X1-X0, Y1-Y0)} is kept, every other pixel turns black.
+	// Each column and row is compared once, instead of once per pixel.
+	width := api.Sub(params.X1, params.X0)
+	height := api.Sub(params.Y1, params.Y0)
+	var inWidth [myImage.Width]frontend.Variable
+	for x := range inWidth {
+		inWidth[x] = inRange(api, comparator, x, 0, width)
+	}
+	var inHeight [myImage.Height]frontend.Variable
+	for y := range inHeight {
+		inHeight[y] = inRange(api, comparator, y, 0, height)
+	}
+	var inCropArea channelPlane
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			inCropArea[y][x] = api.And(inWidth[x], inHeight[y])
+		}
+	}
 
-	// inCropAreaY translates to:
-	// 		isZero(Cmp(area.topLeft.Y, y))              ->   true if (topLeft.Y == y)
-	// 		isZero(Cmp(y, area.bottomRight.Y))          ->   true if (y == bottomRight.Y)
-	// 		isZero(Cmp(area.topLeft.Y, y) + 1)          ->   true if (topLeft.Y < y)
-	// 		isZero(Cmp(y, area.bottomRight.Y) + 1)      ->   true if (y == bottomRight.Y)
-	inCropAreaY := api.And(
-		api.And(
-			api.IsZero(api.Cmp(area.topLeft.Y, y)),
-			api.IsZero(api.Cmp(y, area.bottomRight.Y)),
-		),
-		api.And(
-			api.IsZero(api.Add(api.Cmp(area.topLeft.Y, y), 1)),
-			api.IsZero(api.Add(api.Cmp(y, area.bottomRight.Y), 1)),
-		),
-	)
+	terms := make([]frontend.Variable, 0, max(myImage.Width, myImage.Height)) // scratch space shared by every shiftRow
+	for c := range planes {
+		plane := &planes[c]
+
+		// Translate along x:
+		// 		plane[y][x] = plane[y][x+X0], or black past the right edge
+		for y := 0; y < myImage.Height; y++ {
+			shiftRow(api, isOffsetX, plane[y][:], terms)
+		}
+
+		// Translate along y, one column at a time:
+		// 		plane[y][x] = plane[y+Y0][x], or black past the bottom edge
+		for x := 0; x < myImage.Width; x++ {
+			var column [myImage.Height]frontend.Variable
+			for y := 0; y < myImage.Height; y++ {
+				column[y] = plane[y][x]
+			}
+			shiftRow(api, isOffsetY, column[:], terms)
+			for y := 0; y < myImage.Height; y++ {
+				plane[y][x] = api.Mul(inCropArea[y][x], column[y])
+			}
+		}
+	}
+}
+
+// shiftRow translates a row (or a column) to the left, in place, by the offset whose indicator is set:
+//
+//	row[i] = row[i+offset], or 0 past the end of the row
+//
+// The indicators are boolean, so indicator * value selects value or 0 with a single multiplication,
+// where api.Select would build y + b*(x-y). Each shifted value is a single sum of these products, rather
+// than a running sum that copies a growing linear expression with every term. terms is scratch space for
+// the products. Every value only reads the values after it, so the row is shifted from its start.
+func shiftRow(api frontend.API, isOffset []frontend.Variable, row []frontend.Variable, terms []frontend.Variable) {
+	for i := range row {
+		terms = terms[:0]
+		for k := 0; i+k < len(row); k++ {
+			terms = append(terms, api.Mul(isOffset[k], row[i+k]))
+		}
+		if len(terms) == 1 {
+			row[i] = terms[0]
+		} else {
+			row[i] = api.Add(terms[0], terms[1], terms[2:]...)
+		}
+	}
+}
 
-	// (inCropAreaX && inCropAreaY)
-	inCropArea := api.And(inCropAreaX, inCropAreaY)
+// offsetIndicators returns, for every offset k in [0, size), 1 if k == offset and 0 if not.
+// It asserts that exactly one indicator is set, i.e. that offset is a location within a row (or column) of
+// size pixels.
+func offsetIndicators(api frontend.API, offset frontend.Variable, size int) []frontend.Variable {
+	indicators := make([]frontend.Variable, size)
+	var count frontend.Variable = 0
+	for k := 0; k < size; k++ {
+		indicators[k] = api.IsZero(api.Sub(offset, k))
+		count = api.Add(count, indicators[k])
+	}
+	api.AssertIsEqual(count, 1)
+	return indicators
+}
+
+// valueIndicators returns, for every value in values, 1 if v is that value and 0 if not, like offsetIndicators
+// does for offsets. It asserts that exactly one indicator is set, i.e. that v is one of values, e.g. one of the
+// permissible values of a public parameter.
+func valueIndicators(api frontend.API, v frontend.Variable, values []int) []frontend.Variable {
+	indicators := make([]frontend.Variable, len(values))
+	var count frontend.Variable = 0
+	for i, value := range values {
+		indicators[i] = api.IsZero(api.Sub(v, value))
+		count = api.Add(count, indicators[i])
+	}
+	api.AssertIsEqual(count, 1)
+	return indicators
+}
+
+// inRange returns 1 if lo <= v <= hi, 0 if not. The bounds are included, i.e. all variables are
+// index locations.
+//
+// Each bound costs a single comparison of a comparator from newLocationComparator, instead of
+// a full 254 bit api.Cmp.
+func inRange(api frontend.API, comparator *cmp.BoundedComparator, v, lo, hi frontend.Variable) frontend.Variable {
+	return api.And(
+		comparator.IsLessEq(lo, v),
+		comparator.IsLessEq(v, hi),
+	)
+}
 
-	return inCropArea
+// newLocationComparator returns a comparator for pixel locations of an image and their translations,
+// which all lie within [-S, 2S] for the larger side S of the image. The comparator only decomposes the difference of its
+// operands into the few bits that such a difference needs; a prover cannot satisfy a comparison of
+// locations further apart.
+func newLocationComparator(api frontend.API) *cmp.BoundedComparator {
+	return cmp.NewBoundedComparator(api, big.NewInt(3*max(myImage.Width, myImage.Height)), false)
 }
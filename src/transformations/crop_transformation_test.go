@@ -0,0 +1,243 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// Asserts inRange(V, Lo, Hi) == In.
+type inRangeCircuit struct {
+	V, Lo, Hi frontend.Variable
+	In        frontend.Variable
+}
+
+func (circuit *inRangeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(inRange(api, newLocationComparator(api), circuit.V, circuit.Lo, circuit.Hi), circuit.In)
+	return nil
+}
+
+func TestInRange(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, c := range []struct {
+		v  int
+		in int
+	}{
+		{3, 1}, // lower bound
+		{6, 1}, // upper bound
+		{4, 1},
+		{2, 0},
+		{7, 0},
+		{-1, 0}, // translated locations can be negative
+	} {
+		assignment := inRangeCircuit{V: c.v, Lo: 3, Hi: 6, In: c.in}
+		assert.NoError(test.IsSolved(&inRangeCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d", c.v)
+
+		assignment.In = 1 - c.in
+		assert.Error(test.IsSolved(&inRangeCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d", c.v)
+	}
+}
+
+// Asserts cropFrontendImage(In, Params) == Out, without the signature check of the CropCircuit.
+type cropPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Params CropParams
+}
+
+func (circuit *cropPixelsCircuit) Define(api frontend.API) error {
+	out := cropFrontendImage(api, &circuit.In, circuit.Params)
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			api.AssertIsEqual(out.Pixels[y][x].R, circuit.Out.Pixels[y][x].R)
+			api.AssertIsEqual(out.Pixels[y][x].G, circuit.Out.Pixels[y][x].G)
+			api.AssertIsEqual(out.Pixels[y][x].B, circuit.Out.Pixels[y][x].B)
+		}
+	}
+	return nil
+}
+
+// Apply returns a new image, and leaves the image it was given as it was.
+func TestApply(t *testing.T) {
+	img := myImage.CoordinateImage()
+	before := img.Pixels
+
+	cropped, err := Transformation{T: Crop, Params: map[string]int{"x0": 2, "y0": 3, "x1": 10, "y1": 7}}.Apply(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cropped.Pixels[0][0] != before[3][2] || cropped.M.Width != 9 || cropped.M.Height != 5 {
+		t.Errorf("the crop is not of the area")
+	}
+	identity, err := Transformation{T: Identity}.Apply(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity.M.Author = "Jane Doe"
+	if identity.Pixels != before || img.Pixels != before || img.M.Width != myImage.Width || img.M.Author != "John Doe" {
+		t.Errorf("Apply changed the image it was given")
+	}
+
+	if _, err := (Transformation{T: Crop, Params: map[string]int{"x0": 2, "y0": 3, "x1": myImage.Width, "y1": 7}}).Apply(img); err == nil {
+		t.Errorf("a crop outside the image was applied")
+	}
+	if _, err := (Transformation{T: -1}).Apply(img); err == nil {
+		t.Errorf("an unknown transformation was applied")
+	}
+}
+
+func TestCropFrontendImage(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, c := range []struct{ x0, y0, x1, y1 int }{
+		{0, 0, myImage.Width - 1, myImage.Height - 1}, // identity
+		{2, 3, 10, 7},
+		{5, 5, 5, 5}, // a single pixel
+		{myImage.Width - 1, 0, myImage.Width - 1, myImage.Height - 1},  // the right column
+		{0, myImage.Height - 1, myImage.Width - 1, myImage.Height - 1}, // the bottom row
+	} {
+		// Over patterns that tell pixels apart, so that a crop reading the wrong pixel is caught
+		for name, in := range map[string]myImage.I{
+			"coordinates":  myImage.CoordinateImage(),
+			"gradient":     myImage.GradientImage(),
+			"checkerboard": myImage.CheckerboardImage(3),
+			"noise":        myImage.NoiseImage(testSeed),
+		} {
+			out, err := in.SubImage(c.x0, c.y0, c.x1, c.y1)
+			assert.NoError(err)
+
+			assignment := cropPixelsCircuit{
+				In:     in.ToFrontendImage(),
+				Out:    out.ToFrontendImage(),
+				Params: CropParams{X0: c.x0, Y0: c.y0, X1: c.x1, Y1: c.y1},
+			}
+			assert.NoError(test.IsSolved(&cropPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%s %v", name, c)
+
+			// The uncropped image is not the crop
+			if c.x1-c.x0 < myImage.Width-1 || c.y1-c.y0 < myImage.Height-1 {
+				assignment.Out = in.ToFrontendImage()
+				assert.Error(test.IsSolved(&cropPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%s %v", name, c)
+			}
+		}
+	}
+
+	// Crop areas outside the image, or upside down, cannot be proven
+	for _, c := range []struct{ x0, y0, x1, y1 int }{
+		{-1, 0, 4, 4},
+		{0, 0, myImage.Width, 4},
+		{0, 0, 4, myImage.Height},
+		{4, 0, 3, 4},
+		{0, 4, 4, 3},
+	} {
+		img := myImage.CoordinateImage()
+		assignment := cropPixelsCircuit{
+			In:     img.ToFrontendImage(),
+			Out:    img.ToFrontendImage(),
+			Params: CropParams{X0: c.x0, Y0: c.y0, X1: c.x1, Y1: c.y1},
+		}
+		assert.Error(test.IsSolved(&cropPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%v", c)
+	}
+}
+
+// Every offset of a crop selects the pixels Crop keeps, along y by Y0 and not by X0: a translation along y by
+// the offset along x is not the crop.
+func TestCropOffsets(t *testing.T) {
+	assert := test.NewAssert(t)
+	in := myImage.CoordinateImage()
+
+	for x0 := 0; x0 < myImage.Width; x0++ {
+		y0 := (5*x0 + 1) % myImage.Height
+		x1, y1 := min(x0+4, myImage.Width-1), min(y0+3, myImage.Height-1)
+		out, err := in.SubImage(x0, y0, x1, y1)
+		assert.NoError(err)
+
+		assignment := cropPixelsCircuit{
+			In:     in.ToFrontendImage(),
+			Out:    out.ToFrontendImage(),
+			Params: CropParams{X0: x0, Y0: y0, X1: x1, Y1: y1},
+		}
+		assert.NoError(test.IsSolved(&cropPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "(%d, %d)", x0, y0)
+
+		if x0 != y0 && x0+y1-y0 < myImage.Height {
+			swapped, err := in.SubImage(x0, x0, x1, x0+y1-y0)
+			assert.NoError(err)
+			assignment.Out = swapped.ToFrontendImage()
+			assert.Error(test.IsSolved(&cropPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "(%d, %d)", x0, y0)
+		}
+	}
+}
+
+// The keys of a crop disclose the parameters they are generated for, in the order of CropParams, and a proof
+// holds for the values of those parameters only.
+func TestCropDisclosed(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	id, err := Transformation{T: Crop, Public: []string{"y1", "x1"}}.Circuit()
+	assert.NoError(err)
+	assert.Equal(CircuitID{Name: "crop", Version: CropCircuitID.Version, Disclosed: "x1,y1"}, id)
+	assert.Equal([]EditParam{{Name: "x1", Identity: myImage.Width - 1}, {Name: "y1", Identity: myImage.Height - 1}}, id.EditParams())
+	assert.Equal(map[string]int{"x1": 9, "y1": 6}, PublicParams(id, Transformation{T: Crop, Params: map[string]int{"x0": 3, "y0": 2, "x1": 9, "y1": 6}}))
+	none, err := Transformation{T: Identity}.Circuit()
+	assert.NoError(err)
+	assert.Equal(CropCircuitID, none)
+	assert.Nil(none.EditParams())
+	for _, public := range [][]string{{"x1", "x1"}, {"width"}} {
+		_, err := Transformation{T: Crop, Public: public}.Circuit()
+		assert.Error(err, "%v", public)
+	}
+	_, err = Transformation{T: Rotate, Public: []string{"quarters"}}.Circuit()
+	assert.Error(err)
+
+	// An original image, the crop of the whole image
+	img := myImage.CoordinateImage()
+	publicKey, signature := signedTestImage(t, img)
+	assignment := CropCircuit{
+		Statement:       testStatement(t, publicKey, signature, testNonce, 0, testNullifier(t), 0, img.Digest(), img.Digest(), publicKey),
+		PublicKey:       publicKey,
+		ImageSignature:  signature,
+		Nonce:           testNonce,
+		PrevProofHash:   0,
+		Nullifier:       testNullifier(t),
+		Hops:            0,
+		ImageBytes:      img.Digest(),
+		PrevImageBytes:  img.Digest(),
+		Metadata:        img.MetadataDigest(),
+		PrevMetadata:    img.MetadataDigest(),
+		Prev:            PrevSignature{PublicKey: publicKey, Signature: signature, PrevProofHash: 0},
+		FrImage:         img.ToFrontendImage(),
+		CroppedImage_in: img.ToFrontendImage(),
+		Params:          CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+	}
+	assert.NoError(assignment.SetDisclosed(id))
+	circuit := CropCircuit{Disclose: assignment.Disclose, Disclosed: make([]frontend.Variable, len(assignment.Disclose))}
+	assert.NoError(test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField()))
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	sig, err := secretKey.Sign(myImage.Statement(img.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+	statementWitness, err := StatementWitness(id, secretKey.Public().Bytes(), sig, big.NewInt(testNonce), big.NewInt(0), testNullifier(t), 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), PublicParams(id, Transformation{T: Identity}))
+	assert.NoError(err)
+	want, err := statementWitness.MarshalBinary()
+	assert.NoError(err)
+	secretWitness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secretWitness.Public()
+	assert.NoError(err)
+	got, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+
+	// Another disclosed size than the size of the crop
+	assignment.Disclosed[0] = myImage.Width - 2
+	assert.Error(test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField()))
+}
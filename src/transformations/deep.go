@@ -0,0 +1,92 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit proves that a published deep color image is the Area of a deep color capture the camera signed,
+// translated to the top left corner like image.Deep.Crop does; a capture that is not cropped has the whole
+// image as its Area. It is the FrameCircuit for channels of image.DeepBits, range checked to [0, 65535].
+//
+// The published image is bound through a single public input, its DeepDigest. The signed ImageBytes are the
+// digest of the Capture, which the circuit recomputes from its pixels and Metadata.
+//
+// Public fields: PublicKey, Nonce, ImageDigest, Area
+// Secret fields: ImageSignature, ImageBytes, Metadata, Capture
+type DeepCircuit struct {
+	PublicKey      eddsa.PublicKey       `gnark:",public"` // the camera's public key
+	Nonce          frontend.Variable     `gnark:",public"` // capture counter of the capture, see image.Statement
+	ImageDigest    frontend.Variable     `gnark:",public"` // DeepDigest of the published image
+	Area           CropParams            `gnark:",public"` // area of the capture that is published
+	ImageSignature eddsa.Signature       // the camera's signature over the capture
+	ImageBytes     frontend.Variable     // Digest of the capture
+	Metadata       frontend.Variable     // MetadataDigest of the capture
+	Capture        myImage.FrontendImage // capture as a FrontendImage of deep color channels
+}
+
+// Defines the Compliance Predicate of a deep color image.
+func (circuit *DeepCircuit) Define(api frontend.API) error {
+	// The published image is the capture, cropped to the Area
+	published := cropFrontendImage(api, &circuit.Capture, circuit.Area)
+
+	// Channels are packed into few field elements before hashing, which is only unambiguous for values of
+	// DeepBits
+	channels := regionChannels(&published)
+	rangeChecker := rangecheck.New(api)
+	for _, channel := range channels {
+		rangeChecker.Check(channel, myImage.DeepBits)
+	}
+	if err := assertPublicDigest(api, circuit.ImageDigest, packBits(api, channels, myImage.DeepBits)...); err != nil {
+		return err
+	}
+
+	// The signed ImageBytes are the digest of the capture
+	if err := assertImageDigest(api, circuit.ImageBytes, circuit.Metadata, regionChannels(&circuit.Capture), myImage.DeepBits); err != nil {
+		return err
+	}
+
+	// Verify the camera's signature over the capture, which has no previous proof
+	return assertSignedStatement(api, circuit.PublicKey, circuit.ImageSignature, circuit.ImageBytes, circuit.Nonce, 0)
+}
+
+// DeepDigest returns the digest of a deep color image: the PublicDigest of its channels, pixel by pixel in the
+// order R, G, B, row by row, packed 15 to a field element.
+func DeepDigest(deep myImage.Deep) *big.Int {
+	channels := make([]uint16, 0, 3*myImage.Width*myImage.Height)
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			pixel := deep.Pixels[y][x]
+			channels = append(channels, pixel.R, pixel.G, pixel.B)
+		}
+	}
+	return channelsDigest(channels, myImage.DeepBits)
+}
+
+// DeepWitness returns the public witness of a proof that deep shows the area {x0, y0, x1, y1} of params of the
+// deep color capture the camera with publicKey took as capture nonce. The verifier builds it from the published
+// image itself.
+func DeepWitness(publicKey []byte, nonce *big.Int, deep myImage.Deep, params map[string]int) (witness.Witness, error) {
+	if err := CheckArea(params); err != nil {
+		return nil, err
+	}
+	if nonce == nil {
+		return nil, fmt.Errorf("missing nonce")
+	}
+
+	var assignment DeepCircuit
+	assignment.PublicKey.Assign(1, publicKey)
+	assignment.Nonce = nonce
+	assignment.ImageDigest = DeepDigest(deep)
+	assignment.Area = Transformation{T: Crop, Params: params}.ToFr().Params
+
+	return frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
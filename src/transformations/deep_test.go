@@ -0,0 +1,87 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// A deep color capture with channels beyond 8 bits.
+func patternDeep() myImage.Deep {
+	deep := myImage.CoordinateImage().ToDeep()
+	deep.Pixels[4][5] = myImage.DeepPixel{R: 65535, G: 40000, B: 1}
+	return deep
+}
+
+func TestDeepCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	capture := patternDeep()
+	signature, err := secretKey.Sign(myImage.Statement(capture.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+
+	area := map[string]int{"x0": 2, "y0": 3, "x1": 10, "y1": 7}
+	published := patternDeep()
+	assert.NoError(published.Crop(2, 3, 10, 7))
+
+	valid := func() DeepCircuit {
+		assignment := DeepCircuit{
+			Nonce:       testNonce,
+			ImageDigest: DeepDigest(published),
+			Area:        CropParams{X0: 2, Y0: 3, X1: 10, Y1: 7},
+			ImageBytes:  capture.Digest(),
+			Metadata:    capture.MetadataDigest(),
+			Capture:     capture.ToFrontendImage(),
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, signature)
+		return assignment
+	}
+
+	assignment := valid()
+	assert.NoError(test.IsSolved(&DeepCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The published image, the capture and the area are all bound
+	for name, tamper := range map[string]func(*DeepCircuit){
+		"image":    func(c *DeepCircuit) { c.ImageDigest = DeepDigest(capture) },
+		"8 bit":    func(c *DeepCircuit) { c.ImageDigest = RegionDigest(published.ToImage()) },
+		"capture":  func(c *DeepCircuit) { c.Capture.Pixels[4][5].G = 40001 },
+		"channel":  func(c *DeepCircuit) { c.Capture.Pixels[4][5].R = 65536 },
+		"nonce":    func(c *DeepCircuit) { c.Nonce = testNonce + 1 },
+		"area":     func(c *DeepCircuit) { c.Area = CropParams{X0: 3, Y0: 3, X1: 11, Y1: 7} },
+		"signed":   func(c *DeepCircuit) { c.Capture.Pixels[0][0].B = capture.Pixels[0][0].B ^ 1 },
+		"metadata": func(c *DeepCircuit) { c.Metadata = 1 },
+	} {
+		assignment := valid()
+		tamper(&assignment)
+		assert.Error(test.IsSolved(&DeepCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// The verifier's public witness is the prover's
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	deepWitness, err := DeepWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), published, area)
+	assert.NoError(err)
+	got, err := deepWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+
+	if _, err := DeepWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), published, map[string]int{"x0": 0, "y0": 0, "x1": myImage.Width, "y1": 7}); err == nil {
+		t.Errorf("DeepWitness accepted an area outside the image")
+	}
+}
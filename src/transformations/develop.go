@@ -0,0 +1,221 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// White balance gains are fixed point numbers with gainFractionBits fractional bits, so GainOne leaves a
+// channel unchanged, bounded by MaxGain.
+const (
+	gainBits         = 10
+	gainFractionBits = 8
+	GainOne          = 1 << gainFractionBits
+	MaxGain          = 1<<gainBits - 1
+
+	// Demosaiced channels are doubled, which keeps the average of the two green samples whole, and white
+	// balance divides them by 2 * GainOne. A white balanced value holds balancedBits, above the RAW white level
+	// when a gain clips the highlights.
+	balanceShift = gainFractionBits + 1
+	balancedBits = myImage.RAWBits + 2
+	rawMax       = 1<<myImage.RAWBits - 1
+
+	// Quantization drops the low bits of a gamma corrected value
+	quantizationBits = myImage.RAWBits - channelBits
+)
+
+// This circuit proves that an image was developed from a RAW capture the camera signed, by the standard steps
+// of a development pipeline:
+//
+//  1. demosaic: every pixel takes the red, the average green and the blue sample of the 2x2 Bayer block it
+//     lies in, a fixed kernel
+//  2. white balance: every channel is multiplied by its public gain out of GainOne, rounded down
+//  3. gamma: a fixed lookup table, see gammaCurve, clips values above the RAW white level and encodes the rest
+//     with gamma 1/2
+//  4. quantization: the RAWBits result is rounded down to an 8 bit channel
+//
+// like Develop does, so that a developed photo remains tied to the sensor data. The white balanced values are
+// part of the witness, Balanced, which the circuit checks rather than divides.
+//
+// Like the DisclosureCircuit, nothing about the capture is public but the camera's key and its capture
+// counter; the developed image is bound through a single public input, its ImageDigest (see RegionDigest).
+// The signed RAWBytes are the digest of the RAW capture, which the circuit recomputes from its samples and
+// Metadata.
+//
+// Public fields: PublicKey, Nonce, ImageDigest, Gains
+// Secret fields: ImageSignature, RAWBytes, Metadata, RAW, Balanced, Developed
+type DevelopCircuit struct {
+	PublicKey      eddsa.PublicKey                                     `gnark:",public"` // the camera's public key
+	Nonce          frontend.Variable                                   `gnark:",public"` // capture counter of the RAW capture
+	ImageDigest    frontend.Variable                                   `gnark:",public"` // RegionDigest of the developed image
+	Gains          [3]frontend.Variable                                `gnark:",public"` // white balance gains of R, G and B, out of GainOne
+	ImageSignature eddsa.Signature                                     // the camera's signature over the RAW capture
+	RAWBytes       frontend.Variable                                   // Digest of the RAW capture
+	Metadata       frontend.Variable                                   // MetadataDigest of the RAW capture
+	RAW            myImage.FrontendRAW                                 // RAW capture as a FrontendRAW
+	Balanced       [3][myImage.Height][myImage.Width]frontend.Variable // white balanced channels, see WhiteBalance
+	Developed      myImage.FrontendImage                               // developed image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a development.
+func (circuit *DevelopCircuit) Define(api frontend.API) error {
+	rangeChecker := rangecheck.New(api)
+	gamma := newGammaTable(api)
+
+	// Bounded gains and samples keep every product below the field size
+	for _, gain := range circuit.Gains {
+		rangeChecker.Check(gain, gainBits)
+	}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			rangeChecker.Check(circuit.RAW.Samples[y][x], myImage.RAWBits)
+		}
+	}
+
+	developed := channelPlanes(&circuit.Developed)
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			linear := demosaicFrontend(api, &circuit.RAW, x, y)
+			for c := range linear {
+				// White balance, rounded down: the remainder is in [0, 2 * GainOne)
+				balanced := circuit.Balanced[c][y][x]
+				remainder := api.Sub(api.Mul(linear[c], circuit.Gains[c]), api.Mul(balanced, 1<<balanceShift))
+				rangeChecker.Check(remainder, balanceShift)
+
+				// Gamma, then quantization rounded down
+				corrected := gamma.gamma(balanced)[0]
+				rangeChecker.Check(api.Sub(corrected, api.Mul(developed[c][y][x], 1<<quantizationBits)), quantizationBits)
+			}
+		}
+	}
+
+	// Channels are packed into few field elements before hashing, which is only unambiguous for 8 bit values
+	channels := regionChannels(&circuit.Developed)
+	assertChannels(api, channels...)
+	if err := assertPublicDigest(api, circuit.ImageDigest, packChannels(api, channels)...); err != nil {
+		return err
+	}
+
+	// The signed RAWBytes are the digest of the RAW capture, its samples packed as 16 bit values
+	samples := make([]frontend.Variable, 0, myImage.Width*myImage.Height)
+	for y := 0; y < myImage.Height; y++ {
+		samples = append(samples, circuit.RAW.Samples[y][:]...)
+	}
+	if err := assertImageDigest(api, circuit.RAWBytes, circuit.Metadata, samples, 16); err != nil {
+		return err
+	}
+
+	// Verify the camera's signature over the RAW capture, which has no previous proof
+	return assertSignedStatement(api, circuit.PublicKey, circuit.ImageSignature, circuit.RAWBytes, circuit.Nonce, 0)
+}
+
+// demosaicFrontend returns the doubled red, green and blue of pixel (x, y), from the samples of its Bayer
+// block, like demosaic does outside the circuit.
+func demosaicFrontend(api frontend.API, raw *myImage.FrontendRAW, x, y int) [3]frontend.Variable {
+	bx, by := x&^1, y&^1
+	return [3]frontend.Variable{
+		api.Mul(raw.Samples[by][bx], 2),
+		api.Add(raw.Samples[by][bx+1], raw.Samples[by+1][bx]),
+		api.Mul(raw.Samples[by+1][bx+1], 2),
+	}
+}
+
+// demosaic returns the doubled red, green and blue of pixel (x, y), from the samples of its RGGB Bayer block.
+func demosaic(raw *myImage.RAW, x, y int) [3]int {
+	bx, by := x&^1, y&^1
+	return [3]int{
+		2 * int(raw.Samples[by][bx]),
+		int(raw.Samples[by][bx+1]) + int(raw.Samples[by+1][bx]),
+		2 * int(raw.Samples[by+1][bx+1]),
+	}
+}
+
+// gammaCurve is the gamma lookup table of the development: a white balanced value v is clipped to the RAW
+// white level, then encoded with gamma 1/2, i.e. floor(sqrt(v * white level)), which maps the white level to
+// itself. The square root is computed on integers so that every build has the same table.
+func gammaCurve(v int) int {
+	return int(new(big.Int).Sqrt(big.NewInt(int64(min(v, rawMax) * rawMax))).Int64())
+}
+
+// CheckGains returns an error if gains are not white balance gains of the DevelopCircuit.
+func CheckGains(gains [3]int) error {
+	for _, gain := range gains {
+		if gain < 0 || gain > MaxGain {
+			return fmt.Errorf("invalid white balance gain %d: expected a gain in [0, %d], out of %d", gain, MaxGain, GainOne)
+		}
+	}
+	return nil
+}
+
+// WhiteBalance returns the demosaiced, white balanced channels of raw for gains, indexed [channel][y][x], the
+// Balanced witness of the DevelopCircuit.
+func WhiteBalance(raw myImage.RAW, gains [3]int) ([3][myImage.Height][myImage.Width]int, error) {
+	var balanced [3][myImage.Height][myImage.Width]int
+	if err := CheckGains(gains); err != nil {
+		return balanced, err
+	}
+	if err := raw.CheckSamples(); err != nil {
+		return balanced, err
+	}
+
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			linear := demosaic(&raw, x, y)
+			for c := range linear {
+				balanced[c][y][x] = linear[c] * gains[c] >> balanceShift
+			}
+		}
+	}
+	return balanced, nil
+}
+
+// Develop returns the image developed from raw with white balance gains, as the DevelopCircuit develops it.
+// Its metadata only holds its width and height.
+func Develop(raw myImage.RAW, gains [3]int) (myImage.I, error) {
+	balanced, err := WhiteBalance(raw, gains)
+	if err != nil {
+		return myImage.I{}, err
+	}
+
+	developed := myImage.I{M: myImage.Metadata{Width: myImage.Width, Height: myImage.Height}}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			var channels [3]uint8
+			for c := range channels {
+				channels[c] = uint8(gammaCurve(balanced[c][y][x]) >> quantizationBits)
+			}
+			developed.Pixels[y][x] = myImage.RGBPixel{R: channels[0], G: channels[1], B: channels[2]}
+		}
+	}
+	return developed, nil
+}
+
+// DevelopWitness returns the public witness of a proof that developed was developed with white balance gains
+// from the RAW capture nonce of the camera with publicKey. The verifier builds it from the published image
+// itself.
+func DevelopWitness(publicKey []byte, nonce *big.Int, developed myImage.I, gains [3]int) (witness.Witness, error) {
+	if err := CheckGains(gains); err != nil {
+		return nil, err
+	}
+	if nonce == nil {
+		return nil, fmt.Errorf("missing nonce")
+	}
+
+	var assignment DevelopCircuit
+	assignment.PublicKey.Assign(1, publicKey)
+	assignment.Nonce = nonce
+	assignment.ImageDigest = RegionDigest(developed)
+	for c, gain := range gains {
+		assignment.Gains[c] = gain
+	}
+
+	return frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
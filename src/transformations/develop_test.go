@@ -0,0 +1,147 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// A RAW capture whose samples cover the whole RAW range.
+func patternRAW() myImage.RAW {
+	raw := myImage.RAW{M: myImage.Metadata{Width: myImage.Width, Height: myImage.Height}}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			raw.Samples[y][x] = uint16((x*myImage.Height + y) * 4)
+		}
+	}
+	return raw
+}
+
+func TestDevelop(t *testing.T) {
+	raw := myImage.RAW{}
+	// A white block, a block with 256 in every sample, and a block with unequal greens
+	raw.Samples[0][0], raw.Samples[0][1], raw.Samples[1][0], raw.Samples[1][1] = 1023, 1023, 1023, 1023
+	raw.Samples[0][2], raw.Samples[0][3], raw.Samples[1][2], raw.Samples[1][3] = 256, 256, 256, 256
+	raw.Samples[0][4], raw.Samples[0][5], raw.Samples[1][4], raw.Samples[1][5] = 0, 100, 300, 0
+
+	developed, err := Develop(raw, [3]int{GainOne, GainOne, GainOne})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		x, y int
+		want myImage.RGBPixel
+	}{
+		{1, 1, myImage.RGBPixel{R: 255, G: 255, B: 255}}, // the white level maps to white
+		{2, 0, myImage.RGBPixel{R: 127, G: 127, B: 127}}, // floor(sqrt(256 * 1023)) / 4
+		{5, 1, myImage.RGBPixel{R: 0, G: 113, B: 0}},     // green is the average of 100 and 300
+		{7, 7, myImage.RGBPixel{}},
+	} {
+		if got := developed.GetPixel(c.x, c.y); got != c.want {
+			t.Errorf("pixel (%d,%d) developed to %v, expected %v", c.x, c.y, got, c.want)
+		}
+	}
+
+	// A gain above the white level clips the highlights, a gain below darkens
+	developed, err = Develop(raw, [3]int{2 * GainOne, GainOne / 4, GainOne})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := developed.GetPixel(2, 0), (myImage.RGBPixel{R: 180, G: 63, B: 127}); got != want {
+		t.Errorf("pixel (2,0) developed to %v, expected %v", got, want)
+	}
+	if got := developed.GetPixel(0, 0).R; got != 255 {
+		t.Errorf("a clipped highlight developed to %d, expected 255", got)
+	}
+
+	if _, err := Develop(raw, [3]int{MaxGain + 1, GainOne, GainOne}); err == nil {
+		t.Error("Develop accepted a gain above MaxGain")
+	}
+	raw.Samples[3][3] = 1 << myImage.RAWBits
+	if _, err := Develop(raw, [3]int{GainOne, GainOne, GainOne}); err == nil {
+		t.Error("Develop accepted a sample above the RAW bit depth")
+	}
+}
+
+func TestDevelopCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	raw := patternRAW()
+	signature, err := secretKey.Sign(myImage.Statement(raw.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+
+	gains := [3]int{2 * GainOne, GainOne, 3 * GainOne / 2}
+	developed, err := Develop(raw, gains)
+	assert.NoError(err)
+	balanced, err := WhiteBalance(raw, gains)
+	assert.NoError(err)
+
+	valid := func() DevelopCircuit {
+		assignment := DevelopCircuit{
+			Nonce:       testNonce,
+			ImageDigest: RegionDigest(developed),
+			RAWBytes:    raw.Digest(),
+			Metadata:    raw.MetadataDigest(),
+			RAW:         raw.ToFrontendRAW(),
+			Developed:   developed.ToFrontendImage(),
+		}
+		for c := range gains {
+			assignment.Gains[c] = gains[c]
+			for y := 0; y < myImage.Height; y++ {
+				for x := 0; x < myImage.Width; x++ {
+					assignment.Balanced[c][y][x] = balanced[c][y][x]
+				}
+			}
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, signature)
+		return assignment
+	}
+
+	assignment := valid()
+	assert.NoError(test.IsSolved(&DevelopCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The developed image, the gains, the capture and every step of the pipeline are bound
+	brighter := developed
+	brighter.Pixels[4][4].G++
+	for name, tamper := range map[string]func(*DevelopCircuit){
+		"image": func(c *DevelopCircuit) { c.ImageDigest = RegionDigest(brighter) },
+		"developed": func(c *DevelopCircuit) {
+			c.ImageDigest = RegionDigest(brighter)
+			c.Developed = brighter.ToFrontendImage()
+		},
+		"gain":     func(c *DevelopCircuit) { c.Gains[1] = GainOne + 1 },
+		"nonce":    func(c *DevelopCircuit) { c.Nonce = testNonce + 1 },
+		"metadata": func(c *DevelopCircuit) { c.Metadata = 1 },
+		"balanced": func(c *DevelopCircuit) { c.Balanced[2][4][4] = balanced[2][4][4] + 1 },
+		"sample":   func(c *DevelopCircuit) { c.RAW.Samples[5][5] = raw.Samples[5][5] + 64 },
+	} {
+		assignment := valid()
+		tamper(&assignment)
+		assert.Error(test.IsSolved(&DevelopCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// The verifier's public witness is the prover's
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	developWitness, err := DevelopWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), developed, gains)
+	assert.NoError(err)
+	got, err := developWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+}
@@ -0,0 +1,151 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+	"src/internal/field"
+)
+
+// This circuit proves a selective disclosure: the published Region is an unmodified rectangle of an original
+// image signed by the camera, translated to the top left corner like image.Crop does. Unlike the CropCircuit,
+// whose statement is about the image it was cropped from, nothing about the original is public: neither its
+// pixels, nor its signature, nor where the region lies in it. Only the camera's key, the capture counter and
+// the region are, so that a source can be protected while part of its picture is published.
+//
+// The region is bound through a single public input, its RegionDigest, which the verifier recomputes from the
+// published region; its size is public, since black rows and columns at its edges could be part of it or not.
+//
+// The Original is packed (see image.I.Pack). The signed ImageBytes are its digest, which the circuit recomputes
+// from the packed elements and Metadata, so that the region is cropped from the pixels the camera signed.
+//
+// Public fields: PublicKey, Nonce, RegionDigest, Width, Height
+// Secret fields: ImageSignature, ImageBytes, Metadata, Original, Params
+type DisclosureCircuit struct {
+	PublicKey      eddsa.PublicKey        `gnark:",public"` // the camera's public key
+	Nonce          frontend.Variable      `gnark:",public"` // capture counter of the original, see image.Statement
+	RegionDigest   frontend.Variable      `gnark:",public"` // RegionDigest of the disclosed region
+	Width          frontend.Variable      `gnark:",public"` // X1 - X0 + 1
+	Height         frontend.Variable      `gnark:",public"` // Y1 - Y0 + 1
+	ImageSignature eddsa.Signature        // the camera's signature over the original
+	ImageBytes     frontend.Variable      // Digest of the original
+	Metadata       frontend.Variable      // MetadataDigest of the original
+	Original       myImage.FrontendPacked // original as a FrontendPacked
+	Params         CropParams             // area of the original that is disclosed, bounded publicly by its size only
+}
+
+// Defines the Compliance Predicate of a selective disclosure.
+func (circuit *DisclosureCircuit) Define(api frontend.API) error {
+	// The region is the original, cropped to the disclosed area
+	original, err := unpackImage(api, &circuit.Original)
+	if err != nil {
+		return err
+	}
+	region := cropFrontendImage(api, &original, circuit.Params)
+	api.AssertIsEqual(circuit.Width, api.Add(api.Sub(circuit.Params.X1, circuit.Params.X0), 1))
+	api.AssertIsEqual(circuit.Height, api.Add(api.Sub(circuit.Params.Y1, circuit.Params.Y0), 1))
+
+	// Channels are packed into few field elements before hashing, which is only unambiguous for 8 bit values
+	channels := regionChannels(&region)
+	assertChannels(api, channels...)
+	if err := assertPublicDigest(api, circuit.RegionDigest, packChannels(api, channels)...); err != nil {
+		return err
+	}
+
+	// The signed ImageBytes are the digest of the original
+	if err := assertPackedDigest(api, circuit.ImageBytes, circuit.Metadata, circuit.Original.Elements[:]); err != nil {
+		return err
+	}
+
+	// Verify the camera's signature over the original, which has no previous proof
+	return assertSignedStatement(api, circuit.PublicKey, circuit.ImageSignature, circuit.ImageBytes, circuit.Nonce, 0)
+}
+
+// RegionDigest returns the digest of a disclosed region: the PublicDigest of its channels, packed 31 to a
+// field element.
+func RegionDigest(region myImage.I) *big.Int {
+	var channels []uint8
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			pixel := region.Pixels[y][x]
+			channels = append(channels, pixel.R, pixel.G, pixel.B)
+		}
+	}
+	return channelsDigest(channels, channelBits)
+}
+
+// The PublicDigest of channels of bits each, packed 253/bits to a field element.
+func channelsDigest[T uint8 | uint16](channels []T, bits int) *big.Int {
+	return PublicDigest(field.Pack(channels, bits)...)
+}
+
+// Number of bits of channels packed into one field element, see field.Pack: 31 8 bit channels, or 15 channels
+// of a deep color image.
+const packedBits = field.PackedBits
+
+// The channels of an image, pixel by pixel in the order R, G, B, row by row.
+func regionChannels(img *myImage.FrontendImage) []frontend.Variable {
+	channels := make([]frontend.Variable, 0, 3*myImage.Width*myImage.Height)
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			pixel := img.Pixels[y][x]
+			channels = append(channels, pixel.R, pixel.G, pixel.B)
+		}
+	}
+	return channels
+}
+
+// packChannels packs 8 bit channels little endian into field elements of packedBits, as RegionDigest does
+// outside the circuit. The packing is a linear combination, which costs no constraints.
+func packChannels(api frontend.API, channels []frontend.Variable) []frontend.Variable {
+	return packBits(api, channels, channelBits)
+}
+
+// packBits packs channels of bits each like packChannels packs 8 bit channels.
+func packBits(api frontend.API, channels []frontend.Variable, bits int) []frontend.Variable {
+	perElement := packedBits / bits
+	var packed []frontend.Variable
+	for start := 0; start < len(channels); start += perElement {
+		var element frontend.Variable = 0
+		var shift = big.NewInt(1)
+		for i := start; i < min(start+perElement, len(channels)); i++ {
+			element = api.Add(element, api.Mul(channels[i], new(big.Int).Set(shift)))
+			shift.Lsh(shift, uint(bits))
+		}
+		packed = append(packed, element)
+	}
+	return packed
+}
+
+// DisclosureWitness returns the public witness of a disclosure of region, from a picture the camera with
+// publicKey took as capture nonce. The verifier builds it from the published region itself.
+func DisclosureWitness(publicKey []byte, nonce *big.Int, region myImage.I) (witness.Witness, error) {
+	width, height, err := regionSize(region)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignment DisclosureCircuit
+	assignment.PublicKey.Assign(1, publicKey)
+	assignment.Nonce = nonce
+	assignment.RegionDigest = RegionDigest(region)
+	assignment.Width = width
+	assignment.Height = height
+
+	return frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
+
+// The size of a region, as recorded in its metadata by image.Crop.
+func regionSize(region myImage.I) (int, int, error) {
+	width, height := region.M.Width, region.M.Height
+	if width < 1 || width > myImage.Width || height < 1 || height > myImage.Height {
+		return 0, 0, fmt.Errorf("invalid region size %d x %d", width, height)
+	}
+	return width, height, nil
+}
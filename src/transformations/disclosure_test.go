@@ -0,0 +1,119 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+func TestDisclosureCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	original := myImage.CoordinateImage()
+	signature, err := secretKey.Sign(myImage.Statement(original.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+
+	region := myImage.CoordinateImage()
+	assert.NoError(region.Crop(2, 3, 10, 7))
+
+	valid := func() DisclosureCircuit {
+		assignment := DisclosureCircuit{
+			Nonce:        testNonce,
+			RegionDigest: RegionDigest(region),
+			Width:        9,
+			Height:       5,
+			ImageBytes:   original.Digest(),
+			Metadata:     original.MetadataDigest(),
+			Original:     original.ToFrontendPacked(),
+			Params:       CropParams{X0: 2, Y0: 3, X1: 10, Y1: 7},
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, signature)
+		return assignment
+	}
+
+	assignment := valid()
+	assert.NoError(test.IsSolved(&DisclosureCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The published region, its size and the capture are all bound
+	for name, tamper := range map[string]func(*DisclosureCircuit){
+		"region":   func(c *DisclosureCircuit) { c.RegionDigest = RegionDigest(myImage.CoordinateImage()) },
+		"width":    func(c *DisclosureCircuit) { c.Width = 10 },
+		"height":   func(c *DisclosureCircuit) { c.Height = 4 },
+		"nonce":    func(c *DisclosureCircuit) { c.Nonce = testNonce + 1 },
+		"location": func(c *DisclosureCircuit) { c.Params = CropParams{X0: 3, Y0: 3, X1: 11, Y1: 7} },
+		"signed":   func(c *DisclosureCircuit) { c.Original.Elements[0] = flipLowBit(original.Pack()[0]) },
+		"metadata": func(c *DisclosureCircuit) { c.Metadata = 1 },
+	} {
+		assignment := valid()
+		tamper(&assignment)
+		assert.Error(test.IsSolved(&DisclosureCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// The verifier's public witness is the prover's
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	disclosureWitness, err := DisclosureWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), region)
+	assert.NoError(err)
+	got, err := disclosureWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+}
+
+// The location of a region is a secret witness: regions of the same pixels at different locations of an
+// original have the same public witness, which bounds the location by the size of the region only.
+func TestDisclosureLocation(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	original := myImage.AllWhiteImage()
+	signature, err := secretKey.Sign(myImage.Statement(original.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+
+	var publicWitnesses [][]byte
+	for _, params := range []CropParams{{X0: 0, Y0: 0, X1: 3, Y1: 2}, {X0: 5, Y0: 4, X1: 8, Y1: 6}, {X0: 12, Y0: 9, X1: 15, Y1: 11}} {
+		region := myImage.AllWhiteImage()
+		assert.NoError(region.Crop(params.X0.(int), params.Y0.(int), params.X1.(int), params.Y1.(int)))
+
+		assignment := DisclosureCircuit{
+			Nonce:        testNonce,
+			RegionDigest: RegionDigest(region),
+			Width:        4,
+			Height:       3,
+			ImageBytes:   original.Digest(),
+			Metadata:     original.MetadataDigest(),
+			Original:     original.ToFrontendPacked(),
+			Params:       params,
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, signature)
+		assert.NoError(test.IsSolved(&DisclosureCircuit{}, &assignment, ecc.BN254.ScalarField()), "location %+v", params)
+
+		secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		publicWitness, err := secret_witness.Public()
+		assert.NoError(err)
+		bytes, err := publicWitness.MarshalBinary()
+		assert.NoError(err)
+		publicWitnesses = append(publicWitnesses, bytes)
+	}
+	for _, publicWitness := range publicWitnesses[1:] {
+		assert.Equal(publicWitnesses[0], publicWitness)
+	}
+}
@@ -0,0 +1,185 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+func init() {
+	solver.RegisterHint(quotientHint)
+}
+
+// Number of bits that holds the remainder of a division by the pixels of a 2 x 2 block.
+const blockRemainderBits = 2
+
+// This circuit is only for Downscale transformations: the signed image is the image FrImage, of the Width and
+// Height of Params, halved by averaging blocks of 2 x 2 pixels, like image.I.Downscale does, or FrImage itself
+// when Params does not Scale. It has the public fields of the CropCircuit, so its proofs are verified and
+// chained like the proofs of a crop.
+// Public fields: Statement
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, ScaledImage_in, Params
+type DownscaleCircuit struct {
+	Statement      frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey      eddsa.PublicKey       // Key the image is signed with
+	ImageSignature eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce          frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash  frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier      frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops           frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes     frontend.Variable     // Digest of the signed image, ScaledImage_in
+	PrevImageBytes frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Metadata       frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata   frontend.Variable     // MetadataDigest of the previous image
+	Prev           PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage        myImage.FrontendImage // z_in as a FrontendImage
+	ScaledImage_in myImage.FrontendImage // Downscaled previous image as a FrontendImage
+	Params         DownscaleParams       // Downscale transformation parameters
+}
+
+// Parameters of a downscale: 1 to halve the image, 0 to keep it, and the size of the image before the
+// downscale, every pixel outside it black.
+type DownscaleParams struct {
+	Scale  frontend.Variable
+	Width  frontend.Variable
+	Height frontend.Variable
+}
+
+// Defines the Compliance Predicate of a downscale by 2.
+func (circuit *DownscaleCircuit) Define(api frontend.API) error {
+	// Downscale the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	if err := downscalePlanes(api, planes[:], circuit.Params); err != nil {
+		return err
+	}
+	scaledImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.ScaledImage_in, &scaledImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.ScaledImage_in)
+}
+
+// downscalePlanes halves every plane of the size of params, in place, when params Scale, exactly like
+// image.I.Downscale does outside the circuit: every pixel is the average, rounded down, of the pixels of the
+// image in a block of 2 x 2, with the blocks of the right and bottom edges of an odd size holding 1 or 2.
+//
+// Blocks are at constant locations, but the number of pixels of the image they hold depends on its size, and
+// a circuit cannot divide integers. So the averages are computed by a hint, and asserted to be the quotients
+// of the sums of the blocks by their number of pixels: the remainder of a sum is in [0, pixels). An average
+// is only unique as a channel of 8 bits, which the digest of the signed image range checks. A block outside
+// the image sums black pixels, which is divided by 1 instead of 0 and averages black.
+func downscalePlanes(api frontend.API, planes []channelPlane, params DownscaleParams) error {
+	const width, height = myImage.Width / 2, myImage.Height / 2
+	comparator := newLocationComparator(api)
+	rangeChecker := rangecheck.New(api)
+	api.AssertIsBoolean(params.Scale)
+
+	// The image lies within the pixels, every pixel outside it black, so its blocks hold all the image has
+	inWidth, inHeight := imageIndicators(api, comparator, planes, params.Width, params.Height)
+
+	// The number of pixels of the image in every block, 1 for a block outside it
+	var pixels [height][width]frontend.Variable
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			columns := api.Add(inWidth[2*x], inWidth[2*x+1])
+			rows := api.Add(inHeight[2*y], inHeight[2*y+1])
+			pixels[y][x] = api.Add(api.Mul(columns, rows), api.Sub(1, api.And(inWidth[2*x], inHeight[2*y])))
+		}
+	}
+
+	// The sum of every block, and its average computed by the hint
+	sums := make([]frontend.Variable, 0, len(planes)*width*height)
+	divisors := make([]frontend.Variable, 0, len(planes)*width*height)
+	for c := range planes {
+		plane := &planes[c]
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				sums = append(sums, api.Add(plane[2*y][2*x], plane[2*y][2*x+1], plane[2*y+1][2*x], plane[2*y+1][2*x+1]))
+				divisors = append(divisors, pixels[y][x])
+			}
+		}
+	}
+	averages, err := api.Compiler().NewHint(quotientHint, len(sums), append(sums, divisors...)...)
+	if err != nil {
+		return err
+	}
+	for i := range averages {
+		remainder := api.Sub(sums[i], api.Mul(averages[i], divisors[i]))
+		rangeChecker.Check(remainder, blockRemainderBits)
+		rangeChecker.Check(api.Sub(divisors[i], remainder, 1), blockRemainderBits)
+	}
+
+	// plane[y][x] = average of the block at (x, y) when scaling, black past the halved pixels, plane[y][x] when not
+	for c := range planes {
+		plane := &planes[c]
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				var scaled frontend.Variable = 0
+				if x < width && y < height {
+					scaled = averages[(c*height+y)*width+x]
+				}
+				plane[y][x] = api.Add(plane[y][x], api.Mul(params.Scale, api.Sub(scaled, plane[y][x])))
+			}
+		}
+	}
+	return nil
+}
+
+// imageIndicators returns, for every column and every row of the planes, 1 if it lies within an image of the
+// given width and height and 0 if not. It asserts the image lies within the planes, at least a pixel wide and
+// high, and that every pixel of the planes outside of it is black, as the pixels outside of an image are.
+func imageIndicators(api frontend.API, comparator *cmp.BoundedComparator, planes []channelPlane, width, height frontend.Variable) (*[myImage.Width]frontend.Variable, *[myImage.Height]frontend.Variable) {
+	comparator.AssertIsLessEq(1, width)
+	comparator.AssertIsLessEq(1, height)
+	comparator.AssertIsLessEq(width, myImage.Width)
+	comparator.AssertIsLessEq(height, myImage.Height)
+	var inWidth [myImage.Width]frontend.Variable
+	for x := range inWidth {
+		inWidth[x] = comparator.IsLess(x, width)
+	}
+	var inHeight [myImage.Height]frontend.Variable
+	for y := range inHeight {
+		inHeight[y] = comparator.IsLess(y, height)
+	}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			outside := api.Sub(1, api.And(inWidth[x], inHeight[y]))
+			for c := range planes {
+				api.AssertIsEqual(api.Mul(outside, planes[c][y][x]), 0)
+			}
+		}
+	}
+	return &inWidth, &inHeight
+}
+
+// quotientHint computes the quotients of downscalePlanes and colorMatrixPlanes, rounded down: its inputs are the
+// dividends, then as many divisors.
+func quotientHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 2*len(outputs) {
+		return fmt.Errorf("quotientHint: %d inputs for %d quotients", len(inputs), len(outputs))
+	}
+	divisors := inputs[len(outputs):]
+	for i := range outputs {
+		if divisors[i].Sign() == 0 {
+			return fmt.Errorf("quotientHint: division by zero")
+		}
+		outputs[i].Div(inputs[i], divisors[i])
+	}
+	return nil
+}
+
+// DownscaleCircuitParams returns the parameters of the DownscaleCircuit for img, halved when scale is set.
+func DownscaleCircuitParams(img myImage.I, scale bool) DownscaleParams {
+	params := DownscaleParams{Scale: 0, Width: img.M.Width, Height: img.M.Height}
+	if scale {
+		params.Scale = 1
+	}
+	return params
+}
@@ -0,0 +1,69 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts downscalePlanes(In, Params) == Out, without the signature check of the DownscaleCircuit.
+type downscalePixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Params DownscaleParams
+}
+
+func (circuit *downscalePixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := downscalePlanes(api, planes[:], circuit.Params); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestDownscalePlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, size := range [][2]int{{myImage.Width, myImage.Height}, {9, 5}, {1, 1}} {
+		in, err := myImage.NoiseImage(testSeed).SubImage(myImage.Width-size[0], myImage.Height-size[1], myImage.Width-1, myImage.Height-1)
+		assert.NoError(err)
+		out, err := in.Downscale()
+		assert.NoError(err)
+
+		assignment := downscalePixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: DownscaleCircuitParams(in, true)}
+		assert.NoError(test.IsSolved(&downscalePixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "size %v", size)
+
+		// Without a downscale, the image is kept
+		kept := downscalePixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Params: DownscaleCircuitParams(in, false)}
+		assert.NoError(test.IsSolved(&downscalePixelsCircuit{}, &kept, ecc.BN254.ScalarField()), "size %v", size)
+
+		// An average off by one is not the average of its block
+		if size[0] > 1 {
+			wrong := out
+			wrong.Pixels[0][0].G ^= 1
+			assignment.Out = wrong.ToFrontendImage()
+			assert.Error(test.IsSolved(&downscalePixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "size %v", size)
+		}
+	}
+
+	// The downscale is of the size of the image, which cannot hide pixels past it
+	in := myImage.NoiseImage(testSeed)
+	out, err := in.Downscale()
+	assert.NoError(err)
+	for name, params := range map[string]DownscaleParams{
+		"not boolean": {Scale: 2, Width: myImage.Width, Height: myImage.Height},
+		"narrower":    {Scale: 1, Width: myImage.Width - 1, Height: myImage.Height},
+		"shorter":     {Scale: 1, Width: myImage.Width, Height: myImage.Height - 1},
+		"no width":    {Scale: 1, Width: 0, Height: myImage.Height},
+		"wider":       {Scale: 1, Width: myImage.Width + 1, Height: myImage.Height},
+	} {
+		assignment := downscalePixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: params}
+		assert.Error(test.IsSolved(&downscalePixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+}
@@ -0,0 +1,140 @@
+package transformations
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// An EditStatement is what the compliance predicates of edits other than a crop, e.g. the RotateCircuit, are
+// assigned besides the pixels: the statement of the CropCircuit, and the digest of the signed image.
+type EditStatement struct {
+	Statement      frontend.Variable // StatementDigest of the fields PublicKey to PrevImageBytes
+	PublicKey      eddsa.PublicKey
+	ImageSignature eddsa.Signature
+	Nonce          frontend.Variable
+	PrevProofHash  frontend.Variable
+	Nullifier      frontend.Variable
+	Hops           frontend.Variable // Edits since the original image, see MaxHops
+	ImageBytes     frontend.Variable // Digest of the signed image
+	Metadata       frontend.Variable // MetadataDigest of the signed image
+	PrevImageBytes frontend.Variable // Digest of the previous image, the ImageBytes of the proof the edit was made from
+	PrevMetadata   frontend.Variable // MetadataDigest of the previous image
+	Prev           PrevSignature     // Signature of the previous image, see PrevSignature
+}
+
+// EditAssignment returns the assignment of the compliance predicate id, of an edit other than a crop, that
+// the signed image out is the image in transformed by t. An Identity is the edit of the predicate that keeps
+// the image, e.g. a rotation by no quarter turn, which is how an original image is proven with the keys of
+// the predicate.
+func EditAssignment(id CircuitID, t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+	if t.T != Identity {
+		if tID, err := t.Circuit(); err != nil || tID != id {
+			return nil, fmt.Errorf("the transformation is not proven by circuit %s", id)
+		}
+	}
+
+	edit, ok := editOf(id)
+	if !ok || edit.Circuit().Version != id.Version {
+		return nil, fmt.Errorf("circuit %s is not the compliance predicate of an edit", id)
+	}
+	if composed, ok := edit.(composedEdit); ok {
+		return composed.assignment(id, t, in, out, statement)
+	}
+	return edit.Assignment(t, in, out, statement)
+}
+
+// A public parameter of an edit, e.g. the Delta of a Brightness: its key in the Params of a Transformation, and
+// its value for an Identity, the edit of the predicate that keeps the image.
+type EditParam struct {
+	Name     string
+	Identity int
+}
+
+// EditParams returns the public parameters of proofs of the circuit id, in the order of its public fields, or
+// nil if it has none.
+func (id CircuitID) EditParams() []EditParam {
+	if id.Name == CropCircuitID.Name {
+		return cropEditParams(id)
+	}
+	if edit, ok := editOf(id); ok {
+		if composed, ok := edit.(composedEdit); ok {
+			return composed.paramsOf(id)
+		}
+		return edit.Params()
+	}
+	return nil
+}
+
+// PublicParams returns the values of the public parameters of a proof of the circuit id that the image is
+// edited by t, those of an Identity for an Identity, or nil if the circuit has none. A verifier reads them to
+// learn how an image was edited, e.g. by how much it was brightened.
+func PublicParams(id CircuitID, t Transformation) map[string]int {
+	if len(id.EditParams()) == 0 {
+		return nil
+	}
+	params := make(map[string]int, len(id.EditParams()))
+	for _, param := range id.EditParams() {
+		params[param.Name] = t.Params[param.Name]
+		if t.T == Identity {
+			params[param.Name] = param.Identity
+		}
+	}
+	return params
+}
+
+// assertEqualImages asserts that the images have equal pixels.
+func assertEqualImages(api frontend.API, a, b *myImage.FrontendImage) {
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			api.AssertIsEqual(a.Pixels[y][x].R, b.Pixels[y][x].R)
+			api.AssertIsEqual(a.Pixels[y][x].G, b.Pixels[y][x].G)
+			api.AssertIsEqual(a.Pixels[y][x].B, b.Pixels[y][x].B)
+		}
+	}
+}
+
+// assertSignedEdit asserts what the compliance predicate of every edit asserts besides its pixels, like the
+// CropCircuit: that the channels of the previous image in are color channel values, that prevImageBytes are
+// its digest with prevMetadata, the Nullifier of an original image, that the signed image is at most MaxHops
+// edits past it, that imageBytes are the digest of the signed image out and its metadata, the signature over
+// the statement of imageBytes, the nonce and prevProofHash, the signature prev of the previous image, and that
+// statement is the StatementDigest of them all, the public input they are bound to.
+func assertSignedEdit(api frontend.API, statement frontend.Variable, publicKey eddsa.PublicKey, signature eddsa.Signature, nonce, prevProofHash, nullifier, hops, imageBytes, metadata, prevImageBytes, prevMetadata frontend.Variable, prev PrevSignature, in, out *myImage.FrontendImage) error {
+	// The previous image is an image, whichever of its pixels the edit keeps; the digest checks the signed one
+	assertPixels(api, in)
+
+	// The previous image is the image of the proof the edit was made from
+	if err := assertPrevImage(api, prevImageBytes, prevMetadata, imageBytes, prevProofHash, in); err != nil {
+		return err
+	}
+
+	// An original image is signed by the camera, whose key and capture counter make the Nullifier
+	if err := assertNullifier(api, nullifier, publicKey, nonce, prevProofHash); err != nil {
+		return err
+	}
+
+	// The image is at most MaxHops edits past the original
+	assertHops(api, hops, prevProofHash)
+
+	// The signed ImageBytes are the digest of the edited image
+	if err := assertImageDigest(api, imageBytes, metadata, regionChannels(out), channelBits); err != nil {
+		return err
+	}
+
+	// Verify the ImageSignature over the statement of ImageBytes, the Nonce and the PrevProofHash
+	if err := assertSignedStatement(api, publicKey, signature, imageBytes, nonce, prevProofHash); err != nil {
+		return err
+	}
+
+	// The previous image was signed for the same capture, by the key the statement exposes
+	if err := assertPrevSignature(api, prev, prevImageBytes, nonce); err != nil {
+		return err
+	}
+
+	// The public Statement is the digest of the secret values of the statement
+	return assertStatementDigest(api, statement, publicKey, signature, nonce, prevProofHash, nullifier, hops, imageBytes, prevImageBytes, prev.PublicKey)
+}
@@ -0,0 +1,5 @@
+// Package edits holds the golden proofs of the compliance predicates of the edits of a single image, e.g.
+// rotations, flips and photometric adjustments, which package transformations defines. Each predicate needs its
+// own setup to be proven, so these are kept apart from the tests of package transformations, and the tests of
+// each package run within their own timeout.
+package edits
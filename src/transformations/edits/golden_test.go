@@ -0,0 +1,209 @@
+package edits
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+
+	"src/hashsuite"
+	myImage "src/image"
+	"src/internal/testutil"
+	myTransformations "src/transformations"
+)
+
+var update = flag.Bool("update", false, "rewrite the files in testdata/ with the current results")
+
+// A short run only compiles the predicates and compares the constraints and public witness of their golden
+// records, because the setup of their keys takes minutes each. An update always records them in full.
+func short() bool {
+	return testing.Short() && !*update
+}
+
+// Seed used to derive the camera key, and nonce the test image is signed for, like in the circuit tests of
+// package transformations, so that the public witness is identical on every run.
+const (
+	testSeed  = 1
+	testNonce = 7
+)
+
+// An edit whose compliance predicate is under test, and the transformation of an all white image it is proven
+// for.
+type editCase struct {
+	id myTransformations.CircuitID
+	t  myTransformations.Transformation
+}
+
+// Every compliance predicate of an edit of a single image. The edits keep the all white image, or are an
+// Identity when they do not, e.g. a Sepia.
+var editCases = []editCase{
+	{myTransformations.RotateCircuitID, myTransformations.Transformation{T: myTransformations.Rotate, Params: map[string]int{"quarters": 2}}},
+	{myTransformations.FlipHCircuitID, myTransformations.Transformation{T: myTransformations.FlipH}},
+	{myTransformations.FlipVCircuitID, myTransformations.Transformation{T: myTransformations.FlipV}},
+	{myTransformations.DownscaleCircuitID, myTransformations.Transformation{T: myTransformations.Identity}},
+	{myTransformations.RotateCropCircuitID, myTransformations.Transformation{T: myTransformations.RotateCrop, Params: map[string]int{"quarters": 2, "x0": 0, "y0": 0, "x1": myImage.Width - 1, "y1": myImage.Height - 1}}},
+	{myTransformations.BrightnessCircuitID, myTransformations.Transformation{T: myTransformations.Brightness, Params: map[string]int{"delta": 40}}},
+	{myTransformations.GammaCircuitID, myTransformations.Transformation{T: myTransformations.Gamma, Params: map[string]int{"gamma": 220}}},
+	{myTransformations.SepiaCircuitID, myTransformations.Transformation{T: myTransformations.Identity}},
+	{myTransformations.HueSaturationCircuitID, myTransformations.Transformation{T: myTransformations.HueSaturation, Params: map[string]int{"hue": 90, "saturation": 384}}},
+	{myTransformations.GainCircuitID, myTransformations.Transformation{T: myTransformations.Gain, Params: map[string]int{"gain_r": 384, "gain_g": 512, "gain_b": 256}}},
+	{myTransformations.ThresholdCircuitID, myTransformations.Transformation{T: myTransformations.Threshold, Params: map[string]int{"threshold": 200}}},
+	{myTransformations.ChannelSwapCircuitID, myTransformations.Transformation{T: myTransformations.ChannelSwap, Params: map[string]int{"source_r": 2, "source_g": 0, "source_b": 1}}},
+	{myTransformations.RedactCircuitID, myTransformations.Transformation{T: myTransformations.Identity}},
+	{myTransformations.MosaicCircuitID, myTransformations.Transformation{T: myTransformations.Mosaic, Params: map[string]int{"x0": 2, "y0": 1, "x1": 13, "y1": 10, "block": 4}}},
+	{myTransformations.BlurCircuitID, myTransformations.Transformation{T: myTransformations.Blur, Params: map[string]int{"x0": 3, "y0": 2, "x1": 12, "y1": 9}}},
+	{myTransformations.SharpenCircuitID, myTransformations.Transformation{T: myTransformations.Sharpen, Params: map[string]int{"x0": 1, "y0": 1, "x1": 14, "y1": 10}}},
+	{myTransformations.WatermarkCircuitID, myTransformations.Transformation{T: myTransformations.Watermark, Params: myTransformations.WatermarkParams(&myImage.Watermark{{{R: 200, G: 16, B: 32}}}, 12, 8)}},
+	{myTransformations.CaptionCircuitID, myTransformations.Transformation{T: myTransformations.Caption, Params: map[string]int{"y": myImage.Height - myImage.CaptionHeight, "caption_0": 0x0f0f, "caption_1": 0x0990, "caption_2": 0x0f0f, "caption_3": 0}}},
+	{myTransformations.BorderCircuitID, myTransformations.Transformation{T: myTransformations.Border, Params: map[string]int{"width": 3, "r": 20, "g": 20, "b": 20}}},
+	{myTransformations.ConvolutionCircuitID, myTransformations.Transformation{T: myTransformations.Convolution, Params: map[string]int{"x0": 2, "y0": 2, "x1": 9, "y1": 6, "kernel": 2}}},
+	{myTransformations.PolicyCircuitID, myTransformations.Transformation{T: myTransformations.Policy, Params: map[string]int{"selector": myTransformations.Rotate, "quarters": 2, "x0": 0, "y0": 0, "x1": 0, "y1": 0}}},
+	{myTransformations.CircuitID{Name: myTransformations.ChainCircuitID.Name, Version: myTransformations.ChainCircuitID.Version, Steps: "crop,brightness,fliph"}, myTransformations.Transformation{T: myTransformations.Chain, Params: map[string]int{"step_0": myTransformations.Crop, "x0_0": 0, "y0_0": 0, "x1_0": myImage.Width - 1, "y1_0": myImage.Height - 1, "step_1": myTransformations.Brightness, "delta_1": 40, "step_2": myTransformations.FlipH}}},
+	{myTransformations.CreditCircuitID, myTransformations.Transformation{T: myTransformations.Credit, Params: myTransformations.CreditParams("PhotoGnark")}},
+}
+
+// TestGoldenProofs compiles the compliance predicate of every edit, proves it over the EditAssignment of its
+// transformation of an all white image and compares the result against testdata/golden/<circuit>.golden, like
+// TestGoldenProofs of package transformations does for its other predicates.
+func TestGoldenProofs(t *testing.T) {
+	for _, c := range editCases {
+		t.Run(c.id.Name, func(t *testing.T) {
+			got := goldenRecord(t, c)
+			path := filepath.Join("testdata", "golden", c.id.Name+".golden")
+
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if short() {
+				// The record of a short run stops before the proof, so it only has to start the golden file
+				want = want[:min(len(want), len(got))]
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s changed; if this is intended, run go test -update\n--- got\n%s--- want\n%s", path, got, want)
+			}
+		})
+	}
+}
+
+// Compile, setup, prove and verify an edit case and return its golden record.
+func goldenRecord(t *testing.T, c editCase) []byte {
+	// Compiling a circuit replaces its fields, so it is compiled from an assignment of its own
+	compliance_predicate, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, editAssignment(t, c))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret_witness, err := frontend.NewWitness(editAssignment(t, c), ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitness, err := secret_witness.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitnessBytes, err := publicWitness.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var record bytes.Buffer
+	fmt.Fprintf(&record, "constraints: %d\n", compliance_predicate.GetNbConstraints())
+	fmt.Fprintf(&record, "constraints-sha256: %x\n", testutil.ConstraintsDigest(t, compliance_predicate))
+	fmt.Fprintf(&record, "public-witness: %s\n", hex.EncodeToString(publicWitnessBytes))
+	if short() {
+		return record.Bytes()
+	}
+
+	provingKey, verifyingKey, err := groth16.Setup(compliance_predicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(compliance_predicate, provingKey, secret_witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var proofBytes bytes.Buffer
+	if _, err := proof.WriteTo(&proofBytes); err != nil {
+		t.Fatal(err)
+	}
+
+	verified := groth16.Verify(proof, verifyingKey, publicWitness) == nil
+
+	fmt.Fprintf(&record, "proof-size: %d\n", proofBytes.Len())
+	fmt.Fprintf(&record, "verified: %t\n", verified)
+
+	return record.Bytes()
+}
+
+// The EditAssignment of an edit case: the all white image, edited by the transformation of the case and signed
+// for testNonce with a key derived from testSeed, as the first edit of a proof whose hash is 1.
+func editAssignment(t *testing.T, c editCase) frontend.Circuit {
+	t.Helper()
+
+	img := myImage.AllWhiteImage()
+	out, err := c.t.Apply(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := secretKey.Sign(myImage.Statement(out.Digest(), big.NewInt(testNonce), big.NewInt(1)), hashsuite.Default.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevSignature, err := secretKey.Sign(myImage.Statement(img.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nullifier, err := myTransformations.Nullifier(secretKey.Public().Bytes(), big.NewInt(testNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement := myTransformations.EditStatement{
+		Nonce:          testNonce,
+		PrevProofHash:  1,
+		Nullifier:      nullifier,
+		Hops:           1,
+		ImageBytes:     out.Digest(),
+		Metadata:       out.MetadataDigest(),
+		PrevImageBytes: img.Digest(),
+		PrevMetadata:   img.MetadataDigest(),
+		Prev:           myTransformations.NewPrevSignature(secretKey.Public().Bytes(), prevSignature, big.NewInt(0)), // The original image
+	}
+	statement.PublicKey.Assign(1, secretKey.Public().Bytes())
+	statement.ImageSignature.Assign(1, signature)
+	statement.Statement, err = myTransformations.StatementDigest(secretKey.Public().Bytes(), signature, big.NewInt(testNonce), big.NewInt(1), nullifier, 1, out.Digest(), img.Digest(), secretKey.Public().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assignment, err := myTransformations.EditAssignment(c.id, c.t, img, out, statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assignment
+}
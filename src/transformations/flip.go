@@ -0,0 +1,167 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for FlipH transformations: the signed image is the image FrImage mirrored left to
+// right within the width of Params, like image.I.FlipH does, or FrImage itself when Params does not Flip. It
+// has the public fields of the CropCircuit, so its proofs are verified and chained like the proofs of a crop.
+// Public fields: Statement
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, FlippedImage_in, Params
+type FlipHCircuit struct {
+	Statement       frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey       // Key the image is signed with
+	ImageSignature  eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable     // Digest of the signed image, FlippedImage_in
+	PrevImageBytes  frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Metadata        frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable     // MetadataDigest of the previous image
+	Prev            PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage         myImage.FrontendImage // z_in as a FrontendImage
+	FlippedImage_in myImage.FrontendImage // Flipped previous image as a FrontendImage
+	Params          FlipParams            // Flip transformation parameters
+}
+
+// Parameters of a flip: 1 to mirror the image, 0 to keep it, and the size of the image along the mirrored
+// axis, its width for a FlipH and its height for a FlipV, every pixel past it black.
+type FlipParams struct {
+	Flip frontend.Variable
+	Size frontend.Variable
+}
+
+// Defines the Compliance Predicate of a horizontal flip.
+func (circuit *FlipHCircuit) Define(api frontend.API) error {
+	// Flip the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	flipPlanesH(api, planes[:], circuit.Params)
+	flippedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.FlippedImage_in, &flippedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.FlippedImage_in)
+}
+
+// flipPlanesH mirrors every plane left to right within the width of params, in place, when params Flip, exactly
+// like image.I.FlipH does outside the circuit.
+//
+// The mirror of a row depends on the width of the image, but pixel locations inside a circuit must be
+// constants. So the whole row is mirrored instead, which leaves the mirrored image past the right edge of its
+// width, and is then translated back to the left edge by the Width minus the width, like cropPlanes translates
+// a crop. The columns past the width must be black, or the translation would bring them into the image.
+func flipPlanesH(api frontend.API, planes []channelPlane, params FlipParams) {
+	comparator := newLocationComparator(api)
+	api.AssertIsBoolean(params.Flip)
+	comparator.AssertIsLessEq(1, params.Size)
+	comparator.AssertIsLessEq(params.Size, myImage.Width)
+
+	var outside [myImage.Width]frontend.Variable
+	for x := range outside {
+		outside[x] = api.Sub(1, comparator.IsLess(x, params.Size))
+	}
+	isOffset := offsetIndicators(api, api.Mul(params.Flip, api.Sub(myImage.Width, params.Size)), myImage.Width)
+
+	terms := make([]frontend.Variable, 0, myImage.Width) // scratch space shared by every shiftRow
+	for c := range planes {
+		plane := &planes[c]
+		for y := 0; y < myImage.Height; y++ {
+			// row[x] = plane[y][Width-1-x] when flipping, plane[y][x] when not
+			var row [myImage.Width]frontend.Variable
+			for x := 0; x < myImage.Width; x++ {
+				api.AssertIsEqual(api.Mul(outside[x], plane[y][x]), 0)
+				row[x] = api.Add(plane[y][x], api.Mul(params.Flip, api.Sub(plane[y][myImage.Width-1-x], plane[y][x])))
+			}
+			shiftRow(api, isOffset, row[:], terms)
+			plane[y] = row
+		}
+	}
+}
+
+// FlipHCircuitParams returns the parameters of the FlipHCircuit for img, mirrored when flip is set.
+func FlipHCircuitParams(img myImage.I, flip bool) FlipParams {
+	params := FlipParams{Flip: 0, Size: img.M.Width}
+	if flip {
+		params.Flip = 1
+	}
+	return params
+}
+
+// This circuit is only for FlipV transformations: the signed image is the image FrImage mirrored top to
+// bottom within the height of Params, like image.I.FlipV does, or FrImage itself when Params does not Flip.
+// Public fields: Statement
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, FlippedImage_in, Params
+type FlipVCircuit struct {
+	Statement       frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey       // Key the image is signed with
+	ImageSignature  eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable     // Digest of the signed image, FlippedImage_in
+	PrevImageBytes  frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Metadata        frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable     // MetadataDigest of the previous image
+	Prev            PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage         myImage.FrontendImage // z_in as a FrontendImage
+	FlippedImage_in myImage.FrontendImage // Flipped previous image as a FrontendImage
+	Params          FlipParams            // Flip transformation parameters, Size the height of the image
+}
+
+// Defines the Compliance Predicate of a vertical flip.
+func (circuit *FlipVCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.FrImage)
+	flipPlanesV(api, planes[:], circuit.Params)
+	flippedImage_out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &circuit.FlippedImage_in, &flippedImage_out)
+
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.FlippedImage_in)
+}
+
+// flipPlanesV mirrors every plane top to bottom within the height of params, in place, when params Flip,
+// exactly like image.I.FlipV does outside the circuit: like flipPlanesH, with columns for rows.
+func flipPlanesV(api frontend.API, planes []channelPlane, params FlipParams) {
+	comparator := newLocationComparator(api)
+	api.AssertIsBoolean(params.Flip)
+	comparator.AssertIsLessEq(1, params.Size)
+	comparator.AssertIsLessEq(params.Size, myImage.Height)
+
+	var outside [myImage.Height]frontend.Variable
+	for y := range outside {
+		outside[y] = api.Sub(1, comparator.IsLess(y, params.Size))
+	}
+	isOffset := offsetIndicators(api, api.Mul(params.Flip, api.Sub(myImage.Height, params.Size)), myImage.Height)
+
+	terms := make([]frontend.Variable, 0, myImage.Height) // scratch space shared by every shiftRow
+	for c := range planes {
+		plane := &planes[c]
+		for x := 0; x < myImage.Width; x++ {
+			// column[y] = plane[Height-1-y][x] when flipping, plane[y][x] when not
+			var column [myImage.Height]frontend.Variable
+			for y := 0; y < myImage.Height; y++ {
+				api.AssertIsEqual(api.Mul(outside[y], plane[y][x]), 0)
+				column[y] = api.Add(plane[y][x], api.Mul(params.Flip, api.Sub(plane[myImage.Height-1-y][x], plane[y][x])))
+			}
+			shiftRow(api, isOffset, column[:], terms)
+			for y := 0; y < myImage.Height; y++ {
+				plane[y][x] = column[y]
+			}
+		}
+	}
+}
+
+// FlipVCircuitParams returns the parameters of the FlipVCircuit for img, mirrored when flip is set.
+func FlipVCircuitParams(img myImage.I, flip bool) FlipParams {
+	params := FlipParams{Flip: 0, Size: img.M.Height}
+	if flip {
+		params.Flip = 1
+	}
+	return params
+}
@@ -0,0 +1,121 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts flipPlanesH(In, Params) == Out, without the signature check of the FlipHCircuit.
+type flipHPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Params FlipParams
+}
+
+func (circuit *flipHPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	flipPlanesH(api, planes[:], circuit.Params)
+	out := fromChannelPlanes(&planes)
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			api.AssertIsEqual(out.Pixels[y][x].R, circuit.Out.Pixels[y][x].R)
+			api.AssertIsEqual(out.Pixels[y][x].G, circuit.Out.Pixels[y][x].G)
+			api.AssertIsEqual(out.Pixels[y][x].B, circuit.Out.Pixels[y][x].B)
+		}
+	}
+	return nil
+}
+
+func TestFlipPlanesH(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, width := range []int{myImage.Width, 9, 1} {
+		in, err := myImage.NoiseImage(testSeed).SubImage(myImage.Width-width, 2, myImage.Width-1, 8)
+		assert.NoError(err)
+		out, err := in.FlipH()
+		assert.NoError(err)
+
+		assignment := flipHPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: FlipHCircuitParams(in, true)}
+		assert.NoError(test.IsSolved(&flipHPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "width %d", width)
+
+		// Without a flip, the image is kept
+		kept := flipHPixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Params: FlipHCircuitParams(in, false)}
+		assert.NoError(test.IsSolved(&flipHPixelsCircuit{}, &kept, ecc.BN254.ScalarField()), "width %d", width)
+
+		if width > 1 {
+			kept.Params = FlipHCircuitParams(in, true)
+			assert.Error(test.IsSolved(&flipHPixelsCircuit{}, &kept, ecc.BN254.ScalarField()), "width %d", width)
+		}
+	}
+
+	// The flip is within the width of the image, which cannot hide pixels past it
+	in := myImage.NoiseImage(testSeed)
+	out, err := in.FlipH()
+	assert.NoError(err)
+	for name, params := range map[string]FlipParams{
+		"not boolean": {Flip: 2, Size: myImage.Width},
+		"narrower":    {Flip: 1, Size: myImage.Width - 1},
+		"no width":    {Flip: 1, Size: 0},
+		"wider":       {Flip: 1, Size: myImage.Width + 1},
+	} {
+		assignment := flipHPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: params}
+		assert.Error(test.IsSolved(&flipHPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+}
+
+// Asserts flipPlanesV(In, Params) == Out, without the signature check of the FlipVCircuit.
+type flipVPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Params FlipParams
+}
+
+func (circuit *flipVPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	flipPlanesV(api, planes[:], circuit.Params)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestFlipPlanesV(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, height := range []int{myImage.Height, 7, 1} {
+		in, err := myImage.NoiseImage(testSeed).SubImage(3, myImage.Height-height, 12, myImage.Height-1)
+		assert.NoError(err)
+		out, err := in.FlipV()
+		assert.NoError(err)
+
+		assignment := flipVPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: FlipVCircuitParams(in, true)}
+		assert.NoError(test.IsSolved(&flipVPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "height %d", height)
+
+		// Without a flip, the image is kept
+		kept := flipVPixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Params: FlipVCircuitParams(in, false)}
+		assert.NoError(test.IsSolved(&flipVPixelsCircuit{}, &kept, ecc.BN254.ScalarField()), "height %d", height)
+
+		if height > 1 {
+			kept.Params = FlipVCircuitParams(in, true)
+			assert.Error(test.IsSolved(&flipVPixelsCircuit{}, &kept, ecc.BN254.ScalarField()), "height %d", height)
+		}
+	}
+
+	// The flip is within the height of the image, which cannot hide pixels past it
+	in := myImage.NoiseImage(testSeed)
+	out, err := in.FlipV()
+	assert.NoError(err)
+	for name, params := range map[string]FlipParams{
+		"not boolean": {Flip: 2, Size: myImage.Height},
+		"shorter":     {Flip: 1, Size: myImage.Height - 1},
+		"no height":   {Flip: 1, Size: 0},
+		"taller":      {Flip: 1, Size: myImage.Height + 1},
+	} {
+		assignment := flipVPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: params}
+		assert.Error(test.IsSolved(&flipVPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+}
@@ -0,0 +1,96 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit proves one frame of a video clip: the published Frame is the Area of a picture the camera
+// signed, translated to the top left corner like image.Crop does. It is the DisclosureCircuit with a public
+// Area, so that a verifier can check the same crop was applied to every frame of a clip; a clip that is not
+// cropped has the whole image as its Area.
+//
+// The frame counter of a frame is the capture counter of its picture, the public Nonce: the frames of a clip
+// are consecutive captures, which the clip verifier checks from the Nonces it builds the public witnesses
+// with. The frame is bound through a single public input, its FrameDigest (see RegionDigest).
+//
+// The Capture is packed (see image.I.Pack), 19 field elements instead of one per channel. The signed ImageBytes
+// are its digest, which the circuit recomputes from the packed elements and Metadata, so that the frame is
+// cropped from the pixels the camera signed.
+//
+// Public fields: PublicKey, Nonce, FrameDigest, Area
+// Secret fields: ImageSignature, ImageBytes, Metadata, Capture
+type FrameCircuit struct {
+	PublicKey      eddsa.PublicKey        `gnark:",public"` // the camera's public key
+	Nonce          frontend.Variable      `gnark:",public"` // capture counter of the frame, see image.Statement
+	FrameDigest    frontend.Variable      `gnark:",public"` // RegionDigest of the frame
+	Area           CropParams             `gnark:",public"` // area of the capture every frame of the clip shows
+	ImageSignature eddsa.Signature        // the camera's signature over the capture
+	ImageBytes     frontend.Variable      // Digest of the capture
+	Metadata       frontend.Variable      // MetadataDigest of the capture
+	Capture        myImage.FrontendPacked // capture as a FrontendPacked
+}
+
+// Defines the Compliance Predicate of a frame.
+func (circuit *FrameCircuit) Define(api frontend.API) error {
+	// The frame is the capture, cropped to the Area of the clip
+	capture, err := unpackImage(api, &circuit.Capture)
+	if err != nil {
+		return err
+	}
+	frame := cropFrontendImage(api, &capture, circuit.Area)
+
+	// Channels are packed into few field elements before hashing, which is only unambiguous for 8 bit values
+	channels := regionChannels(&frame)
+	assertChannels(api, channels...)
+	if err := assertPublicDigest(api, circuit.FrameDigest, packChannels(api, channels)...); err != nil {
+		return err
+	}
+
+	// The signed ImageBytes are the digest of the capture
+	if err := assertPackedDigest(api, circuit.ImageBytes, circuit.Metadata, circuit.Capture.Elements[:]); err != nil {
+		return err
+	}
+
+	// Verify the camera's signature over the capture, which has no previous proof
+	return assertSignedStatement(api, circuit.PublicKey, circuit.ImageSignature, circuit.ImageBytes, circuit.Nonce, 0)
+}
+
+// CheckArea returns an error if the area {x0, y0, x1, y1} of params is not within an image, or params has any
+// other key.
+func CheckArea(params map[string]int) error {
+	if err := (Transformation{T: Crop, Params: params}).CheckParams(); err != nil {
+		return fmt.Errorf("invalid area: %w", err)
+	}
+	if params["x0"] < 0 || params["y0"] < 0 || params["x1"] >= myImage.Width || params["y1"] >= myImage.Height || params["x0"] > params["x1"] || params["y0"] > params["y1"] {
+		return fmt.Errorf("invalid area {(%d,%d), (%d,%d)}: expected corners in order, within the image", params["x0"], params["y0"], params["x1"], params["y1"])
+	}
+	return nil
+}
+
+// FrameWitness returns the public witness of a proof that frame shows the area {x0, y0, x1, y1} of params of
+// the picture the camera with publicKey took as capture nonce. The verifier builds it from the published frame
+// itself.
+func FrameWitness(publicKey []byte, nonce *big.Int, frame myImage.I, params map[string]int) (witness.Witness, error) {
+	if err := CheckArea(params); err != nil {
+		return nil, err
+	}
+	if nonce == nil {
+		return nil, fmt.Errorf("missing nonce")
+	}
+
+	var assignment FrameCircuit
+	assignment.PublicKey.Assign(1, publicKey)
+	assignment.Nonce = nonce
+	assignment.FrameDigest = RegionDigest(frame)
+	assignment.Area = Transformation{T: Crop, Params: params}.ToFr().Params
+
+	return frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
@@ -0,0 +1,94 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+func TestFrameCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	capture := myImage.CoordinateImage()
+	signature, err := secretKey.Sign(myImage.Statement(capture.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+
+	area := map[string]int{"x0": 2, "y0": 3, "x1": 10, "y1": 7}
+	frame := myImage.CoordinateImage()
+	assert.NoError(frame.Crop(2, 3, 10, 7))
+
+	valid := func() FrameCircuit {
+		assignment := FrameCircuit{
+			Nonce:       testNonce,
+			FrameDigest: RegionDigest(frame),
+			Area:        CropParams{X0: 2, Y0: 3, X1: 10, Y1: 7},
+			ImageBytes:  capture.Digest(),
+			Metadata:    capture.MetadataDigest(),
+			Capture:     capture.ToFrontendPacked(),
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, signature)
+		return assignment
+	}
+
+	assignment := valid()
+	assert.NoError(test.IsSolved(&FrameCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The frame, the capture and the area are all bound
+	for name, tamper := range map[string]func(*FrameCircuit){
+		"frame":  func(c *FrameCircuit) { c.FrameDigest = RegionDigest(myImage.CoordinateImage()) },
+		"nonce":  func(c *FrameCircuit) { c.Nonce = testNonce + 1 },
+		"area":   func(c *FrameCircuit) { c.Area = CropParams{X0: 3, Y0: 3, X1: 11, Y1: 7} },
+		"size":   func(c *FrameCircuit) { c.Area = CropParams{X0: 2, Y0: 3, X1: 11, Y1: 7} },
+		"signed": func(c *FrameCircuit) { c.Capture.Elements[0] = flipLowBit(capture.Pack()[0]) },
+		"packed": func(c *FrameCircuit) {
+			c.Capture.Elements[0] = new(big.Int).Add(capture.Pack()[0], new(big.Int).Lsh(big.NewInt(1), 8*myImage.PackedChannels))
+		},
+		"metadata": func(c *FrameCircuit) { c.Metadata = 1 },
+	} {
+		assignment := valid()
+		tamper(&assignment)
+		assert.Error(test.IsSolved(&FrameCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// The verifier's public witness is the prover's
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	frameWitness, err := FrameWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), frame, area)
+	assert.NoError(err)
+	got, err := frameWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+
+	for _, params := range []map[string]int{
+		{"x0": 2, "y0": 3, "x1": 10},
+		{"x0": 10, "y0": 3, "x1": 2, "y1": 7},
+		{"x0": 0, "y0": 0, "x1": myImage.Width, "y1": 7},
+		{"x0": 0, "y0": 0, "x1": 7, "y1": myImage.Height},
+		{"x0": -1, "y0": 0, "x1": 2, "y1": 7},
+	} {
+		if _, err := FrameWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), frame, params); err == nil {
+			t.Errorf("FrameWitness accepted the area %v", params)
+		}
+	}
+}
+
+// flipLowBit returns element with its lowest bit flipped, e.g. the first channel of a packed image.
+func flipLowBit(element *big.Int) *big.Int {
+	return new(big.Int).Xor(element, big.NewInt(1))
+}
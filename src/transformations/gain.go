@@ -0,0 +1,67 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// Number of bits that holds a channel gain in [0, image.MaxChannelGain].
+const channelGainBits = 10
+
+// This circuit is only for Gain transformations, a white balance or an exposure correction: the signed image is
+// the image FrImage with its R, G and B multiplied by the public Gains, out of 256, like image.I.ApplyGains
+// does. The gains are the diagonal of a color matrix, rounded and clamped like any other, and their bounds are
+// asserted. Equal gains correct the exposure, and gains of 256 keep the image.
+// Public fields: Statement, Gains
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, BalancedImage_in
+type GainCircuit struct {
+	Statement        frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey        eddsa.PublicKey       // Key the image is signed with
+	ImageSignature   eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce            frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash    frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier        frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops             frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes       frontend.Variable     // Digest of the signed image, BalancedImage_in
+	PrevImageBytes   frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Gains            [3]frontend.Variable  `gnark:",public"` // Gains of R, G and B, out of 256, in [0, image.MaxChannelGain]
+	Metadata         frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata     frontend.Variable     // MetadataDigest of the previous image
+	Prev             PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage          myImage.FrontendImage // z_in as a FrontendImage
+	BalancedImage_in myImage.FrontendImage // White balanced previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a white balance or exposure correction, a gain of every channel.
+func (circuit *GainCircuit) Define(api frontend.API) error {
+	// Balance the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	if err := gainPlanes(api, planes[:], circuit.Gains); err != nil {
+		return err
+	}
+	balancedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.BalancedImage_in, &balancedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.BalancedImage_in)
+}
+
+// gainPlanes maps every pixel of the planes by the image.GainMatrix of gains, in place, exactly like
+// image.I.ApplyGains does outside the circuit. It asserts every gain lies within [0, image.MaxChannelGain],
+// which keeps every product within the range of the clampTable.
+func gainPlanes(api frontend.API, planes []channelPlane, gains [3]frontend.Variable) error {
+	rangeChecker := rangecheck.New(api)
+	var matrix [3][3]frontend.Variable
+	for c, gain := range gains {
+		rangeChecker.Check(gain, channelGainBits)
+		rangeChecker.Check(api.Sub(myImage.MaxChannelGain, gain), channelGainBits)
+		for k := range matrix[c] {
+			matrix[c][k] = 0
+		}
+		matrix[c][c] = api.Mul(gain, 1<<(myImage.ColorMatrixBits-channelBits))
+	}
+	return colorMatrixPlanes(api, planes, &matrix)
+}
@@ -0,0 +1,55 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts gainPlanes(In, Gains) == Out, without the signature check of the GainCircuit.
+type gainPixelsCircuit struct {
+	In    myImage.FrontendImage
+	Out   myImage.FrontendImage
+	Gains [3]frontend.Variable
+}
+
+func (circuit *gainPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := gainPlanes(api, planes[:], circuit.Gains); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestGainPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	for _, gains := range [][3]int{{256, 256, 256}, {384, 256, 192}, {0, myImage.MaxChannelGain, 1}} {
+		out, err := in.ApplyGains(gains)
+		assert.NoError(err)
+
+		assignment := gainPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage()}
+		for c, gain := range gains {
+			assignment.Gains[c] = gain
+		}
+		assert.NoError(test.IsSolved(&gainPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "gains %v", gains)
+
+		// The image balanced with another gain is not the balanced image
+		assignment.Gains[1] = gains[1] + 1
+		assert.Error(test.IsSolved(&gainPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "gains %v", gains)
+	}
+
+	// The gains are bounded
+	white := myImage.AllWhiteImage().ToFrontendImage()
+	for _, gains := range [][3]frontend.Variable{{256, 256, myImage.MaxChannelGain + 1}, {-1, 256, 256}} {
+		assignment := gainPixelsCircuit{In: white, Out: white, Gains: gains}
+		assert.Error(test.IsSolved(&gainPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "gains %v", gains)
+	}
+}
@@ -0,0 +1,81 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Gamma transformations: the signed image is the image FrImage with every channel
+// mapped by the image.GammaLUT of the public Gamma, like image.I.GammaCorrect does. A gamma correction is not
+// a polynomial of the channel value, so it is looked up: all the image.Gammas are tabulated in a selectedLUT,
+// and the Gamma selects its table. Black maps to black, so the pixels outside the size of the image stay black
+// without any size parameter. A Gamma of 100 keeps the image.
+// Public fields: Statement, Gamma
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, CorrectedImage_in
+type GammaCircuit struct {
+	Statement         frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey         eddsa.PublicKey       // Key the image is signed with
+	ImageSignature    eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce             frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash     frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier         frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops              frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes        frontend.Variable     // Digest of the signed image, CorrectedImage_in
+	PrevImageBytes    frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Gamma             frontend.Variable     `gnark:",public"` // In hundredths, one of image.Gammas
+	Metadata          frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata      frontend.Variable     // MetadataDigest of the previous image
+	Prev              PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage           myImage.FrontendImage // z_in as a FrontendImage
+	CorrectedImage_in myImage.FrontendImage // Gamma corrected previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a gamma correction.
+func (circuit *GammaCircuit) Define(api frontend.API) error {
+	// Gamma correct the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	if err := gammaCorrectPlanes(api, planes[:], circuit.Gamma); err != nil {
+		return err
+	}
+	correctedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.CorrectedImage_in, &correctedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.CorrectedImage_in)
+}
+
+// gammaCorrectPlanes maps every channel of the planes by the image.GammaLUT of gamma, in place, exactly like
+// image.I.GammaCorrect does outside the circuit. It asserts gamma is one of image.Gammas.
+func gammaCorrectPlanes(api frontend.API, planes []channelPlane, gamma frontend.Variable) error {
+	// The index of the gamma among image.Gammas, which it must be exactly one of
+	luts := make([]*[channelMax + 1]uint8, len(myImage.Gammas))
+	var k, found frontend.Variable = 0, 0
+	for i, g := range myImage.Gammas {
+		lut, err := myImage.GammaLUT(g)
+		if err != nil {
+			return err
+		}
+		luts[i] = lut
+
+		isGamma := api.IsZero(api.Sub(gamma, g))
+		k = api.Add(k, api.Mul(isGamma, i))
+		found = api.Add(found, isGamma)
+	}
+	api.AssertIsEqual(found, 1)
+
+	values := make([]frontend.Variable, 0, len(planes)*myImage.Width*myImage.Height)
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			values = append(values, planes[c][y][:]...)
+		}
+	}
+	corrected := newSelectedLUT(api, luts).apply(k, values...)
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			copy(planes[c][y][:], corrected[(c*myImage.Height+y)*myImage.Width:])
+		}
+	}
+	return nil
+}
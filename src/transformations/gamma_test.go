@@ -0,0 +1,53 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts gammaCorrectPlanes(In, Gamma) == Out, without the signature check of the GammaCircuit.
+type gammaPixelsCircuit struct {
+	In    myImage.FrontendImage
+	Out   myImage.FrontendImage
+	Gamma frontend.Variable
+}
+
+func (circuit *gammaPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := gammaCorrectPlanes(api, planes[:], circuit.Gamma); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestGammaCorrectPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in, err := myImage.NoiseImage(testSeed).SubImage(0, 0, 10, 8)
+	assert.NoError(err)
+	for i, gamma := range myImage.Gammas {
+		out, err := in.GammaCorrect(gamma)
+		assert.NoError(err)
+
+		assignment := gammaPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Gamma: gamma}
+		assert.NoError(test.IsSolved(&gammaPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "gamma %d", gamma)
+
+		// The image corrected with another gamma is not the corrected image
+		assignment.Gamma = myImage.Gammas[(i+1)%len(myImage.Gammas)]
+		assert.Error(test.IsSolved(&gammaPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "gamma %d", gamma)
+	}
+
+	// Only the permissible gammas are tabulated: 0 would map every channel to white, and 101 is close to 100
+	white := myImage.AllWhiteImage().ToFrontendImage()
+	for _, gamma := range []int{0, 101} {
+		assignment := gammaPixelsCircuit{In: white, Out: white, Gamma: gamma}
+		assert.Error(test.IsSolved(&gammaPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "gamma %d", gamma)
+	}
+}
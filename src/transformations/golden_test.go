@@ -0,0 +1,120 @@
+package transformations
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+
+	"src/internal/testutil"
+)
+
+var update = flag.Bool("update", false, "rewrite the files in testdata/ with the current results")
+
+// A short run only compiles the predicates and compares the constraints and public witness of their golden
+// records, because the setup of their keys takes minutes each. An update always records them in full.
+func short() bool {
+	return testing.Short() && !*update
+}
+
+// TestGoldenProofs compiles every compliance predicate, proves it over a fixed, seeded
+// assignment and compares the result against testdata/golden/<circuit>.golden.
+//
+// A difference in the constraint system digest means the circuit changed, which
+// invalidates every proving and verifying key generated for it so far.
+// Groth16 proofs are randomized by gnark on every call, so the proof itself is
+// compared by its serialized size and by the verification result, not byte for byte.
+// The golden proofs of the edits of a single image are recorded by package edits, to keep
+// this package's tests within their timeout.
+func TestGoldenProofs(t *testing.T) {
+	for _, c := range circuitCases(t) {
+		t.Run(c.name, func(t *testing.T) {
+			if c.skip != "" {
+				t.Skip(c.skip)
+			}
+			if c.edit {
+				t.Skip("recorded by package edits")
+			}
+
+			got := goldenRecord(t, c)
+			path := filepath.Join("testdata", "golden", c.name+".golden")
+
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if short() {
+				// The record of a short run stops before the proof, so it only has to start the golden file
+				want = want[:min(len(want), len(got))]
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s changed; if this is intended, run go test -update\n--- got\n%s--- want\n%s", path, got, want)
+			}
+		})
+	}
+}
+
+// Compile, setup, prove and verify a circuit case and return its golden record.
+func goldenRecord(t *testing.T, c circuitCase) []byte {
+	compliance_predicate, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, c.circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret_witness, err := frontend.NewWitness(c.assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitness, err := secret_witness.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitnessBytes, err := publicWitness.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var record bytes.Buffer
+	fmt.Fprintf(&record, "constraints: %d\n", compliance_predicate.GetNbConstraints())
+	fmt.Fprintf(&record, "constraints-sha256: %x\n", testutil.ConstraintsDigest(t, compliance_predicate))
+	fmt.Fprintf(&record, "public-witness: %s\n", hex.EncodeToString(publicWitnessBytes))
+	if short() {
+		return record.Bytes()
+	}
+
+	provingKey, verifyingKey, err := groth16.Setup(compliance_predicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(compliance_predicate, provingKey, secret_witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var proofBytes bytes.Buffer
+	if _, err := proof.WriteTo(&proofBytes); err != nil {
+		t.Fatal(err)
+	}
+
+	verified := groth16.Verify(proof, verifyingKey, publicWitness) == nil
+
+	fmt.Fprintf(&record, "proof-size: %d\n", proofBytes.Len())
+	fmt.Fprintf(&record, "verified: %t\n", verified)
+
+	return record.Bytes()
+}
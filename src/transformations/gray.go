@@ -0,0 +1,93 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit proves that a published grayscale image is the Area of a grayscale capture the camera signed,
+// e.g. a document scan, translated to the top left corner like image.Gray.Crop does; a capture that is not
+// cropped has the whole image as its Area. It is the FrameCircuit for grayscale images: a single channel per
+// pixel, so about a third of the pixel constraints and of the packed elements to hash.
+//
+// The published image is bound through a single public input, its GrayDigest. The signed ImageBytes are the
+// digest of the Capture, which the circuit recomputes from its pixels and Metadata.
+//
+// Public fields: PublicKey, Nonce, ImageDigest, Area
+// Secret fields: ImageSignature, ImageBytes, Metadata, Capture
+type GrayCircuit struct {
+	PublicKey      eddsa.PublicKey      `gnark:",public"` // the camera's public key
+	Nonce          frontend.Variable    `gnark:",public"` // capture counter of the capture, see image.Statement
+	ImageDigest    frontend.Variable    `gnark:",public"` // GrayDigest of the published image
+	Area           CropParams           `gnark:",public"` // area of the capture that is published
+	ImageSignature eddsa.Signature      // the camera's signature over the capture
+	ImageBytes     frontend.Variable    // Digest of the capture
+	Metadata       frontend.Variable    // MetadataDigest of the capture
+	Capture        myImage.FrontendGray // capture as a FrontendGray
+}
+
+// Defines the Compliance Predicate of a grayscale image.
+func (circuit *GrayCircuit) Define(api frontend.API) error {
+	// The published image is the capture, cropped to the Area
+	planes := []channelPlane{circuit.Capture.Pixels}
+	cropPlanes(api, planes, circuit.Area)
+
+	// Luma values are packed into few field elements before hashing, which is only unambiguous for 8 bit values
+	channels := make([]frontend.Variable, 0, myImage.Width*myImage.Height)
+	for y := 0; y < myImage.Height; y++ {
+		channels = append(channels, planes[0][y][:]...)
+	}
+	assertChannels(api, channels...)
+	if err := assertPublicDigest(api, circuit.ImageDigest, packChannels(api, channels)...); err != nil {
+		return err
+	}
+
+	// The signed ImageBytes are the digest of the capture
+	capture := make([]frontend.Variable, 0, myImage.Width*myImage.Height)
+	for y := 0; y < myImage.Height; y++ {
+		capture = append(capture, circuit.Capture.Pixels[y][:]...)
+	}
+	if err := assertImageDigest(api, circuit.ImageBytes, circuit.Metadata, capture, channelBits); err != nil {
+		return err
+	}
+
+	// Verify the camera's signature over the capture, which has no previous proof
+	return assertSignedStatement(api, circuit.PublicKey, circuit.ImageSignature, circuit.ImageBytes, circuit.Nonce, 0)
+}
+
+// GrayDigest returns the digest of a grayscale image: the PublicDigest of its luma values, row by row,
+// packed like RegionDigest packs channels.
+func GrayDigest(gray myImage.Gray) *big.Int {
+	channels := make([]uint8, 0, myImage.Width*myImage.Height)
+	for y := 0; y < myImage.Height; y++ {
+		channels = append(channels, gray.Pixels[y][:]...)
+	}
+	return channelsDigest(channels, channelBits)
+}
+
+// GrayWitness returns the public witness of a proof that gray shows the area {x0, y0, x1, y1} of params of the
+// grayscale capture the camera with publicKey took as capture nonce. The verifier builds it from the published
+// image itself.
+func GrayWitness(publicKey []byte, nonce *big.Int, gray myImage.Gray, params map[string]int) (witness.Witness, error) {
+	if err := CheckArea(params); err != nil {
+		return nil, err
+	}
+	if nonce == nil {
+		return nil, fmt.Errorf("missing nonce")
+	}
+
+	var assignment GrayCircuit
+	assignment.PublicKey.Assign(1, publicKey)
+	assignment.Nonce = nonce
+	assignment.ImageDigest = GrayDigest(gray)
+	assignment.Area = Transformation{T: Crop, Params: params}.ToFr().Params
+
+	return frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
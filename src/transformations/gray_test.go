@@ -0,0 +1,79 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+func TestGrayCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	capture := myImage.CoordinateImage().ToGray()
+	signature, err := secretKey.Sign(myImage.Statement(capture.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+
+	area := map[string]int{"x0": 2, "y0": 3, "x1": 10, "y1": 7}
+	published := myImage.CoordinateImage().ToGray()
+	assert.NoError(published.Crop(2, 3, 10, 7))
+
+	valid := func() GrayCircuit {
+		assignment := GrayCircuit{
+			Nonce:       testNonce,
+			ImageDigest: GrayDigest(published),
+			Area:        CropParams{X0: 2, Y0: 3, X1: 10, Y1: 7},
+			ImageBytes:  capture.Digest(),
+			Metadata:    capture.MetadataDigest(),
+			Capture:     capture.ToFrontendGray(),
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, signature)
+		return assignment
+	}
+
+	assignment := valid()
+	assert.NoError(test.IsSolved(&GrayCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The published image, the capture and the area are all bound
+	for name, tamper := range map[string]func(*GrayCircuit){
+		"image":    func(c *GrayCircuit) { c.ImageDigest = GrayDigest(capture) },
+		"capture":  func(c *GrayCircuit) { c.Capture.Pixels[4][5] = 200 },
+		"luma":     func(c *GrayCircuit) { c.Capture.Pixels[4][5] = 256 },
+		"nonce":    func(c *GrayCircuit) { c.Nonce = testNonce + 1 },
+		"area":     func(c *GrayCircuit) { c.Area = CropParams{X0: 3, Y0: 3, X1: 11, Y1: 7} },
+		"signed":   func(c *GrayCircuit) { c.Capture.Pixels[0][0] = capture.Pixels[0][0] ^ 1 },
+		"metadata": func(c *GrayCircuit) { c.Metadata = 1 },
+	} {
+		assignment := valid()
+		tamper(&assignment)
+		assert.Error(test.IsSolved(&GrayCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// The verifier's public witness is the prover's
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	grayWitness, err := GrayWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), published, area)
+	assert.NoError(err)
+	got, err := grayWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+
+	if _, err := GrayWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), published, map[string]int{"x0": 0, "y0": 0, "x1": myImage.Width, "y1": 7}); err == nil {
+		t.Errorf("GrayWitness accepted an area outside the image")
+	}
+}
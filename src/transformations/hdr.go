@@ -0,0 +1,178 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// Number of bracketed exposures of an HDR merge.
+const HDRExposures = 3
+
+// Largest weight of an exposure in an HDR merge, and the number of bits that holds the sum of the weights.
+const (
+	MaxHDRWeight  = 255
+	hdrWeightBits = channelBits
+	hdrTotalBits  = hdrWeightBits + 2
+)
+
+// This circuit proves that an image is an HDR merge of three bracketed exposures, pictures the camera signed:
+// every channel of the Merged image is the weighted mean of the channel in the exposures,
+//
+//	(Weights[0] * Exposures[0] + Weights[1] * Exposures[1] + Weights[2] * Exposures[2]) / (Weights[0] + Weights[1] + Weights[2])
+//
+// rounded down, like MergeHDR does. The weights are public and bounded by MaxHDRWeight, so the verifier knows
+// how much each capture contributes.
+//
+// Like the DisclosureCircuit, nothing about the exposures is public but the camera's key and their capture
+// counters; the merged image is bound through a single public input, its ImageDigest (see RegionDigest). The
+// Exposures are packed (see image.I.Pack), and the signed ImageBytes are their digests, which the circuit
+// recomputes from the packed elements and Metadata.
+//
+// Public fields: PublicKey, Nonces, ImageDigest, Weights
+// Secret fields: Signatures, ImageBytes, Metadata, Exposures, Merged
+type HDRCircuit struct {
+	PublicKey   eddsa.PublicKey                      `gnark:",public"` // the camera's public key
+	Nonces      [HDRExposures]frontend.Variable      `gnark:",public"` // capture counters of the exposures
+	ImageDigest frontend.Variable                    `gnark:",public"` // RegionDigest of the merged image
+	Weights     [HDRExposures]frontend.Variable      `gnark:",public"` // weight of every exposure, at most MaxHDRWeight
+	Signatures  [HDRExposures]eddsa.Signature        // the camera's signatures over the exposures
+	ImageBytes  [HDRExposures]frontend.Variable      // Digests of the exposures
+	Metadata    [HDRExposures]frontend.Variable      // MetadataDigests of the exposures
+	Exposures   [HDRExposures]myImage.FrontendPacked // exposures as FrontendPackeds
+	Merged      myImage.FrontendImage                // merged image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of an HDR merge.
+func (circuit *HDRCircuit) Define(api frontend.API) error {
+	rangeChecker := rangecheck.New(api)
+
+	// Every weight is bounded, and they do not all vanish
+	var total frontend.Variable = 0
+	for _, weight := range circuit.Weights {
+		rangeChecker.Check(weight, hdrWeightBits)
+		total = api.Add(total, weight)
+	}
+	api.AssertIsDifferent(total, 0)
+
+	// Every channel is the weighted mean of the exposures, rounded down: the remainder of the weighted sum is
+	// in [0, total)
+	var exposures [HDRExposures][3]channelPlane
+	for i := range circuit.Exposures {
+		exposure, err := unpackImage(api, &circuit.Exposures[i])
+		if err != nil {
+			return err
+		}
+		exposures[i] = channelPlanes(&exposure)
+	}
+	merged := channelPlanes(&circuit.Merged)
+	for c := range merged {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				var sum frontend.Variable = 0
+				for i := range exposures {
+					sum = api.Add(sum, api.Mul(circuit.Weights[i], exposures[i][c][y][x]))
+				}
+				remainder := api.Sub(sum, api.Mul(merged[c][y][x], total))
+				rangeChecker.Check(remainder, hdrTotalBits)
+				rangeChecker.Check(api.Sub(total, remainder, 1), hdrTotalBits)
+			}
+		}
+	}
+
+	// Channels are packed into few field elements before hashing, which is only unambiguous for 8 bit values
+	channels := regionChannels(&circuit.Merged)
+	assertChannels(api, channels...)
+	if err := assertPublicDigest(api, circuit.ImageDigest, packChannels(api, channels)...); err != nil {
+		return err
+	}
+
+	// Different captures, all original images signed by the camera
+	for i := range circuit.Nonces {
+		for j := i + 1; j < len(circuit.Nonces); j++ {
+			api.AssertIsDifferent(circuit.Nonces[i], circuit.Nonces[j])
+		}
+		if err := assertPackedDigest(api, circuit.ImageBytes[i], circuit.Metadata[i], circuit.Exposures[i].Elements[:]); err != nil {
+			return err
+		}
+		if err := assertSignedStatement(api, circuit.PublicKey, circuit.Signatures[i], circuit.ImageBytes[i], circuit.Nonces[i], 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckHDRWeights returns an error if exposures cannot be merged with weights.
+func CheckHDRWeights(weights [HDRExposures]int) error {
+	total := 0
+	for _, weight := range weights {
+		if weight < 0 || weight > MaxHDRWeight {
+			return fmt.Errorf("invalid HDR weight %d: expected a value in [0, %d]", weight, MaxHDRWeight)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("invalid HDR weights %v: at least one exposure must be weighted", weights)
+	}
+	return nil
+}
+
+// MergeHDR returns the HDR merge of exposures with weights, as the HDRCircuit merges them. Its metadata only
+// holds its width and height.
+func MergeHDR(exposures [HDRExposures]myImage.I, weights [HDRExposures]int) (myImage.I, error) {
+	if err := CheckHDRWeights(weights); err != nil {
+		return myImage.I{}, err
+	}
+
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+	merge := func(channel func(pixel myImage.RGBPixel) uint8, y, x int) uint8 {
+		sum := 0
+		for i, exposure := range exposures {
+			sum += weights[i] * int(channel(exposure.Pixels[y][x]))
+		}
+		return uint8(sum / total)
+	}
+
+	merged := myImage.I{M: myImage.Metadata{Width: myImage.Width, Height: myImage.Height}}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			merged.Pixels[y][x] = myImage.RGBPixel{
+				R: merge(func(pixel myImage.RGBPixel) uint8 { return pixel.R }, y, x),
+				G: merge(func(pixel myImage.RGBPixel) uint8 { return pixel.G }, y, x),
+				B: merge(func(pixel myImage.RGBPixel) uint8 { return pixel.B }, y, x),
+			}
+		}
+	}
+	return merged, nil
+}
+
+// HDRWitness returns the public witness of a proof that merged is the HDR merge, with weights, of the captures
+// nonces of the camera with publicKey. The verifier builds it from the published image itself.
+func HDRWitness(publicKey []byte, nonces [HDRExposures]*big.Int, merged myImage.I, weights [HDRExposures]int) (witness.Witness, error) {
+	if err := CheckHDRWeights(weights); err != nil {
+		return nil, err
+	}
+
+	var assignment HDRCircuit
+	assignment.PublicKey.Assign(1, publicKey)
+	for i := range nonces {
+		if nonces[i] == nil {
+			return nil, fmt.Errorf("missing nonce")
+		}
+		assignment.Nonces[i] = nonces[i]
+		assignment.Weights[i] = weights[i]
+	}
+	assignment.ImageDigest = RegionDigest(merged)
+
+	return frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
@@ -0,0 +1,137 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// Three exposures of the pattern: darker, as is, and brighter.
+func testExposures() [HDRExposures]myImage.I {
+	var exposures [HDRExposures]myImage.I
+	for i := range exposures {
+		exposures[i] = myImage.CoordinateImage()
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				pixel := exposures[i].Pixels[y][x]
+				scale := func(channel uint8) uint8 { return uint8(min(int(channel)*(i+1)/2, 255)) }
+				exposures[i].SetPixel(x, y, myImage.RGBPixel{R: scale(pixel.R), G: scale(pixel.G), B: scale(pixel.B)})
+			}
+		}
+	}
+	return exposures
+}
+
+func TestMergeHDR(t *testing.T) {
+	exposures := testExposures()
+
+	merged, err := MergeHDR(exposures, [HDRExposures]int{1, 2, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pixel := [HDRExposures]uint8{exposures[0].Pixels[3][5].B, exposures[1].Pixels[3][5].B, exposures[2].Pixels[3][5].B}
+	if want := uint8((int(pixel[0]) + 2*int(pixel[1]) + int(pixel[2])) / 4); merged.Pixels[3][5].B != want {
+		t.Errorf("merged blue is %d, expected %d", merged.Pixels[3][5].B, want)
+	}
+
+	// A single weighted exposure is kept as is
+	merged, err = MergeHDR(exposures, [HDRExposures]int{0, 7, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Pixels != exposures[1].Pixels {
+		t.Error("a single weighted exposure changed in the merge")
+	}
+
+	for _, weights := range [][HDRExposures]int{{0, 0, 0}, {-1, 1, 1}, {MaxHDRWeight + 1, 1, 1}} {
+		if _, err := MergeHDR(exposures, weights); err == nil {
+			t.Errorf("weights %v were accepted", weights)
+		}
+	}
+}
+
+func TestHDRCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	exposures := testExposures()
+	var nonces [HDRExposures]*big.Int
+	var signatures [HDRExposures][]byte
+	for i, exposure := range exposures {
+		nonces[i] = big.NewInt(testNonce + int64(i))
+		signatures[i], err = secretKey.Sign(myImage.Statement(exposure.Digest(), nonces[i], big.NewInt(0)), hashsuite.Default.New())
+		assert.NoError(err)
+	}
+
+	valid := func(weights [HDRExposures]int) HDRCircuit {
+		merged, err := MergeHDR(exposures, weights)
+		assert.NoError(err)
+		assignment := HDRCircuit{ImageDigest: RegionDigest(merged), Merged: merged.ToFrontendImage()}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		for i := range exposures {
+			assignment.Nonces[i] = nonces[i]
+			assignment.Weights[i] = weights[i]
+			assignment.Signatures[i].Assign(1, signatures[i])
+			assignment.ImageBytes[i] = exposures[i].Digest()
+			assignment.Metadata[i] = exposures[i].MetadataDigest()
+			assignment.Exposures[i] = exposures[i].ToFrontendPacked()
+		}
+		return assignment
+	}
+
+	for _, weights := range [][HDRExposures]int{{1, 2, 1}, {0, 1, 0}, {MaxHDRWeight, MaxHDRWeight, MaxHDRWeight}, {3, 0, 250}} {
+		assignment := valid(weights)
+		assert.NoError(test.IsSolved(&HDRCircuit{}, &assignment, ecc.BN254.ScalarField()), "%v", weights)
+	}
+
+	// The merged image, the weights and the captures are all bound
+	other, err := MergeHDR(exposures, [HDRExposures]int{1, 1, 1})
+	assert.NoError(err)
+	for name, tamper := range map[string]func(*HDRCircuit){
+		"digest":   func(c *HDRCircuit) { c.ImageDigest = RegionDigest(other) },
+		"pixels":   func(c *HDRCircuit) { c.Merged = other.ToFrontendImage() },
+		"rounded":  func(c *HDRCircuit) { c.Merged.Pixels[4][4].G = 0 },
+		"weight":   func(c *HDRCircuit) { c.Weights[0] = 2 },
+		"nonce":    func(c *HDRCircuit) { c.Nonces[2] = testNonce + 3 },
+		"twice":    func(c *HDRCircuit) { c.Nonces[2] = testNonce },
+		"metadata": func(c *HDRCircuit) { c.Metadata[1] = 1 },
+	} {
+		assignment := valid([HDRExposures]int{1, 2, 1})
+		tamper(&assignment)
+		assert.Error(test.IsSolved(&HDRCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// Out of bounds weights cannot be proven
+	assignment := valid([HDRExposures]int{1, 2, 1})
+	assignment.Weights = [HDRExposures]frontend.Variable{0, 0, 0}
+	assert.Error(test.IsSolved(&HDRCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	assignment = valid([HDRExposures]int{0, 1, 0})
+	assignment.Weights = [HDRExposures]frontend.Variable{0, MaxHDRWeight + 1, 0}
+	assert.Error(test.IsSolved(&HDRCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The verifier's public witness is the prover's
+	assignment = valid([HDRExposures]int{1, 2, 1})
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	merged, err := MergeHDR(exposures, [HDRExposures]int{1, 2, 1})
+	assert.NoError(err)
+	hdrWitness, err := HDRWitness(secretKey.Public().Bytes(), nonces, merged, [HDRExposures]int{1, 2, 1})
+	assert.NoError(err)
+	got, err := hdrWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+}
@@ -0,0 +1,24 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+// MaxHops is the most edits an image may undergo after its original: the compliance predicates that carry the
+// hop counter prove Hops of at most MaxHops, and an edit increments the Hops of the proof it was made from, see
+// prover.Prover. A verifier of an edit history checks that every proof is one hop past the one before it, see
+// verifier.VerifyChain, so a longer history cannot be proven.
+const MaxHops = 16
+
+// Bits of the hop counter, which hold MaxHops.
+const hopBits = 5
+
+// assertHops asserts that hops, the Hops of the proof, is at most MaxHops, and 0 for an original image, i.e.
+// when prevProofHash is 0.
+func assertHops(api frontend.API, hops frontend.Variable, prevProofHash frontend.Variable) {
+	rangeChecker := rangecheck.New(api)
+	rangeChecker.Check(hops, hopBits)
+	rangeChecker.Check(api.Sub(MaxHops, hops), hopBits)
+	api.AssertIsEqual(api.Mul(api.IsZero(prevProofHash), hops), 0)
+}
@@ -0,0 +1,65 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// An original image is proven with no hops, and an edit with at most MaxHops.
+func TestHops(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	nullifier := testNullifier(t)
+
+	img := myImage.AllWhiteImage()
+	cameraKey, cameraSignature := signedTestImage(t, img)
+	crop := func(prevProofHash int64, hops interface{}) CropCircuit {
+		sig, err := secretKey.Sign(myImage.Statement(img.Digest(), big.NewInt(testNonce), big.NewInt(prevProofHash)), hashsuite.Default.New())
+		assert.NoError(err)
+		assignment := CropCircuit{
+			Nonce:           testNonce,
+			PrevProofHash:   prevProofHash,
+			Nullifier:       nullifier,
+			Hops:            hops,
+			ImageBytes:      img.Digest(),
+			PrevImageBytes:  img.Digest(),
+			Metadata:        img.MetadataDigest(),
+			PrevMetadata:    img.MetadataDigest(),
+			Prev:            PrevSignature{PublicKey: cameraKey, Signature: cameraSignature, PrevProofHash: 0},
+			FrImage:         img.ToFrontendImage(),
+			CroppedImage_in: img.ToFrontendImage(),
+			Params:          CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, sig)
+		assignment.Statement = testStatement(t, assignment.PublicKey, assignment.ImageSignature, assignment.Nonce, assignment.PrevProofHash, assignment.Nullifier, assignment.Hops, assignment.ImageBytes, assignment.PrevImageBytes, assignment.Prev.PublicKey)
+		return assignment
+	}
+
+	// An original image
+	assignment := crop(0, 0)
+	assert.NoError(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	assignment = crop(0, 1)
+	assert.Error(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// An edit
+	for _, hops := range []int{1, MaxHops} {
+		assignment := crop(1, hops)
+		assert.NoError(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	}
+	minusOne := new(big.Int).Sub(ecc.BN254.ScalarField(), big.NewInt(1))
+	for _, hops := range []interface{}{MaxHops + 1, 1 << hopBits, minusOne} {
+		assignment := crop(1, hops)
+		assert.Error(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	}
+}
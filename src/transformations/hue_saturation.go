@@ -0,0 +1,100 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// Number of bits that holds a saturation in [0, image.MaxSaturation].
+const saturationBits = 10
+
+// This circuit is only for HueSaturation transformations: the signed image is the image FrImage with its hue
+// rotated by the public Hue, one of image.Hues, and its saturation scaled by the public Saturation, like
+// image.I.AdjustHueSaturation does. Both are color matrices of the public values: the HueMatrix of every
+// permissible hue is a constant, selected by the Hue, and the SaturationMatrix is linear in the Saturation,
+// whose bounds are asserted. A Hue of 0 and a Saturation of 256 keep the image.
+// Public fields: Statement, Hue, Saturation
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, AdjustedImage_in
+type HueSaturationCircuit struct {
+	Statement        frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey        eddsa.PublicKey       // Key the image is signed with
+	ImageSignature   eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce            frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash    frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier        frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops             frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes       frontend.Variable     // Digest of the signed image, AdjustedImage_in
+	PrevImageBytes   frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Hue              frontend.Variable     `gnark:",public"` // In degrees, one of image.Hues
+	Saturation       frontend.Variable     `gnark:",public"` // Out of 256, in [0, image.MaxSaturation]
+	Metadata         frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata     frontend.Variable     // MetadataDigest of the previous image
+	Prev             PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage          myImage.FrontendImage // z_in as a FrontendImage
+	AdjustedImage_in myImage.FrontendImage // Adjusted previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a hue and saturation adjustment.
+func (circuit *HueSaturationCircuit) Define(api frontend.API) error {
+	// Adjust the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	if err := hueSaturationPlanes(api, planes[:], circuit.Hue, circuit.Saturation); err != nil {
+		return err
+	}
+	adjustedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.AdjustedImage_in, &adjustedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.AdjustedImage_in)
+}
+
+// hueSaturationPlanes maps every pixel of the planes by the image.HueMatrix of hue and then by the
+// image.SaturationMatrix of saturation, in place, exactly like image.I.AdjustHueSaturation does outside the
+// circuit. It asserts hue is one of image.Hues and saturation lies within [0, image.MaxSaturation].
+func hueSaturationPlanes(api frontend.API, planes []channelPlane, hue, saturation frontend.Variable) error {
+	// The HueMatrix of the permissible hue that hue is
+	isHue := valueIndicators(api, hue, myImage.Hues[:])
+	matrices := make([]myImage.ColorMatrix, len(myImage.Hues))
+	for i, h := range myImage.Hues {
+		var err error
+		if matrices[i], err = myImage.HueMatrix(h); err != nil {
+			return err
+		}
+	}
+	var hueMatrix [3][3]frontend.Variable
+	for c := range hueMatrix {
+		for k := range hueMatrix[c] {
+			var weight frontend.Variable = 0
+			for i := range matrices {
+				weight = api.Add(weight, api.Mul(isHue[i], matrices[i][c][k]))
+			}
+			hueMatrix[c][k] = weight
+		}
+	}
+	if err := colorMatrixPlanes(api, planes, &hueMatrix); err != nil {
+		return err
+	}
+
+	// The SaturationMatrix is linear in the saturation: the matrix of 0, plus saturation times the change of 1
+	rangeChecker := rangecheck.New(api)
+	rangeChecker.Check(saturation, saturationBits)
+	rangeChecker.Check(api.Sub(myImage.MaxSaturation, saturation), saturationBits)
+	gray, err := myImage.SaturationMatrix(0)
+	if err != nil {
+		return err
+	}
+	step, err := myImage.SaturationMatrix(1)
+	if err != nil {
+		return err
+	}
+	var saturationMatrix [3][3]frontend.Variable
+	for c := range saturationMatrix {
+		for k := range saturationMatrix[c] {
+			saturationMatrix[c][k] = api.Add(gray[c][k], api.Mul(saturation, step[c][k]-gray[c][k]))
+		}
+	}
+	return colorMatrixPlanes(api, planes, &saturationMatrix)
+}
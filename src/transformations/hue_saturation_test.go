@@ -0,0 +1,60 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts hueSaturationPlanes(In, Hue, Saturation) == Out, without the signature check of the
+// HueSaturationCircuit.
+type hueSaturationPixelsCircuit struct {
+	In         myImage.FrontendImage
+	Out        myImage.FrontendImage
+	Hue        frontend.Variable
+	Saturation frontend.Variable
+}
+
+func (circuit *hueSaturationPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := hueSaturationPlanes(api, planes[:], circuit.Hue, circuit.Saturation); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestHueSaturationPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	for _, hue := range myImage.Hues {
+		for _, saturation := range []int{0, 256, myImage.MaxSaturation} {
+			out, err := in.AdjustHueSaturation(hue, saturation)
+			assert.NoError(err)
+
+			assignment := hueSaturationPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Hue: hue, Saturation: saturation}
+			assert.NoError(test.IsSolved(&hueSaturationPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "hue %d, saturation %d", hue, saturation)
+		}
+	}
+
+	// The image adjusted by other values is not the adjusted image
+	out, err := in.AdjustHueSaturation(60, 200)
+	assert.NoError(err)
+	for _, c := range []struct{ hue, saturation int }{{90, 200}, {60, 201}} {
+		assignment := hueSaturationPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Hue: c.hue, Saturation: c.saturation}
+		assert.Error(test.IsSolved(&hueSaturationPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "hue %d, saturation %d", c.hue, c.saturation)
+	}
+
+	// Only the permissible hues and saturations
+	white := myImage.AllWhiteImage().ToFrontendImage()
+	for _, c := range []struct{ hue, saturation int }{{45, 256}, {360, 256}, {0, -1}, {0, myImage.MaxSaturation + 1}} {
+		assignment := hueSaturationPixelsCircuit{In: white, Out: white, Hue: c.hue, Saturation: c.saturation}
+		assert.Error(test.IsSolved(&hueSaturationPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "hue %d, saturation %d", c.hue, c.saturation)
+	}
+}
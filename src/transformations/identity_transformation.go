@@ -2,42 +2,54 @@ package transformations
 
 import (
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/std/algebra/native/twistededwards"
-	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
 )
 
-// This circuit is only for Identity transformations.
-// Public fields: PublicKey, ImageSignature
-// Secret fields: ImageBytes
+// This circuit is only for Identity transformations. The signed ImageBytes are recomputed from the pixels of
+// the FrImage, like the CropCircuit does, so the proof is about the pixels the camera signed.
+// Public fields: Statement
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, FrImage
 type IdentityCircuit struct {
-	PublicKey           eddsa.PublicKey   `gnark:",public"`
-	ImageSignature      eddsa.Signature   `gnark:",public"` // Digital signature as eddsa.Signature
-	Original_ImageBytes frontend.Variable // Original image as Big Endian
+	Statement      frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey      eddsa.PublicKey       // Key the image is signed with
+	ImageSignature eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce          frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash  frontend.Variable     // Always 0: the original image has no previous proof
+	Nullifier      frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops           frontend.Variable     // Always 0: the original image is no edit
+	ImageBytes     frontend.Variable     // Digest of the original image, FrImage
+	PrevImageBytes frontend.Variable     // Always ImageBytes: the original image is its own previous image
+	Metadata       frontend.Variable     // MetadataDigest of the original image
+	FrImage        myImage.FrontendImage // Original image as a FrontendImage
 }
 
 // Defines the Compliance Predicate for the IdentityCircuit, which is used to enforce Identity tranformations only,
 // in this case. This function utilizes the frontend.API to verify the circuit's ImageSignature inside the
 // Compliance Predicate, so secret fields remain secret when creating proofs or verifyin proofs.
 func (circuit *IdentityCircuit) Define(api frontend.API) error {
-	// Set the twisted edwards curve
-	curve, err := twistededwards.NewEdCurve(api, 1)
-	if err != nil {
+	// The original image starts the edit history
+	api.AssertIsEqual(circuit.PrevProofHash, 0)
+	api.AssertIsEqual(circuit.Hops, 0)
+	api.AssertIsEqual(circuit.PrevImageBytes, circuit.ImageBytes)
+
+	// The original image is signed by the camera, whose key and capture counter make the Nullifier
+	if err := assertNullifier(api, circuit.Nullifier, circuit.PublicKey, circuit.Nonce, circuit.PrevProofHash); err != nil {
 		return err
 	}
 
-	// Get the hash function that can be used in verifying signatures inside a Gnark ZKP-circuit.
-	// i.e. without revealing secret fields in the circuit.
-	mimc, err := mimc.NewMiMC(api)
-	if err != nil {
+	// The signed ImageBytes are the digest of the original image
+	if err := assertImageDigest(api, circuit.ImageBytes, circuit.Metadata, regionChannels(&circuit.FrImage), channelBits); err != nil {
 		return err
 	}
 
-	// Verify the circuit's ImageSignature using a ZKP-circuit function for EdDSA signatures.
-	// This involves using the same hash function MiMC(ImageBytes + public key) to generate a secondary
-	// signature, and then verifying if the signatures match. This is done in a ZKP-circuit so the secret
-	// fields are not revealed.
-	eddsa.Verify(curve, circuit.ImageSignature, circuit.Original_ImageBytes, circuit.PublicKey, &mimc)
+	// Verify the ImageSignature over the statement of ImageBytes, the Nonce and the PrevProofHash
+	if err := assertSignedStatement(api, circuit.PublicKey, circuit.ImageSignature, circuit.ImageBytes, circuit.Nonce, circuit.PrevProofHash); err != nil {
+		return err
+	}
 
-	return nil
+	// The public Statement is the digest of the secret values of the statement, the original image signed by the
+	// key of its own PrevSignature
+	return assertStatementDigest(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.PrevImageBytes, circuit.PublicKey)
 }
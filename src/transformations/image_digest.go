@@ -0,0 +1,97 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+func init() {
+	solver.RegisterHint(unpackHint)
+}
+
+// assertImageDigest asserts that imageBytes, the message the camera signed, is the digest of an image with
+// channels of bits each and metadata, its MetadataDigest, as image.I.Digest computes it outside the circuit.
+// This ties a signature to the pixels a compliance predicate is about: the prover cannot prove an edit of
+// other pixels than the ones the camera signed. Channels are range checked to bits, without which their
+// packing would be ambiguous.
+func assertImageDigest(api frontend.API, imageBytes frontend.Variable, metadata frontend.Variable, channels []frontend.Variable, bits int) error {
+	rangeChecker := rangecheck.New(api)
+	for _, channel := range channels {
+		rangeChecker.Check(channel, bits)
+	}
+	return assertPackedDigest(api, imageBytes, metadata, packBits(api, channels, bits))
+}
+
+// assertPackedDigest asserts that imageBytes is the digest of an image whose channels are packed, e.g. a
+// FrontendPacked unpacked by unpackImage, which range checks them, and metadata.
+func assertPackedDigest(api frontend.API, imageBytes frontend.Variable, metadata frontend.Variable, packed []frontend.Variable) error {
+	h, err := hashsuite.Default.NewGadget(api)
+	if err != nil {
+		return err
+	}
+	h.Write(packed...)
+	h.Write(metadata)
+	api.AssertIsEqual(h.Sum(), imageBytes)
+	return nil
+}
+
+// unpackImage returns the image packed in a FrontendPacked, see image.I.Pack. The channels are computed by a
+// hint, range checked to 8 bits and packed again into the elements, so they are the only channels that pack
+// to them: unpacking costs a range check per channel, like assertImageDigest, and no more.
+func unpackImage(api frontend.API, packed *myImage.FrontendPacked) (myImage.FrontendImage, error) {
+	channels, err := unpackBits(api, packed.Elements[:], channelBits, 3*myImage.Width*myImage.Height)
+	if err != nil {
+		return myImage.FrontendImage{}, err
+	}
+	var img myImage.FrontendImage
+	for i := 0; i < myImage.Width*myImage.Height; i++ {
+		img.Pixels[i/myImage.Width][i%myImage.Width] = myImage.FrontendPixel{R: channels[3*i], G: channels[3*i+1], B: channels[3*i+2]}
+	}
+	return img, nil
+}
+
+// unpackBits unpacks n values of bits each from packed elements, as packBits packs them.
+func unpackBits(api frontend.API, packed []frontend.Variable, bits, n int) ([]frontend.Variable, error) {
+	values, err := api.Compiler().NewHint(unpackHint, n, append([]frontend.Variable{bits}, packed...)...)
+	if err != nil {
+		return nil, err
+	}
+	rangeChecker := rangecheck.New(api)
+	for _, value := range values {
+		rangeChecker.Check(value, bits)
+	}
+	repacked := packBits(api, values, bits)
+	if len(repacked) != len(packed) {
+		return nil, fmt.Errorf("%d packed elements for %d values of %d bits, expected %d", len(packed), n, bits, len(repacked))
+	}
+	for i := range packed {
+		api.AssertIsEqual(repacked[i], packed[i])
+	}
+	return values, nil
+}
+
+// unpackHint computes the values of unpackBits: its inputs are the number of bits of a value, then the packed
+// elements.
+func unpackHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) < 1 {
+		return fmt.Errorf("unpackHint: missing the number of bits")
+	}
+	bits := int(inputs[0].Int64())
+	perElement := packedBits / bits
+	if (len(outputs)+perElement-1)/perElement != len(inputs)-1 {
+		return fmt.Errorf("unpackHint: %d packed elements for %d values of %d bits", len(inputs)-1, len(outputs), bits)
+	}
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+	for i := range outputs {
+		outputs[i].Rsh(inputs[1+i/perElement], uint(bits*(i%perElement)))
+		outputs[i].And(outputs[i], mask)
+	}
+	return nil
+}
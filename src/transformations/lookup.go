@@ -0,0 +1,176 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/rangecheck"
+
+	myImage "src/image"
+)
+
+// Per-pixel arithmetic in the compliance predicates, e.g. brightness, gamma or tone curves, maps
+// every channel value through a function. Building that function out of api.Select/api.Cmp trees costs
+// hundreds of constraints per channel value. A log-derivative lookup argument (std/lookup/logderivlookup)
+// costs one constraint per table entry, shared by the whole image, and a few per lookup.
+//
+// A lookup panics while solving when its index is outside the table, so every index is range checked
+// first, which turns a bad index into an unsatisfied constraint. Range checks (std/rangecheck) are
+// themselves collected into a single log-derivative argument per circuit.
+
+const (
+	channelBits = 8                  // Bits of a color channel value
+	channelMax  = 1<<channelBits - 1 // Largest color channel value
+
+	// A clampTable accepts values in [-clampOffset, 2^clampBits - clampOffset), which covers a channel
+	// value plus or minus another channel value, e.g. a pixel after a brightness adjustment.
+	clampOffset = 1 << channelBits
+	clampBits   = channelBits + 2
+
+	// An absTable accepts values in [-absOffset, absOffset), which covers the difference of two channel values.
+	absOffset = 1 << channelBits
+	absBits   = channelBits + 1
+)
+
+// assertChannels asserts that every value is a color channel value, i.e. in [0, 255].
+func assertChannels(api frontend.API, values ...frontend.Variable) {
+	rangeChecker := rangecheck.New(api)
+	for _, value := range values {
+		rangeChecker.Check(value, channelBits)
+	}
+}
+
+// assertPixels asserts that every channel of every pixel of img is a color channel value. A witness of an
+// image is any field element otherwise, which the gadgets of the predicates, e.g. the lookup tables, do not all
+// check, and which an edit can drop from the signed image, e.g. the pixels a crop or a redaction removes.
+func assertPixels(api frontend.API, img *myImage.FrontendImage) {
+	assertChannels(api, regionChannels(img)...)
+}
+
+// A channelLUT maps every color channel value to a new one, e.g. along a tone curve or gamma correction.
+type channelLUT struct {
+	api   frontend.API
+	table *logderivlookup.Table
+}
+
+// newChannelLUT returns a channelLUT that maps a channel value v to lut[v].
+func newChannelLUT(api frontend.API, lut *[channelMax + 1]uint8) channelLUT {
+	table := logderivlookup.New(api)
+	for _, value := range lut {
+		table.Insert(value)
+	}
+	return channelLUT{api: api, table: table}
+}
+
+// apply returns lut[v] for every value v, and asserts every v is a channel value.
+func (lut channelLUT) apply(values ...frontend.Variable) []frontend.Variable {
+	assertChannels(lut.api, values...)
+	return lut.table.Lookup(values...)
+}
+
+// A clampTable clamps values to color channel values: below 0 becomes 0 and above 255 becomes 255.
+type clampTable struct {
+	api   frontend.API
+	table *logderivlookup.Table
+}
+
+// newClampTable returns a clampTable for values in [-clampOffset, 2^clampBits - clampOffset).
+func newClampTable(api frontend.API) clampTable {
+	table := logderivlookup.New(api)
+	for i := 0; i < 1<<clampBits; i++ {
+		table.Insert(min(max(i-clampOffset, 0), channelMax))
+	}
+	return clampTable{api: api, table: table}
+}
+
+// clamp returns every value clamped to [0, 255], and asserts every value is within the range of the table.
+func (clamp clampTable) clamp(values ...frontend.Variable) []frontend.Variable {
+	rangeChecker := rangecheck.New(clamp.api)
+	indices := make([]frontend.Variable, len(values))
+	for i, value := range values {
+		indices[i] = clamp.api.Add(value, clampOffset)
+		rangeChecker.Check(indices[i], clampBits)
+	}
+	return clamp.table.Lookup(indices...)
+}
+
+// An absTable returns the absolute value of the difference of two channel values, e.g. to measure the
+// distance between two images.
+type absTable struct {
+	api   frontend.API
+	table *logderivlookup.Table
+}
+
+// newAbsTable returns an absTable for values in [-absOffset, absOffset).
+func newAbsTable(api frontend.API) absTable {
+	table := logderivlookup.New(api)
+	for i := 0; i < 1<<absBits; i++ {
+		table.Insert(max(i-absOffset, absOffset-i))
+	}
+	return absTable{api: api, table: table}
+}
+
+// abs returns the absolute value of every value, and asserts every value is within the range of the table.
+func (abs absTable) abs(values ...frontend.Variable) []frontend.Variable {
+	rangeChecker := rangecheck.New(abs.api)
+	indices := make([]frontend.Variable, len(values))
+	for i, value := range values {
+		indices[i] = abs.api.Add(value, absOffset)
+		rangeChecker.Check(indices[i], absBits)
+	}
+	return abs.table.Lookup(indices...)
+}
+
+// A gammaTable applies the gamma curve of the DevelopCircuit to white balanced RAW values, see gammaCurve.
+type gammaTable struct {
+	api   frontend.API
+	table *logderivlookup.Table
+}
+
+// newGammaTable returns a gammaTable for values in [0, 2^balancedBits).
+func newGammaTable(api frontend.API) gammaTable {
+	table := logderivlookup.New(api)
+	for i := 0; i < 1<<balancedBits; i++ {
+		table.Insert(gammaCurve(i))
+	}
+	return gammaTable{api: api, table: table}
+}
+
+// gamma returns gammaCurve(v) for every value v, and asserts every v is within the range of the table.
+func (gamma gammaTable) gamma(values ...frontend.Variable) []frontend.Variable {
+	rangeChecker := rangecheck.New(gamma.api)
+	for _, value := range values {
+		rangeChecker.Check(value, balancedBits)
+	}
+	return gamma.table.Lookup(values...)
+}
+
+// A selectedLUT maps every color channel value with one of several lookup tables, selected in the circuit, e.g.
+// by the public Gamma of a GammaCircuit. The tables are concatenated into a single one, in which table k maps
+// v at k * 256 + v.
+type selectedLUT struct {
+	api   frontend.API
+	table *logderivlookup.Table
+}
+
+// newSelectedLUT returns a selectedLUT that maps a channel value v to luts[k][v].
+func newSelectedLUT(api frontend.API, luts []*[channelMax + 1]uint8) selectedLUT {
+	table := logderivlookup.New(api)
+	for _, lut := range luts {
+		for _, value := range lut {
+			table.Insert(value)
+		}
+	}
+	return selectedLUT{api: api, table: table}
+}
+
+// apply returns luts[k][v] for every value v, and asserts every v is a channel value. The caller asserts k is
+// the index of one of the luts.
+func (lut selectedLUT) apply(k frontend.Variable, values ...frontend.Variable) []frontend.Variable {
+	assertChannels(lut.api, values...)
+	offset := lut.api.Mul(k, channelMax+1)
+	indices := make([]frontend.Variable, len(values))
+	for i, value := range values {
+		indices[i] = lut.api.Add(offset, value)
+	}
+	return lut.table.Lookup(indices...)
+}
@@ -0,0 +1,170 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// An inverting tone curve, 255 - v.
+func invertLUT() *[channelMax + 1]uint8 {
+	var lut [channelMax + 1]uint8
+	for v := range lut {
+		lut[v] = uint8(channelMax - v)
+	}
+	return &lut
+}
+
+// Asserts Out[i] == invertLUT()[In[i]].
+type channelLUTCircuit struct {
+	In, Out [4]frontend.Variable
+}
+
+func (circuit *channelLUTCircuit) Define(api frontend.API) error {
+	out := newChannelLUT(api, invertLUT()).apply(circuit.In[:]...)
+	for i := range out {
+		api.AssertIsEqual(out[i], circuit.Out[i])
+	}
+	return nil
+}
+
+// Asserts Out[i] == clamp(In[i]).
+type clampCircuit struct {
+	In, Out [4]frontend.Variable
+}
+
+func (circuit *clampCircuit) Define(api frontend.API) error {
+	out := newClampTable(api).clamp(circuit.In[:]...)
+	for i := range out {
+		api.AssertIsEqual(out[i], circuit.Out[i])
+	}
+	return nil
+}
+
+// Asserts Out[i] == |In[i]|.
+type absCircuit struct {
+	In, Out [4]frontend.Variable
+}
+
+func (circuit *absCircuit) Define(api frontend.API) error {
+	out := newAbsTable(api).abs(circuit.In[:]...)
+	for i := range out {
+		api.AssertIsEqual(out[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestChannelLUT(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assignment := channelLUTCircuit{In: [4]frontend.Variable{0, 1, 128, 255}, Out: [4]frontend.Variable{255, 254, 127, 0}}
+	assert.NoError(test.IsSolved(&channelLUTCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	assignment.Out[2] = 128
+	assert.Error(test.IsSolved(&channelLUTCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// Not a channel value
+	assignment = channelLUTCircuit{In: [4]frontend.Variable{0, 1, 128, 256}, Out: [4]frontend.Variable{255, 254, 127, 0}}
+	assert.Error(test.IsSolved(&channelLUTCircuit{}, &assignment, ecc.BN254.ScalarField()))
+}
+
+func TestClampTable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assignment := clampCircuit{In: [4]frontend.Variable{-clampOffset, 0, 200, 510}, Out: [4]frontend.Variable{0, 0, 200, 255}}
+	assert.NoError(test.IsSolved(&clampCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	assignment.Out[3] = 510
+	assert.Error(test.IsSolved(&clampCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// Outside the table
+	for _, in := range []int{-clampOffset - 1, 1<<clampBits - clampOffset} {
+		assignment = clampCircuit{In: [4]frontend.Variable{0, 0, 0, in}, Out: [4]frontend.Variable{0, 0, 0, 0}}
+		assert.Error(test.IsSolved(&clampCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d", in)
+	}
+}
+
+func TestAbsTable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assignment := absCircuit{In: [4]frontend.Variable{-channelMax, -1, 0, channelMax}, Out: [4]frontend.Variable{channelMax, 1, 0, channelMax}}
+	assert.NoError(test.IsSolved(&absCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	assignment.Out[1] = -1
+	assert.Error(test.IsSolved(&absCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// Outside the table
+	for _, in := range []int{-absOffset - 1, absOffset} {
+		assignment = absCircuit{In: [4]frontend.Variable{0, 0, 0, in}, Out: [4]frontend.Variable{0, 0, 0, 0}}
+		assert.Error(test.IsSolved(&absCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d", in)
+	}
+}
+
+func TestPixelRange(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// An edit proves the previous image is an image, also where the edited image drops its pixels
+	img := myImage.AllWhiteImage()
+	area := myImage.Rect{X0: 4, Y0: 2, X1: 11, Y1: 9}
+	cropped, err := img.SubImage(area.X0, area.Y0, area.X1, area.Y1)
+	assert.NoError(err)
+	redacted, err := img.Redact(area)
+	assert.NoError(err)
+	nullifier := testNullifier(t)
+
+	// Both are edits of a proof of img, whose digest is their PrevImageBytes and which the camera signed
+	cameraKey, cameraSignature := signedTestImage(t, img)
+	prev := PrevSignature{PublicKey: cameraKey, Signature: cameraSignature, PrevProofHash: 0}
+	croppedKey, croppedSignature := signedTestEdit(t, cropped, 1)
+	crop := CropCircuit{
+		Statement:       testStatement(t, croppedKey, croppedSignature, testNonce, 1, nullifier, 1, cropped.Digest(), img.Digest(), cameraKey),
+		PublicKey:       croppedKey,
+		ImageSignature:  croppedSignature,
+		Nonce:           testNonce,
+		PrevProofHash:   1,
+		Nullifier:       nullifier,
+		Hops:            1,
+		ImageBytes:      cropped.Digest(),
+		PrevImageBytes:  img.Digest(),
+		Metadata:        cropped.MetadataDigest(),
+		PrevMetadata:    img.MetadataDigest(),
+		Prev:            prev,
+		FrImage:         img.ToFrontendImage(),
+		CroppedImage_in: cropped.ToFrontendImage(),
+		Params:          CropArgs{Area: area}.ToFr(),
+	}
+	redactedKey, redactedSignature := signedTestEdit(t, redacted, 1)
+	redact := RedactCircuit{
+		Statement:        testStatement(t, redactedKey, redactedSignature, testNonce, 1, nullifier, 1, redacted.Digest(), img.Digest(), cameraKey),
+		PublicKey:        redactedKey,
+		ImageSignature:   redactedSignature,
+		Nonce:            testNonce,
+		PrevProofHash:    1,
+		Nullifier:        nullifier,
+		Hops:             1,
+		Region:           CropArgs{Area: area}.ToFr(),
+		ImageBytes:       redacted.Digest(),
+		PrevImageBytes:   img.Digest(),
+		Metadata:         redacted.MetadataDigest(),
+		PrevMetadata:     img.MetadataDigest(),
+		Prev:             prev,
+		FrImage:          img.ToFrontendImage(),
+		RedactedImage_in: redacted.ToFrontendImage(),
+	}
+	assert.NoError(test.IsSolved(&CropCircuit{}, &crop, ecc.BN254.ScalarField()))
+	assert.NoError(test.IsSolved(&RedactCircuit{}, &redact, ecc.BN254.ScalarField()))
+
+	for _, channel := range []frontend.Variable{channelMax + 1, -1} {
+		outside := crop
+		outside.FrImage.Pixels[0][0].G = channel
+		assert.Error(test.IsSolved(&CropCircuit{}, &outside, ecc.BN254.ScalarField()), "channel %v outside of the crop", channel)
+
+		inside := redact
+		inside.FrImage.Pixels[area.Y0][area.X0].G = channel
+		assert.Error(test.IsSolved(&RedactCircuit{}, &inside, ecc.BN254.ScalarField()), "channel %v inside of the redaction", channel)
+	}
+}
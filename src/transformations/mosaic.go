@@ -0,0 +1,129 @@
+package transformations
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Mosaic transformations: the signed image is the image FrImage with every block of a
+// mosaic of Block x Block pixels that lies within the public Region replaced by its average color, like
+// image.I.Pixelate does, e.g. a face anonymized by pixelation. The Block is public too, one of
+// image.MosaicBlocks, and the averages are asserted in the circuit. An empty Region keeps the image.
+// Public fields: Statement, Region, Block
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, PixelatedImage_in
+type MosaicCircuit struct {
+	Statement         frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey         eddsa.PublicKey       // Key the image is signed with
+	ImageSignature    eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce             frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash     frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier         frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops              frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes        frontend.Variable     // Digest of the signed image, PixelatedImage_in
+	PrevImageBytes    frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Region            CropParams            `gnark:",public"` // Pixelated rectangle, possibly empty
+	Block             frontend.Variable     `gnark:",public"` // Size of the blocks, one of image.MosaicBlocks
+	Metadata          frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata      frontend.Variable     // MetadataDigest of the previous image
+	Prev              PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage           myImage.FrontendImage // z_in as a FrontendImage
+	PixelatedImage_in myImage.FrontendImage // Pixelated previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a pixelation.
+func (circuit *MosaicCircuit) Define(api frontend.API) error {
+	// Pixelate the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	if err := mosaicPlanes(api, planes[:], circuit.Region, circuit.Block); err != nil {
+		return err
+	}
+	pixelatedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.PixelatedImage_in, &pixelatedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.PixelatedImage_in)
+}
+
+// mosaicPlanes pixelates region in every plane with blocks of block x block pixels, in place, exactly like
+// image.I.Pixelate does outside the circuit. It asserts block is one of image.MosaicBlocks, and region lies
+// within the pixels of an image, or is empty.
+//
+// The mosaic of every permissible block is computed, and the block selects one. A block lies within region when
+// both of its corners do. Its averages are computed by a quotientHint and asserted like those of
+// downscalePlanes: the remainder of a sum is in [0, block*block), a power of two, and the average is unique as a
+// channel of 8 bits, which the digest of the signed image range checks.
+func mosaicPlanes(api frontend.API, planes []channelPlane, region CropParams, block frontend.Variable) error {
+	inRegion := regionIndicators(api, region, 0)
+	isBlock := valueIndicators(api, block, myImage.MosaicBlocks[:])
+	rangeChecker := rangecheck.New(api)
+
+	// Whether every pixel is replaced, and by which average, for the selected block
+	var replaced channelPlane
+	averaged := make([]channelPlane, len(planes))
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			replaced[y][x] = 0
+			for c := range averaged {
+				averaged[c][y][x] = 0
+			}
+		}
+	}
+	for i, size := range myImage.MosaicBlocks {
+		width, height := myImage.Width/size, myImage.Height/size
+
+		sums := make([]frontend.Variable, 0, len(planes)*width*height)
+		for c := range planes {
+			for y0 := 0; y0 < myImage.Height; y0 += size {
+				for x0 := 0; x0 < myImage.Width; x0 += size {
+					var sum frontend.Variable = 0
+					for y := y0; y < y0+size; y++ {
+						for x := x0; x < x0+size; x++ {
+							sum = api.Add(sum, planes[c][y][x])
+						}
+					}
+					sums = append(sums, sum)
+				}
+			}
+		}
+		n := len(sums)
+		for range n {
+			sums = append(sums, size*size)
+		}
+		averages, err := api.Compiler().NewHint(quotientHint, n, sums...)
+		if err != nil {
+			return err
+		}
+		for k := range averages {
+			rangeChecker.Check(api.Sub(sums[k], api.Mul(averages[k], size*size)), bits.Len(uint(size*size-1)))
+		}
+
+		for by := 0; by < height; by++ {
+			for bx := 0; bx < width; bx++ {
+				x0, y0 := bx*size, by*size
+				inside := api.Mul(isBlock[i], api.And(inRegion[y0][x0], inRegion[y0+size-1][x0+size-1]))
+				for y := y0; y < y0+size; y++ {
+					for x := x0; x < x0+size; x++ {
+						replaced[y][x] = api.Add(replaced[y][x], inside)
+						for c := range averaged {
+							averaged[c][y][x] = api.Add(averaged[c][y][x], api.Mul(isBlock[i], averages[(c*height+by)*width+bx]))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				planes[c][y][x] = api.Select(replaced[y][x], averaged[c][y][x], planes[c][y][x])
+			}
+		}
+	}
+	return nil
+}
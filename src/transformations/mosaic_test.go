@@ -0,0 +1,66 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts mosaicPlanes(In, Region, Block) == Out, without the signature check of the MosaicCircuit.
+type mosaicPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Region CropParams
+	Block  frontend.Variable
+}
+
+func (circuit *mosaicPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := mosaicPlanes(api, planes[:], circuit.Region, circuit.Block); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestMosaicPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	rects := []myImage.Rect{{X0: 3, Y0: 1, X1: 12, Y1: 8}, {X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1}}
+	for _, rect := range rects {
+		for _, block := range myImage.MosaicBlocks {
+			out, err := in.Pixelate(rect, block)
+			assert.NoError(err)
+
+			region := CropParams{X0: rect.X0, Y0: rect.Y0, X1: rect.X1, Y1: rect.Y1}
+			assignment := mosaicPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Region: region, Block: block}
+			assert.NoError(test.IsSolved(&mosaicPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "region %+v, block %d", rect, block)
+
+			// The image pixelated with other blocks, or within a smaller region, is not the pixelated image
+			assignment.Block = 6 - block
+			assert.Error(test.IsSolved(&mosaicPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "region %+v, block %d", rect, block)
+			assignment.Block = block
+			assignment.Region.X1 = rect.X1 - block
+			assert.Error(test.IsSolved(&mosaicPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "region %+v, block %d", rect, block)
+		}
+	}
+
+	// An empty region keeps the image, and blocks are one of image.MosaicBlocks
+	assignment := mosaicPixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Region: CropParams{X0: 0, Y0: 0, X1: -1, Y1: -1}, Block: 2}
+	assert.NoError(test.IsSolved(&mosaicPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	assignment.Block = 3
+	assert.Error(test.IsSolved(&mosaicPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The averages are those of the blocks
+	out, err := in.Pixelate(rects[1], 2)
+	assert.NoError(err)
+	out.Pixels[5][6].G++
+	assignment = mosaicPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Region: CropParams{X0: rects[1].X0, Y0: rects[1].Y0, X1: rects[1].X1, Y1: rects[1].Y1}, Block: 2}
+	assert.Error(test.IsSolved(&mosaicPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,44 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/hashsuite"
+)
+
+// Nullifier returns the nullifier of the capture nonce of the camera with publicKey: the PublicDigest of the
+// coordinates of the key and the nonce. It is the same for every proof of the capture, whichever edits they
+// prove, so a contest or claims system that records the nullifiers it was submitted detects a second
+// submission of the same picture.
+func Nullifier(publicKey []byte, nonce *big.Int) (*big.Int, error) {
+	var key eddsabn254.PublicKey
+	if _, err := key.SetBytes(publicKey); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if nonce == nil {
+		return nil, fmt.Errorf("missing nonce")
+	}
+
+	var x, y big.Int
+	key.A.X.BigInt(&x)
+	key.A.Y.BigInt(&y)
+	return PublicDigest(&x, &y, nonce), nil
+}
+
+// assertNullifier asserts that nullifier is the Nullifier of the capture nonce of the camera with publicKey,
+// when the proof is of an original image, i.e. prevProofHash is 0. An edit is signed by the prover rather than
+// the camera, and carries the nullifier of the proof it was made from instead, see prover.Prover.
+func assertNullifier(api frontend.API, nullifier frontend.Variable, publicKey eddsa.PublicKey, nonce frontend.Variable, prevProofHash frontend.Variable) error {
+	h, err := hashsuite.Default.NewGadget(api)
+	if err != nil {
+		return err
+	}
+	h.Write(publicKey.A.X, publicKey.A.Y, nonce)
+	api.AssertIsEqual(api.Mul(api.IsZero(prevProofHash), api.Sub(nullifier, h.Sum())), 0)
+	return nil
+}
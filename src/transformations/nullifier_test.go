@@ -0,0 +1,77 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// An original image is proven with the nullifier of its camera and capture, an edit with the one it carries.
+func TestNullifier(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	otherKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed+1)))
+	assert.NoError(err)
+
+	// Deterministic, and different for every camera and capture
+	nullifier := testNullifier(t)
+	again, err := Nullifier(secretKey.Public().Bytes(), big.NewInt(testNonce))
+	assert.NoError(err)
+	assert.Equal(0, nullifier.Cmp(again))
+	otherCapture, err := Nullifier(secretKey.Public().Bytes(), big.NewInt(testNonce+1))
+	assert.NoError(err)
+	assert.NotEqual(0, nullifier.Cmp(otherCapture))
+	otherCamera, err := Nullifier(otherKey.Public().Bytes(), big.NewInt(testNonce))
+	assert.NoError(err)
+	assert.NotEqual(0, nullifier.Cmp(otherCamera))
+
+	_, err = Nullifier(secretKey.Public().Bytes()[:10], big.NewInt(testNonce))
+	assert.Error(err)
+
+	// An original image, signed by the camera
+	img := myImage.AllWhiteImage()
+	cameraKey, cameraSignature := signedTestImage(t, img)
+	crop := func(signer signature.Signer, prevProofHash int64, nullifier *big.Int) CropCircuit {
+		sig, err := signer.Sign(myImage.Statement(img.Digest(), big.NewInt(testNonce), big.NewInt(prevProofHash)), hashsuite.Default.New())
+		assert.NoError(err)
+		assignment := CropCircuit{
+			Nonce:           testNonce,
+			PrevProofHash:   prevProofHash,
+			Nullifier:       nullifier,
+			Hops:            0,
+			ImageBytes:      img.Digest(),
+			PrevImageBytes:  img.Digest(),
+			Metadata:        img.MetadataDigest(),
+			PrevMetadata:    img.MetadataDigest(),
+			Prev:            PrevSignature{PublicKey: cameraKey, Signature: cameraSignature, PrevProofHash: 0},
+			FrImage:         img.ToFrontendImage(),
+			CroppedImage_in: img.ToFrontendImage(),
+			Params:          CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+		}
+		assignment.PublicKey.Assign(1, signer.Public().Bytes())
+		assignment.ImageSignature.Assign(1, sig)
+		assignment.Statement = testStatement(t, assignment.PublicKey, assignment.ImageSignature, assignment.Nonce, assignment.PrevProofHash, assignment.Nullifier, assignment.Hops, assignment.ImageBytes, assignment.PrevImageBytes, assignment.Prev.PublicKey)
+		return assignment
+	}
+
+	assignment := crop(secretKey, 0, nullifier)
+	assert.NoError(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	for _, wrong := range []*big.Int{otherCapture, otherCamera} {
+		assignment := crop(secretKey, 0, wrong)
+		assert.Error(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	}
+
+	// An edit is signed by the prover's key, and carries the camera's nullifier along
+	assignment = crop(otherKey, 1, nullifier)
+	assert.NoError(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+}
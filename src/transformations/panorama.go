@@ -0,0 +1,208 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// Largest number of columns the two captures of a panorama are blended over, and the number of bits that
+// holds it.
+const (
+	MaxPanoramaOverlap = myImage.Width / 2
+	overlapBits        = 4
+)
+
+// This circuit proves that an image is a panorama of two captures of the same camera: their horizontal
+// concatenation, with the captures blended over Overlap columns before the Seam, the first column that
+// only shows the Right capture. With d = Seam - Overlap, the column where the Right capture starts, and
+// k = x - d + 1, column x of the panorama is
+//
+//	x < d:         Left[x]
+//	d <= x < Seam: (Left[x] * (Overlap + 1 - k) + Right[x - d] * k) / (Overlap + 1)
+//	x >= Seam:     Right[x - d]
+//
+// rounded down, like Stitch does. Crop a capture first to choose the part of it that shows.
+//
+// Like the DisclosureCircuit, nothing about the captures is public but the camera's key and their capture
+// counters; the panorama is bound through a single public input, its ImageDigest (see RegionDigest). The
+// captures are packed (see image.I.Pack), and the signed LeftBytes and RightBytes are their digests, which the
+// circuit recomputes from the packed elements and metadata.
+//
+// Public fields: PublicKey, LeftNonce, RightNonce, ImageDigest, Seam, Overlap
+// Secret fields: LeftSignature, RightSignature, LeftBytes, RightBytes, LeftMetadata, RightMetadata, Left, Right,
+// Panorama
+type PanoramaCircuit struct {
+	PublicKey      eddsa.PublicKey        `gnark:",public"` // the camera's public key
+	LeftNonce      frontend.Variable      `gnark:",public"` // capture counter of the left capture
+	RightNonce     frontend.Variable      `gnark:",public"` // capture counter of the right capture
+	ImageDigest    frontend.Variable      `gnark:",public"` // RegionDigest of the panorama
+	Seam           frontend.Variable      `gnark:",public"` // first column of the panorama that only shows Right
+	Overlap        frontend.Variable      `gnark:",public"` // columns before the Seam that blend both, at most MaxPanoramaOverlap
+	LeftSignature  eddsa.Signature        // the camera's signature over the left capture
+	RightSignature eddsa.Signature        // the camera's signature over the right capture
+	LeftBytes      frontend.Variable      // Digest of the left capture
+	RightBytes     frontend.Variable      // Digest of the right capture
+	LeftMetadata   frontend.Variable      // MetadataDigest of the left capture
+	RightMetadata  frontend.Variable      // MetadataDigest of the right capture
+	Left           myImage.FrontendPacked // left capture as a FrontendPacked
+	Right          myImage.FrontendPacked // right capture as a FrontendPacked
+	Panorama       myImage.FrontendImage  // panorama as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a panorama.
+func (circuit *PanoramaCircuit) Define(api frontend.API) error {
+	comparator := newLocationComparator(api)
+	rangeChecker := rangecheck.New(api)
+
+	// Both captures show, and the overlap is bounded. The Right capture starts at column d = Seam - Overlap,
+	// which offsetIndicators asserts is within the image.
+	comparator.AssertIsLessEq(1, circuit.Seam)
+	comparator.AssertIsLessEq(circuit.Seam, myImage.Width-1)
+	rangeChecker.Check(circuit.Overlap, overlapBits)
+	rangeChecker.Check(api.Sub(MaxPanoramaOverlap, circuit.Overlap), overlapBits)
+	start := api.Sub(circuit.Seam, circuit.Overlap)
+	isOffset := offsetIndicators(api, start, myImage.Width)
+
+	// Weight of the Right capture in every column, out of Overlap+1
+	var weight [myImage.Width]frontend.Variable
+	for x := 0; x < myImage.Width; x++ {
+		beforeStart := comparator.IsLess(x, start)
+		afterSeam := comparator.IsLessEq(circuit.Seam, x)
+		blended := api.Select(afterSeam, api.Add(circuit.Overlap, 1), api.Add(api.Sub(x, start), 1))
+		weight[x] = api.Select(beforeStart, 0, blended)
+	}
+
+	// Every channel of the panorama is the blend of its captures, rounded down: the remainder of the blend
+	// is in [0, Overlap]. Outside the blended columns the weights are 0 or Overlap+1, so it is 0.
+	leftImage, err := unpackImage(api, &circuit.Left)
+	if err != nil {
+		return err
+	}
+	rightImage, err := unpackImage(api, &circuit.Right)
+	if err != nil {
+		return err
+	}
+	left := channelPlanes(&leftImage)
+	right := channelPlanes(&rightImage)
+	panorama := channelPlanes(&circuit.Panorama)
+	total := api.Add(circuit.Overlap, 1)
+	terms := make([]frontend.Variable, 0, myImage.Width)
+	for c := range panorama {
+		for y := 0; y < myImage.Height; y++ {
+			shifted := shiftRowRight(api, isOffset, &right[c][y], terms)
+			for x := 0; x < myImage.Width; x++ {
+				blend := api.Add(api.Mul(left[c][y][x], api.Sub(total, weight[x])), api.Mul(shifted[x], weight[x]))
+				remainder := api.Sub(blend, api.Mul(panorama[c][y][x], total))
+				rangeChecker.Check(remainder, overlapBits)
+				rangeChecker.Check(api.Sub(circuit.Overlap, remainder), overlapBits)
+			}
+		}
+	}
+
+	// Channels are packed into few field elements before hashing, which is only unambiguous for 8 bit values
+	channels := regionChannels(&circuit.Panorama)
+	assertChannels(api, channels...)
+	if err := assertPublicDigest(api, circuit.ImageDigest, packChannels(api, channels)...); err != nil {
+		return err
+	}
+
+	// Two different captures, both original images signed by the camera
+	api.AssertIsDifferent(circuit.LeftNonce, circuit.RightNonce)
+	if err := assertPackedDigest(api, circuit.LeftBytes, circuit.LeftMetadata, circuit.Left.Elements[:]); err != nil {
+		return err
+	}
+	if err := assertPackedDigest(api, circuit.RightBytes, circuit.RightMetadata, circuit.Right.Elements[:]); err != nil {
+		return err
+	}
+	if err := assertSignedStatement(api, circuit.PublicKey, circuit.LeftSignature, circuit.LeftBytes, circuit.LeftNonce, 0); err != nil {
+		return err
+	}
+	return assertSignedStatement(api, circuit.PublicKey, circuit.RightSignature, circuit.RightBytes, circuit.RightNonce, 0)
+}
+
+// shiftRowRight translates a row to the right by the offset whose indicator is set, like shiftRow does to
+// the left:
+//
+//	shifted[i] = row[i-offset], or 0 before the offset
+func shiftRowRight(api frontend.API, isOffset []frontend.Variable, row *[myImage.Width]frontend.Variable, terms []frontend.Variable) [myImage.Width]frontend.Variable {
+	var shifted [myImage.Width]frontend.Variable
+	for i := 0; i < myImage.Width; i++ {
+		terms = terms[:0]
+		for k := 0; k <= i; k++ {
+			terms = append(terms, api.Mul(isOffset[k], row[i-k]))
+		}
+		if len(terms) == 1 {
+			shifted[i] = terms[0]
+		} else {
+			shifted[i] = api.Add(terms[0], terms[1], terms[2:]...)
+		}
+	}
+	return shifted
+}
+
+// CheckPanorama returns an error if a panorama cannot be stitched at seam with overlap blended columns.
+func CheckPanorama(seam, overlap int) error {
+	if seam < 1 || seam > myImage.Width-1 {
+		return fmt.Errorf("invalid panorama seam %d: expected a column in [1, %d]", seam, myImage.Width-1)
+	}
+	if overlap < 0 || overlap > MaxPanoramaOverlap || overlap > seam {
+		return fmt.Errorf("invalid panorama overlap %d: expected at most %d columns, and at most the seam %d", overlap, MaxPanoramaOverlap, seam)
+	}
+	return nil
+}
+
+// Stitch returns the panorama of left and right, as the PanoramaCircuit stitches it. Its metadata only holds
+// its width and height.
+func Stitch(left, right myImage.I, seam, overlap int) (myImage.I, error) {
+	if err := CheckPanorama(seam, overlap); err != nil {
+		return myImage.I{}, err
+	}
+
+	panorama := myImage.I{M: myImage.Metadata{Width: myImage.Width, Height: myImage.Height}}
+	start := seam - overlap
+	blend := func(l, r uint8, weight int) uint8 {
+		return uint8((int(l)*(overlap+1-weight) + int(r)*weight) / (overlap + 1))
+	}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			weight := min(max(x-start+1, 0), overlap+1)
+			var r myImage.RGBPixel
+			if x >= start {
+				r = right.Pixels[y][x-start]
+			}
+			l := left.Pixels[y][x]
+			panorama.Pixels[y][x] = myImage.RGBPixel{R: blend(l.R, r.R, weight), G: blend(l.G, r.G, weight), B: blend(l.B, r.B, weight)}
+		}
+	}
+	return panorama, nil
+}
+
+// PanoramaWitness returns the public witness of a proof that panorama was stitched at seam, with overlap
+// blended columns, from the captures leftNonce and rightNonce of the camera with publicKey. The verifier
+// builds it from the published panorama itself.
+func PanoramaWitness(publicKey []byte, leftNonce, rightNonce *big.Int, panorama myImage.I, seam, overlap int) (witness.Witness, error) {
+	if err := CheckPanorama(seam, overlap); err != nil {
+		return nil, err
+	}
+	if leftNonce == nil || rightNonce == nil {
+		return nil, fmt.Errorf("missing nonce")
+	}
+
+	var assignment PanoramaCircuit
+	assignment.PublicKey.Assign(1, publicKey)
+	assignment.LeftNonce = leftNonce
+	assignment.RightNonce = rightNonce
+	assignment.ImageDigest = RegionDigest(panorama)
+	assignment.Seam = seam
+	assignment.Overlap = overlap
+
+	return frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
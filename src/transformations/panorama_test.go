@@ -0,0 +1,129 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+func TestStitch(t *testing.T) {
+	left := myImage.AllWhiteImage()
+	right := myImage.CoordinateImage()
+
+	// Seam 10, blended over 3 columns: the right capture starts at column 7
+	panorama, err := Stitch(left, right, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		x    int
+		want uint8 // red channel of row 0
+	}{
+		{6, 255},               // left only
+		{7, (255*3 + 0*1) / 4}, // blended, right column 0
+		{9, (255*1 + 2*3) / 4}, // blended, right column 2
+		{10, 3},                // right only, column 3
+		{myImage.Width - 1, myImage.Width - 1 - 7}, // right column Width-8
+	} {
+		if got := panorama.Pixels[0][c.x].R; got != c.want {
+			t.Errorf("column %d: red is %d, expected %d", c.x, got, c.want)
+		}
+	}
+
+	for _, params := range [][2]int{{0, 0}, {myImage.Width, 0}, {4, 5}, {10, MaxPanoramaOverlap + 1}, {10, -1}} {
+		if _, err := Stitch(left, right, params[0], params[1]); err == nil {
+			t.Errorf("seam %d with overlap %d was stitched", params[0], params[1])
+		}
+	}
+}
+
+func TestPanoramaCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	left, right := myImage.AllWhiteImage(), myImage.CoordinateImage()
+	leftNonce, rightNonce := big.NewInt(testNonce), big.NewInt(testNonce+1)
+	leftSignature, err := secretKey.Sign(myImage.Statement(left.Digest(), leftNonce, big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+	rightSignature, err := secretKey.Sign(myImage.Statement(right.Digest(), rightNonce, big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+
+	valid := func(seam, overlap int) PanoramaCircuit {
+		panorama, err := Stitch(left, right, seam, overlap)
+		assert.NoError(err)
+		assignment := PanoramaCircuit{
+			LeftNonce:     leftNonce,
+			RightNonce:    rightNonce,
+			ImageDigest:   RegionDigest(panorama),
+			Seam:          seam,
+			Overlap:       overlap,
+			LeftBytes:     left.Digest(),
+			LeftMetadata:  left.MetadataDigest(),
+			RightBytes:    right.Digest(),
+			RightMetadata: right.MetadataDigest(),
+			Left:          left.ToFrontendPacked(),
+			Right:         right.ToFrontendPacked(),
+			Panorama:      panorama.ToFrontendImage(),
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.LeftSignature.Assign(1, leftSignature)
+		assignment.RightSignature.Assign(1, rightSignature)
+		return assignment
+	}
+
+	for _, params := range [][2]int{{10, 3}, {8, 0}, {1, 1}, {myImage.Width - 1, MaxPanoramaOverlap}} {
+		assignment := valid(params[0], params[1])
+		assert.NoError(test.IsSolved(&PanoramaCircuit{}, &assignment, ecc.BN254.ScalarField()), "%v", params)
+	}
+
+	// The panorama, its geometry and the captures are all bound
+	other, err := Stitch(left, right, 10, 2)
+	assert.NoError(err)
+	for name, tamper := range map[string]func(*PanoramaCircuit){
+		"digest":   func(c *PanoramaCircuit) { c.ImageDigest = RegionDigest(other) },
+		"pixels":   func(c *PanoramaCircuit) { c.Panorama = other.ToFrontendImage() },
+		"rounded":  func(c *PanoramaCircuit) { c.Panorama.Pixels[0][8].R = 0 },
+		"seam":     func(c *PanoramaCircuit) { c.Seam = 11 },
+		"overlap":  func(c *PanoramaCircuit) { c.Overlap = 2 },
+		"nonce":    func(c *PanoramaCircuit) { c.RightNonce = testNonce + 2 },
+		"twice":    func(c *PanoramaCircuit) { c.RightNonce = testNonce },
+		"metadata": func(c *PanoramaCircuit) { c.LeftMetadata = 1 },
+	} {
+		assignment := valid(10, 3)
+		tamper(&assignment)
+		assert.Error(test.IsSolved(&PanoramaCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// Out of bounds geometry cannot be proven, even when stitched consistently
+	for _, params := range [][2]int{{10, MaxPanoramaOverlap + 1}, {4, 5}} {
+		assignment := valid(10, 3)
+		assignment.Seam, assignment.Overlap = params[0], params[1]
+		assert.Error(test.IsSolved(&PanoramaCircuit{}, &assignment, ecc.BN254.ScalarField()), "%v", params)
+	}
+
+	// The verifier's public witness is the prover's
+	assignment := valid(10, 3)
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	panorama, err := Stitch(left, right, 10, 3)
+	assert.NoError(err)
+	panoramaWitness, err := PanoramaWitness(secretKey.Public().Bytes(), leftNonce, rightNonce, panorama, 10, 3)
+	assert.NoError(err)
+	got, err := panoramaWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+}
@@ -0,0 +1,36 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+
+	myImage "src/image"
+)
+
+// A single color channel of a FrontendImage, indexed [y][x] like its pixels.
+// Pixel loops of the compliance predicates work on planes one row at a time, so each row of
+// constraints only depends on its own row of values.
+type channelPlane [myImage.Height][myImage.Width]frontend.Variable
+
+// channelPlanes splits the image into its R, G and B planes.
+func channelPlanes(img *myImage.FrontendImage) [3]channelPlane {
+	var planes [3]channelPlane
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			planes[0][y][x] = img.Pixels[y][x].R
+			planes[1][y][x] = img.Pixels[y][x].G
+			planes[2][y][x] = img.Pixels[y][x].B
+		}
+	}
+	return planes
+}
+
+// fromChannelPlanes joins R, G and B planes back into an image.
+func fromChannelPlanes(planes *[3]channelPlane) myImage.FrontendImage {
+	var img myImage.FrontendImage
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			img.Pixels[y][x] = myImage.FrontendPixel{R: planes[0][y][x], G: planes[1][y][x], B: planes[2][y][x]}
+		}
+	}
+	return img
+}
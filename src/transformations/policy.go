@@ -0,0 +1,120 @@
+package transformations
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// PolicyTypes are the permissible transformations of a Policy, the set T of the Generator of the paper: keys
+// generated for a Policy prove an edit of any of them.
+var PolicyTypes = [...]int{Identity, Crop, Rotate}
+
+// This circuit is only for Policy transformations: the signed image is the image FrImage transformed by one of
+// the PolicyTypes, which the public Selector names, i.e. it is the image itself, a crop of it or a rotation of
+// it. Each is a RotateCrop, of the RotateCropCircuit, the Selector bounds: an Identity neither rotates nor crops,
+// a Crop does not rotate and a Rotate keeps the whole rotated image. So one pair of keys covers the whole
+// policy, and a proof tells which of its transformations it holds for, but not the area of a Crop.
+// Public fields: Statement, Selector
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, EditedImage_in, Params
+type PolicyCircuit struct {
+	Statement      frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey      eddsa.PublicKey       // Key the image is signed with
+	ImageSignature eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce          frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash  frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier      frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops           frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes     frontend.Variable     // Digest of the signed image, EditedImage_in
+	PrevImageBytes frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Selector       frontend.Variable     `gnark:",public"` // Type of the transformation, one of PolicyTypes
+	Metadata       frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata   frontend.Variable     // MetadataDigest of the previous image
+	Prev           PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage        myImage.FrontendImage // z_in as a FrontendImage
+	EditedImage_in myImage.FrontendImage // Transformed previous image as a FrontendImage
+	Params         RotateCropParams      // The transformation as a RotateCrop, see PolicyCircuitParams
+}
+
+// Defines the Compliance Predicate of a transformation of a Policy.
+func (circuit *PolicyCircuit) Define(api frontend.API) error {
+	// Transform the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	policyPlanes(api, &planes, circuit.Selector, circuit.Params)
+	editedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.EditedImage_in, &editedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.EditedImage_in)
+}
+
+// policyPlanes transforms the planes of an image, in place, by the transformation of the PolicyTypes that
+// selector names, with params its RotateCrop, see rotateCropPlanes.
+func policyPlanes(api frontend.API, planes *[3]channelPlane, selector frontend.Variable, params RotateCropParams) {
+	isType := valueIndicators(api, selector, PolicyTypes[:])
+	isIdentity, isCrop, isRotate := isType[0], isType[1], isType[2]
+	rotated := rotateCropPlanes(api, planes, params)
+
+	// An Identity and a Crop keep the orientation of the image
+	api.AssertIsEqual(api.Mul(api.Add(isIdentity, isCrop), params.Rotate.Quarters), 0)
+
+	// An Identity and a Rotate keep the whole rotated image, from its top left corner to its bottom right one
+	keepsArea := api.Add(isIdentity, isRotate)
+	api.AssertIsEqual(api.Mul(keepsArea, params.Crop.X0), 0)
+	api.AssertIsEqual(api.Mul(keepsArea, params.Crop.Y0), 0)
+	api.AssertIsEqual(api.Mul(keepsArea, api.Sub(params.Crop.X1, api.Sub(rotated.X1, rotated.X0))), 0)
+	api.AssertIsEqual(api.Mul(keepsArea, api.Sub(params.Crop.Y1, api.Sub(rotated.Y1, rotated.Y0))), 0)
+}
+
+// PolicyOf returns the Policy transformation that makes the same image as t, one of the PolicyTypes: its
+// selector is the type of t, and its parameters those of t, the quarter turns of a Rotate and the area of a
+// Crop, with the others 0. A Policy is returned as it is.
+func PolicyOf(t Transformation) (Transformation, error) {
+	if t.T == Policy {
+		return t, nil
+	}
+	if !slices.Contains(PolicyTypes[:], t.T) {
+		return Transformation{}, fmt.Errorf("transformation type %d is not one of the policy %v", t.T, PolicyTypes)
+	}
+	if err := t.CheckParams(); err != nil {
+		return Transformation{}, err
+	}
+	return Transformation{T: Policy, Params: policyParams(t.T, t.Params)}, nil
+}
+
+// policyParams returns the Params of the Policy transformation of type t of the PolicyTypes with params.
+func policyParams(t int, params map[string]int) map[string]int {
+	policy := map[string]int{"selector": t, "quarters": 0, "x0": 0, "y0": 0, "x1": 0, "y1": 0}
+	if t != Identity {
+		for key, value := range params {
+			policy[key] = value
+		}
+	}
+	return policy
+}
+
+// policyEdit returns the transformation of the PolicyTypes that the Policy params select.
+func policyEdit(params map[string]int) (Transformation, error) {
+	switch params["selector"] {
+	case Identity:
+		return Transformation{T: Identity}, nil
+	case Crop:
+		return Transformation{T: Crop, Params: map[string]int{"x0": params["x0"], "y0": params["y0"], "x1": params["x1"], "y1": params["y1"]}}, nil
+	case Rotate:
+		return Transformation{T: Rotate, Params: map[string]int{"quarters": params["quarters"]}}, nil
+	}
+	return Transformation{}, fmt.Errorf("invalid selector %d: expected one of the policy %v", params["selector"], PolicyTypes)
+}
+
+// PolicyCircuitParams returns the parameters of the PolicyCircuit for the transformation t of the PolicyTypes,
+// which made out of in: the quarter turns of a Rotate, and the area of a Crop, or else the whole of out.
+func PolicyCircuitParams(in, out myImage.I, t Transformation) RotateCropParams {
+	if t.T == Crop {
+		return RotateCropCircuitParams(in, 0, t.Params["x0"], t.Params["y0"], t.Params["x1"], t.Params["y1"])
+	}
+	return RotateCropCircuitParams(in, t.Params["quarters"], 0, 0, out.M.Width-1, out.M.Height-1)
+}
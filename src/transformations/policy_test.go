@@ -0,0 +1,87 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts policyPlanes(In, Selector, Params) == Out, without the signature check of the PolicyCircuit.
+type policyPixelsCircuit struct {
+	In       myImage.FrontendImage
+	Out      myImage.FrontendImage
+	Selector frontend.Variable
+	Params   RotateCropParams
+}
+
+func (circuit *policyPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	policyPlanes(api, &planes, circuit.Selector, circuit.Params)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestPolicyPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Every transformation of the policy, as a Policy, is the image the transformation makes
+	in, err := myImage.CoordinateImage().SubImage(4, 3, 13, 8)
+	assert.NoError(err)
+	for _, edit := range []Transformation{
+		{T: Identity},
+		{T: Crop, Params: map[string]int{"x0": 2, "y0": 1, "x1": 7, "y1": 4}},
+		{T: Rotate, Params: map[string]int{"quarters": 1}},
+		{T: Rotate, Params: map[string]int{"quarters": 2}},
+	} {
+		policy, err := PolicyOf(edit)
+		assert.NoError(err)
+		out, err := policy.Apply(in)
+		assert.NoError(err)
+		want, err := edit.Apply(in)
+		assert.NoError(err)
+		assert.Equal(want.Pixels, out.Pixels)
+		assert.Equal(want.M.Width, out.M.Width)
+
+		assignment := policyPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Selector: edit.T, Params: PolicyCircuitParams(in, out, edit)}
+		assert.NoError(test.IsSolved(&policyPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "transformation %+v", edit)
+	}
+
+	// A transformation is not proven as another of the policy, even one that makes the same pixels
+	rotated, err := in.Rotate(1)
+	assert.NoError(err)
+	cropped, err := in.SubImage(2, 1, 7, 4)
+	assert.NoError(err)
+	rotatedCrop, err := rotated.SubImage(1, 1, 4, 5)
+	assert.NoError(err)
+	rightSide, err := in.SubImage(2, 0, 9, 5)
+	assert.NoError(err)
+	for name, assignment := range map[string]policyPixelsCircuit{
+		"a crop as an identity":           {Out: cropped.ToFrontendImage(), Selector: Identity, Params: RotateCropCircuitParams(in, 0, 2, 1, 7, 4)},
+		"a right side as an identity":     {Out: rightSide.ToFrontendImage(), Selector: Identity, Params: RotateCropCircuitParams(in, 0, 2, 0, 9, 5)},
+		"a rotation as a crop":            {Out: rotated.ToFrontendImage(), Selector: Crop, Params: RotateCropCircuitParams(in, 1, 0, 0, 5, 9)},
+		"a rotation and crop as a rotate": {Out: rotatedCrop.ToFrontendImage(), Selector: Rotate, Params: RotateCropCircuitParams(in, 1, 1, 1, 4, 5)},
+		"a crop as a rotate":              {Out: cropped.ToFrontendImage(), Selector: Rotate, Params: RotateCropCircuitParams(in, 0, 2, 1, 7, 4)},
+		"a transformation out of it":      {Out: in.ToFrontendImage(), Selector: Brightness, Params: RotateCropCircuitParams(in, 0, 0, 0, 9, 5)},
+	} {
+		assignment.In = in.ToFrontendImage()
+		assert.Error(test.IsSolved(&policyPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// Only the transformations of the policy are converted into a Policy
+	for _, edit := range []Transformation{
+		{T: Brightness, Params: map[string]int{"delta": 10}},
+		{T: Crop, Params: map[string]int{"x0": 2, "y0": 1, "x1": 7}},
+	} {
+		if _, err := PolicyOf(edit); err == nil {
+			t.Errorf("transformation %+v was converted into a Policy", edit)
+		}
+	}
+	if _, err := New(PolicyArgs{Edit: BrightnessArgs{Delta: 10}}); err == nil {
+		t.Error("a Brightness was accepted as the edit of a Policy")
+	}
+}
@@ -0,0 +1,20 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+
+	myImage "src/image"
+)
+
+// assertPrevImage asserts that prevImageBytes, a public input, is the digest of the previous image in, whose
+// channels are range checked, with its metadata prevMetadata, and that it is imageBytes for an original image,
+// i.e. when prevProofHash is 0. The ImageBytes of a proof are public too, so a verifier of an edit history
+// checks that the PrevImageBytes of every edit are the ImageBytes of the proof before it: the image an edit
+// was made from is the image the previous proof is about, rather than any image the prover chose.
+func assertPrevImage(api frontend.API, prevImageBytes, prevMetadata, imageBytes, prevProofHash frontend.Variable, in *myImage.FrontendImage) error {
+	if err := assertPackedDigest(api, prevImageBytes, prevMetadata, packBits(api, regionChannels(in), channelBits)); err != nil {
+		return err
+	}
+	api.AssertIsEqual(api.Mul(api.IsZero(prevProofHash), api.Sub(prevImageBytes, imageBytes)), 0)
+	return nil
+}
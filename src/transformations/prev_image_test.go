@@ -0,0 +1,61 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// An edit exposes the digest of the image it was made from, which is its own digest for an original image.
+func TestPrevImage(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	img := myImage.AllWhiteImage()
+	other := img.Clone()
+	other.SetPixel(3, 4, myImage.RGBPixel{})
+	nullifier := testNullifier(t)
+
+	// A crop of the whole image, made from the proof whose hash is prevProofHash, of the image prev
+	crop := func(prevProofHash int64, prev myImage.I) CropCircuit {
+		publicKey, signature := signedTestEdit(t, img, prevProofHash)
+		prevKey, prevSignature := signedTestImage(t, prev)
+		hops := 1
+		if prevProofHash == 0 {
+			hops = 0
+		}
+		return CropCircuit{
+			Statement:       testStatement(t, publicKey, signature, testNonce, prevProofHash, nullifier, hops, img.Digest(), prev.Digest(), prevKey),
+			PublicKey:       publicKey,
+			ImageSignature:  signature,
+			Nonce:           testNonce,
+			PrevProofHash:   prevProofHash,
+			Nullifier:       nullifier,
+			Hops:            hops,
+			ImageBytes:      img.Digest(),
+			PrevImageBytes:  prev.Digest(),
+			Metadata:        img.MetadataDigest(),
+			PrevMetadata:    prev.MetadataDigest(),
+			Prev:            PrevSignature{PublicKey: prevKey, Signature: prevSignature, PrevProofHash: 0},
+			FrImage:         img.ToFrontendImage(),
+			CroppedImage_in: img.ToFrontendImage(),
+			Params:          CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+		}
+	}
+
+	assignment := crop(1, img)
+	assert.NoError(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	assignment = crop(0, img)
+	assert.NoError(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The digest of another image than the one the edit was made from
+	assignment = crop(1, other)
+	assert.Error(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The digest of the pixels with other metadata
+	assignment = crop(1, img)
+	assignment.PrevMetadata = 1
+	assert.Error(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+}
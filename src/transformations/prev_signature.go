@@ -0,0 +1,38 @@
+package transformations
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// A PrevSignature is the signature of the previous image of an edit, the image of the proof the edit was made
+// from: the key it was signed with, the camera's for an original and the editor key for an edit, and the
+// signature over the statement of its digest, the nonce of the capture and the PrevProofHash of that proof. An
+// original image is its own previous image, with its own signature.
+//
+// The compliance predicates of edits verify it, besides the signature of the edited image, so that a proof
+// attests that the image it was made from was signed for the same capture, rather than only hashed into its
+// PrevImageBytes. Its PublicKey is part of the StatementDigest, for the verifier to check against the keys it
+// holds; the signature and the hash of the proof before the previous one stay secret.
+type PrevSignature struct {
+	PublicKey     eddsa.PublicKey   // Key the previous image is signed with
+	Signature     eddsa.Signature   // Signature of the previous image
+	PrevProofHash frontend.Variable // PrevProofHash of the proof the edit was made from, 0 for an original image
+}
+
+// NewPrevSignature returns the assignment of a PrevSignature: the signature of publicKey over the previous image,
+// the nonce and prevProofHash, the PrevProofHash of the proof the edit was made from.
+func NewPrevSignature(publicKey []byte, signature []byte, prevProofHash *big.Int) PrevSignature {
+	prev := PrevSignature{PrevProofHash: prevProofHash}
+	prev.PublicKey.Assign(1, publicKey)
+	prev.Signature.Assign(1, signature)
+	return prev
+}
+
+// assertPrevSignature asserts that prev is the signature of the previous image, whose digest is prevImageBytes,
+// for the capture nonce, see assertSignedStatement.
+func assertPrevSignature(api frontend.API, prev PrevSignature, prevImageBytes, nonce frontend.Variable) error {
+	return assertSignedStatement(api, prev.PublicKey, prev.Signature, prevImageBytes, nonce, prev.PrevProofHash)
+}
@@ -0,0 +1,76 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// An edit proves that the image it was made from was signed for the same capture, by the key its statement exposes.
+func TestPrevSignature(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	img := myImage.AllWhiteImage()
+	other := img.Clone()
+	other.SetPixel(3, 4, myImage.RGBPixel{})
+	nullifier := testNullifier(t)
+	editKey, editSignature := signedTestEdit(t, img, 1)
+	camera, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	stranger, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed+1)))
+	assert.NoError(err)
+
+	// A crop of the whole image, edited from the proof of img whose PrevSignature is prev, exposing the key
+	// statementKey of the previous image
+	crop := func(prev PrevSignature, statementKey []byte) CropCircuit {
+		assignment := CropCircuit{
+			PublicKey:       editKey,
+			ImageSignature:  editSignature,
+			Nonce:           testNonce,
+			PrevProofHash:   1,
+			Nullifier:       nullifier,
+			Hops:            1,
+			ImageBytes:      img.Digest(),
+			PrevImageBytes:  img.Digest(),
+			Metadata:        img.MetadataDigest(),
+			PrevMetadata:    img.MetadataDigest(),
+			Prev:            prev,
+			FrImage:         img.ToFrontendImage(),
+			CroppedImage_in: img.ToFrontendImage(),
+			Params:          CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1},
+		}
+		var prevKey eddsa.PublicKey
+		prevKey.Assign(1, statementKey)
+		assignment.Statement = testStatement(t, editKey, editSignature, testNonce, 1, nullifier, 1, img.Digest(), img.Digest(), prevKey)
+		return assignment
+	}
+	sign := func(signer signature.Signer, image myImage.I, nonce int64) PrevSignature {
+		sig, err := signer.Sign(myImage.Statement(image.Digest(), big.NewInt(nonce), big.NewInt(0)), hashsuite.Default.New())
+		assert.NoError(err)
+		return NewPrevSignature(signer.Public().Bytes(), sig, big.NewInt(0))
+	}
+
+	assignment := crop(sign(camera, img, testNonce), camera.Public().Bytes())
+	assert.NoError(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The previous image signed for another capture, or another image signed for the capture
+	assignment = crop(sign(camera, img, testNonce+1), camera.Public().Bytes())
+	assert.Error(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	assignment = crop(sign(camera, other, testNonce), camera.Public().Bytes())
+	assert.Error(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// Signed by another key, which the statement exposes for the verifier to reject
+	assignment = crop(sign(stranger, img, testNonce), stranger.Public().Bytes())
+	assert.NoError(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+	assignment = crop(sign(stranger, img, testNonce), camera.Public().Bytes())
+	assert.Error(test.IsSolved(&CropCircuit{}, &assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,44 @@
+package transformations
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+
+	"src/hashsuite"
+)
+
+// Every public input of a compliance predicate costs the verifier a scalar multiplication, and an on-chain
+// verifier 32 bytes of calldata. Public data that grows with the image or its edit history, e.g. hashes of
+// image chunks or transformation metadata, therefore is not exposed one field element per public input.
+// It is a secret input of the circuit instead, bound to a single public input: its digest under
+// hashsuite.Default. The verifier holds the data, recomputes the digest with PublicDigest and verifies the
+// proof against that one public input.
+//
+// (gnark's commitment API does not fit this purpose: the commitment it creates is only known inside the
+// proof, so the verifier has nothing to compare its data with.)
+
+// PublicDigest returns the digest of values, as asserted by assertPublicDigest inside a circuit.
+// Values are reduced modulo the BN254 scalar field, like the circuit variables they are assigned to.
+func PublicDigest(values ...*big.Int) *big.Int {
+	h := hashsuite.Default.New()
+	for _, value := range values {
+		var element fr.Element
+		element.SetBigInt(value)
+		bytes := element.Bytes()
+		h.Write(bytes[:])
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// assertPublicDigest asserts that digest is the PublicDigest of values.
+func assertPublicDigest(api frontend.API, digest frontend.Variable, values ...frontend.Variable) error {
+	h, err := hashsuite.Default.NewGadget(api)
+	if err != nil {
+		return err
+	}
+	h.Write(values...)
+	api.AssertIsEqual(h.Sum(), digest)
+	return nil
+}
@@ -0,0 +1,45 @@
+package transformations
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+// Exposes the digest of its values as its only public input.
+type publicDigestCircuit struct {
+	Digest frontend.Variable `gnark:",public"`
+	Values [8]frontend.Variable
+}
+
+func (circuit *publicDigestCircuit) Define(api frontend.API) error {
+	return assertPublicDigest(api, circuit.Digest, circuit.Values[:]...)
+}
+
+func TestPublicDigest(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(255), ecc.BN254.ScalarField()}
+	for i := 4; i < 8; i++ {
+		values = append(values, new(big.Int).Lsh(big.NewInt(1), uint(60*i)))
+	}
+
+	var assignment publicDigestCircuit
+	for i, value := range values {
+		assignment.Values[i] = value
+	}
+	assignment.Digest = PublicDigest(values...)
+	assert.NoError(test.IsSolved(&publicDigestCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The digest commits to every value
+	assignment.Values[7] = 0
+	assert.Error(test.IsSolved(&publicDigestCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &publicDigestCircuit{})
+	assert.NoError(err)
+	assert.Equal(1+1, ccs.GetNbPublicVariables(), "the digest and the constant 1 wire")
+}
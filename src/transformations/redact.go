@@ -0,0 +1,87 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Redact transformations: the signed image is the image FrImage with every pixel of the
+// public Region blackened, like image.I.Redact does, and every pixel outside of it unchanged, e.g. a face or a
+// license plate hidden from a published photo. The Region is a rectangle like the area of a crop, from (X0, Y0)
+// to (X1, Y1) included; an empty Region, e.g. one whose X1 is X0-1, keeps the image.
+// Public fields: Statement, Region
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, RedactedImage_in
+type RedactCircuit struct {
+	Statement        frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey        eddsa.PublicKey       // Key the image is signed with
+	ImageSignature   eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce            frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash    frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier        frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops             frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes       frontend.Variable     // Digest of the signed image, RedactedImage_in
+	PrevImageBytes   frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Region           CropParams            `gnark:",public"` // Redacted rectangle, possibly empty
+	Metadata         frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata     frontend.Variable     // MetadataDigest of the previous image
+	Prev             PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage          myImage.FrontendImage // z_in as a FrontendImage
+	RedactedImage_in myImage.FrontendImage // Redacted previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a redaction.
+func (circuit *RedactCircuit) Define(api frontend.API) error {
+	// Redact the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	redactPlanes(api, planes[:], circuit.Region)
+	redactedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.RedactedImage_in, &redactedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.RedactedImage_in)
+}
+
+// redactPlanes blackens the pixels of region in every plane, in place, exactly like image.I.Redact does outside
+// the circuit, and keeps every other pixel. It asserts region lies within the pixels of an image, or is empty.
+func redactPlanes(api frontend.API, planes []channelPlane, region CropParams) {
+	inRegion := regionIndicators(api, region, 0)
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				planes[c][y][x] = api.Select(inRegion[y][x], 0, planes[c][y][x])
+			}
+		}
+	}
+}
+
+// regionIndicators returns, for every pixel, 1 if it lies within region and 0 if not. It asserts region lies
+// within the pixels of an image, at least margin pixels from their edges, with its top left corner at most one
+// pixel past its bottom right corner, so that a region may be empty.
+func regionIndicators(api frontend.API, region CropParams, margin int) *channelPlane {
+	comparator := newLocationComparator(api)
+	comparator.AssertIsLessEq(margin, region.X0)
+	comparator.AssertIsLessEq(margin, region.Y0)
+	comparator.AssertIsLessEq(region.X0, api.Add(region.X1, 1))
+	comparator.AssertIsLessEq(region.Y0, api.Add(region.Y1, 1))
+	comparator.AssertIsLessEq(region.X1, myImage.Width-1-margin)
+	comparator.AssertIsLessEq(region.Y1, myImage.Height-1-margin)
+
+	// Each column and row is compared once, instead of once per pixel
+	var inWidth [myImage.Width]frontend.Variable
+	for x := range inWidth {
+		inWidth[x] = inRange(api, comparator, x, region.X0, region.X1)
+	}
+	var inHeight [myImage.Height]frontend.Variable
+	for y := range inHeight {
+		inHeight[y] = inRange(api, comparator, y, region.Y0, region.Y1)
+	}
+	var inRegion channelPlane
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			inRegion[y][x] = api.And(inWidth[x], inHeight[y])
+		}
+	}
+	return &inRegion
+}
@@ -0,0 +1,61 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts redactPlanes(In, Region) == Out, without the signature check of the RedactCircuit.
+type redactPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Region CropParams
+}
+
+func (circuit *redactPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	redactPlanes(api, planes[:], circuit.Region)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestRedactPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	for _, rect := range []myImage.Rect{{X0: 2, Y0: 1, X1: 5, Y1: 3}, {X0: 0, Y0: 0, X1: 0, Y1: 0}, {X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1}} {
+		out, err := in.Redact(rect)
+		assert.NoError(err)
+
+		region := CropParams{X0: rect.X0, Y0: rect.Y0, X1: rect.X1, Y1: rect.Y1}
+		assignment := redactPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Region: region}
+		assert.NoError(test.IsSolved(&redactPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "region %+v", rect)
+
+		// The image is not redacted outside of the region, nor kept inside of it
+		assignment.Region = CropParams{X0: rect.X0, Y0: rect.Y0, X1: rect.X1, Y1: rect.Y1 - 1}
+		assert.Error(test.IsSolved(&redactPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "region %+v", rect)
+		assignment.Region = CropParams{X0: rect.X0, Y0: rect.Y0, X1: rect.X1 + 1, Y1: rect.Y1}
+		if rect.X1+1 < myImage.Width {
+			assert.Error(test.IsSolved(&redactPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "region %+v", rect)
+		}
+	}
+
+	// An empty region keeps the image
+	for _, region := range []CropParams{{X0: 0, Y0: 0, X1: -1, Y1: -1}, {X0: 5, Y0: 3, X1: 4, Y1: 7}} {
+		assignment := redactPixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Region: region}
+		assert.NoError(test.IsSolved(&redactPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "region %+v", region)
+	}
+
+	// The region lies within the pixels of an image
+	black := myImage.I{}.ToFrontendImage()
+	for _, region := range []CropParams{{X0: -1, Y0: 0, X1: 2, Y1: 2}, {X0: 0, Y0: 0, X1: myImage.Width, Y1: 2}, {X0: 0, Y0: 0, X1: 2, Y1: myImage.Height}, {X0: 4, Y0: 0, X1: 2, Y1: 2}} {
+		assignment := redactPixelsCircuit{In: black, Out: black, Region: region}
+		assert.Error(test.IsSolved(&redactPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "region %+v", region)
+	}
+}
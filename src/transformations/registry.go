@@ -0,0 +1,738 @@
+package transformations
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+
+	myImage "src/image"
+)
+
+// An Edit is a type of permissible transformation that a compliance predicate of its own proves, e.g. a
+// Brightness, which the BrightnessCircuit proves. Edits are registered by their type, see Register, so that a
+// Transformation of the type is applied, and the Generator and the Prover assign its predicate, without a case
+// of their own for it.
+type Edit interface {
+	// Circuit returns the compliance predicate that proves the edit.
+	Circuit() CircuitID
+	// Params returns the public parameters of the predicate, in the order of its public fields after the
+	// Hops, or nil if it has none.
+	Params() []EditParam
+	// Apply returns the image the edit makes of img with params, a new image like Transformation.Apply returns.
+	Apply(img myImage.I, params map[string]int) (myImage.I, error)
+	// Assignment returns the assignment of the predicate that the signed image out is the image in
+	// transformed by t, which is either of the type of the edit or an Identity, see EditAssignment.
+	Assignment(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error)
+}
+
+// Edits of this build, by type of transformation.
+var edits = map[int]Edit{}
+
+// Register adds edit to the permissible transformations of this build as the type t, and its compliance
+// predicate to the predicates it proves and verifies. The predicate must bind the statement of an edit like
+// those of the built-in edits do, see assertSignedEdit. Register is meant to be called from an init function:
+// it panics if t is already a type of transformation, or the predicate of edit is already another's.
+func Register(t int, edit Edit) {
+	id := edit.Circuit()
+	if _, ok := edits[t]; ok || t == Identity || t == Crop {
+		panic(fmt.Sprintf("transformation type %d is already registered", t))
+	}
+	if _, ok := editOf(id); ok {
+		panic(fmt.Sprintf("circuit %s already proves a transformation", id))
+	}
+	if current, ok := circuits[id.Name]; ok && current != id {
+		panic(fmt.Sprintf("circuit %s is already registered as %s", id, current))
+	}
+
+	edits[t] = edit
+	if _, ok := circuits[id.Name]; !ok {
+		circuits[id.Name] = id
+		statementVersions[id.Name] = 1
+	}
+}
+
+// editOf returns the Edit whose compliance predicate is the circuit id, in any version.
+func editOf(id CircuitID) (Edit, bool) {
+	for _, edit := range edits {
+		if edit.Circuit().Name == id.Name {
+			return edit, true
+		}
+	}
+	return nil, false
+}
+
+// A composedEdit is an Edit whose compliance predicate is composed of the predicates of other edits, e.g. the
+// Edit of a Chain: the predicate of a Transformation, the public parameters of a proof and the assignment of
+// the predicate depend on the edits it composes, named by the Steps of its CircuitID.
+type composedEdit interface {
+	Edit
+	// circuitOf returns the predicate of the edit with params.
+	circuitOf(params map[string]int) (CircuitID, error)
+	// paramsOf returns the public parameters of the predicate id, like Edit.Params.
+	paramsOf(id CircuitID) []EditParam
+	// assignment returns the assignment of the predicate id, like Edit.Assignment.
+	assignment(id CircuitID, t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error)
+}
+
+// editFuncs is an Edit made of functions, which every built-in edit is.
+type editFuncs struct {
+	circuit CircuitID
+	params  []EditParam
+	apply   func(img myImage.I, params map[string]int) (myImage.I, error)
+	assign  func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error)
+}
+
+func (edit editFuncs) Circuit() CircuitID {
+	return edit.circuit
+}
+
+func (edit editFuncs) Params() []EditParam {
+	return edit.params
+}
+
+func (edit editFuncs) Apply(img myImage.I, params map[string]int) (myImage.I, error) {
+	return edit.apply(img, params)
+}
+
+func (edit editFuncs) Assignment(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+	return edit.assign(t, in, out, statement)
+}
+
+// The built-in edits.
+func init() {
+	Register(Rotate, editFuncs{
+		circuit: RotateCircuitID,
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.Rotate(params["quarters"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &RotateCircuit{
+				Statement:       statement.Statement,
+				PublicKey:       statement.PublicKey,
+				ImageSignature:  statement.ImageSignature,
+				Nonce:           statement.Nonce,
+				PrevProofHash:   statement.PrevProofHash,
+				Nullifier:       statement.Nullifier,
+				Hops:            statement.Hops,
+				ImageBytes:      statement.ImageBytes,
+				Metadata:        statement.Metadata,
+				PrevImageBytes:  statement.PrevImageBytes,
+				PrevMetadata:    statement.PrevMetadata,
+				Prev:            statement.Prev,
+				FrImage:         in.ToFrontendImage(),
+				RotatedImage_in: out.ToFrontendImage(),
+				Params:          RotateCircuitParams(in, t.Params["quarters"]),
+			}, nil
+		},
+	})
+	Register(FlipH, editFuncs{
+		circuit: FlipHCircuitID,
+		apply: func(img myImage.I, _ map[string]int) (myImage.I, error) {
+			return img.FlipH()
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &FlipHCircuit{
+				Statement:       statement.Statement,
+				PublicKey:       statement.PublicKey,
+				ImageSignature:  statement.ImageSignature,
+				Nonce:           statement.Nonce,
+				PrevProofHash:   statement.PrevProofHash,
+				Nullifier:       statement.Nullifier,
+				Hops:            statement.Hops,
+				ImageBytes:      statement.ImageBytes,
+				Metadata:        statement.Metadata,
+				PrevImageBytes:  statement.PrevImageBytes,
+				PrevMetadata:    statement.PrevMetadata,
+				Prev:            statement.Prev,
+				FrImage:         in.ToFrontendImage(),
+				FlippedImage_in: out.ToFrontendImage(),
+				Params:          FlipHCircuitParams(in, t.T == FlipH),
+			}, nil
+		},
+	})
+	Register(FlipV, editFuncs{
+		circuit: FlipVCircuitID,
+		apply: func(img myImage.I, _ map[string]int) (myImage.I, error) {
+			return img.FlipV()
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &FlipVCircuit{
+				Statement:       statement.Statement,
+				PublicKey:       statement.PublicKey,
+				ImageSignature:  statement.ImageSignature,
+				Nonce:           statement.Nonce,
+				PrevProofHash:   statement.PrevProofHash,
+				Nullifier:       statement.Nullifier,
+				Hops:            statement.Hops,
+				ImageBytes:      statement.ImageBytes,
+				Metadata:        statement.Metadata,
+				PrevImageBytes:  statement.PrevImageBytes,
+				PrevMetadata:    statement.PrevMetadata,
+				Prev:            statement.Prev,
+				FrImage:         in.ToFrontendImage(),
+				FlippedImage_in: out.ToFrontendImage(),
+				Params:          FlipVCircuitParams(in, t.T == FlipV),
+			}, nil
+		},
+	})
+	Register(Downscale, editFuncs{
+		circuit: DownscaleCircuitID,
+		apply: func(img myImage.I, _ map[string]int) (myImage.I, error) {
+			return img.Downscale()
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &DownscaleCircuit{
+				Statement:      statement.Statement,
+				PublicKey:      statement.PublicKey,
+				ImageSignature: statement.ImageSignature,
+				Nonce:          statement.Nonce,
+				PrevProofHash:  statement.PrevProofHash,
+				Nullifier:      statement.Nullifier,
+				Hops:           statement.Hops,
+				ImageBytes:     statement.ImageBytes,
+				Metadata:       statement.Metadata,
+				PrevImageBytes: statement.PrevImageBytes,
+				PrevMetadata:   statement.PrevMetadata,
+				Prev:           statement.Prev,
+				FrImage:        in.ToFrontendImage(),
+				ScaledImage_in: out.ToFrontendImage(),
+				Params:         DownscaleCircuitParams(in, t.T == Downscale),
+			}, nil
+		},
+	})
+	Register(RotateCrop, editFuncs{
+		circuit: RotateCropCircuitID,
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			rotated, err := img.Rotate(params["quarters"])
+			if err != nil {
+				return myImage.I{}, err
+			}
+			return rotated, rotated.Crop(params["x0"], params["y0"], params["x1"], params["y1"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := RotateCropCircuitParams(in, 0, 0, 0, in.M.Width-1, in.M.Height-1)
+			if t.T == RotateCrop {
+				params = RotateCropCircuitParams(in, t.Params["quarters"], t.Params["x0"], t.Params["y0"], t.Params["x1"], t.Params["y1"])
+			}
+			return &RotateCropCircuit{
+				Statement:       statement.Statement,
+				PublicKey:       statement.PublicKey,
+				ImageSignature:  statement.ImageSignature,
+				Nonce:           statement.Nonce,
+				PrevProofHash:   statement.PrevProofHash,
+				Nullifier:       statement.Nullifier,
+				Hops:            statement.Hops,
+				ImageBytes:      statement.ImageBytes,
+				Metadata:        statement.Metadata,
+				PrevImageBytes:  statement.PrevImageBytes,
+				PrevMetadata:    statement.PrevMetadata,
+				Prev:            statement.Prev,
+				FrImage:         in.ToFrontendImage(),
+				CroppedImage_in: out.ToFrontendImage(),
+				Params:          params,
+			}, nil
+		},
+	})
+	Register(Brightness, editFuncs{
+		circuit: BrightnessCircuitID,
+		params:  []EditParam{{Name: "delta", Identity: 0}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.Brighten(params["delta"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &BrightnessCircuit{
+				Statement:          statement.Statement,
+				PublicKey:          statement.PublicKey,
+				ImageSignature:     statement.ImageSignature,
+				Nonce:              statement.Nonce,
+				PrevProofHash:      statement.PrevProofHash,
+				Nullifier:          statement.Nullifier,
+				Hops:               statement.Hops,
+				Delta:              PublicParams(BrightnessCircuitID, t)["delta"],
+				ImageBytes:         statement.ImageBytes,
+				Metadata:           statement.Metadata,
+				PrevImageBytes:     statement.PrevImageBytes,
+				PrevMetadata:       statement.PrevMetadata,
+				Prev:               statement.Prev,
+				FrImage:            in.ToFrontendImage(),
+				BrightenedImage_in: out.ToFrontendImage(),
+				Params:             SizeCircuitParams(in),
+			}, nil
+		},
+	})
+	Register(Gamma, editFuncs{
+		circuit: GammaCircuitID,
+		params:  []EditParam{{Name: "gamma", Identity: 100}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.GammaCorrect(params["gamma"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &GammaCircuit{
+				Statement:         statement.Statement,
+				PublicKey:         statement.PublicKey,
+				ImageSignature:    statement.ImageSignature,
+				Nonce:             statement.Nonce,
+				PrevProofHash:     statement.PrevProofHash,
+				Nullifier:         statement.Nullifier,
+				Hops:              statement.Hops,
+				Gamma:             PublicParams(GammaCircuitID, t)["gamma"],
+				ImageBytes:        statement.ImageBytes,
+				Metadata:          statement.Metadata,
+				PrevImageBytes:    statement.PrevImageBytes,
+				PrevMetadata:      statement.PrevMetadata,
+				Prev:              statement.Prev,
+				FrImage:           in.ToFrontendImage(),
+				CorrectedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Sepia, editFuncs{
+		circuit: SepiaCircuitID,
+		apply: func(img myImage.I, _ map[string]int) (myImage.I, error) {
+			return img.Sepia(), nil
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &SepiaCircuit{
+				Statement:      statement.Statement,
+				PublicKey:      statement.PublicKey,
+				ImageSignature: statement.ImageSignature,
+				Nonce:          statement.Nonce,
+				PrevProofHash:  statement.PrevProofHash,
+				Nullifier:      statement.Nullifier,
+				Hops:           statement.Hops,
+				ImageBytes:     statement.ImageBytes,
+				Metadata:       statement.Metadata,
+				PrevImageBytes: statement.PrevImageBytes,
+				PrevMetadata:   statement.PrevMetadata,
+				Prev:           statement.Prev,
+				FrImage:        in.ToFrontendImage(),
+				TonedImage_in:  out.ToFrontendImage(),
+				Params:         SepiaCircuitParams(t.T == Sepia),
+			}, nil
+		},
+	})
+	Register(HueSaturation, editFuncs{
+		circuit: HueSaturationCircuitID,
+		params:  []EditParam{{Name: "hue", Identity: 0}, {Name: "saturation", Identity: 256}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.AdjustHueSaturation(params["hue"], params["saturation"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(HueSaturationCircuitID, t)
+			return &HueSaturationCircuit{
+				Statement:        statement.Statement,
+				PublicKey:        statement.PublicKey,
+				ImageSignature:   statement.ImageSignature,
+				Nonce:            statement.Nonce,
+				PrevProofHash:    statement.PrevProofHash,
+				Nullifier:        statement.Nullifier,
+				Hops:             statement.Hops,
+				Hue:              params["hue"],
+				Saturation:       params["saturation"],
+				ImageBytes:       statement.ImageBytes,
+				Metadata:         statement.Metadata,
+				PrevImageBytes:   statement.PrevImageBytes,
+				PrevMetadata:     statement.PrevMetadata,
+				Prev:             statement.Prev,
+				FrImage:          in.ToFrontendImage(),
+				AdjustedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Gain, editFuncs{
+		circuit: GainCircuitID,
+		params:  []EditParam{{Name: "gain_r", Identity: 256}, {Name: "gain_g", Identity: 256}, {Name: "gain_b", Identity: 256}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.ApplyGains([3]int{params["gain_r"], params["gain_g"], params["gain_b"]})
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(GainCircuitID, t)
+			return &GainCircuit{
+				Statement:        statement.Statement,
+				PublicKey:        statement.PublicKey,
+				ImageSignature:   statement.ImageSignature,
+				Nonce:            statement.Nonce,
+				PrevProofHash:    statement.PrevProofHash,
+				Nullifier:        statement.Nullifier,
+				Hops:             statement.Hops,
+				Gains:            [3]frontend.Variable{params["gain_r"], params["gain_g"], params["gain_b"]},
+				ImageBytes:       statement.ImageBytes,
+				Metadata:         statement.Metadata,
+				PrevImageBytes:   statement.PrevImageBytes,
+				PrevMetadata:     statement.PrevMetadata,
+				Prev:             statement.Prev,
+				FrImage:          in.ToFrontendImage(),
+				BalancedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Threshold, editFuncs{
+		circuit: ThresholdCircuitID,
+		params:  []EditParam{{Name: "threshold", Identity: keepThreshold}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.Binarize(params["threshold"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &ThresholdCircuit{
+				Statement:         statement.Statement,
+				PublicKey:         statement.PublicKey,
+				ImageSignature:    statement.ImageSignature,
+				Nonce:             statement.Nonce,
+				PrevProofHash:     statement.PrevProofHash,
+				Nullifier:         statement.Nullifier,
+				Hops:              statement.Hops,
+				Threshold:         PublicParams(ThresholdCircuitID, t)["threshold"],
+				ImageBytes:        statement.ImageBytes,
+				Metadata:          statement.Metadata,
+				PrevImageBytes:    statement.PrevImageBytes,
+				PrevMetadata:      statement.PrevMetadata,
+				Prev:              statement.Prev,
+				FrImage:           in.ToFrontendImage(),
+				BinarizedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(ChannelSwap, editFuncs{
+		circuit: ChannelSwapCircuitID,
+		params:  []EditParam{{Name: "source_r", Identity: 0}, {Name: "source_g", Identity: 1}, {Name: "source_b", Identity: 2}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.SwapChannels([3]int{params["source_r"], params["source_g"], params["source_b"]})
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(ChannelSwapCircuitID, t)
+			return &ChannelSwapCircuit{
+				Statement:       statement.Statement,
+				PublicKey:       statement.PublicKey,
+				ImageSignature:  statement.ImageSignature,
+				Nonce:           statement.Nonce,
+				PrevProofHash:   statement.PrevProofHash,
+				Nullifier:       statement.Nullifier,
+				Hops:            statement.Hops,
+				Sources:         [3]frontend.Variable{params["source_r"], params["source_g"], params["source_b"]},
+				ImageBytes:      statement.ImageBytes,
+				Metadata:        statement.Metadata,
+				PrevImageBytes:  statement.PrevImageBytes,
+				PrevMetadata:    statement.PrevMetadata,
+				Prev:            statement.Prev,
+				FrImage:         in.ToFrontendImage(),
+				SwappedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Redact, editFuncs{
+		circuit: RedactCircuitID,
+		params:  []EditParam{{Name: "x0", Identity: 0}, {Name: "y0", Identity: 0}, {Name: "x1", Identity: -1}, {Name: "y1", Identity: -1}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.Redact(myImage.Rect{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]})
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(RedactCircuitID, t)
+			return &RedactCircuit{
+				Statement:        statement.Statement,
+				PublicKey:        statement.PublicKey,
+				ImageSignature:   statement.ImageSignature,
+				Nonce:            statement.Nonce,
+				PrevProofHash:    statement.PrevProofHash,
+				Nullifier:        statement.Nullifier,
+				Hops:             statement.Hops,
+				Region:           CropParams{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]},
+				ImageBytes:       statement.ImageBytes,
+				Metadata:         statement.Metadata,
+				PrevImageBytes:   statement.PrevImageBytes,
+				PrevMetadata:     statement.PrevMetadata,
+				Prev:             statement.Prev,
+				FrImage:          in.ToFrontendImage(),
+				RedactedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Mosaic, editFuncs{
+		circuit: MosaicCircuitID,
+		params:  []EditParam{{Name: "x0", Identity: 0}, {Name: "y0", Identity: 0}, {Name: "x1", Identity: -1}, {Name: "y1", Identity: -1}, {Name: "block", Identity: myImage.MosaicBlocks[0]}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.Pixelate(myImage.Rect{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]}, params["block"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(MosaicCircuitID, t)
+			return &MosaicCircuit{
+				Statement:         statement.Statement,
+				PublicKey:         statement.PublicKey,
+				ImageSignature:    statement.ImageSignature,
+				Nonce:             statement.Nonce,
+				PrevProofHash:     statement.PrevProofHash,
+				Nullifier:         statement.Nullifier,
+				Hops:              statement.Hops,
+				Region:            CropParams{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]},
+				Block:             params["block"],
+				ImageBytes:        statement.ImageBytes,
+				Metadata:          statement.Metadata,
+				PrevImageBytes:    statement.PrevImageBytes,
+				PrevMetadata:      statement.PrevMetadata,
+				Prev:              statement.Prev,
+				FrImage:           in.ToFrontendImage(),
+				PixelatedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Blur, editFuncs{
+		circuit: BlurCircuitID,
+		params:  []EditParam{{Name: "x0", Identity: 1}, {Name: "y0", Identity: 1}, {Name: "x1", Identity: 0}, {Name: "y1", Identity: 0}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.BoxBlur(myImage.Rect{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]})
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(BlurCircuitID, t)
+			return &BlurCircuit{
+				Statement:       statement.Statement,
+				PublicKey:       statement.PublicKey,
+				ImageSignature:  statement.ImageSignature,
+				Nonce:           statement.Nonce,
+				PrevProofHash:   statement.PrevProofHash,
+				Nullifier:       statement.Nullifier,
+				Hops:            statement.Hops,
+				Region:          CropParams{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]},
+				ImageBytes:      statement.ImageBytes,
+				Metadata:        statement.Metadata,
+				PrevImageBytes:  statement.PrevImageBytes,
+				PrevMetadata:    statement.PrevMetadata,
+				Prev:            statement.Prev,
+				FrImage:         in.ToFrontendImage(),
+				BlurredImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Sharpen, editFuncs{
+		circuit: SharpenCircuitID,
+		params:  []EditParam{{Name: "x0", Identity: 1}, {Name: "y0", Identity: 1}, {Name: "x1", Identity: 0}, {Name: "y1", Identity: 0}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			return img.Sharpen(myImage.Rect{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]})
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(SharpenCircuitID, t)
+			return &SharpenCircuit{
+				Statement:         statement.Statement,
+				PublicKey:         statement.PublicKey,
+				ImageSignature:    statement.ImageSignature,
+				Nonce:             statement.Nonce,
+				PrevProofHash:     statement.PrevProofHash,
+				Nullifier:         statement.Nullifier,
+				Hops:              statement.Hops,
+				Region:            CropParams{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]},
+				ImageBytes:        statement.ImageBytes,
+				Metadata:          statement.Metadata,
+				PrevImageBytes:    statement.PrevImageBytes,
+				PrevMetadata:      statement.PrevMetadata,
+				Prev:              statement.Prev,
+				FrImage:           in.ToFrontendImage(),
+				SharpenedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Watermark, editFuncs{
+		circuit: WatermarkCircuitID,
+		params:  watermarkEditParams(),
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			watermark, err := watermarkOf(params)
+			if err != nil {
+				return myImage.I{}, err
+			}
+			return img.Stamp(&watermark, params["x"], params["y"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(WatermarkCircuitID, t)
+			circuit := &WatermarkCircuit{
+				Statement:       statement.Statement,
+				PublicKey:       statement.PublicKey,
+				ImageSignature:  statement.ImageSignature,
+				Nonce:           statement.Nonce,
+				PrevProofHash:   statement.PrevProofHash,
+				Nullifier:       statement.Nullifier,
+				Hops:            statement.Hops,
+				X:               params["x"],
+				Y:               params["y"],
+				ImageBytes:      statement.ImageBytes,
+				Metadata:        statement.Metadata,
+				PrevImageBytes:  statement.PrevImageBytes,
+				PrevMetadata:    statement.PrevMetadata,
+				Prev:            statement.Prev,
+				FrImage:         in.ToFrontendImage(),
+				StampedImage_in: out.ToFrontendImage(),
+			}
+			for k, param := range WatermarkCircuitID.EditParams()[2:] {
+				circuit.Watermark[k/myImage.WatermarkWidth][k%myImage.WatermarkWidth] = params[param.Name]
+			}
+			return circuit, nil
+		},
+	})
+	Register(Caption, editFuncs{
+		circuit: CaptionCircuitID,
+		params:  captionEditParams(),
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			caption, err := captionOf(params)
+			if err != nil {
+				return myImage.I{}, err
+			}
+			return img.AddCaption(&caption, params["y"])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(CaptionCircuitID, t)
+			circuit := &CaptionCircuit{
+				Statement:         statement.Statement,
+				PublicKey:         statement.PublicKey,
+				ImageSignature:    statement.ImageSignature,
+				Nonce:             statement.Nonce,
+				PrevProofHash:     statement.PrevProofHash,
+				Nullifier:         statement.Nullifier,
+				Hops:              statement.Hops,
+				Y:                 params["y"],
+				ImageBytes:        statement.ImageBytes,
+				Metadata:          statement.Metadata,
+				PrevImageBytes:    statement.PrevImageBytes,
+				PrevMetadata:      statement.PrevMetadata,
+				Prev:              statement.Prev,
+				FrImage:           in.ToFrontendImage(),
+				CaptionedImage_in: out.ToFrontendImage(),
+			}
+			for j, param := range CaptionCircuitID.EditParams()[1:] {
+				circuit.Caption[j] = params[param.Name]
+			}
+			return circuit, nil
+		},
+	})
+	Register(Border, editFuncs{
+		circuit: BorderCircuitID,
+		params:  []EditParam{{Name: "width", Identity: 0}, {Name: "r", Identity: 0}, {Name: "g", Identity: 0}, {Name: "b", Identity: 0}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			if params["r"] < 0 || params["r"] > 255 || params["g"] < 0 || params["g"] > 255 || params["b"] < 0 || params["b"] > 255 {
+				return myImage.I{}, fmt.Errorf("invalid border color (%d, %d, %d)", params["r"], params["g"], params["b"])
+			}
+			return img.AddBorder(params["width"], myImage.RGBPixel{R: uint8(params["r"]), G: uint8(params["g"]), B: uint8(params["b"])})
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(BorderCircuitID, t)
+			return &BorderCircuit{
+				Statement:        statement.Statement,
+				PublicKey:        statement.PublicKey,
+				ImageSignature:   statement.ImageSignature,
+				Nonce:            statement.Nonce,
+				PrevProofHash:    statement.PrevProofHash,
+				Nullifier:        statement.Nullifier,
+				Hops:             statement.Hops,
+				Width:            params["width"],
+				Color:            [3]frontend.Variable{params["r"], params["g"], params["b"]},
+				ImageBytes:       statement.ImageBytes,
+				Metadata:         statement.Metadata,
+				PrevImageBytes:   statement.PrevImageBytes,
+				PrevMetadata:     statement.PrevMetadata,
+				Prev:             statement.Prev,
+				FrImage:          in.ToFrontendImage(),
+				BorderedImage_in: out.ToFrontendImage(),
+				ImageWidth:       in.M.Width,
+				ImageHeight:      in.M.Height,
+			}, nil
+		},
+	})
+	Register(Convolution, editFuncs{
+		circuit: ConvolutionCircuitID,
+		params:  []EditParam{{Name: "x0", Identity: 1}, {Name: "y0", Identity: 1}, {Name: "x1", Identity: 0}, {Name: "y1", Identity: 0}, {Name: "kernel", Identity: 0}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			if params["kernel"] < 0 || params["kernel"] >= len(myImage.Kernels) {
+				return myImage.I{}, fmt.Errorf("invalid kernel %d: expected an index of the %d permissible kernels", params["kernel"], len(myImage.Kernels))
+			}
+			return img.Convolve(myImage.Rect{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]}, myImage.Kernels[params["kernel"]])
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params := PublicParams(ConvolutionCircuitID, t)
+			return &ConvolutionCircuit{
+				Statement:         statement.Statement,
+				PublicKey:         statement.PublicKey,
+				ImageSignature:    statement.ImageSignature,
+				Nonce:             statement.Nonce,
+				PrevProofHash:     statement.PrevProofHash,
+				Nullifier:         statement.Nullifier,
+				Hops:              statement.Hops,
+				Region:            CropParams{X0: params["x0"], Y0: params["y0"], X1: params["x1"], Y1: params["y1"]},
+				Kernel:            params["kernel"],
+				ImageBytes:        statement.ImageBytes,
+				Metadata:          statement.Metadata,
+				PrevImageBytes:    statement.PrevImageBytes,
+				PrevMetadata:      statement.PrevMetadata,
+				Prev:              statement.Prev,
+				FrImage:           in.ToFrontendImage(),
+				ConvolvedImage_in: out.ToFrontendImage(),
+			}, nil
+		},
+	})
+	Register(Policy, editFuncs{
+		circuit: PolicyCircuitID,
+		params:  []EditParam{{Name: "selector", Identity: Identity}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			edit, err := policyEdit(params)
+			if err != nil {
+				return myImage.I{}, err
+			}
+			return edit.Apply(img)
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			edit := Transformation{T: Identity}
+			if t.T == Policy {
+				var err error
+				if edit, err = policyEdit(t.Params); err != nil {
+					return nil, err
+				}
+			}
+			return &PolicyCircuit{
+				Statement:      statement.Statement,
+				PublicKey:      statement.PublicKey,
+				ImageSignature: statement.ImageSignature,
+				Nonce:          statement.Nonce,
+				PrevProofHash:  statement.PrevProofHash,
+				Nullifier:      statement.Nullifier,
+				Hops:           statement.Hops,
+				Selector:       edit.T,
+				ImageBytes:     statement.ImageBytes,
+				Metadata:       statement.Metadata,
+				PrevImageBytes: statement.PrevImageBytes,
+				PrevMetadata:   statement.PrevMetadata,
+				Prev:           statement.Prev,
+				FrImage:        in.ToFrontendImage(),
+				EditedImage_in: out.ToFrontendImage(),
+				Params:         PolicyCircuitParams(in, out, edit),
+			}, nil
+		},
+	})
+	Register(Chain, chainEdit{})
+	Register(Credit, editFuncs{
+		circuit: CreditCircuitID,
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			author, err := authorOf(params)
+			if err != nil {
+				return myImage.I{}, err
+			}
+			return img.Credit(author)
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			params, err := CreditCircuitParams(in, out)
+			if err != nil {
+				return nil, err
+			}
+			return &CreditCircuit{
+				Statement:        statement.Statement,
+				PublicKey:        statement.PublicKey,
+				ImageSignature:   statement.ImageSignature,
+				Nonce:            statement.Nonce,
+				PrevProofHash:    statement.PrevProofHash,
+				Nullifier:        statement.Nullifier,
+				Hops:             statement.Hops,
+				ImageBytes:       statement.ImageBytes,
+				Metadata:         statement.Metadata,
+				PrevImageBytes:   statement.PrevImageBytes,
+				PrevMetadata:     statement.PrevMetadata,
+				Prev:             statement.Prev,
+				FrImage:          in.ToFrontendImage(),
+				CreditedImage_in: out.ToFrontendImage(),
+				Params:           params,
+			}, nil
+		},
+	})
+}
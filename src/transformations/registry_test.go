@@ -0,0 +1,100 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// A type of transformation registered by the tests only, which inverts every channel of an image.
+const testInvert = 1000
+
+var testInvertCircuitID = CircuitID{Name: "testinvert", Version: 1}
+
+// Asserts Inverted_in is FrImage with every channel inverted. It binds no statement, which a registered edit
+// must, so that the test checks the registry only.
+type testInvertCircuit struct {
+	Level       frontend.Variable `gnark:",public"`
+	FrImage     myImage.FrontendImage
+	Inverted_in myImage.FrontendImage
+}
+
+func (circuit *testInvertCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.FrImage)
+	for c := range planes {
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				planes[c][y][x] = api.Sub(circuit.Level, planes[c][y][x])
+			}
+		}
+	}
+	inverted := fromChannelPlanes(&planes)
+	assertEqualImages(api, &inverted, &circuit.Inverted_in)
+	return nil
+}
+
+func init() {
+	Register(testInvert, editFuncs{
+		circuit: testInvertCircuitID,
+		params:  []EditParam{{Name: "level", Identity: 255}},
+		apply: func(img myImage.I, params map[string]int) (myImage.I, error) {
+			inverted := img.Clone()
+			inverted.Map(func(_, _ int, p myImage.RGBPixel) myImage.RGBPixel {
+				return myImage.RGBPixel{R: uint8(params["level"]) - p.R, G: uint8(params["level"]) - p.G, B: uint8(params["level"]) - p.B}
+			})
+			return inverted, nil
+		},
+		assign: func(t Transformation, in, out myImage.I, statement EditStatement) (frontend.Circuit, error) {
+			return &testInvertCircuit{Level: PublicParams(testInvertCircuitID, t)["level"], FrImage: in.ToFrontendImage(), Inverted_in: out.ToFrontendImage()}, nil
+		},
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Every type of transformation but the crops is an edit of this build
+	for T := Rotate; T <= Convolution; T++ {
+		edit, ok := edits[T]
+		assert.True(ok, "type %d", T)
+		id, err := Transformation{T: T}.Circuit()
+		assert.NoError(err)
+		assert.Equal(edit.Circuit(), id)
+		assert.NoError(CheckProvable(id))
+		assert.True(id.BindsStatement(), "circuit %s", id)
+	}
+
+	// A registered edit is applied, proven and verified like a built-in one
+	in := myImage.NoiseImage(testSeed)
+	transformation := Transformation{T: testInvert, Params: map[string]int{"level": 255}}
+	out, err := transformation.Apply(in)
+	assert.NoError(err)
+	assert.Equal(255-in.Pixels[3][4].G, out.Pixels[3][4].G)
+
+	id, err := transformation.Circuit()
+	assert.NoError(err)
+	assert.Equal(testInvertCircuitID, id)
+	assert.NoError(CheckProvable(id))
+	assert.True(id.BindsStatement())
+	assert.Equal(map[string]int{"level": 255}, PublicParams(id, Transformation{T: Identity}))
+
+	assignment, err := EditAssignment(id, transformation, in, out, EditStatement{})
+	assert.NoError(err)
+	assert.NoError(test.IsSolved(&testInvertCircuit{}, assignment, ecc.BN254.ScalarField()))
+	if _, err := EditAssignment(id, Transformation{T: Rotate}, in, out, EditStatement{}); err == nil {
+		t.Error("a rotation was assigned to the predicate of another edit")
+	}
+	if _, err := EditAssignment(CropCircuitID, transformation, in, out, EditStatement{}); err == nil {
+		t.Error("an edit was assigned to the CropCircuit")
+	}
+
+	// A type, or a predicate, is registered once
+	assert.Panics(func() { Register(testInvert, editFuncs{circuit: CircuitID{Name: "other", Version: 1}}) })
+	assert.Panics(func() { Register(testInvert+1, editFuncs{circuit: RotateCircuitID}) })
+	assert.Panics(func() { Register(Crop, editFuncs{circuit: CircuitID{Name: "other", Version: 1}}) })
+	assert.Panics(func() { Register(testInvert+1, editFuncs{circuit: CircuitID{Name: CropCircuitID.Name, Version: 1}}) })
+}
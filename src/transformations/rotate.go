@@ -0,0 +1,140 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Rotate transformations: the signed image is the image FrImage, of the Width and
+// Height of Params, rotated clockwise by the Quarters of Params, like image.I.Rotate does. It has the public
+// fields of the CropCircuit, so its proofs are verified and chained like the proofs of a crop.
+// Public fields: Statement
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, RotatedImage_in, Params
+type RotateCircuit struct {
+	Statement       frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey       // Key the image is signed with
+	ImageSignature  eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable     // Digest of the signed image, RotatedImage_in
+	PrevImageBytes  frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Metadata        frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable     // MetadataDigest of the previous image
+	Prev            PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage         myImage.FrontendImage // z_in as a FrontendImage
+	RotatedImage_in myImage.FrontendImage // Rotated previous image as a FrontendImage
+	Params          RotateParams          // Rotate transformation parameters
+}
+
+// Parameters of a rotation: the number of clockwise quarter turns, 0 to 3, and the size of the image before
+// the rotation, every pixel outside it black.
+type RotateParams struct {
+	Quarters frontend.Variable
+	Width    frontend.Variable
+	Height   frontend.Variable
+}
+
+// Defines the Compliance Predicate of a rotation by a right angle.
+func (circuit *RotateCircuit) Define(api frontend.API) error {
+	// Rotate the FrImage
+	rotatedImage_out := rotateFrontendImage(api, &circuit.FrImage, circuit.Params)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.RotatedImage_in, &rotatedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.RotatedImage_in)
+}
+
+// rotateFrontendImage rotates the image of the size of params clockwise by the quarter turns of params, with
+// the rotated image in the top left corner and every other pixel black, exactly like image.I.Rotate does
+// outside the circuit.
+//
+// Pixel locations inside a circuit must be constants, and the rotation of an image depends on its size. So the
+// whole Width x Height pixels are rotated instead, by constant locations, the four rotations selected by an
+// indicator of the quarter turns, and the rotated image is then cropped from wherever the rotation of the whole
+// pixels left it: past the bottom right corner of the image for a half turn, past its right edge for a quarter
+// turn and past its bottom edge for three quarter turns. A quarter turn swaps the width and height, which
+// the crop asserts fit the pixels of an image.
+func rotateFrontendImage(api frontend.API, img *myImage.FrontendImage, params RotateParams) myImage.FrontendImage {
+	planes := channelPlanes(img)
+	cropPlanes(api, planes[:], rotatePlanes(api, &planes, params))
+
+	return fromChannelPlanes(&planes)
+}
+
+// rotatePlanes rotates the whole pixels of the planes of an image of the size of params, in place, clockwise by
+// the quarter turns of params, like rotateFrontendImage, and returns the area the rotated image lies in, for
+// cropPlanes to translate to the top left corner.
+func rotatePlanes(api frontend.API, planes *[3]channelPlane, params RotateParams) CropParams {
+	comparator := newLocationComparator(api)
+	isQuarters := offsetIndicators(api, params.Quarters, 4)
+
+	// The image lies within the pixels, every pixel outside it black, so its rotation is all the rotation has
+	comparator.AssertIsLessEq(1, params.Width)
+	comparator.AssertIsLessEq(1, params.Height)
+	comparator.AssertIsLessEq(params.Width, myImage.Width)
+	comparator.AssertIsLessEq(params.Height, myImage.Height)
+	var inWidth [myImage.Width]frontend.Variable
+	for x := range inWidth {
+		inWidth[x] = comparator.IsLess(x, params.Width)
+	}
+	var inHeight [myImage.Height]frontend.Variable
+	for y := range inHeight {
+		inHeight[y] = comparator.IsLess(y, params.Height)
+	}
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			outside := api.Sub(1, api.And(inWidth[x], inHeight[y]))
+			for c := range planes {
+				api.AssertIsEqual(api.Mul(outside, planes[c][y][x]), 0)
+			}
+		}
+	}
+
+	// Rotate the whole pixels, by each number of quarter turns, and select the rotation of params. Width is
+	// larger than Height, so a quarter turn keeps Height of the Width columns, the last ones for one quarter
+	// turn and the first ones for three.
+	for c := range planes {
+		var rotated channelPlane
+		for y := 0; y < myImage.Height; y++ {
+			for x := 0; x < myImage.Width; x++ {
+				terms := []frontend.Variable{
+					api.Mul(isQuarters[0], planes[c][y][x]),
+					api.Mul(isQuarters[2], planes[c][myImage.Height-1-y][myImage.Width-1-x]),
+				}
+				if x >= myImage.Width-myImage.Height {
+					terms = append(terms, api.Mul(isQuarters[1], planes[c][myImage.Width-1-x][y]))
+				}
+				if x < myImage.Height {
+					terms = append(terms, api.Mul(isQuarters[3], planes[c][x][myImage.Height-1-y]))
+				}
+				rotated[y][x] = api.Add(terms[0], terms[1], terms[2:]...)
+			}
+		}
+		planes[c] = rotated
+	}
+
+	// The rotated image, of the size of the image with the width and height swapped by an odd number of
+	// quarter turns, from where the rotation of the whole pixels left it
+	odd := api.Add(isQuarters[1], isQuarters[3])
+	width := api.Add(params.Width, api.Mul(odd, api.Sub(params.Height, params.Width)))
+	height := api.Add(params.Height, api.Mul(odd, api.Sub(params.Width, params.Height)))
+	x0 := api.Add(
+		api.Mul(isQuarters[1], api.Sub(myImage.Width, params.Height)),
+		api.Mul(isQuarters[2], api.Sub(myImage.Width, params.Width)),
+	)
+	y0 := api.Add(
+		api.Mul(isQuarters[2], api.Sub(myImage.Height, params.Height)),
+		api.Mul(isQuarters[3], api.Sub(myImage.Height, params.Width)),
+	)
+	return CropParams{X0: x0, Y0: y0, X1: api.Sub(api.Add(x0, width), 1), Y1: api.Sub(api.Add(y0, height), 1)}
+}
+
+// RotateCircuitParams returns the parameters of the RotateCircuit for rotating img clockwise by quarters
+// quarter turns, any number of them like image.I.Rotate takes.
+func RotateCircuitParams(img myImage.I, quarters int) RotateParams {
+	return RotateParams{Quarters: ((quarters % 4) + 4) % 4, Width: img.M.Width, Height: img.M.Height}
+}
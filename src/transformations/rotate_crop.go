@@ -0,0 +1,87 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for RotateCrop transformations: the signed image is the image FrImage rotated by the
+// Rotate parameters of Params, like the RotateCircuit, then cropped to the area of its Crop parameters, like
+// the CropCircuit, in a single proof instead of two chained ones. It has the public fields of the
+// CropCircuit, so its proofs are verified and chained like the proofs of a crop.
+// Public fields: Statement
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, CroppedImage_in, Params
+type RotateCropCircuit struct {
+	Statement       frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey       // Key the image is signed with
+	ImageSignature  eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable     // Digest of the signed image, CroppedImage_in
+	PrevImageBytes  frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Metadata        frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable     // MetadataDigest of the previous image
+	Prev            PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage         myImage.FrontendImage // z_in as a FrontendImage
+	CroppedImage_in myImage.FrontendImage // Rotated and cropped previous image as a FrontendImage
+	Params          RotateCropParams      // RotateCrop transformation parameters
+}
+
+// Parameters of a rotation followed by a crop: the rotation of the image, and the area of the rotated image to
+// keep, in the locations of the rotated image.
+type RotateCropParams struct {
+	Rotate RotateParams
+	Crop   CropParams
+}
+
+// Defines the Compliance Predicate of a rotation by a right angle followed by a crop.
+func (circuit *RotateCropCircuit) Define(api frontend.API) error {
+	// Rotate and crop the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	rotateCropPlanes(api, &planes, circuit.Params)
+	croppedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.CroppedImage_in, &croppedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.CroppedImage_in)
+}
+
+// rotateCropPlanes rotates the planes of an image, in place, and crops the rotated image, exactly like
+// image.I.Rotate followed by image.I.Crop do outside the circuit. It returns the area the rotated image lay in
+// before the crop, like rotatePlanes.
+//
+// The rotation of the whole pixels leaves the rotated image in an area away from the top left corner, which
+// rotateFrontendImage crops. The crop of the rotated image is an area within that one, so a single crop of the
+// area moved by the corner of the rotated image does both, and costs no more translations than a rotation.
+func rotateCropPlanes(api frontend.API, planes *[3]channelPlane, params RotateCropParams) CropParams {
+	comparator := newLocationComparator(api)
+	rotated := rotatePlanes(api, planes, params.Rotate)
+
+	// The crop area lies within the rotated image, which cropPlanes asserts is a valid area
+	area := CropParams{
+		X0: api.Add(rotated.X0, params.Crop.X0),
+		Y0: api.Add(rotated.Y0, params.Crop.Y0),
+		X1: api.Add(rotated.X0, params.Crop.X1),
+		Y1: api.Add(rotated.Y0, params.Crop.Y1),
+	}
+	comparator.AssertIsLessEq(0, params.Crop.X0)
+	comparator.AssertIsLessEq(0, params.Crop.Y0)
+	comparator.AssertIsLessEq(area.X1, rotated.X1)
+	comparator.AssertIsLessEq(area.Y1, rotated.Y1)
+	cropPlanes(api, planes[:], area)
+	return rotated
+}
+
+// RotateCropCircuitParams returns the parameters of the RotateCropCircuit for rotating img clockwise by quarters
+// quarter turns, any number of them like image.I.Rotate takes, and cropping the rotated image to the area
+// {(x0, y0), (x1, y1)}.
+func RotateCropCircuitParams(img myImage.I, quarters, x0, y0, x1, y1 int) RotateCropParams {
+	return RotateCropParams{
+		Rotate: RotateCircuitParams(img, quarters),
+		Crop:   CropParams{X0: x0, Y0: y0, X1: x1, Y1: y1},
+	}
+}
@@ -0,0 +1,67 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts rotateCropPlanes(In, Params) == Out, without the signature check of the RotateCropCircuit.
+type rotateCropPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Params RotateCropParams
+}
+
+func (circuit *rotateCropPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	rotateCropPlanes(api, &planes, circuit.Params)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestRotateCropPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// A 10 x 6 image, 6 x 10 when turned by a quarter, cropped to a corner of 3 x 4 pixels of the rotated image
+	in, err := myImage.CoordinateImage().SubImage(4, 3, 13, 8)
+	assert.NoError(err)
+	for quarters := 0; quarters < 4; quarters++ {
+		apply := Transformation{T: RotateCrop, Params: map[string]int{"quarters": quarters, "x0": 2, "y0": 1, "x1": 4, "y1": 4}}
+		out, err := apply.Apply(in)
+		assert.NoError(err)
+		if out.M.Width != 3 || out.M.Height != 4 {
+			t.Fatalf("a %d x %d crop by %d quarter turns", out.M.Width, out.M.Height, quarters)
+		}
+
+		params := RotateCropCircuitParams(in, quarters, 2, 1, 4, 4)
+		assignment := rotateCropPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: params}
+		assert.NoError(test.IsSolved(&rotateCropPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d quarters", quarters)
+
+		// Another crop of the rotated image is not the crop
+		params.Crop.X0, params.Crop.X1 = 1, 3
+		assignment.Params = params
+		assert.Error(test.IsSolved(&rotateCropPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%d quarters", quarters)
+	}
+
+	// Without a rotation and with a crop of the whole image, the image is kept
+	kept := rotateCropPixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Params: RotateCropCircuitParams(in, 0, 0, 0, 9, 5)}
+	assert.NoError(test.IsSolved(&rotateCropPixelsCircuit{}, &kept, ecc.BN254.ScalarField()))
+
+	// The crop area lies within the rotated image, not just within the pixels
+	var black myImage.I
+	for name, params := range map[string]RotateCropParams{
+		"past the right edge":  RotateCropCircuitParams(in, 1, 6, 0, 6, 0),
+		"past the bottom edge": RotateCropCircuitParams(in, 0, 0, 6, 0, 6),
+		"before the left edge": RotateCropCircuitParams(in, 2, -1, 0, -1, 0),
+		"before the top edge":  RotateCropCircuitParams(in, 2, 0, -1, 0, -1),
+	} {
+		assignment := rotateCropPixelsCircuit{In: in.ToFrontendImage(), Out: black.ToFrontendImage(), Params: params}
+		assert.Error(test.IsSolved(&rotateCropPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+}
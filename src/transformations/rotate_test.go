@@ -0,0 +1,79 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts rotateFrontendImage(In, Params) == Out, without the signature check of the RotateCircuit.
+type rotatePixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Params RotateParams
+}
+
+func (circuit *rotatePixelsCircuit) Define(api frontend.API) error {
+	out := rotateFrontendImage(api, &circuit.In, circuit.Params)
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			api.AssertIsEqual(out.Pixels[y][x].R, circuit.Out.Pixels[y][x].R)
+			api.AssertIsEqual(out.Pixels[y][x].G, circuit.Out.Pixels[y][x].G)
+			api.AssertIsEqual(out.Pixels[y][x].B, circuit.Out.Pixels[y][x].B)
+		}
+	}
+	return nil
+}
+
+func TestRotateFrontendImage(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, c := range []struct{ width, height int }{
+		{myImage.Height, myImage.Height}, // the largest square
+		{9, 5},
+		{1, 1},
+		{myImage.Height, 1},
+		{1, myImage.Height},
+	} {
+		in, err := myImage.CoordinateImage().SubImage(2, 0, 2+c.width-1, c.height-1)
+		assert.NoError(err)
+		for quarters := 0; quarters < 4; quarters++ {
+			out, err := in.Rotate(quarters)
+			assert.NoError(err)
+
+			assignment := rotatePixelsCircuit{
+				In:     in.ToFrontendImage(),
+				Out:    out.ToFrontendImage(),
+				Params: RotateCircuitParams(in, quarters),
+			}
+			assert.NoError(test.IsSolved(&rotatePixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%v by %d", c, quarters)
+
+			// Another rotation is not the rotation
+			if c.width > 1 || c.height > 1 {
+				other, err := in.Rotate(quarters + 2)
+				assert.NoError(err)
+				assignment.Out = other.ToFrontendImage()
+				assert.Error(test.IsSolved(&rotatePixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "%v by %d", c, quarters)
+			}
+		}
+	}
+
+	// The whole landscape image turns by half turns only, and an image cannot hide pixels outside its size
+	in := myImage.NoiseImage(testSeed)
+	out, err := in.Rotate(2)
+	assert.NoError(err)
+	assert.NoError(test.IsSolved(&rotatePixelsCircuit{}, &rotatePixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: RotateCircuitParams(in, 2)}, ecc.BN254.ScalarField()))
+	for name, params := range map[string]RotateParams{
+		"quarter turn":  {Quarters: 1, Width: myImage.Width, Height: myImage.Height},
+		"five quarters": {Quarters: 5, Width: myImage.Width, Height: myImage.Height},
+		"smaller":       {Quarters: 2, Width: myImage.Width - 1, Height: myImage.Height},
+		"larger":        {Quarters: 2, Width: myImage.Width + 1, Height: myImage.Height},
+	} {
+		assignment := rotatePixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Params: params}
+		assert.Error(test.IsSolved(&rotatePixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+}
@@ -0,0 +1,77 @@
+package transformations
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// This circuit is only for Sepia transformations: the signed image is the image FrImage with every pixel
+// mapped by the image.SepiaMatrix, like image.I.Sepia does, or FrImage itself when Params does not Tone. The
+// matrix is a constant of the predicate, so the keys of a Sepia prove that one tone only. It has the public
+// fields of the CropCircuit, so its proofs are verified and chained like the proofs of a crop.
+// Public fields: Statement
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, TonedImage_in, Params
+type SepiaCircuit struct {
+	Statement      frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey      eddsa.PublicKey       // Key the image is signed with
+	ImageSignature eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce          frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash  frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier      frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops           frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes     frontend.Variable     // Digest of the signed image, TonedImage_in
+	PrevImageBytes frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Metadata       frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata   frontend.Variable     // MetadataDigest of the previous image
+	Prev           PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage        myImage.FrontendImage // z_in as a FrontendImage
+	TonedImage_in  myImage.FrontendImage // Sepia toned previous image as a FrontendImage
+	Params         SepiaParams           // Sepia transformation parameters
+}
+
+// Parameters of a sepia tone: 1 to tone the image, 0 to keep it.
+type SepiaParams struct {
+	Tone frontend.Variable
+}
+
+// Defines the Compliance Predicate of a sepia tone.
+func (circuit *SepiaCircuit) Define(api frontend.API) error {
+	// Tone the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	if err := sepiaPlanes(api, planes[:], circuit.Params); err != nil {
+		return err
+	}
+	tonedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.TonedImage_in, &tonedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.TonedImage_in)
+}
+
+// sepiaPlanes maps every pixel of the planes by the image.SepiaMatrix when params Tone, or by the matrix that
+// keeps every pixel when not, in place, exactly like image.I.Sepia does outside the circuit.
+func sepiaPlanes(api frontend.API, planes []channelPlane, params SepiaParams) error {
+	api.AssertIsBoolean(params.Tone)
+
+	var matrix [3][3]frontend.Variable
+	for c := range matrix {
+		for k := range matrix[c] {
+			identity := 0
+			if c == k {
+				identity = 1 << myImage.ColorMatrixBits
+			}
+			matrix[c][k] = api.Add(identity, api.Mul(params.Tone, myImage.SepiaMatrix[c][k]-identity))
+		}
+	}
+	return colorMatrixPlanes(api, planes, &matrix)
+}
+
+// SepiaCircuitParams returns the parameters of the SepiaCircuit, toned when tone is set.
+func SepiaCircuitParams(tone bool) SepiaParams {
+	if tone {
+		return SepiaParams{Tone: 1}
+	}
+	return SepiaParams{Tone: 0}
+}
@@ -0,0 +1,85 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts sepiaPlanes(In, Params) == Out, without the signature check of the SepiaCircuit.
+type sepiaPixelsCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Params SepiaParams
+}
+
+func (circuit *sepiaPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := sepiaPlanes(api, planes[:], circuit.Params); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestSepiaPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	toned := in.Sepia()
+	for name, c := range map[string]struct {
+		out  myImage.I
+		tone bool
+	}{"toned": {toned, true}, "kept": {in, false}} {
+		assignment := sepiaPixelsCircuit{In: in.ToFrontendImage(), Out: c.out.ToFrontendImage(), Params: SepiaCircuitParams(c.tone)}
+		assert.NoError(test.IsSolved(&sepiaPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+
+		assignment.Params = SepiaCircuitParams(!c.tone)
+		assert.Error(test.IsSolved(&sepiaPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// Only the tone of the predicate, or none
+	assignment := sepiaPixelsCircuit{In: in.ToFrontendImage(), Out: toned.ToFrontendImage(), Params: SepiaParams{Tone: 2}}
+	assert.Error(test.IsSolved(&sepiaPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()))
+}
+
+// Asserts colorMatrixPlanes(In, Matrix) == Out.
+type colorMatrixCircuit struct {
+	In     myImage.FrontendImage
+	Out    myImage.FrontendImage
+	Matrix [3][3]frontend.Variable
+}
+
+func (circuit *colorMatrixCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	if err := colorMatrixPlanes(api, planes[:], &circuit.Matrix); err != nil {
+		return err
+	}
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+// Negative weights make negative sums, which are clamped to black like outside the circuit.
+func TestColorMatrixPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	matrix := myImage.ColorMatrix{{131072, -32768, -32768}, {-32768, 131072, -32768}, {-32768, -32768, 131072}}
+	var assignment colorMatrixCircuit
+	for c := range matrix {
+		for k := range matrix[c] {
+			assignment.Matrix[c][k] = matrix[c][k]
+		}
+	}
+	assignment.In, assignment.Out = in.ToFrontendImage(), in.ApplyColorMatrix(&matrix).ToFrontendImage()
+	assert.NoError(test.IsSolved(&colorMatrixCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	assignment.Out = in.ToFrontendImage()
+	assert.Error(test.IsSolved(&colorMatrixCircuit{}, &assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,188 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// A Norm measures the distance between two images, over the differences of their color channels.
+type Norm int
+
+const (
+	L1 Norm = 1 // sum of the absolute differences
+	L2 Norm = 2 // sum of the squared differences, i.e. the squared euclidean distance
+)
+
+func (norm Norm) String() string {
+	switch norm {
+	case L1:
+		return "L1"
+	case L2:
+		return "L2"
+	}
+	return fmt.Sprintf("Norm(%d)", int(norm))
+}
+
+// ParseNorm returns the Norm named "L1" or "L2".
+func ParseNorm(name string) (Norm, error) {
+	switch name {
+	case "L1", "l1":
+		return L1, nil
+	case "L2", "l2":
+		return L2, nil
+	}
+	return 0, fmt.Errorf("unknown norm %q: expected L1 or L2", name)
+}
+
+// Number of bits of a distance bound. The largest distance between two images, 3*Width*Height*255*255 in
+// L2, is well below 2^distanceBits, so that a bound of distanceBits bits can be compared with a distance by
+// range checking their difference.
+const distanceBits = 32
+
+// This circuit proves that the Published image is within a Bound of the Original signed by the camera, in the
+// public Norm. Mild recompression or resampling changes a few channels a little, which the camera's signature
+// alone cannot tolerate: the similarity proof accepts the published image under an explicit budget the
+// verifier can judge, while the original stays secret.
+//
+// The published image is bound through a single public input, its ImageDigest (see RegionDigest), which the
+// verifier recomputes from the published image.
+//
+// The Original is packed (see image.I.Pack). The signed ImageBytes are its digest, which the circuit recomputes
+// from the packed elements and Metadata, so that the distance is measured from the pixels the camera signed.
+//
+// Public fields: PublicKey, Nonce, ImageDigest, Norm, Bound
+// Secret fields: ImageSignature, ImageBytes, Metadata, Original, Published
+type SimilarityCircuit struct {
+	PublicKey      eddsa.PublicKey        `gnark:",public"` // the camera's public key
+	Nonce          frontend.Variable      `gnark:",public"` // capture counter of the original, see image.Statement
+	ImageDigest    frontend.Variable      `gnark:",public"` // RegionDigest of the published image
+	Norm           frontend.Variable      `gnark:",public"` // L1 or L2
+	Bound          frontend.Variable      `gnark:",public"` // largest accepted Distance, below 2^distanceBits
+	ImageSignature eddsa.Signature        // the camera's signature over the original
+	ImageBytes     frontend.Variable      // Digest of the original
+	Metadata       frontend.Variable      // MetadataDigest of the original
+	Original       myImage.FrontendPacked // original as a FrontendPacked
+	Published      myImage.FrontendImage  // published image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a bounded distance between the published image and the original.
+func (circuit *SimilarityCircuit) Define(api frontend.API) error {
+	// Differences of channels are only in the range of the absTable for 8 bit values, which unpacking the
+	// original checks
+	unpacked, err := unpackImage(api, &circuit.Original)
+	if err != nil {
+		return err
+	}
+	original := regionChannels(&unpacked)
+	published := regionChannels(&circuit.Published)
+	assertChannels(api, published...)
+
+	differences := make([]frontend.Variable, len(original))
+	var l2 frontend.Variable = 0
+	for i := range original {
+		differences[i] = api.Sub(original[i], published[i])
+		l2 = api.Add(l2, api.Mul(differences[i], differences[i]))
+	}
+	var l1 frontend.Variable = 0
+	for _, abs := range newAbsTable(api).abs(differences...) {
+		l1 = api.Add(l1, abs)
+	}
+
+	// The Norm is L1 or L2, and selects its distance
+	api.AssertIsEqual(api.Mul(api.Sub(circuit.Norm, int(L1)), api.Sub(circuit.Norm, int(L2))), 0)
+	distance := api.Add(l1, api.Mul(api.Sub(circuit.Norm, int(L1)), api.Sub(l2, l1)))
+
+	// distance <= Bound: both are below 2^distanceBits, so their difference is too if and only if it is not
+	// negative
+	rangeChecker := rangecheck.New(api)
+	rangeChecker.Check(circuit.Bound, distanceBits)
+	rangeChecker.Check(api.Sub(circuit.Bound, distance), distanceBits)
+
+	if err := assertPublicDigest(api, circuit.ImageDigest, packChannels(api, published)...); err != nil {
+		return err
+	}
+
+	// The signed ImageBytes are the digest of the original
+	if err := assertPackedDigest(api, circuit.ImageBytes, circuit.Metadata, circuit.Original.Elements[:]); err != nil {
+		return err
+	}
+
+	// Verify the camera's signature over the original, which has no previous proof
+	return assertSignedStatement(api, circuit.PublicKey, circuit.ImageSignature, circuit.ImageBytes, circuit.Nonce, 0)
+}
+
+// Distance returns the distance between the images a and b in the given norm, as the SimilarityCircuit
+// computes it.
+func Distance(a, b myImage.I, norm Norm) (*big.Int, error) {
+	if norm != L1 && norm != L2 {
+		return nil, fmt.Errorf("unknown norm %d: expected L1 or L2", int(norm))
+	}
+
+	var distance int64
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			pa, pb := a.Pixels[y][x], b.Pixels[y][x]
+			for _, d := range []int64{int64(pa.R) - int64(pb.R), int64(pa.G) - int64(pb.G), int64(pa.B) - int64(pb.B)} {
+				if norm == L1 {
+					distance += max(d, -d)
+				} else {
+					distance += d * d
+				}
+			}
+		}
+	}
+	return big.NewInt(distance), nil
+}
+
+// checkBound returns an error if bound cannot be proven by the SimilarityCircuit.
+func checkBound(bound *big.Int) error {
+	if bound == nil || bound.Sign() < 0 || bound.BitLen() > distanceBits {
+		return fmt.Errorf("invalid distance bound %v: expected a value in [0, 2^%d)", bound, distanceBits)
+	}
+	return nil
+}
+
+// SimilarityWitness returns the public witness of a proof that published is within bound, in norm, of a
+// picture the camera with publicKey took as capture nonce. The verifier builds it from the published image
+// itself.
+func SimilarityWitness(publicKey []byte, nonce *big.Int, published myImage.I, norm Norm, bound *big.Int) (witness.Witness, error) {
+	if norm != L1 && norm != L2 {
+		return nil, fmt.Errorf("unknown norm %d: expected L1 or L2", int(norm))
+	}
+	if err := checkBound(bound); err != nil {
+		return nil, err
+	}
+
+	var assignment SimilarityCircuit
+	assignment.PublicKey.Assign(1, publicKey)
+	assignment.Nonce = nonce
+	assignment.ImageDigest = RegionDigest(published)
+	assignment.Norm = int(norm)
+	assignment.Bound = bound
+
+	return frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
+
+// CheckSimilarity returns an error if published is farther than bound, in norm, from original, i.e. if the
+// SimilarityCircuit cannot be proven for them.
+func CheckSimilarity(original, published myImage.I, norm Norm, bound *big.Int) error {
+	if err := checkBound(bound); err != nil {
+		return err
+	}
+	distance, err := Distance(original, published, norm)
+	if err != nil {
+		return err
+	}
+	if distance.Cmp(bound) > 0 {
+		return fmt.Errorf("the published image is at %s distance %v of the original, above the bound %v", norm, distance, bound)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package transformations
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+func TestDistance(t *testing.T) {
+	a := myImage.CoordinateImage()
+	b := myImage.CoordinateImage()
+	b.SetPixel(0, 0, myImage.RGBPixel{R: a.Pixels[0][0].R + 3, G: a.Pixels[0][0].G, B: a.Pixels[0][0].B})
+	b.SetPixel(1, 0, myImage.RGBPixel{R: a.Pixels[0][1].R, G: a.Pixels[0][1].G + 4, B: a.Pixels[0][1].B})
+
+	for _, c := range []struct {
+		norm Norm
+		want int64
+	}{
+		{L1, 7},
+		{L2, 25},
+	} {
+		distance, err := Distance(a, b, c.norm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if distance.Cmp(big.NewInt(c.want)) != 0 {
+			t.Errorf("%s distance is %v, expected %d", c.norm, distance, c.want)
+		}
+	}
+
+	if _, err := Distance(a, b, 3); err == nil {
+		t.Error("expected an error for an unknown norm")
+	}
+}
+
+func TestSimilarityCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	assert.NoError(err)
+	original := myImage.CoordinateImage()
+	signature, err := secretKey.Sign(myImage.Statement(original.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	assert.NoError(err)
+
+	// Channels moved in both directions, as recompression does: L1 distance 10, L2 distance 38
+	published := myImage.CoordinateImage()
+	published.SetPixel(4, 2, myImage.RGBPixel{R: original.Pixels[2][4].R + 5, G: original.Pixels[2][4].G, B: original.Pixels[2][4].B})
+	published.SetPixel(9, 9, myImage.RGBPixel{R: original.Pixels[9][9].R, G: original.Pixels[9][9].G - 3, B: original.Pixels[9][9].B - 2})
+
+	valid := func(norm Norm, bound int) SimilarityCircuit {
+		assignment := SimilarityCircuit{
+			Nonce:       testNonce,
+			ImageDigest: RegionDigest(published),
+			Norm:        int(norm),
+			Bound:       bound,
+			ImageBytes:  original.Digest(),
+			Metadata:    original.MetadataDigest(),
+			Original:    original.ToFrontendPacked(),
+			Published:   published.ToFrontendImage(),
+		}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, signature)
+		return assignment
+	}
+
+	for _, c := range []struct {
+		norm     Norm
+		distance int
+	}{
+		{L1, 10},
+		{L2, 38},
+	} {
+		// At and above the distance
+		for _, bound := range []int{c.distance, c.distance + 1} {
+			assignment := valid(c.norm, bound)
+			assert.NoError(test.IsSolved(&SimilarityCircuit{}, &assignment, ecc.BN254.ScalarField()), "%s %d", c.norm, bound)
+		}
+
+		// Below the distance
+		assignment := valid(c.norm, c.distance-1)
+		assert.Error(test.IsSolved(&SimilarityCircuit{}, &assignment, ecc.BN254.ScalarField()), "%s %d", c.norm, c.distance-1)
+	}
+
+	// The published image, the norm, the bound and the capture are all bound
+	for name, tamper := range map[string]func(*SimilarityCircuit){
+		"image":    func(c *SimilarityCircuit) { c.ImageDigest = RegionDigest(original) },
+		"norm":     func(c *SimilarityCircuit) { c.Norm = 3 },
+		"zero":     func(c *SimilarityCircuit) { c.Norm = 0 },
+		"bound":    func(c *SimilarityCircuit) { c.Bound = new(big.Int).Lsh(big.NewInt(1), distanceBits) },
+		"nonce":    func(c *SimilarityCircuit) { c.Nonce = testNonce + 1 },
+		"channel":  func(c *SimilarityCircuit) { c.Published.Pixels[0][0].R = -1 },
+		"signed":   func(c *SimilarityCircuit) { c.Original.Elements[0] = flipLowBit(original.Pack()[0]) },
+		"metadata": func(c *SimilarityCircuit) { c.Metadata = 1 },
+	} {
+		assignment := valid(L1, 10)
+		tamper(&assignment)
+		assert.Error(test.IsSolved(&SimilarityCircuit{}, &assignment, ecc.BN254.ScalarField()), name)
+	}
+
+	// The verifier's public witness is the prover's
+	assignment := valid(L2, 38)
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := secret_witness.Public()
+	assert.NoError(err)
+	want, err := publicWitness.MarshalBinary()
+	assert.NoError(err)
+
+	similarityWitness, err := SimilarityWitness(secretKey.Public().Bytes(), big.NewInt(testNonce), published, L2, big.NewInt(38))
+	assert.NoError(err)
+	got, err := similarityWitness.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(want, got)
+
+	assert.NoError(CheckSimilarity(original, published, L2, big.NewInt(38)))
+	assert.Error(CheckSimilarity(original, published, L2, big.NewInt(37)))
+}
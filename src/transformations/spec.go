@@ -0,0 +1,147 @@
+package transformations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// An edit script, the JSON specification of a sequence of transformations for tools that do not construct
+// them in Go, e.g. {"ops": [{"crop": {"x0": 1, "y0": 2, "x1": 12, "y1": 9}}, {"rotate": 90}]}. Every op is an
+// object of a single key, the name of the predicate of its type, e.g. "brightness", or "identity", whose value
+// is either:
+//   - an object of its Params, e.g. {"delta": 30}, or {} for a type without any;
+//   - a number, for a type of a single parameter, e.g. 30 for a brightness, or the clockwise angle in degrees,
+//     a multiple of 90, of a rotation;
+//   - the array of the ops of its steps, for a chain, see ChainOf;
+//   - the op of the transformation it covers, for a policy, see PolicyOf.
+type editScript struct {
+	Ops []json.RawMessage `json:"ops"`
+}
+
+// ParseSpec returns the transformations of the edit script data, in their order, or an error if it is not
+// one, e.g. an op of an unknown type, or with parameters its type does not have.
+func ParseSpec(data []byte) ([]Transformation, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var script editScript
+	if err := decoder.Decode(&script); err != nil {
+		return nil, fmt.Errorf("invalid edit script: %w", err)
+	}
+	if len(script.Ops) == 0 {
+		return nil, fmt.Errorf("invalid edit script: no ops")
+	}
+	return parseOps(script.Ops)
+}
+
+// parseOps returns the transformations of ops.
+func parseOps(ops []json.RawMessage) ([]Transformation, error) {
+	transformations := make([]Transformation, len(ops))
+	for i, op := range ops {
+		t, err := parseOp(op)
+		if err != nil {
+			return nil, fmt.Errorf("op %d: %w", i, err)
+		}
+		transformations[i] = t
+	}
+	return transformations, nil
+}
+
+// parseOp returns the transformation of an op of an edit script.
+func parseOp(op json.RawMessage) (Transformation, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(op, &fields); err != nil {
+		return Transformation{}, err
+	}
+	if len(fields) != 1 {
+		return Transformation{}, fmt.Errorf("expected an object of a single transformation, got %d keys", len(fields))
+	}
+	name := slices.Collect(maps.Keys(fields))[0]
+	value := bytes.TrimSpace(fields[name])
+	t, ok := opType(name)
+	if !ok {
+		return Transformation{}, fmt.Errorf("unknown transformation %q", name)
+	}
+
+	switch t {
+	case Chain:
+		var ops []json.RawMessage
+		if err := json.Unmarshal(value, &ops); err != nil {
+			return Transformation{}, fmt.Errorf("%s: expected the array of its steps: %w", name, err)
+		}
+		steps, err := parseOps(ops)
+		if err != nil {
+			return Transformation{}, fmt.Errorf("%s: %w", name, err)
+		}
+		return ChainOf(steps...)
+	case Policy:
+		edit, err := parseOp(value)
+		if err != nil {
+			return Transformation{}, fmt.Errorf("%s: %w", name, err)
+		}
+		return PolicyOf(edit)
+	}
+
+	params, err := opParams(t, value)
+	if err != nil {
+		return Transformation{}, fmt.Errorf("%s: %w", name, err)
+	}
+	transformation := Transformation{T: t, Params: params}
+	if err := transformation.CheckParams(); err != nil {
+		return Transformation{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return transformation, nil
+}
+
+// opParams returns the Params of a transformation of type t of the value of its op.
+func opParams(t int, value json.RawMessage) (map[string]int, error) {
+	if len(value) > 0 && value[0] == '{' {
+		var params map[string]int
+		if err := json.Unmarshal(value, &params); err != nil {
+			return nil, err
+		}
+		if len(params) == 0 {
+			return nil, nil
+		}
+		return params, nil
+	}
+
+	var number int
+	if err := json.Unmarshal(value, &number); err != nil {
+		return nil, fmt.Errorf("expected an object of parameters or a number: %w", err)
+	}
+	if t == Rotate {
+		if number%90 != 0 {
+			return nil, fmt.Errorf("invalid angle %d: expected a multiple of 90 degrees", number)
+		}
+		return map[string]int{"quarters": number / 90}, nil
+	}
+	args, ok := typeArgs[t]
+	if !ok {
+		return nil, fmt.Errorf("expected an object of parameters")
+	}
+	keys := slices.Sorted(maps.Keys(args.Params()))
+	if len(keys) != 1 {
+		return nil, fmt.Errorf("expected an object of parameters %v", keys)
+	}
+	return map[string]int{keys[0]: number}, nil
+}
+
+// opType returns the type of transformation of an op named name: "identity", or the name of the predicate of
+// the type, e.g. "brightness".
+func opType(name string) (int, bool) {
+	switch name {
+	case "identity":
+		return Identity, true
+	case CropCircuitID.Name:
+		return Crop, true
+	}
+	for t, edit := range edits {
+		if edit.Circuit().Name == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
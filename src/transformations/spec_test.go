@@ -0,0 +1,67 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/test"
+)
+
+func TestParseSpec(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Every op is the transformation of its type, with its parameters or its single one
+	transformations, err := ParseSpec([]byte(`{"ops": [
+		{"crop": {"x0": 1, "y0": 2, "x1": 12, "y1": 9}},
+		{"rotate": 90},
+		{"rotate": {"quarters": 2}},
+		{"brightness": -30},
+		{"gain": {"gain_r": 300, "gain_g": 256, "gain_b": 200}},
+		{"fliph": {}},
+		{"identity": {}}
+	]}`))
+	assert.NoError(err)
+	assert.Equal([]Transformation{
+		{T: Crop, Params: map[string]int{"x0": 1, "y0": 2, "x1": 12, "y1": 9}},
+		{T: Rotate, Params: map[string]int{"quarters": 1}},
+		{T: Rotate, Params: map[string]int{"quarters": 2}},
+		{T: Brightness, Params: map[string]int{"delta": -30}},
+		{T: Gain, Params: map[string]int{"gain_r": 300, "gain_g": 256, "gain_b": 200}},
+		{T: FlipH},
+		{T: Identity},
+	}, transformations)
+
+	// A chain is the Chain of its steps, and a policy the Policy of its transformation
+	transformations, err = ParseSpec([]byte(`{"ops": [
+		{"chain": [{"crop": {"x0": 1, "y0": 2, "x1": 12, "y1": 9}}, {"brightness": 30}, {"downscale": {}}]},
+		{"policy": {"rotate": 270}}
+	]}`))
+	assert.NoError(err)
+	chain, err := ChainOf(
+		Transformation{T: Crop, Params: map[string]int{"x0": 1, "y0": 2, "x1": 12, "y1": 9}},
+		Transformation{T: Brightness, Params: map[string]int{"delta": 30}},
+		Transformation{T: Downscale},
+	)
+	assert.NoError(err)
+	policy, err := PolicyOf(Transformation{T: Rotate, Params: map[string]int{"quarters": 3}})
+	assert.NoError(err)
+	assert.Equal([]Transformation{chain, policy}, transformations)
+
+	for name, spec := range map[string]string{
+		"not JSON":               `{"ops": [`,
+		"no op":                  `{"ops": []}`,
+		"an unknown field":       `{"ops": [{"fliph": {}}], "steps": 1}`,
+		"an unknown op":          `{"ops": [{"sharpness": 2}]}`,
+		"two ops in one":         `{"ops": [{"fliph": {}, "flipv": {}}]}`,
+		"a misspelled parameter": `{"ops": [{"crop": {"x0": 1, "y0": 2, "x1": 12, "yl": 9}}]}`,
+		"a missing parameter":    `{"ops": [{"crop": {"x0": 1, "y0": 2, "x1": 12}}]}`,
+		"a fractional parameter": `{"ops": [{"brightness": 1.5}]}`,
+		"a number of parameters": `{"ops": [{"crop": 4}]}`,
+		"an oblique rotation":    `{"ops": [{"rotate": 45}]}`,
+		"an unchainable step":    `{"ops": [{"chain": [{"sepia": {}}]}]}`,
+		"a policy of a flip":     `{"ops": [{"policy": {"fliph": {}}}]}`,
+	} {
+		if _, err := ParseSpec([]byte(spec)); err == nil {
+			t.Errorf("an edit script of %s was parsed", name)
+		}
+	}
+}
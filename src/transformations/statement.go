@@ -0,0 +1,133 @@
+package transformations
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/hashsuite"
+)
+
+// assertSignedStatement asserts that signature is the signature of publicKey over the statement of
+// imageBytes, nonce and prevProofHash, i.e. the message computed by image.Statement outside the circuit.
+// The nonce and prevProofHash are public inputs, so the proof holds for one capture, and one place in
+// its edit history, only.
+func assertSignedStatement(api frontend.API, publicKey eddsa.PublicKey, signature eddsa.Signature, imageBytes frontend.Variable, nonce frontend.Variable, prevProofHash frontend.Variable) error {
+	// Set the twisted edwards curve
+	curve, err := twistededwards.NewEdCurve(api, 1)
+	if err != nil {
+		return err
+	}
+
+	// Get the hash function that can be used in verifying signatures inside a Gnark ZKP-circuit.
+	// i.e. without revealing secret fields in the circuit.
+	hFunc, err := hashsuite.Default.NewGadget(api)
+	if err != nil {
+		return err
+	}
+
+	// The signed message is the hash of the image, the nonce and the hash of the previous proof
+	hFunc.Write(imageBytes, nonce, prevProofHash)
+	statement := hFunc.Sum()
+	hFunc.Reset()
+
+	// Verify the ImageSignature using a ZKP-circuit function for EdDSA signatures.
+	// This involves using the same hash function hashsuite.Default(statement + public key) to generate a secondary
+	// signature, and then verifying if the signatures match. This is done in a ZKP-circuit so the secret
+	// fields are not revealed.
+	return eddsa.Verify(curve, signature, statement, publicKey, hFunc)
+}
+
+// StatementDigest returns the digest of the statement of a proof that signature is the signature of publicKey
+// over an image, the nonce and prevProofHash: the PublicDigest of the coordinates of the key and of the
+// signature, the nonce, prevProofHash, the nullifier, the hops, imageBytes and prevImageBytes, the digests
+// of the image and of the image it was made from, and the coordinates of prevPublicKey, the key of the
+// PrevSignature of the image it was made from. It is the single public input of the statement of the
+// compliance predicates that BindStatement, as asserted by assertStatementDigest inside the circuit.
+func StatementDigest(publicKey []byte, signature []byte, nonce *big.Int, prevProofHash *big.Int, nullifier *big.Int, hops int, imageBytes, prevImageBytes []byte, prevPublicKey []byte) (*big.Int, error) {
+	var key eddsabn254.PublicKey
+	if _, err := key.SetBytes(publicKey); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	var prevKey eddsabn254.PublicKey
+	if _, err := prevKey.SetBytes(prevPublicKey); err != nil {
+		return nil, fmt.Errorf("invalid public key of the previous image: %w", err)
+	}
+	var sig eddsabn254.Signature
+	if _, err := sig.SetBytes(signature); err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if nonce == nil || prevProofHash == nil || nullifier == nil || imageBytes == nil || prevImageBytes == nil {
+		return nil, fmt.Errorf("incomplete statement")
+	}
+
+	// The coordinates and S are assigned to the circuit as they are encoded, see eddsa.Signature.Assign
+	var keyX, keyY, rX, rY, prevKeyX, prevKeyY big.Int
+	key.A.X.BigInt(&keyX)
+	key.A.Y.BigInt(&keyY)
+	sig.R.X.BigInt(&rX)
+	sig.R.Y.BigInt(&rY)
+	prevKey.A.X.BigInt(&prevKeyX)
+	prevKey.A.Y.BigInt(&prevKeyY)
+	s := new(big.Int).SetBytes(sig.S[:])
+	return PublicDigest(&keyX, &keyY, &rX, &rY, s, nonce, prevProofHash, nullifier, big.NewInt(int64(hops)), new(big.Int).SetBytes(imageBytes), new(big.Int).SetBytes(prevImageBytes), &prevKeyX, &prevKeyY), nil
+}
+
+// assertStatementDigest asserts that digest is the StatementDigest of the statement of publicKey, signature,
+// nonce, prevProofHash, nullifier, hops, imageBytes, prevImageBytes and prevPublicKey, which are secret inputs of
+// the circuit.
+func assertStatementDigest(api frontend.API, digest frontend.Variable, publicKey eddsa.PublicKey, signature eddsa.Signature, nonce, prevProofHash, nullifier, hops, imageBytes, prevImageBytes frontend.Variable, prevPublicKey eddsa.PublicKey) error {
+	return assertPublicDigest(api, digest, publicKey.A.X, publicKey.A.Y, signature.R.X, signature.R.Y, signature.S, nonce, prevProofHash, nullifier, hops, imageBytes, prevImageBytes, prevPublicKey.A.X, prevPublicKey.A.Y)
+}
+
+// StatementWitness returns the public witness of a proof of the circuit id, that signature is the signature of
+// publicKey over an image, the nonce and prevProofHash. The verifier rebuilds it from the proof it received,
+// rather than trusting the public witness sent along, so that the statement it checks is the one the proof was
+// created for. imageBytes and prevImageBytes are the digests of the image and of the image it was made from,
+// prevPublicKey the key the verifier expects the image it was made from to be signed with, see PrevSignature,
+// and params the public parameters of the edit, see PublicParams, nil for circuits without EditParams.
+//
+// The statement is a single public input, its StatementDigest, followed by the EditParams of the predicates of
+// edits that have them. The versions before they BindStatement, which CheckVerifiable rejects, have none: their
+// proofs are proven again.
+func StatementWitness(id CircuitID, publicKey []byte, signature []byte, nonce *big.Int, prevProofHash *big.Int, nullifier *big.Int, hops int, imageBytes, prevImageBytes []byte, prevPublicKey []byte, params map[string]int) (witness.Witness, error) {
+	if err := CheckVerifiable(id); err != nil {
+		return nil, err
+	}
+	digest, err := StatementDigest(publicKey, signature, nonce, prevProofHash, nullifier, hops, imageBytes, prevImageBytes, prevPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return statementWitness(id, []interface{}{digest}, params)
+}
+
+// statementWitness returns the public witness of values, followed by the params of the circuit id.
+func statementWitness(id CircuitID, values []interface{}, params map[string]int) (witness.Witness, error) {
+	for _, param := range id.EditParams() {
+		value, ok := params[param.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing parameter %s", param.Name)
+		}
+		values = append(values, big.NewInt(int64(value)))
+	}
+
+	publicWitness, err := witness.New(ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	elements := make(chan any, len(values))
+	for _, value := range values {
+		elements <- value
+	}
+	close(elements)
+	if err := publicWitness.Fill(len(values), 0, elements); err != nil {
+		return nil, err
+	}
+	return publicWitness, nil
+}
@@ -0,0 +1,153 @@
+package transformations
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	"src/hashsuite"
+	myImage "src/image"
+)
+
+// The verifier's rebuilt public witness must be the one every compliance predicate is proven with.
+func TestStatementWitness(t *testing.T) {
+	// The same key and signature as signedTestImage
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := myImage.AllWhiteImage()
+	signature, err := secretKey.Sign(myImage.Statement(img.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nullifier := testNullifier(t)
+
+	for _, c := range circuitCases(t) {
+		if !c.circuitID().BindsStatement() {
+			continue
+		}
+		statementWitness, err := StatementWitness(c.circuitID(), secretKey.Public().Bytes(), signature, big.NewInt(testNonce), big.NewInt(0), nullifier, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), c.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := statementWitness.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		secret_witness, err := frontend.NewWitness(c.assignment, ecc.BN254.ScalarField())
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicWitness, err := secret_witness.Public()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := publicWitness.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: public witness differs from the statement witness", c.name)
+		}
+	}
+
+	// The statement is a single public input, and older versions, which expose it value by value or do not verify
+	// the signature of the previous image, are proven again
+	statementWitness, err := StatementWitness(CropCircuitID, secretKey.Public().Bytes(), signature, big.NewInt(testNonce), big.NewInt(0), nullifier, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(statementWitness.Vector().(fr.Vector)); got != 1 {
+		t.Errorf("%s: %d public inputs, expected 1", CropCircuitID, got)
+	}
+	for _, version := range []int{1, 9, 10} {
+		if _, err := StatementWitness(CircuitID{Name: "crop", Version: version}, secretKey.Public().Bytes(), signature, big.NewInt(testNonce), big.NewInt(0), nullifier, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), nil); err == nil {
+			t.Errorf("crop v%d does not bind its statement to a digest with the key of its previous image, but a statement witness was returned", version)
+		}
+	}
+
+	// Invalid points come from untrusted proofs, and must not panic
+	if _, err := StatementWitness(CropCircuitID, secretKey.Public().Bytes(), signature[:10], big.NewInt(testNonce), big.NewInt(0), nullifier, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), nil); err == nil {
+		t.Error("truncated signature was accepted")
+	}
+	if _, err := StatementWitness(CropCircuitID, nil, signature, big.NewInt(testNonce), big.NewInt(0), nullifier, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), nil); err == nil {
+		t.Error("missing public key was accepted")
+	}
+	if _, err := StatementWitness(CropCircuitID, secretKey.Public().Bytes(), signature, nil, big.NewInt(0), nullifier, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), nil); err == nil {
+		t.Error("missing nonce was accepted")
+	}
+	if _, err := StatementWitness(CropCircuitID, secretKey.Public().Bytes(), signature, big.NewInt(testNonce), nil, nullifier, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), nil); err == nil {
+		t.Error("missing hash of the previous proof was accepted")
+	}
+	if _, err := StatementWitness(CropCircuitID, secretKey.Public().Bytes(), signature, big.NewInt(testNonce), big.NewInt(0), nil, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes(), nil); err == nil {
+		t.Error("missing nullifier was accepted")
+	}
+	if _, err := StatementWitness(CropCircuitID, secretKey.Public().Bytes(), signature, big.NewInt(testNonce), big.NewInt(0), nullifier, 0, img.Digest(), nil, secretKey.Public().Bytes(), nil); err == nil {
+		t.Error("missing digest of the previous image was accepted")
+	}
+	if _, err := StatementWitness(CropCircuitID, secretKey.Public().Bytes(), signature, big.NewInt(testNonce), big.NewInt(0), nullifier, 0, img.Digest(), img.Digest(), nil, nil); err == nil {
+		t.Error("missing key of the previous image was accepted")
+	}
+}
+
+// The identity proves an original image, which has no previous proof.
+func TestIdentityPrevProofHash(t *testing.T) {
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := myImage.AllWhiteImage()
+	prevProofHash := big.NewInt(1)
+	signature, err := secretKey.Sign(myImage.Statement(img.Digest(), big.NewInt(testNonce), prevProofHash), hashsuite.Default.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assignment := IdentityCircuit{Nonce: testNonce, PrevProofHash: prevProofHash, Nullifier: testNullifier(t), Hops: 0, ImageBytes: img.Digest(), PrevImageBytes: img.Digest(), Metadata: img.MetadataDigest(), FrImage: img.ToFrontendImage()}
+	assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+	assignment.ImageSignature.Assign(1, signature)
+	assignment.Statement = testStatement(t, assignment.PublicKey, assignment.ImageSignature, assignment.Nonce, assignment.PrevProofHash, assignment.Nullifier, assignment.Hops, assignment.ImageBytes, assignment.PrevImageBytes, assignment.PublicKey)
+
+	if test.IsSolved(&IdentityCircuit{}, &assignment, ecc.BN254.ScalarField()) == nil {
+		t.Error("an identity with a previous proof was solved")
+	}
+}
+
+// The identity proves the pixels the camera signed, not a digest of other ones.
+func TestIdentityPixels(t *testing.T) {
+	secretKey, err := ceddsa.New(1, rand.New(rand.NewSource(testSeed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := myImage.AllWhiteImage()
+	signature, err := secretKey.Sign(myImage.Statement(img.Digest(), big.NewInt(testNonce), big.NewInt(0)), hashsuite.Default.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := img.Clone()
+	tampered.SetPixel(3, 4, myImage.RGBPixel{})
+	for name, frImage := range map[string]myImage.I{"signed": img, "tampered": tampered} {
+		assignment := IdentityCircuit{Nonce: testNonce, PrevProofHash: 0, Nullifier: testNullifier(t), Hops: 0, ImageBytes: img.Digest(), PrevImageBytes: img.Digest(), Metadata: img.MetadataDigest(), FrImage: frImage.ToFrontendImage()}
+		assignment.PublicKey.Assign(1, secretKey.Public().Bytes())
+		assignment.ImageSignature.Assign(1, signature)
+		assignment.Statement = testStatement(t, assignment.PublicKey, assignment.ImageSignature, assignment.Nonce, assignment.PrevProofHash, assignment.Nullifier, assignment.Hops, assignment.ImageBytes, assignment.PrevImageBytes, assignment.PublicKey)
+
+		err := test.IsSolved(&IdentityCircuit{}, &assignment, ecc.BN254.ScalarField())
+		if name == "signed" && err != nil {
+			t.Errorf("the identity of the signed image was not solved: %v", err)
+		}
+		if name == "tampered" && err == nil {
+			t.Error("the identity of pixels the camera did not sign was solved")
+		}
+	}
+}
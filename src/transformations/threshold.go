@@ -0,0 +1,80 @@
+package transformations
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// Number of bits that holds a threshold in [0, image.MaxThreshold+1].
+const thresholdBits = 9
+
+// Threshold of the ThresholdCircuit that keeps the image, the Identity of the predicate.
+const keepThreshold = myImage.MaxThreshold + 1
+
+// This circuit is only for Threshold transformations: the signed image is the image FrImage binarized at the
+// public Threshold, like image.I.Binarize does, i.e. every pixel whose luma is above the Threshold is white and
+// every other pixel black. The comparisons of the lumas with the Threshold are made in the circuit. A Threshold
+// of image.MaxThreshold+1 keeps the image instead, so an original image is proven with the keys of the predicate.
+// Public fields: Statement, Threshold
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, BinarizedImage_in
+type ThresholdCircuit struct {
+	Statement         frontend.Variable     `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey         eddsa.PublicKey       // Key the image is signed with
+	ImageSignature    eddsa.Signature       // Digital signature as eddsa.Signature
+	Nonce             frontend.Variable     // Capture counter, see image.Statement
+	PrevProofHash     frontend.Variable     // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier         frontend.Variable     // Nullifier of the capture, see Nullifier
+	Hops              frontend.Variable     // Edits since the original image, see MaxHops
+	ImageBytes        frontend.Variable     // Digest of the signed image, BinarizedImage_in
+	PrevImageBytes    frontend.Variable     // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	Threshold         frontend.Variable     `gnark:",public"` // Luma in [0, image.MaxThreshold], or image.MaxThreshold+1 to keep the image
+	Metadata          frontend.Variable     // MetadataDigest of the signed image
+	PrevMetadata      frontend.Variable     // MetadataDigest of the previous image
+	Prev              PrevSignature         // Signature of the previous image, see PrevSignature
+	FrImage           myImage.FrontendImage // z_in as a FrontendImage
+	BinarizedImage_in myImage.FrontendImage // Binarized previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a binarization.
+func (circuit *ThresholdCircuit) Define(api frontend.API) error {
+	// Binarize the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	thresholdPlanes(api, planes[:], circuit.Threshold)
+	binarizedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.BinarizedImage_in, &binarizedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.BinarizedImage_in)
+}
+
+// thresholdPlanes binarizes the R, G and B planes at threshold, in place, exactly like image.I.Binarize does
+// outside the circuit, or keeps them for a threshold of image.MaxThreshold+1. It asserts threshold lies within
+// [0, image.MaxThreshold+1].
+//
+// The luma of a pixel, with the weights of image.Luma, is above the threshold when its weighted sum, out of 256,
+// is at least 256 * (threshold+1), so the sum is compared without being divided. Both lie within
+// [0, 256 * (image.MaxThreshold+2)], which bounds the comparator.
+func thresholdPlanes(api frontend.API, planes []channelPlane, threshold frontend.Variable) {
+	rangeChecker := rangecheck.New(api)
+	rangeChecker.Check(threshold, thresholdBits)
+	rangeChecker.Check(api.Sub(keepThreshold, threshold), thresholdBits)
+	keep := api.IsZero(api.Sub(keepThreshold, threshold))
+
+	comparator := cmp.NewBoundedComparator(api, big.NewInt(256*(keepThreshold+1)), false)
+	lowest := api.Mul(api.Add(threshold, 1), 256)
+	for y := 0; y < myImage.Height; y++ {
+		for x := 0; x < myImage.Width; x++ {
+			sum := api.Add(api.Mul(77, planes[0][y][x]), api.Mul(150, planes[1][y][x]), api.Mul(29, planes[2][y][x]))
+			binarized := api.Mul(comparator.IsLessEq(lowest, sum), 255)
+			for c := range planes {
+				planes[c][y][x] = api.Select(keep, planes[c][y][x], binarized)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts thresholdPlanes(In, Threshold) == Out, without the signature check of the ThresholdCircuit.
+type thresholdPixelsCircuit struct {
+	In        myImage.FrontendImage
+	Out       myImage.FrontendImage
+	Threshold frontend.Variable
+}
+
+func (circuit *thresholdPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	thresholdPlanes(api, planes[:], circuit.Threshold)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+func TestThresholdPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	for _, threshold := range []int{0, 100, 128, myImage.MaxThreshold} {
+		out, err := in.Binarize(threshold)
+		assert.NoError(err)
+
+		assignment := thresholdPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), Threshold: threshold}
+		assert.NoError(test.IsSolved(&thresholdPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "threshold %d", threshold)
+
+		// The image binarized at another threshold is not the binarized image, nor is the image kept
+		assignment.Threshold = (threshold + 64) % (myImage.MaxThreshold + 1)
+		assert.Error(test.IsSolved(&thresholdPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "threshold %d", threshold)
+		assignment.Threshold = keepThreshold
+		assert.Error(test.IsSolved(&thresholdPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "threshold %d", threshold)
+	}
+
+	// A threshold of image.MaxThreshold+1 keeps the image
+	assignment := thresholdPixelsCircuit{In: in.ToFrontendImage(), Out: in.ToFrontendImage(), Threshold: keepThreshold}
+	assert.NoError(test.IsSolved(&thresholdPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()))
+
+	// The threshold is bounded
+	black := myImage.I{}.ToFrontendImage()
+	for _, threshold := range []int{-1, keepThreshold + 1} {
+		assignment := thresholdPixelsCircuit{In: black, Out: black, Threshold: threshold}
+		assert.Error(test.IsSolved(&thresholdPixelsCircuit{}, &assignment, ecc.BN254.ScalarField()), "threshold %d", threshold)
+	}
+}
@@ -1,23 +1,111 @@
+// Package transformations defines the permissible transformations and their compliance predicates.
+//
+// An edit history is verified proof by proof: the predicates do not verify the previous proof inside the circuit
+// of the next one, as the proof-carrying data of PhotoProof does. Every predicate is a Groth16 or PLONK circuit over
+// BN254, and verifying a BN254 proof inside a BN254 circuit needs emulated field arithmetic, millions of
+// constraints per step, on top of predicates that already take minutes to prove. gnark's efficient recursion pairs
+// BLS12-377 with BW6-761, a chain of two curves rather than a cycle, so it folds one step into another but cannot
+// carry a history of any length. Until the predicates move to a curve cycle or a folding scheme, the hash chain of
+// the PrevProofHash, the digests of the images, the PrevSignature, the hops and the nullifier tie the proofs of a
+// history together, and a verifier needs every proof of it.
 package transformations
 
-import "github.com/consensys/gnark/frontend"
+import (
+	"fmt"
 
+	"github.com/consensys/gnark/frontend"
+
+	myImage "src/image"
+)
+
+// Types of permissible transformations.
 const (
-	Identity = 0
-	Crop     = 1
+	Identity      = 0
+	Crop          = 1
+	Rotate        = 2
+	FlipH         = 3
+	FlipV         = 4
+	Downscale     = 5
+	RotateCrop    = 6
+	Brightness    = 7
+	Gamma         = 8
+	Sepia         = 9
+	HueSaturation = 10
+	Gain          = 11
+	Threshold     = 12
+	ChannelSwap   = 13
+	Redact        = 14
+	Mosaic        = 15
+	Blur          = 16
+	Sharpen       = 17
+	Watermark     = 18
+	Caption       = 19
+	Border        = 20
+	Convolution   = 21
+	Policy        = 22
+	Chain         = 23
+	Credit        = 24
 )
 
+// A Transformation of type T with its parameters.
 type Transformation struct {
 	T      int
-	Params map[string]int // [x0, y0, x1, y1]{...}
+	Params map[string]int // [x0, y0, x1, y1]{...} for a Crop, [quarters]{...} for a Rotate, [quarters, x0, y0, x1, y1]{...} for a RotateCrop, [delta]{...} for a Brightness, [gamma]{...} in hundredths for a Gamma, [hue, saturation]{...} for a HueSaturation, [gain_r, gain_g, gain_b]{...} out of 256 for a Gain, [threshold]{...} for a Threshold, [source_r, source_g, source_b]{...} for a ChannelSwap, [x0, y0, x1, y1]{...} for a Redact, [x0, y0, x1, y1, block]{...} for a Mosaic, [x0, y0, x1, y1]{...} for a Blur, [x0, y0, x1, y1]{...} for a Sharpen, [x, y, watermark_0, ...]{...} for a Watermark, see WatermarkParams, [y, caption_0, ...]{...} for a Caption, see CaptionParams, [width, r, g, b]{...} for a Border, [x0, y0, x1, y1, kernel]{...} for a Convolution, [selector, quarters, x0, y0, x1, y1]{...} for a Policy, see PolicyOf, [step_0, ...]{...} and the Params of every step for a Chain, see ChainOf, [author_length, author_0, ...]{...} for a Credit, see CreditParams, none for a FlipH, a FlipV, a Downscale or a Sepia
+	Public []string       // Secret Params its proof discloses, e.g. [x1, y1] for a Crop of a public size but a secret offset, see CropCircuit
 }
 
+// A Transformation whose parameters are frontend variables, ready to be assigned to a circuit.
 type FrTransformation struct {
 	T      frontend.Variable
 	Params CropParams
 }
 
+// ToFr converts the Transformation parameters into frontend variables.
+// An Identity is a crop of the whole image, so its parameters are ignored.
 func (t Transformation) ToFr() FrTransformation {
 	params := CropParams{X0: t.Params["x0"], Y0: t.Params["y0"], X1: t.Params["x1"], Y1: t.Params["y1"]}
+	if t.T == Identity {
+		params = CropParams{X0: 0, Y0: 0, X1: myImage.Width - 1, Y1: myImage.Height - 1}
+	}
 	return FrTransformation{T: t.T, Params: params}
 }
+
+// Apply returns the image the Transformation makes of img, a new image that shares nothing with img, which is
+// left unchanged: so the image before an edit and the image after it can never be the same image. Its Params
+// must be exactly those of its type, see CheckParams.
+func (t Transformation) Apply(img myImage.I) (myImage.I, error) {
+	if err := t.CheckParams(); err != nil {
+		return myImage.I{}, err
+	}
+
+	switch t.T {
+	case Identity:
+		return img.Clone(), nil
+	case Crop:
+		params := t.ToFr().Params
+		return img.SubImage(params.X0.(int), params.Y0.(int), params.X1.(int), params.Y1.(int))
+	}
+	if edit, ok := edits[t.T]; ok {
+		return edit.Apply(img, t.Params)
+	}
+	return myImage.I{}, fmt.Errorf("unknown transformation type %d", t.T)
+}
+
+// Circuit returns the compliance predicate that proves the Transformation: the CropCircuit for an Identity or
+// a Crop, disclosing its Public parameters, and the predicate of its Edit for every other type, e.g. the
+// RotateCircuit for a Rotate.
+func (t Transformation) Circuit() (CircuitID, error) {
+	if t.T == Identity || t.T == Crop {
+		return cropCircuitDisclosing(t.Public)
+	}
+	if len(t.Public) > 0 {
+		return CircuitID{}, fmt.Errorf("a transformation of type %d has no secret parameters to disclose", t.T)
+	}
+	if edit, ok := edits[t.T]; ok {
+		if composed, ok := edit.(composedEdit); ok {
+			return composed.circuitOf(t.Params)
+		}
+		return edit.Circuit(), nil
+	}
+	return CircuitID{}, fmt.Errorf("unknown transformation type %d", t.T)
+}
@@ -0,0 +1,173 @@
+package transformations
+
+import "fmt"
+
+// A CircuitID names a compliance predicate and the version of its constraints.
+// Keys generated for one version can neither prove nor verify another, so a circuit's version must
+// be bumped whenever its constraints change (TestGoldenProofs fails when they do: it records a digest of the
+// constraints and their coefficients, which does not change with the source lines they were added at).
+// A predicate composed of the predicates of other edits, e.g. that of a Chain, also names them by their Steps:
+// keys generated for one composition can neither prove nor verify another. Likewise, a predicate that discloses
+// some of its secret parameters, e.g. the bottom right corner of a Crop, names them by its Disclosed.
+type CircuitID struct {
+	Name      string `json:"name"`
+	Version   int    `json:"version"`
+	Steps     string `json:"steps,omitempty"`     // Names of the composed predicates, comma separated, e.g. "crop,brightness"
+	Disclosed string `json:"disclosed,omitempty"` // Names of the disclosed secret parameters, comma separated, e.g. "x1,y1"
+}
+
+// Current versions of the compliance predicates.
+var (
+	IdentityCircuitID      = CircuitID{Name: "identity", Version: 9}
+	CropCircuitID          = CircuitID{Name: "crop", Version: 11}
+	DisclosureCircuitID    = CircuitID{Name: "disclosure", Version: 4}
+	SimilarityCircuitID    = CircuitID{Name: "similarity", Version: 4}
+	CollageCircuitID       = CircuitID{Name: "collage", Version: 4}
+	PanoramaCircuitID      = CircuitID{Name: "panorama", Version: 4}
+	HDRCircuitID           = CircuitID{Name: "hdr", Version: 4}
+	FrameCircuitID         = CircuitID{Name: "frame", Version: 4}
+	DevelopCircuitID       = CircuitID{Name: "develop", Version: 3}
+	GrayCircuitID          = CircuitID{Name: "gray", Version: 2}
+	DeepCircuitID          = CircuitID{Name: "deep", Version: 2}
+	RotateCircuitID        = CircuitID{Name: "rotate", Version: 6}
+	FlipHCircuitID         = CircuitID{Name: "fliph", Version: 6}
+	FlipVCircuitID         = CircuitID{Name: "flipv", Version: 6}
+	DownscaleCircuitID     = CircuitID{Name: "downscale", Version: 6}
+	RotateCropCircuitID    = CircuitID{Name: "rotatecrop", Version: 6}
+	BrightnessCircuitID    = CircuitID{Name: "brightness", Version: 6}
+	GammaCircuitID         = CircuitID{Name: "gamma", Version: 6}
+	SepiaCircuitID         = CircuitID{Name: "sepia", Version: 6}
+	HueSaturationCircuitID = CircuitID{Name: "huesaturation", Version: 6}
+	GainCircuitID          = CircuitID{Name: "gain", Version: 6}
+	ThresholdCircuitID     = CircuitID{Name: "threshold", Version: 6}
+	ChannelSwapCircuitID   = CircuitID{Name: "channelswap", Version: 6}
+	RedactCircuitID        = CircuitID{Name: "redact", Version: 6}
+	MosaicCircuitID        = CircuitID{Name: "mosaic", Version: 6}
+	BlurCircuitID          = CircuitID{Name: "blur", Version: 6}
+	SharpenCircuitID       = CircuitID{Name: "sharpen", Version: 6}
+	WatermarkCircuitID     = CircuitID{Name: "watermark", Version: 6}
+	CaptionCircuitID       = CircuitID{Name: "caption", Version: 6}
+	BorderCircuitID        = CircuitID{Name: "border", Version: 6}
+	ConvolutionCircuitID   = CircuitID{Name: "convolution", Version: 6}
+	PolicyCircuitID        = CircuitID{Name: "policy", Version: 6}
+	ChainCircuitID         = CircuitID{Name: "chain", Version: 6}
+	CreditCircuitID        = CircuitID{Name: "credit", Version: 5}
+)
+
+// First version of each compliance predicate with a statement that this build verifies: the first version that
+// exposes it as a single public input, its StatementDigest, with the key of the PrevSignature of the image it
+// was made from, which it verifies.
+var (
+	statementVersions = map[string]int{
+		IdentityCircuitID.Name:      9,
+		CropCircuitID.Name:          11,
+		RotateCircuitID.Name:        6,
+		FlipHCircuitID.Name:         6,
+		FlipVCircuitID.Name:         6,
+		DownscaleCircuitID.Name:     6,
+		RotateCropCircuitID.Name:    6,
+		BrightnessCircuitID.Name:    6,
+		GammaCircuitID.Name:         6,
+		SepiaCircuitID.Name:         6,
+		HueSaturationCircuitID.Name: 6,
+		GainCircuitID.Name:          6,
+		ThresholdCircuitID.Name:     6,
+		ChannelSwapCircuitID.Name:   6,
+		RedactCircuitID.Name:        6,
+		MosaicCircuitID.Name:        6,
+		BlurCircuitID.Name:          6,
+		SharpenCircuitID.Name:       6,
+		WatermarkCircuitID.Name:     6,
+		CaptionCircuitID.Name:       6,
+		BorderCircuitID.Name:        6,
+		ConvolutionCircuitID.Name:   6,
+		PolicyCircuitID.Name:        6,
+		ChainCircuitID.Name:         6,
+		CreditCircuitID.Name:        5,
+	}
+)
+
+// Compliance predicates of this build, by name.
+var circuits = map[string]CircuitID{
+	IdentityCircuitID.Name:      IdentityCircuitID,
+	CropCircuitID.Name:          CropCircuitID,
+	DisclosureCircuitID.Name:    DisclosureCircuitID,
+	SimilarityCircuitID.Name:    SimilarityCircuitID,
+	CollageCircuitID.Name:       CollageCircuitID,
+	PanoramaCircuitID.Name:      PanoramaCircuitID,
+	HDRCircuitID.Name:           HDRCircuitID,
+	FrameCircuitID.Name:         FrameCircuitID,
+	DevelopCircuitID.Name:       DevelopCircuitID,
+	GrayCircuitID.Name:          GrayCircuitID,
+	DeepCircuitID.Name:          DeepCircuitID,
+	RotateCircuitID.Name:        RotateCircuitID,
+	FlipHCircuitID.Name:         FlipHCircuitID,
+	FlipVCircuitID.Name:         FlipVCircuitID,
+	DownscaleCircuitID.Name:     DownscaleCircuitID,
+	RotateCropCircuitID.Name:    RotateCropCircuitID,
+	BrightnessCircuitID.Name:    BrightnessCircuitID,
+	GammaCircuitID.Name:         GammaCircuitID,
+	SepiaCircuitID.Name:         SepiaCircuitID,
+	HueSaturationCircuitID.Name: HueSaturationCircuitID,
+	GainCircuitID.Name:          GainCircuitID,
+	ThresholdCircuitID.Name:     ThresholdCircuitID,
+	ChannelSwapCircuitID.Name:   ChannelSwapCircuitID,
+	RedactCircuitID.Name:        RedactCircuitID,
+	MosaicCircuitID.Name:        MosaicCircuitID,
+	BlurCircuitID.Name:          BlurCircuitID,
+	SharpenCircuitID.Name:       SharpenCircuitID,
+	WatermarkCircuitID.Name:     WatermarkCircuitID,
+	CaptionCircuitID.Name:       CaptionCircuitID,
+	BorderCircuitID.Name:        BorderCircuitID,
+	ConvolutionCircuitID.Name:   ConvolutionCircuitID,
+	PolicyCircuitID.Name:        PolicyCircuitID,
+	ChainCircuitID.Name:         ChainCircuitID,
+	CreditCircuitID.Name:        CreditCircuitID,
+}
+
+// BindsStatement reports whether proofs of the circuit id prove a statement, see StatementWitness: they expose
+// its StatementDigest, and verify the PrevSignature of the image they were made from.
+func (id CircuitID) BindsStatement() bool {
+	version, ok := statementVersions[id.Name]
+	return ok && id.Version >= version
+}
+
+func (id CircuitID) String() string {
+	if id.Steps != "" {
+		return fmt.Sprintf("%s v%d [%s]", id.Name, id.Version, id.Steps)
+	}
+	if id.Disclosed != "" {
+		return fmt.Sprintf("%s v%d (%s public)", id.Name, id.Version, id.Disclosed)
+	}
+	return fmt.Sprintf("%s v%d", id.Name, id.Version)
+}
+
+// CheckVerifiable returns an error if proofs of the circuit id cannot be verified by this build.
+// Proofs of older versions remain verifiable with the keys they were generated with, but those of the predicates
+// with a statement made before they BindStatement, e.g. before it was bound to its StatementDigest or before they
+// verified the PrevSignature: their images are proven again.
+func CheckVerifiable(id CircuitID) error {
+	current, ok := circuits[id.Name]
+	if !ok {
+		return fmt.Errorf("unknown circuit %q: it was not created by PhotoGnark, or by a newer release; upgrade PhotoGnark to verify it", id.Name)
+	}
+	if id.Version > current.Version {
+		return fmt.Errorf("circuit %s is newer than this build (%s): upgrade PhotoGnark to verify it", id, current)
+	}
+	if _, ok := statementVersions[id.Name]; ok && !id.BindsStatement() {
+		return fmt.Errorf("circuit %s proves a statement this build no longer verifies, without its digest or the signature of the previous image: prove the image again", id)
+	}
+	return nil
+}
+
+// CheckProvable returns an error if new proofs cannot be created for the circuit id by this build.
+// Only the current version of a circuit can be proven.
+func CheckProvable(id CircuitID) error {
+	if err := CheckVerifiable(id); err != nil {
+		return err
+	}
+	if current := circuits[id.Name]; id.Version != current.Version {
+		return fmt.Errorf("circuit %s has been replaced by %s: run the Generator again to create keys for it", id, current)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package transformations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCircuitCompatibility(t *testing.T) {
+	older := CircuitID{Name: CropCircuitID.Name, Version: CropCircuitID.Version - 1}
+	newer := CircuitID{Name: CropCircuitID.Name, Version: CropCircuitID.Version + 1}
+	unknown := CircuitID{Name: "unknown", Version: 1}
+	// An older version of a predicate without a statement
+	olderDisclosure := CircuitID{Name: DisclosureCircuitID.Name, Version: DisclosureCircuitID.Version - 1}
+
+	for _, c := range []struct {
+		id         CircuitID
+		verifiable bool
+		provable   bool
+	}{
+		{CropCircuitID, true, true},
+		{IdentityCircuitID, true, true},
+		{older, false, false},
+		{olderDisclosure, true, false},
+		{newer, false, false},
+		{unknown, false, false},
+	} {
+		if err := CheckVerifiable(c.id); (err == nil) != c.verifiable {
+			t.Errorf("CheckVerifiable(%s) = %v", c.id, err)
+		}
+		if err := CheckProvable(c.id); (err == nil) != c.provable {
+			t.Errorf("CheckProvable(%s) = %v", c.id, err)
+		}
+	}
+}
+
+// The version before a predicate with a statement verified the signature of the previous image is rejected,
+// with the reason and the way out.
+func TestCircuitBeforeStatement(t *testing.T) {
+	for name, version := range statementVersions {
+		current := circuits[name]
+		if current.Version != version {
+			continue
+		}
+		older := CircuitID{Name: name, Version: version - 1}
+		err := CheckVerifiable(older)
+		if err == nil {
+			t.Errorf("CheckVerifiable(%s) accepted a version without the signature of the previous image", older)
+			continue
+		}
+		if !strings.Contains(err.Error(), "signature of the previous image") || !strings.Contains(err.Error(), "prove the image again") {
+			t.Errorf("CheckVerifiable(%s) = %v", older, err)
+		}
+		if older.BindsStatement() || !current.BindsStatement() {
+			t.Errorf("%s binds its statement, or %s does not", older, current)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package transformations
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	myImage "src/image"
+)
+
+// Abscissa of the watermark of the WatermarkCircuit that keeps the image, past the right edge of its pixels: the
+// Identity of the predicate.
+const keepWatermark = myImage.Width
+
+// This circuit is only for Watermark transformations: the signed image is the image FrImage with the public
+// Watermark composited at (X, Y), like image.I.Stamp does, e.g. the logo of the agency that publishes a photo,
+// and every pixel it does not cover unchanged. The pixels of the Watermark are public inputs, each packed as
+// 0xRRGGBB, so a proof commits to the very watermark it stamped, which a verifier compares with the one the
+// agency publishes. The Watermark lies within the pixels of an image, or at an X of image.Width, which keeps
+// the image.
+// Public fields: Statement, X, Y, Watermark
+// Secret fields: PublicKey, ImageSignature, Nonce, PrevProofHash, Nullifier, Hops, ImageBytes, PrevImageBytes, Metadata, PrevMetadata, Prev, FrImage, StampedImage_in
+type WatermarkCircuit struct {
+	Statement       frontend.Variable                                                  `gnark:",public"` // StatementDigest of the fields PublicKey to PrevImageBytes, see StatementDigest
+	PublicKey       eddsa.PublicKey                                                    // Key the image is signed with
+	ImageSignature  eddsa.Signature                                                    // Digital signature as eddsa.Signature
+	Nonce           frontend.Variable                                                  // Capture counter, see image.Statement
+	PrevProofHash   frontend.Variable                                                  // Hash of the proof this edit was made from, 0 for an original image
+	Nullifier       frontend.Variable                                                  // Nullifier of the capture, see Nullifier
+	Hops            frontend.Variable                                                  // Edits since the original image, see MaxHops
+	ImageBytes      frontend.Variable                                                  // Digest of the signed image, StampedImage_in
+	PrevImageBytes  frontend.Variable                                                  // Digest of the previous image FrImage, the ImageBytes of the proof this edit was made from
+	X               frontend.Variable                                                  `gnark:",public"` // Abscissa of the top left corner of the watermark
+	Y               frontend.Variable                                                  `gnark:",public"` // Ordinate of the top left corner of the watermark
+	Watermark       [myImage.WatermarkHeight][myImage.WatermarkWidth]frontend.Variable `gnark:",public"` // Packed pixels of the watermark, row by row
+	Metadata        frontend.Variable                                                  // MetadataDigest of the signed image
+	PrevMetadata    frontend.Variable                                                  // MetadataDigest of the previous image
+	Prev            PrevSignature                                                      // Signature of the previous image, see PrevSignature
+	FrImage         myImage.FrontendImage                                              // z_in as a FrontendImage
+	StampedImage_in myImage.FrontendImage                                              // Watermarked previous image as a FrontendImage
+}
+
+// Defines the Compliance Predicate of a watermark.
+func (circuit *WatermarkCircuit) Define(api frontend.API) error {
+	// Stamp the watermark on the FrImage
+	planes := channelPlanes(&circuit.FrImage)
+	watermarkPlanes(api, planes[:], circuit.X, circuit.Y, &circuit.Watermark)
+	stampedImage_out := fromChannelPlanes(&planes)
+
+	// Assert the edited image is the signed image, which is signed for the capture and the previous proof
+	assertEqualImages(api, &circuit.StampedImage_in, &stampedImage_out)
+	return assertSignedEdit(api, circuit.Statement, circuit.PublicKey, circuit.ImageSignature, circuit.Nonce, circuit.PrevProofHash, circuit.Nullifier, circuit.Hops, circuit.ImageBytes, circuit.Metadata, circuit.PrevImageBytes, circuit.PrevMetadata, circuit.Prev, &circuit.FrImage, &circuit.StampedImage_in)
+}
+
+// watermarkPlanes composites the watermark, whose pixels are packed as 0xRRGGBB, at (x, y) over the R, G and B
+// planes, in place, exactly like image.I.Stamp does outside the circuit, and keeps every other pixel. It asserts
+// every packed pixel has 24 bits, and that the watermark lies within the pixels of an image, or that x is
+// keepWatermark.
+//
+// The position selects the pixels without a lookup: every row of the watermark is shifted to x, the sum of its
+// pixels weighed by the indicators of x, and then every shifted row to y the same way.
+func watermarkPlanes(api frontend.API, planes []channelPlane, x, y frontend.Variable, watermark *[myImage.WatermarkHeight][myImage.WatermarkWidth]frontend.Variable) {
+	const width, height = myImage.WatermarkWidth, myImage.WatermarkHeight
+
+	// The channels of every pixel of the watermark, R in its high byte
+	var channels [3][height][width]frontend.Variable
+	for j := range watermark {
+		for i := range watermark[j] {
+			bits := api.ToBinary(watermark[j][i], 24)
+			for c := range channels {
+				channels[c][j][i] = api.FromBinary(bits[8*(2-c) : 8*(3-c)]...)
+			}
+		}
+	}
+
+	xs := make([]int, 0, myImage.Width-width+2)
+	for v := 0; v <= myImage.Width-width; v++ {
+		xs = append(xs, v)
+	}
+	isX := valueIndicators(api, x, append(xs, keepWatermark))
+	ys := make([]int, 0, myImage.Height-height+1)
+	for v := 0; v <= myImage.Height-height; v++ {
+		ys = append(ys, v)
+	}
+	isY := valueIndicators(api, y, ys)
+
+	// Every row of the watermark shifted to x, and whether x covers every column
+	var shifted [3][height][myImage.Width]frontend.Variable
+	var coversColumn [myImage.Width]frontend.Variable
+	for col := 0; col < myImage.Width; col++ {
+		coversColumn[col] = 0
+		for c := range shifted {
+			for j := range shifted[c] {
+				shifted[c][j][col] = 0
+			}
+		}
+		for i := max(col-len(xs)+1, 0); i <= min(col, width-1); i++ {
+			coversColumn[col] = api.Add(coversColumn[col], isX[col-i])
+			for c := range shifted {
+				for j := range shifted[c] {
+					shifted[c][j][col] = api.Add(shifted[c][j][col], api.Mul(isX[col-i], channels[c][j][i]))
+				}
+			}
+		}
+	}
+
+	// Then shifted to y
+	for row := 0; row < myImage.Height; row++ {
+		var coversRow frontend.Variable = 0
+		var placed [3][myImage.Width]frontend.Variable
+		for c := range placed {
+			for col := range placed[c] {
+				placed[c][col] = 0
+			}
+		}
+		for j := max(row-len(ys)+1, 0); j <= min(row, height-1); j++ {
+			coversRow = api.Add(coversRow, isY[row-j])
+			for c := range placed {
+				for col := range placed[c] {
+					placed[c][col] = api.Add(placed[c][col], api.Mul(isY[row-j], shifted[c][j][col]))
+				}
+			}
+		}
+		for col := 0; col < myImage.Width; col++ {
+			covered := api.Mul(coversColumn[col], coversRow)
+			for c := range planes {
+				planes[c][row][col] = api.Select(covered, placed[c][col], planes[c][row][col])
+			}
+		}
+	}
+}
+
+// Names of the EditParams of the WatermarkCircuit, x and y, then the packed pixels of the watermark row by row.
+func watermarkEditParams() []EditParam {
+	params := []EditParam{{Name: "x", Identity: keepWatermark}, {Name: "y", Identity: 0}}
+	for k := range myImage.WatermarkWidth * myImage.WatermarkHeight {
+		params = append(params, EditParam{Name: fmt.Sprintf("watermark_%d", k), Identity: 0})
+	}
+	return params
+}
+
+// WatermarkParams returns the parameters of a Watermark transformation that stamps watermark at (x, y).
+func WatermarkParams(watermark *myImage.Watermark, x, y int) map[string]int {
+	params := map[string]int{"x": x, "y": y}
+	for j, row := range watermark {
+		for i, p := range row {
+			params[fmt.Sprintf("watermark_%d", j*myImage.WatermarkWidth+i)] = int(p.R)<<16 | int(p.G)<<8 | int(p.B)
+		}
+	}
+	return params
+}
+
+// watermarkOf returns the watermark of the parameters of a Watermark transformation.
+func watermarkOf(params map[string]int) (myImage.Watermark, error) {
+	var watermark myImage.Watermark
+	for j := range watermark {
+		for i := range watermark[j] {
+			name := fmt.Sprintf("watermark_%d", j*myImage.WatermarkWidth+i)
+			v := params[name]
+			if v < 0 || v >= 1<<24 {
+				return myImage.Watermark{}, fmt.Errorf("invalid %s %#x: expected a pixel packed as 0xRRGGBB", name, v)
+			}
+			watermark[j][i] = myImage.RGBPixel{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}
+		}
+	}
+	return watermark, nil
+}
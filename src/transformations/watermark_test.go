@@ -0,0 +1,82 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+
+	myImage "src/image"
+)
+
+// Asserts watermarkPlanes(In, X, Y, Watermark) == Out, without the signature check of the WatermarkCircuit.
+type watermarkPixelsCircuit struct {
+	In        myImage.FrontendImage
+	Out       myImage.FrontendImage
+	X         frontend.Variable
+	Y         frontend.Variable
+	Watermark [myImage.WatermarkHeight][myImage.WatermarkWidth]frontend.Variable
+}
+
+func (circuit *watermarkPixelsCircuit) Define(api frontend.API) error {
+	planes := channelPlanes(&circuit.In)
+	watermarkPlanes(api, planes[:], circuit.X, circuit.Y, &circuit.Watermark)
+	out := fromChannelPlanes(&planes)
+	assertEqualImages(api, &out, &circuit.Out)
+	return nil
+}
+
+// watermarkAssignment returns the assignment of a watermarkPixelsCircuit for the parameters of a Watermark
+// transformation.
+func watermarkAssignment(in, out myImage.I, params map[string]int) *watermarkPixelsCircuit {
+	assignment := &watermarkPixelsCircuit{In: in.ToFrontendImage(), Out: out.ToFrontendImage(), X: params["x"], Y: params["y"]}
+	for j := range assignment.Watermark {
+		for i := range assignment.Watermark[j] {
+			assignment.Watermark[j][i] = params[watermarkEditParams()[2+j*myImage.WatermarkWidth+i].Name]
+		}
+	}
+	return assignment
+}
+
+func TestWatermarkPlanes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := myImage.NoiseImage(testSeed)
+	var watermark myImage.Watermark
+	noise := myImage.NoiseImage(testSeed + 1)
+	for j := range watermark {
+		copy(watermark[j][:], noise.Pixels[j][:])
+	}
+	for _, position := range [][2]int{{0, 0}, {5, 3}, {myImage.Width - myImage.WatermarkWidth, myImage.Height - myImage.WatermarkHeight}} {
+		out, err := in.Stamp(&watermark, position[0], position[1])
+		assert.NoError(err)
+
+		params := WatermarkParams(&watermark, position[0], position[1])
+		assert.NoError(test.IsSolved(&watermarkPixelsCircuit{}, watermarkAssignment(in, out, params), ecc.BN254.ScalarField()), "position %v", position)
+
+		// The watermark is stamped at its position, and is the watermark of the parameters
+		params["x"]++
+		assert.Error(test.IsSolved(&watermarkPixelsCircuit{}, watermarkAssignment(in, out, params), ecc.BN254.ScalarField()), "position %v", position)
+		params["x"]--
+		params["watermark_5"] ^= 1
+		assert.Error(test.IsSolved(&watermarkPixelsCircuit{}, watermarkAssignment(in, out, params), ecc.BN254.ScalarField()), "position %v", position)
+	}
+
+	// A watermark past the right edge keeps the image
+	identity := map[string]int{}
+	for _, param := range watermarkEditParams() {
+		identity[param.Name] = param.Identity
+	}
+	assert.NoError(test.IsSolved(&watermarkPixelsCircuit{}, watermarkAssignment(in, in, identity), ecc.BN254.ScalarField()))
+
+	// The watermark lies within the pixels of an image, and its pixels are packed channels
+	black := myImage.I{}
+	for _, position := range [][2]int{{myImage.Width - myImage.WatermarkWidth + 1, 0}, {0, myImage.Height - myImage.WatermarkHeight + 1}, {-1, 0}} {
+		params := WatermarkParams(&myImage.Watermark{}, position[0], position[1])
+		assert.Error(test.IsSolved(&watermarkPixelsCircuit{}, watermarkAssignment(black, black, params), ecc.BN254.ScalarField()), "position %v", position)
+	}
+	params := WatermarkParams(&myImage.Watermark{}, 0, 0)
+	params["watermark_0"] = 1 << 24
+	assert.Error(test.IsSolved(&watermarkPixelsCircuit{}, watermarkAssignment(black, black, params), ecc.BN254.ScalarField()))
+}
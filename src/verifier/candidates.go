@@ -0,0 +1,22 @@
+package verifier
+
+import (
+	myImage "src/image"
+	"src/prover"
+)
+
+// Candidates returns the proofs of originals whose perceptual hash, stored in the metadata the camera signed,
+// is within maxDistance bits of the perceptual hash of a published image, see image.I.DHash. It runs no SNARK
+// verification: it picks the few originals worth running one against for a provenance claim about published,
+// e.g. a similarity or a disclosure. Originals without a stored hash are kept, since they cannot be ruled out.
+func Candidates(published myImage.I, originals []prover.Proof, maxDistance int) []prover.Proof {
+	hash := published.DHash()
+	var candidates []prover.Proof
+	for _, original := range originals {
+		stored := original.Z().Image.M.DHash
+		if stored == nil || myImage.HashDistance(*stored, hash) <= maxDistance {
+			candidates = append(candidates, original)
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,36 @@
+package verifier
+
+import (
+	"math/big"
+	"testing"
+
+	myImage "src/image"
+	"src/prover"
+)
+
+// Only originals that look like the published image, or that store no perceptual hash, are candidates.
+func TestCandidates(t *testing.T) {
+	original := myImage.AllWhiteImage()
+	original.Pixels[3][3] = myImage.RGBPixel{}
+	original.SetDHash()
+	other := myImage.AllWhiteImage()
+	for x := 0; x < myImage.Width/2; x++ {
+		for y := 0; y < myImage.Height; y++ {
+			other.Pixels[y][x] = myImage.RGBPixel{}
+		}
+	}
+	other.SetDHash()
+	unhashed := myImage.AllWhiteImage()
+
+	var originals []prover.Proof
+	for i, img := range []myImage.I{original, other, unhashed} {
+		originals = append(originals, prover.NewSignedProof(myImage.Z{Image: img}, []byte{1}, big.NewInt(int64(i+1))))
+	}
+	published := original
+	published.Pixels[3][3] = myImage.RGBPixel{R: 8, G: 8, B: 8}
+
+	candidates := Candidates(published, originals, 4)
+	if len(candidates) != 2 || candidates[0].Nonce().Int64() != 1 || candidates[1].Nonce().Int64() != 3 {
+		t.Errorf("%d candidates, expected the original and the image without a perceptual hash", len(candidates))
+	}
+}
@@ -0,0 +1,163 @@
+package verifier
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"src/backend"
+	gen "src/generator"
+	"src/hashsuite"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	ceddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// FuzzVerifierSignature feeds attacker controlled signature bytes to the digital
+// signature path of the Verifier. It must never panic, and must only accept the
+// camera's genuine signature.
+func FuzzVerifierSignature(f *testing.F) {
+	secretKey, err := ceddsa.New(1, rand.Reader)
+	if err != nil {
+		f.Fatal(err)
+	}
+	img := myImage.AllWhiteImage()
+	counter := big.NewInt(1)
+	genuine := img.Sign(secretKey, counter, big.NewInt(0))
+
+	vk_pp := gen.VK_PP{PublicKey: secretKey.Public()}
+
+	f.Add(genuine)
+	f.Add([]byte{})
+	f.Add(make([]byte, len(genuine)))
+	f.Add(bytes.Repeat([]byte{0xff}, len(genuine)))
+
+	f.Fuzz(func(t *testing.T, signature []byte) {
+		proof := prover.NewSignedProof(myImage.Z{Image: img, PublicKey: vk_pp.PublicKey}, signature, counter)
+		if Verifier(vk_pp, proof) && !bytes.Equal(signature, genuine) {
+			t.Fatalf("accepted a forged signature %x", signature)
+		}
+	})
+}
+
+// FuzzVerifierPCDProof decodes attacker controlled bytes with prover.DecodeProof, the way a
+// verifier receiving a proof over the wire would, and runs the PCD proof path of the
+// Verifier on them. Neither may panic or exhaust memory.
+func FuzzVerifierPCDProof(f *testing.F) {
+	vk_pp, signature, proofBytes, witnessBytes := identityProof(f)
+	z := myImage.Z{Image: myImage.AllWhiteImage(), PublicKey: vk_pp.PublicKey}
+	identityNullifier, err := myTransformations.Nullifier(vk_pp.PublicKey.Bytes(), identityNonce)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(proofBytes, witnessBytes)
+	f.Add([]byte{}, []byte{})
+	f.Add(proofBytes[:len(proofBytes)/2], witnessBytes)
+	f.Add(proofBytes, witnessBytes[:len(witnessBytes)/2])
+
+	// Length prefixes declaring far more elements than the input holds.
+	oversizedProof := bytes.Clone(proofBytes)
+	binary.BigEndian.PutUint32(oversizedProof[2*bn254.SizeOfG1AffineCompressed+bn254.SizeOfG2AffineCompressed:], 1<<30)
+	oversizedWitness := bytes.Clone(witnessBytes)
+	binary.BigEndian.PutUint32(oversizedWitness[8:], 1<<30)
+	f.Add(oversizedProof, witnessBytes)
+	f.Add(proofBytes, oversizedWitness)
+
+	f.Fuzz(func(t *testing.T, proofBytes []byte, witnessBytes []byte) {
+		proof, err := prover.DecodeProof(backend.Default, z, signature, identityNonce, big.NewInt(0), identityNullifier, z.Image.Digest(), vk_pp.Circuit, proofBytes, witnessBytes)
+		if err != nil {
+			return
+		}
+
+		Verifier(vk_pp, proof)
+	})
+}
+
+// Nonce of the proof created by identityProof.
+var identityNonce = big.NewInt(1)
+
+// Create a verifying key and a valid signature, serialized proof and public witness for the IdentityCircuit.
+func identityProof(tb testing.TB) (gen.VK_PP, []byte, []byte, []byte) {
+	secretKey, err := ceddsa.New(1, rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	img := myImage.AllWhiteImage()
+
+	normalSignature, err := secretKey.Sign(myImage.Statement(img.Digest(), identityNonce, big.NewInt(0)), hashsuite.Default.New())
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var eddsa_signature eddsa.Signature
+	eddsa_signature.Assign(1, normalSignature)
+
+	var eddsa_publicKey eddsa.PublicKey
+	eddsa_publicKey.Assign(1, secretKey.Public().Bytes())
+
+	nullifier, err := myTransformations.Nullifier(secretKey.Public().Bytes(), identityNonce)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	statement, err := myTransformations.StatementDigest(secretKey.Public().Bytes(), normalSignature, identityNonce, big.NewInt(0), nullifier, 0, img.Digest(), img.Digest(), secretKey.Public().Bytes())
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	assignment := myTransformations.IdentityCircuit{
+		Statement:      statement,
+		PublicKey:      eddsa_publicKey,
+		ImageSignature: eddsa_signature,
+		Nonce:          identityNonce,
+		PrevProofHash:  0,
+		Nullifier:      nullifier,
+		Hops:           0,
+		ImageBytes:     img.Digest(),
+		PrevImageBytes: img.Digest(),
+		Metadata:       img.MetadataDigest(),
+		FrImage:        img.ToFrontendImage(),
+	}
+
+	compliance_predicate, err := backend.Default.Compile(&myTransformations.IdentityCircuit{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	provingKey, verifyingKey, err := backend.Default.Setup(compliance_predicate)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	secret_witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	publicWitness, err := secret_witness.Public()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pcd_proof, err := backend.Default.Prove(compliance_predicate, provingKey, secret_witness)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var proofBytes bytes.Buffer
+	if _, err := pcd_proof.WriteTo(&proofBytes); err != nil {
+		tb.Fatal(err)
+	}
+	witnessBytes, err := publicWitness.MarshalBinary()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return gen.VK_PP{VerifyingKey: verifyingKey, PublicKey: secretKey.Public(), Circuit: myTransformations.IdentityCircuitID, Backend: backend.Default.ID()}, normalSignature, proofBytes.Bytes(), witnessBytes
+}
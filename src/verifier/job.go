@@ -0,0 +1,167 @@
+package verifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/gnark/backend/witness"
+
+	"src/backend"
+	"src/generator"
+	"src/prover"
+	myTransformations "src/transformations"
+)
+
+// Version of the job encoding written by MarshalJSON.
+//
+//	1: records the circuit, the backend, the PCD proof and its public witness
+const JobFormatVersion = 1
+
+// A Job is the verification of a PCD proof reduced to what its backend checks: the PCD proof, and the public
+// witness the Verifier rebuilds from the statement of the proof. Rebuilding the witness hashes the image of the
+// proof, so a verification service that queues proofs, or replays their verification later, stores the Jobs
+// of the proofs rather than the proofs and their images.
+//
+// VerifyJob checks the PCD proof against the public witness of the Job, not against an image: a Job is only as
+// trustworthy as the queue or storage it was read from.
+type Job struct {
+	Circuit       myTransformations.CircuitID
+	Backend       backend.ID
+	PCDProof      backend.Proof
+	PublicWitness witness.Witness
+}
+
+// NewJob returns the Job of a PCD proof for vk_pp, after the checks of the Verifier that need the proof itself,
+// e.g. that its image was signed by the camera or by the EditorKey of vk_pp.
+func NewJob(vk_pp generator.VK_PP, proof prover.Proof) (Job, error) {
+	if proof.PCDProof() == nil {
+		return Job{}, fmt.Errorf("the proof carries no PCD proof: verify the digital signature of the original image with the Verifier")
+	}
+	if err := checkKeys(vk_pp, proof.Circuit(), proof.Backend()); err != nil {
+		return Job{}, err
+	}
+
+	// The public witness of a proof with a statement is rebuilt from the digest of the proof's public key,
+	// signature, nonce, hash of the previous proof, nullifier, hops, image, digest of the previous image and key
+	// the previous image must be signed with, and the public parameters of the edit, so that Nonce,
+	// PrevProofHash, Nullifier, Z, PrevImageBytes and Params return what was proven. Proofs of the predicates
+	// without a statement are verified against the witness they carry.
+	publicWitness := proof.PublicWitness()
+	if proof.Circuit().BindsStatement() {
+		if proof.Z().PublicKey == nil {
+			return Job{}, fmt.Errorf("the proof carries no public key")
+		}
+		prevPublicKey, err := prevSigner(vk_pp, proof)
+		if err != nil {
+			return Job{}, err
+		}
+		publicWitness, err = myTransformations.StatementWitness(proof.Circuit(), proof.Z().PublicKey.Bytes(), proof.ImageSignature(), proof.Nonce(), proof.PrevProofHash(), proof.Nullifier(), proof.Z().Hops, proof.Z().Image.Digest(), proof.PrevImageBytes(), prevPublicKey.Bytes(), proof.Params())
+		if err != nil {
+			return Job{}, err
+		}
+
+		// The image of an original is signed by the camera, and the image of an edit by the editor key of the
+		// keys, so that every proof of a history is anchored in keys the verifier holds
+		if err := checkSigner(vk_pp, proof); err != nil {
+			return Job{}, err
+		}
+	}
+
+	return Job{Circuit: proof.Circuit(), Backend: proof.Backend(), PCDProof: proof.PCDProof(), PublicWitness: publicWitness}, nil
+}
+
+// VerifyJob returns true if the PCD proof of job holds for its public witness under vk_pp.
+func VerifyJob(vk_pp generator.VK_PP, job Job) bool {
+	if err := checkKeys(vk_pp, job.Circuit, job.Backend); err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+	if job.PCDProof == nil || job.PublicWitness == nil {
+		fmt.Println("FAIL: the job carries no PCD proof or no public witness.")
+		return false
+	}
+
+	b, err := backend.Get(vk_pp.Backend)
+	if err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+
+	// Verify the PCD proof.
+	if err := b.Verify(job.PCDProof, vk_pp.VerifyingKey, job.PublicWitness); err != nil {
+		// Invalid proof.
+		fmt.Println("FAIL: Image did not pass verification against PCD Proof.")
+		return false
+	}
+	// Valid proof.
+	fmt.Println("SUCCESS: Image verified against PCD Proof.")
+	return true
+}
+
+// checkKeys returns an error unless proofs of circuit, created with the backend b, are verified with vk_pp: the
+// circuit must be a known compliance predicate, and the verifying key must be for that same predicate.
+func checkKeys(vk_pp generator.VK_PP, circuit myTransformations.CircuitID, b backend.ID) error {
+	if err := myTransformations.CheckVerifiable(circuit); err != nil {
+		return err
+	}
+	if b != vk_pp.Backend {
+		return fmt.Errorf("the proof was created with %s, but the verifying key is for %s", b, vk_pp.Backend)
+	}
+	if circuit != vk_pp.Circuit {
+		return fmt.Errorf("the proof was created with circuit %s, but the verifying key is for %s: verify it with the keys it was generated with", circuit, vk_pp.Circuit)
+	}
+	return nil
+}
+
+// JSON encoding of a Job, so that it can be queued or stored.
+type jobJSON struct {
+	Version       int                         `json:"version"`
+	Circuit       myTransformations.CircuitID `json:"circuit"`
+	Backend       backend.ID                  `json:"backend"`
+	PCDProof      []byte                      `json:"pcdProof"`
+	PublicWitness []byte                      `json:"publicWitness"`
+}
+
+func (job Job) MarshalJSON() ([]byte, error) {
+	if job.PCDProof == nil || job.PublicWitness == nil {
+		return nil, fmt.Errorf("the job carries no PCD proof or no public witness")
+	}
+
+	var pcd_proof bytes.Buffer
+	if _, err := job.PCDProof.WriteTo(&pcd_proof); err != nil {
+		return nil, err
+	}
+	publicWitness, err := job.PublicWitness.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jobJSON{Version: JobFormatVersion, Circuit: job.Circuit, Backend: job.Backend, PCDProof: pcd_proof.Bytes(), PublicWitness: publicWitness})
+}
+
+func (job *Job) UnmarshalJSON(data []byte) error {
+	var decoded jobJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > JobFormatVersion {
+		return fmt.Errorf("job format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this job", decoded.Version, JobFormatVersion)
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcd_proof, err := b.ReadProof(decoded.PCDProof)
+	if err != nil {
+		return err
+	}
+	publicWitness, err := backend.ReadWitness(decoded.PublicWitness)
+	if err != nil {
+		return err
+	}
+
+	*job = Job{Circuit: decoded.Circuit, Backend: decoded.Backend, PCDProof: pcd_proof, PublicWitness: publicWitness}
+	return nil
+}
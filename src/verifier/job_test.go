@@ -0,0 +1,84 @@
+package verifier
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"src/backend"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+)
+
+// The Job of a proof survives its JSON encoding, and is verified later without the proof and its image.
+func TestJob(t *testing.T) {
+	vk_pp, signature, proofBytes, witnessBytes := identityProof(t)
+	img := myImage.AllWhiteImage()
+	nullifier, err := myTransformations.Nullifier(vk_pp.PublicKey.Bytes(), identityNonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(nonce *big.Int) prover.Proof {
+		proof, err := prover.DecodeProof(backend.Default, myImage.Z{Image: img, PublicKey: vk_pp.PublicKey}, signature, nonce, big.NewInt(0), nullifier, img.Digest(), vk_pp.Circuit, proofBytes, witnessBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return proof
+	}
+
+	job, err := NewJob(vk_pp, decode(identityNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replayed Job
+	if err := json.Unmarshal(encoded, &replayed); err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyJob(vk_pp, replayed) {
+		t.Error("the replayed job did not pass verification")
+	}
+
+	// The witness of the job is rebuilt from the statement of the proof, not copied from it
+	job, err = NewJob(vk_pp, decode(big.NewInt(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyJob(vk_pp, job) {
+		t.Error("the job of a proof claimed for another nonce passed verification")
+	}
+
+	// A job holds for the keys of its circuit only
+	other := vk_pp
+	other.Circuit = myTransformations.CropCircuitID
+	if VerifyJob(other, replayed) {
+		t.Error("the job passed verification with the keys of another circuit")
+	}
+	if _, err := NewJob(vk_pp, prover.NewSignedProof(myImage.Z{Image: img, PublicKey: vk_pp.PublicKey}, signature, identityNonce)); err == nil {
+		t.Error("a digital signature was turned into a job")
+	}
+
+	// Newer encodings, and truncated witnesses, are rejected
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]interface{}{"version": JobFormatVersion + 1, "publicWitness": witnessBytes[:len(witnessBytes)-1]} {
+		changed := map[string]interface{}{}
+		for k, v := range fields {
+			changed[k] = v
+		}
+		changed[name] = value
+		data, err := json.Marshal(changed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &replayed); err == nil {
+			t.Errorf("a job with another %s was decoded", name)
+		}
+	}
+}
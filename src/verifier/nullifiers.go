@@ -0,0 +1,71 @@
+package verifier
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"src/generator"
+	"src/prover"
+)
+
+// A NullifierStore records the nullifiers of the captures submitted to a contest or claims system, see
+// prover.Proof.Nullifier. Systems that run on more than one server back it with their database.
+type NullifierStore interface {
+	// Record records nullifier, and reports whether it had been recorded before.
+	Record(nullifier *big.Int) (bool, error)
+}
+
+// A MemoryNullifierStore is a NullifierStore that keeps the nullifiers in memory. It is safe for concurrent use.
+type MemoryNullifierStore struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// NewMemoryNullifierStore returns an empty MemoryNullifierStore.
+func NewMemoryNullifierStore() *MemoryNullifierStore {
+	return &MemoryNullifierStore{seen: make(map[string]struct{})}
+}
+
+func (store *MemoryNullifierStore) Record(nullifier *big.Int) (bool, error) {
+	if nullifier == nil {
+		return false, fmt.Errorf("missing nullifier")
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	key := nullifier.String()
+	if _, ok := store.seen[key]; ok {
+		return true, nil
+	}
+	store.seen[key] = struct{}{}
+	return false, nil
+}
+
+// VerifySubmission returns true if chain, the edit history of a submitted image, passes VerifyChain and its
+// capture was not submitted before, and records its nullifier in store. The same picture submitted again fails,
+// whichever edits it went through.
+//
+// The whole history is needed: only the nullifier of the original image is derived from the camera's key, and
+// every edit carries it along.
+func VerifySubmission(vk_pp generator.VK_PP, chain []prover.Proof, store NullifierStore) bool {
+	if !VerifyChain(vk_pp, chain) {
+		return false
+	}
+	if chain[0].Nullifier() == nil {
+		fmt.Println("FAIL: the submission carries no nullifier.")
+		return false
+	}
+
+	seen, err := store.Record(chain[0].Nullifier())
+	if err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+	if seen {
+		fmt.Printf("FAIL: capture %v of this camera was already submitted.\n", chain[0].Nonce())
+		return false
+	}
+	fmt.Println("SUCCESS: Submission verified, and its capture was not submitted before.")
+	return true
+}
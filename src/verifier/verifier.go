@@ -1,31 +1,45 @@
+// Package verifier implements the Verifier of PhotoProof.
 package verifier
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/witness"
+
+	"src/backend"
 	"src/generator"
+	"src/hashsuite"
+	"src/image"
 	"src/prover"
-
-	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
-	"github.com/consensys/gnark-crypto/hash"
-	"github.com/consensys/gnark/backend/groth16"
+	myTransformations "src/transformations"
 )
 
+// Verifier returns true if the proof is a valid digital signature of the camera over an original image,
+// or a valid PCD proof for the verifying key. The image of a PCD proof must be signed by the camera of the
+// verifying key, for an original image, or by its EditorKey, for an edit.
+//
+// A valid proof holds for its Nonce only. Verifier does not know which nonce the caller expects, so a
+// proof that was copied from another context still passes it; use VerifierWithNonce to rule that out.
 func Verifier(vk_pp generator.VK_PP, proof prover.Proof) bool {
-	if proof.PCD_proof == nil {
-		// Encode image.
-		msg := proof.Z.Image.ToByte() // []byte{0xde, 0xad, 0xf0, 0x0d, 0x0d}
+	if proof.PCDProof() == nil {
+		if proof.Nonce() == nil || proof.PrevProofHash() == nil {
+			fmt.Println("FAIL: the digital signature carries no nonce.")
+			return false
+		}
 
+		// Encode image, nonce and hash of the previous proof into the signed message.
 		// The size of the message must be a multiple of the size of Fr or you can get runtime error:
 		// "runtime error: slice bounds out of range"
-		var msgFr fr.Element
-		msgFr.SetBytes(msg)   // Set z value for the fr.Element
-		msg = msgFr.Marshal() // Convert z value to a big endian slice
+		msg := image.Statement(proof.Z().Image.Digest(), proof.Nonce(), proof.PrevProofHash())
 
 		// Instantiate hash function.
-		hFunc := hash.MIMC_BN254.New()
+		hFunc := hashsuite.Default.New()
 
 		// Verify digital signature.
-		isVerified, err := vk_pp.PublicKey.Verify(proof.ImageSignature, msg, hFunc)
+		isVerified, err := vk_pp.PublicKey.Verify(proof.ImageSignature(), msg, hFunc)
 		if err != nil {
 			fmt.Println(err.Error())
 		}
@@ -37,17 +51,266 @@ func Verifier(vk_pp generator.VK_PP, proof prover.Proof) bool {
 			fmt.Println("FAIL: Image did not pass verification against original image's Digital Signature.")
 		}
 	} else {
-		// Verify the PCD proof.
-		err := groth16.Verify(proof.PCD_proof, vk_pp.VerifyingKey, proof.Public_Witness)
+		// The statement of the proof is checked and turned into the public witness its PCD proof is verified against
+		job, err := NewJob(vk_pp, proof)
 		if err != nil {
-			// Invalid proof.
-			fmt.Println("FAIL: Image did not pass verification against PCD Proof.")
-		} else {
-			// Valid proof.
-			fmt.Println("SUCCESS: Image verified against PCD Proof.")
-			return true
+			fmt.Println("FAIL: " + err.Error())
+			return false
 		}
+		return VerifyJob(vk_pp, job)
 	}
 
 	return false
 }
+
+// checkSigner returns an error unless the image of proof, a proof with a PrevProofHash, was signed by the camera
+// of vk_pp, for an original, or by its EditorKey, for an edit.
+func checkSigner(vk_pp generator.VK_PP, proof prover.Proof) error {
+	if proof.PrevProofHash() == nil {
+		return fmt.Errorf("the proof carries no hash of the previous proof")
+	}
+	if proof.PrevProofHash().Sign() == 0 {
+		if vk_pp.PublicKey == nil || !proof.Z().PublicKey.Equal(vk_pp.PublicKey) {
+			return fmt.Errorf("the original image was not signed by the camera of the verifying key")
+		}
+		return nil
+	}
+	if vk_pp.EditorKey == nil {
+		return fmt.Errorf("the verifying key has no editor key to check edits against: create the keys with the Generator again")
+	}
+	if !proof.Z().PublicKey.Equal(vk_pp.EditorKey) {
+		return fmt.Errorf("the edited image was not signed by the editor key of the verifying key")
+	}
+	return nil
+}
+
+// prevSigner returns the key the image proof was made from must be signed with, the key of its PrevSignature: the
+// camera of vk_pp for an original image and for the edit of an original, and the EditorKey of vk_pp for the edit
+// of an edit, the Hops of the proof telling them apart.
+func prevSigner(vk_pp generator.VK_PP, proof prover.Proof) (signature.PublicKey, error) {
+	if proof.Z().Hops <= 1 {
+		if vk_pp.PublicKey == nil {
+			return nil, fmt.Errorf("the verifying key has no public key of the camera")
+		}
+		return vk_pp.PublicKey, nil
+	}
+	if vk_pp.EditorKey == nil {
+		return nil, fmt.Errorf("the verifying key has no editor key to check edits against: create the keys with the Generator again")
+	}
+	return vk_pp.EditorKey, nil
+}
+
+// VerifierWithNonce returns true if the proof passes the Verifier and holds for nonce: the capture counter
+// of the original image the caller expects the proof of.
+func VerifierWithNonce(vk_pp generator.VK_PP, proof prover.Proof, nonce *big.Int) bool {
+	if nonce == nil || proof.Nonce() == nil || proof.Nonce().Cmp(nonce) != 0 {
+		fmt.Printf("FAIL: the proof holds for nonce %v, not %v.\n", proof.Nonce(), nonce)
+		return false
+	}
+	return Verifier(vk_pp, proof)
+}
+
+// VerifyChain returns true if chain is the complete edit history of an image, in order: every proof passes
+// the Verifier, the first one is of an original image, and every later one was edited from the proof before
+// it, i.e. holds for the same capture, carries its Nullifier, is one hop past it, its PrevProofHash is the
+// ProofHash of the proof before it and its PrevImageBytes the digest of the image of that proof. The hops of
+// every proof are its place in the history, so a history longer than the transformations.MaxHops edits its
+// predicates prove fails.
+// A history that was reordered, forked or had steps dropped fails, and so does a single edited proof.
+func VerifyChain(vk_pp generator.VK_PP, chain []prover.Proof) bool {
+	if len(chain) == 0 {
+		fmt.Println("FAIL: the edit history is empty.")
+		return false
+	}
+	if !chain[0].Circuit().BindsStatement() && chain[0].PCDProof() != nil {
+		fmt.Printf("FAIL: proofs of circuit %s are not linked to the proof they were edited from.\n", chain[0].Circuit())
+		return false
+	}
+	if chain[0].PrevProofHash() == nil || chain[0].PrevProofHash().Sign() != 0 {
+		fmt.Println("FAIL: the edit history does not start with an original image.")
+		return false
+	}
+
+	for i, proof := range chain {
+		if i > 0 {
+			prevProofHash, err := prover.ProofHash(chain[i-1])
+			if err != nil {
+				fmt.Printf("FAIL: proof %d of the edit history: %s.\n", i-1, err)
+				return false
+			}
+			if proof.PrevProofHash() == nil || proof.PrevProofHash().Cmp(prevProofHash) != 0 {
+				fmt.Printf("FAIL: proof %d of the edit history was not edited from proof %d.\n", i, i-1)
+				return false
+			}
+			if proof.Nullifier() == nil || chain[0].Nullifier() == nil || proof.Nullifier().Cmp(chain[0].Nullifier()) != 0 {
+				fmt.Printf("FAIL: proof %d of the edit history carries another nullifier than the original image.\n", i)
+				return false
+			}
+			if !bytes.Equal(proof.PrevImageBytes(), chain[i-1].Z().Image.Digest()) {
+				fmt.Printf("FAIL: proof %d of the edit history was not made from the image of proof %d.\n", i, i-1)
+				return false
+			}
+		}
+		if proof.Z().Hops != i {
+			fmt.Printf("FAIL: proof %d of the edit history holds for %d hops.\n", i, proof.Z().Hops)
+			return false
+		}
+		if !VerifierWithNonce(vk_pp, proof, chain[0].Nonce()) {
+			fmt.Printf("FAIL: proof %d of the edit history did not pass verification.\n", i)
+			return false
+		}
+	}
+
+	return true
+}
+
+// publishedProof is a proof of a predicate of its own, like a Disclosure or a Collage, that is verified against
+// the public witness of its published image rather than a statement of the PCD.
+type publishedProof interface {
+	Circuit() myTransformations.CircuitID
+	Backend() backend.ID
+	PCDProof() backend.Proof
+}
+
+// verifyPublished returns true if the PCD proof of proof, named label in the messages, verifies with vk_pp against
+// the public witness publicWitness builds, once proof is known to be made with the circuit and backend of vk_pp.
+func verifyPublished(vk_pp generator.VK_PP, label string, proof publishedProof, publicWitness func() (witness.Witness, error)) bool {
+	if err := myTransformations.CheckVerifiable(proof.Circuit()); err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+	if proof.Circuit() != vk_pp.Circuit || proof.Backend() != vk_pp.Backend {
+		fmt.Printf("FAIL: %s was created with circuit %s and %s, but the verifying key is for circuit %s and %s.\n", label, proof.Circuit(), proof.Backend(), vk_pp.Circuit, vk_pp.Backend)
+		return false
+	}
+	if proof.PCDProof() == nil {
+		fmt.Printf("FAIL: %s carries no PCD proof.\n", label)
+		return false
+	}
+
+	b, err := backend.Get(vk_pp.Backend)
+	if err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+
+	w, err := publicWitness()
+	if err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+
+	if err := b.Verify(proof.PCDProof(), vk_pp.VerifyingKey, w); err != nil {
+		fmt.Printf("FAIL: %s did not pass verification against its PCD proof.\n", label)
+		return false
+	}
+	fmt.Printf("SUCCESS: %s verified against its PCD proof.\n", label)
+	return true
+}
+
+// VerifyDisclosure returns true if the region of the disclosure is an unmodified rectangle of a picture taken
+// by the camera of vk_pp, keys created by the DisclosureGenerator. The caller checks the capture counter of
+// that picture, Nonce, against the capture it expects a region of.
+func VerifyDisclosure(vk_pp generator.VK_PP, disclosure prover.Disclosure) bool {
+	// The public witness is built from the published region and the camera's key
+	return verifyPublished(vk_pp, "the disclosure", disclosure, func() (witness.Witness, error) {
+		if disclosure.Nonce() == nil {
+			return nil, fmt.Errorf("the disclosure carries no nonce")
+		}
+		return myTransformations.DisclosureWitness(vk_pp.PublicKey.Bytes(), disclosure.Nonce(), disclosure.Region())
+	})
+}
+
+// VerifySimilarity returns true if the image of the similarity is within its bound, in its norm, of a picture
+// taken by the camera of vk_pp, keys created by the SimilarityGenerator. The caller judges whether the norm and
+// bound are acceptable, and checks the capture counter of that picture, Nonce.
+func VerifySimilarity(vk_pp generator.VK_PP, similarity prover.Similarity) bool {
+	// The public witness is built from the published image, its norm and bound, and the camera's key
+	return verifyPublished(vk_pp, "the similarity", similarity, func() (witness.Witness, error) {
+		if similarity.Nonce() == nil {
+			return nil, fmt.Errorf("the similarity carries no nonce")
+		}
+		return myTransformations.SimilarityWitness(vk_pp.PublicKey.Bytes(), similarity.Nonce(), similarity.Image(), similarity.Norm(), similarity.Bound())
+	})
+}
+
+// VerifyCollage returns true if the image of the collage is the composition of its inputs, keys created by
+// the CollageGenerator, and every input passes the Verifier with vk_inputs, the verifying keys of the inputs
+// in the same order.
+func VerifyCollage(vk_pp generator.VK_PP, vk_inputs []generator.VK_PP, collage prover.Collage) bool {
+	return verifyPublished(vk_pp, "the collage", collage, func() (witness.Witness, error) {
+		if len(vk_inputs) != len(collage.Inputs()) {
+			return nil, fmt.Errorf("the collage has %d inputs, but %d verifying keys were given", len(collage.Inputs()), len(vk_inputs))
+		}
+
+		// The provenance of the collage is the provenance of every input
+		for i, input := range collage.Inputs() {
+			if !Verifier(vk_inputs[i], input) {
+				return nil, fmt.Errorf("input %d of the collage did not pass verification", i)
+			}
+		}
+
+		// The public witness is built from the published collage and the statements of the proofs of its inputs
+		statements, err := collage.Statements()
+		if err != nil {
+			return nil, err
+		}
+		rows, cols := collage.Grid()
+		return myTransformations.CollageWitness(rows, cols, collage.Image(), statements)
+	})
+}
+
+// VerifyPanorama returns true if the image of the panorama was stitched from two pictures taken by the camera
+// of vk_pp, keys created by the PanoramaGenerator. The caller checks the capture counters of those pictures,
+// Nonces, against the captures it expects a panorama of.
+func VerifyPanorama(vk_pp generator.VK_PP, panorama prover.Panorama) bool {
+	// The public witness is built from the published panorama and the camera's key
+	return verifyPublished(vk_pp, "the panorama", panorama, func() (witness.Witness, error) {
+		leftNonce, rightNonce := panorama.Nonces()
+		if leftNonce == nil || rightNonce == nil {
+			return nil, fmt.Errorf("the panorama carries no nonces")
+		}
+		seam, overlap := panorama.Seam()
+		return myTransformations.PanoramaWitness(vk_pp.PublicKey.Bytes(), leftNonce, rightNonce, panorama.Image(), seam, overlap)
+	})
+}
+
+// VerifyHDR returns true if the image of the HDR merge was merged, with its weights, from pictures taken by the
+// camera of vk_pp, keys created by the HDRGenerator. The caller checks the capture counters of those pictures,
+// Nonces, against the captures it expects a merge of.
+func VerifyHDR(vk_pp generator.VK_PP, hdr prover.HDR) bool {
+	// The public witness is built from the published image, its weights and the camera's key
+	return verifyPublished(vk_pp, "the HDR merge", hdr, func() (witness.Witness, error) {
+		return myTransformations.HDRWitness(vk_pp.PublicKey.Bytes(), hdr.Nonces(), hdr.Image(), hdr.Weights())
+	})
+}
+
+// VerifyDevelopment returns true if the image of the development was developed, with its white balance gains,
+// from a RAW capture taken by the camera of vk_pp, keys created by the DevelopGenerator. The caller checks the
+// capture counter of that capture, Nonce, against the capture it expects a development of.
+func VerifyDevelopment(vk_pp generator.VK_PP, development prover.Development) bool {
+	// The public witness is built from the published image, its gains and the camera's key
+	return verifyPublished(vk_pp, "the development", development, func() (witness.Witness, error) {
+		return myTransformations.DevelopWitness(vk_pp.PublicKey.Bytes(), development.Nonce(), development.Image(), development.Gains())
+	})
+}
+
+// VerifyGray returns true if the grayscale image shows its area of a grayscale capture taken by the camera of
+// vk_pp, keys created by the GrayGenerator. The caller checks the capture counter of that capture, Nonce,
+// against the capture it expects.
+func VerifyGray(vk_pp generator.VK_PP, gray prover.GrayImage) bool {
+	// The public witness is built from the published image, its area and the camera's key
+	return verifyPublished(vk_pp, "the grayscale image", gray, func() (witness.Witness, error) {
+		return myTransformations.GrayWitness(vk_pp.PublicKey.Bytes(), gray.Nonce(), gray.Image(), gray.Area())
+	})
+}
+
+// VerifyDeep returns true if the deep color image shows its area of a deep color capture taken by the camera of
+// vk_pp, keys created by the DeepGenerator. The caller checks the capture counter of that capture, Nonce,
+// against the capture it expects.
+func VerifyDeep(vk_pp generator.VK_PP, deep prover.DeepImage) bool {
+	// The public witness is built from the published image, its area and the camera's key
+	return verifyPublished(vk_pp, "the deep color image", deep, func() (witness.Witness, error) {
+		return myTransformations.DeepWitness(vk_pp.PublicKey.Bytes(), deep.Nonce(), deep.Image(), deep.Area())
+	})
+}
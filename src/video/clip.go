@@ -0,0 +1,252 @@
+// Package video extends PhotoGnark from stills to short clips: every frame of a clip is proven separately,
+// and the frames are chained by their frame counters, the capture counters the camera signed them for.
+package video
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"src/backend"
+	"src/camera"
+	gen "src/generator"
+	myImage "src/image"
+	"src/prover"
+	myTransformations "src/transformations"
+)
+
+// A Clip is the artifact published with a video clip: its frames, the frame counter of the first one, the area
+// of the captures every frame shows, and a PCD proof of every frame. Frame i was captured as frame counter
+// First + i, so a verifier notices frames that were dropped from the middle of the clip, reordered, or spliced
+// in from another recording. Neither the captures nor their signatures are part of the Clip.
+type Clip struct {
+	frames    []myImage.I
+	first     *big.Int
+	area      map[string]int
+	pcdProofs []backend.Proof
+	circuit   myTransformations.CircuitID
+	backend   backend.ID
+}
+
+// Frames returns the frames of the clip, in order.
+func (clip Clip) Frames() []myImage.I {
+	return clip.frames
+}
+
+// First returns the frame counter of the first frame of the clip.
+func (clip Clip) First() *big.Int {
+	return clip.first
+}
+
+// Area returns the area {x0, y0, x1, y1} of the captures every frame of the clip shows.
+func (clip Clip) Area() map[string]int {
+	return clip.area
+}
+
+// PCDProofs returns the PCD proof of every frame, in order.
+func (clip Clip) PCDProofs() []backend.Proof {
+	return clip.pcdProofs
+}
+
+// Circuit returns the compliance predicate the PCD proofs were created with.
+func (clip Clip) Circuit() myTransformations.CircuitID {
+	return clip.circuit
+}
+
+// Backend returns the proving system the PCD proofs were created with.
+func (clip Clip) Backend() backend.ID {
+	return clip.backend
+}
+
+// Record has the camera take frames consecutive pictures, and returns their proofs in order. The camera must
+// have run its generator.
+func Record(cam *camera.SecureCamera, frames int) []prover.Proof {
+	captures := make([]prover.Proof, frames)
+	for i := range captures {
+		cam.TakePicture()
+		captures[i] = cam.CameraProver()
+	}
+	return captures
+}
+
+// Prove crops every capture to the area {x0, y0, x1, y1} of params and proves the resulting clip; the whole
+// image is the area {0, 0, Width-1, Height-1}. The captures must be proofs of consecutive original images signed by the
+// camera, e.g. the proofs returned by Record, in the order they were captured. pk_pp are keys created by the
+// FrameGenerator for the camera.
+func Prove(pk_pp gen.PK_PP, captures []prover.Proof, params map[string]int, opts ...backend.ProveOption) (Clip, error) {
+	if err := myTransformations.CheckProvable(pk_pp.Circuit); err != nil {
+		return Clip{}, err
+	}
+	if pk_pp.Circuit != myTransformations.FrameCircuitID {
+		return Clip{}, fmt.Errorf("the keys are for circuit %s, not %s: create them with the FrameGenerator", pk_pp.Circuit, myTransformations.FrameCircuitID)
+	}
+	if err := myTransformations.CheckArea(params); err != nil {
+		return Clip{}, err
+	}
+	if len(captures) == 0 {
+		return Clip{}, fmt.Errorf("a clip has at least one frame")
+	}
+	b, err := backend.Get(pk_pp.Backend)
+	if err != nil {
+		return Clip{}, err
+	}
+
+	// Only the camera's signatures over consecutive original images make the frames a recording it took
+	first := captures[0].Nonce()
+	for i, capture := range captures {
+		if len(capture.ImageSignature()) == 0 || capture.Nonce() == nil || capture.PrevProofHash() == nil || capture.PrevProofHash().Sign() != 0 {
+			return Clip{}, fmt.Errorf("frame %d: the proof is not of an original image signed by the camera", i)
+		}
+		if capture.Z().PublicKey == nil || !capture.Z().PublicKey.Equal(pk_pp.PublicKey) {
+			return Clip{}, fmt.Errorf("frame %d: the original image was not signed by the camera of the keys", i)
+		}
+		if counter := new(big.Int).Add(first, big.NewInt(int64(i))); capture.Nonce().Cmp(counter) != 0 {
+			return Clip{}, fmt.Errorf("frame %d was captured as %v, not %v: the frames of a clip are consecutive captures", i, capture.Nonce(), counter)
+		}
+	}
+
+	backend.Report(opts, backend.PhaseCompiling)
+	compliance_predicate, err := gen.Compile(b, pk_pp.Circuit, &myTransformations.FrameCircuit{})
+	if err != nil {
+		return Clip{}, err
+	}
+
+	area := myTransformations.Transformation{T: myTransformations.Crop, Params: params}.ToFr().Params
+	clip := Clip{first: first, area: copyArea(params), circuit: pk_pp.Circuit, backend: b.ID()}
+	for i, capture := range captures {
+		// A frame keeps none of its capture's metadata, which Crop then sets to the size of the frame
+		image := capture.Z().Image
+		frame := image
+		frame.M = myImage.Metadata{Width: image.M.Width, Height: image.M.Height}
+		if err := frame.Crop(params["x0"], params["y0"], params["x1"], params["y1"]); err != nil {
+			return Clip{}, fmt.Errorf("frame %d: %w", i, err)
+		}
+
+		var eddsa_signature eddsa.Signature
+		eddsa_signature.Assign(1, capture.ImageSignature())
+		var eddsa_publicKey eddsa.PublicKey
+		eddsa_publicKey.Assign(1, pk_pp.PublicKey.Bytes())
+
+		circuit := myTransformations.FrameCircuit{
+			PublicKey:      eddsa_publicKey,
+			Nonce:          capture.Nonce(),
+			FrameDigest:    myTransformations.RegionDigest(frame),
+			Area:           area,
+			ImageSignature: eddsa_signature,
+			ImageBytes:     image.Digest(),
+			Metadata:       image.MetadataDigest(),
+			Capture:        image.ToFrontendPacked(),
+		}
+
+		backend.Report(opts, backend.PhaseSolving)
+		secret_witness, err := frontend.NewWitness(&circuit, ecc.BN254.ScalarField())
+		if err != nil {
+			return Clip{}, err
+		}
+		pcd_proof, err := b.Prove(compliance_predicate, pk_pp.ProvingKey, secret_witness, opts...)
+		if err != nil {
+			return Clip{}, fmt.Errorf("frame %d: %w", i, err)
+		}
+
+		clip.frames = append(clip.frames, frame)
+		clip.pcdProofs = append(clip.pcdProofs, pcd_proof)
+	}
+	return clip, nil
+}
+
+// Trim returns the clip of frames from up to, but excluding, to. The frames keep their proofs, and the trimmed
+// clip starts at the frame counter of its first frame, so trimming needs no new proofs.
+func (clip Clip) Trim(from, to int) (Clip, error) {
+	if from < 0 || to > len(clip.frames) || from >= to {
+		return Clip{}, fmt.Errorf("invalid trim [%d, %d) of a clip of %d frames", from, to, len(clip.frames))
+	}
+
+	trimmed := clip
+	trimmed.frames = clip.frames[from:to:to]
+	trimmed.pcdProofs = clip.pcdProofs[from:to:to]
+	trimmed.first = new(big.Int).Add(clip.first, big.NewInt(int64(from)))
+	return trimmed, nil
+}
+
+func copyArea(params map[string]int) map[string]int {
+	return map[string]int{"x0": params["x0"], "y0": params["y0"], "x1": params["x1"], "y1": params["y1"]}
+}
+
+// Version of the clip encoding written by MarshalJSON.
+const ClipFormatVersion = 1
+
+// JSON encoding of a Clip, as published.
+type clipJSON struct {
+	Version   int                         `json:"version"`
+	Circuit   myTransformations.CircuitID `json:"circuit"`
+	Backend   backend.ID                  `json:"backend"`
+	First     string                      `json:"first"` // decimal
+	Area      map[string]int              `json:"area"`
+	Frames    []myImage.I                 `json:"frames"`
+	PCDProofs [][]byte                    `json:"pcdProofs"`
+}
+
+func (clip Clip) MarshalJSON() ([]byte, error) {
+	if len(clip.pcdProofs) != len(clip.frames) {
+		return nil, fmt.Errorf("the clip carries %d PCD proofs for %d frames", len(clip.pcdProofs), len(clip.frames))
+	}
+
+	encoded := clipJSON{
+		Version: ClipFormatVersion,
+		Circuit: clip.circuit,
+		Backend: clip.backend,
+		First:   clip.first.String(),
+		Area:    clip.area,
+		Frames:  clip.frames,
+	}
+	for i, pcdProof := range clip.pcdProofs {
+		if pcdProof == nil {
+			return nil, fmt.Errorf("frame %d carries no PCD proof", i)
+		}
+		var pcd_proof bytes.Buffer
+		if _, err := pcdProof.WriteTo(&pcd_proof); err != nil {
+			return nil, err
+		}
+		encoded.PCDProofs = append(encoded.PCDProofs, pcd_proof.Bytes())
+	}
+	return json.Marshal(encoded)
+}
+
+func (clip *Clip) UnmarshalJSON(data []byte) error {
+	var decoded clipJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version > ClipFormatVersion {
+		return fmt.Errorf("clip format version %d is newer than this build supports (%d): upgrade PhotoGnark to read this clip", decoded.Version, ClipFormatVersion)
+	}
+	if err := myTransformations.CheckArea(decoded.Area); err != nil {
+		return err
+	}
+	first, ok := new(big.Int).SetString(decoded.First, 10)
+	if !ok || first.Sign() < 0 {
+		return fmt.Errorf("invalid first frame counter %q", decoded.First)
+	}
+	if len(decoded.PCDProofs) != len(decoded.Frames) {
+		return fmt.Errorf("the clip carries %d PCD proofs for %d frames", len(decoded.PCDProofs), len(decoded.Frames))
+	}
+
+	b, err := backend.Get(decoded.Backend)
+	if err != nil {
+		return err
+	}
+	pcdProofs := make([]backend.Proof, len(decoded.PCDProofs))
+	for i, data := range decoded.PCDProofs {
+		if pcdProofs[i], err = b.ReadProof(data); err != nil {
+			return fmt.Errorf("frame %d: %w", i, err)
+		}
+	}
+
+	*clip = Clip{frames: decoded.Frames, first: first, area: copyArea(decoded.Area), pcdProofs: pcdProofs, circuit: decoded.Circuit, backend: b.ID()}
+	return nil
+}
@@ -0,0 +1,142 @@
+package video
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/signature"
+
+	"src/hashsuite"
+	myImage "src/image"
+	"src/prover"
+)
+
+/*
+A Sequence is a clip as the camera captured it, before any proof: every frame with its own Z and the camera's
+signature over it, as Record returns them. The frames are chained by hash: link i is the hashsuite.Default hash
+of link i-1 (0 before the first frame), the digest of frame i and its frame counter. The last link, the Head,
+commits to the whole recording, so a sequence is archived or published by its head, and a frame dropped,
+reordered or replaced changes every link from it on. A Clip proves the frames of a Sequence without revealing
+the captures or their signatures.
+*/
+
+// A SequenceFrame is a frame of a Sequence: the picture and the camera's key, the frame counter the camera
+// signed it for, and the signature.
+type SequenceFrame struct {
+	Z         myImage.Z `json:"z"`
+	Nonce     *big.Int  `json:"nonce"`
+	Signature []byte    `json:"signature"`
+}
+
+// A Sequence is the frames of a recording, in order, and their hash chain.
+type Sequence struct {
+	Frames []SequenceFrame `json:"frames"`
+	Chain  [][]byte        `json:"chain"` // Link of every frame, as a big endian field element.
+}
+
+// NewSequence returns the sequence of captures, proofs of consecutive original images signed by the same
+// camera, e.g. the proofs returned by Record, in the order they were captured.
+func NewSequence(captures []prover.Proof) (Sequence, error) {
+	if len(captures) == 0 {
+		return Sequence{}, fmt.Errorf("a sequence has at least one frame")
+	}
+	sequence := Sequence{}
+	for i, capture := range captures {
+		if len(capture.ImageSignature()) == 0 || capture.Nonce() == nil || capture.PrevProofHash() == nil || capture.PrevProofHash().Sign() != 0 {
+			return Sequence{}, fmt.Errorf("frame %d: the proof is not of an original image signed by the camera", i)
+		}
+		sequence.Frames = append(sequence.Frames, SequenceFrame{
+			Z:         capture.Z(),
+			Nonce:     new(big.Int).Set(capture.Nonce()),
+			Signature: bytes.Clone(capture.ImageSignature()),
+		})
+	}
+	if err := sequence.check(sequence.Frames[0].Z.PublicKey); err != nil {
+		return Sequence{}, err
+	}
+	sequence.Chain = chain(sequence.Frames)
+	return sequence, nil
+}
+
+// Head returns the last link of the chain of the sequence, which commits to all its frames, or nil for a
+// sequence without frames.
+func (sequence Sequence) Head() []byte {
+	if len(sequence.Chain) == 0 {
+		return nil
+	}
+	return sequence.Chain[len(sequence.Chain)-1]
+}
+
+// VerifySequence returns true if every frame of the sequence is a picture signed by the camera of publicKey,
+// the frames are consecutive captures, and the chain of the sequence is theirs. The caller checks the Head, the
+// first frame counter and the number of frames against the recording it expects.
+func VerifySequence(publicKey signature.PublicKey, sequence Sequence) bool {
+	if err := sequence.check(publicKey); err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+	for i, frame := range sequence.Frames {
+		msg := myImage.Statement(frame.Z.Image.Digest(), frame.Nonce, big.NewInt(0))
+		isVerified, err := publicKey.Verify(frame.Signature, msg, hashsuite.Default.New())
+		if err != nil || !isVerified {
+			fmt.Printf("FAIL: frame %d of the sequence did not pass verification against its Digital Signature.\n", i)
+			return false
+		}
+	}
+	expected := chain(sequence.Frames)
+	if len(sequence.Chain) != len(expected) {
+		fmt.Printf("FAIL: the sequence carries %d links for %d frames.\n", len(sequence.Chain), len(sequence.Frames))
+		return false
+	}
+	for i, link := range expected {
+		if !bytes.Equal(sequence.Chain[i], link) {
+			fmt.Printf("FAIL: link %d of the sequence is not the hash of its frames.\n", i)
+			return false
+		}
+	}
+	fmt.Println("SUCCESS: Sequence verified against the Digital Signatures of its frames.")
+	return true
+}
+
+// check returns an error if the frames of the sequence are not consecutive captures of the camera of publicKey.
+func (sequence Sequence) check(publicKey signature.PublicKey) error {
+	if len(sequence.Frames) == 0 {
+		return fmt.Errorf("the sequence carries no frames")
+	}
+	if publicKey == nil {
+		return fmt.Errorf("no public key to verify the sequence with")
+	}
+	first := sequence.Frames[0].Nonce
+	for i, frame := range sequence.Frames {
+		if frame.Z.PublicKey == nil || !frame.Z.PublicKey.Equal(publicKey) {
+			return fmt.Errorf("frame %d was not signed by the camera of the sequence", i)
+		}
+		if first == nil || frame.Nonce == nil {
+			return fmt.Errorf("frame %d carries no frame counter", i)
+		}
+		if counter := new(big.Int).Add(first, big.NewInt(int64(i))); frame.Nonce.Cmp(counter) != 0 {
+			return fmt.Errorf("frame %d was captured as %v, not %v: the frames of a sequence are consecutive captures", i, frame.Nonce, counter)
+		}
+	}
+	return nil
+}
+
+// The hash chain of frames, see Sequence.
+func chain(frames []SequenceFrame) [][]byte {
+	links := make([][]byte, len(frames))
+	link := new(big.Int)
+	for i, frame := range frames {
+		h := hashsuite.Default.New()
+		for _, value := range []*big.Int{link, new(big.Int).SetBytes(frame.Z.Image.Digest()), frame.Nonce} {
+			var element fr.Element
+			element.SetBigInt(value)
+			elementBytes := element.Bytes()
+			h.Write(elementBytes[:])
+		}
+		links[i] = h.Sum(nil)
+		link = new(big.Int).SetBytes(links[i])
+	}
+	return links
+}
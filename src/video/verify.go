@@ -0,0 +1,57 @@
+package video
+
+import (
+	"fmt"
+	"math/big"
+
+	"src/backend"
+	"src/generator"
+	myTransformations "src/transformations"
+)
+
+// Verify returns true if every frame of the clip shows the Area of a picture taken by the camera of vk_pp, keys
+// created by the FrameGenerator, and the frames are consecutive captures starting at the frame counter First.
+// A clip with frames dropped from its middle, reordered, spliced in from another recording, or cropped to
+// another area fails; a trimmed clip passes. The caller checks First and the number of frames against the
+// recording it expects.
+func Verify(vk_pp generator.VK_PP, clip Clip) bool {
+	if err := myTransformations.CheckVerifiable(clip.Circuit()); err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+	if clip.Circuit() != vk_pp.Circuit || clip.Backend() != vk_pp.Backend {
+		fmt.Printf("FAIL: the clip was created with circuit %s and %s, but the verifying key is for circuit %s and %s.\n", clip.Circuit(), clip.Backend(), vk_pp.Circuit, vk_pp.Backend)
+		return false
+	}
+	if len(clip.Frames()) == 0 || len(clip.PCDProofs()) != len(clip.Frames()) || clip.First() == nil {
+		fmt.Println("FAIL: the clip carries no frames, or not a PCD proof for every frame.")
+		return false
+	}
+
+	b, err := backend.Get(vk_pp.Backend)
+	if err != nil {
+		fmt.Println("FAIL: " + err.Error())
+		return false
+	}
+
+	// The public witness of every frame is built from the published frame, its frame counter, the area of the
+	// clip and the camera's key, so the frames are chained by their counters
+	for i, frame := range clip.Frames() {
+		counter := new(big.Int).Add(clip.First(), big.NewInt(int64(i)))
+		publicWitness, err := myTransformations.FrameWitness(vk_pp.PublicKey.Bytes(), counter, frame, clip.Area())
+		if err != nil {
+			fmt.Println("FAIL: " + err.Error())
+			return false
+		}
+		if clip.PCDProofs()[i] == nil {
+			fmt.Printf("FAIL: frame %d of the clip carries no PCD proof.\n", i)
+			return false
+		}
+		if err := b.Verify(clip.PCDProofs()[i], vk_pp.VerifyingKey, publicWitness); err != nil {
+			fmt.Printf("FAIL: frame %d of the clip did not pass verification against its PCD proof.\n", i)
+			return false
+		}
+	}
+	fmt.Println("SUCCESS: Clip verified against the PCD proofs of its frames.")
+	return true
+}